@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	agent "dk-go-gophkeeper/internal/client/agent/v1"
+	grpcclient "dk-go-gophkeeper/internal/client/grpcclient/client"
+	keyring "dk-go-gophkeeper/internal/client/keyring/v1"
+	"dk-go-gophkeeper/internal/client/storage/inmemory"
+	"dk-go-gophkeeper/internal/config"
+	"dk-go-gophkeeper/internal/logger"
+	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+func main() {
+	wg := &sync.WaitGroup{}
+	flog, err := os.OpenFile(`agent.log`, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer flog.Close()
+	loggerInstance := logger.InitLog(flog)
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+	cfg := config.NewDefaultConfiguration()
+	err = cfg.Parse()
+	if err != nil {
+		loggerInstance.Fatal().Err(err).Msg("Could not parse configuration")
+	}
+	keyringInstance, err := keyring.InitFileKeyring(loggerInstance, cfg)
+	if err != nil {
+		loggerInstance.Fatal().Err(err).Msg("Could not initialize keyring")
+	}
+	clientGRPC := grpcclient.InitGRPCClient(ctx, loggerInstance, wg, cfg)
+	storage := inmemory.InitStorage(loggerInstance, clientGRPC, keyringInstance, cfg)
+	agentInstance, err := agent.InitAgent(storage, clientGRPC, loggerInstance, cfg)
+	if err != nil {
+		loggerInstance.Fatal().Err(err).Msg("Could not initialize agent")
+	}
+	if err := agentInstance.Serve(ctx); err != nil {
+		loggerInstance.Error().Err(err).Msg("Agent failed")
+	}
+	cancel()
+	wg.Wait()
+}
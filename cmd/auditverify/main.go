@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"dk-go-gophkeeper/internal/config"
+	"dk-go-gophkeeper/internal/server/auditchain"
+	cipher "dk-go-gophkeeper/internal/server/cipher/v1"
+	storage "dk-go-gophkeeper/internal/server/storage/v1"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+func main() {
+	os.Exit(run())
+}
+
+// run walks audit chains of all users, it returns 1 if a chain is broken and 2 if the log could not be verified.
+func run() int {
+	flog, err := os.OpenFile(`auditverify.log`, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer flog.Close()
+	// stdout is reserved for the report, so the log is written to the file only
+	zerolog.TimeFieldFormat = time.RFC3339
+	fileLogger := zerolog.New(flog).With().Timestamp().Logger()
+	loggerInstance := &fileLogger
+	cfg := config.NewDefaultConfiguration()
+	err = cfg.Parse()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	if cfg.DatabaseDSN == "" {
+		fmt.Fprintln(os.Stderr, "usage: auditverify -d <database DSN>")
+		return 2
+	}
+	// checkpoints are signed with a key derived from the USER_KEY of the server
+	cipherInstance, err := cipher.NewCipherService(cfg, loggerInstance)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "auditverify:", err)
+		return 2
+	}
+	storageInstance, err := storage.OpenStorage(loggerInstance, cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "auditverify:", err)
+		return 2
+	}
+	defer storageInstance.DB.Close()
+	ctx := context.Background()
+	checkpoints, err := storageInstance.GetAuditCheckpoints(ctx)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "auditverify:", err)
+		return 2
+	}
+	verifier := auditchain.NewVerifier(checkpoints, cipherInstance.VerifyCheckpoint)
+	err = storageInstance.WalkAuditChains(ctx, verifier.Next)
+	if err == nil {
+		err = verifier.Finish()
+	}
+	var brokenLink *auditchain.BrokenLinkError
+	switch {
+	case errors.As(err, &brokenLink):
+		fmt.Fprintln(os.Stdout, brokenLink.Error())
+		return 1
+	case err != nil:
+		fmt.Fprintln(os.Stderr, "auditverify:", err)
+		return 2
+	}
+	fmt.Fprintf(os.Stdout, "audit log is intact: %d events of %d users, %d checkpoints\n", verifier.Events, verifier.Users, len(checkpoints))
+	return 0
+}
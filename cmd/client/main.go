@@ -3,7 +3,11 @@ package main
 import (
 	"context"
 	grpcclient "dk-go-gophkeeper/internal/client/grpcclient/client"
+	importer "dk-go-gophkeeper/internal/client/importer/v1"
+	keyring "dk-go-gophkeeper/internal/client/keyring/v1"
+	"dk-go-gophkeeper/internal/client/storage"
 	"dk-go-gophkeeper/internal/client/storage/inmemory"
+	"dk-go-gophkeeper/internal/client/storage/remote"
 	"dk-go-gophkeeper/internal/client/tui"
 	"dk-go-gophkeeper/internal/config"
 	"dk-go-gophkeeper/internal/logger"
@@ -29,9 +33,23 @@ func main() {
 	if err != nil {
 		loggerInstance.Fatal().Err(err)
 	}
-	clientGRPC := grpcclient.InitGRPCClient(ctx, loggerInstance, wg, cfg)
-	storage := inmemory.InitStorage(loggerInstance, clientGRPC, cfg)
-	app := tui.InitTUI(cancel, storage, loggerInstance, cfg)
+	var storage storage.DataStorage
+	if remote.Available(cfg) {
+		// attach to a running agent holding the vault
+		storage, err = remote.InitStorage(loggerInstance, cfg)
+		if err != nil {
+			loggerInstance.Fatal().Err(err).Msg("Could not attach to agent")
+		}
+	} else {
+		keyringInstance, err := keyring.InitFileKeyring(loggerInstance, cfg)
+		if err != nil {
+			loggerInstance.Fatal().Err(err).Msg("Could not initialize keyring")
+		}
+		clientGRPC := grpcclient.InitGRPCClient(ctx, loggerInstance, wg, cfg)
+		storage = inmemory.InitStorage(loggerInstance, clientGRPC, keyringInstance, cfg)
+	}
+	importerInstance := importer.InitImporter(storage, loggerInstance, cfg)
+	app := tui.InitTUI(cancel, storage, importerInstance, loggerInstance, cfg)
 	app.Run()
 	done := make(chan os.Signal, 1)
 	signal.Notify(done, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
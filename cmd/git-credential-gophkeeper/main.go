@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"dk-go-gophkeeper/internal/client/credhelper"
+	grpcclient "dk-go-gophkeeper/internal/client/grpcclient/client"
+	keyring "dk-go-gophkeeper/internal/client/keyring/v1"
+	session "dk-go-gophkeeper/internal/client/session/v1"
+	"dk-go-gophkeeper/internal/client/storage"
+	"dk-go-gophkeeper/internal/client/storage/inmemory"
+	"dk-go-gophkeeper/internal/client/storage/remote"
+	"dk-go-gophkeeper/internal/config"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+func main() {
+	os.Exit(run())
+}
+
+func run() int {
+	wg := &sync.WaitGroup{}
+	flog, err := os.OpenFile(`client.log`, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer flog.Close()
+	// stdout is reserved for the credential helper protocol, so the log is written to the file only
+	zerolog.TimeFieldFormat = time.RFC3339
+	fileLogger := zerolog.New(flog).With().Timestamp().Logger()
+	loggerInstance := &fileLogger
+	cfg := config.NewDefaultConfiguration()
+	err = cfg.Parse()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	if flag.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: git-credential-gophkeeper [-a address] <get|store|erase>")
+		return 2
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer wg.Wait()
+	defer cancel()
+	var st storage.DataStorage
+	if remote.Available(cfg) {
+		// attach to a running agent holding the vault
+		st, err = remote.InitStorage(loggerInstance, cfg)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "gophkeeper:", err)
+			return 1
+		}
+	} else {
+		// reuse the session cached by the gophkeeper CLI
+		keeper, err := session.InitFileKeeper(loggerInstance, cfg)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "gophkeeper:", err)
+			return 1
+		}
+		token, sessionKey, err := keeper.Load(cfg.ServerAddress)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "gophkeeper:", err)
+			return 1
+		}
+		keyringInstance, err := keyring.InitFileKeyring(loggerInstance, cfg)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "gophkeeper:", err)
+			return 1
+		}
+		clientGRPC := grpcclient.InitGRPCClient(ctx, loggerInstance, wg, cfg)
+		clientGRPC.SetToken(token, sessionKey)
+		st = inmemory.InitStorage(loggerInstance, clientGRPC, keyringInstance, cfg)
+	}
+	if err := st.Sync(); err != nil {
+		fmt.Fprintln(os.Stderr, "gophkeeper:", err)
+		return 1
+	}
+	helper := credhelper.InitHelper(st, loggerInstance, cfg)
+	if err := helper.Run(flag.Arg(0), os.Stdin, os.Stdout); err != nil {
+		loggerInstance.Error().Err(err).Msg("Credential helper failed")
+		fmt.Fprintln(os.Stderr, "gophkeeper:", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"dk-go-gophkeeper/internal/client/cli"
+	grpcclient "dk-go-gophkeeper/internal/client/grpcclient/client"
+	importer "dk-go-gophkeeper/internal/client/importer/v1"
+	keyring "dk-go-gophkeeper/internal/client/keyring/v1"
+	session "dk-go-gophkeeper/internal/client/session/v1"
+	"dk-go-gophkeeper/internal/client/storage/inmemory"
+	"dk-go-gophkeeper/internal/client/storage/remote"
+	"dk-go-gophkeeper/internal/config"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+func main() {
+	os.Exit(run())
+}
+
+func run() int {
+	wg := &sync.WaitGroup{}
+	flog, err := os.OpenFile(`client.log`, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer flog.Close()
+	// stdout is reserved for command output, so the log is written to the file only
+	zerolog.TimeFieldFormat = time.RFC3339
+	fileLogger := zerolog.New(flog).With().Timestamp().Logger()
+	loggerInstance := &fileLogger
+	flag.Usage = func() {
+		fmt.Fprint(os.Stderr, cli.Usage)
+	}
+	cfg := config.NewDefaultConfiguration()
+	err = cfg.Parse()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer wg.Wait()
+	defer cancel()
+	var app *cli.CLI
+	if remote.Available(cfg) {
+		// attach to a running agent holding the vault
+		storage, err := remote.InitStorage(loggerInstance, cfg)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		importerInstance := importer.InitImporter(storage, loggerInstance, cfg)
+		app = cli.InitAttachedCLI(storage, importerInstance, os.Stdin, os.Stdout, os.Stderr, loggerInstance, cfg)
+	} else {
+		keeper, err := session.InitFileKeeper(loggerInstance, cfg)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		keyringInstance, err := keyring.InitFileKeyring(loggerInstance, cfg)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		clientGRPC := grpcclient.InitGRPCClient(ctx, loggerInstance, wg, cfg)
+		storage := inmemory.InitStorage(loggerInstance, clientGRPC, keyringInstance, cfg)
+		importerInstance := importer.InitImporter(storage, loggerInstance, cfg)
+		app = cli.InitCLI(storage, clientGRPC, keeper, importerInstance, os.Stdin, os.Stdout, os.Stderr, loggerInstance, cfg)
+	}
+	if err := app.Run(flag.Args()); err != nil {
+		var exitErr *cli.ExitError
+		if errors.As(err, &exitErr) {
+			return exitErr.Code
+		}
+		loggerInstance.Error().Err(err).Msg("CLI command failed")
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+	return 0
+}
@@ -16,10 +16,14 @@ import (
 	"os/signal"
 	"sync"
 	"syscall"
+	"time"
 
 	"google.golang.org/grpc"
 )
 
+// sessionCleanupInterval is an interval at which expired sessions are removed.
+const sessionCleanupInterval = time.Hour
+
 // build parameters to be used with ldflags
 
 var (
@@ -71,6 +75,50 @@ func main() {
 	if err != nil {
 		loggerInstance.Fatal().Err(err).Msg("Handlers initialization failed")
 	}
+	// requests of emergency access are granted once their waiting periods are over
+	if cfg.EmergencyTimer > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			t := time.NewTicker(time.Duration(cfg.EmergencyTimer) * time.Second)
+			defer t.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-t.C:
+					granted, err := server.Processor().GrantDueEmergencyAccess(ctx)
+					if err != nil {
+						loggerInstance.Error().Err(err).Msg("Could not grant due emergency access")
+					}
+					if granted > 0 {
+						loggerInstance.Info().Msgf("Emergency access was granted to %d trusted contacts", granted)
+					}
+				}
+			}
+		}()
+	}
+	// expired sessions are removed hourly, they are rejected in between anyway
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		t := time.NewTicker(sessionCleanupInterval)
+		defer t.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+				deleted, err := server.Processor().DeleteExpiredSessions(ctx)
+				if err != nil {
+					loggerInstance.Error().Err(err).Msg("Could not delete expired sessions")
+				}
+				if deleted > 0 {
+					loggerInstance.Info().Msgf("%d expired sessions were deleted", deleted)
+				}
+			}
+		}
+	}()
 	listen, err := net.Listen("tcp", cfg.ServerAddress)
 	if err != nil {
 		loggerInstance.Fatal().Err(err).Msg("Server listening failed")
@@ -79,8 +127,14 @@ func main() {
 	if err != nil {
 		loggerInstance.Fatal().Err(err).Msg("Cipher initialization failed")
 	}
-	interceptorService := interceptors.NewAuthHandler(cipherInstance, cfg)
-	s := grpc.NewServer(grpc.UnaryInterceptor(interceptorService.UnaryServerInterceptor()))
+	interceptorService := interceptors.NewAuthHandler(cipherInstance, cfg, server.Processor(), server.Processor())
+	errorService := interceptors.NewErrorHandler(loggerInstance)
+	// requests are audited with the status codes clients get
+	auditService := interceptors.NewAuditHandler(server.Processor(), cfg, loggerInstance)
+	s := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(auditService.UnaryServerInterceptor(), errorService.UnaryServerInterceptor(), interceptorService.UnaryServerInterceptor()),
+		grpc.ChainStreamInterceptor(auditService.StreamServerInterceptor(), errorService.StreamServerInterceptor(), interceptorService.StreamServerInterceptor()),
+	)
 	done := make(chan os.Signal, 1)
 	signal.Notify(done, os.Interrupt, syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT)
 	go func() {
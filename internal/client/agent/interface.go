@@ -0,0 +1,21 @@
+// Package agent provides a long-running client agent holding an unlocked vault in memory.
+package agent
+
+import "context"
+
+// Server defines a set of methods for types implementing Server.
+type Server interface {
+	Serve(ctx context.Context) error
+}
+
+// Locker defines a set of methods for types implementing Locker.
+type Locker interface {
+	Lock()
+	Locked() bool
+}
+
+// Agent defines a set of embedded interfaces for types implementing Agent.
+type Agent interface {
+	Server
+	Locker
+}
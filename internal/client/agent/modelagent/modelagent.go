@@ -0,0 +1,107 @@
+// Package modelagent provides models and routes of the local agent API.
+package modelagent
+
+import "dk-go-gophkeeper/internal/client/storage/modelstorage"
+
+// agent API routes
+const (
+	RouteStatus   = "/v1/status"
+	RouteLogin    = "/v1/login"
+	RouteRegister = "/v1/register"
+	RouteLock     = "/v1/lock"
+	RouteSync     = "/v1/sync"
+	RouteEntries  = "/v1/entries"
+	RouteEntry    = "/v1/entry"
+	RouteBatch    = "/v1/batch"
+	RouteLabels   = "/v1/labels"
+	RouteFields   = "/v1/fields"
+	RouteShare    = "/v1/share"
+	RouteShares   = "/v1/shares"
+	RouteShared   = "/v1/shared"
+	// collection routes
+	RouteCollections      = "/v1/collections"
+	RouteInvitations      = "/v1/invitations"
+	RouteMembers          = "/v1/members"
+	RouteCollectionEvents = "/v1/collection-events"
+	RouteActivity         = "/v1/activity"
+	RouteSessions         = "/v1/sessions"
+	// emergency access routes
+	RouteTrustedContacts = "/v1/trusted-contacts"
+	RouteEmergency       = "/v1/emergency"
+	RouteEmergencyVault  = "/v1/emergency-vault"
+)
+
+// AuthHeader is the header carrying the agent access token.
+const AuthHeader = "X-Gophkeeper-Agent-Token"
+
+type (
+	Status struct {
+		Locked      bool `json:"locked"`
+		IdleTimeout int  `json:"idle_timeout"`
+	}
+	Credentials struct {
+		Login    string `json:"login"`
+		Password string `json:"password"`
+	}
+	Entry struct {
+		Db         string `json:"db"`
+		Identifier string `json:"identifier"`
+		Number     string `json:"number,omitempty"`
+		Holder     string `json:"holder,omitempty"`
+		Cvv        string `json:"cvv,omitempty"`
+		Expiry     string `json:"expiry,omitempty"`
+		Pin        string `json:"pin,omitempty"`
+		Login      string `json:"login,omitempty"`
+		Password   string `json:"password,omitempty"`
+		Entry      string `json:"entry,omitempty"`
+		Meta       string `json:"meta,omitempty"`
+	}
+	LabelsRequest struct {
+		Db         string              `json:"db"`
+		Identifier string              `json:"identifier"`
+		Labels     modelstorage.Labels `json:"labels"`
+	}
+	FieldsRequest struct {
+		Db         string                     `json:"db"`
+		Identifier string                     `json:"identifier"`
+		Fields     []modelstorage.CustomField `json:"fields"`
+	}
+	ShareRequest struct {
+		Db         string `json:"db"`
+		Identifier string `json:"identifier"`
+		Recipient  string `json:"recipient"`
+		Permission string `json:"permission,omitempty"`
+	}
+	CollectionRequest struct {
+		Name string `json:"name"`
+	}
+	MemberRequest struct {
+		CollectionID string `json:"collection_id"`
+		Login        string `json:"login"`
+		Role         string `json:"role"`
+	}
+	InvitationResponse struct {
+		ID     int64 `json:"id"`
+		Accept bool  `json:"accept"`
+	}
+	TrustedContactRequest struct {
+		Login    string `json:"login"`
+		WaitDays int    `json:"wait_days"`
+	}
+	EmergencyRequest struct {
+		Login   string `json:"login"`
+		Approve bool   `json:"approve,omitempty"`
+	}
+	EntryResponse struct {
+		Data   string `json:"data"`
+		Exists bool   `json:"exists"`
+	}
+	BatchItemResult struct {
+		Identifier string `json:"identifier"`
+		Db         string `json:"db"`
+		Error      string `json:"error,omitempty"`
+	}
+	Error struct {
+		Error string `json:"error"`
+	}
+)
@@ -0,0 +1,948 @@
+// Package agent provides a long-running client agent serving the unlocked vault over a Unix domain socket.
+package agent
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"dk-go-gophkeeper/internal/client/agent"
+	"dk-go-gophkeeper/internal/client/agent/modelagent"
+	"dk-go-gophkeeper/internal/client/grpcclient"
+	"dk-go-gophkeeper/internal/client/storage"
+	"dk-go-gophkeeper/internal/client/storage/modelstorage"
+	"dk-go-gophkeeper/internal/config"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// file and directory permissions of the agent socket and token
+const (
+	fileMode os.FileMode = 0600
+	dirMode  os.FileMode = 0700
+)
+
+// ErrLocked is returned when the vault is requested while the agent is locked.
+var ErrLocked = errors.New("agent is locked, log in first")
+
+// check for interface compliance
+var (
+	_ agent.Agent = (*Agent)(nil)
+)
+
+// peerKey is a context key of a connection peer user ID.
+type peerKey struct{}
+
+// peer defines credentials of a connected process.
+type peer struct {
+	uid int
+	ok  bool
+}
+
+// Agent defines attributes and methods of an Agent instance.
+type Agent struct {
+	mu           sync.Mutex
+	storage      storage.DataStorage
+	client       grpcclient.SessionKeeper
+	locked       bool
+	lastActivity time.Time
+	idleTimeout  time.Duration
+	socketPath   string
+	token        string
+	logger       *zerolog.Logger
+	cfg          *config.Config
+}
+
+// InitAgent initializes a locked Agent instance serving the given storage.
+func InitAgent(st storage.DataStorage, client grpcclient.SessionKeeper, logger *zerolog.Logger, cfg *config.Config) (*Agent, error) {
+	logger.Info().Msg("Attempting to initialize agent")
+	socketPath, err := SocketPath(cfg)
+	if err != nil {
+		return nil, err
+	}
+	token := make([]byte, 32)
+	if _, err := rand.Read(token); err != nil {
+		return nil, err
+	}
+	return &Agent{
+		storage:     st,
+		client:      client,
+		locked:      true,
+		idleTimeout: time.Duration(cfg.AgentIdleTimeout) * time.Second,
+		socketPath:  socketPath,
+		token:       hex.EncodeToString(token),
+		logger:      logger,
+		cfg:         cfg,
+	}, nil
+}
+
+// SocketPath returns the agent socket path, cfg.AgentSocket or a socket in the user configuration directory.
+func SocketPath(cfg *config.Config) (string, error) {
+	if cfg.AgentSocket != "" {
+		return cfg.AgentSocket, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "gophkeeper", "agent.sock"), nil
+}
+
+// TokenPath returns the path of the file holding the agent access token.
+func TokenPath(socketPath string) string {
+	return socketPath + ".token"
+}
+
+// ReadToken reads the agent access token refusing files accessible by other users.
+func ReadToken(socketPath string) (string, error) {
+	path := TokenPath(socketPath)
+	info, err := os.Lstat(path)
+	if err != nil {
+		return "", err
+	}
+	if !info.Mode().IsRegular() || info.Mode().Perm()&0077 != 0 {
+		return "", fmt.Errorf("agent token %s must be a regular file with 0600 permissions", path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// Serve listens on the agent socket until the context is cancelled.
+func (a *Agent) Serve(ctx context.Context) error {
+	// the socket is created with the process umask, so only the directory keeps other users away from it
+	if err := prepareDir(filepath.Dir(a.socketPath)); err != nil {
+		return err
+	}
+	if err := a.removeStaleSocket(); err != nil {
+		return err
+	}
+	listener, err := net.Listen("unix", a.socketPath)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(TokenPath(a.socketPath))
+	if err := os.Chmod(a.socketPath, fileMode); err != nil {
+		listener.Close()
+		return err
+	}
+	if err := writeToken(TokenPath(a.socketPath), a.token); err != nil {
+		listener.Close()
+		return err
+	}
+	server := &http.Server{
+		Handler:           a.routes(),
+		ReadHeaderTimeout: 5 * time.Second,
+		ConnContext: func(ctx context.Context, conn net.Conn) context.Context {
+			uid, ok := peerUID(conn)
+			return context.WithValue(ctx, peerKey{}, peer{uid: uid, ok: ok})
+		},
+	}
+	a.touch()
+	go a.watchIdle(ctx)
+	go func() {
+		<-ctx.Done()
+		a.logger.Warn().Msg("Attempting to shut down agent")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+	a.logger.Info().Msgf("Agent listening on %s", a.socketPath)
+	err = server.Serve(listener)
+	a.Lock()
+	if errors.Is(err, http.ErrServerClosed) {
+		a.logger.Info().Msg("Agent shut down")
+		return nil
+	}
+	return err
+}
+
+// prepareDir creates the agent directory or restricts an existing one to its owner, a symlink or a directory of
+// another user is refused as the owner permissions cannot be set on it.
+func prepareDir(dir string) error {
+	if err := os.MkdirAll(dir, dirMode); err != nil {
+		return err
+	}
+	info, err := os.Lstat(dir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s exists and is not a directory", dir)
+	}
+	if info.Mode().Perm() != dirMode {
+		if err = os.Chmod(dir, dirMode); err != nil {
+			return fmt.Errorf("agent directory %s must have 0700 permissions: %w", dir, err)
+		}
+	}
+	return nil
+}
+
+// writeToken writes the agent access token to a new file, a stale one is removed first so that its permissions
+// and owner are never kept.
+func writeToken(path, token string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, fileMode)
+	if err != nil {
+		return err
+	}
+	if _, err = file.WriteString(token); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// removeStaleSocket removes a socket left by an agent which is not running anymore.
+func (a *Agent) removeStaleSocket() error {
+	info, err := os.Lstat(a.socketPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("%s exists and is not a socket", a.socketPath)
+	}
+	if conn, err := net.Dial("unix", a.socketPath); err == nil {
+		conn.Close()
+		return fmt.Errorf("agent is already running at %s", a.socketPath)
+	}
+	return os.Remove(a.socketPath)
+}
+
+// Lock wipes the vault from memory and drops the server session.
+func (a *Agent) Lock() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.lock()
+}
+
+// lock wipes the vault, the caller must hold the mutex.
+func (a *Agent) lock() {
+	if a.locked {
+		return
+	}
+	a.storage.CleanDB()
+	a.client.SetToken("", "")
+	a.locked = true
+	a.logger.Info().Msg("Agent locked")
+}
+
+// Locked reports whether the agent is locked.
+func (a *Agent) Locked() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.locked
+}
+
+// touch records client activity postponing the auto-lock.
+func (a *Agent) touch() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.lastActivity = time.Now()
+}
+
+// watchIdle locks the agent once no requests were received during the idle timeout.
+func (a *Agent) watchIdle(ctx context.Context) {
+	if a.idleTimeout <= 0 {
+		return
+	}
+	interval := a.idleTimeout / 10
+	if interval > time.Second {
+		interval = time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			a.mu.Lock()
+			if !a.locked && time.Since(a.lastActivity) >= a.idleTimeout {
+				a.logger.Info().Msg("Idle timeout reached")
+				a.lock()
+			}
+			a.mu.Unlock()
+		}
+	}
+}
+
+// routes defines the agent API.
+func (a *Agent) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(modelagent.RouteStatus, a.handleStatus)
+	mux.HandleFunc(modelagent.RouteLogin, a.handleAuthorize(func(login, password string) error { return a.storage.Login(login, password) }))
+	mux.HandleFunc(modelagent.RouteRegister, a.handleAuthorize(func(login, password string) error { return a.storage.Register(login, password) }))
+	mux.HandleFunc(modelagent.RouteLock, a.handleLock)
+	mux.HandleFunc(modelagent.RouteSync, a.unlocked(http.MethodPost, a.handleSync))
+	mux.HandleFunc(modelagent.RouteEntries, a.unlocked(http.MethodGet, a.handleEntries))
+	mux.HandleFunc(modelagent.RouteEntry, a.handleEntry)
+	mux.HandleFunc(modelagent.RouteBatch, a.unlocked(http.MethodPost, a.handleBatch))
+	mux.HandleFunc(modelagent.RouteLabels, a.unlocked(http.MethodPost, a.handleLabels))
+	mux.HandleFunc(modelagent.RouteFields, a.unlocked(http.MethodPost, a.handleFields))
+	mux.HandleFunc(modelagent.RouteShare, a.handleShare)
+	mux.HandleFunc(modelagent.RouteShares, a.unlocked(http.MethodGet, a.handleShares))
+	mux.HandleFunc(modelagent.RouteShared, a.handleShared)
+	mux.HandleFunc(modelagent.RouteCollections, a.handleCollections)
+	mux.HandleFunc(modelagent.RouteInvitations, a.handleInvitations)
+	mux.HandleFunc(modelagent.RouteMembers, a.handleMembers)
+	mux.HandleFunc(modelagent.RouteCollectionEvents, a.unlocked(http.MethodGet, a.handleCollectionEvents))
+	mux.HandleFunc(modelagent.RouteActivity, a.unlocked(http.MethodGet, a.handleActivity))
+	mux.HandleFunc(modelagent.RouteSessions, a.handleSessions)
+	mux.HandleFunc(modelagent.RouteTrustedContacts, a.handleTrustedContacts)
+	mux.HandleFunc(modelagent.RouteEmergency, a.handleEmergency)
+	mux.HandleFunc(modelagent.RouteEmergencyVault, a.unlocked(http.MethodGet, a.handleEmergencyVault))
+	return a.authorize(mux)
+}
+
+// authorize rejects requests from other users or without a valid access token.
+func (a *Agent) authorize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p, _ := r.Context().Value(peerKey{}).(peer)
+		if peerCredSupported && (!p.ok || p.uid != os.Getuid()) {
+			a.logger.Warn().Msgf("Rejected agent connection from UID %d", p.uid)
+			writeError(w, http.StatusForbidden, errors.New("permission denied"))
+			return
+		}
+		token := r.Header.Get(modelagent.AuthHeader)
+		if subtle.ConstantTimeCompare([]byte(token), []byte(a.token)) != 1 {
+			a.logger.Warn().Msg("Rejected agent request with invalid token")
+			writeError(w, http.StatusUnauthorized, errors.New("invalid agent token"))
+			return
+		}
+		a.touch()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// unlocked serves requests with the given method while holding the mutex of an unlocked agent.
+func (a *Agent) unlocked(method string, next func(w http.ResponseWriter, r *http.Request)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != method {
+			writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+			return
+		}
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		if a.locked {
+			writeError(w, http.StatusLocked, ErrLocked)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// handleStatus reports whether the agent is locked.
+func (a *Agent) handleStatus(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, modelagent.Status{Locked: a.Locked(), IdleTimeout: int(a.idleTimeout / time.Second)})
+}
+
+// handleAuthorize logs in or registers and unlocks the agent with freshly synced data.
+func (a *Agent) handleAuthorize(fn func(login, password string) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+			return
+		}
+		var credentials modelagent.Credentials
+		if err := json.NewDecoder(r.Body).Decode(&credentials); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		if err := fn(credentials.Login, credentials.Password); err != nil {
+			a.writeStorageError(w, err)
+			return
+		}
+		if err := a.storage.Sync(); err != nil {
+			a.writeStorageError(w, err)
+			return
+		}
+		a.locked = false
+		a.logger.Info().Msg("Agent unlocked")
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// handleLock locks the agent.
+func (a *Agent) handleLock(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+		return
+	}
+	a.Lock()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleSync re-synchronizes the vault with the server.
+func (a *Agent) handleSync(w http.ResponseWriter, r *http.Request) {
+	if err := a.storage.Sync(); err != nil {
+		a.writeStorageError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleEntries returns all entries.
+func (a *Agent) handleEntries(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, a.storage.Export())
+}
+
+// handleEntry retrieves, adds or removes a single entry.
+func (a *Agent) handleEntry(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		a.unlocked(http.MethodGet, a.handleGetEntry)(w, r)
+	case http.MethodPost:
+		a.unlocked(http.MethodPost, a.handleAddEntry)(w, r)
+	case http.MethodDelete:
+		a.unlocked(http.MethodDelete, a.handleRemoveEntry)(w, r)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+	}
+}
+
+// handleGetEntry retrieves a single entry, only its existence is checked if the exists parameter is set.
+func (a *Agent) handleGetEntry(w http.ResponseWriter, r *http.Request) {
+	identifier, db := r.URL.Query().Get("identifier"), r.URL.Query().Get("db")
+	if r.URL.Query().Get("exists") != "" {
+		writeJSON(w, modelagent.EntryResponse{Exists: a.storage.Exists(identifier, db)})
+		return
+	}
+	data, err := a.storage.Get(identifier, db)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	writeJSON(w, modelagent.EntryResponse{Data: data, Exists: true})
+}
+
+// handleAddEntry adds a single entry.
+func (a *Agent) handleAddEntry(w http.ResponseWriter, r *http.Request) {
+	var entry modelagent.Entry
+	if err := json.NewDecoder(r.Body).Decode(&entry); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	var err error
+	switch entry.Db {
+	case a.cfg.BankCardDB:
+		err = a.storage.AddBankCard(entry.Identifier, entry.Number, entry.Holder, entry.Cvv, entry.Expiry, entry.Pin, entry.Meta)
+	case a.cfg.LoginPasswordDB:
+		err = a.storage.AddLoginPassword(entry.Identifier, entry.Login, entry.Password, entry.Meta)
+	case a.cfg.TextBinaryDB:
+		err = a.storage.AddTextBinary(entry.Identifier, entry.Entry, entry.Meta)
+	default:
+		err = fmt.Errorf("invalid db %s", entry.Db)
+	}
+	if err != nil {
+		a.writeStorageError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleRemoveEntry removes a single entry.
+func (a *Agent) handleRemoveEntry(w http.ResponseWriter, r *http.Request) {
+	if err := a.storage.Remove(r.URL.Query().Get("identifier"), r.URL.Query().Get("db")); err != nil {
+		a.writeStorageError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleBatch adds a batch of entries.
+func (a *Agent) handleBatch(w http.ResponseWriter, r *http.Request) {
+	var batch modelstorage.Batch
+	if err := json.NewDecoder(r.Body).Decode(&batch); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	results := make([]modelagent.BatchItemResult, 0)
+	for _, result := range a.storage.AddBatch(batch) {
+		item := modelagent.BatchItemResult{Identifier: result.Identifier, Db: result.Db}
+		if result.Err != nil {
+			item.Error = result.Err.Error()
+		}
+		results = append(results, item)
+	}
+	writeJSON(w, results)
+}
+
+// handleLabels replaces labels of a single entry.
+func (a *Agent) handleLabels(w http.ResponseWriter, r *http.Request) {
+	var request modelagent.LabelsRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := a.storage.SetLabels(request.Identifier, request.Db, request.Labels); err != nil {
+		a.writeStorageError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleFields replaces custom fields of a single entry.
+func (a *Agent) handleFields(w http.ResponseWriter, r *http.Request) {
+	var request modelagent.FieldsRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := a.storage.SetFields(request.Identifier, request.Db, request.Fields); err != nil {
+		a.writeStorageError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleShare shares an entry with another user or revokes the share.
+func (a *Agent) handleShare(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		a.unlocked(http.MethodPost, a.handleAddShare)(w, r)
+	case http.MethodDelete:
+		a.unlocked(http.MethodDelete, a.handleRevokeShare)(w, r)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+	}
+}
+
+// handleAddShare shares an entry with another user.
+func (a *Agent) handleAddShare(w http.ResponseWriter, r *http.Request) {
+	var request modelagent.ShareRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := a.storage.Share(request.Identifier, request.Db, request.Recipient, request.Permission); err != nil {
+		a.writeStorageError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleRevokeShare revokes access of another user to an entry.
+func (a *Agent) handleRevokeShare(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if err := a.storage.Unshare(query.Get("identifier"), query.Get("db"), query.Get("recipient")); err != nil {
+		a.writeStorageError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleShares returns entries shared with other users.
+func (a *Agent) handleShares(w http.ResponseWriter, r *http.Request) {
+	shares, err := a.storage.Shares()
+	if err != nil {
+		a.writeStorageError(w, err)
+		return
+	}
+	writeJSON(w, shares)
+}
+
+// handleShared returns entries shared with the user or updates one of them.
+func (a *Agent) handleShared(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		a.unlocked(http.MethodGet, a.handleSharedWithMe)(w, r)
+	case http.MethodPost:
+		a.unlocked(http.MethodPost, a.handleUpdateShared)(w, r)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+	}
+}
+
+// handleSharedWithMe returns entries shared with the user.
+func (a *Agent) handleSharedWithMe(w http.ResponseWriter, r *http.Request) {
+	entries, err := a.storage.SharedWithMe()
+	if err != nil {
+		a.writeStorageError(w, err)
+		return
+	}
+	writeJSON(w, entries)
+}
+
+// handleUpdateShared updates an entry shared with the user.
+func (a *Agent) handleUpdateShared(w http.ResponseWriter, r *http.Request) {
+	var entry modelstorage.SharedEntry
+	if err := json.NewDecoder(r.Body).Decode(&entry); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := a.storage.UpdateShared(entry); err != nil {
+		a.writeStorageError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleCollections returns collections of the user, creates or deletes one.
+func (a *Agent) handleCollections(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		a.unlocked(http.MethodGet, a.handleListCollections)(w, r)
+	case http.MethodPost:
+		a.unlocked(http.MethodPost, a.handleCreateCollection)(w, r)
+	case http.MethodDelete:
+		a.unlocked(http.MethodDelete, a.handleDeleteCollection)(w, r)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+	}
+}
+
+// handleListCollections returns collections the user is a member of.
+func (a *Agent) handleListCollections(w http.ResponseWriter, r *http.Request) {
+	collections, err := a.storage.Collections()
+	if err != nil {
+		a.writeStorageError(w, err)
+		return
+	}
+	writeJSON(w, collections)
+}
+
+// handleCreateCollection creates a collection owned by the user.
+func (a *Agent) handleCreateCollection(w http.ResponseWriter, r *http.Request) {
+	var request modelagent.CollectionRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	collection, err := a.storage.CreateCollection(request.Name)
+	if err != nil {
+		a.writeStorageError(w, err)
+		return
+	}
+	writeJSON(w, collection)
+}
+
+// handleDeleteCollection deletes a collection with all its entries.
+func (a *Agent) handleDeleteCollection(w http.ResponseWriter, r *http.Request) {
+	if err := a.storage.DeleteCollection(r.URL.Query().Get("collection")); err != nil {
+		a.writeStorageError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleInvitations returns pending invitations of the user, invites another user or responds an invitation.
+func (a *Agent) handleInvitations(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		a.unlocked(http.MethodGet, a.handleListInvitations)(w, r)
+	case http.MethodPost:
+		a.unlocked(http.MethodPost, a.handleInvite)(w, r)
+	case http.MethodPut:
+		a.unlocked(http.MethodPut, a.handleRespondInvitation)(w, r)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+	}
+}
+
+// handleListInvitations returns pending invitations of the user.
+func (a *Agent) handleListInvitations(w http.ResponseWriter, r *http.Request) {
+	invitations, err := a.storage.Invitations()
+	if err != nil {
+		a.writeStorageError(w, err)
+		return
+	}
+	writeJSON(w, invitations)
+}
+
+// handleInvite invites another user to a collection.
+func (a *Agent) handleInvite(w http.ResponseWriter, r *http.Request) {
+	var request modelagent.MemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := a.storage.Invite(request.CollectionID, request.Login, request.Role); err != nil {
+		a.writeStorageError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleRespondInvitation accepts or declines an invitation of the user.
+func (a *Agent) handleRespondInvitation(w http.ResponseWriter, r *http.Request) {
+	var request modelagent.InvitationResponse
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := a.storage.RespondInvitation(request.ID, request.Accept); err != nil {
+		a.writeStorageError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleMembers returns members of a collection, changes a role of one of them or removes one.
+func (a *Agent) handleMembers(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		a.unlocked(http.MethodGet, a.handleListMembers)(w, r)
+	case http.MethodPost:
+		a.unlocked(http.MethodPost, a.handleSetMemberRole)(w, r)
+	case http.MethodDelete:
+		a.unlocked(http.MethodDelete, a.handleRemoveMember)(w, r)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+	}
+}
+
+// handleListMembers returns members of a collection.
+func (a *Agent) handleListMembers(w http.ResponseWriter, r *http.Request) {
+	members, err := a.storage.Members(r.URL.Query().Get("collection"))
+	if err != nil {
+		a.writeStorageError(w, err)
+		return
+	}
+	writeJSON(w, members)
+}
+
+// handleSetMemberRole changes a role of a collection member.
+func (a *Agent) handleSetMemberRole(w http.ResponseWriter, r *http.Request) {
+	var request modelagent.MemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := a.storage.SetMemberRole(request.CollectionID, request.Login, request.Role); err != nil {
+		a.writeStorageError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleRemoveMember removes a member from a collection.
+func (a *Agent) handleRemoveMember(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if err := a.storage.RemoveMember(query.Get("collection"), query.Get("login")); err != nil {
+		a.writeStorageError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleCollectionEvents returns membership changes of a collection.
+func (a *Agent) handleCollectionEvents(w http.ResponseWriter, r *http.Request) {
+	events, err := a.storage.CollectionEvents(r.URL.Query().Get("collection"))
+	if err != nil {
+		a.writeStorageError(w, err)
+		return
+	}
+	writeJSON(w, events)
+}
+
+// handleActivity returns a page of the audit log of the user, the newest events first.
+func (a *Agent) handleActivity(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	var beforeID int64
+	var limit int
+	var err error
+	if before := query.Get("before"); before != "" {
+		if beforeID, err = strconv.ParseInt(before, 10, 64); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid before %s", before))
+			return
+		}
+	}
+	if value := query.Get("limit"); value != "" {
+		if limit, err = strconv.Atoi(value); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid limit %s", value))
+			return
+		}
+	}
+	events, err := a.storage.Activity(beforeID, limit)
+	if err != nil {
+		a.writeStorageError(w, err)
+		return
+	}
+	writeJSON(w, events)
+}
+
+// handleSessions returns open sessions of the user or revokes one of them.
+func (a *Agent) handleSessions(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		a.unlocked(http.MethodGet, a.handleListSessions)(w, r)
+	case http.MethodDelete:
+		a.unlocked(http.MethodDelete, a.handleRevokeSession)(w, r)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+	}
+}
+
+// handleListSessions returns open sessions of the user.
+func (a *Agent) handleListSessions(w http.ResponseWriter, r *http.Request) {
+	sessions, err := a.storage.Sessions()
+	if err != nil {
+		a.writeStorageError(w, err)
+		return
+	}
+	writeJSON(w, sessions)
+}
+
+// handleRevokeSession revokes a session of the user.
+func (a *Agent) handleRevokeSession(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+	sessionID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid id %s", id))
+		return
+	}
+	if err := a.storage.RevokeSession(sessionID); err != nil {
+		a.writeStorageError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleTrustedContacts returns trusted contacts of the user along with owners who trust the user, designates a trusted
+// contact or removes one.
+func (a *Agent) handleTrustedContacts(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		a.unlocked(http.MethodGet, a.handleListEmergencyAccess)(w, r)
+	case http.MethodPost:
+		a.unlocked(http.MethodPost, a.handleSetTrustedContact)(w, r)
+	case http.MethodDelete:
+		a.unlocked(http.MethodDelete, a.handleRemoveTrustedContact)(w, r)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+	}
+}
+
+// handleListEmergencyAccess returns trusted contacts of the user along with owners who trust the user.
+func (a *Agent) handleListEmergencyAccess(w http.ResponseWriter, r *http.Request) {
+	list, err := a.storage.EmergencyAccess()
+	if err != nil {
+		a.writeStorageError(w, err)
+		return
+	}
+	writeJSON(w, list)
+}
+
+// handleSetTrustedContact designates a trusted contact of the user.
+func (a *Agent) handleSetTrustedContact(w http.ResponseWriter, r *http.Request) {
+	var request modelagent.TrustedContactRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := a.storage.SetTrustedContact(request.Login, request.WaitDays); err != nil {
+		a.writeStorageError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleRemoveTrustedContact removes a trusted contact of the user.
+func (a *Agent) handleRemoveTrustedContact(w http.ResponseWriter, r *http.Request) {
+	if err := a.storage.RemoveTrustedContact(r.URL.Query().Get("login")); err != nil {
+		a.writeStorageError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleEmergency requests emergency access to the vault of an owner, approves or rejects a request of a trusted
+// contact.
+func (a *Agent) handleEmergency(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		a.unlocked(http.MethodPost, a.handleRequestEmergencyAccess)(w, r)
+	case http.MethodPut:
+		a.unlocked(http.MethodPut, a.handleRespondEmergencyAccess)(w, r)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+	}
+}
+
+// handleRequestEmergencyAccess requests emergency access to the vault of an owner.
+func (a *Agent) handleRequestEmergencyAccess(w http.ResponseWriter, r *http.Request) {
+	var request modelagent.EmergencyRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := a.storage.RequestEmergencyAccess(request.Login); err != nil {
+		a.writeStorageError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleRespondEmergencyAccess approves or rejects emergency access of a trusted contact.
+func (a *Agent) handleRespondEmergencyAccess(w http.ResponseWriter, r *http.Request) {
+	var request modelagent.EmergencyRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	respond := a.storage.RejectEmergencyAccess
+	if request.Approve {
+		respond = a.storage.ApproveEmergencyAccess
+	}
+	if err := respond(request.Login); err != nil {
+		a.writeStorageError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleEmergencyVault returns the vault of an owner who granted emergency access to the user.
+func (a *Agent) handleEmergencyVault(w http.ResponseWriter, r *http.Request) {
+	vault, err := a.storage.EmergencyVault(r.URL.Query().Get("owner"))
+	if err != nil {
+		a.writeStorageError(w, err)
+		return
+	}
+	writeJSON(w, vault)
+}
+
+// writeStorageError reports a storage error locking the agent if the server session has expired,
+// the caller must hold the mutex.
+func (a *Agent) writeStorageError(w http.ResponseWriter, err error) {
+	if status.Code(err) == codes.Unauthenticated {
+		a.lock()
+		writeError(w, http.StatusUnauthorized, err)
+		return
+	}
+	writeError(w, http.StatusBadRequest, err)
+}
+
+// writeJSON writes a JSON response.
+func writeJSON(w http.ResponseWriter, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+// writeError writes a JSON error response.
+func writeError(w http.ResponseWriter, code int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(modelagent.Error{Error: err.Error()})
+}
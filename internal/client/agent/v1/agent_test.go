@@ -0,0 +1,158 @@
+package agent
+
+import (
+	"context"
+	"dk-go-gophkeeper/internal/client/agent/modelagent"
+	"dk-go-gophkeeper/internal/client/storage/inmemory"
+	"dk-go-gophkeeper/internal/client/storage/modelstorage"
+	"dk-go-gophkeeper/internal/config"
+	"dk-go-gophkeeper/internal/mocks"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+)
+
+type testAgent struct {
+	agent  *Agent
+	client *mocks.MockGRPCClient
+	keeper *mocks.MockKeeper
+	http   *http.Client
+	done   chan error
+}
+
+func startTestAgent(t *testing.T) *testAgent {
+	cfg := config.NewDefaultConfiguration()
+	cfg.BankCardDB = "bankCard"
+	cfg.LoginPasswordDB = "loginPassword"
+	cfg.TextBinaryDB = "textBinary"
+	cfg.AgentSocket = filepath.Join(t.TempDir(), "agent.sock")
+	ctrl := gomock.NewController(t)
+	client := mocks.NewMockGRPCClient(ctrl)
+	keeper := mocks.NewMockKeeper(ctrl)
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	st := inmemory.InitStorage(&logger, client, keeper, cfg)
+	a, err := InitAgent(st, client, &logger, cfg)
+	assert.Equal(t, nil, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- a.Serve(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		assert.Equal(t, nil, <-done)
+	})
+	assert.Eventually(t, func() bool {
+		_, err := ReadToken(cfg.AgentSocket)
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+	httpClient := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", cfg.AgentSocket)
+		},
+	}}
+	return &testAgent{agent: a, client: client, keeper: keeper, http: httpClient, done: done}
+}
+
+func (ta *testAgent) request(t *testing.T, method, route, token, body string) *http.Response {
+	request, err := http.NewRequest(method, "http://agent"+route, strings.NewReader(body))
+	assert.Equal(t, nil, err)
+	request.Header.Set(modelagent.AuthHeader, token)
+	response, err := ta.http.Do(request)
+	assert.Equal(t, nil, err)
+	response.Body.Close()
+	return response
+}
+
+func (ta *testAgent) unlock(t *testing.T) {
+	ta.client.EXPECT().Login(modelstorage.RegisterLogin{Login: "user", Password: "password"}).Return(codes.OK, nil)
+	ta.client.EXPECT().GetPublicKey("").Return("public_key", codes.OK, nil)
+	ta.keeper.EXPECT().PrivateKey("public_key").Return("private_key", nil)
+	ta.client.EXPECT().GetBankCards().Return(map[string]modelstorage.BankCard{}, codes.OK, nil)
+	ta.client.EXPECT().GetLoginsPasswords().Return(map[string]modelstorage.LoginAndPassword{}, codes.OK, nil)
+	ta.client.EXPECT().GetTextsBinaries().Return(map[string]modelstorage.TextOrBinary{}, codes.OK, nil)
+	ta.client.EXPECT().ListEmergencyAccess().Return(modelstorage.EmergencyAccessList{}, codes.OK, nil)
+	response := ta.request(t, http.MethodPost, modelagent.RouteLogin, ta.agent.token, `{"login": "user", "password": "password"}`)
+	assert.Equal(t, http.StatusNoContent, response.StatusCode)
+}
+
+func TestAgent_Permissions(t *testing.T) {
+	ta := startTestAgent(t)
+	info, err := os.Stat(ta.agent.socketPath)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	info, err = os.Stat(TokenPath(ta.agent.socketPath))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	info, err = os.Stat(filepath.Dir(ta.agent.socketPath))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, os.FileMode(0700), info.Mode().Perm())
+
+	response := ta.request(t, http.MethodGet, modelagent.RouteStatus, "generic_token", "")
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+	response = ta.request(t, http.MethodGet, modelagent.RouteStatus, ta.agent.token, "")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+}
+
+func TestAgent_PrepareFiles(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "gophkeeper")
+	assert.Equal(t, nil, os.Mkdir(dir, 0755))
+	assert.Equal(t, nil, prepareDir(dir))
+	info, err := os.Stat(dir)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, os.FileMode(0700), info.Mode().Perm())
+
+	link := filepath.Join(t.TempDir(), "link")
+	assert.Equal(t, nil, os.Symlink(dir, link))
+	assert.Equal(t, link+" exists and is not a directory", prepareDir(link).Error())
+
+	path := TokenPath(filepath.Join(dir, "agent.sock"))
+	assert.Equal(t, nil, os.WriteFile(path, []byte("stale_token"), 0644))
+	assert.Equal(t, nil, writeToken(path, "fresh_token"))
+	info, err = os.Stat(path)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	token, err := ReadToken(filepath.Join(dir, "agent.sock"))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "fresh_token", token)
+}
+
+func TestAgent_Lock(t *testing.T) {
+	ta := startTestAgent(t)
+	response := ta.request(t, http.MethodGet, modelagent.RouteEntries, ta.agent.token, "")
+	assert.Equal(t, http.StatusLocked, response.StatusCode)
+
+	ta.unlock(t)
+	assert.Equal(t, false, ta.agent.Locked())
+	response = ta.request(t, http.MethodGet, modelagent.RouteEntries, ta.agent.token, "")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	ta.client.EXPECT().SetToken("", "")
+	response = ta.request(t, http.MethodPost, modelagent.RouteLock, ta.agent.token, "")
+	assert.Equal(t, http.StatusNoContent, response.StatusCode)
+	assert.Equal(t, true, ta.agent.Locked())
+}
+
+func TestAgent_IdleLock(t *testing.T) {
+	ta := startTestAgent(t)
+	ta.agent.idleTimeout = 100 * time.Millisecond
+	go ta.agent.watchIdle(context.Background())
+	ta.unlock(t)
+	ta.client.EXPECT().SetToken("", "")
+	assert.Eventually(t, ta.agent.Locked, time.Second, 10*time.Millisecond)
+}
+
+func TestAgent_AlreadyRunning(t *testing.T) {
+	ta := startTestAgent(t)
+	err := ta.agent.removeStaleSocket()
+	assert.Equal(t, "agent is already running at "+ta.agent.socketPath, err.Error())
+}
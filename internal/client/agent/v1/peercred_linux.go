@@ -0,0 +1,32 @@
+//go:build linux
+
+package agent
+
+import (
+	"net"
+	"syscall"
+)
+
+// peerCredSupported reports whether peer credentials of socket connections can be verified.
+const peerCredSupported = true
+
+// peerUID returns the user ID of the process on the other side of a Unix socket connection.
+func peerUID(conn net.Conn) (int, bool) {
+	unixConn, ok := conn.(*net.UnixConn)
+	if !ok {
+		return 0, false
+	}
+	raw, err := unixConn.SyscallConn()
+	if err != nil {
+		return 0, false
+	}
+	var cred *syscall.Ucred
+	var credErr error
+	err = raw.Control(func(fd uintptr) {
+		cred, credErr = syscall.GetsockoptUcred(int(fd), syscall.SOL_SOCKET, syscall.SO_PEERCRED)
+	})
+	if err != nil || credErr != nil {
+		return 0, false
+	}
+	return int(cred.Uid), true
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+
+package agent
+
+import "net"
+
+// peerCredSupported reports whether peer credentials of socket connections can be verified.
+const peerCredSupported = false
+
+// peerUID is not supported on this platform, access is restricted by agent directory permissions and the token only.
+func peerUID(conn net.Conn) (int, bool) {
+	return 0, false
+}
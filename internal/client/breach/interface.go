@@ -0,0 +1,9 @@
+// Package breach provides checks of passwords against known data breaches.
+package breach
+
+// Checker defines a set of methods for types implementing Checker.
+type Checker interface {
+	// Check returns the number of times a password was seen in data breaches, zero if it was not.
+	Check(password string) (int, error)
+	Close() error
+}
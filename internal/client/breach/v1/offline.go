@@ -0,0 +1,202 @@
+// Package breach provides an offline check of passwords against a local copy of the HIBP Pwned Passwords corpus.
+package breach
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"dk-go-gophkeeper/internal/client/breach"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+// SHA-1 hash sizes of the corpus formats
+const (
+	hashLength   = 40
+	prefixLength = 5
+)
+
+// hexDigits are upper-case hexadecimal digits of corpus hashes, range files hold an odd number of them.
+const hexDigits = "0123456789ABCDEF"
+
+// maxLineLength limits the length of a corpus line, the longest one is a hash followed by a count and CRLF.
+const maxLineLength = 128
+
+// rangeFileExtension is the extension of range files of a corpus directory.
+const rangeFileExtension = ".txt"
+
+// check for interface compliance
+var (
+	_ breach.Checker = (*OfflineChecker)(nil)
+)
+
+// OfflineChecker defines attributes and methods of an OfflineChecker instance.
+//
+// The corpus is either a single file of upper-case SHA-1 hashes sorted in ascending order, each optionally followed by
+// a colon and a count (the "ordered by hash" download of Pwned Passwords), or a directory of range files named by the
+// first five characters of hashes and holding the remaining characters of hashes in the same format (as served by the
+// k-anonymity range API). Lines are found by binary search over file offsets, so the corpus is never loaded into memory
+// and passwords never leave the machine.
+type OfflineChecker struct {
+	file   *os.File
+	size   int64
+	dir    string
+	logger *zerolog.Logger
+}
+
+// InitOfflineChecker initializes an OfflineChecker instance reading a corpus file or directory.
+func InitOfflineChecker(path string, logger *zerolog.Logger) (*OfflineChecker, error) {
+	logger.Info().Msg("Attempting to initialize offline breach checker")
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return &OfflineChecker{dir: path, logger: logger}, nil
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	checker := &OfflineChecker{file: file, size: info.Size(), logger: logger}
+	// the first line tells whether the file is a corpus at all
+	if info.Size() > 0 {
+		line, err := readLine(file, 0, info.Size())
+		if err == nil {
+			_, _, err = parseLine(line, hashLength)
+		}
+		if err != nil {
+			_ = file.Close()
+			return nil, fmt.Errorf("%s is not a Pwned Passwords file: %w", path, err)
+		}
+	}
+	return checker, nil
+}
+
+// Check returns the number of times a password was seen in data breaches according to the corpus.
+func (c *OfflineChecker) Check(password string) (int, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	if c.dir == "" {
+		return search(c.file, c.size, hash)
+	}
+	file, err := os.Open(filepath.Join(c.dir, hash[:prefixLength]+rangeFileExtension))
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, fmt.Errorf("range file of prefix %s is missing in %s", hash[:prefixLength], c.dir)
+	}
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return 0, err
+	}
+	return search(file, info.Size(), hash[prefixLength:])
+}
+
+// Close closes the corpus file.
+func (c *OfflineChecker) Close() error {
+	if c.file == nil {
+		return nil
+	}
+	return c.file.Close()
+}
+
+// search performs a binary search of a hash in sorted lines of a file and returns its count.
+func search(r io.ReaderAt, size int64, hash string) (int, error) {
+	// lo is always a line start, the wanted line starts in [lo, hi) if present
+	lo, hi := int64(0), size
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		start, err := lineStart(r, mid, size)
+		if err != nil {
+			return 0, err
+		}
+		if start >= hi {
+			hi = mid
+			continue
+		}
+		line, err := readLine(r, start, size)
+		if err != nil {
+			return 0, err
+		}
+		lineHash, count, err := parseLine(line, len(hash))
+		if err != nil {
+			return 0, fmt.Errorf("malformed line at offset %d: %w", start, err)
+		}
+		switch {
+		case lineHash == hash:
+			return count, nil
+		case lineHash < hash:
+			lo = start + int64(len(line)) + 1
+		default:
+			hi = mid
+		}
+	}
+	return 0, nil
+}
+
+// lineStart returns the offset of the first line starting at or after an offset, the size if there is none.
+func lineStart(r io.ReaderAt, offset, size int64) (int64, error) {
+	if offset == 0 {
+		return 0, nil
+	}
+	buf := make([]byte, maxLineLength)
+	n, err := r.ReadAt(buf, offset-1)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return 0, err
+	}
+	idx := bytes.IndexByte(buf[:n], '\n')
+	if idx < 0 {
+		if offset-1+int64(n) >= size {
+			return size, nil
+		}
+		return 0, fmt.Errorf("line at offset %d is too long", offset)
+	}
+	return offset + int64(idx), nil
+}
+
+// readLine reads a line starting at an offset without its line ending.
+func readLine(r io.ReaderAt, start, size int64) ([]byte, error) {
+	buf := make([]byte, maxLineLength)
+	n, err := r.ReadAt(buf, start)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	line := buf[:n]
+	if idx := bytes.IndexByte(line, '\n'); idx >= 0 {
+		line = line[:idx]
+	} else if start+int64(n) < size {
+		return nil, fmt.Errorf("line at offset %d is too long", start)
+	}
+	return line, nil
+}
+
+// parseLine parses a line of an upper-case hash of the given length and an optional count.
+func parseLine(line []byte, length int) (string, int, error) {
+	text := strings.TrimRight(string(line), "\r")
+	hash, countText, hasCount := strings.Cut(text, ":")
+	if len(hash) != length {
+		return "", 0, fmt.Errorf("hash must consist of %d characters", length)
+	}
+	hash = strings.ToUpper(hash)
+	if strings.Trim(hash, hexDigits) != "" {
+		return "", 0, errors.New("hash must be hexadecimal")
+	}
+	if !hasCount {
+		return hash, 1, nil
+	}
+	count, err := strconv.Atoi(countText)
+	if err != nil || count < 1 {
+		return "", 0, errors.New("count must be a positive number")
+	}
+	return hash, count, nil
+}
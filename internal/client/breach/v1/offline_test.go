@@ -0,0 +1,85 @@
+package breach
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+// hashOf returns an upper-case SHA-1 hash of a password.
+func hashOf(password string) string {
+	sum := sha1.Sum([]byte(password))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+// writeCorpus writes a sorted corpus file of the given passwords and counts.
+func writeCorpus(t *testing.T, path string, lines []string) {
+	sort.Strings(lines)
+	assert.Equal(t, nil, os.WriteFile(path, []byte(strings.Join(lines, "\r\n")+"\r\n"), 0600))
+}
+
+func TestOfflineChecker_File(t *testing.T) {
+	logger := zerolog.Nop()
+	var lines []string
+	counts := map[string]int{}
+	for i := 0; i < 1000; i++ {
+		password := fmt.Sprintf("password%d", i)
+		counts[password] = i + 1
+		lines = append(lines, fmt.Sprintf("%s:%d", hashOf(password), i+1))
+	}
+	path := filepath.Join(t.TempDir(), "pwned-passwords.txt")
+	writeCorpus(t, path, lines)
+	checker, err := InitOfflineChecker(path, &logger)
+	assert.Equal(t, nil, err)
+	defer checker.Close()
+	for password, count := range counts {
+		found, err := checker.Check(password)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, count, found)
+	}
+	found, err := checker.Check("xK9#mP2$vL7@qR4!")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, found)
+}
+
+func TestOfflineChecker_Directory(t *testing.T) {
+	logger := zerolog.Nop()
+	dir := t.TempDir()
+	hash := hashOf("letmein")
+	writeCorpus(t, filepath.Join(dir, hash[:5]+".txt"), []string{hash[5:] + ":42", strings.Repeat("0", 35) + ":1", strings.Repeat("F", 35) + ":3"})
+	checker, err := InitOfflineChecker(dir, &logger)
+	assert.Equal(t, nil, err)
+	found, err := checker.Check("letmein")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 42, found)
+	_, err = checker.Check("xK9#mP2$vL7@qR4!")
+	assert.Equal(t, fmt.Sprintf("range file of prefix %s is missing in %s", hashOf("xK9#mP2$vL7@qR4!")[:5], dir), err.Error())
+	assert.Equal(t, nil, checker.Close())
+}
+
+func TestInitOfflineChecker(t *testing.T) {
+	logger := zerolog.Nop()
+	path := filepath.Join(t.TempDir(), "passwords.txt")
+	assert.Equal(t, nil, os.WriteFile(path, []byte("letmein\n"), 0600))
+	_, err := InitOfflineChecker(path, &logger)
+	assert.Equal(t, path+" is not a Pwned Passwords file: hash must consist of 40 characters", err.Error())
+
+	// hashes without counts are accepted as seen once
+	writeCorpus(t, path, []string{hashOf("letmein"), hashOf("qwerty")})
+	checker, err := InitOfflineChecker(path, &logger)
+	assert.Equal(t, nil, err)
+	found, err := checker.Check("qwerty")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, found)
+
+	_, err = InitOfflineChecker(filepath.Join(t.TempDir(), "missing.txt"), &logger)
+	assert.Equal(t, true, os.IsNotExist(err))
+}
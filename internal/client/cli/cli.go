@@ -0,0 +1,1212 @@
+// Package cli provides a non-interactive command line client for scripting and CI usage.
+package cli
+
+import (
+	"bufio"
+	"context"
+	breachV1 "dk-go-gophkeeper/internal/client/breach/v1"
+	"dk-go-gophkeeper/internal/client/generator"
+	"dk-go-gophkeeper/internal/client/grpcclient"
+	"dk-go-gophkeeper/internal/client/health"
+	"dk-go-gophkeeper/internal/client/importer"
+	"dk-go-gophkeeper/internal/client/renderer"
+	"dk-go-gophkeeper/internal/client/runner"
+	"dk-go-gophkeeper/internal/client/secretref"
+	"dk-go-gophkeeper/internal/client/session"
+	"dk-go-gophkeeper/internal/client/storage"
+	"dk-go-gophkeeper/internal/client/storage/modelstorage"
+	"dk-go-gophkeeper/internal/client/storage/search"
+	"dk-go-gophkeeper/internal/config"
+	"dk-go-gophkeeper/internal/validation"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// entry type names accepted on the command line
+const (
+	typeCard  = "card"
+	typeLogin = "login"
+	typeText  = "text"
+)
+
+// Usage describes all supported subcommands.
+const Usage = `Usage: gophkeeper [-a address] [-c config] <command> [arguments]
+
+Commands:
+  login -u <login>                       log in, the password is read from stdin
+  register -u <login>                    register, the password is read from stdin
+  logout                                 remove the cached session or lock the agent
+  sync [-json]                           retrieve all entries from the server
+  ls [-json] [-folder f] [-tag t]... [-favorites] [type]
+                                         list entry identifiers, optionally filtered by labels
+  get [-json] [-field name] <type> <id>  print an entry
+  add [flags] <type> <id>                add an entry, secrets are read from stdin:
+                                           login — password (flags: -login, -meta)
+                                           card  — number, CVV and an optional PIN on separate lines
+                                                   (flags: -holder, -expiry MM/YY, -meta)
+                                           text  — the whole input (flags: -meta)
+  label [-folder f] [-tag t]... [-favorite] <type> <id>
+                                         replace folder, tags and favorite flag of an entry
+  rm <type> <id>                         remove an entry
+  share [-write] <type> <id> <user>      share an entry with another user, read-only unless -write is given
+  unshare <type> <id> <user>             revoke access of another user to an entry
+  shares [-json]                         list entries shared with other users
+  shared [-json]                         list entries other users share with you
+  collection <subcommand> [arguments]    manage team collections:
+                                           create <name>, ls [-json], rm <collection>,
+                                           invite [-role r] <collection> <user>, invitations [-json],
+                                           accept <invitation>, decline <invitation>,
+                                           members [-json] <collection>, role <collection> <user> <role>,
+                                           remove <collection> <user> (remove yourself to leave),
+                                           events [-json] <collection>
+  gen [-length n] [-no-lower] [-no-upper] [-no-digits] [-no-symbols] [-no-ambiguous] [-json]
+  gen -passphrase [-words n] [-separator s] [-capitalize] [-number] [-json]
+                                         generate a random password or passphrase, no login required
+  audit [-min-entropy bits] [-max-age days] [-breaches path] [-json]
+                                         report weak, reused, old and breached passwords of login entries
+  export [-o file]                       export all entries as JSON
+  import -format <format> [-dry-run] [-json] <file|->
+                                         import entries exported by other password managers
+  render [-check] [-json] [-o file] [-mode 0600] <template>
+                                         render a template with {{ secret "gk://..." }} references
+  run [-env-file file] [-e NAME=ref]... [-no-mask] -- <command> [arguments]
+                                         run a command with secret references resolved in its environment
+
+Types: card, login, text.
+Roles: owner, admin, member, read-only. Set COLLECTION to a collection ID to work with its entries.
+References: gk://<type or db>/<id>/<field>, e.g. gk://loginPassword/db/password.
+`
+
+// ExitError reports a non-zero exit code of a command run by the CLI.
+type ExitError struct {
+	Code int
+}
+
+// Error implements the error interface.
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
+// envFlags collects repeated NAME=value flags.
+type envFlags []string
+
+// String implements the flag.Value interface.
+func (f *envFlags) String() string {
+	return strings.Join(*f, ",")
+}
+
+// Set implements the flag.Value interface.
+func (f *envFlags) Set(value string) error {
+	if !strings.Contains(value, "=") {
+		return fmt.Errorf("expected NAME=value, got %s", value)
+	}
+	*f = append(*f, value)
+	return nil
+}
+
+// listFlags collects repeated flag values.
+type listFlags []string
+
+// String implements the flag.Value interface.
+func (f *listFlags) String() string {
+	return strings.Join(*f, ",")
+}
+
+// Set implements the flag.Value interface.
+func (f *listFlags) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+// command defines a subcommand handler.
+type command func(args []string) error
+
+// CLI defines attributes and methods of a CLI instance.
+type CLI struct {
+	storage  storage.DataStorage
+	client   grpcclient.SessionKeeper
+	session  session.Keeper
+	importer importer.Importer
+	stdin    *bufio.Reader
+	rawStdin io.Reader
+	stdout   io.Writer
+	stderr   io.Writer
+	commands map[string]command
+	logger   *zerolog.Logger
+	cfg      *config.Config
+}
+
+// InitCLI initializes a CLI instance.
+func InitCLI(st storage.DataStorage, client grpcclient.SessionKeeper, keeper session.Keeper, imp importer.Importer,
+	stdin io.Reader, stdout, stderr io.Writer, logger *zerolog.Logger, cfg *config.Config) *CLI {
+	logger.Info().Msg("Attempting to initialize CLI")
+	c := &CLI{
+		storage:  st,
+		client:   client,
+		session:  keeper,
+		importer: imp,
+		stdin:    bufio.NewReader(stdin),
+		rawStdin: stdin,
+		stdout:   stdout,
+		stderr:   stderr,
+		logger:   logger,
+		cfg:      cfg,
+	}
+	c.commands = map[string]command{
+		"login":      c.login,
+		"register":   c.register,
+		"logout":     c.logout,
+		"sync":       c.sync,
+		"ls":         c.list,
+		"get":        c.get,
+		"add":        c.add,
+		"label":      c.label,
+		"rm":         c.remove,
+		"share":      c.share,
+		"unshare":    c.unshare,
+		"shares":     c.shares,
+		"shared":     c.shared,
+		"collection": c.collection,
+		"gen":        c.generate,
+		"audit":      c.audit,
+		"export":     c.export,
+		"import":     c.importData,
+		"run":        c.run,
+		"render":     c.render,
+	}
+	return c
+}
+
+// InitAttachedCLI initializes a CLI instance attached to a running agent, which holds the session and the vault.
+func InitAttachedCLI(st storage.DataStorage, imp importer.Importer, stdin io.Reader, stdout, stderr io.Writer,
+	logger *zerolog.Logger, cfg *config.Config) *CLI {
+	return InitCLI(st, nil, nil, imp, stdin, stdout, stderr, logger, cfg)
+}
+
+// attached reports whether the CLI is attached to an agent.
+func (c *CLI) attached() bool {
+	return c.session == nil
+}
+
+// Run executes a subcommand given as the first argument.
+func (c *CLI) Run(args []string) error {
+	if len(args) == 0 {
+		fmt.Fprint(c.stderr, Usage)
+		return errors.New("no command given")
+	}
+	cmd, ok := c.commands[args[0]]
+	if !ok {
+		fmt.Fprint(c.stderr, Usage)
+		return fmt.Errorf("unknown command %s", args[0])
+	}
+	c.logger.Info().Msgf("Running CLI command %s", args[0])
+	return cmd(args[1:])
+}
+
+// login logs in and caches the session.
+func (c *CLI) login(args []string) error {
+	return c.authorize("login", args, c.storage.Login)
+}
+
+// register registers a new user and caches the session.
+func (c *CLI) register(args []string) error {
+	return c.authorize("register", args, c.storage.Register)
+}
+
+// authorize runs an authorization request with the password read from stdin and caches the obtained session.
+func (c *CLI) authorize(name string, args []string, fn func(login, password string) error) error {
+	fs := c.newFlagSet(name)
+	login := fs.String("u", "", "login")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 0 {
+		return fmt.Errorf("unexpected arguments %v", positional)
+	}
+	password, err := c.readLine()
+	if err != nil {
+		return err
+	}
+	if err := fn(*login, password); err != nil {
+		return err
+	}
+	if c.attached() {
+		fmt.Fprintf(c.stderr, "Agent unlocked as %s\n", *login)
+		return nil
+	}
+	token, sessionKey := c.client.Token()
+	if err := c.session.Save(c.cfg.ServerAddress, token, sessionKey); err != nil {
+		return err
+	}
+	fmt.Fprintf(c.stderr, "Logged in as %s\n", *login)
+	return nil
+}
+
+// logout removes the cached session or locks the agent.
+func (c *CLI) logout(args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("unexpected arguments %v", args)
+	}
+	if c.attached() {
+		c.storage.CleanDB()
+		return nil
+	}
+	return c.session.Clear()
+}
+
+// sync retrieves all entries from the server and reports their amount.
+func (c *CLI) sync(args []string) error {
+	fs := c.newFlagSet("sync")
+	asJSON := fs.Bool("json", false, "print JSON")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 0 {
+		return fmt.Errorf("unexpected arguments %v", positional)
+	}
+	if err := c.restore(); err != nil {
+		return err
+	}
+	batch := c.storage.Export()
+	counts := map[string]int{
+		typeCard:  len(batch.BankCards),
+		typeLogin: len(batch.LoginsPasswords),
+		typeText:  len(batch.TextsBinaries),
+	}
+	if *asJSON {
+		return c.writeJSON(counts)
+	}
+	fmt.Fprintf(c.stdout, "%s: %d\n%s: %d\n%s: %d\n", typeCard, counts[typeCard], typeLogin, counts[typeLogin], typeText, counts[typeText])
+	return nil
+}
+
+// listItem defines a single entry of the ls output.
+type listItem struct {
+	Type       string `json:"type"`
+	Identifier string `json:"identifier"`
+	modelstorage.Labels
+}
+
+// list prints identifiers of all entries, optionally of a single type and with given labels.
+func (c *CLI) list(args []string) error {
+	fs := c.newFlagSet("ls")
+	asJSON := fs.Bool("json", false, "print JSON")
+	folder := fs.String("folder", "", "list entries of a folder and its subfolders only")
+	favorites := fs.Bool("favorites", false, "list favorite entries only")
+	var tags listFlags
+	fs.Var(&tags, "tag", "list entries having a tag only, may be repeated")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 1 {
+		return fmt.Errorf("unexpected arguments %v", positional[1:])
+	}
+	filter := ""
+	if len(positional) == 1 {
+		if _, err := c.db(positional[0]); err != nil {
+			return err
+		}
+		filter = c.typeName(positional[0])
+	}
+	if err := c.restore(); err != nil {
+		return err
+	}
+	batch := c.storage.Export()
+	items := make([]listItem, 0)
+	for _, value := range batch.BankCards {
+		items = append(items, listItem{Type: typeCard, Identifier: value.Identifier, Labels: value.Labels})
+	}
+	for _, value := range batch.LoginsPasswords {
+		items = append(items, listItem{Type: typeLogin, Identifier: value.Identifier, Labels: value.Labels})
+	}
+	for _, value := range batch.TextsBinaries {
+		items = append(items, listItem{Type: typeText, Identifier: value.Identifier, Labels: value.Labels})
+	}
+	filtered := items[:0]
+	for _, item := range items {
+		if (filter == "" || item.Type == filter) && search.MatchLabels(item.Labels, *folder, tags, *favorites) {
+			filtered = append(filtered, item)
+		}
+	}
+	if *asJSON {
+		return c.writeJSON(filtered)
+	}
+	for _, item := range filtered {
+		fmt.Fprintf(c.stdout, "%s\t%s\n", item.Type, item.Identifier)
+	}
+	return nil
+}
+
+// get prints a single entry as JSON, as a list of fields or a single field value.
+func (c *CLI) get(args []string) error {
+	fs := c.newFlagSet("get")
+	asJSON := fs.Bool("json", false, "print JSON")
+	field := fs.String("field", "", "print a single field value only")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 2 {
+		return errors.New("type and identifier are required")
+	}
+	if _, err := c.db(positional[0]); err != nil {
+		return err
+	}
+	if err := c.restore(); err != nil {
+		return err
+	}
+	value, fields, err := c.find(c.typeName(positional[0]), positional[1])
+	if err != nil {
+		return err
+	}
+	if bankCard, ok := value.(modelstorage.BankCard); ok {
+		if warning := validation.ExpiryWarning(bankCard.Expiry, time.Now()); warning != "" {
+			fmt.Fprintf(c.stderr, "Warning: %s\n", warning)
+		}
+	}
+	switch {
+	case *field != "":
+		for _, f := range fields {
+			if f[0] == *field {
+				fmt.Fprintln(c.stdout, f[1])
+				return nil
+			}
+		}
+		return fmt.Errorf("unknown field %s", *field)
+	case *asJSON:
+		return c.writeJSON(value)
+	default:
+		for _, f := range fields {
+			fmt.Fprintf(c.stdout, "%s: %s\n", f[0], f[1])
+		}
+		return nil
+	}
+}
+
+// find looks an entry up in the local storage and returns it along with its ordered fields followed by custom ones.
+func (c *CLI) find(typeName, identifier string) (interface{}, [][2]string, error) {
+	batch := c.storage.Export()
+	switch typeName {
+	case typeCard:
+		for _, value := range batch.BankCards {
+			if value.Identifier == identifier {
+				return value, withCustomFields([][2]string{{"identifier", value.Identifier}, {"number", value.Number}, {"holder", value.Holder}, {"cvv", value.Cvv}, {"expiry", value.Expiry}, {"pin", value.Pin}, {"meta", value.Meta}}, value.Fields), nil
+			}
+		}
+	case typeLogin:
+		for _, value := range batch.LoginsPasswords {
+			if value.Identifier == identifier {
+				return value, withCustomFields([][2]string{{"identifier", value.Identifier}, {"login", value.Login}, {"password", value.Password}, {"meta", value.Meta}}, value.Fields), nil
+			}
+		}
+	case typeText:
+		for _, value := range batch.TextsBinaries {
+			if value.Identifier == identifier {
+				return value, withCustomFields([][2]string{{"identifier", value.Identifier}, {"entry", value.Entry}, {"meta", value.Meta}}, value.Fields), nil
+			}
+		}
+	}
+	return nil, nil, fmt.Errorf("entry ID %s of type %s does not exist", identifier, typeName)
+}
+
+// withCustomFields appends custom fields to the fields of an entry, custom fields never shadow predefined ones.
+func withCustomFields(fields [][2]string, custom []modelstorage.CustomField) [][2]string {
+	predefined := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		predefined[field[0]] = true
+	}
+	for _, field := range custom {
+		if !predefined[field.Name] {
+			fields = append(fields, [2]string{field.Name, field.Value})
+		}
+	}
+	return fields
+}
+
+// add adds a new entry reading its secret parts from stdin.
+func (c *CLI) add(args []string) error {
+	fs := c.newFlagSet("add")
+	login := fs.String("login", "", "login of a login/password entry")
+	holder := fs.String("holder", "", "holder of a bank card entry")
+	expiry := fs.String("expiry", "", "expiry date of a bank card entry, MM/YY")
+	meta := fs.String("meta", "", "meta information")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 2 {
+		return errors.New("type and identifier are required")
+	}
+	if _, err := c.db(positional[0]); err != nil {
+		return err
+	}
+	identifier := positional[1]
+	// secrets are read prior to any network activity so that a broken pipe fails fast
+	var secrets []string
+	switch c.typeName(positional[0]) {
+	case typeCard:
+		for i := 0; i < 2; i++ {
+			line, err := c.readLine()
+			if err != nil {
+				return err
+			}
+			secrets = append(secrets, line)
+		}
+		// the PIN line is optional
+		pin, err := c.readLine()
+		if err != nil {
+			pin = ""
+		}
+		secrets = append(secrets, pin)
+	case typeLogin:
+		line, err := c.readLine()
+		if err != nil {
+			return err
+		}
+		secrets = append(secrets, line)
+	case typeText:
+		data, err := io.ReadAll(c.stdin)
+		if err != nil {
+			return err
+		}
+		secrets = append(secrets, strings.TrimSuffix(string(data), "\n"))
+	}
+	if err := c.restore(); err != nil {
+		return err
+	}
+	switch c.typeName(positional[0]) {
+	case typeCard:
+		return c.storage.AddBankCard(identifier, secrets[0], *holder, secrets[1], *expiry, secrets[2], *meta)
+	case typeLogin:
+		return c.storage.AddLoginPassword(identifier, *login, secrets[0], *meta)
+	default:
+		return c.storage.AddTextBinary(identifier, secrets[0], *meta)
+	}
+}
+
+// label replaces labels of an entry, labels not given are cleared.
+func (c *CLI) label(args []string) error {
+	fs := c.newFlagSet("label")
+	folder := fs.String("folder", "", "folder, nested folders are separated by /")
+	favorite := fs.Bool("favorite", false, "mark as favorite")
+	var tags listFlags
+	fs.Var(&tags, "tag", "tag, may be repeated or comma-separated")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 2 {
+		return errors.New("type and identifier are required")
+	}
+	db, err := c.db(positional[0])
+	if err != nil {
+		return err
+	}
+	if err := c.restore(); err != nil {
+		return err
+	}
+	return c.storage.SetLabels(positional[1], db, modelstorage.Labels{Folder: *folder, Tags: tags, Favorite: *favorite})
+}
+
+// remove removes an entry.
+func (c *CLI) remove(args []string) error {
+	if len(args) != 2 {
+		return errors.New("type and identifier are required")
+	}
+	db, err := c.db(args[0])
+	if err != nil {
+		return err
+	}
+	if err := c.restore(); err != nil {
+		return err
+	}
+	return c.storage.Remove(args[1], db)
+}
+
+// share shares an entry with another user.
+func (c *CLI) share(args []string) error {
+	fs := c.newFlagSet("share")
+	write := fs.Bool("write", false, "let the user update the entry")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 3 {
+		return errors.New("type, identifier and user are required")
+	}
+	db, err := c.db(positional[0])
+	if err != nil {
+		return err
+	}
+	if err := c.restore(); err != nil {
+		return err
+	}
+	permission := modelstorage.PermissionRead
+	if *write {
+		permission = modelstorage.PermissionReadWrite
+	}
+	return c.storage.Share(positional[1], db, positional[2], permission)
+}
+
+// unshare revokes access of another user to an entry.
+func (c *CLI) unshare(args []string) error {
+	if len(args) != 3 {
+		return errors.New("type, identifier and user are required")
+	}
+	db, err := c.db(args[0])
+	if err != nil {
+		return err
+	}
+	if err := c.restore(); err != nil {
+		return err
+	}
+	return c.storage.Unshare(args[1], db, args[2])
+}
+
+// shares prints entries shared with other users, one line per recipient.
+func (c *CLI) shares(args []string) error {
+	fs := c.newFlagSet("shares")
+	asJSON := fs.Bool("json", false, "print JSON")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 0 {
+		return fmt.Errorf("unexpected arguments %v", positional)
+	}
+	if err := c.restore(); err != nil {
+		return err
+	}
+	shares, err := c.storage.Shares()
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return c.writeJSON(shares)
+	}
+	for _, share := range shares {
+		// entries shared before clients kept key pairs cannot be opened by their recipients
+		if share.ReshareRequired {
+			fmt.Fprintf(c.stdout, "%s\t%s\tshare again to restore access\n", c.typeName(share.Db), share.Identifier)
+		}
+		for _, recipient := range share.Recipients {
+			fmt.Fprintf(c.stdout, "%s\t%s\t%s\t%s\n", c.typeName(share.Db), share.Identifier, recipient.Login, recipient.Permission)
+		}
+	}
+	return nil
+}
+
+// shared prints entries other users share with the user.
+func (c *CLI) shared(args []string) error {
+	fs := c.newFlagSet("shared")
+	asJSON := fs.Bool("json", false, "print JSON")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 0 {
+		return fmt.Errorf("unexpected arguments %v", positional)
+	}
+	if err := c.restore(); err != nil {
+		return err
+	}
+	entries, err := c.storage.SharedWithMe()
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return c.writeJSON(entries)
+	}
+	for _, entry := range entries {
+		fmt.Fprintf(c.stdout, "%s\t%s\t%s\t%s\n", c.typeName(entry.Db), entry.Identifier(), entry.Owner, entry.Permission)
+	}
+	return nil
+}
+
+// collection runs a collection management subcommand.
+func (c *CLI) collection(args []string) error {
+	subcommands := map[string]command{
+		"create":      c.createCollection,
+		"ls":          c.listCollections,
+		"rm":          c.deleteCollection,
+		"invite":      c.invite,
+		"invitations": c.invitations,
+		"accept":      func(args []string) error { return c.respondInvitation(args, true) },
+		"decline":     func(args []string) error { return c.respondInvitation(args, false) },
+		"members":     c.members,
+		"role":        c.setMemberRole,
+		"remove":      c.removeMember,
+		"events":      c.collectionEvents,
+	}
+	if len(args) == 0 {
+		return errors.New("collection subcommand is required")
+	}
+	cmd, ok := subcommands[args[0]]
+	if !ok {
+		return fmt.Errorf("unknown collection subcommand %s", args[0])
+	}
+	return cmd(args[1:])
+}
+
+// createCollection creates a collection owned by the user and prints its ID.
+func (c *CLI) createCollection(args []string) error {
+	if len(args) != 1 {
+		return errors.New("collection name is required")
+	}
+	if err := c.restore(); err != nil {
+		return err
+	}
+	collection, err := c.storage.CreateCollection(args[0])
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(c.stdout, collection.ID)
+	return nil
+}
+
+// listCollections prints collections the user is a member of.
+func (c *CLI) listCollections(args []string) error {
+	fs := c.newFlagSet("collection ls")
+	asJSON := fs.Bool("json", false, "print JSON")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 0 {
+		return fmt.Errorf("unexpected arguments %v", positional)
+	}
+	if err := c.restore(); err != nil {
+		return err
+	}
+	collections, err := c.storage.Collections()
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return c.writeJSON(collections)
+	}
+	for _, collection := range collections {
+		fmt.Fprintf(c.stdout, "%s\t%s\t%s\n", collection.ID, collection.Name, collection.Role)
+	}
+	return nil
+}
+
+// deleteCollection deletes a collection with all its entries.
+func (c *CLI) deleteCollection(args []string) error {
+	if len(args) != 1 {
+		return errors.New("collection is required")
+	}
+	if err := c.restore(); err != nil {
+		return err
+	}
+	return c.storage.DeleteCollection(args[0])
+}
+
+// invite invites another user to a collection.
+func (c *CLI) invite(args []string) error {
+	fs := c.newFlagSet("collection invite")
+	role := fs.String("role", modelstorage.RoleMember, "role of the user in the collection")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 2 {
+		return errors.New("collection and user are required")
+	}
+	if err := c.restore(); err != nil {
+		return err
+	}
+	return c.storage.Invite(positional[0], positional[1], *role)
+}
+
+// invitations prints pending invitations of the user.
+func (c *CLI) invitations(args []string) error {
+	fs := c.newFlagSet("collection invitations")
+	asJSON := fs.Bool("json", false, "print JSON")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 0 {
+		return fmt.Errorf("unexpected arguments %v", positional)
+	}
+	if err := c.restore(); err != nil {
+		return err
+	}
+	invitations, err := c.storage.Invitations()
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return c.writeJSON(invitations)
+	}
+	for _, invitation := range invitations {
+		fmt.Fprintf(c.stdout, "%d\t%s\t%s\t%s\n", invitation.ID, invitation.CollectionName, invitation.Inviter, invitation.Role)
+	}
+	return nil
+}
+
+// respondInvitation accepts or declines an invitation of the user.
+func (c *CLI) respondInvitation(args []string, accept bool) error {
+	if len(args) != 1 {
+		return errors.New("invitation is required")
+	}
+	invitationID, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid invitation %s", args[0])
+	}
+	if err := c.restore(); err != nil {
+		return err
+	}
+	return c.storage.RespondInvitation(invitationID, accept)
+}
+
+// members prints members of a collection.
+func (c *CLI) members(args []string) error {
+	fs := c.newFlagSet("collection members")
+	asJSON := fs.Bool("json", false, "print JSON")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return errors.New("collection is required")
+	}
+	if err := c.restore(); err != nil {
+		return err
+	}
+	members, err := c.storage.Members(positional[0])
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return c.writeJSON(members)
+	}
+	for _, member := range members {
+		fmt.Fprintf(c.stdout, "%s\t%s\n", member.Login, member.Role)
+	}
+	return nil
+}
+
+// setMemberRole changes a role of a collection member.
+func (c *CLI) setMemberRole(args []string) error {
+	if len(args) != 3 {
+		return errors.New("collection, user and role are required")
+	}
+	if err := c.restore(); err != nil {
+		return err
+	}
+	return c.storage.SetMemberRole(args[0], args[1], args[2])
+}
+
+// removeMember removes a member from a collection.
+func (c *CLI) removeMember(args []string) error {
+	if len(args) != 2 {
+		return errors.New("collection and user are required")
+	}
+	if err := c.restore(); err != nil {
+		return err
+	}
+	return c.storage.RemoveMember(args[0], args[1])
+}
+
+// collectionEvents prints membership changes of a collection, the oldest first.
+func (c *CLI) collectionEvents(args []string) error {
+	fs := c.newFlagSet("collection events")
+	asJSON := fs.Bool("json", false, "print JSON")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return errors.New("collection is required")
+	}
+	if err := c.restore(); err != nil {
+		return err
+	}
+	events, err := c.storage.CollectionEvents(positional[0])
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return c.writeJSON(events)
+	}
+	for _, event := range events {
+		var createdAt string
+		if event.CreatedAt != nil {
+			createdAt = event.CreatedAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(c.stdout, "%s\t%s\t%s\t%s\t%s\n", createdAt, event.Actor, event.Action, event.Target, event.Role)
+	}
+	return nil
+}
+
+// generated defines the JSON output of the gen command.
+type generated struct {
+	Secret  string  `json:"secret"`
+	Entropy float64 `json:"entropy"`
+}
+
+// generate prints a random password or passphrase, its estimated entropy goes to stderr.
+func (c *CLI) generate(args []string) error {
+	fs := c.newFlagSet("gen")
+	length := fs.Int("length", generator.DefaultPolicy.Length, "password length")
+	noLower := fs.Bool("no-lower", false, "exclude lowercase letters")
+	noUpper := fs.Bool("no-upper", false, "exclude uppercase letters")
+	noDigits := fs.Bool("no-digits", false, "exclude digits")
+	noSymbols := fs.Bool("no-symbols", false, "exclude symbols")
+	noAmbiguous := fs.Bool("no-ambiguous", false, "exclude ambiguous characters such as 0, O, 1, l and I")
+	passphrase := fs.Bool("passphrase", false, "generate a passphrase of random words instead")
+	words := fs.Int("words", generator.DefaultPassphrasePolicy.Words, "number of passphrase words")
+	separator := fs.String("separator", generator.DefaultPassphrasePolicy.Separator, "passphrase word separator")
+	capitalize := fs.Bool("capitalize", false, "capitalize passphrase words")
+	number := fs.Bool("number", false, "append a digit to the passphrase")
+	asJSON := fs.Bool("json", false, "print JSON")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 0 {
+		return fmt.Errorf("unexpected arguments %v", positional)
+	}
+	g := generator.InitGenerator()
+	var result generated
+	if *passphrase {
+		policy := generator.PassphrasePolicy{Words: *words, Separator: *separator, Capitalize: *capitalize, Number: *number}
+		result.Secret, err = g.Passphrase(policy)
+		result.Entropy = policy.Entropy()
+	} else {
+		policy := generator.Policy{
+			Length:           *length,
+			Lower:            !*noLower,
+			Upper:            !*noUpper,
+			Digits:           !*noDigits,
+			Symbols:          !*noSymbols,
+			ExcludeAmbiguous: *noAmbiguous,
+		}
+		result.Secret, err = g.Password(policy)
+		result.Entropy = policy.Entropy()
+	}
+	if err != nil {
+		return err
+	}
+	result.Entropy = math.Round(result.Entropy*10) / 10
+	if *asJSON {
+		return c.writeJSON(result)
+	}
+	fmt.Fprintln(c.stdout, result.Secret)
+	fmt.Fprintf(c.stderr, "Estimated entropy: %.1f bits\n", result.Entropy)
+	return nil
+}
+
+// audit prints a password health report of login/password entries.
+func (c *CLI) audit(args []string) error {
+	fs := c.newFlagSet("audit")
+	minEntropy := fs.Float64("min-entropy", health.DefaultOptions.MinEntropy, "estimated entropy in bits below which a password is weak")
+	maxAge := fs.Int("max-age", health.DefaultOptions.MaxAgeDays, "number of days after which an unchanged password is old, 0 disables the check")
+	corpus := fs.String("breaches", c.cfg.BreachCorpus, "Pwned Passwords file or directory of range files to check passwords against")
+	asJSON := fs.Bool("json", false, "print JSON")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 0 {
+		return fmt.Errorf("unexpected arguments %v", positional)
+	}
+	if *maxAge < 0 {
+		return errors.New("max age must not be negative")
+	}
+	options := health.Options{MinEntropy: *minEntropy, MaxAgeDays: *maxAge}
+	if *corpus != "" {
+		checker, err := breachV1.InitOfflineChecker(*corpus, c.logger)
+		if err != nil {
+			return err
+		}
+		defer checker.Close()
+		options.Checker = checker
+	}
+	if err := c.restore(); err != nil {
+		return err
+	}
+	report, err := health.Audit(c.storage.Export(), options)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return c.writeJSON(report)
+	}
+	fmt.Fprint(c.stdout, health.Format(report))
+	return nil
+}
+
+// export prints all entries as JSON or writes them to a file readable by its owner only.
+func (c *CLI) export(args []string) error {
+	fs := c.newFlagSet("export")
+	output := fs.String("o", "", "output file path")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 0 {
+		return fmt.Errorf("unexpected arguments %v", positional)
+	}
+	if err := c.restore(); err != nil {
+		return err
+	}
+	batch := c.storage.Export()
+	if *output == "" {
+		return c.writeJSON(batch)
+	}
+	data, err := json.MarshalIndent(batch, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(*output, append(data, '\n'), 0600)
+}
+
+// importData imports entries exported by other password managers from a file or stdin.
+func (c *CLI) importData(args []string) error {
+	fs := c.newFlagSet("import")
+	format := fs.String("format", "", "export format")
+	dryRun := fs.Bool("dry-run", false, "report entries without uploading them")
+	asJSON := fs.Bool("json", false, "print JSON")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return errors.New("input file path (or - for stdin) is required")
+	}
+	var r io.Reader = c.stdin
+	if positional[0] != "-" {
+		f, err := os.Open(positional[0])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+	if err := c.restore(); err != nil {
+		return err
+	}
+	run := c.importer.Import
+	if *dryRun {
+		run = c.importer.Preview
+	}
+	report, err := run(*format, r)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return c.writeJSON(report)
+	}
+	fmt.Fprintf(c.stdout, "new: %d\nduplicates: %d\nfailed: %d\n", len(report.New), len(report.Duplicates), len(report.Failed))
+	for _, item := range report.Failed {
+		fmt.Fprintf(c.stdout, "failed\t%s\t%s\t%s\n", item.Db, item.Identifier, item.Reason)
+	}
+	return nil
+}
+
+// run executes a command with secret references in its environment resolved, the environment is composed of
+// the CLI environment, the env file and -e flags, later ones taking precedence.
+func (c *CLI) run(args []string) error {
+	fs := c.newFlagSet("run")
+	envFile := fs.String("env-file", "", "file with NAME=value pairs")
+	noMask := fs.Bool("no-mask", false, "do not mask secrets in the command output")
+	var env envFlags
+	fs.Var(&env, "e", "NAME=value pair, may be repeated")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	command := fs.Args()
+	if len(command) == 0 {
+		return errors.New("command is required after --")
+	}
+	environ := os.Environ()
+	if *envFile != "" {
+		f, err := os.Open(*envFile)
+		if err != nil {
+			return err
+		}
+		fileEnv, err := secretref.ReadEnvFile(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", *envFile, err)
+		}
+		environ = append(environ, fileEnv...)
+	}
+	environ = append(environ, env...)
+	if err := c.restore(); err != nil {
+		return err
+	}
+	resolved, secrets, err := secretref.InitResolver(c.storage, c.cfg).ResolveEnv(environ)
+	if err != nil {
+		return err
+	}
+	// os/exec keeps the last value of duplicate names, the raw stdin lets a terminal be passed to the command as is
+	code, err := runner.InitRunner(c.rawStdin, c.stdout, c.stderr, c.logger).Run(context.Background(), resolved, secrets, command, !*noMask)
+	if err != nil {
+		return err
+	}
+	if code != 0 {
+		return &ExitError{Code: code}
+	}
+	return nil
+}
+
+// render renders a template to stdout or a file, or reports references which cannot be resolved.
+func (c *CLI) render(args []string) error {
+	fs := c.newFlagSet("render")
+	check := fs.Bool("check", false, "report references which cannot be resolved without rendering")
+	asJSON := fs.Bool("json", false, "print JSON check report")
+	output := fs.String("o", "", "output file path")
+	mode := fs.String("mode", fmt.Sprintf("%#o", renderer.DefaultMode), "output file permissions")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return errors.New("template file path is required")
+	}
+	perm, err := strconv.ParseUint(*mode, 8, 32)
+	if err != nil {
+		return fmt.Errorf("invalid mode %s", *mode)
+	}
+	text, err := os.ReadFile(positional[0])
+	if err != nil {
+		return err
+	}
+	if err := c.restore(); err != nil {
+		return err
+	}
+	r := renderer.InitRenderer(secretref.InitResolver(c.storage, c.cfg), c.logger)
+	switch {
+	case *check:
+		problems, err := r.Check(filepath.Base(positional[0]), string(text))
+		if err != nil {
+			return err
+		}
+		if *asJSON {
+			if problems == nil {
+				problems = []renderer.Problem{}
+			}
+			if err := c.writeJSON(problems); err != nil {
+				return err
+			}
+		} else {
+			for _, problem := range problems {
+				fmt.Fprintf(c.stdout, "%s\t%s\n", problem.Reference, problem.Reason)
+			}
+		}
+		if len(problems) != 0 {
+			return fmt.Errorf("%d references cannot be resolved", len(problems))
+		}
+		return nil
+	case *output != "":
+		return r.RenderFile(positional[0], *output, os.FileMode(perm))
+	default:
+		return r.Render(filepath.Base(positional[0]), string(text), c.stdout)
+	}
+}
+
+// restore applies the cached session and synchronizes local storage with the server.
+func (c *CLI) restore() error {
+	if c.attached() {
+		// the agent holds the session and fails if it is locked
+		return c.storage.Sync()
+	}
+	token, sessionKey, err := c.session.Load(c.cfg.ServerAddress)
+	if err != nil {
+		return err
+	}
+	c.client.SetToken(token, sessionKey)
+	if err := c.storage.Sync(); err != nil {
+		if status.Code(err) == codes.Unauthenticated {
+			_ = c.session.Clear()
+			return errors.New("session expired, log in again")
+		}
+		return err
+	}
+	return nil
+}
+
+// db maps a type name given on the command line to a DB identifier.
+func (c *CLI) db(typeName string) (string, error) {
+	switch c.typeName(typeName) {
+	case typeCard:
+		return c.cfg.BankCardDB, nil
+	case typeLogin:
+		return c.cfg.LoginPasswordDB, nil
+	case typeText:
+		return c.cfg.TextBinaryDB, nil
+	}
+	return "", fmt.Errorf("invalid type %s, must be one of %s, %s, %s", typeName, typeCard, typeLogin, typeText)
+}
+
+// typeName normalizes a type name, DB identifiers are accepted as well.
+func (c *CLI) typeName(name string) string {
+	switch name {
+	case c.cfg.BankCardDB:
+		return typeCard
+	case c.cfg.LoginPasswordDB:
+		return typeLogin
+	case c.cfg.TextBinaryDB:
+		return typeText
+	}
+	return name
+}
+
+// readLine reads a single line from stdin without its line ending.
+func (c *CLI) readLine() (string, error) {
+	line, err := c.stdin.ReadString('\n')
+	if err != nil && !(errors.Is(err, io.EOF) && line != "") {
+		return "", errors.New("could not read secret from stdin")
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// writeJSON prints an indented JSON representation of a value.
+func (c *CLI) writeJSON(value interface{}) error {
+	encoder := json.NewEncoder(c.stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
+
+// newFlagSet creates a flag set for a subcommand.
+func (c *CLI) newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	return fs
+}
+
+// parseArgs parses flags interleaved with positional arguments and returns the latter.
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
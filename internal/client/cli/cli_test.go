@@ -0,0 +1,444 @@
+package cli
+
+import (
+	"bytes"
+	"dk-go-gophkeeper/internal/client/health"
+	importer "dk-go-gophkeeper/internal/client/importer/v1"
+	keyring "dk-go-gophkeeper/internal/client/keyring/v1"
+	session "dk-go-gophkeeper/internal/client/session/v1"
+	"dk-go-gophkeeper/internal/client/storage/inmemory"
+	"dk-go-gophkeeper/internal/client/storage/modelstorage"
+	"dk-go-gophkeeper/internal/config"
+	"dk-go-gophkeeper/internal/keys"
+	"dk-go-gophkeeper/internal/mocks"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type testCLI struct {
+	cli    *CLI
+	client *mocks.MockGRPCClient
+	keeper *session.FileKeeper
+	// publicKey is the key published by the client
+	publicKey string
+	stdout    *bytes.Buffer
+	stderr    *bytes.Buffer
+	cfg       *config.Config
+}
+
+func newTestCLI(t *testing.T, stdin string) *testCLI {
+	cfg := config.NewDefaultConfiguration()
+	cfg.ServerAddress = ":8080"
+	cfg.BankCardDB = "bankCard"
+	cfg.LoginPasswordDB = "loginPassword"
+	cfg.TextBinaryDB = "textBinary"
+	cfg.SessionPath = filepath.Join(t.TempDir(), "session.json")
+	cfg.KeyringPath = filepath.Join(t.TempDir(), "keyring.json")
+	ctrl := gomock.NewController(t)
+	client := mocks.NewMockGRPCClient(ctrl)
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	keeper, err := session.InitFileKeeper(&logger, cfg)
+	assert.Equal(t, nil, err)
+	keyringInstance, err := keyring.InitFileKeyring(&logger, cfg)
+	assert.Equal(t, nil, err)
+	st := inmemory.InitStorage(&logger, client, keyringInstance, cfg)
+	imp := importer.InitImporter(st, &logger, cfg)
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	c := InitCLI(st, client, keeper, imp, strings.NewReader(stdin), stdout, stderr, &logger, cfg)
+	tc := &testCLI{cli: c, client: client, keeper: keeper, stdout: stdout, stderr: stderr, cfg: cfg}
+	// users have no trusted contacts, so syncing seals no entries for them
+	client.EXPECT().ListEmergencyAccess().Return(modelstorage.EmergencyAccessList{}, codes.OK, nil).AnyTimes()
+	// the server keeps the public key the client publishes
+	client.EXPECT().GetPublicKey("").DoAndReturn(func(string) (string, codes.Code, error) {
+		if tc.publicKey == "" {
+			return "", codes.NotFound, status.Error(codes.NotFound, "no public key is published")
+		}
+		return tc.publicKey, codes.OK, nil
+	}).AnyTimes()
+	client.EXPECT().SetPublicKey(gomock.Any()).DoAndReturn(func(publicKey string) (codes.Code, error) {
+		tc.publicKey = publicKey
+		return codes.OK, nil
+	}).AnyTimes()
+	return tc
+}
+
+// expectSync sets up a session and server-side data returned upon syncing.
+func (tc *testCLI) expectSync(t *testing.T, loginsPasswords map[string]modelstorage.LoginAndPassword) {
+	assert.Equal(t, nil, tc.keeper.Save(tc.cfg.ServerAddress, "some_token", "some_session"))
+	tc.client.EXPECT().SetToken("some_token", "some_session")
+	tc.client.EXPECT().GetBankCards().Return(map[string]modelstorage.BankCard{}, codes.OK, nil)
+	tc.client.EXPECT().GetLoginsPasswords().Return(loginsPasswords, codes.OK, nil)
+	tc.client.EXPECT().GetTextsBinaries().Return(map[string]modelstorage.TextOrBinary{}, codes.OK, nil)
+}
+
+func TestCLI_Run(t *testing.T) {
+	tc := newTestCLI(t, "")
+	assert.Equal(t, "no command given", tc.cli.Run(nil).Error())
+	assert.Equal(t, "unknown command generic_command", tc.cli.Run([]string{"generic_command"}).Error())
+}
+
+func TestCLI_Login(t *testing.T) {
+	tc := newTestCLI(t, "password\n")
+	tc.client.EXPECT().Login(modelstorage.RegisterLogin{Login: "user", Password: "password"}).Return(codes.OK, nil)
+	tc.client.EXPECT().Token().Return("some_token", "some_session")
+	err := tc.cli.Run([]string{"login", "-u", "user"})
+	assert.Equal(t, nil, err)
+	token, sessionKey, err := tc.keeper.Load(":8080")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "some_token", token)
+	assert.Equal(t, "some_session", sessionKey)
+
+	err = tc.cli.Run([]string{"logout"})
+	assert.Equal(t, nil, err)
+	_, _, err = tc.keeper.Load(":8080")
+	assert.Equal(t, session.ErrNoSession, err)
+}
+
+func TestCLI_LoginNoPassword(t *testing.T) {
+	tc := newTestCLI(t, "")
+	err := tc.cli.Run([]string{"login", "-u", "user"})
+	assert.Equal(t, "could not read secret from stdin", err.Error())
+}
+
+func TestCLI_NoSession(t *testing.T) {
+	tc := newTestCLI(t, "")
+	err := tc.cli.Run([]string{"ls"})
+	assert.Equal(t, session.ErrNoSession, err)
+}
+
+func TestCLI_ExpiredSession(t *testing.T) {
+	tc := newTestCLI(t, "")
+	assert.Equal(t, nil, tc.keeper.Save(":8080", "some_token", "some_session"))
+	tc.client.EXPECT().SetToken("some_token", "some_session")
+	tc.client.EXPECT().GetBankCards().Return(nil, codes.Unauthenticated, status.Error(codes.Unauthenticated, "generic_error")).AnyTimes()
+	tc.client.EXPECT().GetLoginsPasswords().Return(nil, codes.Unauthenticated, status.Error(codes.Unauthenticated, "generic_error")).AnyTimes()
+	tc.client.EXPECT().GetTextsBinaries().Return(nil, codes.Unauthenticated, status.Error(codes.Unauthenticated, "generic_error")).AnyTimes()
+	err := tc.cli.Run([]string{"sync"})
+	assert.Equal(t, "session expired, log in again", err.Error())
+	_, _, err = tc.keeper.Load(":8080")
+	assert.Equal(t, session.ErrNoSession, err)
+}
+
+func TestCLI_ListGet(t *testing.T) {
+	tc := newTestCLI(t, "")
+	tc.expectSync(t, map[string]modelstorage.LoginAndPassword{
+		"github": {Identifier: "github", Login: "user", Password: "pass", Meta: "work"},
+	})
+	err := tc.cli.Run([]string{"ls", "-json", "login"})
+	assert.Equal(t, nil, err)
+	assert.JSONEq(t, `[{"type": "login", "identifier": "github"}]`, tc.stdout.String())
+
+	tc.stdout.Reset()
+	tc.expectSync(t, map[string]modelstorage.LoginAndPassword{
+		"github": {Identifier: "github", Login: "user", Password: "pass", Meta: "work"},
+	})
+	err = tc.cli.Run([]string{"get", "login", "github", "-json"})
+	assert.Equal(t, nil, err)
+	assert.JSONEq(t, `{"identifier": "github", "login": "user", "password": "pass", "meta": "work"}`, tc.stdout.String())
+
+	tc.stdout.Reset()
+	tc.expectSync(t, map[string]modelstorage.LoginAndPassword{
+		"github": {Identifier: "github", Login: "user", Password: "pass", Meta: "work"},
+	})
+	err = tc.cli.Run([]string{"get", "-field", "password", "login", "github"})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "pass\n", tc.stdout.String())
+
+	// every invocation starts with empty local storage
+	tc = newTestCLI(t, "")
+	tc.expectSync(t, map[string]modelstorage.LoginAndPassword{})
+	err = tc.cli.Run([]string{"get", "login", "github"})
+	assert.Equal(t, "entry ID github of type login does not exist", err.Error())
+
+	err = tc.cli.Run([]string{"get", "generic_type", "github"})
+	assert.Equal(t, "invalid type generic_type, must be one of card, login, text", err.Error())
+}
+
+func TestCLI_GetCustomFields(t *testing.T) {
+	tc := newTestCLI(t, "")
+	fields := []modelstorage.CustomField{{Name: "PIN", Value: "1234", Concealed: true}, {Name: "login", Value: "shadowed"}}
+	tc.expectSync(t, map[string]modelstorage.LoginAndPassword{
+		"bank": {Identifier: "bank", Login: "user", Password: "pass", Fields: fields},
+	})
+	err := tc.cli.Run([]string{"get", "login", "bank"})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "identifier: bank\nlogin: user\npassword: pass\nmeta: \nPIN: 1234\n", tc.stdout.String())
+}
+
+func TestCLI_AddRemove(t *testing.T) {
+	tc := newTestCLI(t, "4111111111111111\n123\n")
+	tc.expectSync(t, map[string]modelstorage.LoginAndPassword{})
+	tc.client.EXPECT().SendBankCard(modelstorage.BankCard{Identifier: "visa", Number: "4111111111111111", Holder: "JOHN DOE", Cvv: "123"}).Return(codes.OK, nil)
+	err := tc.cli.Run([]string{"add", "card", "visa", "-holder", "JOHN DOE"})
+	assert.Equal(t, nil, err)
+
+	tc.expectSync(t, map[string]modelstorage.LoginAndPassword{"github": {Identifier: "github"}})
+	tc.client.EXPECT().RemoveLoginPassword("github").Return(codes.OK, nil)
+	err = tc.cli.Run([]string{"rm", "login", "github"})
+	assert.Equal(t, nil, err)
+}
+
+func TestCLI_BankCard(t *testing.T) {
+	tc := newTestCLI(t, "3782 822463 10005\n1234\n0000\n")
+	tc.expectSync(t, map[string]modelstorage.LoginAndPassword{})
+	bankCard := modelstorage.BankCard{Identifier: "amex", Number: "378282246310005", Holder: "JOHN DOE", Cvv: "1234", Expiry: "01/20", Pin: "0000"}
+	tc.client.EXPECT().SendBankCard(bankCard).Return(codes.OK, nil)
+	err := tc.cli.Run([]string{"add", "card", "amex", "-holder", "JOHN DOE", "-expiry", "01/2020"})
+	assert.Equal(t, nil, err)
+
+	tc.client.EXPECT().SetToken("some_token", "some_session")
+	tc.client.EXPECT().GetBankCards().Return(map[string]modelstorage.BankCard{"amex": bankCard}, codes.OK, nil)
+	tc.client.EXPECT().GetLoginsPasswords().Return(map[string]modelstorage.LoginAndPassword{}, codes.OK, nil)
+	tc.client.EXPECT().GetTextsBinaries().Return(map[string]modelstorage.TextOrBinary{}, codes.OK, nil)
+	err = tc.cli.Run([]string{"get", "card", "amex"})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "identifier: amex\nnumber: 378282246310005\nholder: JOHN DOE\ncvv: 1234\nexpiry: 01/20\npin: 0000\nmeta: \n", tc.stdout.String())
+	assert.Equal(t, "Warning: card expired at the end of 01/2020\n", tc.stderr.String())
+
+	tc = newTestCLI(t, "4111111111111112\n12\n")
+	tc.expectSync(t, map[string]modelstorage.LoginAndPassword{})
+	err = tc.cli.Run([]string{"add", "card", "visa"})
+	assert.Equal(t, "card number fails the checksum, check it for typos; card security code must consist of 3 digits", err.Error())
+}
+
+func TestCLI_Labels(t *testing.T) {
+	tc := newTestCLI(t, "")
+	tc.expectSync(t, map[string]modelstorage.LoginAndPassword{"github": {Identifier: "github"}})
+	labels := modelstorage.Labels{Folder: "Work/Dev", Tags: []string{"code", "oss"}, Favorite: true}
+	tc.client.EXPECT().SendBatch(modelstorage.Batch{LoginsPasswords: []modelstorage.LoginAndPassword{{Identifier: "github", Labels: labels}}}, true).
+		Return([]modelstorage.BatchItemResult{{Identifier: "github", Db: "loginPassword"}}, codes.OK, nil)
+	tc.client.EXPECT().GetShares().Return(nil, codes.OK, nil)
+	err := tc.cli.Run([]string{"label", "-folder", "Work/Dev", "-tag", "code", "-tag", "OSS", "-favorite", "login", "github"})
+	assert.Equal(t, nil, err)
+
+	tc.expectSync(t, map[string]modelstorage.LoginAndPassword{
+		"github": {Identifier: "github", Labels: labels},
+		"gitlab": {Identifier: "gitlab", Labels: modelstorage.Labels{Folder: "Personal"}},
+	})
+	err = tc.cli.Run([]string{"ls", "-json", "-folder", "work", "-tag", "code", "-favorites"})
+	assert.Equal(t, nil, err)
+	assert.JSONEq(t, `[{"type": "login", "identifier": "github", "folder": "Work/Dev", "tags": ["code", "oss"], "favorite": true}]`, tc.stdout.String())
+
+	err = tc.cli.Run([]string{"label", "login"})
+	assert.Equal(t, "type and identifier are required", err.Error())
+}
+
+func TestCLI_Generate(t *testing.T) {
+	tc := newTestCLI(t, "")
+	err := tc.cli.Run([]string{"gen", "-length", "32", "-no-symbols", "-no-ambiguous"})
+	assert.Equal(t, nil, err)
+	password := strings.TrimSuffix(tc.stdout.String(), "\n")
+	assert.Equal(t, 32, len(password))
+	assert.Equal(t, false, strings.ContainsAny(password, "0O1lI!#$%"))
+
+	tc.stdout.Reset()
+	err = tc.cli.Run([]string{"gen", "-passphrase", "-words", "5", "-separator", ".", "-json"})
+	assert.Equal(t, nil, err)
+	var result generated
+	assert.Equal(t, nil, json.Unmarshal(tc.stdout.Bytes(), &result))
+	assert.Equal(t, 5, len(strings.Split(result.Secret, ".")))
+	assert.Equal(t, 64.6, result.Entropy)
+
+	err = tc.cli.Run([]string{"gen", "-no-lower", "-no-upper", "-no-digits", "-no-symbols"})
+	assert.Equal(t, "at least one character class must be enabled", err.Error())
+}
+
+func TestCLI_Audit(t *testing.T) {
+	tc := newTestCLI(t, "")
+	changed := time.Now().Add(-400 * 24 * time.Hour)
+	tc.expectSync(t, map[string]modelstorage.LoginAndPassword{
+		"github": {Identifier: "github", Login: "user", Password: "letmein", PasswordChangedAt: &changed},
+		"gitlab": {Identifier: "gitlab", Login: "user", Password: "letmein"},
+	})
+	err := tc.cli.Run([]string{"audit", "-json"})
+	assert.Equal(t, nil, err)
+	var report health.Report
+	assert.Equal(t, nil, json.Unmarshal(tc.stdout.Bytes(), &report))
+	assert.Equal(t, 2, report.Checked)
+	assert.Equal(t, 2, report.Weak)
+	assert.Equal(t, 2, report.Reused)
+	assert.Equal(t, 1, report.Old)
+	assert.Equal(t, []string{health.IssueWeak, health.IssueReused, health.IssueOld}, report.Findings[0].Issues)
+	assert.Equal(t, false, strings.Contains(tc.stdout.String(), "letmein"))
+
+	err = tc.cli.Run([]string{"audit", "-max-age", "-1"})
+	assert.Equal(t, "max age must not be negative", err.Error())
+
+	// SHA-1 of letmein
+	corpus := filepath.Join(t.TempDir(), "pwned-passwords.txt")
+	assert.Equal(t, nil, os.WriteFile(corpus, []byte("B7A875FC1EA228B9061041B7CEC4BD3C52AB3CE3:4\n"), 0600))
+	tc = newTestCLI(t, "")
+	tc.expectSync(t, map[string]modelstorage.LoginAndPassword{"github": {Identifier: "github", Login: "user", Password: "letmein"}})
+	err = tc.cli.Run([]string{"audit", "-breaches", corpus, "-json"})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, nil, json.Unmarshal(tc.stdout.Bytes(), &report))
+	assert.Equal(t, 1, report.Breached)
+	assert.Equal(t, 4, report.Findings[0].Breaches)
+}
+
+func TestCLI_Export(t *testing.T) {
+	tc := newTestCLI(t, "")
+	tc.expectSync(t, map[string]modelstorage.LoginAndPassword{"github": {Identifier: "github", Login: "user", Password: "pass"}})
+	output := filepath.Join(t.TempDir(), "export.json")
+	err := tc.cli.Run([]string{"export", "-o", output})
+	assert.Equal(t, nil, err)
+	info, err := os.Stat(output)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	data, err := os.ReadFile(output)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, strings.Contains(string(data), `"password": "pass"`))
+}
+
+func TestCLI_Attached(t *testing.T) {
+	cfg := config.NewDefaultConfiguration()
+	cfg.BankCardDB = "bankCard"
+	cfg.LoginPasswordDB = "loginPassword"
+	cfg.TextBinaryDB = "textBinary"
+	ctrl := gomock.NewController(t)
+	client := mocks.NewMockGRPCClient(ctrl)
+	keeper := mocks.NewMockKeeper(ctrl)
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	st := inmemory.InitStorage(&logger, client, keeper, cfg)
+	stdout := &bytes.Buffer{}
+	c := InitAttachedCLI(st, importer.InitImporter(st, &logger, cfg), strings.NewReader("password\n"), stdout, &bytes.Buffer{}, &logger, cfg)
+
+	// no session is cached when attached, the agent holds it
+	client.EXPECT().Login(gomock.Any()).Return(codes.OK, nil)
+	client.EXPECT().GetPublicKey("").Return("public_key", codes.OK, nil)
+	keeper.EXPECT().PrivateKey("public_key").Return("private_key", nil)
+	err := c.Run([]string{"login", "-u", "user"})
+	assert.Equal(t, nil, err)
+
+	client.EXPECT().GetBankCards().Return(map[string]modelstorage.BankCard{"visa": {Identifier: "visa"}}, codes.OK, nil)
+	client.EXPECT().GetLoginsPasswords().Return(map[string]modelstorage.LoginAndPassword{}, codes.OK, nil)
+	client.EXPECT().GetTextsBinaries().Return(map[string]modelstorage.TextOrBinary{}, codes.OK, nil)
+	client.EXPECT().ListEmergencyAccess().Return(modelstorage.EmergencyAccessList{}, codes.OK, nil)
+	err = c.Run([]string{"ls"})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "card\tvisa\n", stdout.String())
+
+	err = c.Run([]string{"logout"})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, false, st.Exists("visa", cfg.BankCardDB))
+}
+
+func TestCLI_RunCommand(t *testing.T) {
+	tc := newTestCLI(t, "")
+	tc.expectSync(t, map[string]modelstorage.LoginAndPassword{"db": {Identifier: "db", Login: "user", Password: "hunter22"}})
+	err := tc.cli.Run([]string{"run", "-e", "DB_PASSWORD=gk://loginPassword/db/password", "--", "sh", "-c", `echo "password is $DB_PASSWORD"; exit 2`})
+	assert.Equal(t, &ExitError{Code: 2}, err)
+	assert.Equal(t, "password is <concealed by gophkeeper>\n", tc.stdout.String())
+
+	err = tc.cli.Run([]string{"run", "-e", "DB_PASSWORD"})
+	assert.NotEqual(t, nil, err)
+}
+
+func TestCLI_Render(t *testing.T) {
+	dir := t.TempDir()
+	templatePath := filepath.Join(dir, "pgpass.tmpl")
+	assert.Equal(t, nil, os.WriteFile(templatePath, []byte(`*:*:*:{{ secret "gk://login/db/login" }}:{{ secret "gk://login/generic_id/password" }}`), 0600))
+
+	tc := newTestCLI(t, "")
+	tc.expectSync(t, map[string]modelstorage.LoginAndPassword{"db": {Identifier: "db", Login: "user", Password: "hunter22"}})
+	err := tc.cli.Run([]string{"render", "-check", templatePath})
+	assert.Equal(t, "1 references cannot be resolved", err.Error())
+	assert.Equal(t, "gk://login/generic_id/password\tentry generic_id of login does not exist\n", tc.stdout.String())
+
+	assert.Equal(t, nil, os.WriteFile(templatePath, []byte(`*:*:*:{{ secret "gk://login/db/login" }}:{{ secret "gk://login/db/password" }}`), 0600))
+	tc = newTestCLI(t, "")
+	tc.expectSync(t, map[string]modelstorage.LoginAndPassword{"db": {Identifier: "db", Login: "user", Password: "hunter22"}})
+	outputPath := filepath.Join(dir, "pgpass")
+	err = tc.cli.Run([]string{"render", "-o", outputPath, templatePath})
+	assert.Equal(t, nil, err)
+	data, _ := os.ReadFile(outputPath)
+	assert.Equal(t, "*:*:*:user:hunter22", string(data))
+	info, _ := os.Stat(outputPath)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+func TestCLI_Share(t *testing.T) {
+	tc := newTestCLI(t, "")
+	tc.expectSync(t, map[string]modelstorage.LoginAndPassword{"staging": {Identifier: "staging", Login: "db_user", Password: "pass"}})
+	bobPublicKey, bobPrivateKey, _ := keys.NewKeyPair()
+	tc.client.EXPECT().GetPublicKey("bob").Return(bobPublicKey, codes.OK, nil)
+	tc.client.EXPECT().GetShares().Return(nil, codes.OK, nil)
+	var sealed modelstorage.SealedEntry
+	tc.client.EXPECT().ShareEntry("staging", "loginPassword", "bob", modelstorage.PermissionReadWrite, gomock.Any()).DoAndReturn(
+		func(_, _, _, _ string, entry modelstorage.SealedEntry) (codes.Code, error) {
+			sealed = entry
+			return codes.OK, nil
+		})
+	err := tc.cli.Run([]string{"share", "-write", "login", "staging", "bob"})
+	assert.Equal(t, nil, err)
+	// the recipient opens the entry with the own key pair only
+	recordKey, err := keys.UnwrapKey(sealed.WrappedKeys["bob"], bobPublicKey, bobPrivateKey)
+	assert.Equal(t, nil, err)
+	payload, err := keys.OpenRecord(recordKey, sealed.Payload)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, strings.Contains(payload, `"password":"pass"`))
+
+	tc.expectSync(t, map[string]modelstorage.LoginAndPassword{})
+	recordKey, _ = keys.NewRecordKey()
+	payload, _ = keys.SealRecord(recordKey, `{"texts_binaries":[{"identifier":"notes","entry":"text"}]}`)
+	wrappedKey, _ := keys.WrapKey(recordKey, tc.publicKey)
+	tc.client.EXPECT().GetSharedWithMe().Return([]modelstorage.SharedEntry{
+		{ID: 3, Owner: "alice", Permission: modelstorage.PermissionRead, Db: "textBinary", Payload: payload, WrappedKey: wrappedKey},
+	}, codes.OK, nil)
+	err = tc.cli.Run([]string{"shared"})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "text\tnotes\talice\tread\n", tc.stdout.String())
+
+	err = tc.cli.Run([]string{"unshare", "login", "staging"})
+	assert.Equal(t, "type, identifier and user are required", err.Error())
+}
+
+func TestCLI_Collection(t *testing.T) {
+	tc := newTestCLI(t, "")
+	tc.expectSync(t, map[string]modelstorage.LoginAndPassword{})
+	tc.client.EXPECT().CreateCollection("Ops").Return(modelstorage.Collection{ID: "team", Name: "Ops", Role: modelstorage.RoleOwner}, codes.OK, nil)
+	err := tc.cli.Run([]string{"collection", "create", "Ops"})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "team\n", tc.stdout.String())
+
+	tc.stdout.Reset()
+	tc.expectSync(t, map[string]modelstorage.LoginAndPassword{})
+	tc.client.EXPECT().InviteMember("team", "bob", modelstorage.RoleReadOnly).Return(codes.OK, nil)
+	err = tc.cli.Run([]string{"collection", "invite", "-role", "read-only", "team", "bob"})
+	assert.Equal(t, nil, err)
+
+	tc.expectSync(t, map[string]modelstorage.LoginAndPassword{})
+	tc.client.EXPECT().GetMembers("team").Return([]modelstorage.CollectionMember{
+		{Login: "alice", Role: modelstorage.RoleOwner},
+		{Login: "bob", Role: modelstorage.RoleReadOnly},
+	}, codes.OK, nil)
+	err = tc.cli.Run([]string{"collection", "members", "team"})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "alice\towner\nbob\tread-only\n", tc.stdout.String())
+
+	// the server describes rejected membership changes itself
+	tc.expectSync(t, map[string]modelstorage.LoginAndPassword{})
+	tc.client.EXPECT().RemoveMember("team", "alice").Return(codes.FailedPrecondition,
+		status.Error(codes.FailedPrecondition, "the last owner cannot leave a collection, delete it instead"))
+	err = tc.cli.Run([]string{"collection", "remove", "team", "alice"})
+	assert.Equal(t, "the last owner cannot leave a collection, delete it instead", err.Error())
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	tc.expectSync(t, map[string]modelstorage.LoginAndPassword{})
+	err = tc.cli.Run([]string{"collection", "invite", "-role", "superuser", "team", "bob"})
+	assert.Equal(t, "invalid role superuser, expected one of owner, admin, member, read-only", err.Error())
+	err = tc.cli.Run([]string{"collection", "accept", "first"})
+	assert.Equal(t, "invalid invitation first", err.Error())
+	err = tc.cli.Run([]string{"collection", "join"})
+	assert.Equal(t, "unknown collection subcommand join", err.Error())
+}
@@ -0,0 +1,157 @@
+// Package credhelper provides a git credential helper backed by login/password entries.
+package credhelper
+
+import (
+	"dk-go-gophkeeper/internal/client/storage"
+	"dk-go-gophkeeper/internal/client/storage/modelstorage"
+	"dk-go-gophkeeper/internal/config"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+// supported credential helper actions
+const (
+	ActionGet   = "get"
+	ActionStore = "store"
+	ActionErase = "erase"
+)
+
+// Helper defines attributes and methods of a Helper instance.
+type Helper struct {
+	storage storage.DataStorage
+	logger  *zerolog.Logger
+	cfg     *config.Config
+}
+
+// InitHelper initializes a Helper instance operating on synced client storage.
+func InitHelper(st storage.DataStorage, logger *zerolog.Logger, cfg *config.Config) *Helper {
+	logger.Info().Msg("Attempting to initialize credential helper")
+	return &Helper{storage: st, logger: logger, cfg: cfg}
+}
+
+// Run performs a credential helper action reading the request from r and writing the response to w.
+func (h *Helper) Run(action string, r io.Reader, w io.Writer) error {
+	credential, err := ReadCredential(r)
+	if err != nil {
+		return err
+	}
+	if credential.Protocol == "" || credential.Host == "" {
+		return errors.New("protocol and host are required")
+	}
+	h.logger.Info().Msgf("Credential helper %s request for %s", action, credential.URL())
+	switch action {
+	case ActionGet:
+		entry, ok := h.find(credential)
+		if !ok {
+			// an empty response lets git try other helpers or prompt the user
+			return nil
+		}
+		return WriteCredential(w, Credential{Username: entry.Login, Password: entry.Password})
+	case ActionStore:
+		return h.store(credential)
+	case ActionErase:
+		return h.erase(credential)
+	}
+	// unknown actions must be ignored for forward compatibility
+	return nil
+}
+
+// find selects the most specific entry matching the credential host, path and username.
+func (h *Helper) find(credential Credential) (modelstorage.LoginAndPassword, bool) {
+	var best modelstorage.LoginAndPassword
+	bestScore := -1
+	for _, entry := range h.storage.Export().LoginsPasswords {
+		if credential.Username != "" && entry.Login != credential.Username {
+			continue
+		}
+		score := matchScore(entry, credential)
+		if score > bestScore {
+			best, bestScore = entry, score
+		}
+	}
+	return best, bestScore >= 0
+}
+
+// store saves the credential replacing an entry previously stored by the helper for the same URL.
+func (h *Helper) store(credential Credential) error {
+	if credential.Username == "" || credential.Password == "" {
+		return nil
+	}
+	identifier := identifierOf(credential)
+	for _, entry := range h.storage.Export().LoginsPasswords {
+		// git stores credentials after every successful authentication, unchanged ones are kept as is
+		if entry.Identifier == identifier && entry.Login == credential.Username && entry.Password == credential.Password {
+			return nil
+		}
+	}
+	if h.storage.Exists(identifier, h.cfg.LoginPasswordDB) {
+		if err := h.storage.Remove(identifier, h.cfg.LoginPasswordDB); err != nil {
+			return err
+		}
+	}
+	return h.storage.AddLoginPassword(identifier, credential.Username, credential.Password, credential.URL())
+}
+
+// erase removes entries matching the credential which were stored by the helper.
+func (h *Helper) erase(credential Credential) error {
+	identifier := identifierOf(credential)
+	if !h.storage.Exists(identifier, h.cfg.LoginPasswordDB) {
+		return nil
+	}
+	return h.storage.Remove(identifier, h.cfg.LoginPasswordDB)
+}
+
+// identifierOf returns an identifier of an entry stored by the helper.
+func identifierOf(credential Credential) string {
+	return "git:" + credential.URL()
+}
+
+// matchScore reports how specifically an entry matches the credential, a negative score means no match.
+// URLs are taken from the entry meta parts separated by semicolons, the identifier is matched against the host as well.
+func matchScore(entry modelstorage.LoginAndPassword, credential Credential) int {
+	score := -1
+	if strings.EqualFold(entry.Identifier, credential.Host) {
+		score = 0
+	}
+	for _, part := range strings.Split(entry.Meta, ";") {
+		candidate, err := parseURL(strings.TrimSpace(part))
+		if err != nil || !strings.EqualFold(candidate.Host, credential.Host) {
+			continue
+		}
+		if candidate.Protocol != credential.Protocol {
+			continue
+		}
+		entryPath := strings.TrimSuffix(strings.Trim(candidate.Path, "/"), ".git")
+		requestPath := strings.TrimSuffix(strings.Trim(credential.Path, "/"), ".git")
+		// an entry for a path applies to that path and everything below it
+		if entryPath != "" && requestPath != entryPath && !strings.HasPrefix(requestPath, entryPath+"/") {
+			continue
+		}
+		if s := 1 + len(entryPath); s > score {
+			score = s
+		}
+	}
+	return score
+}
+
+// parseURL parses an absolute URL into credential attributes.
+func parseURL(raw string) (Credential, error) {
+	parsed, err := url.Parse(raw)
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return Credential{}, fmt.Errorf("invalid URL %q", raw)
+	}
+	credential := Credential{
+		Protocol: parsed.Scheme,
+		Host:     parsed.Host,
+		Path:     strings.TrimPrefix(parsed.Path, "/"),
+	}
+	if parsed.User != nil {
+		credential.Username = parsed.User.Username()
+	}
+	return credential, nil
+}
@@ -0,0 +1,100 @@
+package credhelper
+
+import (
+	"bytes"
+	"dk-go-gophkeeper/internal/client/storage/inmemory"
+	"dk-go-gophkeeper/internal/client/storage/modelstorage"
+	"dk-go-gophkeeper/internal/config"
+	"dk-go-gophkeeper/internal/mocks"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+)
+
+func newTestHelper(t *testing.T) (*Helper, *inmemory.Storage, *mocks.MockGRPCClient) {
+	cfg := config.NewDefaultConfiguration()
+	cfg.BankCardDB = "bankCard"
+	cfg.LoginPasswordDB = "loginPassword"
+	cfg.TextBinaryDB = "textBinary"
+	ctrl := gomock.NewController(t)
+	client := mocks.NewMockGRPCClient(ctrl)
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	st := inmemory.InitStorage(&logger, client, nil, cfg)
+	return InitHelper(st, &logger, cfg), st, client
+}
+
+func TestReadCredential(t *testing.T) {
+	credential, err := ReadCredential(strings.NewReader("protocol=https\nhost=github.com\npath=org/repo.git\nusername=user\n\nignored=1\n"))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, Credential{Protocol: "https", Host: "github.com", Path: "org/repo.git", Username: "user"}, credential)
+
+	credential, err = ReadCredential(strings.NewReader("url=https://user@example.com:8443/repo\n"))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, Credential{Protocol: "https", Host: "example.com:8443", Path: "repo", Username: "user"}, credential)
+
+	_, err = ReadCredential(strings.NewReader("generic_line\n"))
+	assert.Equal(t, `invalid credential attribute "generic_line"`, err.Error())
+}
+
+func TestHelper_Get(t *testing.T) {
+	helper, st, client := newTestHelper(t)
+	client.EXPECT().SendLoginPassword(gomock.Any()).Return(codes.OK, nil).Times(4)
+	_ = st.AddLoginPassword("github", "user", "generic", "https://github.com; work")
+	_ = st.AddLoginPassword("github-org", "bot", "org", "https://github.com/org")
+	_ = st.AddLoginPassword("gitlab.com", "user2", "gitlab", "")
+	_ = st.AddLoginPassword("other", "user3", "other", "http://github.com")
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "host match", input: "protocol=https\nhost=github.com\n", expected: "username=user\npassword=generic\n"},
+		{name: "path match", input: "protocol=https\nhost=github.com\npath=org/repo.git\n", expected: "username=bot\npassword=org\n"},
+		{name: "path mismatch", input: "protocol=https\nhost=github.com\npath=organization/repo.git\n", expected: "username=user\npassword=generic\n"},
+		{name: "username", input: "protocol=https\nhost=github.com\npath=org/repo.git\nusername=user\n", expected: "username=user\npassword=generic\n"},
+		{name: "identifier match", input: "protocol=https\nhost=gitlab.com\n", expected: "username=user2\npassword=gitlab\n"},
+		{name: "no match", input: "protocol=https\nhost=example.com\n", expected: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := &bytes.Buffer{}
+			err := helper.Run(ActionGet, strings.NewReader(tt.input), output)
+			assert.Equal(t, nil, err)
+			assert.Equal(t, tt.expected, output.String())
+		})
+	}
+
+	err := helper.Run(ActionGet, strings.NewReader("host=github.com\n"), &bytes.Buffer{})
+	assert.Equal(t, "protocol and host are required", err.Error())
+}
+
+func TestHelper_StoreErase(t *testing.T) {
+	helper, st, client := newTestHelper(t)
+	input := "protocol=https\nhost=example.com\nusername=user\npassword=pass\n"
+	client.EXPECT().SendLoginPassword(modelstorage.LoginAndPassword{
+		Identifier: "git:https://example.com", Login: "user", Password: "pass", Meta: "https://example.com",
+	}).Return(codes.OK, nil)
+	err := helper.Run(ActionStore, strings.NewReader(input), &bytes.Buffer{})
+	assert.Equal(t, nil, err)
+	// storing unchanged credentials sends nothing
+	err = helper.Run(ActionStore, strings.NewReader(input), &bytes.Buffer{})
+	assert.Equal(t, nil, err)
+
+	client.EXPECT().RemoveLoginPassword("git:https://example.com").Return(codes.OK, nil)
+	client.EXPECT().SendLoginPassword(gomock.Any()).Return(codes.OK, nil)
+	err = helper.Run(ActionStore, strings.NewReader("protocol=https\nhost=example.com\nusername=user\npassword=new\n"), &bytes.Buffer{})
+	assert.Equal(t, nil, err)
+
+	client.EXPECT().RemoveLoginPassword("git:https://example.com").Return(codes.OK, nil)
+	err = helper.Run(ActionErase, strings.NewReader(input), &bytes.Buffer{})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, false, st.Exists("git:https://example.com", "loginPassword"))
+	err = helper.Run(ActionErase, strings.NewReader(input), &bytes.Buffer{})
+	assert.Equal(t, nil, err)
+}
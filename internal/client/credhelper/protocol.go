@@ -0,0 +1,77 @@
+package credhelper
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Credential defines attributes exchanged with git using the credential helper protocol.
+type Credential struct {
+	Protocol string
+	Host     string
+	Path     string
+	Username string
+	Password string
+}
+
+// ReadCredential reads key=value attribute lines until a blank line or the end of input.
+func ReadCredential(r io.Reader) (Credential, error) {
+	var credential Credential
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" {
+			break
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return Credential{}, fmt.Errorf("invalid credential attribute %q", line)
+		}
+		switch key {
+		case "protocol":
+			credential.Protocol = value
+		case "host":
+			credential.Host = value
+		case "path":
+			credential.Path = value
+		case "username":
+			credential.Username = value
+		case "password":
+			credential.Password = value
+		case "url":
+			parsed, err := parseURL(value)
+			if err != nil {
+				return Credential{}, err
+			}
+			credential.Protocol, credential.Host, credential.Path = parsed.Protocol, parsed.Host, parsed.Path
+			if parsed.Username != "" {
+				credential.Username = parsed.Username
+			}
+		}
+	}
+	return credential, scanner.Err()
+}
+
+// WriteCredential writes non-empty username and password attributes.
+func WriteCredential(w io.Writer, credential Credential) error {
+	var sb strings.Builder
+	if credential.Username != "" {
+		sb.WriteString("username=" + credential.Username + "\n")
+	}
+	if credential.Password != "" {
+		sb.WriteString("password=" + credential.Password + "\n")
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// URL returns a URL built from the credential protocol, host and path.
+func (c Credential) URL() string {
+	u := c.Protocol + "://" + c.Host
+	if c.Path != "" {
+		u += "/" + strings.TrimPrefix(c.Path, "/")
+	}
+	return u
+}
@@ -0,0 +1,226 @@
+// Package generator provides generation of random passwords and diceware-style passphrases.
+package generator
+
+import (
+	"bufio"
+	"crypto/rand"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"strings"
+)
+
+// character classes of generated passwords
+const (
+	lowerLetters = "abcdefghijklmnopqrstuvwxyz"
+	upperLetters = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	digits       = "0123456789"
+	symbols      = "!#$%&()*+-./:;<=>?@[]^_{}~"
+	// ambiguous characters are easily confused with each other when read or typed by hand
+	ambiguous = "0O1lI|"
+)
+
+// password and passphrase size limits
+const (
+	minLength = 4
+	maxLength = 128
+	minWords  = 3
+	maxWords  = 20
+)
+
+// wordListData is the EFF large word list for passphrases, https://www.eff.org/dice (CC BY 3.0 US).
+//
+//go:embed eff_large_wordlist.txt
+var wordListData string
+
+// wordList holds words of the EFF large word list.
+var wordList = parseWordList(wordListData)
+
+// Policy defines parameters of a generated password.
+type Policy struct {
+	Length           int  `json:"length"`
+	Lower            bool `json:"lower"`
+	Upper            bool `json:"upper"`
+	Digits           bool `json:"digits"`
+	Symbols          bool `json:"symbols"`
+	ExcludeAmbiguous bool `json:"exclude_ambiguous"`
+}
+
+// DefaultPolicy is a policy of a 20-character password of all character classes.
+var DefaultPolicy = Policy{Length: 20, Lower: true, Upper: true, Digits: true, Symbols: true}
+
+// PassphrasePolicy defines parameters of a generated passphrase.
+type PassphrasePolicy struct {
+	Words      int    `json:"words"`
+	Separator  string `json:"separator"`
+	Capitalize bool   `json:"capitalize"`
+	Number     bool   `json:"number"`
+}
+
+// DefaultPassphrasePolicy is a policy of a passphrase of six words separated by dashes.
+var DefaultPassphrasePolicy = PassphrasePolicy{Words: 6, Separator: "-"}
+
+// classes returns alphabets of enabled character classes, ambiguous characters are dropped if requested.
+func (p Policy) classes() []string {
+	var classes []string
+	for _, class := range []struct {
+		enabled  bool
+		alphabet string
+	}{{p.Lower, lowerLetters}, {p.Upper, upperLetters}, {p.Digits, digits}, {p.Symbols, symbols}} {
+		if !class.enabled {
+			continue
+		}
+		alphabet := class.alphabet
+		if p.ExcludeAmbiguous {
+			alphabet = strings.Map(func(r rune) rune {
+				if strings.ContainsRune(ambiguous, r) {
+					return -1
+				}
+				return r
+			}, alphabet)
+		}
+		classes = append(classes, alphabet)
+	}
+	return classes
+}
+
+// Validate checks whether a password can be generated according to the policy.
+func (p Policy) Validate() error {
+	if p.Length < minLength || p.Length > maxLength {
+		return fmt.Errorf("password length must be between %d and %d", minLength, maxLength)
+	}
+	classes := p.classes()
+	if len(classes) == 0 {
+		return errors.New("at least one character class must be enabled")
+	}
+	if p.Length < len(classes) {
+		return fmt.Errorf("password length must be at least %d to include every enabled character class", len(classes))
+	}
+	return nil
+}
+
+// Entropy estimates entropy of a password generated according to the policy in bits.
+func (p Policy) Entropy() float64 {
+	return float64(p.Length) * math.Log2(float64(len(strings.Join(p.classes(), ""))))
+}
+
+// Validate checks whether a passphrase can be generated according to the policy.
+func (p PassphrasePolicy) Validate() error {
+	if p.Words < minWords || p.Words > maxWords {
+		return fmt.Errorf("passphrase must consist of %d to %d words", minWords, maxWords)
+	}
+	return nil
+}
+
+// Entropy estimates entropy of a passphrase generated according to the policy in bits, capitalization adds none.
+func (p PassphrasePolicy) Entropy() float64 {
+	entropy := float64(p.Words) * math.Log2(float64(len(wordList)))
+	if p.Number {
+		entropy += math.Log2(float64(len(digits)))
+	}
+	return entropy
+}
+
+// Generator defines attributes and methods of a Generator instance.
+type Generator struct {
+	source io.Reader
+}
+
+// InitGenerator initializes a Generator instance reading randomness from crypto/rand.
+func InitGenerator() *Generator {
+	return &Generator{source: rand.Reader}
+}
+
+// Password generates a password according to the policy, every enabled character class is present in it.
+func (g *Generator) Password(policy Policy) (string, error) {
+	if err := policy.Validate(); err != nil {
+		return "", err
+	}
+	classes := policy.classes()
+	alphabet := strings.Join(classes, "")
+	password := make([]byte, 0, policy.Length)
+	for _, class := range classes {
+		c, err := g.pick(class)
+		if err != nil {
+			return "", err
+		}
+		password = append(password, c)
+	}
+	for len(password) < policy.Length {
+		c, err := g.pick(alphabet)
+		if err != nil {
+			return "", err
+		}
+		password = append(password, c)
+	}
+	// Fisher-Yates shuffle so that mandatory characters are not always in front
+	for i := len(password) - 1; i > 0; i-- {
+		j, err := g.intn(i + 1)
+		if err != nil {
+			return "", err
+		}
+		password[i], password[j] = password[j], password[i]
+	}
+	return string(password), nil
+}
+
+// Passphrase generates a passphrase of random words of the EFF large word list according to the policy.
+func (g *Generator) Passphrase(policy PassphrasePolicy) (string, error) {
+	if err := policy.Validate(); err != nil {
+		return "", err
+	}
+	words := make([]string, 0, policy.Words)
+	for len(words) < policy.Words {
+		idx, err := g.intn(len(wordList))
+		if err != nil {
+			return "", err
+		}
+		word := wordList[idx]
+		if policy.Capitalize {
+			word = strings.ToUpper(word[:1]) + word[1:]
+		}
+		words = append(words, word)
+	}
+	if policy.Number {
+		digit, err := g.pick(digits)
+		if err != nil {
+			return "", err
+		}
+		words[len(words)-1] += string(digit)
+	}
+	return strings.Join(words, policy.Separator), nil
+}
+
+// pick returns a uniformly chosen character of an alphabet.
+func (g *Generator) pick(alphabet string) (byte, error) {
+	idx, err := g.intn(len(alphabet))
+	if err != nil {
+		return 0, err
+	}
+	return alphabet[idx], nil
+}
+
+// intn returns a uniformly chosen number in [0, n).
+func (g *Generator) intn(n int) (int, error) {
+	value, err := rand.Int(g.source, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, err
+	}
+	return int(value.Int64()), nil
+}
+
+// parseWordList parses a word list of lines of dice rolls followed by words.
+func parseWordList(data string) []string {
+	var words []string
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) > 0 {
+			words = append(words, fields[len(fields)-1])
+		}
+	}
+	return words
+}
@@ -0,0 +1,68 @@
+package generator
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerator_Password(t *testing.T) {
+	g := InitGenerator()
+	password, err := g.Password(DefaultPolicy)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 20, len(password))
+
+	policy := Policy{Length: 4, Lower: true, Upper: true, Digits: true, Symbols: true}
+	for i := 0; i < 100; i++ {
+		password, err = g.Password(policy)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, true, strings.ContainsAny(password, lowerLetters))
+		assert.Equal(t, true, strings.ContainsAny(password, upperLetters))
+		assert.Equal(t, true, strings.ContainsAny(password, digits))
+		assert.Equal(t, true, strings.ContainsAny(password, symbols))
+	}
+
+	policy = Policy{Length: 128, Upper: true, Digits: true, ExcludeAmbiguous: true}
+	password, err = g.Password(policy)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, false, strings.ContainsAny(password, ambiguous+lowerLetters+symbols))
+}
+
+func TestPolicy_Validate(t *testing.T) {
+	assert.Equal(t, nil, DefaultPolicy.Validate())
+	assert.Equal(t, "password length must be between 4 and 128", Policy{Length: 3, Lower: true}.Validate().Error())
+	assert.Equal(t, "password length must be between 4 and 128", Policy{Length: 129, Lower: true}.Validate().Error())
+	assert.Equal(t, "at least one character class must be enabled", Policy{Length: 20}.Validate().Error())
+	_, err := InitGenerator().Password(Policy{Length: 20})
+	assert.Equal(t, "at least one character class must be enabled", err.Error())
+}
+
+func TestPolicy_Entropy(t *testing.T) {
+	assert.Equal(t, 10*math.Log2(10), Policy{Length: 10, Digits: true}.Entropy())
+	assert.Equal(t, 10*math.Log2(8), Policy{Length: 10, Digits: true, ExcludeAmbiguous: true}.Entropy())
+	assert.Equal(t, 20*math.Log2(26+26+10+26), DefaultPolicy.Entropy())
+}
+
+func TestGenerator_Passphrase(t *testing.T) {
+	assert.Equal(t, 7776, len(wordList))
+	assert.Equal(t, "abacus", wordList[0])
+	g := InitGenerator()
+	passphrase, err := g.Passphrase(DefaultPassphrasePolicy)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 6, len(strings.Split(passphrase, "-")))
+
+	passphrase, err = g.Passphrase(PassphrasePolicy{Words: 4, Separator: " ", Capitalize: true, Number: true})
+	assert.Equal(t, nil, err)
+	words := strings.Split(passphrase, " ")
+	assert.Equal(t, 4, len(words))
+	for _, word := range words {
+		assert.Equal(t, strings.ToUpper(word[:1]), word[:1])
+	}
+	assert.Equal(t, true, strings.ContainsAny(words[3][len(words[3])-1:], digits))
+
+	_, err = g.Passphrase(PassphrasePolicy{Words: 2})
+	assert.Equal(t, "passphrase must consist of 3 to 20 words", err.Error())
+	assert.Equal(t, 6*math.Log2(7776), DefaultPassphrasePolicy.Entropy())
+}
@@ -7,17 +7,27 @@ import (
 	"dk-go-gophkeeper/internal/client/storage/modelstorage"
 	"dk-go-gophkeeper/internal/config"
 	pb "dk-go-gophkeeper/internal/grpc/proto"
+	"dk-go-gophkeeper/internal/validation"
+	"io"
+	"os"
 	"sync"
+	"time"
 
 	"github.com/rs/zerolog"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
+// build parameters to be used with ldflags
+
+var buildVersion = "NA"
+
 // check for interface compliance
 var (
 	_ grpcclient.GRPCClient = (*GRPCClient)(nil)
@@ -25,13 +35,14 @@ var (
 
 // GRPCClient defines attributes and methods of a GRPCClient instance.
 type GRPCClient struct {
-	token  string
-	md     metadata.MD
-	ctx    context.Context
-	conn   *grpc.ClientConn
-	logger *zerolog.Logger
-	client pb.GophkeeperClient
-	cfg    *config.Config
+	token      string
+	sessionKey string
+	md         metadata.MD
+	ctx        context.Context
+	conn       *grpc.ClientConn
+	logger     *zerolog.Logger
+	client     pb.GophkeeperClient
+	cfg        *config.Config
 }
 
 // InitGRPCClient initializes GRPCClient instance and listens for context cancellation to close it.
@@ -69,7 +80,7 @@ func InitGRPCClient(ctx context.Context, logger *zerolog.Logger, wg *sync.WaitGr
 func (c *GRPCClient) Login(credentials modelstorage.RegisterLogin) (codes.Code, error) {
 	c.logger.Info().Msg("Login attempt received")
 	var header, trailer metadata.MD
-	_, err := c.client.Login(c.ctx, &pb.LoginRegisterRequest{Login: credentials.Login, Password: credentials.Password}, grpc.Header(&header), grpc.Trailer(&trailer))
+	_, err := c.client.Login(c.ctx, c.loginRegisterRequest(credentials), grpc.Header(&header), grpc.Trailer(&trailer))
 	e, ok := status.FromError(err)
 	if err != nil {
 		c.logger.Error().Err(err).Msg("could not execute client request")
@@ -78,18 +89,14 @@ func (c *GRPCClient) Login(credentials modelstorage.RegisterLogin) (codes.Code,
 		}
 		return codes.Unknown, err
 	}
-	token := header.Get(c.cfg.AuthBearerName)
-	md := metadata.New(map[string]string{c.cfg.AuthBearerName: token[0]})
-	c.token = token[0]
-	c.md = md
-	return e.Code(), nil
+	return c.setSession(header)
 }
 
 // Register implements client-side register functionality.
 func (c *GRPCClient) Register(credentials modelstorage.RegisterLogin) (codes.Code, error) {
 	c.logger.Info().Msg("Register attempt received")
 	var header, trailer metadata.MD
-	_, err := c.client.Register(c.ctx, &pb.LoginRegisterRequest{Login: credentials.Login, Password: credentials.Password}, grpc.Header(&header), grpc.Trailer(&trailer))
+	_, err := c.client.Register(c.ctx, c.loginRegisterRequest(credentials), grpc.Header(&header), grpc.Trailer(&trailer))
 	e, ok := status.FromError(err)
 	if err != nil {
 		c.logger.Error().Err(err).Msg("could not execute client request")
@@ -98,11 +105,45 @@ func (c *GRPCClient) Register(credentials modelstorage.RegisterLogin) (codes.Cod
 		}
 		return codes.Unknown, err
 	}
+	return c.setSession(header)
+}
+
+// loginRegisterRequest builds a login or register request naming the device a session is opened from.
+func (c *GRPCClient) loginRegisterRequest(credentials modelstorage.RegisterLogin) *pb.LoginRegisterRequest {
+	deviceName := c.cfg.DeviceName
+	if deviceName == "" {
+		deviceName, _ = os.Hostname()
+	}
+	return &pb.LoginRegisterRequest{Login: credentials.Login, Password: credentials.Password, DeviceName: deviceName, ClientVersion: buildVersion}
+}
+
+// setSession keeps the access token and the session key returned by the server upon login or register.
+func (c *GRPCClient) setSession(header metadata.MD) (codes.Code, error) {
 	token := header.Get(c.cfg.AuthBearerName)
-	md := metadata.New(map[string]string{c.cfg.AuthBearerName: token[0]})
-	c.token = token[0]
-	c.md = md
-	return e.Code(), nil
+	sessionKey := header.Get(c.cfg.SessionHeader)
+	if len(token) == 0 || len(sessionKey) == 0 {
+		err := status.Error(codes.Internal, "server did not return a session")
+		c.logger.Error().Err(err).Msg("could not open session")
+		return codes.Internal, err
+	}
+	c.SetToken(token[0], sessionKey[0])
+	return codes.OK, nil
+}
+
+// Token returns the access token and the session key obtained upon the last successful login or register request.
+func (c *GRPCClient) Token() (string, string) {
+	return c.token, c.sessionKey
+}
+
+// SetToken restores a previously obtained session so that requests can be made without logging in.
+func (c *GRPCClient) SetToken(token, sessionKey string) {
+	c.token = token
+	c.sessionKey = sessionKey
+	c.md = metadata.New(map[string]string{c.cfg.AuthBearerName: token, c.cfg.SessionHeader: sessionKey})
+	// entries of a collection are requested instead of the personal vault once it is selected
+	if c.cfg.Collection != "" {
+		c.md.Set(c.cfg.CollectionHeader, c.cfg.Collection)
+	}
 }
 
 // GetTextsBinaries implements client-side retrieval of texts/binaries from server and storing them in client storage.
@@ -110,25 +151,32 @@ func (c *GRPCClient) GetTextsBinaries() (map[string]modelstorage.TextOrBinary, c
 	c.logger.Info().Msg("Getting texts/binaries attempt received")
 	newCtx := metadata.NewOutgoingContext(c.ctx, c.md)
 	var request emptypb.Empty
-	resp, err := c.client.GetTextsBinaries(newCtx, &request)
-	e, ok := status.FromError(err)
+	stream, err := c.client.StreamTextsBinaries(newCtx, &request)
 	if err != nil {
 		c.logger.Error().Err(err).Msg("could not execute client request")
-		if ok {
-			return nil, e.Code(), err
-		}
-		return nil, codes.Unknown, err
+		return nil, status.Code(err), err
 	}
 	result := make(map[string]modelstorage.TextOrBinary)
-	for _, responsePiece := range resp.ResponsePiecesTextsBinaries {
+	for {
+		responsePiece, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			c.logger.Error().Err(err).Msg("could not receive streamed entry")
+			return nil, status.Code(err), err
+		}
 		resultPiece := modelstorage.TextOrBinary{
 			Identifier: responsePiece.Identifier,
 			Entry:      responsePiece.Entry,
 			Meta:       responsePiece.Meta,
+			Labels:     labelsFromProto(responsePiece.GetLabels()),
+			Fields:     fieldsFromProto(responsePiece.GetFields()),
+			Revision:   revisionFromProto(responsePiece.GetRevision()),
 		}
 		result[responsePiece.Identifier] = resultPiece
 	}
-	return result, e.Code(), nil
+	return result, codes.OK, nil
 }
 
 // GetLoginsPasswords implements client-side retrieval of logins/passwords from server and storing them in client storage.
@@ -136,26 +184,34 @@ func (c *GRPCClient) GetLoginsPasswords() (map[string]modelstorage.LoginAndPassw
 	c.logger.Info().Msg("Getting logins/passwords attempt received")
 	newCtx := metadata.NewOutgoingContext(c.ctx, c.md)
 	var request emptypb.Empty
-	resp, err := c.client.GetLoginsPasswords(newCtx, &request)
-	e, ok := status.FromError(err)
+	stream, err := c.client.StreamLoginsPasswords(newCtx, &request)
 	if err != nil {
 		c.logger.Error().Err(err).Msg("could not execute client request")
-		if ok {
-			return nil, e.Code(), err
-		}
-		return nil, codes.Unknown, err
+		return nil, status.Code(err), err
 	}
 	result := make(map[string]modelstorage.LoginAndPassword)
-	for _, responsePiece := range resp.ResponsePiecesLoginsPasswords {
+	for {
+		responsePiece, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			c.logger.Error().Err(err).Msg("could not receive streamed entry")
+			return nil, status.Code(err), err
+		}
 		resultPiece := modelstorage.LoginAndPassword{
-			Identifier: responsePiece.Identifier,
-			Login:      responsePiece.Login,
-			Password:   responsePiece.Password,
-			Meta:       responsePiece.Meta,
+			Identifier:        responsePiece.Identifier,
+			Login:             responsePiece.Login,
+			Password:          responsePiece.Password,
+			Meta:              responsePiece.Meta,
+			Labels:            labelsFromProto(responsePiece.GetLabels()),
+			Fields:            fieldsFromProto(responsePiece.GetFields()),
+			Revision:          revisionFromProto(responsePiece.GetRevision()),
+			PasswordChangedAt: timestampFromProto(responsePiece.GetRevision().GetPasswordChangedAt()),
 		}
 		result[responsePiece.Identifier] = resultPiece
 	}
-	return result, e.Code(), nil
+	return result, codes.OK, nil
 }
 
 // GetBankCards implements client-side retrieval of bank cards from server and storing them in client storage.
@@ -163,39 +219,48 @@ func (c *GRPCClient) GetBankCards() (map[string]modelstorage.BankCard, codes.Cod
 	c.logger.Info().Msg("Getting bank cards attempt received")
 	newCtx := metadata.NewOutgoingContext(c.ctx, c.md)
 	var request emptypb.Empty
-	resp, err := c.client.GetBankCards(newCtx, &request)
-	e, ok := status.FromError(err)
+	stream, err := c.client.StreamBankCards(newCtx, &request)
 	if err != nil {
 		c.logger.Error().Err(err).Msg("could not execute client request")
-		if ok {
-			return nil, e.Code(), err
-		}
-		return nil, codes.Unknown, err
+		return nil, status.Code(err), err
 	}
 	result := make(map[string]modelstorage.BankCard)
-	for _, responsePiece := range resp.ResponsePiecesBankCards {
+	for {
+		responsePiece, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			c.logger.Error().Err(err).Msg("could not receive streamed entry")
+			return nil, status.Code(err), err
+		}
 		resultPiece := modelstorage.BankCard{
 			Identifier: responsePiece.Identifier,
 			Number:     responsePiece.Number,
 			Holder:     responsePiece.Holder,
 			Cvv:        responsePiece.Cvv,
+			Expiry:     responsePiece.Expiry,
+			Pin:        responsePiece.Pin,
 			Meta:       responsePiece.Meta,
+			Labels:     labelsFromProto(responsePiece.GetLabels()),
+			Fields:     fieldsFromProto(responsePiece.GetFields()),
+			Revision:   revisionFromProto(responsePiece.GetRevision()),
 		}
 		result[responsePiece.Identifier] = resultPiece
 	}
-	return result, e.Code(), nil
+	return result, codes.OK, nil
 }
 
 // SendBankCard implements client-side sending of bank card entry to server and client storage.
 func (c *GRPCClient) SendBankCard(bankCard modelstorage.BankCard) (codes.Code, error) {
 	c.logger.Info().Msg("Sending bank card attempt received")
 	newCtx := metadata.NewOutgoingContext(c.ctx, c.md)
-	_, err := c.client.PostBankCard(newCtx, &pb.SendBankCardRequest{Identifier: bankCard.Identifier, Number: bankCard.Number, Holder: bankCard.Holder, Cvv: bankCard.Cvv, Meta: bankCard.Meta})
+	_, err := c.client.PostBankCard(newCtx, &pb.SendBankCardRequest{Identifier: bankCard.Identifier, Number: bankCard.Number, Holder: bankCard.Holder, Cvv: bankCard.Cvv, Expiry: bankCard.Expiry, Pin: bankCard.Pin, Meta: bankCard.Meta, Labels: labelsToProto(bankCard.Labels), Fields: fieldsToProto(bankCard.Fields)})
 	e, ok := status.FromError(err)
 	if err != nil {
 		c.logger.Error().Err(err).Msg("could not execute client request")
 		if ok {
-			return e.Code(), err
+			return e.Code(), fieldViolations(e, err)
 		}
 		return codes.Unknown, err
 	}
@@ -206,12 +271,12 @@ func (c *GRPCClient) SendBankCard(bankCard modelstorage.BankCard) (codes.Code, e
 func (c *GRPCClient) SendLoginPassword(loginPassword modelstorage.LoginAndPassword) (codes.Code, error) {
 	c.logger.Info().Msg("Sending login/password attempt received")
 	newCtx := metadata.NewOutgoingContext(c.ctx, c.md)
-	_, err := c.client.PostLoginPassword(newCtx, &pb.SendLoginPasswordRequest{Identifier: loginPassword.Identifier, Login: loginPassword.Login, Password: loginPassword.Password, Meta: loginPassword.Meta})
+	_, err := c.client.PostLoginPassword(newCtx, &pb.SendLoginPasswordRequest{Identifier: loginPassword.Identifier, Login: loginPassword.Login, Password: loginPassword.Password, Meta: loginPassword.Meta, Labels: labelsToProto(loginPassword.Labels), Fields: fieldsToProto(loginPassword.Fields)})
 	e, ok := status.FromError(err)
 	if err != nil {
 		c.logger.Error().Err(err).Msg("could not execute client request")
 		if ok {
-			return e.Code(), err
+			return e.Code(), fieldViolations(e, err)
 		}
 		return codes.Unknown, err
 	}
@@ -222,18 +287,63 @@ func (c *GRPCClient) SendLoginPassword(loginPassword modelstorage.LoginAndPasswo
 func (c *GRPCClient) SendTextBinary(textBinary modelstorage.TextOrBinary) (codes.Code, error) {
 	c.logger.Info().Msg("Sending text/binary attempt received")
 	newCtx := metadata.NewOutgoingContext(c.ctx, c.md)
-	_, err := c.client.PostTextBinary(newCtx, &pb.SendTextBinaryRequest{Identifier: textBinary.Identifier, Entry: textBinary.Entry, Meta: textBinary.Meta})
+	_, err := c.client.PostTextBinary(newCtx, &pb.SendTextBinaryRequest{Identifier: textBinary.Identifier, Entry: textBinary.Entry, Meta: textBinary.Meta, Labels: labelsToProto(textBinary.Labels), Fields: fieldsToProto(textBinary.Fields)})
 	e, ok := status.FromError(err)
 	if err != nil {
 		c.logger.Error().Err(err).Msg("could not execute client request")
 		if ok {
-			return e.Code(), err
+			return e.Code(), fieldViolations(e, err)
 		}
 		return codes.Unknown, err
 	}
 	return e.Code(), nil
 }
 
+// SendBatch implements client-side sending of a batch of entries of mixed types to server in a single request, entries
+// already stored on the server are replaced only if overwrite is set.
+func (c *GRPCClient) SendBatch(batch modelstorage.Batch, overwrite bool) ([]modelstorage.BatchItemResult, codes.Code, error) {
+	c.logger.Info().Msg("Sending batch attempt received")
+	newCtx := metadata.NewOutgoingContext(c.ctx, c.md)
+	request := pb.BatchUpsertRequest{Overwrite: overwrite}
+	var dbs []string
+	for _, bankCard := range batch.BankCards {
+		request.Items = append(request.Items, &pb.BatchItem{Item: &pb.BatchItem_BankCard{BankCard: &pb.SendBankCardRequest{Identifier: bankCard.Identifier, Number: bankCard.Number, Holder: bankCard.Holder, Cvv: bankCard.Cvv, Expiry: bankCard.Expiry, Pin: bankCard.Pin, Meta: bankCard.Meta, Labels: labelsToProto(bankCard.Labels), Fields: fieldsToProto(bankCard.Fields)}}})
+		dbs = append(dbs, c.cfg.BankCardDB)
+	}
+	for _, loginPassword := range batch.LoginsPasswords {
+		request.Items = append(request.Items, &pb.BatchItem{Item: &pb.BatchItem_LoginPassword{LoginPassword: &pb.SendLoginPasswordRequest{Identifier: loginPassword.Identifier, Login: loginPassword.Login, Password: loginPassword.Password, Meta: loginPassword.Meta, Labels: labelsToProto(loginPassword.Labels), Fields: fieldsToProto(loginPassword.Fields)}}})
+		dbs = append(dbs, c.cfg.LoginPasswordDB)
+	}
+	for _, textBinary := range batch.TextsBinaries {
+		request.Items = append(request.Items, &pb.BatchItem{Item: &pb.BatchItem_TextBinary{TextBinary: &pb.SendTextBinaryRequest{Identifier: textBinary.Identifier, Entry: textBinary.Entry, Meta: textBinary.Meta, Labels: labelsToProto(textBinary.Labels), Fields: fieldsToProto(textBinary.Fields)}}})
+		dbs = append(dbs, c.cfg.TextBinaryDB)
+	}
+	resp, err := c.client.BatchUpsert(newCtx, &request)
+	e, ok := status.FromError(err)
+	if err != nil {
+		c.logger.Error().Err(err).Msg("could not execute client request")
+		if ok {
+			return nil, e.Code(), err
+		}
+		return nil, codes.Unknown, err
+	}
+	results := make([]modelstorage.BatchItemResult, 0, len(resp.Results))
+	for _, responsePiece := range resp.Results {
+		if int(responsePiece.Index) >= len(dbs) {
+			continue
+		}
+		result := modelstorage.BatchItemResult{
+			Identifier: responsePiece.Identifier,
+			Db:         dbs[responsePiece.Index],
+		}
+		if codes.Code(responsePiece.Code) != codes.OK {
+			result.Err = status.Error(codes.Code(responsePiece.Code), responsePiece.Error)
+		}
+		results = append(results, result)
+	}
+	return results, e.Code(), nil
+}
+
 // RemoveBankCard implements client-side removal of bank card entry from server and client storage.
 func (c *GRPCClient) RemoveBankCard(identifier string) (codes.Code, error) {
 	c.logger.Info().Msg("Removing bank card attempt received")
@@ -281,3 +391,433 @@ func (c *GRPCClient) RemoveTextBinary(identifier string) (codes.Code, error) {
 	}
 	return e.Code(), nil
 }
+
+// SetPublicKey implements client-side publishing of the public key of the key pair the client keeps for the user.
+func (c *GRPCClient) SetPublicKey(publicKey string) (codes.Code, error) {
+	c.logger.Info().Msg("Setting public key attempt received")
+	newCtx := metadata.NewOutgoingContext(c.ctx, c.md)
+	_, err := c.client.SetPublicKey(newCtx, &pb.PublicKey{PublicKey: publicKey})
+	if err != nil {
+		c.logger.Error().Err(err).Msg("could not execute client request")
+		return status.Code(err), err
+	}
+	return codes.OK, nil
+}
+
+// GetPublicKey implements client-side retrieval of a public key published by another user or, for an empty login,
+// by the user.
+func (c *GRPCClient) GetPublicKey(login string) (string, codes.Code, error) {
+	c.logger.Info().Msg("Getting public key attempt received")
+	newCtx := metadata.NewOutgoingContext(c.ctx, c.md)
+	resp, err := c.client.GetPublicKey(newCtx, &pb.GetPublicKeyRequest{Login: login})
+	if err != nil {
+		c.logger.Error().Err(err).Msg("could not execute client request")
+		return "", status.Code(err), err
+	}
+	return resp.GetPublicKey(), codes.OK, nil
+}
+
+// ShareEntry implements client-side sharing of an entry sealed by the client with another user.
+func (c *GRPCClient) ShareEntry(identifier, db, recipient, permission string, sealed modelstorage.SealedEntry) (codes.Code, error) {
+	c.logger.Info().Msg("Sharing entry attempt received")
+	newCtx := metadata.NewOutgoingContext(c.ctx, c.md)
+	_, err := c.client.ShareEntry(newCtx, &pb.ShareEntryRequest{
+		Identifier: identifier,
+		Db:         db,
+		Recipient:  recipient,
+		Permission: permission,
+		Payload:    sealed.Payload,
+		OwnerKey:   sealed.OwnerKey,
+		WrappedKey: sealed.WrappedKeys[recipient],
+	})
+	if err != nil {
+		c.logger.Error().Err(err).Msg("could not execute client request")
+		return status.Code(err), err
+	}
+	return codes.OK, nil
+}
+
+// RevokeShare implements client-side revocation of access of another user to an entry, the entry is resealed by the
+// client for remaining recipients.
+func (c *GRPCClient) RevokeShare(identifier, db, recipient string, sealed modelstorage.SealedEntry) (codes.Code, error) {
+	c.logger.Info().Msg("Revoking share attempt received")
+	newCtx := metadata.NewOutgoingContext(c.ctx, c.md)
+	request := pb.RevokeShareRequest{Identifier: identifier, Db: db, Recipient: recipient, Payload: sealed.Payload, OwnerKey: sealed.OwnerKey}
+	for login, wrappedKey := range sealed.WrappedKeys {
+		request.RecipientKeys = append(request.RecipientKeys, &pb.RecipientKey{Login: login, WrappedKey: wrappedKey})
+	}
+	_, err := c.client.RevokeShare(newCtx, &request)
+	if err != nil {
+		c.logger.Error().Err(err).Msg("could not execute client request")
+		return status.Code(err), err
+	}
+	return codes.OK, nil
+}
+
+// SetSharePayload implements client-side update of an entry the user shares, the update is sealed by the client.
+func (c *GRPCClient) SetSharePayload(identifier, db, payload string) (codes.Code, error) {
+	c.logger.Info().Msg("Setting share payload attempt received")
+	newCtx := metadata.NewOutgoingContext(c.ctx, c.md)
+	_, err := c.client.SetSharePayload(newCtx, &pb.SetSharePayloadRequest{Identifier: identifier, Db: db, Payload: payload})
+	if err != nil {
+		c.logger.Error().Err(err).Msg("could not execute client request")
+		return status.Code(err), err
+	}
+	return codes.OK, nil
+}
+
+// GetShares implements client-side retrieval of entries the user shares with others.
+func (c *GRPCClient) GetShares() ([]modelstorage.Share, codes.Code, error) {
+	c.logger.Info().Msg("Getting shares attempt received")
+	newCtx := metadata.NewOutgoingContext(c.ctx, c.md)
+	resp, err := c.client.GetShares(newCtx, &emptypb.Empty{})
+	if err != nil {
+		c.logger.Error().Err(err).Msg("could not execute client request")
+		return nil, status.Code(err), err
+	}
+	shares := make([]modelstorage.Share, 0, len(resp.Shares))
+	for _, responsePiece := range resp.Shares {
+		share := modelstorage.Share{
+			Identifier:      responsePiece.Identifier,
+			Db:              responsePiece.Db,
+			UpdatedAt:       timestampFromProto(responsePiece.GetUpdatedAt()),
+			ReshareRequired: responsePiece.ReshareRequired,
+			OwnerKey:        responsePiece.OwnerKey,
+		}
+		for _, recipient := range responsePiece.Recipients {
+			share.Recipients = append(share.Recipients, modelstorage.ShareRecipient{Login: recipient.Login, Permission: recipient.Permission, PublicKey: recipient.PublicKey})
+		}
+		shares = append(shares, share)
+	}
+	return shares, codes.OK, nil
+}
+
+// GetSharedWithMe implements client-side retrieval of entries other users share with the user, the entries are sealed.
+func (c *GRPCClient) GetSharedWithMe() ([]modelstorage.SharedEntry, codes.Code, error) {
+	c.logger.Info().Msg("Getting shared entries attempt received")
+	newCtx := metadata.NewOutgoingContext(c.ctx, c.md)
+	resp, err := c.client.GetSharedWithMe(newCtx, &emptypb.Empty{})
+	if err != nil {
+		c.logger.Error().Err(err).Msg("could not execute client request")
+		return nil, status.Code(err), err
+	}
+	entries := make([]modelstorage.SharedEntry, 0, len(resp.Entries))
+	for _, responsePiece := range resp.Entries {
+		entries = append(entries, modelstorage.SharedEntry{
+			ID:         responsePiece.ShareId,
+			Owner:      responsePiece.Owner,
+			Permission: responsePiece.Permission,
+			Db:         responsePiece.Db,
+			UpdatedAt:  timestampFromProto(responsePiece.GetUpdatedAt()),
+			Payload:    responsePiece.Payload,
+			WrappedKey: responsePiece.WrappedKey,
+		})
+	}
+	return entries, codes.OK, nil
+}
+
+// UpdateSharedEntry implements client-side update of an entry shared with the user, the payload is the update sealed
+// with the record key of the entry.
+func (c *GRPCClient) UpdateSharedEntry(entry modelstorage.SharedEntry, payload string) (codes.Code, error) {
+	c.logger.Info().Msg("Updating shared entry attempt received")
+	newCtx := metadata.NewOutgoingContext(c.ctx, c.md)
+	request := pb.UpdateSharedEntryRequest{ShareId: entry.ID, Item: &pb.BatchItem{}, Payload: payload}
+	switch {
+	case entry.BankCard != nil:
+		bankCard := entry.BankCard
+		request.Item.Item = &pb.BatchItem_BankCard{BankCard: &pb.SendBankCardRequest{Identifier: bankCard.Identifier, Number: bankCard.Number, Holder: bankCard.Holder, Cvv: bankCard.Cvv, Expiry: bankCard.Expiry, Pin: bankCard.Pin, Meta: bankCard.Meta, Labels: labelsToProto(bankCard.Labels), Fields: fieldsToProto(bankCard.Fields)}}
+	case entry.LoginPassword != nil:
+		loginPassword := entry.LoginPassword
+		request.Item.Item = &pb.BatchItem_LoginPassword{LoginPassword: &pb.SendLoginPasswordRequest{Identifier: loginPassword.Identifier, Login: loginPassword.Login, Password: loginPassword.Password, Meta: loginPassword.Meta, Labels: labelsToProto(loginPassword.Labels), Fields: fieldsToProto(loginPassword.Fields)}}
+	case entry.TextBinary != nil:
+		textBinary := entry.TextBinary
+		request.Item.Item = &pb.BatchItem_TextBinary{TextBinary: &pb.SendTextBinaryRequest{Identifier: textBinary.Identifier, Entry: textBinary.Entry, Meta: textBinary.Meta, Labels: labelsToProto(textBinary.Labels), Fields: fieldsToProto(textBinary.Fields)}}
+	}
+	_, err := c.client.UpdateSharedEntry(newCtx, &request)
+	if err != nil {
+		c.logger.Error().Err(err).Msg("could not execute client request")
+		return status.Code(err), fieldViolations(status.Convert(err), err)
+	}
+	return codes.OK, nil
+}
+
+// ListAuditEvents implements client-side retrieval of a page of the audit log of the user, the newest events first.
+func (c *GRPCClient) ListAuditEvents(beforeID int64, limit int) ([]modelstorage.AuditEvent, codes.Code, error) {
+	c.logger.Info().Msg("Listing audit events attempt received")
+	newCtx := metadata.NewOutgoingContext(c.ctx, c.md)
+	resp, err := c.client.ListAuditEvents(newCtx, &pb.ListAuditEventsRequest{BeforeId: beforeID, Limit: uint32(limit)})
+	if err != nil {
+		c.logger.Error().Err(err).Msg("could not execute client request")
+		return nil, status.Code(err), err
+	}
+	var events []modelstorage.AuditEvent
+	for _, event := range resp.GetEvents() {
+		events = append(events, modelstorage.AuditEvent{
+			ID:        event.GetId(),
+			Action:    event.GetAction(),
+			Method:    event.GetMethod(),
+			Peer:      event.GetPeer(),
+			Code:      event.GetCode(),
+			CreatedAt: timestampFromProto(event.GetCreatedAt()),
+		})
+	}
+	return events, codes.OK, nil
+}
+
+// ListSessions implements client-side retrieval of open sessions of the user.
+func (c *GRPCClient) ListSessions() ([]modelstorage.Session, codes.Code, error) {
+	c.logger.Info().Msg("Listing sessions attempt received")
+	newCtx := metadata.NewOutgoingContext(c.ctx, c.md)
+	resp, err := c.client.ListSessions(newCtx, &emptypb.Empty{})
+	if err != nil {
+		c.logger.Error().Err(err).Msg("could not execute client request")
+		return nil, status.Code(err), err
+	}
+	var sessions []modelstorage.Session
+	for _, session := range resp.GetSessions() {
+		sessions = append(sessions, modelstorage.Session{
+			ID:            session.GetId(),
+			DeviceName:    session.GetDeviceName(),
+			ClientVersion: session.GetClientVersion(),
+			Peer:          session.GetPeer(),
+			CreatedAt:     timestampFromProto(session.GetCreatedAt()),
+			LastSeenAt:    timestampFromProto(session.GetLastSeenAt()),
+			Current:       session.GetCurrent(),
+		})
+	}
+	return sessions, codes.OK, nil
+}
+
+// RevokeSession implements client-side revocation of a session of the user.
+func (c *GRPCClient) RevokeSession(sessionID int64) (codes.Code, error) {
+	c.logger.Info().Msg("Revoking session attempt received")
+	newCtx := metadata.NewOutgoingContext(c.ctx, c.md)
+	_, err := c.client.RevokeSession(newCtx, &pb.RevokeSessionRequest{Id: sessionID})
+	if err != nil {
+		c.logger.Error().Err(err).Msg("could not execute client request")
+		return status.Code(err), err
+	}
+	return codes.OK, nil
+}
+
+// SetTrustedContact implements client-side designation of a trusted contact of the user.
+func (c *GRPCClient) SetTrustedContact(login string, waitDays int) (codes.Code, error) {
+	c.logger.Info().Msg("Setting trusted contact attempt received")
+	newCtx := metadata.NewOutgoingContext(c.ctx, c.md)
+	_, err := c.client.SetTrustedContact(newCtx, &pb.SetTrustedContactRequest{Login: login, WaitDays: uint32(waitDays)})
+	if err != nil {
+		c.logger.Error().Err(err).Msg("could not execute client request")
+		return status.Code(err), err
+	}
+	return codes.OK, nil
+}
+
+// RemoveTrustedContact implements client-side removal of a trusted contact of the user.
+func (c *GRPCClient) RemoveTrustedContact(login string) (codes.Code, error) {
+	c.logger.Info().Msg("Removing trusted contact attempt received")
+	newCtx := metadata.NewOutgoingContext(c.ctx, c.md)
+	_, err := c.client.RemoveTrustedContact(newCtx, &pb.EmergencyAccessRequest{Login: login})
+	if err != nil {
+		c.logger.Error().Err(err).Msg("could not execute client request")
+		return status.Code(err), err
+	}
+	return codes.OK, nil
+}
+
+// ListEmergencyAccess implements client-side listing of trusted contacts of the user and owners who trust the user.
+func (c *GRPCClient) ListEmergencyAccess() (modelstorage.EmergencyAccessList, codes.Code, error) {
+	c.logger.Info().Msg("Listing emergency access attempt received")
+	newCtx := metadata.NewOutgoingContext(c.ctx, c.md)
+	resp, err := c.client.ListEmergencyAccess(newCtx, &emptypb.Empty{})
+	if err != nil {
+		c.logger.Error().Err(err).Msg("could not execute client request")
+		return modelstorage.EmergencyAccessList{}, status.Code(err), err
+	}
+	var list modelstorage.EmergencyAccessList
+	for _, contact := range resp.GetTrustedContacts() {
+		list.TrustedContacts = append(list.TrustedContacts, emergencyAccessFromProto(contact))
+	}
+	for _, grantor := range resp.GetGrantors() {
+		list.Grantors = append(list.Grantors, emergencyAccessFromProto(grantor))
+	}
+	return list, codes.OK, nil
+}
+
+// RequestEmergencyAccess implements client-side request of emergency access to the vault of an owner.
+func (c *GRPCClient) RequestEmergencyAccess(owner string) (codes.Code, error) {
+	c.logger.Info().Msg("Requesting emergency access attempt received")
+	newCtx := metadata.NewOutgoingContext(c.ctx, c.md)
+	_, err := c.client.RequestEmergencyAccess(newCtx, &pb.EmergencyAccessRequest{Login: owner})
+	if err != nil {
+		c.logger.Error().Err(err).Msg("could not execute client request")
+		return status.Code(err), err
+	}
+	return codes.OK, nil
+}
+
+// ApproveEmergencyAccess implements client-side approval of a request of a trusted contact of the user.
+func (c *GRPCClient) ApproveEmergencyAccess(contact string) (codes.Code, error) {
+	c.logger.Info().Msg("Approving emergency access attempt received")
+	newCtx := metadata.NewOutgoingContext(c.ctx, c.md)
+	_, err := c.client.ApproveEmergencyAccess(newCtx, &pb.EmergencyAccessRequest{Login: contact})
+	if err != nil {
+		c.logger.Error().Err(err).Msg("could not execute client request")
+		return status.Code(err), err
+	}
+	return codes.OK, nil
+}
+
+// RejectEmergencyAccess implements client-side rejection or revocation of emergency access of a trusted contact.
+func (c *GRPCClient) RejectEmergencyAccess(contact string) (codes.Code, error) {
+	c.logger.Info().Msg("Rejecting emergency access attempt received")
+	newCtx := metadata.NewOutgoingContext(c.ctx, c.md)
+	_, err := c.client.RejectEmergencyAccess(newCtx, &pb.EmergencyAccessRequest{Login: contact})
+	if err != nil {
+		c.logger.Error().Err(err).Msg("could not execute client request")
+		return status.Code(err), err
+	}
+	return codes.OK, nil
+}
+
+// SetEmergencyEntries implements client-side upload of entries of the user sealed for trusted contacts of the user.
+func (c *GRPCClient) SetEmergencyEntries(entries []modelstorage.EmergencyEntry) (codes.Code, error) {
+	c.logger.Info().Msg("Setting emergency entries attempt received")
+	newCtx := metadata.NewOutgoingContext(c.ctx, c.md)
+	request := pb.SetEmergencyEntriesRequest{Entries: make([]*pb.EmergencyEntry, 0, len(entries))}
+	for _, entry := range entries {
+		piece := pb.EmergencyEntry{Identifier: entry.Identifier, Db: entry.Db, Payload: entry.Payload}
+		for login, wrappedKey := range entry.WrappedKeys {
+			piece.WrappedKeys = append(piece.WrappedKeys, &pb.RecipientKey{Login: login, WrappedKey: wrappedKey})
+		}
+		request.Entries = append(request.Entries, &piece)
+	}
+	_, err := c.client.SetEmergencyEntries(newCtx, &request)
+	if err != nil {
+		c.logger.Error().Err(err).Msg("could not execute client request")
+		return status.Code(err), err
+	}
+	return codes.OK, nil
+}
+
+// GetEmergencyEntries implements client-side retrieval of entries of the user sealed for trusted contacts along with
+// logins of the contacts they are sealed for.
+func (c *GRPCClient) GetEmergencyEntries() ([]modelstorage.EmergencyEntry, codes.Code, error) {
+	c.logger.Info().Msg("Getting emergency entries attempt received")
+	newCtx := metadata.NewOutgoingContext(c.ctx, c.md)
+	stream, err := c.client.StreamEmergencyEntries(newCtx, &emptypb.Empty{})
+	if err != nil {
+		c.logger.Error().Err(err).Msg("could not execute client request")
+		return nil, status.Code(err), err
+	}
+	return c.receiveEmergencyEntries(stream.Recv)
+}
+
+// GetEmergencyVault implements client-side retrieval of sealed entries of an owner who granted emergency access to the
+// user.
+func (c *GRPCClient) GetEmergencyVault(owner string) ([]modelstorage.EmergencyEntry, codes.Code, error) {
+	c.logger.Info().Msg("Getting emergency vault attempt received")
+	newCtx := metadata.NewOutgoingContext(c.ctx, c.md)
+	stream, err := c.client.StreamEmergencyVault(newCtx, &pb.EmergencyAccessRequest{Login: owner})
+	if err != nil {
+		c.logger.Error().Err(err).Msg("could not execute client request")
+		return nil, status.Code(err), err
+	}
+	return c.receiveEmergencyEntries(stream.Recv)
+}
+
+// receiveEmergencyEntries receives sealed emergency entries of a stream until it ends.
+func (c *GRPCClient) receiveEmergencyEntries(recv func() (*pb.EmergencyEntry, error)) ([]modelstorage.EmergencyEntry, codes.Code, error) {
+	var entries []modelstorage.EmergencyEntry
+	for {
+		piece, err := recv()
+		if err == io.EOF {
+			return entries, codes.OK, nil
+		}
+		if err != nil {
+			c.logger.Error().Err(err).Msg("could not receive streamed entry")
+			return nil, status.Code(err), err
+		}
+		entries = append(entries, modelstorage.EmergencyEntry{
+			Identifier: piece.GetIdentifier(),
+			Db:         piece.GetDb(),
+			Payload:    piece.GetPayload(),
+			Contacts:   piece.GetContacts(),
+			WrappedKey: piece.GetWrappedKey(),
+			SealedAt:   timestampFromProto(piece.GetSealedAt()),
+		})
+	}
+}
+
+// emergencyAccessFromProto converts emergency access of a response, missing times are nil.
+func emergencyAccessFromProto(access *pb.EmergencyAccess) modelstorage.EmergencyAccess {
+	return modelstorage.EmergencyAccess{
+		Owner:       access.GetOwner(),
+		Contact:     access.GetContact(),
+		WaitDays:    int(access.GetWaitDays()),
+		Status:      access.GetStatus(),
+		RequestedAt: timestampFromProto(access.GetRequestedAt()),
+		GrantsAt:    timestampFromProto(access.GetGrantsAt()),
+		GrantedAt:   timestampFromProto(access.GetGrantedAt()),
+	}
+}
+
+// fieldsFromProto converts custom fields of a response.
+func fieldsFromProto(fields []*pb.CustomField) []modelstorage.CustomField {
+	var converted []modelstorage.CustomField
+	for _, field := range fields {
+		converted = append(converted, modelstorage.CustomField{Name: field.GetName(), Value: field.GetValue(), Concealed: field.GetConcealed()})
+	}
+	return converted
+}
+
+// fieldsToProto converts custom fields of an entry to a request.
+func fieldsToProto(fields []modelstorage.CustomField) []*pb.CustomField {
+	var converted []*pb.CustomField
+	for _, field := range fields {
+		converted = append(converted, &pb.CustomField{Name: field.Name, Value: field.Value, Concealed: field.Concealed})
+	}
+	return converted
+}
+
+// revisionFromProto converts revision timestamps of a response, missing timestamps are nil.
+func revisionFromProto(revision *pb.Revision) modelstorage.Revision {
+	return modelstorage.Revision{CreatedAt: timestampFromProto(revision.GetCreatedAt()), UpdatedAt: timestampFromProto(revision.GetUpdatedAt())}
+}
+
+// timestampFromProto converts a timestamp of a response, a missing timestamp is nil.
+func timestampFromProto(timestamp *timestamppb.Timestamp) *time.Time {
+	if timestamp == nil {
+		return nil
+	}
+	converted := timestamp.AsTime()
+	return &converted
+}
+
+// labelsFromProto converts labels of a response, missing labels are empty.
+func labelsFromProto(labels *pb.Labels) modelstorage.Labels {
+	return modelstorage.Labels{Folder: labels.GetFolder(), Tags: labels.GetTags(), Favorite: labels.GetFavorite()}
+}
+
+// labelsToProto converts labels of an entry to a request.
+func labelsToProto(labels modelstorage.Labels) *pb.Labels {
+	return &pb.Labels{Folder: labels.Folder, Tags: labels.Tags, Favorite: labels.Favorite}
+}
+
+// fieldViolations returns field violations a request was rejected with as validation.Errors, so that they can be shown
+// field by field, other errors are returned as they are.
+func fieldViolations(st *status.Status, err error) error {
+	var violations validation.Errors
+	for _, detail := range st.Details() {
+		badRequest, ok := detail.(*errdetails.BadRequest)
+		if !ok {
+			continue
+		}
+		for _, violation := range badRequest.GetFieldViolations() {
+			violations = append(violations, &validation.FieldError{Field: violation.GetField(), Description: violation.GetDescription()})
+		}
+	}
+	if len(violations) == 0 {
+		return err
+	}
+	return violations
+}
@@ -9,13 +9,17 @@ import (
 	"dk-go-gophkeeper/internal/server/api/handlers"
 	"dk-go-gophkeeper/internal/server/api/interceptors"
 	"dk-go-gophkeeper/internal/server/cipher/v1"
+	"dk-go-gophkeeper/internal/server/modeldto"
+	storageErrors "dk-go-gophkeeper/internal/server/storage/errors"
 	serverStorage "dk-go-gophkeeper/internal/server/storage/modelstorage"
+	"dk-go-gophkeeper/internal/validation"
 	"errors"
 	"log"
 	"net"
 	"os"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/rs/zerolog"
@@ -23,7 +27,7 @@ import (
 	"github.com/stretchr/testify/suite"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 type ClientTestSuite struct {
@@ -44,6 +48,11 @@ func (suite *ClientTestSuite) SetupTest() {
 	cfg.ServerAddress = ":8080"
 	cfg.UserKey = "jds__63h3_7ds"
 	cfg.AuthBearerName = "token"
+	cfg.CollectionHeader = "collection"
+	cfg.SessionHeader = "session"
+	cfg.BankCardDB = "bankCard"
+	cfg.LoginPasswordDB = "loginPassword"
+	cfg.TextBinaryDB = "textBinary"
 	suite.cfg = cfg
 	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
 	suite.ctx, suite.cancel = context.WithCancel(context.Background())
@@ -51,6 +60,8 @@ func (suite *ClientTestSuite) SetupTest() {
 	ctrl := gomock.NewController(suite.T())
 	defer ctrl.Finish()
 	suite.storage = mocks.NewMockDataStorage(ctrl)
+	suite.storage.EXPECT().GetSession(gomock.Any(), gomock.Any(), gomock.Any()).Return(serverStorage.Session{ID: 1, LastSeenAt: time.Now()}, nil).AnyTimes()
+	suite.storage.EXPECT().TouchSession(gomock.Any(), int64(1), gomock.Any()).Return(nil).AnyTimes()
 	server, err := handlers.InitServer(cfg, suite.storage, &logger)
 	if err != nil {
 		log.Fatal(err)
@@ -61,8 +72,12 @@ func (suite *ClientTestSuite) SetupTest() {
 	if err != nil {
 		log.Fatal(err)
 	}
-	interceptorService := interceptors.NewAuthHandler(cipherInstance, cfg)
-	suite.s = grpc.NewServer(grpc.UnaryInterceptor(interceptorService.UnaryServerInterceptor()))
+	interceptorService := interceptors.NewAuthHandler(cipherInstance, cfg, server.Processor(), server.Processor())
+	errorService := interceptors.NewErrorHandler(&logger)
+	suite.s = grpc.NewServer(
+		grpc.ChainUnaryInterceptor(errorService.UnaryServerInterceptor(), interceptorService.UnaryServerInterceptor()),
+		grpc.ChainStreamInterceptor(errorService.StreamServerInterceptor(), interceptorService.StreamServerInterceptor()),
+	)
 	pb.RegisterGophkeeperServer(suite.s, suite.server)
 	listen, err := net.Listen("tcp", ":8080")
 	if err != nil {
@@ -82,12 +97,12 @@ func TestHandlersTestSuite(t *testing.T) {
 }
 
 func (suite *ClientTestSuite) TestLoginFail() {
-	suite.storage.EXPECT().CheckUser(gomock.Any(), gomock.Any(), gomock.Any()).Return("", errors.New("generic_error"))
+	suite.storage.EXPECT().CheckUser(gomock.Any(), gomock.Any(), gomock.Any()).Return("", &storageErrors.InvalidCredentialsError{})
 	code, err := suite.client.Login(modelstorage.RegisterLogin{
 		Login:    "some_login",
 		Password: "some_password",
 	})
-	assert.Equal(suite.T(), "rpc error: code = Unauthenticated desc = generic_error", err.Error())
+	assert.Equal(suite.T(), "rpc error: code = Unauthenticated desc = invalid login or password", err.Error())
 	assert.Equal(suite.T(), codes.Unauthenticated, code)
 	suite.s.GracefulStop()
 	suite.cancel()
@@ -96,25 +111,34 @@ func (suite *ClientTestSuite) TestLoginFail() {
 
 func (suite *ClientTestSuite) TestLoginSuccess() {
 	suite.storage.EXPECT().CheckUser(gomock.Any(), gomock.Any(), gomock.Any()).Return("some_user_id", nil)
+	suite.storage.EXPECT().AddSession(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, session serverStorage.Session) (int64, error) {
+		assert.Equal(suite.T(), "laptop", session.DeviceName)
+		assert.Equal(suite.T(), buildVersion, session.ClientVersion)
+		return 1, nil
+	})
+	suite.cfg.DeviceName = "laptop"
 	code, err := suite.client.Login(modelstorage.RegisterLogin{
 		Login:    "some_login",
 		Password: "some_password",
 	})
 	assert.Equal(suite.T(), nil, err)
 	assert.Equal(suite.T(), codes.OK, code)
+	token, sessionKey := suite.client.Token()
+	assert.NotEqual(suite.T(), "", token)
+	assert.Equal(suite.T(), 64, len(sessionKey))
 	suite.s.GracefulStop()
 	suite.cancel()
 	suite.wg.Wait()
 }
 
 func (suite *ClientTestSuite) TestRegisterFail() {
-	suite.storage.EXPECT().AddNewUser(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("generic_error"))
+	suite.storage.EXPECT().AddNewUser(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(&storageErrors.AlreadyExistsError{ID: "encoded_login"})
 	code, err := suite.client.Register(modelstorage.RegisterLogin{
 		Login:    "some_login",
 		Password: "some_password",
 	})
-	assert.Equal(suite.T(), "rpc error: code = Unauthenticated desc = generic_error", err.Error())
-	assert.Equal(suite.T(), codes.Unauthenticated, code)
+	assert.Equal(suite.T(), "rpc error: code = AlreadyExists desc = already exists", err.Error())
+	assert.Equal(suite.T(), codes.AlreadyExists, code)
 	suite.s.GracefulStop()
 	suite.cancel()
 	suite.wg.Wait()
@@ -122,6 +146,7 @@ func (suite *ClientTestSuite) TestRegisterFail() {
 
 func (suite *ClientTestSuite) TestRegisterSuccess() {
 	suite.storage.EXPECT().AddNewUser(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	suite.storage.EXPECT().AddSession(gomock.Any(), gomock.Any()).Return(int64(1), nil)
 	code, err := suite.client.Register(modelstorage.RegisterLogin{
 		Login:    "some_login",
 		Password: "some_password",
@@ -134,20 +159,18 @@ func (suite *ClientTestSuite) TestRegisterSuccess() {
 }
 
 func (suite *ClientTestSuite) TestGetTextsBinariesFail() {
-	suite.client.token = "8773a90a68ebd0fd56dffb1441682414fbec5f454eba9be6129bb00744f50d7f19fd870e97eba101a03b857c675e4836de6f5196"
-	suite.client.md = metadata.New(map[string]string{suite.cfg.AuthBearerName: suite.client.token})
-	suite.storage.EXPECT().GetTextBinaryData(gomock.Any(), gomock.Any()).Return(nil, errors.New("generic_error"))
+	suite.client.SetToken("8773a90a68ebd0fd56dffb1441682414fbec5f454eba9be6129bb00744f50d7f19fd870e97eba101a03b857c675e4836de6f5196", "session_key")
+	suite.storage.EXPECT().GetTextBinaryData(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("generic_error"))
 	_, code, err := suite.client.GetTextsBinaries()
-	assert.Equal(suite.T(), "rpc error: code = Unknown desc = generic_error", err.Error())
-	assert.Equal(suite.T(), codes.Unknown, code)
+	assert.Equal(suite.T(), "rpc error: code = Internal desc = internal server error", err.Error())
+	assert.Equal(suite.T(), codes.Internal, code)
 	suite.s.GracefulStop()
 	suite.cancel()
 	suite.wg.Wait()
 }
 
 func (suite *ClientTestSuite) TestGetTextsBinariesSuccess() {
-	suite.client.token = "8773a90a68ebd0fd56dffb1441682414fbec5f454eba9be6129bb00744f50d7f19fd870e97eba101a03b857c675e4836de6f5196"
-	suite.client.md = metadata.New(map[string]string{suite.cfg.AuthBearerName: suite.client.token})
+	suite.client.SetToken("8773a90a68ebd0fd56dffb1441682414fbec5f454eba9be6129bb00744f50d7f19fd870e97eba101a03b857c675e4836de6f5196", "session_key")
 	storageData := []serverStorage.TextBinaryStorageEntry{
 		{
 			ID:         0,
@@ -163,7 +186,7 @@ func (suite *ClientTestSuite) TestGetTextsBinariesSuccess() {
 		Entry:      "3",
 		Meta:       "4",
 	}
-	suite.storage.EXPECT().GetTextBinaryData(gomock.Any(), gomock.Any()).Return(storageData, nil)
+	suite.storage.EXPECT().GetTextBinaryData(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(storageData, nil)
 	data, code, err := suite.client.GetTextsBinaries()
 	assert.Equal(suite.T(), nil, err)
 	assert.Equal(suite.T(), codes.OK, code)
@@ -174,20 +197,18 @@ func (suite *ClientTestSuite) TestGetTextsBinariesSuccess() {
 }
 
 func (suite *ClientTestSuite) TestGetLoginsPaswordsFail() {
-	suite.client.token = "8773a90a68ebd0fd56dffb1441682414fbec5f454eba9be6129bb00744f50d7f19fd870e97eba101a03b857c675e4836de6f5196"
-	suite.client.md = metadata.New(map[string]string{suite.cfg.AuthBearerName: suite.client.token})
-	suite.storage.EXPECT().GetLoginPasswordData(gomock.Any(), gomock.Any()).Return(nil, errors.New("generic_error"))
+	suite.client.SetToken("8773a90a68ebd0fd56dffb1441682414fbec5f454eba9be6129bb00744f50d7f19fd870e97eba101a03b857c675e4836de6f5196", "session_key")
+	suite.storage.EXPECT().GetLoginPasswordData(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("generic_error"))
 	_, code, err := suite.client.GetLoginsPasswords()
-	assert.Equal(suite.T(), "rpc error: code = Unknown desc = generic_error", err.Error())
-	assert.Equal(suite.T(), codes.Unknown, code)
+	assert.Equal(suite.T(), "rpc error: code = Internal desc = internal server error", err.Error())
+	assert.Equal(suite.T(), codes.Internal, code)
 	suite.s.GracefulStop()
 	suite.cancel()
 	suite.wg.Wait()
 }
 
 func (suite *ClientTestSuite) TestGetLoginsPaswordsSuccess() {
-	suite.client.token = "8773a90a68ebd0fd56dffb1441682414fbec5f454eba9be6129bb00744f50d7f19fd870e97eba101a03b857c675e4836de6f5196"
-	suite.client.md = metadata.New(map[string]string{suite.cfg.AuthBearerName: suite.client.token})
+	suite.client.SetToken("8773a90a68ebd0fd56dffb1441682414fbec5f454eba9be6129bb00744f50d7f19fd870e97eba101a03b857c675e4836de6f5196", "session_key")
 	storageData := []serverStorage.LoginPasswordStorageEntry{
 		{
 			ID:         0,
@@ -205,7 +226,7 @@ func (suite *ClientTestSuite) TestGetLoginsPaswordsSuccess() {
 		Password:   "4",
 		Meta:       "5",
 	}
-	suite.storage.EXPECT().GetLoginPasswordData(gomock.Any(), gomock.Any()).Return(storageData, nil)
+	suite.storage.EXPECT().GetLoginPasswordData(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(storageData, nil)
 	data, code, err := suite.client.GetLoginsPasswords()
 	assert.Equal(suite.T(), nil, err)
 	assert.Equal(suite.T(), codes.OK, code)
@@ -216,20 +237,18 @@ func (suite *ClientTestSuite) TestGetLoginsPaswordsSuccess() {
 }
 
 func (suite *ClientTestSuite) TestGetBankCardsFail() {
-	suite.client.token = "8773a90a68ebd0fd56dffb1441682414fbec5f454eba9be6129bb00744f50d7f19fd870e97eba101a03b857c675e4836de6f5196"
-	suite.client.md = metadata.New(map[string]string{suite.cfg.AuthBearerName: suite.client.token})
-	suite.storage.EXPECT().GetBankCardData(gomock.Any(), gomock.Any()).Return(nil, errors.New("generic_error"))
+	suite.client.SetToken("8773a90a68ebd0fd56dffb1441682414fbec5f454eba9be6129bb00744f50d7f19fd870e97eba101a03b857c675e4836de6f5196", "session_key")
+	suite.storage.EXPECT().GetBankCardData(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("generic_error"))
 	_, code, err := suite.client.GetBankCards()
-	assert.Equal(suite.T(), "rpc error: code = Unknown desc = generic_error", err.Error())
-	assert.Equal(suite.T(), codes.Unknown, code)
+	assert.Equal(suite.T(), "rpc error: code = Internal desc = internal server error", err.Error())
+	assert.Equal(suite.T(), codes.Internal, code)
 	suite.s.GracefulStop()
 	suite.cancel()
 	suite.wg.Wait()
 }
 
 func (suite *ClientTestSuite) TestGetBankCardsSuccess() {
-	suite.client.token = "8773a90a68ebd0fd56dffb1441682414fbec5f454eba9be6129bb00744f50d7f19fd870e97eba101a03b857c675e4836de6f5196"
-	suite.client.md = metadata.New(map[string]string{suite.cfg.AuthBearerName: suite.client.token})
+	suite.client.SetToken("8773a90a68ebd0fd56dffb1441682414fbec5f454eba9be6129bb00744f50d7f19fd870e97eba101a03b857c675e4836de6f5196", "session_key")
 	storageData := []serverStorage.BankCardStorageEntry{
 		{
 			ID:         0,
@@ -249,7 +268,7 @@ func (suite *ClientTestSuite) TestGetBankCardsSuccess() {
 		Cvv:        "5",
 		Meta:       "6",
 	}
-	suite.storage.EXPECT().GetBankCardData(gomock.Any(), gomock.Any()).Return(storageData, nil)
+	suite.storage.EXPECT().GetBankCardData(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(storageData, nil)
 	data, code, err := suite.client.GetBankCards()
 	assert.Equal(suite.T(), nil, err)
 	assert.Equal(suite.T(), codes.OK, code)
@@ -260,33 +279,31 @@ func (suite *ClientTestSuite) TestGetBankCardsSuccess() {
 }
 
 func (suite *ClientTestSuite) TestSendBankCardFail() {
-	suite.client.token = "8773a90a68ebd0fd56dffb1441682414fbec5f454eba9be6129bb00744f50d7f19fd870e97eba101a03b857c675e4836de6f5196"
-	suite.client.md = metadata.New(map[string]string{suite.cfg.AuthBearerName: suite.client.token})
-	suite.storage.EXPECT().SetBankCardData(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("generic_error"))
+	suite.client.SetToken("8773a90a68ebd0fd56dffb1441682414fbec5f454eba9be6129bb00744f50d7f19fd870e97eba101a03b857c675e4836de6f5196", "session_key")
+	suite.storage.EXPECT().SetBankCardData(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("generic_error"))
 	bankCard := modelstorage.BankCard{
 		Identifier: "1",
-		Number:     "2",
+		Number:     "4111111111111111",
 		Holder:     "3",
-		Cvv:        "4",
+		Cvv:        "123",
 		Meta:       "5",
 	}
 	code, err := suite.client.SendBankCard(bankCard)
-	assert.Equal(suite.T(), "rpc error: code = Unknown desc = generic_error", err.Error())
-	assert.Equal(suite.T(), codes.Unknown, code)
+	assert.Equal(suite.T(), "rpc error: code = Internal desc = internal server error", err.Error())
+	assert.Equal(suite.T(), codes.Internal, code)
 	suite.s.GracefulStop()
 	suite.cancel()
 	suite.wg.Wait()
 }
 
 func (suite *ClientTestSuite) TestSendBankCardSuccess() {
-	suite.client.token = "8773a90a68ebd0fd56dffb1441682414fbec5f454eba9be6129bb00744f50d7f19fd870e97eba101a03b857c675e4836de6f5196"
-	suite.client.md = metadata.New(map[string]string{suite.cfg.AuthBearerName: suite.client.token})
-	suite.storage.EXPECT().SetBankCardData(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	suite.client.SetToken("8773a90a68ebd0fd56dffb1441682414fbec5f454eba9be6129bb00744f50d7f19fd870e97eba101a03b857c675e4836de6f5196", "session_key")
+	suite.storage.EXPECT().SetBankCardData(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
 	bankCard := modelstorage.BankCard{
 		Identifier: "1",
-		Number:     "2",
+		Number:     "4111111111111111",
 		Holder:     "3",
-		Cvv:        "4",
+		Cvv:        "123",
 		Meta:       "5",
 	}
 	code, err := suite.client.SendBankCard(bankCard)
@@ -298,9 +315,8 @@ func (suite *ClientTestSuite) TestSendBankCardSuccess() {
 }
 
 func (suite *ClientTestSuite) TestSendLoginPasswordFail() {
-	suite.client.token = "8773a90a68ebd0fd56dffb1441682414fbec5f454eba9be6129bb00744f50d7f19fd870e97eba101a03b857c675e4836de6f5196"
-	suite.client.md = metadata.New(map[string]string{suite.cfg.AuthBearerName: suite.client.token})
-	suite.storage.EXPECT().SetLoginPasswordData(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("generic_error"))
+	suite.client.SetToken("8773a90a68ebd0fd56dffb1441682414fbec5f454eba9be6129bb00744f50d7f19fd870e97eba101a03b857c675e4836de6f5196", "session_key")
+	suite.storage.EXPECT().SetLoginPasswordData(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("generic_error"))
 	loginPassword := modelstorage.LoginAndPassword{
 		Identifier: "1",
 		Login:      "2",
@@ -308,17 +324,35 @@ func (suite *ClientTestSuite) TestSendLoginPasswordFail() {
 		Meta:       "4",
 	}
 	code, err := suite.client.SendLoginPassword(loginPassword)
-	assert.Equal(suite.T(), "rpc error: code = Unknown desc = generic_error", err.Error())
-	assert.Equal(suite.T(), codes.Unknown, code)
+	assert.Equal(suite.T(), "rpc error: code = Internal desc = internal server error", err.Error())
+	assert.Equal(suite.T(), codes.Internal, code)
+	suite.s.GracefulStop()
+	suite.cancel()
+	suite.wg.Wait()
+}
+
+func (suite *ClientTestSuite) TestSendBankCardInvalid() {
+	suite.client.SetToken("8773a90a68ebd0fd56dffb1441682414fbec5f454eba9be6129bb00744f50d7f19fd870e97eba101a03b857c675e4836de6f5196", "session_key")
+	bankCard := modelstorage.BankCard{
+		Number: "4111111111111112",
+		Cvv:    "123",
+	}
+	code, err := suite.client.SendBankCard(bankCard)
+	assert.Equal(suite.T(), codes.InvalidArgument, code)
+	var violations validation.Errors
+	assert.Equal(suite.T(), true, errors.As(err, &violations))
+	assert.Equal(suite.T(), validation.Errors{
+		{Field: "identifier", Description: "identifier cannot be empty"},
+		{Field: "number", Description: "card number fails the checksum, check it for typos"},
+	}, violations)
 	suite.s.GracefulStop()
 	suite.cancel()
 	suite.wg.Wait()
 }
 
 func (suite *ClientTestSuite) TestSendLoginPasswordSuccess() {
-	suite.client.token = "8773a90a68ebd0fd56dffb1441682414fbec5f454eba9be6129bb00744f50d7f19fd870e97eba101a03b857c675e4836de6f5196"
-	suite.client.md = metadata.New(map[string]string{suite.cfg.AuthBearerName: suite.client.token})
-	suite.storage.EXPECT().SetLoginPasswordData(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	suite.client.SetToken("8773a90a68ebd0fd56dffb1441682414fbec5f454eba9be6129bb00744f50d7f19fd870e97eba101a03b857c675e4836de6f5196", "session_key")
+	suite.storage.EXPECT().SetLoginPasswordData(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
 	loginPassword := modelstorage.LoginAndPassword{
 		Identifier: "1",
 		Login:      "2",
@@ -334,26 +368,24 @@ func (suite *ClientTestSuite) TestSendLoginPasswordSuccess() {
 }
 
 func (suite *ClientTestSuite) TestSendTextBinaryFail() {
-	suite.client.token = "8773a90a68ebd0fd56dffb1441682414fbec5f454eba9be6129bb00744f50d7f19fd870e97eba101a03b857c675e4836de6f5196"
-	suite.client.md = metadata.New(map[string]string{suite.cfg.AuthBearerName: suite.client.token})
-	suite.storage.EXPECT().SetTextBinaryData(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("generic_error"))
+	suite.client.SetToken("8773a90a68ebd0fd56dffb1441682414fbec5f454eba9be6129bb00744f50d7f19fd870e97eba101a03b857c675e4836de6f5196", "session_key")
+	suite.storage.EXPECT().SetTextBinaryData(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("generic_error"))
 	textBinary := modelstorage.TextOrBinary{
 		Identifier: "1",
 		Entry:      "2",
 		Meta:       "3",
 	}
 	code, err := suite.client.SendTextBinary(textBinary)
-	assert.Equal(suite.T(), "rpc error: code = Unknown desc = generic_error", err.Error())
-	assert.Equal(suite.T(), codes.Unknown, code)
+	assert.Equal(suite.T(), "rpc error: code = Internal desc = internal server error", err.Error())
+	assert.Equal(suite.T(), codes.Internal, code)
 	suite.s.GracefulStop()
 	suite.cancel()
 	suite.wg.Wait()
 }
 
 func (suite *ClientTestSuite) TestSendTextBinarySuccess() {
-	suite.client.token = "8773a90a68ebd0fd56dffb1441682414fbec5f454eba9be6129bb00744f50d7f19fd870e97eba101a03b857c675e4836de6f5196"
-	suite.client.md = metadata.New(map[string]string{suite.cfg.AuthBearerName: suite.client.token})
-	suite.storage.EXPECT().SetTextBinaryData(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	suite.client.SetToken("8773a90a68ebd0fd56dffb1441682414fbec5f454eba9be6129bb00744f50d7f19fd870e97eba101a03b857c675e4836de6f5196", "session_key")
+	suite.storage.EXPECT().SetTextBinaryData(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
 	textBinary := modelstorage.TextOrBinary{
 		Identifier: "1",
 		Entry:      "2",
@@ -368,8 +400,7 @@ func (suite *ClientTestSuite) TestSendTextBinarySuccess() {
 }
 
 func (suite *ClientTestSuite) TestRemoveBankCard() {
-	suite.client.token = "8773a90a68ebd0fd56dffb1441682414fbec5f454eba9be6129bb00744f50d7f19fd870e97eba101a03b857c675e4836de6f5196"
-	suite.client.md = metadata.New(map[string]string{suite.cfg.AuthBearerName: suite.client.token})
+	suite.client.SetToken("8773a90a68ebd0fd56dffb1441682414fbec5f454eba9be6129bb00744f50d7f19fd870e97eba101a03b857c675e4836de6f5196", "session_key")
 	suite.storage.EXPECT().SendToQueue(gomock.Any()).Return()
 	code, err := suite.client.RemoveBankCard("1")
 	assert.Equal(suite.T(), nil, err)
@@ -380,8 +411,7 @@ func (suite *ClientTestSuite) TestRemoveBankCard() {
 }
 
 func (suite *ClientTestSuite) TestRemoveLoginPassword() {
-	suite.client.token = "8773a90a68ebd0fd56dffb1441682414fbec5f454eba9be6129bb00744f50d7f19fd870e97eba101a03b857c675e4836de6f5196"
-	suite.client.md = metadata.New(map[string]string{suite.cfg.AuthBearerName: suite.client.token})
+	suite.client.SetToken("8773a90a68ebd0fd56dffb1441682414fbec5f454eba9be6129bb00744f50d7f19fd870e97eba101a03b857c675e4836de6f5196", "session_key")
 	suite.storage.EXPECT().SendToQueue(gomock.Any()).Return()
 	code, err := suite.client.RemoveLoginPassword("1")
 	assert.Equal(suite.T(), nil, err)
@@ -392,8 +422,7 @@ func (suite *ClientTestSuite) TestRemoveLoginPassword() {
 }
 
 func (suite *ClientTestSuite) TestRemoveTextBinary() {
-	suite.client.token = "8773a90a68ebd0fd56dffb1441682414fbec5f454eba9be6129bb00744f50d7f19fd870e97eba101a03b857c675e4836de6f5196"
-	suite.client.md = metadata.New(map[string]string{suite.cfg.AuthBearerName: suite.client.token})
+	suite.client.SetToken("8773a90a68ebd0fd56dffb1441682414fbec5f454eba9be6129bb00744f50d7f19fd870e97eba101a03b857c675e4836de6f5196", "session_key")
 	suite.storage.EXPECT().SendToQueue(gomock.Any()).Return()
 	code, err := suite.client.RemoveTextBinary("1")
 	assert.Equal(suite.T(), nil, err)
@@ -402,3 +431,169 @@ func (suite *ClientTestSuite) TestRemoveTextBinary() {
 	suite.cancel()
 	suite.wg.Wait()
 }
+
+func (suite *ClientTestSuite) TestSendBatchFail() {
+	suite.client.SetToken("8773a90a68ebd0fd56dffb1441682414fbec5f454eba9be6129bb00744f50d7f19fd870e97eba101a03b857c675e4836de6f5196", "session_key")
+	suite.storage.EXPECT().SetBatchData(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("generic_error"))
+	batch := modelstorage.Batch{BankCards: []modelstorage.BankCard{{Identifier: "1", Number: "4111111111111111", Cvv: "123"}}}
+	_, code, err := suite.client.SendBatch(batch, false)
+	assert.Equal(suite.T(), "rpc error: code = Internal desc = internal server error", err.Error())
+	assert.Equal(suite.T(), codes.Internal, code)
+	suite.s.GracefulStop()
+	suite.cancel()
+	suite.wg.Wait()
+}
+
+func (suite *ClientTestSuite) TestSendBatchSuccess() {
+	suite.client.SetToken("8773a90a68ebd0fd56dffb1441682414fbec5f454eba9be6129bb00744f50d7f19fd870e97eba101a03b857c675e4836de6f5196", "session_key")
+	storageOutput := []serverStorage.BatchItemResult{{Db: "loginPassword", Identifier: suite.cipher.Encode("2"), Created: true}}
+	suite.storage.EXPECT().SetBatchData(gomock.Any(), gomock.Any(), gomock.Any(), false).Return(storageOutput, nil)
+	batch := modelstorage.Batch{
+		LoginsPasswords: []modelstorage.LoginAndPassword{{Identifier: "2"}},
+		TextsBinaries:   []modelstorage.TextOrBinary{{Identifier: ""}},
+	}
+	results, code, err := suite.client.SendBatch(batch, false)
+	assert.Equal(suite.T(), nil, err)
+	assert.Equal(suite.T(), codes.OK, code)
+	assert.Equal(suite.T(), modelstorage.BatchItemResult{Identifier: "2", Db: "loginPassword"}, results[0])
+	assert.Equal(suite.T(), "textBinary", results[1].Db)
+	assert.Equal(suite.T(), codes.InvalidArgument, status.Code(results[1].Err))
+	suite.s.GracefulStop()
+	suite.cancel()
+	suite.wg.Wait()
+}
+
+func (suite *ClientTestSuite) TestShareEntryFail() {
+	suite.client.SetToken("8773a90a68ebd0fd56dffb1441682414fbec5f454eba9be6129bb00744f50d7f19fd870e97eba101a03b857c675e4836de6f5196", "session_key")
+	suite.storage.EXPECT().GetUserIDByLogin(gomock.Any(), suite.cipher.Encode("bob")).Return("", &storageErrors.NotFoundError{})
+	code, err := suite.client.ShareEntry("staging", "loginPassword", "bob", "read", modelstorage.SealedEntry{})
+	assert.Equal(suite.T(), "rpc error: code = NotFound desc = user bob is not registered", err.Error())
+	assert.Equal(suite.T(), codes.NotFound, code)
+	suite.s.GracefulStop()
+	suite.cancel()
+	suite.wg.Wait()
+}
+
+func (suite *ClientTestSuite) TestGetSharedWithMeSuccess() {
+	suite.client.SetToken("8773a90a68ebd0fd56dffb1441682414fbec5f454eba9be6129bb00744f50d7f19fd870e97eba101a03b857c675e4836de6f5196", "session_key")
+	// entries are sealed by clients, the server passes them through as they are
+	suite.storage.EXPECT().GetSharedWithUser(gomock.Any(), gomock.Any()).Return([]serverStorage.Share{
+		{
+			ID:         3,
+			OwnerLogin: suite.cipher.Encode("alice"),
+			Db:         "loginPassword",
+			Payload:    "sealed_entry",
+			Recipients: []serverStorage.ShareRecipient{{WrappedKey: "wrapped_key", Permission: "read-write"}},
+		},
+	}, nil)
+	entries, code, err := suite.client.GetSharedWithMe()
+	assert.Equal(suite.T(), nil, err)
+	assert.Equal(suite.T(), codes.OK, code)
+	assert.Equal(suite.T(), []modelstorage.SharedEntry{{
+		ID:         3,
+		Owner:      "alice",
+		Permission: "read-write",
+		Db:         "loginPassword",
+		Payload:    "sealed_entry",
+		WrappedKey: "wrapped_key",
+	}}, entries)
+	suite.s.GracefulStop()
+	suite.cancel()
+	suite.wg.Wait()
+}
+
+func (suite *ClientTestSuite) TestRemoveTextBinaryInCollection() {
+	suite.cfg.Collection = "team"
+	suite.client.SetToken("8773a90a68ebd0fd56dffb1441682414fbec5f454eba9be6129bb00744f50d7f19fd870e97eba101a03b857c675e4836de6f5196", "session_key")
+	accountID, err := suite.cipher.ValidateToken(suite.client.token)
+	assert.Equal(suite.T(), nil, err)
+	suite.storage.EXPECT().GetMember(gomock.Any(), "team", accountID).Return(serverStorage.CollectionMember{Role: "read-only"}, nil)
+	code, err := suite.client.RemoveTextBinary("1")
+	assert.Equal(suite.T(), "rpc error: code = PermissionDenied desc = role member is required in collection team", err.Error())
+	assert.Equal(suite.T(), codes.PermissionDenied, code)
+	suite.s.GracefulStop()
+	suite.cancel()
+	suite.wg.Wait()
+}
+
+func (suite *ClientTestSuite) TestGetMembersSuccess() {
+	suite.client.SetToken("8773a90a68ebd0fd56dffb1441682414fbec5f454eba9be6129bb00744f50d7f19fd870e97eba101a03b857c675e4836de6f5196", "session_key")
+	suite.storage.EXPECT().GetMember(gomock.Any(), "team", gomock.Any()).Return(serverStorage.CollectionMember{Role: "member"}, nil)
+	suite.storage.EXPECT().GetMembers(gomock.Any(), "team").Return([]serverStorage.CollectionMember{
+		{Login: suite.cipher.Encode("alice"), Role: "owner"},
+		{Login: suite.cipher.Encode("bob"), Role: "member"},
+	}, nil)
+	members, code, err := suite.client.GetMembers("team")
+	assert.Equal(suite.T(), nil, err)
+	assert.Equal(suite.T(), codes.OK, code)
+	assert.Equal(suite.T(), []modelstorage.CollectionMember{{Login: "alice", Role: "owner"}, {Login: "bob", Role: "member"}}, members)
+	suite.s.GracefulStop()
+	suite.cancel()
+	suite.wg.Wait()
+}
+
+func (suite *ClientTestSuite) TestListAuditEventsSuccess() {
+	suite.client.SetToken("8773a90a68ebd0fd56dffb1441682414fbec5f454eba9be6129bb00744f50d7f19fd870e97eba101a03b857c675e4836de6f5196", "session_key")
+	createdAt := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	suite.storage.EXPECT().GetAuditEvents(gomock.Any(), gomock.Any(), int64(8), 10).Return([]serverStorage.AuditEvent{
+		{ID: 7, Action: modeldto.AuditLogin, Method: "Login", Peer: "10.0.0.1:5000", Code: "OK", CreatedAt: createdAt},
+	}, nil)
+	events, code, err := suite.client.ListAuditEvents(8, 10)
+	assert.Equal(suite.T(), nil, err)
+	assert.Equal(suite.T(), codes.OK, code)
+	assert.Equal(suite.T(), []modelstorage.AuditEvent{{ID: 7, Action: "login", Method: "Login", Peer: "10.0.0.1:5000", Code: "OK", CreatedAt: &createdAt}}, events)
+	suite.s.GracefulStop()
+	suite.cancel()
+	suite.wg.Wait()
+}
+
+func (suite *ClientTestSuite) TestListSessionsSuccess() {
+	suite.client.SetToken("8773a90a68ebd0fd56dffb1441682414fbec5f454eba9be6129bb00744f50d7f19fd870e97eba101a03b857c675e4836de6f5196", "session_key")
+	createdAt := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	suite.storage.EXPECT().GetSessions(gomock.Any(), gomock.Any()).Return([]serverStorage.Session{
+		{ID: 1, Digest: suite.cipher.SessionDigest("session_key"), DeviceName: "laptop", ClientVersion: "1.2.0", Peer: "10.0.0.1", CreatedAt: createdAt, LastSeenAt: createdAt},
+		{ID: 2, Digest: suite.cipher.SessionDigest("other_key"), DeviceName: "phone", ClientVersion: "1.1.0", Peer: "10.0.0.2", CreatedAt: createdAt, LastSeenAt: createdAt},
+	}, nil)
+	sessions, code, err := suite.client.ListSessions()
+	assert.Equal(suite.T(), nil, err)
+	assert.Equal(suite.T(), codes.OK, code)
+	assert.Equal(suite.T(), []modelstorage.Session{
+		{ID: 1, DeviceName: "laptop", ClientVersion: "1.2.0", Peer: "10.0.0.1", CreatedAt: &createdAt, LastSeenAt: &createdAt, Current: true},
+		{ID: 2, DeviceName: "phone", ClientVersion: "1.1.0", Peer: "10.0.0.2", CreatedAt: &createdAt, LastSeenAt: &createdAt},
+	}, sessions)
+	suite.s.GracefulStop()
+	suite.cancel()
+	suite.wg.Wait()
+}
+
+func (suite *ClientTestSuite) TestRevokeSessionNotFound() {
+	suite.client.SetToken("8773a90a68ebd0fd56dffb1441682414fbec5f454eba9be6129bb00744f50d7f19fd870e97eba101a03b857c675e4836de6f5196", "session_key")
+	suite.storage.EXPECT().DeleteSession(gomock.Any(), gomock.Any(), int64(5)).Return(&storageErrors.NotFoundError{})
+	code, err := suite.client.RevokeSession(5)
+	assert.Equal(suite.T(), "rpc error: code = NotFound desc = session 5 not found", err.Error())
+	assert.Equal(suite.T(), codes.NotFound, code)
+	suite.s.GracefulStop()
+	suite.cancel()
+	suite.wg.Wait()
+}
+
+func (suite *ClientTestSuite) TestGetEmergencyVaultSuccess() {
+	suite.client.SetToken("8773a90a68ebd0fd56dffb1441682414fbec5f454eba9be6129bb00744f50d7f19fd870e97eba101a03b857c675e4836de6f5196", "session_key")
+	sealedAt := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	suite.storage.EXPECT().GetUserIDByLogin(gomock.Any(), suite.cipher.Encode("alice")).Return("alice_id", nil)
+	suite.storage.EXPECT().GetEmergencyAccess(gomock.Any(), "alice_id", gomock.Any()).Return(serverStorage.EmergencyAccess{
+		ID: 4, OwnerID: "alice_id", Status: modeldto.EmergencyGranted, GrantedAt: sealedAt,
+	}, nil)
+	suite.storage.EXPECT().GetEmergencyVault(gomock.Any(), int64(4), int64(0), gomock.Any()).Return([]serverStorage.EmergencyEntry{
+		{ID: 7, Db: "loginPassword", Identifier: suite.cipher.Encode("db"), Payload: "sealed_db", SealedAt: sealedAt, Keys: []serverStorage.EmergencyKey{{AccessID: 4, WrappedKey: "wrapped_key"}}},
+	}, nil)
+	entries, code, err := suite.client.GetEmergencyVault("alice")
+	assert.Equal(suite.T(), nil, err)
+	assert.Equal(suite.T(), codes.OK, code)
+	assert.Equal(suite.T(), []modelstorage.EmergencyEntry{
+		{Identifier: "db", Db: "loginPassword", Payload: "sealed_db", WrappedKey: "wrapped_key", SealedAt: &sealedAt},
+	}, entries)
+	suite.s.GracefulStop()
+	suite.cancel()
+	suite.wg.Wait()
+}
@@ -0,0 +1,170 @@
+package grpcclient
+
+import (
+	"dk-go-gophkeeper/internal/client/storage/modelstorage"
+	pb "dk-go-gophkeeper/internal/grpc/proto"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// CreateCollection implements client-side creation of a collection owned by the user.
+func (c *GRPCClient) CreateCollection(name string) (modelstorage.Collection, codes.Code, error) {
+	c.logger.Info().Msg("Creating collection attempt received")
+	newCtx := metadata.NewOutgoingContext(c.ctx, c.md)
+	resp, err := c.client.CreateCollection(newCtx, &pb.CreateCollectionRequest{Name: name})
+	if err != nil {
+		c.logger.Error().Err(err).Msg("could not execute client request")
+		return modelstorage.Collection{}, status.Code(err), err
+	}
+	return collectionFromProto(resp), codes.OK, nil
+}
+
+// GetCollections implements client-side retrieval of collections the user is a member of.
+func (c *GRPCClient) GetCollections() ([]modelstorage.Collection, codes.Code, error) {
+	c.logger.Info().Msg("Getting collections attempt received")
+	newCtx := metadata.NewOutgoingContext(c.ctx, c.md)
+	resp, err := c.client.GetCollections(newCtx, &emptypb.Empty{})
+	if err != nil {
+		c.logger.Error().Err(err).Msg("could not execute client request")
+		return nil, status.Code(err), err
+	}
+	var collections []modelstorage.Collection
+	for _, collection := range resp.GetCollections() {
+		collections = append(collections, collectionFromProto(collection))
+	}
+	return collections, codes.OK, nil
+}
+
+// DeleteCollection implements client-side deletion of a collection with all its entries.
+func (c *GRPCClient) DeleteCollection(collectionID string) (codes.Code, error) {
+	c.logger.Info().Msg("Deleting collection attempt received")
+	newCtx := metadata.NewOutgoingContext(c.ctx, c.md)
+	_, err := c.client.DeleteCollection(newCtx, &pb.CollectionRequest{CollectionId: collectionID})
+	if err != nil {
+		c.logger.Error().Err(err).Msg("could not execute client request")
+		return status.Code(err), err
+	}
+	return codes.OK, nil
+}
+
+// InviteMember implements client-side invitation of another user to a collection.
+func (c *GRPCClient) InviteMember(collectionID, login, role string) (codes.Code, error) {
+	c.logger.Info().Msg("Inviting member attempt received")
+	newCtx := metadata.NewOutgoingContext(c.ctx, c.md)
+	_, err := c.client.InviteMember(newCtx, &pb.InviteMemberRequest{CollectionId: collectionID, Login: login, Role: role})
+	if err != nil {
+		c.logger.Error().Err(err).Msg("could not execute client request")
+		return status.Code(err), err
+	}
+	return codes.OK, nil
+}
+
+// GetInvitations implements client-side retrieval of pending invitations of the user.
+func (c *GRPCClient) GetInvitations() ([]modelstorage.CollectionInvitation, codes.Code, error) {
+	c.logger.Info().Msg("Getting invitations attempt received")
+	newCtx := metadata.NewOutgoingContext(c.ctx, c.md)
+	resp, err := c.client.GetInvitations(newCtx, &emptypb.Empty{})
+	if err != nil {
+		c.logger.Error().Err(err).Msg("could not execute client request")
+		return nil, status.Code(err), err
+	}
+	var invitations []modelstorage.CollectionInvitation
+	for _, invitation := range resp.GetInvitations() {
+		invitations = append(invitations, modelstorage.CollectionInvitation{
+			ID:             invitation.GetId(),
+			CollectionID:   invitation.GetCollectionId(),
+			CollectionName: invitation.GetCollectionName(),
+			Inviter:        invitation.GetInviter(),
+			Role:           invitation.GetRole(),
+			CreatedAt:      timestampFromProto(invitation.GetCreatedAt()),
+		})
+	}
+	return invitations, codes.OK, nil
+}
+
+// RespondInvitation implements client-side acceptance or decline of an invitation.
+func (c *GRPCClient) RespondInvitation(invitationID int64, accept bool) (codes.Code, error) {
+	c.logger.Info().Msg("Responding invitation attempt received")
+	newCtx := metadata.NewOutgoingContext(c.ctx, c.md)
+	_, err := c.client.RespondInvitation(newCtx, &pb.RespondInvitationRequest{InvitationId: invitationID, Accept: accept})
+	if err != nil {
+		c.logger.Error().Err(err).Msg("could not execute client request")
+		return status.Code(err), err
+	}
+	return codes.OK, nil
+}
+
+// GetMembers implements client-side retrieval of members of a collection.
+func (c *GRPCClient) GetMembers(collectionID string) ([]modelstorage.CollectionMember, codes.Code, error) {
+	c.logger.Info().Msg("Getting members attempt received")
+	newCtx := metadata.NewOutgoingContext(c.ctx, c.md)
+	resp, err := c.client.GetMembers(newCtx, &pb.CollectionRequest{CollectionId: collectionID})
+	if err != nil {
+		c.logger.Error().Err(err).Msg("could not execute client request")
+		return nil, status.Code(err), err
+	}
+	var members []modelstorage.CollectionMember
+	for _, member := range resp.GetMembers() {
+		members = append(members, modelstorage.CollectionMember{Login: member.GetLogin(), Role: member.GetRole(), JoinedAt: timestampFromProto(member.GetJoinedAt())})
+	}
+	return members, codes.OK, nil
+}
+
+// SetMemberRole implements client-side change of a role of a collection member.
+func (c *GRPCClient) SetMemberRole(collectionID, login, role string) (codes.Code, error) {
+	c.logger.Info().Msg("Setting member role attempt received")
+	newCtx := metadata.NewOutgoingContext(c.ctx, c.md)
+	_, err := c.client.SetMemberRole(newCtx, &pb.SetMemberRoleRequest{CollectionId: collectionID, Login: login, Role: role})
+	if err != nil {
+		c.logger.Error().Err(err).Msg("could not execute client request")
+		return status.Code(err), err
+	}
+	return codes.OK, nil
+}
+
+// RemoveMember implements client-side removal of a collection member, the user may remove themselves to leave.
+func (c *GRPCClient) RemoveMember(collectionID, login string) (codes.Code, error) {
+	c.logger.Info().Msg("Removing member attempt received")
+	newCtx := metadata.NewOutgoingContext(c.ctx, c.md)
+	_, err := c.client.RemoveMember(newCtx, &pb.RemoveMemberRequest{CollectionId: collectionID, Login: login})
+	if err != nil {
+		c.logger.Error().Err(err).Msg("could not execute client request")
+		return status.Code(err), err
+	}
+	return codes.OK, nil
+}
+
+// GetCollectionEvents implements client-side retrieval of membership changes of a collection.
+func (c *GRPCClient) GetCollectionEvents(collectionID string) ([]modelstorage.CollectionEvent, codes.Code, error) {
+	c.logger.Info().Msg("Getting collection events attempt received")
+	newCtx := metadata.NewOutgoingContext(c.ctx, c.md)
+	resp, err := c.client.GetCollectionEvents(newCtx, &pb.CollectionRequest{CollectionId: collectionID})
+	if err != nil {
+		c.logger.Error().Err(err).Msg("could not execute client request")
+		return nil, status.Code(err), err
+	}
+	var events []modelstorage.CollectionEvent
+	for _, event := range resp.GetEvents() {
+		events = append(events, modelstorage.CollectionEvent{
+			Actor:     event.GetActor(),
+			Target:    event.GetTarget(),
+			Action:    event.GetAction(),
+			Role:      event.GetRole(),
+			CreatedAt: timestampFromProto(event.GetCreatedAt()),
+		})
+	}
+	return events, codes.OK, nil
+}
+
+// collectionFromProto converts a collection of a response.
+func collectionFromProto(collection *pb.Collection) modelstorage.Collection {
+	return modelstorage.Collection{
+		ID:        collection.GetId(),
+		Name:      collection.GetName(),
+		Role:      collection.GetRole(),
+		CreatedAt: timestampFromProto(collection.GetCreatedAt()),
+	}
+}
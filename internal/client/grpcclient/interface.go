@@ -37,6 +37,11 @@ type TextBinarySender interface {
 	SendTextBinary(modelstorage.TextOrBinary) (codes.Code, error)
 }
 
+// BatchSender defines a set of methods for types implementing BatchSender.
+type BatchSender interface {
+	SendBatch(modelstorage.Batch, bool) ([]modelstorage.BatchItemResult, codes.Code, error)
+}
+
 // Remover defines a set of methods for types implementing Remover.
 type Remover interface {
 	RemoveBankCard(string) (codes.Code, error)
@@ -44,12 +49,68 @@ type Remover interface {
 	RemoveTextBinary(string) (codes.Code, error)
 }
 
+// ClientSharer defines a set of methods for types implementing ClientSharer.
+type ClientSharer interface {
+	SetPublicKey(publicKey string) (codes.Code, error)
+	GetPublicKey(login string) (string, codes.Code, error)
+	ShareEntry(identifier, db, recipient, permission string, sealed modelstorage.SealedEntry) (codes.Code, error)
+	RevokeShare(identifier, db, recipient string, sealed modelstorage.SealedEntry) (codes.Code, error)
+	SetSharePayload(identifier, db, payload string) (codes.Code, error)
+	GetShares() ([]modelstorage.Share, codes.Code, error)
+	GetSharedWithMe() ([]modelstorage.SharedEntry, codes.Code, error)
+	UpdateSharedEntry(entry modelstorage.SharedEntry, payload string) (codes.Code, error)
+}
+
+// ClientCollector defines a set of methods for types implementing ClientCollector.
+type ClientCollector interface {
+	CreateCollection(name string) (modelstorage.Collection, codes.Code, error)
+	GetCollections() ([]modelstorage.Collection, codes.Code, error)
+	DeleteCollection(collectionID string) (codes.Code, error)
+	InviteMember(collectionID, login, role string) (codes.Code, error)
+	GetInvitations() ([]modelstorage.CollectionInvitation, codes.Code, error)
+	RespondInvitation(invitationID int64, accept bool) (codes.Code, error)
+	GetMembers(collectionID string) ([]modelstorage.CollectionMember, codes.Code, error)
+	SetMemberRole(collectionID, login, role string) (codes.Code, error)
+	RemoveMember(collectionID, login string) (codes.Code, error)
+	GetCollectionEvents(collectionID string) ([]modelstorage.CollectionEvent, codes.Code, error)
+}
+
+// ClientAuditor defines a set of methods for types implementing ClientAuditor.
+type ClientAuditor interface {
+	ListAuditEvents(beforeID int64, limit int) ([]modelstorage.AuditEvent, codes.Code, error)
+}
+
 // ClientAuthorizer defines a set of methods for types implementing ClientAuthorizer.
 type ClientAuthorizer interface {
 	Login(modelstorage.RegisterLogin) (codes.Code, error)
 	Register(modelstorage.RegisterLogin) (codes.Code, error)
 }
 
+// ClientSessionManager defines a set of methods for types implementing ClientSessionManager.
+type ClientSessionManager interface {
+	ListSessions() ([]modelstorage.Session, codes.Code, error)
+	RevokeSession(sessionID int64) (codes.Code, error)
+}
+
+// ClientEmergencyAccessor defines a set of methods for types implementing ClientEmergencyAccessor.
+type ClientEmergencyAccessor interface {
+	SetTrustedContact(login string, waitDays int) (codes.Code, error)
+	RemoveTrustedContact(login string) (codes.Code, error)
+	ListEmergencyAccess() (modelstorage.EmergencyAccessList, codes.Code, error)
+	RequestEmergencyAccess(owner string) (codes.Code, error)
+	ApproveEmergencyAccess(contact string) (codes.Code, error)
+	RejectEmergencyAccess(contact string) (codes.Code, error)
+	SetEmergencyEntries(entries []modelstorage.EmergencyEntry) (codes.Code, error)
+	GetEmergencyEntries() ([]modelstorage.EmergencyEntry, codes.Code, error)
+	GetEmergencyVault(owner string) ([]modelstorage.EmergencyEntry, codes.Code, error)
+}
+
+// SessionKeeper defines a set of methods for types implementing SessionKeeper.
+type SessionKeeper interface {
+	Token() (token, sessionKey string)
+	SetToken(token, sessionKey string)
+}
+
 // GRPCClient defines a set of embedded interfaces for types implementing GRPCClient.
 type GRPCClient interface {
 	TextsBinariesGetter
@@ -58,6 +119,13 @@ type GRPCClient interface {
 	BankCardSender
 	LoginPasswordSender
 	TextBinarySender
+	BatchSender
 	Remover
+	ClientSharer
+	ClientCollector
+	ClientAuditor
 	ClientAuthorizer
+	ClientSessionManager
+	ClientEmergencyAccessor
+	SessionKeeper
 }
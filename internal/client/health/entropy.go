@@ -0,0 +1,288 @@
+package health
+
+import (
+	"bufio"
+	_ "embed"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// names of detected password patterns
+const (
+	PatternCommonPassword = "common password"
+	PatternCommonWord     = "common word"
+	PatternRepeat         = "repeated characters"
+	PatternSequence       = "sequence"
+	PatternKeyboard       = "keyboard pattern"
+	PatternYear           = "year"
+	PatternContext        = "contains login or identifier"
+)
+
+// character pool sizes of an entropy estimate
+const (
+	lowerPool   = 26
+	upperPool   = 26
+	digitPool   = 10
+	symbolPool  = 33
+	unicodePool = 100
+)
+
+// minimum lengths of detected patterns
+const (
+	minWordLength     = 4
+	minRepeatLength   = 3
+	minSequenceLength = 3
+	minKeyboardLength = 4
+	minContextLength  = 3
+)
+
+// yearSpace is the amount of years a year pattern is guessed from.
+const yearSpace = 200
+
+// keyboardRows are rows of a QWERTY keyboard checked for keyboard patterns.
+var keyboardRows = []string{"1234567890", "qwertyuiop", "asdfghjkl", "zxcvbnm"}
+
+// commonPasswordsData holds the most common leaked passwords, one per line, ordered by popularity.
+//
+//go:embed common_passwords.txt
+var commonPasswordsData string
+
+// commonPasswords maps the most common passwords to their popularity rank.
+var commonPasswords = parseCommonPasswords(commonPasswordsData)
+
+// match is a detected pattern covering a part of a password.
+type match struct {
+	pattern    string
+	start, end int
+	entropy    float64
+}
+
+// Estimate estimates entropy of a password in bits: characters are guessed from the pool of character classes present in
+// the password, while the parts matching common patterns cost only as much as guessing the pattern does. Context strings
+// such as the login or the identifier of the entry are treated as known to an attacker. The detected patterns are returned
+// sorted by name.
+func Estimate(password string, context ...string) (float64, []string) {
+	runes := []rune(strings.ToLower(password))
+	if len(runes) == 0 {
+		return 0, nil
+	}
+	pool := poolSize(password)
+	if rank, ok := commonPasswords[string(runes)]; ok {
+		return math.Log2(float64(rank + 1)), []string{PatternCommonPassword}
+	}
+	var candidates []match
+	candidates = append(candidates, contextMatches(runes, context)...)
+	candidates = append(candidates, wordMatches(runes)...)
+	candidates = append(candidates, keyboardMatches(runes)...)
+	candidates = append(candidates, yearMatches(runes)...)
+	candidates = append(candidates, repeatMatches(runes, pool)...)
+	candidates = append(candidates, sequenceMatches(runes, pool)...)
+	// the longest and then the cheapest patterns win, a character is covered by a single pattern at most
+	sort.Slice(candidates, func(i, j int) bool {
+		left, right := candidates[i], candidates[j]
+		if left.end-left.start != right.end-right.start {
+			return left.end-left.start > right.end-right.start
+		}
+		if left.entropy != right.entropy {
+			return left.entropy < right.entropy
+		}
+		if left.start != right.start {
+			return left.start < right.start
+		}
+		return left.pattern < right.pattern
+	})
+	covered := make([]bool, len(runes))
+	found := make(map[string]struct{})
+	var entropy float64
+	for _, candidate := range candidates {
+		if overlaps(covered, candidate) {
+			continue
+		}
+		for i := candidate.start; i < candidate.end; i++ {
+			covered[i] = true
+		}
+		found[candidate.pattern] = struct{}{}
+		entropy += candidate.entropy
+	}
+	for _, isCovered := range covered {
+		if !isCovered {
+			entropy += math.Log2(float64(pool))
+		}
+	}
+	var patterns []string
+	for pattern := range found {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+	return entropy, patterns
+}
+
+// poolSize returns the size of the character pool of character classes present in a password.
+func poolSize(password string) int {
+	var lower, upper, digit, symbol, other bool
+	for _, r := range password {
+		switch {
+		case r >= 'a' && r <= 'z':
+			lower = true
+		case r >= 'A' && r <= 'Z':
+			upper = true
+		case r >= '0' && r <= '9':
+			digit = true
+		case r < unicode.MaxASCII:
+			symbol = true
+		default:
+			other = true
+		}
+	}
+	var pool int
+	for _, class := range []struct {
+		present bool
+		size    int
+	}{{lower, lowerPool}, {upper, upperPool}, {digit, digitPool}, {symbol, symbolPool}, {other, unicodePool}} {
+		if class.present {
+			pool += class.size
+		}
+	}
+	return pool
+}
+
+// overlaps reports whether a match covers an already covered character.
+func overlaps(covered []bool, candidate match) bool {
+	for i := candidate.start; i < candidate.end; i++ {
+		if covered[i] {
+			return true
+		}
+	}
+	return false
+}
+
+// contextMatches finds occurrences of context strings, a login is matched with and without its email domain.
+func contextMatches(runes []rune, context []string) []match {
+	var matches []match
+	for _, value := range context {
+		value = strings.ToLower(value)
+		values := []string{value}
+		if at := strings.Index(value, "@"); at > 0 {
+			values = append(values, value[:at])
+		}
+		for _, value := range values {
+			if len([]rune(value)) >= minContextLength {
+				matches = append(matches, substringMatches(runes, []rune(value), PatternContext, 1)...)
+			}
+		}
+	}
+	return matches
+}
+
+// wordMatches finds occurrences of common passwords used as words.
+func wordMatches(runes []rune) []match {
+	var matches []match
+	for word, rank := range commonPasswords {
+		if len([]rune(word)) >= minWordLength {
+			matches = append(matches, substringMatches(runes, []rune(word), PatternCommonWord, math.Log2(float64(rank+1))+1)...)
+		}
+	}
+	return matches
+}
+
+// keyboardMatches finds runs of adjacent keys of a keyboard row typed in either direction.
+func keyboardMatches(runes []rune) []match {
+	var matches []match
+	for _, row := range keyboardRows {
+		for _, keys := range []string{row, reverse(row)} {
+			keys := []rune(keys)
+			for length := len(keys); length >= minKeyboardLength; length-- {
+				for start := 0; start+length <= len(keys); start++ {
+					entropy := math.Log2(float64(len(keyboardRows)*2*len(row))) + math.Log2(float64(length))
+					matches = append(matches, substringMatches(runes, keys[start:start+length], PatternKeyboard, entropy)...)
+				}
+			}
+		}
+	}
+	return matches
+}
+
+// yearMatches finds years of the 20th and the 21st centuries.
+func yearMatches(runes []rune) []match {
+	var matches []match
+	for i := 0; i+4 <= len(runes); i++ {
+		prefix := string(runes[i : i+2])
+		if (prefix == "19" || prefix == "20") && unicode.IsDigit(runes[i+2]) && unicode.IsDigit(runes[i+3]) {
+			matches = append(matches, match{pattern: PatternYear, start: i, end: i + 4, entropy: math.Log2(yearSpace)})
+		}
+	}
+	return matches
+}
+
+// repeatMatches finds runs of a single repeated character.
+func repeatMatches(runes []rune, pool int) []match {
+	var matches []match
+	for start := 0; start < len(runes); {
+		end := start + 1
+		for end < len(runes) && runes[end] == runes[start] {
+			end++
+		}
+		if end-start >= minRepeatLength {
+			matches = append(matches, match{pattern: PatternRepeat, start: start, end: end, entropy: math.Log2(float64(pool)) + math.Log2(float64(end-start))})
+		}
+		start = end
+	}
+	return matches
+}
+
+// sequenceMatches finds runs of consecutive characters in either direction such as abc or 987.
+func sequenceMatches(runes []rune, pool int) []match {
+	var matches []match
+	for start := 0; start+1 < len(runes); {
+		step := runes[start+1] - runes[start]
+		end := start + 1
+		if step == 1 || step == -1 {
+			for end < len(runes) && runes[end]-runes[end-1] == step {
+				end++
+			}
+		}
+		if end-start >= minSequenceLength {
+			// the direction of a sequence is one more bit to guess
+			matches = append(matches, match{pattern: PatternSequence, start: start, end: end, entropy: math.Log2(float64(pool)) + math.Log2(float64(end-start)) + 1})
+			start = end
+			continue
+		}
+		start++
+	}
+	return matches
+}
+
+// substringMatches finds all occurrences of a pattern in a password.
+func substringMatches(runes, pattern []rune, name string, entropy float64) []match {
+	var matches []match
+	for start := 0; start+len(pattern) <= len(runes); start++ {
+		if string(runes[start:start+len(pattern)]) == string(pattern) {
+			matches = append(matches, match{pattern: name, start: start, end: start + len(pattern), entropy: entropy})
+		}
+	}
+	return matches
+}
+
+// reverse returns a string with its characters in reverse order.
+func reverse(value string) string {
+	runes := []rune(value)
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+	return string(runes)
+}
+
+// parseCommonPasswords parses a list of passwords ordered by popularity.
+func parseCommonPasswords(data string) map[string]int {
+	passwords := make(map[string]int)
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		password := strings.TrimSpace(scanner.Text())
+		if _, ok := passwords[password]; password != "" && !ok {
+			passwords[password] = len(passwords)
+		}
+	}
+	return passwords
+}
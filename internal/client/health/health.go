@@ -0,0 +1,173 @@
+// Package health provides a local audit of the password health of vault entries.
+package health
+
+import (
+	"dk-go-gophkeeper/internal/client/breach"
+	"dk-go-gophkeeper/internal/client/storage/modelstorage"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// issues of an audited entry
+const (
+	IssueWeak     = "weak"
+	IssueReused   = "reused"
+	IssueOld      = "old"
+	IssueBreached = "breached"
+)
+
+// day is the duration of a single day of a password age.
+const day = 24 * time.Hour
+
+// Options defines parameters of an audit.
+type Options struct {
+	// MinEntropy is the estimated entropy in bits below which a password is weak.
+	MinEntropy float64
+	// MaxAgeDays is the number of days after which an unchanged password is old.
+	MaxAgeDays int
+	// Now is the time password ages are counted to.
+	Now time.Time
+	// Checker looks passwords up in known data breaches, the check is skipped if it is nil.
+	Checker breach.Checker
+}
+
+// DefaultOptions flags passwords weaker than 50 bits or not changed for a year.
+var DefaultOptions = Options{MinEntropy: 50, MaxAgeDays: 365}
+
+// Finding describes issues of a single login/password entry, passwords themselves are never reported.
+type Finding struct {
+	Identifier string   `json:"identifier"`
+	Issues     []string `json:"issues"`
+	Entropy    float64  `json:"entropy"`
+	Patterns   []string `json:"patterns,omitempty"`
+	ReusedWith []string `json:"reused_with,omitempty"`
+	AgeDays    *int     `json:"age_days,omitempty"`
+	// Breaches is the number of times the password was seen in data breaches.
+	Breaches int `json:"breaches,omitempty"`
+}
+
+// Report defines results of an audit.
+type Report struct {
+	GeneratedAt time.Time `json:"generated_at"`
+	MinEntropy  float64   `json:"min_entropy"`
+	MaxAgeDays  int       `json:"max_age_days"`
+	Checked     int       `json:"checked"`
+	Weak        int       `json:"weak"`
+	Reused      int       `json:"reused"`
+	Old         int       `json:"old"`
+	Breached    int       `json:"breached"`
+	// BreachCheck tells whether passwords were checked against data breaches.
+	BreachCheck bool `json:"breach_check"`
+	// Undated counts entries whose password change time is unknown because they were not retrieved from the server yet.
+	Undated  int       `json:"undated"`
+	Findings []Finding `json:"findings"`
+}
+
+// Audit checks passwords of login/password entries for weakness, reuse across identifiers, age and, given a checker,
+// presence in data breaches.
+func Audit(batch modelstorage.Batch, options Options) (Report, error) {
+	if options.Now.IsZero() {
+		options.Now = time.Now()
+	}
+	report := Report{
+		GeneratedAt: options.Now,
+		MinEntropy:  options.MinEntropy,
+		MaxAgeDays:  options.MaxAgeDays,
+		Checked:     len(batch.LoginsPasswords),
+		BreachCheck: options.Checker != nil,
+		Findings:    make([]Finding, 0),
+	}
+	// every distinct password is looked up once
+	breaches := make(map[string]int)
+	owners := make(map[string][]string)
+	for _, entry := range batch.LoginsPasswords {
+		if entry.Password != "" {
+			owners[entry.Password] = append(owners[entry.Password], entry.Identifier)
+		}
+	}
+	entries := append([]modelstorage.LoginAndPassword(nil), batch.LoginsPasswords...)
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Identifier < entries[j].Identifier
+	})
+	for _, entry := range entries {
+		entropy, patterns := Estimate(entry.Password, entry.Login, entry.Identifier)
+		finding := Finding{Identifier: entry.Identifier, Entropy: math.Round(entropy*10) / 10, Patterns: patterns}
+		if entropy < options.MinEntropy {
+			finding.Issues = append(finding.Issues, IssueWeak)
+			report.Weak++
+		}
+		for _, owner := range owners[entry.Password] {
+			if owner != entry.Identifier {
+				finding.ReusedWith = append(finding.ReusedWith, owner)
+			}
+		}
+		if len(finding.ReusedWith) > 0 {
+			sort.Strings(finding.ReusedWith)
+			finding.Issues = append(finding.Issues, IssueReused)
+			report.Reused++
+		}
+		if entry.PasswordChangedAt == nil {
+			report.Undated++
+		} else {
+			age := int(options.Now.Sub(*entry.PasswordChangedAt) / day)
+			finding.AgeDays = &age
+			if options.MaxAgeDays > 0 && age >= options.MaxAgeDays {
+				finding.Issues = append(finding.Issues, IssueOld)
+				report.Old++
+			}
+		}
+		if options.Checker != nil && entry.Password != "" {
+			count, ok := breaches[entry.Password]
+			if !ok {
+				var err error
+				count, err = options.Checker.Check(entry.Password)
+				if err != nil {
+					return Report{}, err
+				}
+				breaches[entry.Password] = count
+			}
+			if count > 0 {
+				finding.Breaches = count
+				finding.Issues = append(finding.Issues, IssueBreached)
+				report.Breached++
+			}
+		}
+		if len(finding.Issues) > 0 {
+			report.Findings = append(report.Findings, finding)
+		}
+	}
+	return report, nil
+}
+
+// Format renders a human-readable report.
+func Format(report Report) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Checked %d login/password entries: %d weak, %d reused, %d not changed in %d days.\n",
+		report.Checked, report.Weak, report.Reused, report.Old, report.MaxAgeDays))
+	if report.BreachCheck {
+		sb.WriteString(fmt.Sprintf("%d entries have passwords seen in data breaches.\n", report.Breached))
+	}
+	if report.Undated > 0 {
+		sb.WriteString(fmt.Sprintf("Password age of %d entries is unknown until they are synchronized with the server.\n", report.Undated))
+	}
+	for _, finding := range report.Findings {
+		sb.WriteString(fmt.Sprintf("\n%s: %s\n", finding.Identifier, strings.Join(finding.Issues, ", ")))
+		sb.WriteString(fmt.Sprintf("  estimated entropy: %.1f bits\n", finding.Entropy))
+		if len(finding.Patterns) > 0 {
+			sb.WriteString(fmt.Sprintf("  patterns: %s\n", strings.Join(finding.Patterns, ", ")))
+		}
+		if len(finding.ReusedWith) > 0 {
+			sb.WriteString(fmt.Sprintf("  also used by: %s\n", strings.Join(finding.ReusedWith, ", ")))
+		}
+		if finding.Breaches > 0 {
+			sb.WriteString(fmt.Sprintf("  seen in data breaches: %d times\n", finding.Breaches))
+		}
+		if finding.AgeDays != nil {
+			sb.WriteString(fmt.Sprintf("  last changed: %d days ago\n", *finding.AgeDays))
+		}
+	}
+	return sb.String()
+}
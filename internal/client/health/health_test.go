@@ -0,0 +1,118 @@
+package health
+
+import (
+	"dk-go-gophkeeper/internal/client/storage/modelstorage"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEstimate(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		context  []string
+		patterns []string
+		weak     bool
+	}{
+		{name: "empty", password: "", weak: true},
+		{name: "common password", password: "Qwerty", patterns: []string{PatternCommonPassword}, weak: true},
+		{name: "common word", password: "monkey7", patterns: []string{PatternCommonWord}, weak: true},
+		{name: "repeat", password: "zzzzzzzz", patterns: []string{PatternRepeat}, weak: true},
+		{name: "sequence", password: "mnopqr", patterns: []string{PatternSequence}, weak: true},
+		{name: "keyboard", password: "lkjhgf", patterns: []string{PatternKeyboard}, weak: true},
+		{name: "year", password: "x1987", patterns: []string{PatternYear}, weak: true},
+		{name: "context", password: "alice_Vault", context: []string{"alice@example.com"}, patterns: []string{PatternContext}, weak: true},
+		{name: "random", password: "xK9#mP2$vL7@qR4!", weak: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entropy, patterns := Estimate(tt.password, tt.context...)
+			assert.Equal(t, tt.patterns, patterns)
+			assert.Equal(t, tt.weak, entropy < DefaultOptions.MinEntropy)
+		})
+	}
+	entropy, patterns := Estimate("k7#Qw")
+	assert.Equal(t, 5*math.Log2(lowerPool+upperPool+digitPool+symbolPool), entropy)
+	assert.Equal(t, []string(nil), patterns)
+}
+
+func TestAudit(t *testing.T) {
+	now := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+	recent := now.Add(-10 * day)
+	stale := now.Add(-400 * day)
+	batch := modelstorage.Batch{
+		LoginsPasswords: []modelstorage.LoginAndPassword{
+			{Identifier: "shop", Login: "bob", Password: "xK9#mP2$vL7@qR4!", PasswordChangedAt: &recent},
+			{Identifier: "mail", Login: "bob", Password: "xK9#mP2$vL7@qR4!", PasswordChangedAt: &stale},
+			{Identifier: "bank", Login: "bob", Password: "letmein"},
+			{Identifier: "work", Login: "bob", Password: "Yt5!rQ8@wE3#uI6$", PasswordChangedAt: &recent},
+		},
+	}
+	report, err := Audit(batch, Options{MinEntropy: 50, MaxAgeDays: 365, Now: now})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 4, report.Checked)
+	assert.Equal(t, 1, report.Weak)
+	assert.Equal(t, 2, report.Reused)
+	assert.Equal(t, 1, report.Old)
+	assert.Equal(t, 1, report.Undated)
+	assert.Equal(t, 3, len(report.Findings))
+	assert.Equal(t, "bank", report.Findings[0].Identifier)
+	assert.Equal(t, []string{IssueWeak}, report.Findings[0].Issues)
+	assert.Equal(t, []string{PatternCommonPassword}, report.Findings[0].Patterns)
+	assert.Equal(t, (*int)(nil), report.Findings[0].AgeDays)
+	assert.Equal(t, "mail", report.Findings[1].Identifier)
+	assert.Equal(t, []string{IssueReused, IssueOld}, report.Findings[1].Issues)
+	assert.Equal(t, []string{"shop"}, report.Findings[1].ReusedWith)
+	assert.Equal(t, 400, *report.Findings[1].AgeDays)
+	assert.Equal(t, "shop", report.Findings[2].Identifier)
+	assert.Equal(t, []string{IssueReused}, report.Findings[2].Issues)
+
+	text := Format(report)
+	assert.Equal(t, true, strings.HasPrefix(text, "Checked 4 login/password entries: 1 weak, 2 reused, 1 not changed in 365 days.\n"))
+	assert.Equal(t, false, strings.Contains(text, "letmein"))
+}
+
+// checker is a breach checker of a fixed set of breached passwords counting lookups.
+type checker struct {
+	breached map[string]int
+	lookups  int
+	err      error
+}
+
+// Check implements the breach.Checker interface.
+func (c *checker) Check(password string) (int, error) {
+	c.lookups++
+	return c.breached[password], c.err
+}
+
+// Close implements the breach.Checker interface.
+func (c *checker) Close() error {
+	return nil
+}
+
+func TestAudit_Breaches(t *testing.T) {
+	batch := modelstorage.Batch{
+		LoginsPasswords: []modelstorage.LoginAndPassword{
+			{Identifier: "shop", Password: "Yt5!rQ8@wE3#uI6$"},
+			{Identifier: "mail", Password: "Yt5!rQ8@wE3#uI6$"},
+			{Identifier: "work", Password: "xK9#mP2$vL7@qR4!"},
+		},
+	}
+	breached := &checker{breached: map[string]int{"Yt5!rQ8@wE3#uI6$": 3}}
+	report, err := Audit(batch, Options{MinEntropy: 50, Checker: breached})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, breached.lookups)
+	assert.Equal(t, true, report.BreachCheck)
+	assert.Equal(t, 2, report.Breached)
+	assert.Equal(t, []string{IssueReused, IssueBreached}, report.Findings[0].Issues)
+	assert.Equal(t, 3, report.Findings[0].Breaches)
+	assert.Equal(t, true, strings.Contains(Format(report), "2 entries have passwords seen in data breaches.\n"))
+
+	_, err = Audit(batch, Options{Checker: &checker{err: errors.New("generic_error")}})
+	assert.Equal(t, "generic_error", err.Error())
+}
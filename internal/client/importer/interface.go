@@ -0,0 +1,29 @@
+// Package importer provides functionality for importing data exported by other password managers.
+package importer
+
+import (
+	"dk-go-gophkeeper/internal/client/importer/modelimport"
+	"dk-go-gophkeeper/internal/client/storage/modelstorage"
+	"io"
+)
+
+// Parser defines a set of methods for types implementing Parser.
+type Parser interface {
+	Parse(r io.Reader) (modelstorage.Batch, error)
+}
+
+// Previewer defines a set of methods for types implementing Previewer.
+type Previewer interface {
+	Preview(format string, r io.Reader) (modelimport.Report, error)
+}
+
+// Uploader defines a set of methods for types implementing Uploader.
+type Uploader interface {
+	Import(format string, r io.Reader) (modelimport.Report, error)
+}
+
+// Importer defines a set of embedded interfaces for types implementing Importer.
+type Importer interface {
+	Previewer
+	Uploader
+}
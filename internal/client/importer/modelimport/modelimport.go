@@ -0,0 +1,17 @@
+// Package modelimport provides models for importing data.
+package modelimport
+
+type (
+	Item struct {
+		Identifier string
+		Db         string
+		Reason     string
+	}
+	Report struct {
+		Format     string
+		DryRun     bool
+		New        []Item
+		Duplicates []Item
+		Failed     []Item
+	}
+)
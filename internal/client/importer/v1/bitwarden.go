@@ -0,0 +1,101 @@
+package importer
+
+import (
+	"dk-go-gophkeeper/internal/client/storage/modelstorage"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Bitwarden item types
+
+const (
+	bitwardenLogin      = 1
+	bitwardenSecureNote = 2
+	bitwardenCard       = 3
+	bitwardenIdentity   = 4
+)
+
+type (
+	bitwardenExport struct {
+		Encrypted bool            `json:"encrypted"`
+		Items     []bitwardenItem `json:"items"`
+	}
+	bitwardenItem struct {
+		Type  int                 `json:"type"`
+		Name  string              `json:"name"`
+		Notes string              `json:"notes"`
+		Login *bitwardenLoginData `json:"login"`
+		Card  *bitwardenCardData  `json:"card"`
+	}
+	bitwardenLoginData struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+		URIs     []struct {
+			URI string `json:"uri"`
+		} `json:"uris"`
+	}
+	bitwardenCardData struct {
+		CardholderName string `json:"cardholderName"`
+		Brand          string `json:"brand"`
+		Number         string `json:"number"`
+		ExpMonth       string `json:"expMonth"`
+		ExpYear        string `json:"expYear"`
+		Code           string `json:"code"`
+	}
+)
+
+// BitwardenParser parses unencrypted Bitwarden JSON exports.
+type BitwardenParser struct{}
+
+// Parse converts a Bitwarden JSON export into a batch of entries.
+func (p *BitwardenParser) Parse(r io.Reader) (modelstorage.Batch, error) {
+	var export bitwardenExport
+	if err := json.NewDecoder(r).Decode(&export); err != nil {
+		return modelstorage.Batch{}, fmt.Errorf("invalid Bitwarden export: %w", err)
+	}
+	if export.Encrypted {
+		return modelstorage.Batch{}, errors.New("encrypted Bitwarden exports are not supported")
+	}
+	var batch modelstorage.Batch
+	for _, item := range export.Items {
+		switch {
+		case item.Type == bitwardenLogin && item.Login != nil:
+			var uri string
+			if len(item.Login.URIs) > 0 {
+				uri = item.Login.URIs[0].URI
+			}
+			batch.LoginsPasswords = append(batch.LoginsPasswords, modelstorage.LoginAndPassword{
+				Identifier: item.Name,
+				Login:      item.Login.Username,
+				Password:   item.Login.Password,
+				Meta:       joinMeta(uri, item.Notes),
+			})
+		case item.Type == bitwardenCard && item.Card != nil:
+			// an expiry date that cannot be parsed is kept in meta
+			var note string
+			expiry := cardExpiry(item.Card.ExpMonth + "/" + item.Card.ExpYear)
+			if expiry == "" && (item.Card.ExpMonth != "" || item.Card.ExpYear != "") {
+				note = fmt.Sprintf("exp %s/%s", item.Card.ExpMonth, item.Card.ExpYear)
+			}
+			batch.BankCards = append(batch.BankCards, modelstorage.BankCard{
+				Identifier: item.Name,
+				Number:     item.Card.Number,
+				Holder:     item.Card.CardholderName,
+				Cvv:        item.Card.Code,
+				Expiry:     expiry,
+				Meta:       joinMeta(item.Card.Brand, note, item.Notes),
+			})
+		case item.Type == bitwardenSecureNote || item.Type == bitwardenIdentity:
+			if item.Notes == "" {
+				continue
+			}
+			batch.TextsBinaries = append(batch.TextsBinaries, modelstorage.TextOrBinary{
+				Identifier: item.Name,
+				Entry:      item.Notes,
+			})
+		}
+	}
+	return batch, nil
+}
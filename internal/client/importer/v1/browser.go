@@ -0,0 +1,32 @@
+package importer
+
+import (
+	"dk-go-gophkeeper/internal/client/storage/modelstorage"
+	"io"
+)
+
+// BrowserParser parses password CSV exports of Chrome, Firefox, Edge and Safari.
+type BrowserParser struct{}
+
+// Parse converts a browser password CSV export into a batch of login/password entries.
+func (p *BrowserParser) Parse(r io.Reader) (modelstorage.Batch, error) {
+	records, err := readCSV(r)
+	if err != nil {
+		return modelstorage.Batch{}, err
+	}
+	var batch modelstorage.Batch
+	for _, record := range records {
+		rawURL := record.get("url", "origin_url")
+		identifier := record.get("name", "title")
+		if identifier == "" {
+			identifier = hostOf(rawURL)
+		}
+		batch.LoginsPasswords = append(batch.LoginsPasswords, modelstorage.LoginAndPassword{
+			Identifier: identifier,
+			Login:      record.get("username", "username_value"),
+			Password:   record.get("password", "password_value"),
+			Meta:       joinMeta(rawURL, record.get("note", "notes")),
+		})
+	}
+	return batch, nil
+}
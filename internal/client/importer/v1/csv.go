@@ -0,0 +1,59 @@
+package importer
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+)
+
+// csvRecord maps lower-cased CSV header names to row values.
+type csvRecord map[string]string
+
+// get returns the value of the first present column among the given names.
+func (r csvRecord) get(names ...string) string {
+	for _, name := range names {
+		if value, ok := r[name]; ok && value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// readCSV reads a CSV export with a header row into a list of records.
+func readCSV(r io.Reader) ([]csvRecord, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV export: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	header := make([]string, len(rows[0]))
+	for i, name := range rows[0] {
+		header[i] = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+	}
+	records := make([]csvRecord, 0, len(rows)-1)
+	for _, row := range rows[1:] {
+		record := make(csvRecord, len(header))
+		for i, value := range row {
+			if i < len(header) {
+				record[header[i]] = value
+			}
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// hostOf returns the host part of a URL or the URL itself when it cannot be parsed.
+func hostOf(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" {
+		return rawURL
+	}
+	return parsed.Host
+}
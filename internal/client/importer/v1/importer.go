@@ -0,0 +1,223 @@
+// Package importer provides functionality for importing data exported by other password managers.
+package importer
+
+import (
+	"dk-go-gophkeeper/internal/client/importer"
+	"dk-go-gophkeeper/internal/client/importer/modelimport"
+	"dk-go-gophkeeper/internal/client/storage"
+	"dk-go-gophkeeper/internal/client/storage/modelstorage"
+	"dk-go-gophkeeper/internal/config"
+	"dk-go-gophkeeper/internal/validation"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+// supported export formats
+
+const (
+	FormatBitwarden   = "bitwarden"
+	FormatKeePass     = "keepass"
+	FormatOnePassword = "1password"
+	FormatBrowser     = "browser"
+)
+
+// defaultBatchSize is used when no positive batch size was configured.
+const defaultBatchSize = 50
+
+// check for interface compliance
+var (
+	_ importer.Importer = (*Importer)(nil)
+)
+
+// Importer defines attributes and methods of an Importer instance.
+type Importer struct {
+	storage storage.DataStorage
+	parsers map[string]importer.Parser
+	logger  *zerolog.Logger
+	cfg     *config.Config
+}
+
+// InitImporter initializes an Importer instance with all supported parsers.
+func InitImporter(st storage.DataStorage, logger *zerolog.Logger, cfg *config.Config) *Importer {
+	logger.Info().Msg("Attempting to initialize importer")
+	return &Importer{
+		storage: st,
+		parsers: map[string]importer.Parser{
+			FormatBitwarden:   &BitwardenParser{},
+			FormatKeePass:     &KeePassParser{},
+			FormatOnePassword: &OnePasswordParser{},
+			FormatBrowser:     &BrowserParser{},
+		},
+		logger: logger,
+		cfg:    cfg,
+	}
+}
+
+// Formats returns a sorted list of supported export formats.
+func Formats() []string {
+	formats := []string{FormatBitwarden, FormatKeePass, FormatOnePassword, FormatBrowser}
+	sort.Strings(formats)
+	return formats
+}
+
+// Preview parses an export and reports which entries would be imported without uploading anything.
+func (imp *Importer) Preview(format string, r io.Reader) (modelimport.Report, error) {
+	imp.logger.Info().Msgf("Previewing import of %s export", format)
+	batch, err := imp.parse(format, r)
+	if err != nil {
+		return modelimport.Report{}, err
+	}
+	report, _ := imp.classify(format, batch)
+	report.DryRun = true
+	return report, nil
+}
+
+// Import parses an export and uploads all non-duplicate entries in batches.
+func (imp *Importer) Import(format string, r io.Reader) (modelimport.Report, error) {
+	imp.logger.Info().Msgf("Importing %s export", format)
+	batch, err := imp.parse(format, r)
+	if err != nil {
+		return modelimport.Report{}, err
+	}
+	report, fresh := imp.classify(format, batch)
+	report.New = nil
+	for _, chunk := range splitBatch(fresh, imp.batchSize()) {
+		for _, result := range imp.storage.AddBatch(chunk) {
+			item := modelimport.Item{Identifier: result.Identifier, Db: result.Db}
+			if result.Err != nil {
+				item.Reason = result.Err.Error()
+				report.Failed = append(report.Failed, item)
+				continue
+			}
+			report.New = append(report.New, item)
+		}
+	}
+	imp.logger.Info().Msgf("Import finished: %d new, %d duplicates, %d failed", len(report.New), len(report.Duplicates), len(report.Failed))
+	return report, nil
+}
+
+// parse selects a parser for the given format and runs it.
+func (imp *Importer) parse(format string, r io.Reader) (modelstorage.Batch, error) {
+	parser, ok := imp.parsers[format]
+	if !ok {
+		return modelstorage.Batch{}, fmt.Errorf("unsupported import format %s", format)
+	}
+	batch, err := parser.Parse(r)
+	if err != nil {
+		imp.logger.Error().Err(err).Msgf("Could not parse %s export", format)
+		return modelstorage.Batch{}, err
+	}
+	return batch, nil
+}
+
+// classify splits parsed entries into new ones, duplicates of existing local entries and invalid ones.
+func (imp *Importer) classify(format string, batch modelstorage.Batch) (modelimport.Report, modelstorage.Batch) {
+	report := modelimport.Report{Format: format}
+	var fresh modelstorage.Batch
+	seen := make(map[string]bool)
+	// check validates an identifier and renames repeated identifiers within the same export
+	check := func(identifier, db string) (string, bool) {
+		identifier = strings.TrimSpace(identifier)
+		if identifier == "" {
+			report.Failed = append(report.Failed, modelimport.Item{Db: db, Reason: "identifier cannot be empty"})
+			return "", false
+		}
+		unique := identifier
+		for n := 2; seen[db+"/"+unique]; n++ {
+			unique = fmt.Sprintf("%s (%d)", identifier, n)
+		}
+		seen[db+"/"+unique] = true
+		if imp.storage.Exists(unique, db) {
+			report.Duplicates = append(report.Duplicates, modelimport.Item{Identifier: unique, Db: db, Reason: "already exists"})
+			return "", false
+		}
+		report.New = append(report.New, modelimport.Item{Identifier: unique, Db: db})
+		return unique, true
+	}
+	for _, bankCard := range batch.BankCards {
+		if identifier, ok := check(bankCard.Identifier, imp.cfg.BankCardDB); ok {
+			bankCard.Identifier = identifier
+			fresh.BankCards = append(fresh.BankCards, bankCard)
+		}
+	}
+	for _, loginPassword := range batch.LoginsPasswords {
+		if identifier, ok := check(loginPassword.Identifier, imp.cfg.LoginPasswordDB); ok {
+			loginPassword.Identifier = identifier
+			fresh.LoginsPasswords = append(fresh.LoginsPasswords, loginPassword)
+		}
+	}
+	for _, textBinary := range batch.TextsBinaries {
+		if identifier, ok := check(textBinary.Identifier, imp.cfg.TextBinaryDB); ok {
+			textBinary.Identifier = identifier
+			fresh.TextsBinaries = append(fresh.TextsBinaries, textBinary)
+		}
+	}
+	return report, fresh
+}
+
+// batchSize returns the configured upload batch size.
+func (imp *Importer) batchSize() int {
+	if imp.cfg.ImportBatchSize > 0 {
+		return imp.cfg.ImportBatchSize
+	}
+	return defaultBatchSize
+}
+
+// splitBatch splits a batch of mixed entries into chunks containing at most size entries each.
+func splitBatch(batch modelstorage.Batch, size int) []modelstorage.Batch {
+	var chunks []modelstorage.Batch
+	var chunk modelstorage.Batch
+	count := 0
+	advance := func() {
+		count++
+		if count == size {
+			chunks = append(chunks, chunk)
+			chunk = modelstorage.Batch{}
+			count = 0
+		}
+	}
+	for _, bankCard := range batch.BankCards {
+		chunk.BankCards = append(chunk.BankCards, bankCard)
+		advance()
+	}
+	for _, loginPassword := range batch.LoginsPasswords {
+		chunk.LoginsPasswords = append(chunk.LoginsPasswords, loginPassword)
+		advance()
+	}
+	for _, textBinary := range batch.TextsBinaries {
+		chunk.TextsBinaries = append(chunk.TextsBinaries, textBinary)
+		advance()
+	}
+	if count > 0 {
+		chunks = append(chunks, chunk)
+	}
+	return chunks
+}
+
+// cardExpiry converts an exported expiry date to the MM/YY form, an empty string is returned if it is not a valid date.
+func cardExpiry(expiry string) string {
+	// exports do not always pad months with a zero
+	if month, year, ok := strings.Cut(strings.TrimSpace(expiry), "/"); ok && len(month) == 1 {
+		expiry = "0" + month + "/" + year
+	}
+	if _, _, err := validation.ParseExpiry(expiry); err != nil {
+		return ""
+	}
+	return validation.NormalizeExpiry(expiry)
+}
+
+// joinMeta joins non-empty meta parts into a single meta string.
+func joinMeta(parts ...string) string {
+	var nonEmpty []string
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if part != "" {
+			nonEmpty = append(nonEmpty, part)
+		}
+	}
+	return strings.Join(nonEmpty, "; ")
+}
@@ -0,0 +1,185 @@
+package importer
+
+import (
+	"dk-go-gophkeeper/internal/client/storage/inmemory"
+	"dk-go-gophkeeper/internal/client/storage/modelstorage"
+	"dk-go-gophkeeper/internal/config"
+	"dk-go-gophkeeper/internal/mocks"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+)
+
+const bitwardenExportJSON = `{
+  "encrypted": false,
+  "items": [
+    {"type": 1, "name": "github", "notes": "work", "login": {"username": "user", "password": "pass", "uris": [{"uri": "https://github.com"}]}},
+    {"type": 2, "name": "note", "notes": "some text"},
+    {"type": 3, "name": "visa", "card": {"cardholderName": "JOHN DOE", "brand": "Visa", "number": "4111111111111111", "expMonth": "12", "expYear": "2030", "code": "123"}}
+  ]
+}`
+
+const keePassExportXML = `<?xml version="1.0" encoding="utf-8" standalone="yes"?>
+<KeePassFile>
+  <Root>
+    <Group>
+      <Name>Root</Name>
+      <Entry>
+        <String><Key>Title</Key><Value>mail</Value></String>
+        <String><Key>UserName</Key><Value>user</Value></String>
+        <String><Key>Password</Key><Value>pass</Value></String>
+        <String><Key>URL</Key><Value>https://mail.example.com</Value></String>
+        <History>
+          <Entry>
+            <String><Key>Title</Key><Value>mail-old</Value></String>
+            <String><Key>Password</Key><Value>old</Value></String>
+          </Entry>
+        </History>
+      </Entry>
+      <Group>
+        <Name>Notes</Name>
+        <Entry>
+          <String><Key>Title</Key><Value>wifi</Value></String>
+          <String><Key>Notes</Key><Value>secret</Value></String>
+        </Entry>
+      </Group>
+    </Group>
+  </Root>
+</KeePassFile>`
+
+const onePasswordExportCSV = `"Title","Url","Username","Password","OTPAuth","Favorite","Archived","Tags","Notes"
+"github","https://github.com","user","pass","","false","false","dev",""
+"note","","","","","false","false","","some text"
+`
+
+const browserExportCSV = `name,url,username,password,note
+github.com,https://github.com/login,user,pass,
+,https://example.com/login,user2,pass2,comment
+`
+
+func newTestImporter(t *testing.T) (*Importer, *inmemory.Storage, *mocks.MockGRPCClient) {
+	cfg := config.NewDefaultConfiguration()
+	cfg.BankCardDB = "bankCard"
+	cfg.LoginPasswordDB = "loginPassword"
+	cfg.TextBinaryDB = "textBinary"
+	cfg.ImportBatchSize = 2
+	ctrl := gomock.NewController(t)
+	client := mocks.NewMockGRPCClient(ctrl)
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	st := inmemory.InitStorage(&logger, client, nil, cfg)
+	return InitImporter(st, &logger, cfg), st, client
+}
+
+func TestBitwardenParser_Parse(t *testing.T) {
+	parser := BitwardenParser{}
+	batch, err := parser.Parse(strings.NewReader(bitwardenExportJSON))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []modelstorage.LoginAndPassword{{Identifier: "github", Login: "user", Password: "pass", Meta: "https://github.com; work"}}, batch.LoginsPasswords)
+	assert.Equal(t, []modelstorage.TextOrBinary{{Identifier: "note", Entry: "some text"}}, batch.TextsBinaries)
+	assert.Equal(t, []modelstorage.BankCard{{Identifier: "visa", Number: "4111111111111111", Holder: "JOHN DOE", Cvv: "123", Expiry: "12/30", Meta: "Visa"}}, batch.BankCards)
+
+	_, err = parser.Parse(strings.NewReader(`{"encrypted": true}`))
+	assert.Equal(t, "encrypted Bitwarden exports are not supported", err.Error())
+	_, err = parser.Parse(strings.NewReader(`not json`))
+	assert.NotEqual(t, nil, err)
+}
+
+func TestKeePassParser_Parse(t *testing.T) {
+	parser := KeePassParser{}
+	batch, err := parser.Parse(strings.NewReader(keePassExportXML))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []modelstorage.LoginAndPassword{{Identifier: "mail", Login: "user", Password: "pass", Meta: "https://mail.example.com; Root"}}, batch.LoginsPasswords)
+	assert.Equal(t, []modelstorage.TextOrBinary{{Identifier: "wifi", Entry: "secret", Meta: "Root/Notes"}}, batch.TextsBinaries)
+}
+
+func TestOnePasswordParser_Parse(t *testing.T) {
+	parser := OnePasswordParser{}
+	batch, err := parser.Parse(strings.NewReader(onePasswordExportCSV))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []modelstorage.LoginAndPassword{{Identifier: "github", Login: "user", Password: "pass", Meta: "https://github.com; dev"}}, batch.LoginsPasswords)
+	assert.Equal(t, []modelstorage.TextOrBinary{{Identifier: "note", Entry: "some text"}}, batch.TextsBinaries)
+}
+
+func TestCardExpiry(t *testing.T) {
+	assert.Equal(t, "09/27", cardExpiry("9/2027"))
+	assert.Equal(t, "12/30", cardExpiry("12/30"))
+	assert.Equal(t, "", cardExpiry("/"))
+	assert.Equal(t, "", cardExpiry("203012"))
+}
+
+func TestBrowserParser_Parse(t *testing.T) {
+	parser := BrowserParser{}
+	batch, err := parser.Parse(strings.NewReader(browserExportCSV))
+	assert.Equal(t, nil, err)
+	expected := []modelstorage.LoginAndPassword{
+		{Identifier: "github.com", Login: "user", Password: "pass", Meta: "https://github.com/login"},
+		{Identifier: "example.com", Login: "user2", Password: "pass2", Meta: "https://example.com/login; comment"},
+	}
+	assert.Equal(t, expected, batch.LoginsPasswords)
+}
+
+func TestImporter_Preview(t *testing.T) {
+	imp, st, client := newTestImporter(t)
+	client.EXPECT().SendLoginPassword(gomock.Any()).Return(codes.OK, nil)
+	_ = st.AddLoginPassword("github", "user", "pass", "")
+
+	report, err := imp.Preview(FormatBitwarden, strings.NewReader(bitwardenExportJSON))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, report.DryRun)
+	assert.Equal(t, 2, len(report.New))
+	assert.Equal(t, "github", report.Duplicates[0].Identifier)
+	assert.Equal(t, false, st.Exists("note", "textBinary"))
+
+	_, err = imp.Preview("generic_format", strings.NewReader(""))
+	assert.Equal(t, "unsupported import format generic_format", err.Error())
+}
+
+func TestImporter_Import(t *testing.T) {
+	imp, st, client := newTestImporter(t)
+	// the batch size is 2, so entries are uploaded in two requests
+	client.EXPECT().SendBatch(gomock.Any(), false).Return([]modelstorage.BatchItemResult{
+		{Identifier: "visa", Db: "bankCard"},
+		{Identifier: "github", Db: "loginPassword", Err: errors.New("generic_error")},
+	}, codes.OK, nil)
+	client.EXPECT().SendBatch(gomock.Any(), false).Return([]modelstorage.BatchItemResult{
+		{Identifier: "note", Db: "textBinary"},
+	}, codes.OK, nil)
+
+	report, err := imp.Import(FormatBitwarden, strings.NewReader(bitwardenExportJSON))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, false, report.DryRun)
+	assert.Equal(t, 2, len(report.New))
+	assert.Equal(t, 1, len(report.Failed))
+	assert.Equal(t, "generic_error", report.Failed[0].Reason)
+	assert.Equal(t, true, st.Exists("visa", "bankCard"))
+	assert.Equal(t, true, st.Exists("note", "textBinary"))
+	assert.Equal(t, false, st.Exists("github", "loginPassword"))
+}
+
+func TestImporter_classifyRepeatedIdentifiers(t *testing.T) {
+	imp, _, _ := newTestImporter(t)
+	batch := modelstorage.Batch{LoginsPasswords: []modelstorage.LoginAndPassword{{Identifier: "site"}, {Identifier: "site"}, {Identifier: " "}}}
+	report, fresh := imp.classify(FormatBrowser, batch)
+	assert.Equal(t, "site", fresh.LoginsPasswords[0].Identifier)
+	assert.Equal(t, "site (2)", fresh.LoginsPasswords[1].Identifier)
+	assert.Equal(t, "identifier cannot be empty", report.Failed[0].Reason)
+}
+
+func TestSplitBatch(t *testing.T) {
+	batch := modelstorage.Batch{
+		BankCards:       []modelstorage.BankCard{{Identifier: "1"}},
+		LoginsPasswords: []modelstorage.LoginAndPassword{{Identifier: "2"}, {Identifier: "3"}},
+		TextsBinaries:   []modelstorage.TextOrBinary{{Identifier: "4"}},
+	}
+	chunks := splitBatch(batch, 3)
+	assert.Equal(t, 2, len(chunks))
+	assert.Equal(t, 1, len(chunks[0].BankCards))
+	assert.Equal(t, 2, len(chunks[0].LoginsPasswords))
+	assert.Equal(t, 1, len(chunks[1].TextsBinaries))
+}
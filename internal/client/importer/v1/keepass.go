@@ -0,0 +1,73 @@
+package importer
+
+import (
+	"dk-go-gophkeeper/internal/client/storage/modelstorage"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+)
+
+type (
+	keePassFile struct {
+		Root struct {
+			Groups []keePassGroup `xml:"Group"`
+		} `xml:"Root"`
+	}
+	keePassGroup struct {
+		Name    string         `xml:"Name"`
+		Entries []keePassEntry `xml:"Entry"`
+		Groups  []keePassGroup `xml:"Group"`
+	}
+	keePassEntry struct {
+		Strings []struct {
+			Key   string `xml:"Key"`
+			Value string `xml:"Value"`
+		} `xml:"String"`
+	}
+)
+
+// KeePassParser parses KeePass 2.x XML exports.
+type KeePassParser struct{}
+
+// Parse converts a KeePass XML export into a batch of entries.
+func (p *KeePassParser) Parse(r io.Reader) (modelstorage.Batch, error) {
+	var file keePassFile
+	if err := xml.NewDecoder(r).Decode(&file); err != nil {
+		return modelstorage.Batch{}, fmt.Errorf("invalid KeePass export: %w", err)
+	}
+	var batch modelstorage.Batch
+	for _, group := range file.Root.Groups {
+		p.walk(&batch, group, "")
+	}
+	return batch, nil
+}
+
+// walk recursively collects entries of a group and its subgroups.
+func (p *KeePassParser) walk(batch *modelstorage.Batch, group keePassGroup, parent string) {
+	groupPath := path.Join(parent, group.Name)
+	for _, entry := range group.Entries {
+		fields := make(map[string]string)
+		for _, field := range entry.Strings {
+			fields[field.Key] = field.Value
+		}
+		switch {
+		case fields["UserName"] != "" || fields["Password"] != "":
+			batch.LoginsPasswords = append(batch.LoginsPasswords, modelstorage.LoginAndPassword{
+				Identifier: fields["Title"],
+				Login:      fields["UserName"],
+				Password:   fields["Password"],
+				Meta:       joinMeta(fields["URL"], groupPath, fields["Notes"]),
+			})
+		case fields["Notes"] != "":
+			batch.TextsBinaries = append(batch.TextsBinaries, modelstorage.TextOrBinary{
+				Identifier: fields["Title"],
+				Entry:      fields["Notes"],
+				Meta:       groupPath,
+			})
+		}
+	}
+	for _, subgroup := range group.Groups {
+		p.walk(batch, subgroup, groupPath)
+	}
+}
@@ -0,0 +1,52 @@
+package importer
+
+import (
+	"dk-go-gophkeeper/internal/client/storage/modelstorage"
+	"io"
+)
+
+// OnePasswordParser parses 1Password CSV exports of logins and credit cards.
+type OnePasswordParser struct{}
+
+// Parse converts a 1Password CSV export into a batch of entries.
+func (p *OnePasswordParser) Parse(r io.Reader) (modelstorage.Batch, error) {
+	records, err := readCSV(r)
+	if err != nil {
+		return modelstorage.Batch{}, err
+	}
+	var batch modelstorage.Batch
+	for _, record := range records {
+		title := record.get("title")
+		notes := record.get("notes", "notesplain")
+		switch {
+		case record.get("number", "card number") != "":
+			// an expiry date that cannot be parsed is kept in meta
+			note := record.get("expiry date", "expiry")
+			expiry := cardExpiry(note)
+			if expiry != "" {
+				note = ""
+			}
+			batch.BankCards = append(batch.BankCards, modelstorage.BankCard{
+				Identifier: title,
+				Number:     record.get("number", "card number"),
+				Holder:     record.get("cardholder name", "cardholder"),
+				Cvv:        record.get("verification number", "cvv"),
+				Expiry:     expiry,
+				Meta:       joinMeta(note, notes),
+			})
+		case record.get("username", "password") != "":
+			batch.LoginsPasswords = append(batch.LoginsPasswords, modelstorage.LoginAndPassword{
+				Identifier: title,
+				Login:      record.get("username"),
+				Password:   record.get("password"),
+				Meta:       joinMeta(record.get("url", "website"), record.get("tags"), notes),
+			})
+		case notes != "":
+			batch.TextsBinaries = append(batch.TextsBinaries, modelstorage.TextOrBinary{
+				Identifier: title,
+				Entry:      notes,
+			})
+		}
+	}
+	return batch, nil
+}
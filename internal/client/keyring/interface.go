@@ -0,0 +1,18 @@
+// Package keyring provides keeping of key pairs of users on their clients, so that private keys never leave them.
+package keyring
+
+// PrivateKeyLoader defines a set of methods for types implementing PrivateKeyLoader.
+type PrivateKeyLoader interface {
+	PrivateKey(publicKey string) (string, error)
+}
+
+// KeyPairCreator defines a set of methods for types implementing KeyPairCreator.
+type KeyPairCreator interface {
+	NewKeyPair() (string, error)
+}
+
+// Keeper defines a set of embedded interfaces for types implementing Keeper.
+type Keeper interface {
+	PrivateKeyLoader
+	KeyPairCreator
+}
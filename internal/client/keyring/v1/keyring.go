@@ -0,0 +1,141 @@
+// Package keyring provides a file-based keyring readable by its owner only.
+package keyring
+
+import (
+	"dk-go-gophkeeper/internal/client/keyring"
+	"dk-go-gophkeeper/internal/config"
+	"dk-go-gophkeeper/internal/keys"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/rs/zerolog"
+)
+
+// file and directory permissions of the keyring
+const (
+	fileMode os.FileMode = 0600
+	dirMode  os.FileMode = 0700
+)
+
+// ErrNoKeyPair is returned when the keyring holds no private key of a published public key.
+var ErrNoKeyPair = errors.New("key pair of the user is not kept by this client")
+
+// check for interface compliance
+var (
+	_ keyring.Keeper = (*FileKeyring)(nil)
+)
+
+// FileKeyring defines attributes and methods of a FileKeyring instance. Private keys are kept by their public keys, so
+// that a single keyring serves every user logging in from the client.
+type FileKeyring struct {
+	path   string
+	mu     sync.Mutex
+	logger *zerolog.Logger
+}
+
+// InitFileKeyring initializes a FileKeyring instance storing key pairs at cfg.KeyringPath or, if it is not set,
+// in the user configuration directory.
+func InitFileKeyring(logger *zerolog.Logger, cfg *config.Config) (*FileKeyring, error) {
+	logger.Info().Msg("Attempting to initialize keyring")
+	path := cfg.KeyringPath
+	if path == "" {
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			return nil, err
+		}
+		path = filepath.Join(dir, "gophkeeper", "keyring.json")
+	}
+	return &FileKeyring{path: path, logger: logger}, nil
+}
+
+// PrivateKey returns a private key of the key pair of a public key.
+func (k *FileKeyring) PrivateKey(publicKey string) (string, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	pairs, err := k.load()
+	if err != nil {
+		return "", err
+	}
+	privateKey, ok := pairs[publicKey]
+	if !ok {
+		return "", fmt.Errorf("%w, copy %s from the client the key pair was created by", ErrNoKeyPair, k.path)
+	}
+	return privateKey, nil
+}
+
+// NewKeyPair creates a key pair, stores it in the keyring and returns its public key.
+func (k *FileKeyring) NewKeyPair() (string, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	pairs, err := k.load()
+	if err != nil {
+		return "", err
+	}
+	publicKey, privateKey, err := keys.NewKeyPair()
+	if err != nil {
+		return "", err
+	}
+	pairs[publicKey] = privateKey
+	if err = k.save(pairs); err != nil {
+		return "", err
+	}
+	return publicKey, nil
+}
+
+// load reads key pairs from the keyring, a missing keyring holds none.
+func (k *FileKeyring) load() (map[string]string, error) {
+	pairs := make(map[string]string)
+	info, err := os.Lstat(k.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return pairs, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	// refuse to use a keyring which could have been read or replaced by other users
+	if !info.Mode().IsRegular() || info.Mode().Perm()&0077 != 0 {
+		k.logger.Error().Msgf("Insecure keyring %s with mode %s", k.path, info.Mode())
+		return nil, fmt.Errorf("keyring %s must be a regular file with 0600 permissions", k.path)
+	}
+	data, err := os.ReadFile(k.path)
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(data, &pairs); err != nil {
+		return nil, fmt.Errorf("could not read keyring: %w", err)
+	}
+	return pairs, nil
+}
+
+// save atomically writes key pairs to the keyring.
+func (k *FileKeyring) save(pairs map[string]string) error {
+	if err := os.MkdirAll(filepath.Dir(k.path), dirMode); err != nil {
+		return err
+	}
+	data, err := json.Marshal(pairs)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(k.path), ".keyring-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(fileMode); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	k.logger.Info().Msgf("Storing key pair at %s", k.path)
+	return os.Rename(tmp.Name(), k.path)
+}
@@ -0,0 +1,60 @@
+package keyring
+
+import (
+	"dk-go-gophkeeper/internal/config"
+	"dk-go-gophkeeper/internal/keys"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestKeyring(t *testing.T) *FileKeyring {
+	cfg := config.NewDefaultConfiguration()
+	cfg.KeyringPath = filepath.Join(t.TempDir(), "gophkeeper", "keyring.json")
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	keyring, err := InitFileKeyring(&logger, cfg)
+	assert.Equal(t, nil, err)
+	return keyring
+}
+
+func TestFileKeyring_NewKeyPair(t *testing.T) {
+	keyring := newTestKeyring(t)
+	publicKey, err := keyring.NewKeyPair()
+	assert.Equal(t, nil, err)
+	info, err := os.Stat(keyring.path)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	otherPublicKey, err := keyring.NewKeyPair()
+	assert.Equal(t, nil, err)
+	assert.NotEqual(t, publicKey, otherPublicKey)
+
+	privateKey, err := keyring.PrivateKey(publicKey)
+	assert.Equal(t, nil, err)
+	recordKey, _ := keys.NewRecordKey()
+	wrappedKey, _ := keys.WrapKey(recordKey, publicKey)
+	unwrappedKey, err := keys.UnwrapKey(wrappedKey, publicKey, privateKey)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, recordKey, unwrappedKey)
+	_, err = keyring.PrivateKey(otherPublicKey)
+	assert.Equal(t, nil, err)
+}
+
+func TestFileKeyring_PrivateKeyMissing(t *testing.T) {
+	keyring := newTestKeyring(t)
+	_, err := keyring.PrivateKey("some_public_key")
+	assert.Equal(t, true, errors.Is(err, ErrNoKeyPair))
+}
+
+func TestFileKeyring_Insecure(t *testing.T) {
+	keyring := newTestKeyring(t)
+	publicKey, err := keyring.NewKeyPair()
+	assert.Equal(t, nil, err)
+	err = os.Chmod(keyring.path, 0644)
+	assert.Equal(t, nil, err)
+	_, err = keyring.PrivateKey(publicKey)
+	assert.NotEqual(t, nil, err)
+}
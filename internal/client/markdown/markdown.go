@@ -0,0 +1,247 @@
+// Package markdown provides rendering of Markdown notes as styled text of the TUI.
+//
+// Common block elements (headings, lists, task lists, block quotes, fenced code blocks and rules) and inline elements
+// (strong and emphasized text, strikethrough, code spans and links) are rendered with tview style tags, other text is
+// escaped so that it is never taken for a tag.
+package markdown
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/rivo/tview"
+)
+
+// colors of styled elements
+const (
+	headingColor = "yellow"
+	codeColor    = "teal"
+	quoteColor   = "gray"
+)
+
+// rule replaces horizontal rules.
+const rule = "────────────────────────────────"
+
+// escapable lists characters a backslash escapes in Markdown.
+const escapable = "\\`*_{}[]()#+-.!~>|"
+
+var (
+	headingPattern   = regexp.MustCompile(`^\s{0,3}(#{1,6})\s+(.*?)(\s+#+)?\s*$`)
+	quotePattern     = regexp.MustCompile(`^\s{0,3}>\s?(.*)$`)
+	taskPattern      = regexp.MustCompile(`^(\s*)[-*+]\s+\[([ xX])\]\s+(.*)$`)
+	bulletPattern    = regexp.MustCompile(`^(\s*)[-*+]\s+(.*)$`)
+	numberedPattern  = regexp.MustCompile(`^(\s*)(\d{1,9})[.)]\s+(.*)$`)
+	linkPattern      = regexp.MustCompile(`^\[([^\]]+)\]\(([^)\s]+)\)`)
+	fencePrefixes    = []string{"```", "~~~"}
+	ruleCharacters   = "-*_"
+	minRuleLength    = 3
+	listIndentFactor = 2
+)
+
+// style defines a style of rendered text.
+type style struct {
+	color     string
+	bold      bool
+	italic    bool
+	strike    bool
+	underline bool
+}
+
+// tag returns the tview tag switching to the style.
+func (s style) tag() string {
+	color := s.color
+	if color == "" {
+		color = "-"
+	}
+	var attributes string
+	if s.bold {
+		attributes += "b"
+	}
+	if s.italic {
+		attributes += "i"
+	}
+	if s.strike {
+		attributes += "s"
+	}
+	if s.underline {
+		attributes += "u"
+	}
+	if attributes == "" {
+		attributes = "-"
+	}
+	return "[" + color + "::" + attributes + "]"
+}
+
+// Render renders a Markdown note as text with tview style tags.
+func Render(text string) string {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	rendered := make([]string, 0, len(lines))
+	var fence string
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+				continue
+			}
+			rendered = append(rendered, style{color: codeColor}.tag()+"  "+tview.Escape(line)+style{}.tag())
+			continue
+		}
+		if prefix, ok := fencePrefix(trimmed); ok {
+			fence = prefix
+			continue
+		}
+		rendered = append(rendered, renderLine(line))
+	}
+	return strings.Join(rendered, "\n")
+}
+
+// fencePrefix returns the prefix of a line opening a fenced code block.
+func fencePrefix(line string) (string, bool) {
+	for _, prefix := range fencePrefixes {
+		if strings.HasPrefix(line, prefix) {
+			return prefix, true
+		}
+	}
+	return "", false
+}
+
+// isRule reports whether a line is a horizontal rule.
+func isRule(line string) bool {
+	line = strings.ReplaceAll(strings.TrimSpace(line), " ", "")
+	if len(line) < minRuleLength || !strings.ContainsRune(ruleCharacters, rune(line[0])) {
+		return false
+	}
+	return strings.Count(line, line[:1]) == len(line)
+}
+
+// renderLine renders a line outside of code blocks.
+func renderLine(line string) string {
+	if match := headingPattern.FindStringSubmatch(line); match != nil {
+		return renderInline(match[2], style{color: headingColor, bold: true})
+	}
+	if isRule(line) {
+		return style{color: quoteColor}.tag() + rule + style{}.tag()
+	}
+	if match := quotePattern.FindStringSubmatch(line); match != nil {
+		return style{color: quoteColor}.tag() + "│ " + style{}.tag() + renderInline(match[1], style{italic: true})
+	}
+	if match := taskPattern.FindStringSubmatch(line); match != nil {
+		box := "☐ "
+		if match[2] != " " {
+			box = "☑ "
+		}
+		return listIndent(match[1]) + box + renderInline(match[3], style{})
+	}
+	if match := bulletPattern.FindStringSubmatch(line); match != nil {
+		return listIndent(match[1]) + "• " + renderInline(match[2], style{})
+	}
+	if match := numberedPattern.FindStringSubmatch(line); match != nil {
+		return listIndent(match[1]) + match[2] + ". " + renderInline(match[3], style{})
+	}
+	return renderInline(line, style{})
+}
+
+// listIndent returns the indentation of a list item nested as deep as the original indentation.
+func listIndent(indent string) string {
+	depth := len(strings.ReplaceAll(indent, "\t", "  ")) / listIndentFactor
+	return strings.Repeat("  ", depth+1)
+}
+
+// renderInline renders inline elements of a line starting with a base style, the style is reset at the end of the line.
+func renderInline(text string, base style) string {
+	var sb, literal strings.Builder
+	current := base
+	styled := base != style{}
+	if styled {
+		sb.WriteString(base.tag())
+	}
+	setStyle := func(next style) {
+		sb.WriteString(tview.Escape(literal.String()))
+		literal.Reset()
+		current = next
+		styled = true
+		sb.WriteString(current.tag())
+	}
+	for i := 0; i < len(text); {
+		rest := text[i:]
+		switch {
+		case rest[0] == '\\' && len(rest) > 1 && strings.IndexByte(escapable, rest[1]) >= 0:
+			literal.WriteByte(rest[1])
+			i += 2
+			continue
+		case rest[0] == '`':
+			if end := strings.IndexByte(rest[1:], '`'); end > 0 {
+				previous := current
+				code := current
+				code.color = codeColor
+				setStyle(code)
+				literal.WriteString(rest[1 : end+1])
+				setStyle(previous)
+				i += end + 2
+				continue
+			}
+		case rest[0] == '[':
+			if match := linkPattern.FindStringSubmatch(rest); match != nil {
+				previous := current
+				link := current
+				link.underline = true
+				setStyle(link)
+				literal.WriteString(match[1])
+				setStyle(previous)
+				if match[2] != match[1] {
+					literal.WriteString(" (" + match[2] + ")")
+				}
+				i += len(match[0])
+				continue
+			}
+		case strings.HasPrefix(rest, "**") || strings.HasPrefix(rest, "__"):
+			next := current
+			next.bold = !current.bold
+			setStyle(next)
+			i += 2
+			continue
+		case strings.HasPrefix(rest, "~~"):
+			next := current
+			next.strike = !current.strike
+			setStyle(next)
+			i += 2
+			continue
+		case (rest[0] == '*' || rest[0] == '_') && emphasisDelimiter(text, i, current.italic):
+			next := current
+			next.italic = !current.italic
+			setStyle(next)
+			i++
+			continue
+		}
+		literal.WriteByte(rest[0])
+		i++
+	}
+	sb.WriteString(tview.Escape(literal.String()))
+	if styled {
+		sb.WriteString(style{}.tag())
+	}
+	return sb.String()
+}
+
+// emphasisDelimiter reports whether a single asterisk or underscore at position i opens or closes emphasized text.
+// Delimiters open before a word and close after one, underscores within words are kept as they are.
+func emphasisDelimiter(text string, i int, closing bool) bool {
+	var before, after rune = ' ', ' '
+	if i > 0 {
+		before = rune(text[i-1])
+	}
+	if i+1 < len(text) {
+		after = rune(text[i+1])
+	}
+	if closing {
+		return !unicode.IsSpace(before) && (text[i] == '*' || !isWordCharacter(after))
+	}
+	return !unicode.IsSpace(after) && (text[i] == '*' || !isWordCharacter(before))
+}
+
+// isWordCharacter reports whether a character belongs to a word.
+func isWordCharacter(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,32 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRender(t *testing.T) {
+	text := "# Wi-Fi *home*\n" +
+		"Network **gophers** uses ~~WEP~~ `WPA3`, see [router](http://192.168.0.1)\n" +
+		"> keep_it_safe\n" +
+		"- [x] change password\n" +
+		"  * rotate [keys]\n" +
+		"1. call \\*support\\*\n" +
+		"---\n" +
+		"```\n" +
+		"ssid = [gophers]\n" +
+		"```"
+	assert.Equal(t, "[yellow::b]Wi-Fi [yellow::bi]home[yellow::b][-::-]\n"+
+		"Network [-::b]gophers[-::-] uses [-::s]WEP[-::-] [teal::-]WPA3[-::-], see [-::u]router[-::-] (http://192.168.0.1)[-::-]\n"+
+		"[gray::-]│ [-::-][-::i]keep_it_safe[-::-]\n"+
+		"  ☑ change password\n"+
+		"    • rotate [keys[]\n"+
+		"  1. call *support*\n"+
+		"[gray::-]────────────────────────────────[-::-]\n"+
+		"[teal::-]  ssid = [gophers[][-::-]", Render(text))
+}
+
+func TestRenderPlainText(t *testing.T) {
+	assert.Equal(t, "snake_case_name and 2 * 3\nline [red[]", Render("snake_case_name and 2 * 3\r\nline [red]"))
+}
@@ -0,0 +1,126 @@
+// Package renderer provides rendering of config file templates referencing vault entries.
+package renderer
+
+import (
+	"bytes"
+	"dk-go-gophkeeper/internal/client/secretref"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/rs/zerolog"
+)
+
+// DefaultMode is the permission of rendered files.
+const DefaultMode os.FileMode = 0600
+
+// Problem defines a reference which could not be resolved.
+type Problem struct {
+	Reference string `json:"reference"`
+	Reason    string `json:"reason"`
+}
+
+// Renderer defines attributes and methods of a Renderer instance.
+type Renderer struct {
+	resolver *secretref.Resolver
+	logger   *zerolog.Logger
+}
+
+// InitRenderer initializes a Renderer instance.
+func InitRenderer(resolver *secretref.Resolver, logger *zerolog.Logger) *Renderer {
+	return &Renderer{resolver: resolver, logger: logger}
+}
+
+// Render executes a template failing on the first reference which cannot be resolved.
+func (r *Renderer) Render(name, text string, w io.Writer) error {
+	tmpl, err := r.parse(name, text, nil)
+	if err != nil {
+		return err
+	}
+	return tmpl.Execute(w, nil)
+}
+
+// Check executes a template without producing output and reports all references which cannot be resolved.
+func (r *Renderer) Check(name, text string) ([]Problem, error) {
+	var problems []Problem
+	tmpl, err := r.parse(name, text, &problems)
+	if err != nil {
+		return nil, err
+	}
+	if err := tmpl.Execute(io.Discard, nil); err != nil {
+		return nil, err
+	}
+	return problems, nil
+}
+
+// RenderFile renders a template file to an output file replaced atomically, the output is never readable by others.
+func (r *Renderer) RenderFile(templatePath, outputPath string, mode os.FileMode) error {
+	if mode&0007 != 0 {
+		return fmt.Errorf("output mode %#o must not grant access to others", mode)
+	}
+	text, err := os.ReadFile(templatePath)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := r.Render(filepath.Base(templatePath), string(text), &buf); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(outputPath), ".render-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(mode); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	r.logger.Info().Msgf("Rendering %s to %s", templatePath, outputPath)
+	return os.Rename(tmp.Name(), outputPath)
+}
+
+// parse parses a template, problems are collected instead of failing if a slice is given.
+func (r *Renderer) parse(name, text string, problems *[]Problem) (*template.Template, error) {
+	secret := func(raw string) (string, error) {
+		ref, err := secretref.Parse(raw)
+		if err == nil {
+			var value string
+			value, err = r.resolver.Resolve(ref)
+			if err == nil {
+				return value, nil
+			}
+		}
+		if problems == nil {
+			return "", err
+		}
+		*problems = append(*problems, Problem{Reference: raw, Reason: err.Error()})
+		return "", nil
+	}
+	funcs := template.FuncMap{
+		"secret": secret,
+		// pgpass escapes a .pgpass field
+		"pgpass": func(value string) string {
+			return strings.NewReplacer(`\`, `\\`, `:`, `\:`).Replace(value)
+		},
+		"base64": func(value string) string {
+			return base64.StdEncoding.EncodeToString([]byte(value))
+		},
+		"json": func(value string) (string, error) {
+			data, err := json.Marshal(value)
+			return string(data), err
+		},
+	}
+	return template.New(name).Funcs(funcs).Option("missingkey=error").Parse(text)
+}
@@ -0,0 +1,78 @@
+package renderer
+
+import (
+	"bytes"
+	"dk-go-gophkeeper/internal/client/secretref"
+	"dk-go-gophkeeper/internal/client/storage/inmemory"
+	"dk-go-gophkeeper/internal/config"
+	"dk-go-gophkeeper/internal/mocks"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+)
+
+func newTestRenderer(t *testing.T) *Renderer {
+	cfg := config.NewDefaultConfiguration()
+	cfg.BankCardDB = "bankCard"
+	cfg.LoginPasswordDB = "loginPassword"
+	cfg.TextBinaryDB = "textBinary"
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+	client := mocks.NewMockGRPCClient(ctrl)
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	st := inmemory.InitStorage(&logger, client, nil, cfg)
+	client.EXPECT().SendLoginPassword(gomock.Any()).Return(codes.OK, nil)
+	_ = st.AddLoginPassword("db", "user", "p:a\\ss", "")
+	return InitRenderer(secretref.InitResolver(st, cfg), &logger)
+}
+
+func TestRenderer_Render(t *testing.T) {
+	r := newTestRenderer(t)
+	var buf bytes.Buffer
+	text := `localhost:5432:*:{{ secret "gk://login/db/login" }}:{{ secret "gk://loginPassword/db/password" | pgpass }}
+{"password": {{ secret "gk://login/db/password" | json }}, "token": "{{ secret "gk://login/db/login" | base64 }}"}
+`
+	err := r.Render("pgpass", text, &buf)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "localhost:5432:*:user:p\\:a\\\\ss\n{\"password\": \"p:a\\\\ss\", \"token\": \"dXNlcg==\"}\n", buf.String())
+
+	err = r.Render("pgpass", `{{ secret "gk://login/generic_id/password" }}`, &buf)
+	assert.Contains(t, err.Error(), "entry generic_id of login does not exist")
+}
+
+func TestRenderer_Check(t *testing.T) {
+	r := newTestRenderer(t)
+	problems, err := r.Check("config", `{{ secret "gk://login/db/password" }} {{ secret "gk://login/generic_id/password" }} {{ secret "gk://login/db/generic_field" }} {{ secret "generic_ref" }}`)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []Problem{
+		{Reference: "gk://login/generic_id/password", Reason: "entry generic_id of login does not exist"},
+		{Reference: "gk://login/db/generic_field", Reason: "unknown field generic_field in reference gk://login/db/generic_field"},
+		{Reference: "generic_ref", Reason: `reference "generic_ref" must start with gk://`},
+	}, problems)
+
+	_, err = r.Check("config", `{{ secret `)
+	assert.NotEqual(t, nil, err)
+}
+
+func TestRenderer_RenderFile(t *testing.T) {
+	r := newTestRenderer(t)
+	dir := t.TempDir()
+	templatePath, outputPath := filepath.Join(dir, "netrc.tmpl"), filepath.Join(dir, "netrc")
+	assert.Equal(t, nil, os.WriteFile(templatePath, []byte(`login {{ secret "gk://login/db/login" }}`), 0644))
+	assert.Equal(t, nil, os.WriteFile(outputPath, []byte("stale"), 0644))
+
+	err := r.RenderFile(templatePath, outputPath, DefaultMode)
+	assert.Equal(t, nil, err)
+	data, _ := os.ReadFile(outputPath)
+	assert.Equal(t, "login user", string(data))
+	info, _ := os.Stat(outputPath)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	err = r.RenderFile(templatePath, outputPath, 0644)
+	assert.Equal(t, "output mode 0644 must not grant access to others", err.Error())
+}
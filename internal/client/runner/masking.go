@@ -0,0 +1,75 @@
+package runner
+
+import (
+	"bytes"
+	"io"
+	"sort"
+	"sync"
+)
+
+// Mask replaces secret values in the child process output.
+const Mask = "<concealed by gophkeeper>"
+
+// MaskingWriter defines attributes and methods of a writer replacing secrets with Mask. Bytes which may start
+// a secret are held back until the next write or Flush, so secrets split between writes are masked as well.
+type MaskingWriter struct {
+	mu      sync.Mutex
+	w       io.Writer
+	secrets [][]byte
+	pending []byte
+}
+
+// NewMaskingWriter initializes a MaskingWriter instance, empty secrets are ignored.
+func NewMaskingWriter(w io.Writer, secrets []string) *MaskingWriter {
+	mw := &MaskingWriter{w: w}
+	for _, secret := range secrets {
+		if secret != "" {
+			mw.secrets = append(mw.secrets, []byte(secret))
+		}
+	}
+	// longer secrets go first so that secrets containing other secrets are masked as a whole
+	sort.Slice(mw.secrets, func(i, j int) bool { return len(mw.secrets[i]) > len(mw.secrets[j]) })
+	return mw
+}
+
+// Write masks secrets and writes all output which cannot be the beginning of a secret.
+func (mw *MaskingWriter) Write(p []byte) (int, error) {
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
+	data := append(mw.pending, p...)
+	for _, secret := range mw.secrets {
+		data = bytes.ReplaceAll(data, secret, []byte(Mask))
+	}
+	hold := mw.partial(data)
+	mw.pending = append([]byte(nil), data[len(data)-hold:]...)
+	if _, err := mw.w.Write(data[:len(data)-hold]); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Flush writes held back output.
+func (mw *MaskingWriter) Flush() error {
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
+	if len(mw.pending) == 0 {
+		return nil
+	}
+	_, err := mw.w.Write(mw.pending)
+	mw.pending = nil
+	return err
+}
+
+// partial returns the length of the longest suffix of data which is a proper prefix of a secret.
+func (mw *MaskingWriter) partial(data []byte) int {
+	longest := 0
+	for _, secret := range mw.secrets {
+		for n := len(secret) - 1; n > longest; n-- {
+			if bytes.HasSuffix(data, secret[:n]) {
+				longest = n
+				break
+			}
+		}
+	}
+	return longest
+}
@@ -0,0 +1,80 @@
+// Package runner provides running child processes with secrets injected into their environment.
+package runner
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+
+	"github.com/rs/zerolog"
+)
+
+// Runner defines attributes and methods of a Runner instance.
+type Runner struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+	logger *zerolog.Logger
+}
+
+// InitRunner initializes a Runner instance.
+func InitRunner(stdin io.Reader, stdout, stderr io.Writer, logger *zerolog.Logger) *Runner {
+	return &Runner{stdin: stdin, stdout: stdout, stderr: stderr, logger: logger}
+}
+
+// Run executes a command with the given environment, masking secrets in its output, forwarding interrupts to it
+// and returning its exit code. Secrets are passed to the child in memory only.
+func (r *Runner) Run(ctx context.Context, environ, secrets, args []string, mask bool) (int, error) {
+	if len(args) == 0 {
+		return 0, errors.New("no command given")
+	}
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Env = environ
+	cmd.Stdin = r.stdin
+	cmd.Stdout, cmd.Stderr = r.stdout, r.stderr
+	var writers []*MaskingWriter
+	if mask {
+		stdout, stderr := NewMaskingWriter(r.stdout, secrets), NewMaskingWriter(r.stderr, secrets)
+		cmd.Stdout, cmd.Stderr = stdout, stderr
+		writers = append(writers, stdout, stderr)
+	}
+	r.logger.Info().Msgf("Running %s with %d secrets injected", args[0], len(secrets))
+	if err := cmd.Start(); err != nil {
+		return 0, err
+	}
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case sig := <-signals:
+				_ = cmd.Process.Signal(sig)
+			case <-done:
+				return
+			}
+		}
+	}()
+	err := cmd.Wait()
+	for _, w := range writers {
+		_ = w.Flush()
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		if exitErr.ExitCode() < 0 {
+			// the child was terminated by a signal
+			return 1, nil
+		}
+		return exitErr.ExitCode(), nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return 0, nil
+}
@@ -0,0 +1,42 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaskingWriter(t *testing.T) {
+	output := &bytes.Buffer{}
+	mw := NewMaskingWriter(output, []string{"secret", "", "secret-long"})
+	_, _ = mw.Write([]byte("pass: sec"))
+	assert.Equal(t, "pass: ", output.String())
+	_, _ = mw.Write([]byte("ret, long: secret-long, "))
+	_, _ = mw.Write([]byte("sec"))
+	assert.Equal(t, nil, mw.Flush())
+	assert.Equal(t, "pass: "+Mask+", long: "+Mask+", sec", output.String())
+}
+
+func TestRunner_Run(t *testing.T) {
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	stdout := &bytes.Buffer{}
+	r := InitRunner(strings.NewReader(""), stdout, &bytes.Buffer{}, &logger)
+	code, err := r.Run(context.Background(), []string{"SECRET=hunter22"}, []string{"hunter22"}, []string{"sh", "-c", "echo $SECRET; exit 3"}, true)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 3, code)
+	assert.Equal(t, Mask+"\n", stdout.String())
+
+	stdout.Reset()
+	code, err = r.Run(context.Background(), []string{"SECRET=hunter22"}, []string{"hunter22"}, []string{"sh", "-c", "echo $SECRET"}, false)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "hunter22\n", stdout.String())
+
+	_, err = r.Run(context.Background(), nil, nil, nil, true)
+	assert.Equal(t, "no command given", err.Error())
+}
@@ -0,0 +1,166 @@
+// Package secretref provides resolving of gk:// secret references against the vault.
+package secretref
+
+import (
+	"bufio"
+	"dk-go-gophkeeper/internal/client/storage"
+	"dk-go-gophkeeper/internal/client/storage/modelstorage"
+	"dk-go-gophkeeper/internal/config"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+)
+
+// Scheme prefixes all secret references.
+const Scheme = "gk://"
+
+// Reference defines a single field of a vault entry, gk://<db>/<identifier>/<field>.
+type Reference struct {
+	Db         string
+	Identifier string
+	Field      string
+}
+
+// String returns a textual representation of a reference.
+func (r Reference) String() string {
+	return Scheme + r.Db + "/" + url.PathEscape(r.Identifier) + "/" + r.Field
+}
+
+// IsReference reports whether a value is a secret reference.
+func IsReference(value string) bool {
+	return strings.HasPrefix(value, Scheme)
+}
+
+// Parse parses a secret reference, the identifier may be path-escaped and contain slashes.
+func Parse(raw string) (Reference, error) {
+	if !IsReference(raw) {
+		return Reference{}, fmt.Errorf("reference %q must start with %s", raw, Scheme)
+	}
+	parts := strings.Split(strings.TrimPrefix(raw, Scheme), "/")
+	if len(parts) < 3 {
+		return Reference{}, fmt.Errorf("reference %q must be of form %s<db>/<identifier>/<field>", raw, Scheme)
+	}
+	identifier, err := url.PathUnescape(strings.Join(parts[1:len(parts)-1], "/"))
+	if err != nil {
+		return Reference{}, fmt.Errorf("invalid identifier in reference %q: %w", raw, err)
+	}
+	ref := Reference{Db: parts[0], Identifier: identifier, Field: parts[len(parts)-1]}
+	if ref.Db == "" || ref.Identifier == "" || ref.Field == "" {
+		return Reference{}, fmt.Errorf("reference %q must be of form %s<db>/<identifier>/<field>", raw, Scheme)
+	}
+	return ref, nil
+}
+
+// Resolver defines attributes and methods of a Resolver instance.
+type Resolver struct {
+	storage storage.DataStorage
+	batch   *modelstorage.Batch
+	cfg     *config.Config
+}
+
+// InitResolver initializes a Resolver instance looking references up in synced client storage.
+func InitResolver(st storage.DataStorage, cfg *config.Config) *Resolver {
+	return &Resolver{storage: st, cfg: cfg}
+}
+
+// Resolve returns the value of a referenced field.
+func (r *Resolver) Resolve(ref Reference) (string, error) {
+	if r.batch == nil {
+		batch := r.storage.Export()
+		r.batch = &batch
+	}
+	fields, ok := r.fields(ref)
+	if !ok {
+		return "", fmt.Errorf("entry %s of %s does not exist", ref.Identifier, ref.Db)
+	}
+	value, ok := fields[ref.Field]
+	if !ok {
+		return "", fmt.Errorf("unknown field %s in reference %s", ref.Field, ref)
+	}
+	return value, nil
+}
+
+// fields returns the named fields of a referenced entry including custom ones, DB identifiers and CLI type names are
+// accepted.
+func (r *Resolver) fields(ref Reference) (map[string]string, bool) {
+	switch ref.Db {
+	case r.cfg.BankCardDB, "card":
+		for _, value := range r.batch.BankCards {
+			if value.Identifier == ref.Identifier {
+				return withCustomFields(map[string]string{"identifier": value.Identifier, "number": value.Number, "holder": value.Holder, "cvv": value.Cvv, "expiry": value.Expiry, "pin": value.Pin, "meta": value.Meta}, value.Fields), true
+			}
+		}
+	case r.cfg.LoginPasswordDB, "login":
+		for _, value := range r.batch.LoginsPasswords {
+			if value.Identifier == ref.Identifier {
+				return withCustomFields(map[string]string{"identifier": value.Identifier, "login": value.Login, "password": value.Password, "meta": value.Meta}, value.Fields), true
+			}
+		}
+	case r.cfg.TextBinaryDB, "text":
+		for _, value := range r.batch.TextsBinaries {
+			if value.Identifier == ref.Identifier {
+				return withCustomFields(map[string]string{"identifier": value.Identifier, "entry": value.Entry, "meta": value.Meta}, value.Fields), true
+			}
+		}
+	}
+	return nil, false
+}
+
+// withCustomFields adds custom fields to the named fields of an entry, custom fields never shadow predefined ones.
+func withCustomFields(fields map[string]string, custom []modelstorage.CustomField) map[string]string {
+	for _, field := range custom {
+		if _, ok := fields[field.Name]; !ok {
+			fields[field.Name] = field.Value
+		}
+	}
+	return fields
+}
+
+// ResolveEnv resolves NAME=value pairs whose values are secret references and returns the resolved pairs along
+// with the resolved secret values.
+func (r *Resolver) ResolveEnv(environ []string) ([]string, []string, error) {
+	resolved := make([]string, 0, len(environ))
+	var secrets []string
+	for _, pair := range environ {
+		name, value, _ := strings.Cut(pair, "=")
+		if IsReference(value) {
+			ref, err := Parse(value)
+			if err != nil {
+				return nil, nil, fmt.Errorf("%s: %w", name, err)
+			}
+			value, err = r.Resolve(ref)
+			if err != nil {
+				return nil, nil, fmt.Errorf("%s: %w", name, err)
+			}
+			secrets = append(secrets, value)
+		}
+		resolved = append(resolved, name+"="+value)
+	}
+	return resolved, secrets, nil
+}
+
+// ReadEnvFile reads NAME=value pairs, one per line, skipping blank lines and comments. An optional export
+// keyword and quotes around values are stripped.
+func ReadEnvFile(r io.Reader) ([]string, error) {
+	var environ []string
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		name, value, ok := strings.Cut(line, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("line %d: expected NAME=value", n)
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		environ = append(environ, name+"="+value)
+	}
+	return environ, scanner.Err()
+}
@@ -0,0 +1,91 @@
+package secretref
+
+import (
+	"dk-go-gophkeeper/internal/client/storage/inmemory"
+	"dk-go-gophkeeper/internal/client/storage/modelstorage"
+	"dk-go-gophkeeper/internal/config"
+	"dk-go-gophkeeper/internal/mocks"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+)
+
+func TestParse(t *testing.T) {
+	ref, err := Parse("gk://loginPassword/db/password")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, Reference{Db: "loginPassword", Identifier: "db", Field: "password"}, ref)
+
+	ref, err = Parse("gk://login/prod%20db/nested/login")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, Reference{Db: "login", Identifier: "prod db/nested", Field: "login"}, ref)
+	assert.Equal(t, "gk://login/prod%20db%2Fnested/login", ref.String())
+
+	_, err = Parse("gk://loginPassword/password")
+	assert.Equal(t, `reference "gk://loginPassword/password" must be of form gk://<db>/<identifier>/<field>`, err.Error())
+	_, err = Parse("https://example.com")
+	assert.NotEqual(t, nil, err)
+}
+
+func TestResolver_ResolveEnv(t *testing.T) {
+	cfg := config.NewDefaultConfiguration()
+	cfg.BankCardDB = "bankCard"
+	cfg.LoginPasswordDB = "loginPassword"
+	cfg.TextBinaryDB = "textBinary"
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mocks.NewMockGRPCClient(ctrl)
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	st := inmemory.InitStorage(&logger, client, nil, cfg)
+	client.EXPECT().SendLoginPassword(gomock.Any()).Return(codes.OK, nil)
+	client.EXPECT().SendBankCard(gomock.Any()).Return(codes.OK, nil)
+	_ = st.AddLoginPassword("db", "user", "secret", "")
+	_ = st.AddBankCard("visa", "4111111111111111", "JOHN DOE", "123", "12/30", "", "")
+	resolver := InitResolver(st, cfg)
+
+	resolved, secrets, err := resolver.ResolveEnv([]string{"PATH=/bin", "DB_USER=gk://loginPassword/db/login", "CVV=gk://card/visa/cvv"})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"PATH=/bin", "DB_USER=user", "CVV=123"}, resolved)
+	assert.Equal(t, []string{"user", "123"}, secrets)
+
+	_, _, err = resolver.ResolveEnv([]string{"DB=gk://loginPassword/generic_id/password"})
+	assert.Equal(t, "DB: entry generic_id of loginPassword does not exist", err.Error())
+	_, _, err = resolver.ResolveEnv([]string{"DB=gk://loginPassword/db/generic_field"})
+	assert.Equal(t, "DB: unknown field generic_field in reference gk://loginPassword/db/generic_field", err.Error())
+}
+
+func TestResolver_ResolveCustomField(t *testing.T) {
+	cfg := config.NewDefaultConfiguration()
+	cfg.BankCardDB = "bankCard"
+	cfg.LoginPasswordDB = "loginPassword"
+	cfg.TextBinaryDB = "textBinary"
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mocks.NewMockGRPCClient(ctrl)
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	st := inmemory.InitStorage(&logger, client, nil, cfg)
+	client.EXPECT().SendBankCard(gomock.Any()).Return(codes.OK, nil)
+	client.EXPECT().SendBatch(gomock.Any(), true).Return([]modelstorage.BatchItemResult{{Identifier: "visa", Db: "bankCard"}}, codes.OK, nil)
+	client.EXPECT().GetShares().Return(nil, codes.OK, nil)
+	_ = st.AddBankCard("visa", "4111111111111111", "JOHN DOE", "123", "12/30", "", "")
+	_ = st.SetFields("visa", "bankCard", []modelstorage.CustomField{{Name: "PIN", Value: "0000", Concealed: true}, {Name: "cvv", Value: "999"}})
+	resolver := InitResolver(st, cfg)
+
+	resolved, _, err := resolver.ResolveEnv([]string{"PIN=gk://card/visa/PIN", "CVV=gk://card/visa/cvv"})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"PIN=0000", "CVV=123"}, resolved)
+}
+
+func TestReadEnvFile(t *testing.T) {
+	input := "# database\nexport DB_PASSWORD=\"gk://loginPassword/db/password\"\n\nDB_HOST = localhost\nNAME='a b'\n"
+	environ, err := ReadEnvFile(strings.NewReader(input))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"DB_PASSWORD=gk://loginPassword/db/password", "DB_HOST=localhost", "NAME=a b"}, environ)
+
+	_, err = ReadEnvFile(strings.NewReader("generic_line\n"))
+	assert.Equal(t, "line 1: expected NAME=value", err.Error())
+}
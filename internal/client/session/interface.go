@@ -0,0 +1,24 @@
+// Package session provides caching of client sessions between CLI invocations.
+package session
+
+// Loader defines a set of methods for types implementing Loader.
+type Loader interface {
+	Load(serverAddress string) (token, sessionKey string, err error)
+}
+
+// Saver defines a set of methods for types implementing Saver.
+type Saver interface {
+	Save(serverAddress, token, sessionKey string) error
+}
+
+// Cleaner defines a set of methods for types implementing Cleaner.
+type Cleaner interface {
+	Clear() error
+}
+
+// Keeper defines a set of embedded interfaces for types implementing Keeper.
+type Keeper interface {
+	Loader
+	Saver
+	Cleaner
+}
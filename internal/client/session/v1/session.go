@@ -0,0 +1,123 @@
+// Package session provides a file-based session cache readable by its owner only.
+package session
+
+import (
+	"dk-go-gophkeeper/internal/client/session"
+	"dk-go-gophkeeper/internal/config"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/rs/zerolog"
+)
+
+// file and directory permissions of the session cache
+const (
+	fileMode os.FileMode = 0600
+	dirMode  os.FileMode = 0700
+)
+
+// ErrNoSession is returned when no cached session is available for the requested server.
+var ErrNoSession = errors.New("no cached session, log in first")
+
+// check for interface compliance
+var (
+	_ session.Keeper = (*FileKeeper)(nil)
+)
+
+// cachedSession defines the on-disk format of a cached session.
+type cachedSession struct {
+	ServerAddress string `json:"server_address"`
+	Token         string `json:"token"`
+	SessionKey    string `json:"session_key"`
+}
+
+// FileKeeper defines attributes and methods of a FileKeeper instance.
+type FileKeeper struct {
+	path   string
+	logger *zerolog.Logger
+}
+
+// InitFileKeeper initializes a FileKeeper instance storing the session at cfg.SessionPath or, if it is not set,
+// in the user configuration directory.
+func InitFileKeeper(logger *zerolog.Logger, cfg *config.Config) (*FileKeeper, error) {
+	logger.Info().Msg("Attempting to initialize session keeper")
+	path := cfg.SessionPath
+	if path == "" {
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			return nil, err
+		}
+		path = filepath.Join(dir, "gophkeeper", "session.json")
+	}
+	return &FileKeeper{path: path, logger: logger}, nil
+}
+
+// Load returns a cached session token and session key for the given server address.
+func (k *FileKeeper) Load(serverAddress string) (string, string, error) {
+	info, err := os.Lstat(k.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", "", ErrNoSession
+	}
+	if err != nil {
+		return "", "", err
+	}
+	// refuse to use a cache which could have been read or replaced by other users
+	if !info.Mode().IsRegular() || info.Mode().Perm()&0077 != 0 {
+		k.logger.Error().Msgf("Insecure session cache %s with mode %s", k.path, info.Mode())
+		return "", "", fmt.Errorf("session cache %s must be a regular file with 0600 permissions", k.path)
+	}
+	data, err := os.ReadFile(k.path)
+	if err != nil {
+		return "", "", err
+	}
+	var cached cachedSession
+	if err := json.Unmarshal(data, &cached); err != nil {
+		return "", "", fmt.Errorf("could not read session cache: %w", err)
+	}
+	// caches written before sessions were introduced hold no session key and require logging in again
+	if cached.Token == "" || cached.SessionKey == "" || cached.ServerAddress != serverAddress {
+		return "", "", ErrNoSession
+	}
+	return cached.Token, cached.SessionKey, nil
+}
+
+// Save atomically writes a session token and session key for the given server address to the cache.
+func (k *FileKeeper) Save(serverAddress, token, sessionKey string) error {
+	if err := os.MkdirAll(filepath.Dir(k.path), dirMode); err != nil {
+		return err
+	}
+	data, err := json.Marshal(cachedSession{ServerAddress: serverAddress, Token: token, SessionKey: sessionKey})
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(k.path), ".session-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(fileMode); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	k.logger.Info().Msgf("Caching session at %s", k.path)
+	return os.Rename(tmp.Name(), k.path)
+}
+
+// Clear removes a cached session.
+func (k *FileKeeper) Clear() error {
+	err := os.Remove(k.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,61 @@
+package session
+
+import (
+	"dk-go-gophkeeper/internal/config"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestKeeper(t *testing.T) *FileKeeper {
+	cfg := config.NewDefaultConfiguration()
+	cfg.SessionPath = filepath.Join(t.TempDir(), "gophkeeper", "session.json")
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	keeper, err := InitFileKeeper(&logger, cfg)
+	assert.Equal(t, nil, err)
+	return keeper
+}
+
+func TestFileKeeper_SaveLoad(t *testing.T) {
+	keeper := newTestKeeper(t)
+	_, _, err := keeper.Load(":8080")
+	assert.Equal(t, ErrNoSession, err)
+
+	err = keeper.Save(":8080", "some_token", "some_session")
+	assert.Equal(t, nil, err)
+	info, err := os.Stat(keeper.path)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	token, sessionKey, err := keeper.Load(":8080")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "some_token", token)
+	assert.Equal(t, "some_session", sessionKey)
+	_, _, err = keeper.Load(":8081")
+	assert.Equal(t, ErrNoSession, err)
+
+	assert.Equal(t, nil, keeper.Clear())
+	assert.Equal(t, nil, keeper.Clear())
+	_, _, err = keeper.Load(":8080")
+	assert.Equal(t, ErrNoSession, err)
+}
+
+func TestFileKeeper_LoadInsecure(t *testing.T) {
+	keeper := newTestKeeper(t)
+	err := keeper.Save(":8080", "some_token", "some_session")
+	assert.Equal(t, nil, err)
+	err = os.Chmod(keeper.path, 0644)
+	assert.Equal(t, nil, err)
+	_, _, err = keeper.Load(":8080")
+	assert.NotEqual(t, nil, err)
+}
+
+func TestFileKeeper_LoadWithoutSessionKey(t *testing.T) {
+	keeper := newTestKeeper(t)
+	err := keeper.Save(":8080", "some_token", "")
+	assert.Equal(t, nil, err)
+	_, _, err = keeper.Load(":8080")
+	assert.Equal(t, ErrNoSession, err)
+}
@@ -0,0 +1,20 @@
+package inmemory
+
+import (
+	"dk-go-gophkeeper/internal/client/storage/modelstorage"
+	"fmt"
+)
+
+// Activity retrieves a page of the audit log of the user from the server, the newest events first. Events older than
+// beforeID are retrieved unless it is zero, the server limits the page if the limit is zero.
+func (s *Storage) Activity(beforeID int64, limit int) ([]modelstorage.AuditEvent, error) {
+	if limit < 0 {
+		return nil, fmt.Errorf("invalid limit %d", limit)
+	}
+	events, code, err := s.clientGRPC.ListAuditEvents(beforeID, limit)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Could not retrieve account activity")
+		return nil, requestError(code, err, nil)
+	}
+	return events, nil
+}
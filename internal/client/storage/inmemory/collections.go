@@ -0,0 +1,177 @@
+package inmemory
+
+import (
+	"dk-go-gophkeeper/internal/client/storage/modelstorage"
+	"errors"
+	"fmt"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// collectionError describes a failed collection request, the server describes rejected membership changes for users
+// itself.
+func collectionError(code codes.Code, err error) error {
+	switch code {
+	case codes.InvalidArgument, codes.NotFound, codes.AlreadyExists, codes.PermissionDenied, codes.FailedPrecondition:
+		if st, ok := status.FromError(err); ok {
+			return &RequestError{Code: code, Message: st.Message()}
+		}
+	}
+	return requestError(code, err, nil)
+}
+
+// checkRole validates a role of a collection member.
+func checkRole(role string) error {
+	switch role {
+	case modelstorage.RoleOwner, modelstorage.RoleAdmin, modelstorage.RoleMember, modelstorage.RoleReadOnly:
+		return nil
+	}
+	return fmt.Errorf("invalid role %s, expected one of %s", role,
+		strings.Join([]string{modelstorage.RoleOwner, modelstorage.RoleAdmin, modelstorage.RoleMember, modelstorage.RoleReadOnly}, ", "))
+}
+
+// checkMember validates a collection and a login of its member.
+func checkMember(collectionID, login string) error {
+	if collectionID == "" {
+		return errors.New("collection cannot be empty")
+	}
+	if login == "" {
+		return errors.New("login cannot be empty")
+	}
+	return nil
+}
+
+// CreateCollection creates a collection owned by the user on the server.
+func (s *Storage) CreateCollection(name string) (modelstorage.Collection, error) {
+	if strings.TrimSpace(name) == "" {
+		return modelstorage.Collection{}, errors.New("collection name cannot be empty")
+	}
+	s.logger.Info().Msg("Creating collection")
+	collection, code, err := s.clientGRPC.CreateCollection(name)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Could not create collection")
+		return modelstorage.Collection{}, collectionError(code, err)
+	}
+	return collection, nil
+}
+
+// Collections retrieves collections the user is a member of from the server.
+func (s *Storage) Collections() ([]modelstorage.Collection, error) {
+	collections, code, err := s.clientGRPC.GetCollections()
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Could not retrieve collections")
+		return nil, collectionError(code, err)
+	}
+	return collections, nil
+}
+
+// DeleteCollection deletes a collection with all its entries on the server.
+func (s *Storage) DeleteCollection(collectionID string) error {
+	if collectionID == "" {
+		return errors.New("collection cannot be empty")
+	}
+	s.logger.Info().Msgf("Deleting collection %s", collectionID)
+	code, err := s.clientGRPC.DeleteCollection(collectionID)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Could not delete collection")
+		return collectionError(code, err)
+	}
+	return nil
+}
+
+// Invite invites another user to a collection with a role, the user joins once the invitation is accepted.
+func (s *Storage) Invite(collectionID, login, role string) error {
+	if err := checkMember(collectionID, login); err != nil {
+		return err
+	}
+	if err := checkRole(role); err != nil {
+		return err
+	}
+	s.logger.Info().Msgf("Inviting %s to collection %s", login, collectionID)
+	code, err := s.clientGRPC.InviteMember(collectionID, login, role)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Could not invite member")
+		return collectionError(code, err)
+	}
+	return nil
+}
+
+// Invitations retrieves pending invitations of the user from the server.
+func (s *Storage) Invitations() ([]modelstorage.CollectionInvitation, error) {
+	invitations, code, err := s.clientGRPC.GetInvitations()
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Could not retrieve invitations")
+		return nil, collectionError(code, err)
+	}
+	return invitations, nil
+}
+
+// RespondInvitation accepts or declines an invitation of the user.
+func (s *Storage) RespondInvitation(invitationID int64, accept bool) error {
+	s.logger.Info().Msgf("Responding invitation %d", invitationID)
+	code, err := s.clientGRPC.RespondInvitation(invitationID, accept)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Could not respond invitation")
+		return collectionError(code, err)
+	}
+	return nil
+}
+
+// Members retrieves members of a collection from the server.
+func (s *Storage) Members(collectionID string) ([]modelstorage.CollectionMember, error) {
+	if collectionID == "" {
+		return nil, errors.New("collection cannot be empty")
+	}
+	members, code, err := s.clientGRPC.GetMembers(collectionID)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Could not retrieve members")
+		return nil, collectionError(code, err)
+	}
+	return members, nil
+}
+
+// SetMemberRole changes a role of a collection member.
+func (s *Storage) SetMemberRole(collectionID, login, role string) error {
+	if err := checkMember(collectionID, login); err != nil {
+		return err
+	}
+	if err := checkRole(role); err != nil {
+		return err
+	}
+	s.logger.Info().Msgf("Setting role of %s in collection %s", login, collectionID)
+	code, err := s.clientGRPC.SetMemberRole(collectionID, login, role)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Could not set member role")
+		return collectionError(code, err)
+	}
+	return nil
+}
+
+// RemoveMember removes a member from a collection, the user leaves a collection by removing themselves.
+func (s *Storage) RemoveMember(collectionID, login string) error {
+	if err := checkMember(collectionID, login); err != nil {
+		return err
+	}
+	s.logger.Info().Msgf("Removing %s from collection %s", login, collectionID)
+	code, err := s.clientGRPC.RemoveMember(collectionID, login)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Could not remove member")
+		return collectionError(code, err)
+	}
+	return nil
+}
+
+// CollectionEvents retrieves membership changes of a collection from the server.
+func (s *Storage) CollectionEvents(collectionID string) ([]modelstorage.CollectionEvent, error) {
+	if collectionID == "" {
+		return nil, errors.New("collection cannot be empty")
+	}
+	events, code, err := s.clientGRPC.GetCollectionEvents(collectionID)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Could not retrieve collection events")
+		return nil, collectionError(code, err)
+	}
+	return events, nil
+}
@@ -0,0 +1,298 @@
+package inmemory
+
+import (
+	"dk-go-gophkeeper/internal/client/storage/modelstorage"
+	"dk-go-gophkeeper/internal/keys"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// limits of a single request uploading entries sealed for trusted contacts, requests stay well below the default
+// message size limit of gRPC
+const (
+	emergencyChunkSize  = 100
+	emergencyChunkBytes = 1 << 20
+)
+
+// emergencyError describes a failed emergency access request, the server describes rejected requests for users itself.
+func emergencyError(code codes.Code, err error) error {
+	switch code {
+	case codes.InvalidArgument, codes.NotFound, codes.AlreadyExists, codes.PermissionDenied, codes.FailedPrecondition:
+		if st, ok := status.FromError(err); ok {
+			return &RequestError{Code: code, Message: st.Message()}
+		}
+	}
+	return requestError(code, err, nil)
+}
+
+// checkLogin validates a login of an owner or a trusted contact.
+func checkLogin(login string) error {
+	if strings.TrimSpace(login) == "" {
+		return errors.New("login cannot be empty")
+	}
+	return nil
+}
+
+// SetTrustedContact designates another user as a trusted contact who may request emergency access to the vault, the
+// server waits for waitDays days before granting a request, zero stands for the default period. Local entries are
+// sealed for the contact right away.
+func (s *Storage) SetTrustedContact(login string, waitDays int) error {
+	if err := checkLogin(login); err != nil {
+		return err
+	}
+	if waitDays < 0 {
+		return errors.New("waiting period cannot be negative")
+	}
+	s.logger.Info().Msgf("Setting trusted contact %s", login)
+	code, err := s.clientGRPC.SetTrustedContact(login, waitDays)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Could not set trusted contact")
+		return emergencyError(code, err)
+	}
+	// a new contact can open entries once they are sealed for the contact as well
+	if err = s.sealEmergencyEntries(); err != nil {
+		s.logger.Error().Err(err).Msg("Could not seal entries for trusted contacts")
+		return fmt.Errorf("trusted contact %s is set, but entries could not be sealed for the contact: %w", login, err)
+	}
+	return nil
+}
+
+// RemoveTrustedContact removes a trusted contact of the user, emergency access granted to the contact is revoked.
+func (s *Storage) RemoveTrustedContact(login string) error {
+	if err := checkLogin(login); err != nil {
+		return err
+	}
+	s.logger.Info().Msgf("Removing trusted contact %s", login)
+	code, err := s.clientGRPC.RemoveTrustedContact(login)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Could not remove trusted contact")
+		return emergencyError(code, err)
+	}
+	return nil
+}
+
+// EmergencyAccess retrieves trusted contacts of the user and owners who designated the user as their trusted contact.
+func (s *Storage) EmergencyAccess() (modelstorage.EmergencyAccessList, error) {
+	list, code, err := s.clientGRPC.ListEmergencyAccess()
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Could not retrieve emergency access")
+		return modelstorage.EmergencyAccessList{}, emergencyError(code, err)
+	}
+	return list, nil
+}
+
+// RequestEmergencyAccess requests emergency access to the vault of an owner, it is granted once the waiting period is
+// over unless the owner rejects it.
+func (s *Storage) RequestEmergencyAccess(owner string) error {
+	if err := checkLogin(owner); err != nil {
+		return err
+	}
+	s.logger.Info().Msgf("Requesting emergency access to the vault of %s", owner)
+	code, err := s.clientGRPC.RequestEmergencyAccess(owner)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Could not request emergency access")
+		return emergencyError(code, err)
+	}
+	return nil
+}
+
+// ApproveEmergencyAccess grants a pending request of a trusted contact without waiting.
+func (s *Storage) ApproveEmergencyAccess(contact string) error {
+	if err := checkLogin(contact); err != nil {
+		return err
+	}
+	s.logger.Info().Msgf("Approving emergency access of %s", contact)
+	code, err := s.clientGRPC.ApproveEmergencyAccess(contact)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Could not approve emergency access")
+		return emergencyError(code, err)
+	}
+	return nil
+}
+
+// RejectEmergencyAccess rejects a pending request of a trusted contact or revokes access granted to the contact.
+func (s *Storage) RejectEmergencyAccess(contact string) error {
+	if err := checkLogin(contact); err != nil {
+		return err
+	}
+	s.logger.Info().Msgf("Rejecting emergency access of %s", contact)
+	code, err := s.clientGRPC.RejectEmergencyAccess(contact)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Could not reject emergency access")
+		return emergencyError(code, err)
+	}
+	return nil
+}
+
+// EmergencyVault retrieves the vault of an owner who granted emergency access to the user, each entry is opened with
+// its own record key wrapped for the user. The vault is not kept locally.
+func (s *Storage) EmergencyVault(owner string) (modelstorage.EmergencyVault, error) {
+	if err := checkLogin(owner); err != nil {
+		return modelstorage.EmergencyVault{}, err
+	}
+	s.logger.Info().Msgf("Retrieving emergency vault of %s", owner)
+	entries, code, err := s.clientGRPC.GetEmergencyVault(owner)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Could not retrieve emergency vault")
+		return modelstorage.EmergencyVault{}, emergencyError(code, err)
+	}
+	publicKey, privateKey, err := s.keyPair()
+	if err != nil {
+		return modelstorage.EmergencyVault{}, err
+	}
+	vault := modelstorage.EmergencyVault{Owner: owner}
+	for _, entry := range entries {
+		recordKey, err := keys.UnwrapKey(entry.WrappedKey, publicKey, privateKey)
+		if err != nil {
+			return modelstorage.EmergencyVault{}, err
+		}
+		batch, err := openEntry(recordKey, entry.Payload)
+		if err != nil {
+			return modelstorage.EmergencyVault{}, err
+		}
+		vault.BankCards = append(vault.BankCards, batch.BankCards...)
+		vault.LoginsPasswords = append(vault.LoginsPasswords, batch.LoginsPasswords...)
+		vault.TextsBinaries = append(vault.TextsBinaries, batch.TextsBinaries...)
+	}
+	return vault, nil
+}
+
+// emergencyContacts returns public keys of trusted contacts of the user by their logins, contacts who have not
+// published a public key yet are left out until they do.
+func (s *Storage) emergencyContacts() (map[string]string, error) {
+	list, code, err := s.clientGRPC.ListEmergencyAccess()
+	if err != nil {
+		return nil, emergencyError(code, err)
+	}
+	contacts := make(map[string]string, len(list.TrustedContacts))
+	for _, contact := range list.TrustedContacts {
+		publicKey, code, err := s.clientGRPC.GetPublicKey(contact.Contact)
+		if code == codes.NotFound {
+			s.logger.Warn().Msgf("Entries cannot be sealed for %s until the user publishes a public key", contact.Contact)
+			continue
+		}
+		if err != nil {
+			return nil, emergencyError(code, err)
+		}
+		contacts[contact.Contact] = publicKey
+	}
+	return contacts, nil
+}
+
+// sealedFor tells whether an entry was sealed for exactly the given contacts after its last change.
+func sealedFor(entry modelstorage.EmergencyEntry, updatedAt *time.Time, contacts map[string]string) bool {
+	if entry.SealedAt == nil || updatedAt != nil && entry.SealedAt.Before(*updatedAt) {
+		return false
+	}
+	if len(entry.Contacts) != len(contacts) {
+		return false
+	}
+	for _, contact := range entry.Contacts {
+		if _, ok := contacts[contact]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// sealEmergencyEntry seals a local entry with a new record key wrapped for each trusted contact by their logins.
+func sealEmergencyEntry(identifier, db string, batch modelstorage.Batch, contacts map[string]string) (modelstorage.EmergencyEntry, error) {
+	entry := modelstorage.EmergencyEntry{Identifier: identifier, Db: db, WrappedKeys: make(map[string]string, len(contacts))}
+	recordKey, err := keys.NewRecordKey()
+	if err != nil {
+		return entry, err
+	}
+	entry.Payload, err = sealEntry(recordKey, batch)
+	if err != nil {
+		return entry, err
+	}
+	for login, publicKey := range contacts {
+		entry.WrappedKeys[login], err = keys.WrapKey(recordKey, publicKey)
+		if err != nil {
+			return entry, err
+		}
+	}
+	return entry, nil
+}
+
+// revisions returns times of the last changes of local entries keyed by their DBs and identifiers, entries which have
+// not been synced yet have no time.
+func (s *Storage) revisions() map[string]*time.Time {
+	revisions := make(map[string]*time.Time, len(s.bankCardDB)+len(s.loginPasswordDB)+len(s.textBinaryDB))
+	for identifier, value := range s.bankCardDB {
+		revisions[s.cfg.BankCardDB+"/"+identifier] = value.Revision.UpdatedAt
+	}
+	for identifier, value := range s.loginPasswordDB {
+		revisions[s.cfg.LoginPasswordDB+"/"+identifier] = value.Revision.UpdatedAt
+	}
+	for identifier, value := range s.textBinaryDB {
+		revisions[s.cfg.TextBinaryDB+"/"+identifier] = value.Revision.UpdatedAt
+	}
+	return revisions
+}
+
+// sealEmergencyEntries seals local entries for trusted contacts of the user, so that the server keeps ciphertexts only
+// and never opens entries it grants to contacts. Entries sealed for the same contacts since their last change are left
+// as they are, the rest are uploaded in chunks.
+func (s *Storage) sealEmergencyEntries() error {
+	// entries of a collection are not in the vault of the user
+	if s.cfg.Collection != "" {
+		return nil
+	}
+	contacts, err := s.emergencyContacts()
+	if err != nil || len(contacts) == 0 {
+		return err
+	}
+	sealed, code, err := s.clientGRPC.GetEmergencyEntries()
+	if err != nil {
+		return emergencyError(code, err)
+	}
+	sealedEntries := make(map[string]modelstorage.EmergencyEntry, len(sealed))
+	for _, entry := range sealed {
+		sealedEntries[entry.Db+"/"+entry.Identifier] = entry
+	}
+	var chunk []modelstorage.EmergencyEntry
+	var chunkBytes int
+	upload := func() error {
+		if code, err := s.clientGRPC.SetEmergencyEntries(chunk); err != nil {
+			return emergencyError(code, err)
+		}
+		chunk, chunkBytes = chunk[:0], 0
+		return nil
+	}
+	for key, updatedAt := range s.revisions() {
+		if entry, ok := sealedEntries[key]; ok && sealedFor(entry, updatedAt, contacts) {
+			continue
+		}
+		db, identifier, _ := strings.Cut(key, "/")
+		batch, _ := s.localEntry(identifier, db)
+		entry, err := sealEmergencyEntry(identifier, db, batch, contacts)
+		if err != nil {
+			return err
+		}
+		chunk = append(chunk, entry)
+		chunkBytes += len(entry.Payload)
+		if len(chunk) == emergencyChunkSize || chunkBytes >= emergencyChunkBytes {
+			if err = upload(); err != nil {
+				return err
+			}
+		}
+	}
+	if len(chunk) > 0 {
+		return upload()
+	}
+	return nil
+}
+
+// sealEmergency seals local entries for trusted contacts of the user. Users can still work with their entries if it
+// fails, entries are sealed on the next sync then.
+func (s *Storage) sealEmergency() {
+	if err := s.sealEmergencyEntries(); err != nil {
+		s.logger.Warn().Err(err).Msg("Entries could not be sealed for trusted contacts")
+	}
+}
@@ -0,0 +1,59 @@
+package inmemory
+
+import (
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RequestError defines a failed server request described for users, it keeps the status code so that callers can
+// still branch on it with status.Code.
+type RequestError struct {
+	Code    codes.Code
+	Message string
+}
+
+// Error implements the error interface.
+func (e *RequestError) Error() string {
+	return e.Message
+}
+
+// GRPCStatus lets the status package recognize the error.
+func (e *RequestError) GRPCStatus() *status.Status {
+	return status.New(e.Code, e.Message)
+}
+
+// messages of failed requests by status codes
+var (
+	loginMessages = map[codes.Code]string{
+		codes.Unauthenticated: "invalid login or password",
+	}
+	registerMessages = map[codes.Code]string{
+		codes.AlreadyExists: "login is already taken",
+	}
+	entryMessages = map[codes.Code]string{
+		codes.AlreadyExists: "entry with this ID already exists on the server",
+	}
+	shareMessages = map[codes.Code]string{
+		codes.PermissionDenied: "entry is shared read-only",
+	}
+	sessionMessages = map[codes.Code]string{
+		codes.NotFound: "session was not found, it may have been revoked already",
+	}
+	serverMessages = map[codes.Code]string{
+		codes.Unavailable:      "server is unavailable, try again later",
+		codes.DeadlineExceeded: "server did not respond in time, try again later",
+		codes.Internal:         "server failed to process the request, try again later",
+	}
+)
+
+// requestError describes a failed request by its status code, errors of other codes are returned as they are.
+func requestError(code codes.Code, err error, messages map[codes.Code]string) error {
+	message, ok := messages[code]
+	if !ok {
+		message, ok = serverMessages[code]
+	}
+	if !ok {
+		return err
+	}
+	return &RequestError{Code: code, Message: message}
+}
@@ -4,11 +4,16 @@ package inmemory
 import (
 	"context"
 	"dk-go-gophkeeper/internal/client/grpcclient"
+	"dk-go-gophkeeper/internal/client/keyring"
 	"dk-go-gophkeeper/internal/client/storage"
 	"dk-go-gophkeeper/internal/client/storage/modelstorage"
+	"dk-go-gophkeeper/internal/client/storage/search"
 	"dk-go-gophkeeper/internal/config"
+	"dk-go-gophkeeper/internal/validation"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
 
 	"github.com/rs/zerolog"
 	"golang.org/x/sync/errgroup"
@@ -25,12 +30,13 @@ type Storage struct {
 	loginPasswordDB map[string]modelstorage.LoginAndPassword
 	textBinaryDB    map[string]modelstorage.TextOrBinary
 	clientGRPC      grpcclient.GRPCClient
+	keyring         keyring.Keeper
 	logger          *zerolog.Logger
 	cfg             *config.Config
 }
 
 // InitStorage initializes a Storage instance.
-func InitStorage(logger *zerolog.Logger, client grpcclient.GRPCClient, cfg *config.Config) *Storage {
+func InitStorage(logger *zerolog.Logger, client grpcclient.GRPCClient, keeper keyring.Keeper, cfg *config.Config) *Storage {
 	logger.Info().Msg("Attempting to initialize storage")
 	bankCardDB := make(map[string]modelstorage.BankCard)
 	loginPasswordDB := make(map[string]modelstorage.LoginAndPassword)
@@ -41,6 +47,7 @@ func InitStorage(logger *zerolog.Logger, client grpcclient.GRPCClient, cfg *conf
 		textBinaryDB:    textBinaryDB,
 		logger:          logger,
 		clientGRPC:      client,
+		keyring:         keeper,
 		cfg:             cfg,
 	}
 	return &st
@@ -98,7 +105,8 @@ func (s *Storage) Remove(identifier, db string) error {
 	return err
 }
 
-// Login sends a login request to the server and cleans local DB upon successful response.
+// Login sends a login request to the server and cleans local DB upon successful response, the key pair of the user is
+// published unless it is already.
 func (s *Storage) Login(login, password string) error {
 	if login == "" || password == "" {
 		return errors.New("Login/Password fields cannot be empty")
@@ -107,16 +115,18 @@ func (s *Storage) Login(login, password string) error {
 		Login:    login,
 		Password: password,
 	}
-	_, err := s.clientGRPC.Login(newLoginRegisterEntry)
+	code, err := s.clientGRPC.Login(newLoginRegisterEntry)
 	if err != nil {
 		s.logger.Error().Err(err).Msg("Could not perform login request")
-		return err
+		return requestError(code, err, loginMessages)
 	}
 	s.CleanDB()
+	s.publishKey()
 	return nil
 }
 
-// Register sends a register request to the server and cleans local DB upon successful response.
+// Register sends a register request to the server and cleans local DB upon successful response, a key pair of the user
+// is published so that other users can share entries with the user.
 func (s *Storage) Register(login, password string) error {
 	if login == "" || password == "" {
 		return errors.New("Login/Password fields cannot be empty")
@@ -125,25 +135,31 @@ func (s *Storage) Register(login, password string) error {
 		Login:    login,
 		Password: password,
 	}
-	_, err := s.clientGRPC.Register(newLoginRegisterEntry)
+	code, err := s.clientGRPC.Register(newLoginRegisterEntry)
 	if err != nil {
 		s.logger.Error().Err(err).Msg("Could not perform register request")
-		return err
+		return requestError(code, err, registerMessages)
 	}
 	s.CleanDB()
+	s.publishKey()
 	return nil
 }
 
 // AddBankCard adds a new bank card entry to the local client storage and sends it to the server.
-func (s *Storage) AddBankCard(identifier, number, holder, cvv, meta string) error {
+func (s *Storage) AddBankCard(identifier, number, holder, cvv, expiry, pin, meta string) error {
 	if identifier == "" {
 		return errors.New("identifier cannot be empty")
 	}
+	if err := validation.ValidateBankCard(number, cvv, expiry, pin); err != nil {
+		return err
+	}
 	newBankCardEntry := modelstorage.BankCard{
 		Identifier: identifier,
-		Number:     number,
+		Number:     validation.NormalizeCardNumber(number),
 		Holder:     holder,
 		Cvv:        cvv,
+		Expiry:     validation.NormalizeExpiry(expiry),
+		Pin:        pin,
 		Meta:       meta,
 	}
 	_, ok := s.bankCardDB[identifier]
@@ -153,11 +169,11 @@ func (s *Storage) AddBankCard(identifier, number, holder, cvv, meta string) erro
 	}
 	s.bankCardDB[identifier] = newBankCardEntry
 	s.logger.Info().Msgf("Added to bank card storage: %v", newBankCardEntry)
-	_, err := s.clientGRPC.SendBankCard(newBankCardEntry)
+	code, err := s.clientGRPC.SendBankCard(newBankCardEntry)
 	if err != nil {
 		delete(s.bankCardDB, identifier)
 		s.logger.Error().Err(err).Msg("Could not upload bank card entry")
-		return err
+		return requestError(code, err, entryMessages)
 	}
 	return nil
 }
@@ -180,11 +196,11 @@ func (s *Storage) AddLoginPassword(identifier, login, password, meta string) err
 	}
 	s.loginPasswordDB[identifier] = newLoginPasswordEntry
 	s.logger.Info().Msgf("Added to login/password storage: %v", newLoginPasswordEntry)
-	_, err := s.clientGRPC.SendLoginPassword(newLoginPasswordEntry)
+	code, err := s.clientGRPC.SendLoginPassword(newLoginPasswordEntry)
 	if err != nil {
 		delete(s.loginPasswordDB, identifier)
 		s.logger.Error().Err(err).Msg("Could not upload login/password entry")
-		return err
+		return requestError(code, err, entryMessages)
 	}
 	return nil
 }
@@ -206,16 +222,208 @@ func (s *Storage) AddTextBinary(identifier, entry, meta string) error {
 	}
 	s.textBinaryDB[identifier] = newTextBinaryEntry
 	s.logger.Info().Msgf("Added to text/binary storage: %v", newTextBinaryEntry)
-	_, err := s.clientGRPC.SendTextBinary(newTextBinaryEntry)
+	code, err := s.clientGRPC.SendTextBinary(newTextBinaryEntry)
 	if err != nil {
 		delete(s.textBinaryDB, identifier)
 		s.logger.Error().Err(err).Msg("Could not upload text/binary entry")
+		return requestError(code, err, entryMessages)
+	}
+	return nil
+}
+
+// AddBatch adds a batch of entries of mixed types to the local client storage and sends them to the server
+// in a single request.
+func (s *Storage) AddBatch(batch modelstorage.Batch) []modelstorage.BatchItemResult {
+	s.logger.Info().Msgf("Adding batch of %d entries", len(batch.BankCards)+len(batch.LoginsPasswords)+len(batch.TextsBinaries))
+	var results []modelstorage.BatchItemResult
+	var upload modelstorage.Batch
+	// pending holds indices of results which are being uploaded, in the upload order
+	var pending []int
+	check := func(identifier, db string) error {
+		if identifier == "" {
+			return errors.New("identifier cannot be empty")
+		}
+		if s.Exists(identifier, db) {
+			s.logger.Error().Msgf("Unique violation in %s storage for ID: %s", db, identifier)
+			return fmt.Errorf("entry ID %s in %s storage already exists", identifier, db)
+		}
+		return nil
+	}
+	for _, bankCard := range batch.BankCards {
+		err := check(bankCard.Identifier, s.cfg.BankCardDB)
+		results = append(results, modelstorage.BatchItemResult{Identifier: bankCard.Identifier, Db: s.cfg.BankCardDB, Err: err})
+		if err == nil {
+			s.bankCardDB[bankCard.Identifier] = bankCard
+			upload.BankCards = append(upload.BankCards, bankCard)
+			pending = append(pending, len(results)-1)
+		}
+	}
+	for _, loginPassword := range batch.LoginsPasswords {
+		err := check(loginPassword.Identifier, s.cfg.LoginPasswordDB)
+		results = append(results, modelstorage.BatchItemResult{Identifier: loginPassword.Identifier, Db: s.cfg.LoginPasswordDB, Err: err})
+		if err == nil {
+			s.loginPasswordDB[loginPassword.Identifier] = loginPassword
+			upload.LoginsPasswords = append(upload.LoginsPasswords, loginPassword)
+			pending = append(pending, len(results)-1)
+		}
+	}
+	for _, textBinary := range batch.TextsBinaries {
+		err := check(textBinary.Identifier, s.cfg.TextBinaryDB)
+		results = append(results, modelstorage.BatchItemResult{Identifier: textBinary.Identifier, Db: s.cfg.TextBinaryDB, Err: err})
+		if err == nil {
+			s.textBinaryDB[textBinary.Identifier] = textBinary
+			upload.TextsBinaries = append(upload.TextsBinaries, textBinary)
+			pending = append(pending, len(results)-1)
+		}
+	}
+	if len(pending) == 0 {
+		return results
+	}
+	uploadResults, _, err := s.clientGRPC.SendBatch(upload, false)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Could not upload batch")
+		for _, idx := range pending {
+			results[idx].Err = err
+			s.removeLocal(results[idx].Identifier, results[idx].Db)
+		}
+		return results
+	}
+	for i, uploadResult := range uploadResults {
+		if i >= len(pending) || uploadResult.Err == nil {
+			continue
+		}
+		idx := pending[i]
+		s.logger.Error().Err(uploadResult.Err).Msgf("Could not upload entry %s", results[idx].Identifier)
+		results[idx].Err = uploadResult.Err
+		s.removeLocal(results[idx].Identifier, results[idx].Db)
+	}
+	return results
+}
+
+// SetLabels replaces labels of an entry in the local client storage once the updated entry is uploaded to the server.
+func (s *Storage) SetLabels(identifier, db string, labels modelstorage.Labels) error {
+	labels = search.CleanLabels(labels)
+	return s.update(identifier, db, "labels", func(entryLabels *modelstorage.Labels, _ *[]modelstorage.CustomField) {
+		*entryLabels = labels
+	})
+}
+
+// SetFields replaces custom fields of an entry in the local client storage once the updated entry is uploaded to the
+// server.
+func (s *Storage) SetFields(identifier, db string, fields []modelstorage.CustomField) error {
+	return s.update(identifier, db, "custom fields", func(_ *modelstorage.Labels, entryFields *[]modelstorage.CustomField) {
+		*entryFields = fields
+	})
+}
+
+// update applies a change to a copy of an existing entry, uploads the changed entry as a single-item batch and
+// stores it locally on success only.
+func (s *Storage) update(identifier, db, what string, apply func(labels *modelstorage.Labels, fields *[]modelstorage.CustomField)) error {
+	var upload modelstorage.Batch
+	var ok bool
+	switch db {
+	case s.cfg.BankCardDB:
+		var value modelstorage.BankCard
+		if value, ok = s.bankCardDB[identifier]; ok {
+			apply(&value.Labels, &value.Fields)
+			upload.BankCards = append(upload.BankCards, value)
+		}
+	case s.cfg.LoginPasswordDB:
+		var value modelstorage.LoginAndPassword
+		if value, ok = s.loginPasswordDB[identifier]; ok {
+			apply(&value.Labels, &value.Fields)
+			upload.LoginsPasswords = append(upload.LoginsPasswords, value)
+		}
+	case s.cfg.TextBinaryDB:
+		var value modelstorage.TextOrBinary
+		if value, ok = s.textBinaryDB[identifier]; ok {
+			apply(&value.Labels, &value.Fields)
+			upload.TextsBinaries = append(upload.TextsBinaries, value)
+		}
+	default:
+		return fmt.Errorf("invalid db %s", db)
+	}
+	if !ok {
+		return fmt.Errorf("entry ID %s in %s storage does not exist", identifier, db)
+	}
+	results, _, err := s.clientGRPC.SendBatch(upload, true)
+	if err == nil && len(results) > 0 {
+		err = results[0].Err
+	}
+	if err != nil {
+		s.logger.Error().Err(err).Msgf("Could not upload %s of entry %s", what, identifier)
+		return err
+	}
+	for _, value := range upload.BankCards {
+		s.bankCardDB[identifier] = value
+	}
+	for _, value := range upload.LoginsPasswords {
+		s.loginPasswordDB[identifier] = value
+	}
+	for _, value := range upload.TextsBinaries {
+		s.textBinaryDB[identifier] = value
+	}
+	s.logger.Info().Msgf("Set %s of entry %s in %s storage", what, identifier, db)
+	if err = s.resealShare(identifier, db); err != nil {
+		s.logger.Error().Err(err).Msgf("Could not update shares of entry %s", identifier)
 		return err
 	}
 	return nil
 }
 
-// Sync performs retrieval of all data from server overwriting local storage.
+// removeLocal deletes an entry from local storage only.
+func (s *Storage) removeLocal(identifier, db string) {
+	switch db {
+	case s.cfg.BankCardDB:
+		delete(s.bankCardDB, identifier)
+	case s.cfg.LoginPasswordDB:
+		delete(s.loginPasswordDB, identifier)
+	case s.cfg.TextBinaryDB:
+		delete(s.textBinaryDB, identifier)
+	}
+}
+
+// Exists checks whether an entry with the given identifier is present in local storage.
+func (s *Storage) Exists(identifier, db string) bool {
+	var ok bool
+	switch db {
+	case s.cfg.BankCardDB:
+		_, ok = s.bankCardDB[identifier]
+	case s.cfg.LoginPasswordDB:
+		_, ok = s.loginPasswordDB[identifier]
+	case s.cfg.TextBinaryDB:
+		_, ok = s.textBinaryDB[identifier]
+	}
+	return ok
+}
+
+// Export returns copies of all locally stored entries sorted by identifier.
+func (s *Storage) Export() modelstorage.Batch {
+	var batch modelstorage.Batch
+	for _, value := range s.bankCardDB {
+		batch.BankCards = append(batch.BankCards, value)
+	}
+	for _, value := range s.loginPasswordDB {
+		batch.LoginsPasswords = append(batch.LoginsPasswords, value)
+	}
+	for _, value := range s.textBinaryDB {
+		batch.TextsBinaries = append(batch.TextsBinaries, value)
+	}
+	sort.Slice(batch.BankCards, func(i, j int) bool { return batch.BankCards[i].Identifier < batch.BankCards[j].Identifier })
+	sort.Slice(batch.LoginsPasswords, func(i, j int) bool {
+		return batch.LoginsPasswords[i].Identifier < batch.LoginsPasswords[j].Identifier
+	})
+	sort.Slice(batch.TextsBinaries, func(i, j int) bool { return batch.TextsBinaries[i].Identifier < batch.TextsBinaries[j].Identifier })
+	return batch
+}
+
+// Search returns summaries of entries fuzzy matching the query by identifier or meta, all entries match an empty query.
+func (s *Storage) Search(query string, order modelstorage.Order) []modelstorage.Summary {
+	return search.Filter(s.Export(), s.cfg.BankCardDB, s.cfg.LoginPasswordDB, s.cfg.TextBinaryDB, query, order)
+}
+
+// Sync performs retrieval of all data from server overwriting local storage, entries added or changed since the last
+// sync are sealed for trusted contacts of the user then.
 func (s *Storage) Sync() error {
 	s.logger.Info().Msg("Attempting sync")
 	grp, _ := errgroup.WithContext(context.Background())
@@ -226,15 +434,16 @@ func (s *Storage) Sync() error {
 	if err := grp.Wait(); err != nil {
 		return err
 	}
+	s.sealEmergency()
 	s.logger.Info().Msg("Sync performed successfully")
 	return nil
 }
 
 // dumpBankCards retrieves bank card entries from server.
 func (s *Storage) dumpBankCards() error {
-	cloudDataBankCards, _, err := s.clientGRPC.GetBankCards()
+	cloudDataBankCards, code, err := s.clientGRPC.GetBankCards()
 	if err != nil {
-		return err
+		return requestError(code, err, nil)
 	}
 	for identifier, value := range cloudDataBankCards {
 		// overwrite any local data with cloud data
@@ -245,9 +454,9 @@ func (s *Storage) dumpBankCards() error {
 
 // dumpLoginsPasswords retrieves login/password entries from server.
 func (s *Storage) dumpLoginsPasswords() error {
-	cloudDataLoginsPasswords, _, err := s.clientGRPC.GetLoginsPasswords()
+	cloudDataLoginsPasswords, code, err := s.clientGRPC.GetLoginsPasswords()
 	if err != nil {
-		return err
+		return requestError(code, err, nil)
 	}
 	for identifier, value := range cloudDataLoginsPasswords {
 		// overwrite any local data with cloud data
@@ -258,9 +467,9 @@ func (s *Storage) dumpLoginsPasswords() error {
 
 // dumpTextsBinaries retrieves text/binary entries from server.
 func (s *Storage) dumpTextsBinaries() error {
-	cloudDataTextsBinaries, _, err := s.clientGRPC.GetTextsBinaries()
+	cloudDataTextsBinaries, code, err := s.clientGRPC.GetTextsBinaries()
 	if err != nil {
-		return err
+		return requestError(code, err, nil)
 	}
 	for identifier, value := range cloudDataTextsBinaries {
 		// overwrite any local data with cloud data
@@ -269,39 +478,31 @@ func (s *Storage) dumpTextsBinaries() error {
 	return nil
 }
 
-// Get retrieves a data piece from local storage.
+// Get retrieves a data piece from local storage rendered as indented JSON.
 func (s *Storage) Get(identifier, db string) (string, error) {
 	if identifier == "" {
 		return "", fmt.Errorf("identifier cannot be empty in db %s", db)
 	}
-	var err error
-	var data string
+	var value interface{}
+	var ok bool
 	switch db {
-	case "bankCard":
-		value, ok := s.bankCardDB[identifier]
-		if ok {
-			data = fmt.Sprintf("%#v", value) + "\n"
-		} else {
-			err = fmt.Errorf("entry ID %s in %s storage does not exist", identifier, db)
-		}
-	case "loginPassword":
-		value, ok := s.loginPasswordDB[identifier]
-		if ok {
-			data = fmt.Sprintf("%#v", value) + "\n"
-		} else {
-			err = fmt.Errorf("entry ID %s in %s storage does not exist", identifier, db)
-		}
-	case "textBinary":
-		value, ok := s.textBinaryDB[identifier]
-		if ok {
-			data = fmt.Sprintf("%#v", value) + "\n"
-		} else {
-			err = fmt.Errorf("entry ID %s in %s storage does not exist", identifier, db)
-		}
+	case s.cfg.BankCardDB:
+		value, ok = s.bankCardDB[identifier]
+	case s.cfg.LoginPasswordDB:
+		value, ok = s.loginPasswordDB[identifier]
+	case s.cfg.TextBinaryDB:
+		value, ok = s.textBinaryDB[identifier]
 	default:
-		err = fmt.Errorf("invalid db %s", db)
+		return "", fmt.Errorf("invalid db %s", db)
+	}
+	if !ok {
+		return "", fmt.Errorf("entry ID %s in %s storage does not exist", identifier, db)
+	}
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return "", err
 	}
-	return data, err
+	return string(data) + "\n", nil
 }
 
 // CleanDB re-initializes a local DB.
@@ -1,17 +1,23 @@
 package inmemory
 
 import (
+	keyring "dk-go-gophkeeper/internal/client/keyring/v1"
 	"dk-go-gophkeeper/internal/client/storage/modelstorage"
 	"dk-go-gophkeeper/internal/config"
+	"dk-go-gophkeeper/internal/keys"
 	"dk-go-gophkeeper/internal/mocks"
 	"errors"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 func TestInitStorage(t *testing.T) {
@@ -23,7 +29,7 @@ func TestInitStorage(t *testing.T) {
 	defer ctrl.Finish()
 	client := mocks.NewMockGRPCClient(ctrl)
 	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
-	_ = InitStorage(&logger, client, cfg)
+	_ = InitStorage(&logger, client, nil, cfg)
 }
 
 func TestStorage_Remove(t *testing.T) {
@@ -39,8 +45,8 @@ func TestStorage_Remove(t *testing.T) {
 	client.EXPECT().SendTextBinary(gomock.Any()).Return(codes.OK, nil)
 
 	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
-	st := InitStorage(&logger, client, cfg)
-	_ = st.AddBankCard("id1", "", "", "", "")
+	st := InitStorage(&logger, client, nil, cfg)
+	_ = st.AddBankCard("id1", "4111111111111111", "", "123", "", "", "")
 	_ = st.AddLoginPassword("id2", "", "", "")
 	_ = st.AddTextBinary("id3", "", "")
 
@@ -90,10 +96,11 @@ func TestStorage_Login(t *testing.T) {
 	client.EXPECT().SendBankCard(gomock.Any()).Return(codes.OK, nil)
 	client.EXPECT().SendLoginPassword(gomock.Any()).Return(codes.OK, nil)
 	client.EXPECT().SendTextBinary(gomock.Any()).Return(codes.OK, nil)
+	keeper := mocks.NewMockKeeper(ctrl)
 
 	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
-	st := InitStorage(&logger, client, cfg)
-	_ = st.AddBankCard("id1", "", "", "", "")
+	st := InitStorage(&logger, client, keeper, cfg)
+	_ = st.AddBankCard("id1", "4111111111111111", "", "123", "", "", "")
 	_ = st.AddLoginPassword("id2", "", "", "")
 	_ = st.AddTextBinary("id3", "", "")
 
@@ -104,7 +111,21 @@ func TestStorage_Login(t *testing.T) {
 	err = st.Login("generic_login", "generic_password")
 	assert.Equal(t, "generic_error", err.Error())
 
+	client.EXPECT().Login(gomock.Any()).Return(codes.Unauthenticated, errors.New("generic_error"))
+	err = st.Login("generic_login", "generic_password")
+	assert.Equal(t, "invalid login or password", err.Error())
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	client.EXPECT().Login(gomock.Any()).Return(codes.Unavailable, errors.New("generic_error"))
+	err = st.Login("generic_login", "generic_password")
+	assert.Equal(t, "server is unavailable, try again later", err.Error())
+
+	// a new key pair is published on the first login
 	client.EXPECT().Login(gomock.Any()).Return(codes.OK, nil)
+	client.EXPECT().GetPublicKey("").Return("", codes.NotFound, status.Error(codes.NotFound, "no public key is published"))
+	keeper.EXPECT().NewKeyPair().Return("public_key", nil)
+	client.EXPECT().SetPublicKey("public_key").Return(codes.OK, nil)
+	keeper.EXPECT().PrivateKey("public_key").Return("private_key", nil)
 	err = st.Login("generic_login", "generic_password")
 	assert.Equal(t, nil, err)
 
@@ -129,8 +150,8 @@ func TestStorage_Register(t *testing.T) {
 	client.EXPECT().SendTextBinary(gomock.Any()).Return(codes.OK, nil)
 
 	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
-	st := InitStorage(&logger, client, cfg)
-	_ = st.AddBankCard("id1", "", "", "", "")
+	st := InitStorage(&logger, client, nil, cfg)
+	_ = st.AddBankCard("id1", "4111111111111111", "", "123", "", "", "")
 	_ = st.AddLoginPassword("id2", "", "", "")
 	_ = st.AddTextBinary("id3", "", "")
 
@@ -141,7 +162,14 @@ func TestStorage_Register(t *testing.T) {
 	err = st.Register("generic_login", "generic_password")
 	assert.Equal(t, "generic_error", err.Error())
 
+	client.EXPECT().Register(gomock.Any()).Return(codes.AlreadyExists, errors.New("generic_error"))
+	err = st.Register("generic_login", "generic_password")
+	assert.Equal(t, "login is already taken", err.Error())
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+
+	// users are registered even if their key pair cannot be published yet
 	client.EXPECT().Register(gomock.Any()).Return(codes.OK, nil)
+	client.EXPECT().GetPublicKey("").Return("", codes.Unavailable, status.Error(codes.Unavailable, "connection refused"))
 	err = st.Register("generic_login", "generic_password")
 	assert.Equal(t, nil, err)
 
@@ -162,21 +190,28 @@ func TestStorage_AddBankCard(t *testing.T) {
 	defer ctrl.Finish()
 	client := mocks.NewMockGRPCClient(ctrl)
 	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
-	st := InitStorage(&logger, client, cfg)
+	st := InitStorage(&logger, client, nil, cfg)
 
-	err := st.AddBankCard("", "", "", "", "")
+	err := st.AddBankCard("", "4111111111111111", "", "123", "", "", "")
 	assert.Equal(t, "identifier cannot be empty", err.Error())
 
 	client.EXPECT().SendBankCard(gomock.Any()).Return(codes.OK, nil)
-	err = st.AddBankCard("id1", "", "", "", "")
+	err = st.AddBankCard("id1", "4111111111111111", "", "123", "", "", "")
 	assert.Equal(t, nil, err)
 
-	err = st.AddBankCard("id1", "", "", "", "")
+	err = st.AddBankCard("id1", "4111111111111111", "", "123", "", "", "")
 	assert.Equal(t, "entry of type 'Bank Card' with ID id1 already exists", err.Error())
 
 	client.EXPECT().SendBankCard(gomock.Any()).Return(codes.Unknown, errors.New("generic_error"))
-	err = st.AddBankCard("id2", "", "", "", "")
+	err = st.AddBankCard("id2", "4111111111111111", "", "123", "", "", "")
 	assert.Equal(t, "generic_error", err.Error())
+
+	err = st.AddBankCard("id3", "4111111111111112", "", "123", "13/30", "", "")
+	assert.Equal(t, "card number fails the checksum, check it for typos; expiry date must be of the MM/YY form", err.Error())
+
+	client.EXPECT().SendBankCard(modelstorage.BankCard{Identifier: "id4", Number: "5555555555554444", Cvv: "123", Expiry: "09/31", Pin: "0000"}).Return(codes.OK, nil)
+	err = st.AddBankCard("id4", "5555 5555 5555 4444", "", "123", "09/2031", "0000", "")
+	assert.Equal(t, nil, err)
 }
 
 func TestStorage_AddLoginPassword(t *testing.T) {
@@ -188,7 +223,7 @@ func TestStorage_AddLoginPassword(t *testing.T) {
 	defer ctrl.Finish()
 	client := mocks.NewMockGRPCClient(ctrl)
 	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
-	st := InitStorage(&logger, client, cfg)
+	st := InitStorage(&logger, client, nil, cfg)
 
 	err := st.AddLoginPassword("", "", "", "")
 	assert.Equal(t, "identifier cannot be empty", err.Error())
@@ -214,7 +249,7 @@ func TestStorage_AddTextBinary(t *testing.T) {
 	defer ctrl.Finish()
 	client := mocks.NewMockGRPCClient(ctrl)
 	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
-	st := InitStorage(&logger, client, cfg)
+	st := InitStorage(&logger, client, nil, cfg)
 
 	err := st.AddTextBinary("", "", "")
 	assert.Equal(t, "identifier cannot be empty", err.Error())
@@ -231,6 +266,196 @@ func TestStorage_AddTextBinary(t *testing.T) {
 	assert.Equal(t, "generic_error", err.Error())
 }
 
+func TestStorage_AddBatch(t *testing.T) {
+	cfg := config.NewDefaultConfiguration()
+	cfg.BankCardDB = "bankCard"
+	cfg.LoginPasswordDB = "loginPassword"
+	cfg.TextBinaryDB = "textBinary"
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mocks.NewMockGRPCClient(ctrl)
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	st := InitStorage(&logger, client, nil, cfg)
+
+	client.EXPECT().SendTextBinary(gomock.Any()).Return(codes.OK, nil)
+	_ = st.AddTextBinary("id4", "", "")
+	uploadResults := []modelstorage.BatchItemResult{
+		{Identifier: "id1", Db: "bankCard"},
+		{Identifier: "id2", Db: "loginPassword", Err: errors.New("generic_error")},
+		{Identifier: "id3", Db: "textBinary"},
+	}
+	client.EXPECT().SendBatch(gomock.Any(), false).Return(uploadResults, codes.OK, nil)
+	batch := modelstorage.Batch{
+		BankCards:       []modelstorage.BankCard{{Identifier: "id1"}},
+		LoginsPasswords: []modelstorage.LoginAndPassword{{Identifier: "id2"}},
+		TextsBinaries:   []modelstorage.TextOrBinary{{Identifier: "id3"}, {Identifier: ""}, {Identifier: "id4"}},
+	}
+	results := st.AddBatch(batch)
+	assert.Equal(t, 5, len(results))
+	assert.Equal(t, modelstorage.BatchItemResult{Identifier: "id1", Db: "bankCard", Err: nil}, results[0])
+	assert.Equal(t, "generic_error", results[1].Err.Error())
+	assert.Equal(t, nil, results[2].Err)
+	assert.Equal(t, "identifier cannot be empty", results[3].Err.Error())
+	assert.Equal(t, "entry ID id4 in textBinary storage already exists", results[4].Err.Error())
+	assert.Equal(t, true, st.Exists("id1", "bankCard"))
+	assert.Equal(t, false, st.Exists("id2", "loginPassword"))
+	assert.Equal(t, true, st.Exists("id3", "textBinary"))
+
+	client.EXPECT().SendBatch(gomock.Any(), false).Return(nil, codes.Unknown, errors.New("generic_error"))
+	results = st.AddBatch(modelstorage.Batch{BankCards: []modelstorage.BankCard{{Identifier: "id5"}}})
+	assert.Equal(t, "generic_error", results[0].Err.Error())
+	assert.Equal(t, false, st.Exists("id5", "bankCard"))
+}
+
+func TestStorage_SetLabels(t *testing.T) {
+	cfg := config.NewDefaultConfiguration()
+	cfg.BankCardDB = "bankCard"
+	cfg.LoginPasswordDB = "loginPassword"
+	cfg.TextBinaryDB = "textBinary"
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mocks.NewMockGRPCClient(ctrl)
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	st := InitStorage(&logger, client, nil, cfg)
+
+	client.EXPECT().SendLoginPassword(gomock.Any()).Return(codes.OK, nil)
+	_ = st.AddLoginPassword("id1", "login", "password", "meta")
+	labels := modelstorage.Labels{Folder: "Work/Dev", Tags: []string{"code"}, Favorite: true}
+	client.EXPECT().SendBatch(modelstorage.Batch{LoginsPasswords: []modelstorage.LoginAndPassword{
+		{Identifier: "id1", Login: "login", Password: "password", Meta: "meta", Labels: labels},
+	}}, true).Return([]modelstorage.BatchItemResult{{Identifier: "id1", Db: "loginPassword"}}, codes.OK, nil)
+	client.EXPECT().GetShares().Return(nil, codes.OK, nil)
+	err := st.SetLabels("id1", "loginPassword", modelstorage.Labels{Folder: "/Work/Dev/", Tags: []string{"#Code"}, Favorite: true})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []modelstorage.LoginAndPassword{
+		{Identifier: "id1", Login: "login", Password: "password", Meta: "meta", Labels: labels},
+	}, st.Export().LoginsPasswords)
+
+	client.EXPECT().SendBatch(gomock.Any(), true).Return([]modelstorage.BatchItemResult{{Identifier: "id1", Db: "loginPassword", Err: errors.New("generic_error")}}, codes.OK, nil)
+	err = st.SetLabels("id1", "loginPassword", modelstorage.Labels{})
+	assert.Equal(t, "generic_error", err.Error())
+	assert.Equal(t, labels, st.Export().LoginsPasswords[0].Labels)
+
+	err = st.SetLabels("id2", "loginPassword", modelstorage.Labels{})
+	assert.Equal(t, "entry ID id2 in loginPassword storage does not exist", err.Error())
+	err = st.SetLabels("id1", "generic_db", modelstorage.Labels{})
+	assert.Equal(t, "invalid db generic_db", err.Error())
+}
+
+func TestStorage_SetFields(t *testing.T) {
+	cfg := config.NewDefaultConfiguration()
+	cfg.BankCardDB = "bankCard"
+	cfg.LoginPasswordDB = "loginPassword"
+	cfg.TextBinaryDB = "textBinary"
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mocks.NewMockGRPCClient(ctrl)
+	cfg.KeyringPath = filepath.Join(t.TempDir(), "keyring.json")
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	keyringInstance, err := keyring.InitFileKeyring(&logger, cfg)
+	assert.Equal(t, nil, err)
+	publicKey, err := keyringInstance.NewKeyPair()
+	assert.Equal(t, nil, err)
+	st := InitStorage(&logger, client, keyringInstance, cfg)
+
+	client.EXPECT().SendTextBinary(gomock.Any()).Return(codes.OK, nil)
+	_ = st.AddTextBinary("id1", "entry", "meta")
+	fields := []modelstorage.CustomField{{Name: "Recovery code", Value: "abc", Concealed: true}}
+	client.EXPECT().SendBatch(modelstorage.Batch{TextsBinaries: []modelstorage.TextOrBinary{
+		{Identifier: "id1", Entry: "entry", Meta: "meta", Fields: fields},
+	}}, true).Return([]modelstorage.BatchItemResult{{Identifier: "id1", Db: "textBinary"}}, codes.OK, nil)
+	// everyone the entry is shared with gets the update sealed with its record key
+	recordKey, _ := keys.NewRecordKey()
+	ownerKey, _ := keys.WrapKey(recordKey, publicKey)
+	client.EXPECT().GetShares().Return([]modelstorage.Share{{Identifier: "id1", Db: "textBinary", OwnerKey: ownerKey}}, codes.OK, nil)
+	client.EXPECT().GetPublicKey("").Return(publicKey, codes.OK, nil)
+	var payload string
+	client.EXPECT().SetSharePayload("id1", "textBinary", gomock.Any()).DoAndReturn(func(_, _, sealed string) (codes.Code, error) {
+		payload = sealed
+		return codes.OK, nil
+	})
+	err = st.SetFields("id1", "textBinary", fields)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, fields, st.Export().TextsBinaries[0].Fields)
+	batch, err := openEntry(recordKey, payload)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, st.Export().TextsBinaries, batch.TextsBinaries)
+
+	client.EXPECT().SendBatch(gomock.Any(), true).Return(nil, codes.Unavailable, errors.New("generic_error"))
+	err = st.SetFields("id1", "textBinary", nil)
+	assert.Equal(t, "generic_error", err.Error())
+	assert.Equal(t, fields, st.Export().TextsBinaries[0].Fields)
+}
+
+func TestStorage_Exists(t *testing.T) {
+	cfg := config.NewDefaultConfiguration()
+	cfg.BankCardDB = "bankCard"
+	cfg.LoginPasswordDB = "loginPassword"
+	cfg.TextBinaryDB = "textBinary"
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mocks.NewMockGRPCClient(ctrl)
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	st := InitStorage(&logger, client, nil, cfg)
+
+	client.EXPECT().SendBankCard(gomock.Any()).Return(codes.OK, nil)
+	_ = st.AddBankCard("id1", "4111111111111111", "", "123", "", "", "")
+	assert.Equal(t, true, st.Exists("id1", cfg.BankCardDB))
+	assert.Equal(t, false, st.Exists("id1", cfg.LoginPasswordDB))
+	assert.Equal(t, false, st.Exists("id1", "generic_db"))
+}
+
+func TestStorage_Export(t *testing.T) {
+	cfg := config.NewDefaultConfiguration()
+	cfg.BankCardDB = "bankCard"
+	cfg.LoginPasswordDB = "loginPassword"
+	cfg.TextBinaryDB = "textBinary"
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mocks.NewMockGRPCClient(ctrl)
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	st := InitStorage(&logger, client, nil, cfg)
+
+	client.EXPECT().SendLoginPassword(gomock.Any()).Return(codes.OK, nil).Times(2)
+	client.EXPECT().SendTextBinary(gomock.Any()).Return(codes.OK, nil)
+	_ = st.AddLoginPassword("id2", "login2", "password2", "")
+	_ = st.AddLoginPassword("id1", "login1", "password1", "")
+	_ = st.AddTextBinary("id3", "entry", "meta")
+	batch := st.Export()
+	assert.Equal(t, 0, len(batch.BankCards))
+	assert.Equal(t, []modelstorage.LoginAndPassword{
+		{Identifier: "id1", Login: "login1", Password: "password1"},
+		{Identifier: "id2", Login: "login2", Password: "password2"},
+	}, batch.LoginsPasswords)
+	assert.Equal(t, []modelstorage.TextOrBinary{{Identifier: "id3", Entry: "entry", Meta: "meta"}}, batch.TextsBinaries)
+}
+
+func TestStorage_Search(t *testing.T) {
+	cfg := config.NewDefaultConfiguration()
+	cfg.BankCardDB = "bankCard"
+	cfg.LoginPasswordDB = "loginPassword"
+	cfg.TextBinaryDB = "textBinary"
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mocks.NewMockGRPCClient(ctrl)
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	st := InitStorage(&logger, client, nil, cfg)
+
+	client.EXPECT().SendLoginPassword(gomock.Any()).Return(codes.OK, nil)
+	client.EXPECT().SendBankCard(gomock.Any()).Return(codes.OK, nil)
+	_ = st.AddLoginPassword("github", "login", "password", "work")
+	_ = st.AddBankCard("visa", "4111111111111111", "holder", "123", "", "", "github sponsors")
+	assert.Equal(t, []modelstorage.Summary{
+		{Identifier: "github", Db: "loginPassword", Meta: "work", Score: 10},
+		{Identifier: "visa", Db: "bankCard", Meta: "github sponsors", Score: 5},
+	}, st.Search("gh", modelstorage.OrderRelevance))
+	assert.Equal(t, []modelstorage.Summary{
+		{Identifier: "visa", Db: "bankCard", Meta: "github sponsors", Score: 5},
+		{Identifier: "github", Db: "loginPassword", Meta: "work", Score: 10},
+	}, st.Search("gh", modelstorage.OrderDb))
+	assert.Equal(t, 2, len(st.Search("", modelstorage.OrderIdentifier)))
+}
+
 func TestStorage_Get(t *testing.T) {
 	cfg := config.NewDefaultConfiguration()
 	cfg.BankCardDB = "bankCard"
@@ -244,8 +469,8 @@ func TestStorage_Get(t *testing.T) {
 	client.EXPECT().SendTextBinary(gomock.Any()).Return(codes.OK, nil)
 
 	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
-	st := InitStorage(&logger, client, cfg)
-	_ = st.AddBankCard("id1", "", "", "", "")
+	st := InitStorage(&logger, client, nil, cfg)
+	_ = st.AddBankCard("id1", "4111111111111111", "", "123", "", "", "")
 	_ = st.AddLoginPassword("id2", "", "", "")
 	_ = st.AddTextBinary("id3", "", "")
 
@@ -257,8 +482,9 @@ func TestStorage_Get(t *testing.T) {
 
 	_, err = st.Get("id1", cfg.BankCardDB)
 	assert.Equal(t, nil, err)
-	_, err = st.Get("id2", cfg.LoginPasswordDB)
+	data, err := st.Get("id2", cfg.LoginPasswordDB)
 	assert.Equal(t, nil, err)
+	assert.Equal(t, "{\n  \"identifier\": \"id2\",\n  \"login\": \"\",\n  \"password\": \"\",\n  \"meta\": \"\"\n}\n", data)
 	_, err = st.Get("id3", cfg.TextBinaryDB)
 	assert.Equal(t, nil, err)
 
@@ -283,8 +509,8 @@ func TestStorage_Sync(t *testing.T) {
 	client.EXPECT().SendTextBinary(gomock.Any()).Return(codes.OK, nil)
 
 	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
-	st := InitStorage(&logger, client, cfg)
-	_ = st.AddBankCard("id1", "", "", "", "")
+	st := InitStorage(&logger, client, nil, cfg)
+	_ = st.AddBankCard("id1", "4111111111111111", "", "123", "", "", "")
 	_ = st.AddLoginPassword("id2", "", "", "")
 	_ = st.AddTextBinary("id3", "", "")
 
@@ -300,6 +526,8 @@ func TestStorage_Sync(t *testing.T) {
 	client.EXPECT().GetBankCards().Return(cloudBankCardData, codes.OK, nil)
 	client.EXPECT().GetLoginsPasswords().Return(cloudLoginPasswordData, codes.OK, nil)
 	client.EXPECT().GetTextsBinaries().Return(cloudTextBinaryData, codes.OK, nil)
+	// entries are sealed for trusted contacts only
+	client.EXPECT().ListEmergencyAccess().Return(modelstorage.EmergencyAccessList{}, codes.OK, nil)
 	err = st.Sync()
 	assert.Equal(t, nil, err)
 
@@ -310,3 +538,184 @@ func TestStorage_Sync(t *testing.T) {
 	_, ok = st.textBinaryDB["id6"]
 	assert.Equal(t, true, ok)
 }
+
+func TestStorage_Share(t *testing.T) {
+	cfg := config.NewDefaultConfiguration()
+	cfg.BankCardDB = "bankCard"
+	cfg.LoginPasswordDB = "loginPassword"
+	cfg.TextBinaryDB = "textBinary"
+	cfg.KeyringPath = filepath.Join(t.TempDir(), "keyring.json")
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mocks.NewMockGRPCClient(ctrl)
+	client.EXPECT().SendLoginPassword(gomock.Any()).Return(codes.OK, nil)
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	keyringInstance, err := keyring.InitFileKeyring(&logger, cfg)
+	assert.Equal(t, nil, err)
+	publicKey, err := keyringInstance.NewKeyPair()
+	assert.Equal(t, nil, err)
+	client.EXPECT().GetPublicKey("").Return(publicKey, codes.OK, nil).AnyTimes()
+	st := InitStorage(&logger, client, keyringInstance, cfg)
+	_ = st.AddLoginPassword("staging", "db_user", "pass", "")
+
+	err = st.Share("production", cfg.LoginPasswordDB, "bob", modelstorage.PermissionRead)
+	assert.Equal(t, "entry ID production in loginPassword storage does not exist", err.Error())
+	err = st.Share("staging", cfg.LoginPasswordDB, "bob", "owner")
+	assert.Equal(t, "invalid permission owner, expected read or read-write", err.Error())
+
+	client.EXPECT().GetPublicKey("carol").Return("", codes.NotFound, status.Error(codes.NotFound, "user carol is not registered"))
+	err = st.Share("staging", cfg.LoginPasswordDB, "carol", modelstorage.PermissionRead)
+	assert.Equal(t, "user carol is not registered", err.Error())
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	// the entry is sealed with a record key wrapped for the owner and the recipient only
+	bobPublicKey, bobPrivateKey, _ := keys.NewKeyPair()
+	client.EXPECT().GetPublicKey("bob").Return(bobPublicKey, codes.OK, nil)
+	client.EXPECT().GetShares().Return(nil, codes.OK, nil)
+	var sealed modelstorage.SealedEntry
+	client.EXPECT().ShareEntry("staging", cfg.LoginPasswordDB, "bob", modelstorage.PermissionRead, gomock.Any()).DoAndReturn(
+		func(_, _, _, _ string, entry modelstorage.SealedEntry) (codes.Code, error) {
+			sealed = entry
+			return codes.OK, nil
+		})
+	err = st.Share("staging", cfg.LoginPasswordDB, "bob", modelstorage.PermissionRead)
+	assert.Equal(t, nil, err)
+	recordKey, err := keys.UnwrapKey(sealed.WrappedKeys["bob"], bobPublicKey, bobPrivateKey)
+	assert.Equal(t, nil, err)
+	batch, err := openEntry(recordKey, sealed.Payload)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, st.Export(), batch)
+
+	// remaining recipients get a new record key the revoked recipient cannot unwrap
+	carolPublicKey, carolPrivateKey, _ := keys.NewKeyPair()
+	client.EXPECT().GetShares().Return([]modelstorage.Share{{
+		Identifier: "staging",
+		Db:         cfg.LoginPasswordDB,
+		OwnerKey:   sealed.OwnerKey,
+		Recipients: []modelstorage.ShareRecipient{{Login: "bob", PublicKey: bobPublicKey}, {Login: "carol", PublicKey: carolPublicKey}},
+	}}, codes.OK, nil)
+	client.EXPECT().RevokeShare("staging", cfg.LoginPasswordDB, "bob", gomock.Any()).DoAndReturn(
+		func(_, _, _ string, entry modelstorage.SealedEntry) (codes.Code, error) {
+			sealed = entry
+			return codes.Internal, status.Error(codes.Internal, "internal server error")
+		})
+	err = st.Unshare("staging", cfg.LoginPasswordDB, "bob")
+	assert.Equal(t, "server failed to process the request, try again later", err.Error())
+	assert.Equal(t, 1, len(sealed.WrappedKeys))
+	newRecordKey, err := keys.UnwrapKey(sealed.WrappedKeys["carol"], carolPublicKey, carolPrivateKey)
+	assert.Equal(t, nil, err)
+	assert.NotEqual(t, recordKey, newRecordKey)
+}
+
+func TestStorage_UpdateShared(t *testing.T) {
+	cfg := config.NewDefaultConfiguration()
+	cfg.BankCardDB = "bankCard"
+	cfg.LoginPasswordDB = "loginPassword"
+	cfg.TextBinaryDB = "textBinary"
+	cfg.KeyringPath = filepath.Join(t.TempDir(), "keyring.json")
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mocks.NewMockGRPCClient(ctrl)
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	keyringInstance, err := keyring.InitFileKeyring(&logger, cfg)
+	assert.Equal(t, nil, err)
+	publicKey, err := keyringInstance.NewKeyPair()
+	assert.Equal(t, nil, err)
+	client.EXPECT().GetPublicKey("").Return(publicKey, codes.OK, nil).AnyTimes()
+	st := InitStorage(&logger, client, keyringInstance, cfg)
+
+	recordKey, _ := keys.NewRecordKey()
+	wrappedKey, _ := keys.WrapKey(recordKey, publicKey)
+	payload, _ := sealEntry(recordKey, modelstorage.Batch{TextsBinaries: []modelstorage.TextOrBinary{{Identifier: "notes", Entry: "text"}}})
+	sharedEntries := []modelstorage.SharedEntry{{ID: 3, Owner: "alice", Permission: modelstorage.PermissionReadWrite, Db: cfg.TextBinaryDB, Payload: payload, WrappedKey: wrappedKey}}
+	client.EXPECT().GetSharedWithMe().Return(sharedEntries, codes.OK, nil)
+	entries, err := st.SharedWithMe()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, &modelstorage.TextOrBinary{Identifier: "notes", Entry: "text"}, entries[0].TextBinary)
+
+	entry := modelstorage.SharedEntry{ID: 3, Permission: modelstorage.PermissionRead, Db: cfg.TextBinaryDB, TextBinary: &modelstorage.TextOrBinary{Identifier: "notes", Entry: "changed"}}
+	err = st.UpdateShared(entry)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	assert.Equal(t, "entry is shared read-only", err.Error())
+
+	// the update is sealed with the record key of the entry
+	entry.Permission = modelstorage.PermissionReadWrite
+	client.EXPECT().GetSharedWithMe().Return(sharedEntries, codes.OK, nil)
+	client.EXPECT().UpdateSharedEntry(entry, gomock.Any()).DoAndReturn(func(_ modelstorage.SharedEntry, sealed string) (codes.Code, error) {
+		payload = sealed
+		return codes.OK, nil
+	})
+	err = st.UpdateShared(entry)
+	assert.Equal(t, nil, err)
+	batch, err := openEntry(recordKey, payload)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []modelstorage.TextOrBinary{{Identifier: "notes", Entry: "changed"}}, batch.TextsBinaries)
+}
+
+func TestStorage_EmergencyAccess(t *testing.T) {
+	cfg := config.NewDefaultConfiguration()
+	cfg.BankCardDB = "bankCard"
+	cfg.LoginPasswordDB = "loginPassword"
+	cfg.TextBinaryDB = "textBinary"
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mocks.NewMockGRPCClient(ctrl)
+	keeper := mocks.NewMockKeeper(ctrl)
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	st := InitStorage(&logger, client, nil, cfg)
+	st.loginPasswordDB["db"] = modelstorage.LoginAndPassword{Identifier: "db", Login: "user", Password: "secret"}
+
+	err := st.SetTrustedContact(" ", 7)
+	assert.Equal(t, "login cannot be empty", err.Error())
+	err = st.SetTrustedContact("bob", -1)
+	assert.Equal(t, "waiting period cannot be negative", err.Error())
+
+	// local entries are sealed for the new contact right away
+	bobPublicKey, bobPrivateKey, err := keys.NewKeyPair()
+	assert.Equal(t, nil, err)
+	contacts := modelstorage.EmergencyAccessList{TrustedContacts: []modelstorage.EmergencyAccess{{Owner: "alice", Contact: "bob", Status: modelstorage.EmergencyDesignated}}}
+	client.EXPECT().SetTrustedContact("bob", 0).Return(codes.OK, nil)
+	client.EXPECT().ListEmergencyAccess().Return(contacts, codes.OK, nil).Times(2)
+	client.EXPECT().GetPublicKey("bob").Return(bobPublicKey, codes.OK, nil).Times(2)
+	client.EXPECT().GetEmergencyEntries().Return(nil, codes.OK, nil)
+	var sealed []modelstorage.EmergencyEntry
+	client.EXPECT().SetEmergencyEntries(gomock.Any()).DoAndReturn(func(entries []modelstorage.EmergencyEntry) (codes.Code, error) {
+		sealed = append(sealed, entries...)
+		return codes.OK, nil
+	})
+	err = st.SetTrustedContact("bob", 0)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, len(sealed))
+	assert.Equal(t, "db", sealed[0].Identifier)
+	assert.Equal(t, false, strings.Contains(sealed[0].Payload, "secret"))
+
+	// entries sealed for the same contacts since their last change are left as they are
+	sealedAt := time.Now()
+	client.EXPECT().GetEmergencyEntries().Return([]modelstorage.EmergencyEntry{
+		{Identifier: "db", Db: "loginPassword", Contacts: []string{"bob"}, SealedAt: &sealedAt},
+	}, codes.OK, nil)
+	assert.Equal(t, nil, st.sealEmergencyEntries())
+
+	// the contact opens each entry with the key pair of the contact
+	contact := InitStorage(&logger, client, keeper, cfg)
+	client.EXPECT().GetEmergencyVault("alice").Return([]modelstorage.EmergencyEntry{
+		{Identifier: "db", Db: "loginPassword", Payload: sealed[0].Payload, WrappedKey: sealed[0].WrappedKeys["bob"]},
+	}, codes.OK, nil)
+	client.EXPECT().GetPublicKey("").Return(bobPublicKey, codes.OK, nil)
+	keeper.EXPECT().PrivateKey(bobPublicKey).Return(bobPrivateKey, nil)
+	vault, err := contact.EmergencyVault("alice")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, modelstorage.EmergencyVault{
+		Owner:           "alice",
+		LoginsPasswords: []modelstorage.LoginAndPassword{{Identifier: "db", Login: "user", Password: "secret"}},
+	}, vault)
+
+	client.EXPECT().RequestEmergencyAccess("alice").Return(codes.AlreadyExists, status.Error(codes.AlreadyExists, "emergency access to the vault of alice is already requested"))
+	err = st.RequestEmergencyAccess("alice")
+	assert.Equal(t, "emergency access to the vault of alice is already requested", err.Error())
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+
+	client.EXPECT().GetEmergencyVault("alice").Return(nil, codes.Unavailable, status.Error(codes.Unavailable, "connection refused"))
+	_, err = st.EmergencyVault("alice")
+	assert.Equal(t, "server is unavailable, try again later", err.Error())
+}
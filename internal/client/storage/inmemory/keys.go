@@ -0,0 +1,153 @@
+package inmemory
+
+import (
+	"dk-go-gophkeeper/internal/client/storage/modelstorage"
+	"dk-go-gophkeeper/internal/keys"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+)
+
+// keyPair returns the key pair the client keeps for the user. A new pair is created and its public key is published
+// unless the user has published one already, in which case its private key must be kept by this client.
+func (s *Storage) keyPair() (string, string, error) {
+	publicKey, code, err := s.clientGRPC.GetPublicKey("")
+	switch {
+	case code == codes.NotFound:
+		publicKey, err = s.keyring.NewKeyPair()
+		if err != nil {
+			return "", "", err
+		}
+		s.logger.Info().Msg("Publishing public key of a new key pair")
+		code, err = s.clientGRPC.SetPublicKey(publicKey)
+		if err != nil {
+			return "", "", shareError(code, err)
+		}
+	case err != nil:
+		return "", "", shareError(code, err)
+	}
+	privateKey, err := s.keyring.PrivateKey(publicKey)
+	if err != nil {
+		return "", "", err
+	}
+	return publicKey, privateKey, nil
+}
+
+// publishKey makes sure the user has a published key pair, so that other users can share entries with the user. Users
+// can still work with their own entries if it fails.
+func (s *Storage) publishKey() {
+	if _, _, err := s.keyPair(); err != nil {
+		s.logger.Warn().Err(err).Msg("Entries shared with the user cannot be opened by this client")
+	}
+}
+
+// localEntry returns a locally stored entry as a single-entry batch.
+func (s *Storage) localEntry(identifier, db string) (modelstorage.Batch, bool) {
+	var batch modelstorage.Batch
+	switch db {
+	case s.cfg.BankCardDB:
+		if value, ok := s.bankCardDB[identifier]; ok {
+			batch.BankCards = append(batch.BankCards, value)
+		}
+	case s.cfg.LoginPasswordDB:
+		if value, ok := s.loginPasswordDB[identifier]; ok {
+			batch.LoginsPasswords = append(batch.LoginsPasswords, value)
+		}
+	case s.cfg.TextBinaryDB:
+		if value, ok := s.textBinaryDB[identifier]; ok {
+			batch.TextsBinaries = append(batch.TextsBinaries, value)
+		}
+	}
+	return batch, len(batch.BankCards)+len(batch.LoginsPasswords)+len(batch.TextsBinaries) > 0
+}
+
+// sealEntry encrypts a single-entry batch with a record key.
+func sealEntry(recordKey string, batch modelstorage.Batch) (string, error) {
+	payload, err := json.Marshal(batch)
+	if err != nil {
+		return "", err
+	}
+	return keys.SealRecord(recordKey, string(payload))
+}
+
+// openEntry decrypts a single-entry batch with a record key.
+func openEntry(recordKey, sealed string) (modelstorage.Batch, error) {
+	var batch modelstorage.Batch
+	payload, err := keys.OpenRecord(recordKey, sealed)
+	if err != nil {
+		return batch, err
+	}
+	err = json.Unmarshal([]byte(payload), &batch)
+	return batch, err
+}
+
+// sealShare seals a local entry with a record key wrapped for the user and the given recipients by their logins.
+func sealShare(recordKey, ownerPublicKey string, batch modelstorage.Batch, recipients map[string]string) (modelstorage.SealedEntry, error) {
+	var sealed modelstorage.SealedEntry
+	var err error
+	sealed.Payload, err = sealEntry(recordKey, batch)
+	if err != nil {
+		return sealed, err
+	}
+	sealed.OwnerKey, err = keys.WrapKey(recordKey, ownerPublicKey)
+	if err != nil {
+		return sealed, err
+	}
+	sealed.WrappedKeys = make(map[string]string, len(recipients))
+	for login, publicKey := range recipients {
+		if publicKey == "" {
+			return sealed, fmt.Errorf("user %s has not published a public key yet", login)
+		}
+		sealed.WrappedKeys[login], err = keys.WrapKey(recordKey, publicKey)
+		if err != nil {
+			return sealed, err
+		}
+	}
+	return sealed, nil
+}
+
+// share returns a share of a local entry if the entry is shared.
+func (s *Storage) share(identifier, db string) (modelstorage.Share, bool, error) {
+	shares, code, err := s.clientGRPC.GetShares()
+	if err != nil {
+		return modelstorage.Share{}, false, shareError(code, err)
+	}
+	for _, share := range shares {
+		if share.Identifier == identifier && share.Db == db {
+			return share, true, nil
+		}
+	}
+	return modelstorage.Share{}, false, nil
+}
+
+// resealShare seals an updated local entry with its record key if the entry is shared, so that everyone it is shared
+// with gets the update. Entries to be shared again have no record key the client could unwrap and are left as they are.
+func (s *Storage) resealShare(identifier, db string) error {
+	share, ok, err := s.share(identifier, db)
+	if err != nil || !ok || share.ReshareRequired {
+		return err
+	}
+	publicKey, privateKey, err := s.keyPair()
+	if err != nil {
+		return err
+	}
+	recordKey, err := keys.UnwrapKey(share.OwnerKey, publicKey, privateKey)
+	if err != nil {
+		return err
+	}
+	batch, ok := s.localEntry(identifier, db)
+	if !ok {
+		return errors.New("shared entry is not stored locally")
+	}
+	payload, err := sealEntry(recordKey, batch)
+	if err != nil {
+		return err
+	}
+	code, err := s.clientGRPC.SetSharePayload(identifier, db, payload)
+	if err != nil {
+		return shareError(code, err)
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+package inmemory
+
+import (
+	"dk-go-gophkeeper/internal/client/storage/modelstorage"
+)
+
+// Sessions retrieves open sessions of the user from the server.
+func (s *Storage) Sessions() ([]modelstorage.Session, error) {
+	sessions, code, err := s.clientGRPC.ListSessions()
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Could not retrieve sessions")
+		return nil, requestError(code, err, nil)
+	}
+	return sessions, nil
+}
+
+// RevokeSession revokes a session of the user on the server, requests made with it are rejected afterwards.
+func (s *Storage) RevokeSession(sessionID int64) error {
+	code, err := s.clientGRPC.RevokeSession(sessionID)
+	if err != nil {
+		s.logger.Error().Err(err).Msgf("Could not revoke session %d", sessionID)
+		return requestError(code, err, sessionMessages)
+	}
+	return nil
+}
@@ -0,0 +1,272 @@
+package inmemory
+
+import (
+	"dk-go-gophkeeper/internal/client/storage/modelstorage"
+	"dk-go-gophkeeper/internal/keys"
+	"dk-go-gophkeeper/internal/validation"
+	"errors"
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// shareError describes a failed sharing request, the server describes rejected shares for users itself.
+func shareError(code codes.Code, err error) error {
+	switch code {
+	case codes.InvalidArgument, codes.NotFound, codes.AlreadyExists, codes.FailedPrecondition:
+		if st, ok := status.FromError(err); ok {
+			return &RequestError{Code: code, Message: st.Message()}
+		}
+		// field violations of an update are returned as they are
+		return err
+	}
+	return requestError(code, err, shareMessages)
+}
+
+// checkShare validates a share of a local entry with a recipient.
+func (s *Storage) checkShare(identifier, db, recipient string) error {
+	if identifier == "" {
+		return fmt.Errorf("identifier cannot be empty in db %s", db)
+	}
+	if db != s.cfg.BankCardDB && db != s.cfg.LoginPasswordDB && db != s.cfg.TextBinaryDB {
+		return fmt.Errorf("invalid db %s", db)
+	}
+	if !s.Exists(identifier, db) {
+		return fmt.Errorf("entry ID %s in %s storage does not exist", identifier, db)
+	}
+	if recipient == "" {
+		return errors.New("recipient cannot be empty")
+	}
+	return nil
+}
+
+// Share shares a local entry with another user, the entry stays in sync with the recipient until the share is revoked.
+// The entry is sealed with its record key, which is wrapped for the user and the recipient by the client only.
+func (s *Storage) Share(identifier, db, recipient, permission string) error {
+	if err := s.checkShare(identifier, db, recipient); err != nil {
+		return err
+	}
+	if permission != modelstorage.PermissionRead && permission != modelstorage.PermissionReadWrite {
+		return fmt.Errorf("invalid permission %s, expected %s or %s", permission, modelstorage.PermissionRead, modelstorage.PermissionReadWrite)
+	}
+	s.logger.Info().Msgf("Sharing entry %s in %s storage with %s", identifier, db, recipient)
+	publicKey, privateKey, err := s.keyPair()
+	if err != nil {
+		return err
+	}
+	recipientKey, code, err := s.clientGRPC.GetPublicKey(recipient)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Could not retrieve public key of recipient")
+		return shareError(code, err)
+	}
+	// recipients the entry is already shared with keep their record key
+	share, ok, err := s.share(identifier, db)
+	if err != nil {
+		return err
+	}
+	recipients := map[string]string{recipient: recipientKey}
+	var recordKey string
+	if ok && !share.ReshareRequired {
+		recordKey, err = keys.UnwrapKey(share.OwnerKey, publicKey, privateKey)
+	} else {
+		recordKey, err = keys.NewRecordKey()
+	}
+	if err != nil {
+		return err
+	}
+	// an entry shared before clients kept key pairs gets a new record key for recipients having published public keys
+	// of their clients, the server drops the others
+	if ok && share.ReshareRequired {
+		for _, shareRecipient := range share.Recipients {
+			if shareRecipient.PublicKey != "" && shareRecipient.Login != recipient {
+				recipients[shareRecipient.Login] = shareRecipient.PublicKey
+			}
+		}
+	}
+	batch, _ := s.localEntry(identifier, db)
+	sealed, err := sealShare(recordKey, publicKey, batch, recipients)
+	if err != nil {
+		return err
+	}
+	code, err = s.clientGRPC.ShareEntry(identifier, db, recipient, permission, sealed)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Could not share entry")
+		return shareError(code, err)
+	}
+	return nil
+}
+
+// Unshare revokes access of another user to a local entry. The entry is sealed with a new record key wrapped for
+// remaining recipients, so the key the user might have kept opens no later revision of the entry.
+func (s *Storage) Unshare(identifier, db, recipient string) error {
+	if err := s.checkShare(identifier, db, recipient); err != nil {
+		return err
+	}
+	s.logger.Info().Msgf("Revoking share of entry %s in %s storage with %s", identifier, db, recipient)
+	share, _, err := s.share(identifier, db)
+	if err != nil {
+		return err
+	}
+	remaining := make(map[string]string, len(share.Recipients))
+	for _, shareRecipient := range share.Recipients {
+		if shareRecipient.Login != recipient {
+			remaining[shareRecipient.Login] = shareRecipient.PublicKey
+		}
+	}
+	// the server describes entries not shared with the recipient, an entry without recipients or to be shared again is
+	// not sealed anymore
+	var sealed modelstorage.SealedEntry
+	if len(remaining) > 0 && len(remaining) < len(share.Recipients) && !share.ReshareRequired {
+		publicKey, _, err := s.keyPair()
+		if err != nil {
+			return err
+		}
+		recordKey, err := keys.NewRecordKey()
+		if err != nil {
+			return err
+		}
+		batch, _ := s.localEntry(identifier, db)
+		sealed, err = sealShare(recordKey, publicKey, batch, remaining)
+		if err != nil {
+			return err
+		}
+	}
+	code, err := s.clientGRPC.RevokeShare(identifier, db, recipient, sealed)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Could not revoke share")
+		return shareError(code, err)
+	}
+	return nil
+}
+
+// Shares retrieves local entries shared with other users from the server.
+func (s *Storage) Shares() ([]modelstorage.Share, error) {
+	shares, code, err := s.clientGRPC.GetShares()
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Could not retrieve shares")
+		return nil, shareError(code, err)
+	}
+	return shares, nil
+}
+
+// sharedWithMe retrieves sealed entries other users share with the user from the server.
+func (s *Storage) sharedWithMe() ([]modelstorage.SharedEntry, error) {
+	entries, code, err := s.clientGRPC.GetSharedWithMe()
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Could not retrieve shared entries")
+		return nil, shareError(code, err)
+	}
+	return entries, nil
+}
+
+// openShared opens an entry shared with the user with the record key wrapped for the user and returns the record key.
+func openShared(entry *modelstorage.SharedEntry, publicKey, privateKey string) (string, error) {
+	recordKey, err := keys.UnwrapKey(entry.WrappedKey, publicKey, privateKey)
+	if err != nil {
+		return "", err
+	}
+	batch, err := openEntry(recordKey, entry.Payload)
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case len(batch.BankCards) > 0:
+		entry.BankCard = &batch.BankCards[0]
+	case len(batch.LoginsPasswords) > 0:
+		entry.LoginPassword = &batch.LoginsPasswords[0]
+	case len(batch.TextsBinaries) > 0:
+		entry.TextBinary = &batch.TextsBinaries[0]
+	default:
+		return "", errors.New("shared entry is empty")
+	}
+	return recordKey, nil
+}
+
+// SharedWithMe retrieves entries other users share with the user from the server and opens them, they are not kept
+// locally.
+func (s *Storage) SharedWithMe() ([]modelstorage.SharedEntry, error) {
+	entries, err := s.sharedWithMe()
+	if err != nil || len(entries) == 0 {
+		return entries, err
+	}
+	publicKey, privateKey, err := s.keyPair()
+	if err != nil {
+		return nil, err
+	}
+	for idx := range entries {
+		if _, err = openShared(&entries[idx], publicKey, privateKey); err != nil {
+			s.logger.Error().Err(err).Msgf("Could not open shared entry %d", entries[idx].ID)
+			return nil, fmt.Errorf("could not open entry shared by %s: %w", entries[idx].Owner, err)
+		}
+	}
+	return entries, nil
+}
+
+// UpdateShared sends an update of an entry shared with the user, the update is stored as the entry of its owner and
+// sealed with the record key of the entry for everyone it is shared with.
+func (s *Storage) UpdateShared(entry modelstorage.SharedEntry) error {
+	if entry.Permission != modelstorage.PermissionReadWrite {
+		return &RequestError{Code: codes.PermissionDenied, Message: shareMessages[codes.PermissionDenied]}
+	}
+	var err error
+	switch {
+	case entry.BankCard != nil:
+		card := entry.BankCard
+		err = validation.ValidateBankCard(card.Number, card.Cvv, card.Expiry, card.Pin)
+		card.Number = validation.NormalizeCardNumber(card.Number)
+		card.Expiry = validation.NormalizeExpiry(card.Expiry)
+	case entry.LoginPassword == nil && entry.TextBinary == nil:
+		err = errors.New("shared entry cannot be empty")
+	}
+	if err != nil {
+		return err
+	}
+	s.logger.Info().Msgf("Updating shared entry %d", entry.ID)
+	// the record key is unwrapped from the entry as the server holds it
+	entries, err := s.sharedWithMe()
+	if err != nil {
+		return err
+	}
+	var recordKey string
+	var batch modelstorage.Batch
+	for _, sharedEntry := range entries {
+		if sharedEntry.ID != entry.ID {
+			continue
+		}
+		publicKey, privateKey, err := s.keyPair()
+		if err != nil {
+			return err
+		}
+		if recordKey, err = openShared(&sharedEntry, publicKey, privateKey); err != nil {
+			return err
+		}
+		// recipients cannot rename shared entries, the server keeps the identifier of the owner as well
+		switch {
+		case entry.BankCard != nil && sharedEntry.BankCard != nil:
+			entry.BankCard.Identifier = sharedEntry.BankCard.Identifier
+			batch.BankCards = append(batch.BankCards, *entry.BankCard)
+		case entry.LoginPassword != nil && sharedEntry.LoginPassword != nil:
+			entry.LoginPassword.Identifier = sharedEntry.LoginPassword.Identifier
+			batch.LoginsPasswords = append(batch.LoginsPasswords, *entry.LoginPassword)
+		case entry.TextBinary != nil && sharedEntry.TextBinary != nil:
+			entry.TextBinary.Identifier = sharedEntry.TextBinary.Identifier
+			batch.TextsBinaries = append(batch.TextsBinaries, *entry.TextBinary)
+		default:
+			return fmt.Errorf("shared entry is not of db %s", entry.Db)
+		}
+	}
+	if recordKey == "" {
+		return &RequestError{Code: codes.NotFound, Message: "shared entry not found"}
+	}
+	payload, err := sealEntry(recordKey, batch)
+	if err != nil {
+		return err
+	}
+	code, err := s.clientGRPC.UpdateSharedEntry(entry, payload)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Could not update shared entry")
+		return shareError(code, err)
+	}
+	return nil
+}
@@ -1,9 +1,11 @@
 // Package storage provides local client data storing functionality.
 package storage
 
+import "dk-go-gophkeeper/internal/client/storage/modelstorage"
+
 // BankCardAdder defines a set of methods for types implementing BankCardAdder.
 type BankCardAdder interface {
-	AddBankCard(identifier, number, holder, cvv, meta string) error
+	AddBankCard(identifier, number, holder, cvv, expiry, pin, meta string) error
 }
 
 // LoginPasswordAdder defines a set of methods for types implementing LoginPasswordAdder.
@@ -16,6 +18,81 @@ type TextBinaryAdder interface {
 	AddTextBinary(identifier, entry, meta string) error
 }
 
+// BatchAdder defines a set of methods for types implementing BatchAdder.
+type BatchAdder interface {
+	AddBatch(batch modelstorage.Batch) []modelstorage.BatchItemResult
+}
+
+// Labeler defines a set of methods for types implementing Labeler.
+type Labeler interface {
+	SetLabels(identifier, db string, labels modelstorage.Labels) error
+}
+
+// FieldEditor defines a set of methods for types implementing FieldEditor.
+type FieldEditor interface {
+	SetFields(identifier, db string, fields []modelstorage.CustomField) error
+}
+
+// Checker defines a set of methods for types implementing Checker.
+type Checker interface {
+	Exists(identifier, db string) bool
+}
+
+// Exporter defines a set of methods for types implementing Exporter.
+type Exporter interface {
+	Export() modelstorage.Batch
+}
+
+// Searcher defines a set of methods for types implementing Searcher.
+type Searcher interface {
+	Search(query string, order modelstorage.Order) []modelstorage.Summary
+}
+
+// Sharer defines a set of methods for types implementing Sharer.
+type Sharer interface {
+	Share(identifier, db, recipient, permission string) error
+	Unshare(identifier, db, recipient string) error
+	Shares() ([]modelstorage.Share, error)
+	SharedWithMe() ([]modelstorage.SharedEntry, error)
+	UpdateShared(entry modelstorage.SharedEntry) error
+}
+
+// Collector defines a set of methods for types implementing Collector.
+type Collector interface {
+	CreateCollection(name string) (modelstorage.Collection, error)
+	Collections() ([]modelstorage.Collection, error)
+	DeleteCollection(collectionID string) error
+	Invite(collectionID, login, role string) error
+	Invitations() ([]modelstorage.CollectionInvitation, error)
+	RespondInvitation(invitationID int64, accept bool) error
+	Members(collectionID string) ([]modelstorage.CollectionMember, error)
+	SetMemberRole(collectionID, login, role string) error
+	RemoveMember(collectionID, login string) error
+	CollectionEvents(collectionID string) ([]modelstorage.CollectionEvent, error)
+}
+
+// ActivityReader defines a set of methods for types implementing ActivityReader.
+type ActivityReader interface {
+	Activity(beforeID int64, limit int) ([]modelstorage.AuditEvent, error)
+}
+
+// DeviceManager defines a set of methods for types implementing DeviceManager.
+type DeviceManager interface {
+	Sessions() ([]modelstorage.Session, error)
+	RevokeSession(sessionID int64) error
+}
+
+// EmergencyAccessor defines a set of methods for types implementing EmergencyAccessor.
+type EmergencyAccessor interface {
+	SetTrustedContact(login string, waitDays int) error
+	RemoveTrustedContact(login string) error
+	EmergencyAccess() (modelstorage.EmergencyAccessList, error)
+	RequestEmergencyAccess(owner string) error
+	ApproveEmergencyAccess(contact string) error
+	RejectEmergencyAccess(contact string) error
+	EmergencyVault(owner string) (modelstorage.EmergencyVault, error)
+}
+
 // Syncer defines a set of methods for types implementing Syncer.
 type Syncer interface {
 	Sync() error
@@ -47,6 +124,17 @@ type DataStorage interface {
 	BankCardAdder
 	LoginPasswordAdder
 	TextBinaryAdder
+	BatchAdder
+	Labeler
+	FieldEditor
+	Checker
+	Exporter
+	Searcher
+	Sharer
+	Collector
+	ActivityReader
+	DeviceManager
+	EmergencyAccessor
 	Getter
 	Syncer
 	Remover
@@ -1,27 +1,238 @@
 // Package modelstorage provides models for local client data storage.
 package modelstorage
 
+import "time"
+
 type (
+	Labels struct {
+		Folder   string   `json:"folder,omitempty"`
+		Tags     []string `json:"tags,omitempty"`
+		Favorite bool     `json:"favorite,omitempty"`
+	}
+	CustomField struct {
+		Name      string `json:"name"`
+		Value     string `json:"value"`
+		Concealed bool   `json:"concealed,omitempty"`
+	}
+	// Revision holds server timestamps of an entry, they are unknown until the entry is retrieved from the server.
+	Revision struct {
+		CreatedAt *time.Time `json:"created_at,omitempty"`
+		UpdatedAt *time.Time `json:"updated_at,omitempty"`
+	}
 	LoginAndPassword struct {
-		Identifier string
-		Login      string
-		Password   string
-		Meta       string
+		Identifier string        `json:"identifier"`
+		Login      string        `json:"login"`
+		Password   string        `json:"password"`
+		Meta       string        `json:"meta"`
+		Fields     []CustomField `json:"fields,omitempty"`
+		Labels
+		Revision
+		PasswordChangedAt *time.Time `json:"password_changed_at,omitempty"`
 	}
 	TextOrBinary struct {
-		Identifier string
-		Entry      string
-		Meta       string
+		Identifier string        `json:"identifier"`
+		Entry      string        `json:"entry"`
+		Meta       string        `json:"meta"`
+		Fields     []CustomField `json:"fields,omitempty"`
+		Labels
+		Revision
 	}
 	BankCard struct {
-		Identifier string
-		Number     string
-		Holder     string
-		Cvv        string
-		Meta       string
+		Identifier string        `json:"identifier"`
+		Number     string        `json:"number"`
+		Holder     string        `json:"holder"`
+		Cvv        string        `json:"cvv"`
+		Expiry     string        `json:"expiry,omitempty"`
+		Pin        string        `json:"pin,omitempty"`
+		Meta       string        `json:"meta"`
+		Fields     []CustomField `json:"fields,omitempty"`
+		Labels
+		Revision
 	}
 	RegisterLogin struct {
 		Login    string
 		Password string
 	}
+	Batch struct {
+		BankCards       []BankCard         `json:"bank_cards"`
+		LoginsPasswords []LoginAndPassword `json:"logins_passwords"`
+		TextsBinaries   []TextOrBinary     `json:"texts_binaries"`
+	}
+	BatchItemResult struct {
+		Identifier string
+		Db         string
+		Err        error
+	}
+	ShareRecipient struct {
+		Login      string `json:"login"`
+		Permission string `json:"permission"`
+		PublicKey  string `json:"-"`
+	}
+	// Share holds an entry of the user shared with other users, OwnerKey is its record key wrapped for the user.
+	// ReshareRequired is set for entries shared before clients kept key pairs, nobody can open them until they are
+	// shared again.
+	Share struct {
+		Identifier      string           `json:"identifier"`
+		Db              string           `json:"db"`
+		Recipients      []ShareRecipient `json:"recipients"`
+		UpdatedAt       *time.Time       `json:"updated_at,omitempty"`
+		ReshareRequired bool             `json:"reshare_required,omitempty"`
+		OwnerKey        string           `json:"-"`
+	}
+	// SharedEntry holds an entry other user shares with the user, only the entry of its Db is set once the entry is
+	// opened with its record key wrapped for the user.
+	SharedEntry struct {
+		ID            int64             `json:"id"`
+		Owner         string            `json:"owner"`
+		Permission    string            `json:"permission"`
+		Db            string            `json:"db"`
+		BankCard      *BankCard         `json:"bank_card,omitempty"`
+		LoginPassword *LoginAndPassword `json:"login_password,omitempty"`
+		TextBinary    *TextOrBinary     `json:"text_binary,omitempty"`
+		UpdatedAt     *time.Time        `json:"updated_at,omitempty"`
+		Payload       string            `json:"-"`
+		WrappedKey    string            `json:"-"`
+	}
+	// SealedEntry holds an entry sealed with its record key along with the record key wrapped for its owner and, by
+	// their logins, for users the entry is shared with.
+	SealedEntry struct {
+		Payload     string
+		OwnerKey    string
+		WrappedKeys map[string]string
+	}
+	// Collection holds a team collection the user is a member of.
+	Collection struct {
+		ID        string     `json:"id"`
+		Name      string     `json:"name"`
+		Role      string     `json:"role"`
+		CreatedAt *time.Time `json:"created_at,omitempty"`
+	}
+	// CollectionInvitation holds a pending invitation of the user to a collection.
+	CollectionInvitation struct {
+		ID             int64      `json:"id"`
+		CollectionID   string     `json:"collection_id"`
+		CollectionName string     `json:"collection_name"`
+		Inviter        string     `json:"inviter"`
+		Role           string     `json:"role"`
+		CreatedAt      *time.Time `json:"created_at,omitempty"`
+	}
+	CollectionMember struct {
+		Login    string     `json:"login"`
+		Role     string     `json:"role"`
+		JoinedAt *time.Time `json:"joined_at,omitempty"`
+	}
+	// CollectionEvent holds a membership change of a collection, Target is empty for changes of the collection itself.
+	CollectionEvent struct {
+		Actor     string     `json:"actor"`
+		Target    string     `json:"target,omitempty"`
+		Action    string     `json:"action"`
+		Role      string     `json:"role,omitempty"`
+		CreatedAt *time.Time `json:"created_at,omitempty"`
+	}
+	// AuditEvent holds a request of the user recorded by the server, Code is the name of the status code it ended with.
+	AuditEvent struct {
+		ID        int64      `json:"id"`
+		Action    string     `json:"action"`
+		Method    string     `json:"method"`
+		Peer      string     `json:"peer"`
+		Code      string     `json:"code"`
+		CreatedAt *time.Time `json:"created_at,omitempty"`
+	}
+	// Session holds a login of the user from a device, Current marks the session the request was made with.
+	Session struct {
+		ID            int64      `json:"id"`
+		DeviceName    string     `json:"device_name"`
+		ClientVersion string     `json:"client_version"`
+		Peer          string     `json:"peer"`
+		CreatedAt     *time.Time `json:"created_at,omitempty"`
+		LastSeenAt    *time.Time `json:"last_seen_at,omitempty"`
+		Current       bool       `json:"current"`
+	}
+	// EmergencyAccess holds a trusted contact who may request access to the vault of its owner, GrantsAt is set while a
+	// request waits to be granted.
+	EmergencyAccess struct {
+		Owner       string     `json:"owner"`
+		Contact     string     `json:"contact"`
+		WaitDays    int        `json:"wait_days"`
+		Status      string     `json:"status"`
+		RequestedAt *time.Time `json:"requested_at,omitempty"`
+		GrantsAt    *time.Time `json:"grants_at,omitempty"`
+		GrantedAt   *time.Time `json:"granted_at,omitempty"`
+	}
+	// EmergencyAccessList holds trusted contacts of the user along with owners who designated the user as theirs.
+	EmergencyAccessList struct {
+		TrustedContacts []EmergencyAccess `json:"trusted_contacts"`
+		Grantors        []EmergencyAccess `json:"grantors"`
+	}
+	// EmergencyVault holds entries of an owner as they were last sealed by a client of the owner, each entry is opened
+	// with its own record key wrapped for the user.
+	EmergencyVault struct {
+		Owner           string             `json:"owner"`
+		BankCards       []BankCard         `json:"bank_cards"`
+		LoginsPasswords []LoginAndPassword `json:"logins_passwords"`
+		TextsBinaries   []TextOrBinary     `json:"texts_binaries"`
+	}
+	// EmergencyEntry holds an entry of the user sealed for trusted contacts. WrappedKeys holds its record key wrapped
+	// for the contacts by their logins, entries listed for the user name the contacts in Contacts instead and entries
+	// of an owner hold the record key wrapped for the user in WrappedKey.
+	EmergencyEntry struct {
+		Identifier  string
+		Db          string
+		Payload     string
+		WrappedKeys map[string]string
+		Contacts    []string
+		WrappedKey  string
+		SealedAt    *time.Time
+	}
+	Summary struct {
+		Identifier string `json:"identifier"`
+		Db         string `json:"db"`
+		Meta       string `json:"meta"`
+		Score      int    `json:"score"`
+		Labels
+	}
+)
+
+// share permissions
+const (
+	PermissionRead      = "read"
+	PermissionReadWrite = "read-write"
+)
+
+// collection roles, from the most to the least privileged
+const (
+	RoleOwner    = "owner"
+	RoleAdmin    = "admin"
+	RoleMember   = "member"
+	RoleReadOnly = "read-only"
+)
+
+// emergency access statuses
+const (
+	EmergencyDesignated = "designated"
+	EmergencyRequested  = "requested"
+	EmergencyGranted    = "granted"
+)
+
+// Identifier returns the identifier of the shared entry.
+func (e SharedEntry) Identifier() string {
+	switch {
+	case e.BankCard != nil:
+		return e.BankCard.Identifier
+	case e.LoginPassword != nil:
+		return e.LoginPassword.Identifier
+	case e.TextBinary != nil:
+		return e.TextBinary.Identifier
+	}
+	return ""
+}
+
+// Order defines an ordering of search results.
+type Order string
+
+// supported search result orderings
+const (
+	OrderRelevance  Order = "relevance"
+	OrderIdentifier Order = "identifier"
+	OrderDb         Order = "db"
 )
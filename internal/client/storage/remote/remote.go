@@ -0,0 +1,399 @@
+// Package remote provides client data storing functionality backed by a running local agent.
+package remote
+
+import (
+	"bytes"
+	"context"
+	"dk-go-gophkeeper/internal/client/agent/modelagent"
+	agent "dk-go-gophkeeper/internal/client/agent/v1"
+	"dk-go-gophkeeper/internal/client/storage"
+	"dk-go-gophkeeper/internal/client/storage/modelstorage"
+	"dk-go-gophkeeper/internal/client/storage/search"
+	"dk-go-gophkeeper/internal/config"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// baseURL is a placeholder host of requests sent over the agent socket.
+const baseURL = "http://agent"
+
+// check for interface compliance
+var (
+	_ storage.DataStorage = (*Storage)(nil)
+)
+
+// Storage defines attributes and methods of a Storage instance.
+type Storage struct {
+	client *http.Client
+	token  string
+	logger *zerolog.Logger
+	cfg    *config.Config
+}
+
+// Available reports whether an agent socket exists so that clients can attach to it.
+func Available(cfg *config.Config) bool {
+	socketPath, err := agent.SocketPath(cfg)
+	if err != nil {
+		return false
+	}
+	info, err := os.Stat(socketPath)
+	return err == nil && info.Mode()&os.ModeSocket != 0
+}
+
+// InitStorage initializes a Storage instance attached to the agent socket.
+func InitStorage(logger *zerolog.Logger, cfg *config.Config) (*Storage, error) {
+	logger.Info().Msg("Attempting to attach to agent")
+	socketPath, err := agent.SocketPath(cfg)
+	if err != nil {
+		return nil, err
+	}
+	token, err := agent.ReadToken(socketPath)
+	if err != nil {
+		return nil, err
+	}
+	dialer := net.Dialer{}
+	client := &http.Client{
+		Timeout: time.Minute,
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return dialer.DialContext(ctx, "unix", socketPath)
+			},
+		},
+	}
+	return &Storage{client: client, token: token, logger: logger, cfg: cfg}, nil
+}
+
+// AddBankCard adds a new bank card entry via the agent.
+func (s *Storage) AddBankCard(identifier, number, holder, cvv, expiry, pin, meta string) error {
+	entry := modelagent.Entry{Db: s.cfg.BankCardDB, Identifier: identifier, Number: number, Holder: holder, Cvv: cvv, Expiry: expiry, Pin: pin, Meta: meta}
+	return s.do(http.MethodPost, modelagent.RouteEntry, nil, entry, nil)
+}
+
+// AddLoginPassword adds a new login/password entry via the agent.
+func (s *Storage) AddLoginPassword(identifier, login, password, meta string) error {
+	entry := modelagent.Entry{Db: s.cfg.LoginPasswordDB, Identifier: identifier, Login: login, Password: password, Meta: meta}
+	return s.do(http.MethodPost, modelagent.RouteEntry, nil, entry, nil)
+}
+
+// AddTextBinary adds a new text/binary entry via the agent.
+func (s *Storage) AddTextBinary(identifier, entry, meta string) error {
+	value := modelagent.Entry{Db: s.cfg.TextBinaryDB, Identifier: identifier, Entry: entry, Meta: meta}
+	return s.do(http.MethodPost, modelagent.RouteEntry, nil, value, nil)
+}
+
+// AddBatch adds a batch of entries via the agent, a request failure is reported for every entry.
+func (s *Storage) AddBatch(batch modelstorage.Batch) []modelstorage.BatchItemResult {
+	var response []modelagent.BatchItemResult
+	err := s.do(http.MethodPost, modelagent.RouteBatch, nil, batch, &response)
+	var results []modelstorage.BatchItemResult
+	if err != nil {
+		for _, value := range batch.BankCards {
+			results = append(results, modelstorage.BatchItemResult{Identifier: value.Identifier, Db: s.cfg.BankCardDB, Err: err})
+		}
+		for _, value := range batch.LoginsPasswords {
+			results = append(results, modelstorage.BatchItemResult{Identifier: value.Identifier, Db: s.cfg.LoginPasswordDB, Err: err})
+		}
+		for _, value := range batch.TextsBinaries {
+			results = append(results, modelstorage.BatchItemResult{Identifier: value.Identifier, Db: s.cfg.TextBinaryDB, Err: err})
+		}
+		return results
+	}
+	for _, item := range response {
+		result := modelstorage.BatchItemResult{Identifier: item.Identifier, Db: item.Db}
+		if item.Error != "" {
+			result.Err = errors.New(item.Error)
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// SetLabels replaces labels of an entry via the agent.
+func (s *Storage) SetLabels(identifier, db string, labels modelstorage.Labels) error {
+	request := modelagent.LabelsRequest{Db: db, Identifier: identifier, Labels: labels}
+	return s.do(http.MethodPost, modelagent.RouteLabels, nil, request, nil)
+}
+
+// SetFields replaces custom fields of an entry via the agent.
+func (s *Storage) SetFields(identifier, db string, fields []modelstorage.CustomField) error {
+	request := modelagent.FieldsRequest{Db: db, Identifier: identifier, Fields: fields}
+	return s.do(http.MethodPost, modelagent.RouteFields, nil, request, nil)
+}
+
+// Exists checks whether an entry is present in the agent vault.
+func (s *Storage) Exists(identifier, db string) bool {
+	var response modelagent.EntryResponse
+	query := url.Values{"identifier": {identifier}, "db": {db}, "exists": {"true"}}
+	if err := s.do(http.MethodGet, modelagent.RouteEntry, query, nil, &response); err != nil {
+		return false
+	}
+	return response.Exists
+}
+
+// Export returns all entries of the agent vault.
+func (s *Storage) Export() modelstorage.Batch {
+	var batch modelstorage.Batch
+	if err := s.do(http.MethodGet, modelagent.RouteEntries, nil, nil, &batch); err != nil {
+		s.logger.Error().Err(err).Msg("Could not export entries from agent")
+	}
+	return batch
+}
+
+// Search returns summaries of agent vault entries fuzzy matching the query by identifier or meta.
+func (s *Storage) Search(query string, order modelstorage.Order) []modelstorage.Summary {
+	return search.Filter(s.Export(), s.cfg.BankCardDB, s.cfg.LoginPasswordDB, s.cfg.TextBinaryDB, query, order)
+}
+
+// Get retrieves a data piece from the agent vault.
+func (s *Storage) Get(identifier, db string) (string, error) {
+	var response modelagent.EntryResponse
+	query := url.Values{"identifier": {identifier}, "db": {db}}
+	if err := s.do(http.MethodGet, modelagent.RouteEntry, query, nil, &response); err != nil {
+		return "", err
+	}
+	return response.Data, nil
+}
+
+// Sync makes the agent retrieve all data from the server.
+func (s *Storage) Sync() error {
+	return s.do(http.MethodPost, modelagent.RouteSync, nil, nil, nil)
+}
+
+// Remove deletes an entry via the agent.
+func (s *Storage) Remove(identifier, db string) error {
+	query := url.Values{"identifier": {identifier}, "db": {db}}
+	return s.do(http.MethodDelete, modelagent.RouteEntry, query, nil, nil)
+}
+
+// Share shares an entry with another user via the agent.
+func (s *Storage) Share(identifier, db, recipient, permission string) error {
+	request := modelagent.ShareRequest{Db: db, Identifier: identifier, Recipient: recipient, Permission: permission}
+	return s.do(http.MethodPost, modelagent.RouteShare, nil, request, nil)
+}
+
+// Unshare revokes access of another user to an entry via the agent.
+func (s *Storage) Unshare(identifier, db, recipient string) error {
+	query := url.Values{"identifier": {identifier}, "db": {db}, "recipient": {recipient}}
+	return s.do(http.MethodDelete, modelagent.RouteShare, query, nil, nil)
+}
+
+// Shares retrieves entries shared with other users via the agent.
+func (s *Storage) Shares() ([]modelstorage.Share, error) {
+	var shares []modelstorage.Share
+	err := s.do(http.MethodGet, modelagent.RouteShares, nil, nil, &shares)
+	return shares, err
+}
+
+// SharedWithMe retrieves entries shared with the user via the agent.
+func (s *Storage) SharedWithMe() ([]modelstorage.SharedEntry, error) {
+	var entries []modelstorage.SharedEntry
+	err := s.do(http.MethodGet, modelagent.RouteShared, nil, nil, &entries)
+	return entries, err
+}
+
+// UpdateShared updates an entry shared with the user via the agent.
+func (s *Storage) UpdateShared(entry modelstorage.SharedEntry) error {
+	return s.do(http.MethodPost, modelagent.RouteShared, nil, entry, nil)
+}
+
+// CreateCollection creates a collection owned by the user via the agent.
+func (s *Storage) CreateCollection(name string) (modelstorage.Collection, error) {
+	var collection modelstorage.Collection
+	err := s.do(http.MethodPost, modelagent.RouteCollections, nil, modelagent.CollectionRequest{Name: name}, &collection)
+	return collection, err
+}
+
+// Collections retrieves collections the user is a member of via the agent.
+func (s *Storage) Collections() ([]modelstorage.Collection, error) {
+	var collections []modelstorage.Collection
+	err := s.do(http.MethodGet, modelagent.RouteCollections, nil, nil, &collections)
+	return collections, err
+}
+
+// DeleteCollection deletes a collection with all its entries via the agent.
+func (s *Storage) DeleteCollection(collectionID string) error {
+	return s.do(http.MethodDelete, modelagent.RouteCollections, url.Values{"collection": {collectionID}}, nil, nil)
+}
+
+// Invite invites another user to a collection via the agent.
+func (s *Storage) Invite(collectionID, login, role string) error {
+	request := modelagent.MemberRequest{CollectionID: collectionID, Login: login, Role: role}
+	return s.do(http.MethodPost, modelagent.RouteInvitations, nil, request, nil)
+}
+
+// Invitations retrieves pending invitations of the user via the agent.
+func (s *Storage) Invitations() ([]modelstorage.CollectionInvitation, error) {
+	var invitations []modelstorage.CollectionInvitation
+	err := s.do(http.MethodGet, modelagent.RouteInvitations, nil, nil, &invitations)
+	return invitations, err
+}
+
+// RespondInvitation accepts or declines an invitation of the user via the agent.
+func (s *Storage) RespondInvitation(invitationID int64, accept bool) error {
+	request := modelagent.InvitationResponse{ID: invitationID, Accept: accept}
+	return s.do(http.MethodPut, modelagent.RouteInvitations, nil, request, nil)
+}
+
+// Members retrieves members of a collection via the agent.
+func (s *Storage) Members(collectionID string) ([]modelstorage.CollectionMember, error) {
+	var members []modelstorage.CollectionMember
+	err := s.do(http.MethodGet, modelagent.RouteMembers, url.Values{"collection": {collectionID}}, nil, &members)
+	return members, err
+}
+
+// SetMemberRole changes a role of a collection member via the agent.
+func (s *Storage) SetMemberRole(collectionID, login, role string) error {
+	request := modelagent.MemberRequest{CollectionID: collectionID, Login: login, Role: role}
+	return s.do(http.MethodPost, modelagent.RouteMembers, nil, request, nil)
+}
+
+// RemoveMember removes a member from a collection via the agent.
+func (s *Storage) RemoveMember(collectionID, login string) error {
+	query := url.Values{"collection": {collectionID}, "login": {login}}
+	return s.do(http.MethodDelete, modelagent.RouteMembers, query, nil, nil)
+}
+
+// CollectionEvents retrieves membership changes of a collection via the agent.
+func (s *Storage) CollectionEvents(collectionID string) ([]modelstorage.CollectionEvent, error) {
+	var events []modelstorage.CollectionEvent
+	err := s.do(http.MethodGet, modelagent.RouteCollectionEvents, url.Values{"collection": {collectionID}}, nil, &events)
+	return events, err
+}
+
+// Activity retrieves a page of the audit log of the user via the agent.
+func (s *Storage) Activity(beforeID int64, limit int) ([]modelstorage.AuditEvent, error) {
+	var events []modelstorage.AuditEvent
+	query := url.Values{"before": {strconv.FormatInt(beforeID, 10)}, "limit": {strconv.Itoa(limit)}}
+	err := s.do(http.MethodGet, modelagent.RouteActivity, query, nil, &events)
+	return events, err
+}
+
+// Sessions retrieves open sessions of the user via the agent.
+func (s *Storage) Sessions() ([]modelstorage.Session, error) {
+	var sessions []modelstorage.Session
+	err := s.do(http.MethodGet, modelagent.RouteSessions, nil, nil, &sessions)
+	return sessions, err
+}
+
+// RevokeSession revokes a session of the user via the agent.
+func (s *Storage) RevokeSession(sessionID int64) error {
+	return s.do(http.MethodDelete, modelagent.RouteSessions, url.Values{"id": {strconv.FormatInt(sessionID, 10)}}, nil, nil)
+}
+
+// SetTrustedContact designates a trusted contact of the user via the agent.
+func (s *Storage) SetTrustedContact(login string, waitDays int) error {
+	return s.do(http.MethodPost, modelagent.RouteTrustedContacts, nil, modelagent.TrustedContactRequest{Login: login, WaitDays: waitDays}, nil)
+}
+
+// RemoveTrustedContact removes a trusted contact of the user via the agent.
+func (s *Storage) RemoveTrustedContact(login string) error {
+	return s.do(http.MethodDelete, modelagent.RouteTrustedContacts, url.Values{"login": {login}}, nil, nil)
+}
+
+// EmergencyAccess retrieves trusted contacts of the user and owners who trust the user via the agent.
+func (s *Storage) EmergencyAccess() (modelstorage.EmergencyAccessList, error) {
+	var list modelstorage.EmergencyAccessList
+	err := s.do(http.MethodGet, modelagent.RouteTrustedContacts, nil, nil, &list)
+	return list, err
+}
+
+// RequestEmergencyAccess requests emergency access to the vault of an owner via the agent.
+func (s *Storage) RequestEmergencyAccess(owner string) error {
+	return s.do(http.MethodPost, modelagent.RouteEmergency, nil, modelagent.EmergencyRequest{Login: owner}, nil)
+}
+
+// ApproveEmergencyAccess grants a pending request of a trusted contact via the agent.
+func (s *Storage) ApproveEmergencyAccess(contact string) error {
+	return s.do(http.MethodPut, modelagent.RouteEmergency, nil, modelagent.EmergencyRequest{Login: contact, Approve: true}, nil)
+}
+
+// RejectEmergencyAccess rejects or revokes emergency access of a trusted contact via the agent.
+func (s *Storage) RejectEmergencyAccess(contact string) error {
+	return s.do(http.MethodPut, modelagent.RouteEmergency, nil, modelagent.EmergencyRequest{Login: contact}, nil)
+}
+
+// EmergencyVault retrieves the vault of an owner who granted emergency access to the user via the agent.
+func (s *Storage) EmergencyVault(owner string) (modelstorage.EmergencyVault, error) {
+	var vault modelstorage.EmergencyVault
+	err := s.do(http.MethodGet, modelagent.RouteEmergencyVault, url.Values{"owner": {owner}}, nil, &vault)
+	return vault, err
+}
+
+// CleanDB locks the agent wiping its vault.
+func (s *Storage) CleanDB() {
+	if err := s.do(http.MethodPost, modelagent.RouteLock, nil, nil, nil); err != nil {
+		s.logger.Error().Err(err).Msg("Could not lock agent")
+	}
+}
+
+// Login unlocks the agent by logging in.
+func (s *Storage) Login(login, password string) error {
+	if login == "" || password == "" {
+		return errors.New("Login/Password fields cannot be empty")
+	}
+	return s.do(http.MethodPost, modelagent.RouteLogin, nil, modelagent.Credentials{Login: login, Password: password}, nil)
+}
+
+// Register unlocks the agent by registering a new user.
+func (s *Storage) Register(login, password string) error {
+	if login == "" || password == "" {
+		return errors.New("Login/Password fields cannot be empty")
+	}
+	return s.do(http.MethodPost, modelagent.RouteRegister, nil, modelagent.Credentials{Login: login, Password: password}, nil)
+}
+
+// Status retrieves the agent status.
+func (s *Storage) Status() (modelagent.Status, error) {
+	var response modelagent.Status
+	err := s.do(http.MethodGet, modelagent.RouteStatus, nil, nil, &response)
+	return response, err
+}
+
+// do sends a request to the agent and decodes its response.
+func (s *Storage) do(method, route string, query url.Values, body, response interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	target := baseURL + route
+	if query != nil {
+		target += "?" + query.Encode()
+	}
+	request, err := http.NewRequest(method, target, reader)
+	if err != nil {
+		return err
+	}
+	request.Header.Set(modelagent.AuthHeader, s.token)
+	request.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.Do(request)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Could not execute agent request")
+		return fmt.Errorf("agent is not reachable: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		var agentErr modelagent.Error
+		if err := json.NewDecoder(resp.Body).Decode(&agentErr); err != nil || agentErr.Error == "" {
+			return fmt.Errorf("agent responded with %s", resp.Status)
+		}
+		return errors.New(agentErr.Error)
+	}
+	if response == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(response)
+}
@@ -0,0 +1,88 @@
+package remote
+
+import (
+	"context"
+	agent "dk-go-gophkeeper/internal/client/agent/v1"
+	"dk-go-gophkeeper/internal/client/storage/inmemory"
+	"dk-go-gophkeeper/internal/client/storage/modelstorage"
+	"dk-go-gophkeeper/internal/config"
+	"dk-go-gophkeeper/internal/mocks"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+)
+
+func TestStorage(t *testing.T) {
+	cfg := config.NewDefaultConfiguration()
+	cfg.BankCardDB = "bankCard"
+	cfg.LoginPasswordDB = "loginPassword"
+	cfg.TextBinaryDB = "textBinary"
+	cfg.AgentSocket = filepath.Join(t.TempDir(), "agent.sock")
+	assert.Equal(t, false, Available(cfg))
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mocks.NewMockGRPCClient(ctrl)
+	keeper := mocks.NewMockKeeper(ctrl)
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	agentInstance, err := agent.InitAgent(inmemory.InitStorage(&logger, client, keeper, cfg), client, &logger, cfg)
+	assert.Equal(t, nil, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- agentInstance.Serve(ctx)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+	assert.Eventually(t, func() bool { return Available(cfg) }, time.Second, 10*time.Millisecond)
+
+	st, err := InitStorage(&logger, cfg)
+	assert.Equal(t, nil, err)
+	err = st.AddTextBinary("id1", "entry", "")
+	assert.Equal(t, agent.ErrLocked.Error(), err.Error())
+
+	client.EXPECT().Login(gomock.Any()).Return(codes.OK, nil)
+	client.EXPECT().GetPublicKey("").Return("public_key", codes.OK, nil)
+	keeper.EXPECT().PrivateKey("public_key").Return("private_key", nil)
+	client.EXPECT().GetBankCards().Return(map[string]modelstorage.BankCard{}, codes.OK, nil)
+	client.EXPECT().GetLoginsPasswords().Return(map[string]modelstorage.LoginAndPassword{"id2": {Identifier: "id2", Login: "user"}}, codes.OK, nil)
+	client.EXPECT().GetTextsBinaries().Return(map[string]modelstorage.TextOrBinary{}, codes.OK, nil)
+	client.EXPECT().ListEmergencyAccess().Return(modelstorage.EmergencyAccessList{}, codes.OK, nil)
+	assert.Equal(t, nil, st.Login("user", "password"))
+	status, err := st.Status()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, false, status.Locked)
+
+	client.EXPECT().SendTextBinary(gomock.Any()).Return(codes.OK, nil)
+	assert.Equal(t, nil, st.AddTextBinary("id1", "entry", ""))
+	assert.Equal(t, true, st.Exists("id1", cfg.TextBinaryDB))
+	assert.Equal(t, "entry ID id1 in textBinary storage already exists", st.AddBatch(modelstorage.Batch{
+		TextsBinaries: []modelstorage.TextOrBinary{{Identifier: "id1"}},
+	})[0].Err.Error())
+	batch := st.Export()
+	assert.Equal(t, []modelstorage.LoginAndPassword{{Identifier: "id2", Login: "user"}}, batch.LoginsPasswords)
+	data, err := st.Get("id2", cfg.LoginPasswordDB)
+	assert.Equal(t, nil, err)
+	assert.NotEqual(t, "", data)
+	_, err = st.Get("id3", cfg.LoginPasswordDB)
+	assert.Equal(t, "entry ID id3 in loginPassword storage does not exist", err.Error())
+	assert.Equal(t, true, st.Exists("id2", cfg.LoginPasswordDB))
+	assert.Equal(t, false, st.Exists("id3", cfg.LoginPasswordDB))
+
+	client.EXPECT().RemoveLoginPassword("id2").Return(codes.OK, nil)
+	assert.Equal(t, nil, st.Remove("id2", cfg.LoginPasswordDB))
+
+	client.EXPECT().SetToken("", "")
+	st.CleanDB()
+	status, err = st.Status()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, status.Locked)
+}
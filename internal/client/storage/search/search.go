@@ -0,0 +1,175 @@
+// Package search provides fuzzy searching of locally stored entries.
+package search
+
+import (
+	"dk-go-gophkeeper/internal/client/storage/modelstorage"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// scoring weights of a fuzzy match
+const (
+	matchScore       = 1
+	consecutiveBonus = 4
+	boundaryBonus    = 3
+	identifierWeight = 2
+)
+
+// Match reports whether all runes of the pattern occur in the text in order, ignoring case, and scores the match.
+// Consecutive runes and runes starting a word score higher.
+func Match(pattern, text string) (int, bool) {
+	p := []rune(strings.ToLower(pattern))
+	t := []rune(strings.ToLower(text))
+	if len(p) == 0 {
+		return 0, true
+	}
+	score, pi, prev := 0, 0, -2
+	for ti := 0; ti < len(t) && pi < len(p); ti++ {
+		if t[ti] != p[pi] {
+			continue
+		}
+		score += matchScore
+		if ti == prev+1 {
+			score += consecutiveBonus
+		}
+		if ti == 0 || !unicode.IsLetter(t[ti-1]) && !unicode.IsDigit(t[ti-1]) {
+			score += boundaryBonus
+		}
+		prev = ti
+		pi++
+	}
+	if pi < len(p) {
+		return 0, false
+	}
+	return score, true
+}
+
+// query term prefixes filtering entries by labels
+const (
+	prefixTag      = "tag:"
+	prefixFolder   = "folder:"
+	termFavorite   = "is:favorite"
+	folderSplitter = "/"
+	tagSplitter    = ","
+)
+
+// CleanLabels normalizes labels the same way the server does: folder segments are trimmed and empty ones are dropped,
+// tags are trimmed, lowercased and deduplicated and a leading # is dropped.
+func CleanLabels(labels modelstorage.Labels) modelstorage.Labels {
+	var segments []string
+	for _, segment := range strings.Split(labels.Folder, folderSplitter) {
+		if segment = strings.TrimSpace(segment); segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	seen := make(map[string]bool)
+	var tags []string
+	for _, tag := range labels.Tags {
+		for _, part := range strings.Split(tag, tagSplitter) {
+			part = strings.TrimLeft(strings.ToLower(strings.TrimSpace(part)), "#")
+			if part != "" && !seen[part] {
+				seen[part] = true
+				tags = append(tags, part)
+			}
+		}
+	}
+	return modelstorage.Labels{Folder: strings.Join(segments, folderSplitter), Tags: tags, Favorite: labels.Favorite}
+}
+
+// InFolder reports whether a folder is the parent folder or any of its subfolders ignoring case, every folder is
+// within an empty parent.
+func InFolder(folder, parent string) bool {
+	folder, parent = strings.ToLower(folder), strings.ToLower(CleanLabels(modelstorage.Labels{Folder: parent}).Folder)
+	return parent == "" || folder == parent || strings.HasPrefix(folder, parent+folderSplitter)
+}
+
+// MatchLabels reports whether labels are within a folder, contain every tag ignoring case and a leading # and are
+// marked as favorite if favorites only are requested.
+func MatchLabels(labels modelstorage.Labels, folder string, tags []string, favoritesOnly bool) bool {
+	if favoritesOnly && !labels.Favorite || !InFolder(labels.Folder, folder) {
+		return false
+	}
+	for _, tag := range tags {
+		if !hasTag(labels, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// hasTag reports whether labels contain a tag ignoring case and a leading #.
+func hasTag(labels modelstorage.Labels, tag string) bool {
+	tag = strings.TrimLeft(strings.ToLower(tag), "#")
+	for _, value := range labels.Tags {
+		if strings.ToLower(value) == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// Filter returns summaries of entries matching every whitespace-separated term of the query in either identifier or
+// meta, ordered as requested. Identifier matches weigh more than meta matches. Terms tag:<tag>, folder:<folder> and
+// is:favorite filter entries by their labels instead.
+func Filter(batch modelstorage.Batch, bankCardDB, loginPasswordDB, textBinaryDB, query string, order modelstorage.Order) []modelstorage.Summary {
+	var terms, tags []string
+	var folder string
+	var favorites bool
+	for _, term := range strings.Fields(query) {
+		lower := strings.ToLower(term)
+		switch {
+		case strings.HasPrefix(lower, prefixTag) && len(term) > len(prefixTag):
+			tags = append(tags, term[len(prefixTag):])
+		case strings.HasPrefix(lower, prefixFolder) && len(term) > len(prefixFolder):
+			folder = term[len(prefixFolder):]
+		case lower == termFavorite:
+			favorites = true
+		default:
+			terms = append(terms, term)
+		}
+	}
+	summaries := make([]modelstorage.Summary, 0)
+	add := func(identifier, db, meta string, labels modelstorage.Labels) {
+		if !MatchLabels(labels, folder, tags, favorites) {
+			return
+		}
+		summary := modelstorage.Summary{Identifier: identifier, Db: db, Meta: meta, Labels: labels}
+		for _, term := range terms {
+			best := -1
+			if score, ok := Match(term, identifier); ok {
+				best = score * identifierWeight
+			}
+			if score, ok := Match(term, meta); ok && score > best {
+				best = score
+			}
+			if best < 0 {
+				return
+			}
+			summary.Score += best
+		}
+		summaries = append(summaries, summary)
+	}
+	for _, value := range batch.BankCards {
+		add(value.Identifier, bankCardDB, value.Meta, value.Labels)
+	}
+	for _, value := range batch.LoginsPasswords {
+		add(value.Identifier, loginPasswordDB, value.Meta, value.Labels)
+	}
+	for _, value := range batch.TextsBinaries {
+		add(value.Identifier, textBinaryDB, value.Meta, value.Labels)
+	}
+	sort.SliceStable(summaries, func(i, j int) bool {
+		a, b := summaries[i], summaries[j]
+		switch {
+		case order == modelstorage.OrderRelevance && a.Score != b.Score:
+			return a.Score > b.Score
+		case order == modelstorage.OrderDb && a.Db != b.Db:
+			return a.Db < b.Db
+		case a.Identifier != b.Identifier:
+			return a.Identifier < b.Identifier
+		}
+		return a.Db < b.Db
+	})
+	return summaries
+}
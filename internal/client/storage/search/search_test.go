@@ -0,0 +1,106 @@
+package search
+
+import (
+	"dk-go-gophkeeper/internal/client/storage/modelstorage"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		text    string
+		score   int
+		ok      bool
+	}{
+		{name: "empty pattern", pattern: "", text: "github", score: 0, ok: true},
+		{name: "consecutive prefix", pattern: "git", text: "github", score: 14, ok: true},
+		{name: "scattered", pattern: "gh", text: "github", score: 5, ok: true},
+		{name: "case insensitive", pattern: "GH", text: "GitHub", score: 5, ok: true},
+		{name: "word boundary", pattern: "pb", text: "prod-backup", score: 8, ok: true},
+		{name: "wrong order", pattern: "hg", text: "github", score: 0, ok: false},
+		{name: "no match", pattern: "x", text: "github", score: 0, ok: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			score, ok := Match(tt.pattern, tt.text)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.score, score)
+		})
+	}
+}
+
+func TestFilter(t *testing.T) {
+	batch := modelstorage.Batch{
+		BankCards: []modelstorage.BankCard{{Identifier: "visa"}},
+		LoginsPasswords: []modelstorage.LoginAndPassword{
+			{Identifier: "github", Meta: "work"},
+			{Identifier: "gitlab"},
+		},
+		TextsBinaries: []modelstorage.TextOrBinary{{Identifier: "notes", Meta: "github backup"}},
+	}
+	filter := func(query string, order modelstorage.Order) []modelstorage.Summary {
+		return Filter(batch, "bankCard", "loginPassword", "textBinary", query, order)
+	}
+
+	assert.Equal(t, []modelstorage.Summary{
+		{Identifier: "github", Db: "loginPassword", Meta: "work", Score: 10},
+		{Identifier: "notes", Db: "textBinary", Meta: "github backup", Score: 5},
+	}, filter("gh", modelstorage.OrderRelevance))
+	assert.Equal(t, []modelstorage.Summary{
+		{Identifier: "notes", Db: "textBinary", Meta: "github backup", Score: 27},
+	}, filter("gh backup", modelstorage.OrderRelevance))
+	assert.Equal(t, []modelstorage.Summary{}, filter("generic_query", modelstorage.OrderRelevance))
+
+	var identifiers []string
+	for _, summary := range filter("", modelstorage.OrderRelevance) {
+		identifiers = append(identifiers, summary.Identifier)
+	}
+	assert.Equal(t, []string{"github", "gitlab", "notes", "visa"}, identifiers)
+	identifiers = nil
+	for _, summary := range filter("", modelstorage.OrderDb) {
+		identifiers = append(identifiers, summary.Identifier)
+	}
+	assert.Equal(t, []string{"visa", "github", "gitlab", "notes"}, identifiers)
+}
+
+func TestFilterLabels(t *testing.T) {
+	batch := modelstorage.Batch{
+		BankCards: []modelstorage.BankCard{{Identifier: "visa", Labels: modelstorage.Labels{Folder: "Finance", Tags: []string{"travel"}}}},
+		LoginsPasswords: []modelstorage.LoginAndPassword{
+			{Identifier: "github", Labels: modelstorage.Labels{Folder: "Work/Dev", Tags: []string{"code"}, Favorite: true}},
+			{Identifier: "gitlab", Labels: modelstorage.Labels{Folder: "Work", Tags: []string{"code", "travel"}}},
+		},
+	}
+	filter := func(query string) []string {
+		identifiers := make([]string, 0)
+		for _, summary := range Filter(batch, "bankCard", "loginPassword", "textBinary", query, modelstorage.OrderIdentifier) {
+			identifiers = append(identifiers, summary.Identifier)
+		}
+		return identifiers
+	}
+
+	assert.Equal(t, []string{"github", "gitlab"}, filter("folder:work"))
+	assert.Equal(t, []string{"github"}, filter("folder:Work/Dev"))
+	assert.Equal(t, []string{"gitlab", "visa"}, filter("tag:#Travel"))
+	assert.Equal(t, []string{"gitlab"}, filter("tag:travel tag:code"))
+	assert.Equal(t, []string{"github"}, filter("is:favorite"))
+	assert.Equal(t, []string{"gitlab"}, filter("folder:work lab"))
+	assert.Equal(t, []string{}, filter("folder:wor"))
+}
+
+func TestCleanLabels(t *testing.T) {
+	assert.Equal(t, modelstorage.Labels{Folder: "Work/Dev", Tags: []string{"code", "travel"}, Favorite: true},
+		CleanLabels(modelstorage.Labels{Folder: " /Work// Dev /", Tags: []string{"#Code, travel", "code", " "}, Favorite: true}))
+	assert.Equal(t, modelstorage.Labels{}, CleanLabels(modelstorage.Labels{Folder: "/", Tags: []string{""}}))
+}
+
+func TestInFolder(t *testing.T) {
+	assert.Equal(t, true, InFolder("Work/Dev", ""))
+	assert.Equal(t, true, InFolder("Work/Dev", "work"))
+	assert.Equal(t, true, InFolder("Work/Dev", "Work/Dev/"))
+	assert.Equal(t, false, InFolder("Workshop", "Work"))
+	assert.Equal(t, false, InFolder("", "Work"))
+}
@@ -0,0 +1,77 @@
+package tui
+
+import (
+	"dk-go-gophkeeper/internal/client/storage/modelstorage"
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// addActivityForm defines form behavior and its contents.
+func (a *App) addActivityForm() *tview.Form {
+	a.activityForm.AddButton("Events", func() {
+		a.App.SetFocus(a.activityTable)
+	})
+	a.activityForm.AddButton("Newest", func() {
+		a.refreshActivityTable(0)
+	})
+	a.activityForm.AddButton("Older", func() {
+		if a.activityOldest == 0 {
+			a.operationStatus.SetText("No older events")
+			return
+		}
+		a.refreshActivityTable(a.activityOldest)
+	})
+	a.activityForm.AddButton("Back", func() {
+		pages.SwitchToPage(pageMenu)
+	})
+	a.activityForm.SetCancelFunc(func() {
+		pages.SwitchToPage(pageMenu)
+	})
+	return a.activityForm
+}
+
+// refreshActivityTable retrieves a page of the audit log of the user from the server and lists it, events older than
+// beforeID are listed unless it is zero.
+func (a *App) refreshActivityTable(beforeID int64) {
+	events, err := a.storage.Activity(beforeID, 0)
+	if err != nil {
+		a.operationStatus.SetText(err.Error())
+		return
+	}
+	if beforeID != 0 && len(events) == 0 {
+		a.activityOldest = 0
+		a.operationStatus.SetText("No older events")
+		return
+	}
+	a.activityTable.Clear()
+	for col, title := range []string{"Time", "Action", "Method", "Peer", "Result"} {
+		a.activityTable.SetCell(0, col, tview.NewTableCell(title).SetTextColor(tcell.ColorGreen).SetSelectable(false))
+	}
+	for i, event := range events {
+		a.activityTable.SetCell(i+1, 0, tview.NewTableCell(activityTime(event)))
+		a.activityTable.SetCell(i+1, 1, tview.NewTableCell(event.Action))
+		a.activityTable.SetCell(i+1, 2, tview.NewTableCell(event.Method).SetExpansion(1))
+		a.activityTable.SetCell(i+1, 3, tview.NewTableCell(event.Peer).SetExpansion(1))
+		result := tview.NewTableCell(event.Code)
+		if event.Code != "OK" {
+			result.SetTextColor(tcell.ColorRed)
+		}
+		a.activityTable.SetCell(i+1, 4, result)
+	}
+	a.activityOldest = 0
+	if len(events) != 0 {
+		a.activityOldest = events[len(events)-1].ID
+		a.activityTable.Select(1, 0).ScrollToBeginning()
+	}
+	a.operationStatus.SetText(fmt.Sprintf("Account activity: %d events", len(events)))
+}
+
+// activityTime formats the time an audit event was recorded at in local time.
+func activityTime(event modelstorage.AuditEvent) string {
+	if event.CreatedAt == nil {
+		return ""
+	}
+	return event.CreatedAt.Local().Format("2006-01-02 15:04:05")
+}
@@ -0,0 +1,205 @@
+package tui
+
+import (
+	"dk-go-gophkeeper/internal/client/storage/modelstorage"
+	"dk-go-gophkeeper/internal/validation"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// emergencyRow is a reference of a row of the emergency access table, grantor rows list owners who trust the user.
+type emergencyRow struct {
+	access  modelstorage.EmergencyAccess
+	grantor bool
+}
+
+// addEmergencyForm defines form behavior and its contents, trusted contacts of the user and owners who trust the user
+// are listed below the form. Owners act on their selected trusted contacts, trusted contacts on selected owners.
+func (a *App) addEmergencyForm() *tview.Form {
+	var login string
+	var waitDays int
+	a.emergencyForm.AddInputField("Trusted contact", "", loginLength, nil, func(text string) {
+		login = text
+	})
+	a.emergencyForm.AddInputField("Waiting days", "", emergencyWaitLength, tview.InputFieldInteger, func(text string) {
+		waitDays, _ = strconv.Atoi(text)
+	})
+	a.emergencyForm.AddButton("Trust", func() {
+		if err := a.storage.SetTrustedContact(login, waitDays); err != nil {
+			a.operationStatus.SetText(err.Error())
+			return
+		}
+		a.refreshEmergencyTable()
+		a.operationStatus.SetText(fmt.Sprintf("%s may request emergency access to your vault", login))
+	})
+	a.emergencyForm.AddButton("List", func() {
+		a.App.SetFocus(a.emergencyTable)
+	})
+	a.emergencyForm.AddButton("Approve", func() {
+		row, ok := a.selectedEmergencyRow(false)
+		if !ok {
+			return
+		}
+		if err := a.storage.ApproveEmergencyAccess(row.access.Contact); err != nil {
+			a.operationStatus.SetText(err.Error())
+			return
+		}
+		a.refreshEmergencyTable()
+		a.operationStatus.SetText(fmt.Sprintf("Emergency access was granted to %s", row.access.Contact))
+	})
+	a.emergencyForm.AddButton("Reject", func() {
+		row, ok := a.selectedEmergencyRow(false)
+		if !ok {
+			return
+		}
+		if err := a.storage.RejectEmergencyAccess(row.access.Contact); err != nil {
+			a.operationStatus.SetText(err.Error())
+			return
+		}
+		a.refreshEmergencyTable()
+		a.operationStatus.SetText(fmt.Sprintf("Emergency access of %s was rejected", row.access.Contact))
+	})
+	a.emergencyForm.AddButton("Remove", func() {
+		row, ok := a.selectedEmergencyRow(false)
+		if !ok {
+			return
+		}
+		if err := a.storage.RemoveTrustedContact(row.access.Contact); err != nil {
+			a.operationStatus.SetText(err.Error())
+			return
+		}
+		a.refreshEmergencyTable()
+		a.operationStatus.SetText(fmt.Sprintf("%s is no longer a trusted contact", row.access.Contact))
+	})
+	a.emergencyForm.AddButton("Request access", func() {
+		row, ok := a.selectedEmergencyRow(true)
+		if !ok {
+			return
+		}
+		if err := a.storage.RequestEmergencyAccess(row.access.Owner); err != nil {
+			a.operationStatus.SetText(err.Error())
+			return
+		}
+		a.refreshEmergencyTable()
+		a.operationStatus.SetText(fmt.Sprintf("Emergency access to the vault of %s was requested", row.access.Owner))
+	})
+	a.emergencyForm.AddButton("Open vault", func() {
+		row, ok := a.selectedEmergencyRow(true)
+		if !ok {
+			return
+		}
+		vault, err := a.storage.EmergencyVault(row.access.Owner)
+		if err != nil {
+			a.operationStatus.SetText(err.Error())
+			return
+		}
+		a.operationStatus.SetText(fmt.Sprintf("Opening the vault of %s: OK", vault.Owner))
+		a.result.SetText(a.formatEmergencyVault(vault)).ScrollToBeginning()
+		pages.SwitchToPage(pageResult)
+	})
+	a.emergencyForm.AddButton("Refresh", func() {
+		a.refreshEmergencyTable()
+	})
+	a.emergencyForm.AddButton("Back", func() {
+		pages.SwitchToPage(pageMenu)
+	})
+	a.emergencyForm.SetCancelFunc(func() {
+		pages.SwitchToPage(pageMenu)
+	})
+	return a.emergencyForm
+}
+
+// selectedEmergencyRow returns the selected row of the emergency access table, it reports to the user if the row does
+// not list an owner or a trusted contact as expected.
+func (a *App) selectedEmergencyRow(grantor bool) (emergencyRow, bool) {
+	row, _ := a.emergencyTable.GetSelection()
+	selected, ok := a.emergencyTable.GetCell(row, 0).GetReference().(emergencyRow)
+	switch {
+	case (!ok || !selected.grantor) && grantor:
+		a.operationStatus.SetText("Select an owner who trusts you first")
+		return selected, false
+	case (!ok || selected.grantor) && !grantor:
+		a.operationStatus.SetText("Select a trusted contact first")
+		return selected, false
+	}
+	return selected, true
+}
+
+// refreshEmergencyTable retrieves trusted contacts of the user and owners who trust the user from the server and lists
+// them.
+func (a *App) refreshEmergencyTable() {
+	list, err := a.storage.EmergencyAccess()
+	if err != nil {
+		a.operationStatus.SetText(err.Error())
+		return
+	}
+	a.emergencyTable.Clear()
+	for col, title := range []string{"User", "Relation", "Status", "Waiting days", "Requested", "Granted"} {
+		a.emergencyTable.SetCell(0, col, tview.NewTableCell(title).SetTextColor(tcell.ColorGreen).SetSelectable(false))
+	}
+	rows := make([]emergencyRow, 0, len(list.TrustedContacts)+len(list.Grantors))
+	for _, contact := range list.TrustedContacts {
+		rows = append(rows, emergencyRow{access: contact})
+	}
+	for _, grantor := range list.Grantors {
+		rows = append(rows, emergencyRow{access: grantor, grantor: true})
+	}
+	for i, row := range rows {
+		user, relation := row.access.Contact, "your trusted contact"
+		if row.grantor {
+			user, relation = row.access.Owner, "trusts you"
+		}
+		status := tview.NewTableCell(row.access.Status)
+		if row.access.Status != modelstorage.EmergencyDesignated {
+			status.SetTextColor(tcell.ColorYellow)
+		}
+		a.emergencyTable.SetCell(i+1, 0, tview.NewTableCell(user).SetReference(row).SetExpansion(1))
+		a.emergencyTable.SetCell(i+1, 1, tview.NewTableCell(relation))
+		a.emergencyTable.SetCell(i+1, 2, status)
+		a.emergencyTable.SetCell(i+1, 3, tview.NewTableCell(strconv.Itoa(row.access.WaitDays)))
+		a.emergencyTable.SetCell(i+1, 4, tview.NewTableCell(emergencyRequested(row.access)))
+		a.emergencyTable.SetCell(i+1, 5, tview.NewTableCell(sessionTime(row.access.GrantedAt)))
+	}
+	if len(rows) != 0 {
+		a.emergencyTable.Select(1, 0).ScrollToBeginning()
+	}
+	a.operationStatus.SetText(fmt.Sprintf("Emergency access: %d trusted contacts, %d owners trust you", len(list.TrustedContacts), len(list.Grantors)))
+}
+
+// emergencyRequested describes a pending request along with the time it is granted at.
+func emergencyRequested(access modelstorage.EmergencyAccess) string {
+	if access.RequestedAt == nil {
+		return ""
+	}
+	if access.GrantsAt == nil {
+		return sessionTime(access.RequestedAt)
+	}
+	return fmt.Sprintf("%s, granted in %s", sessionTime(access.RequestedAt), time.Until(*access.GrantsAt).Round(time.Minute))
+}
+
+// formatEmergencyVault renders entries of a vault opened with emergency access as a human-readable text.
+func (a *App) formatEmergencyVault(vault modelstorage.EmergencyVault) string {
+	var sb strings.Builder
+	sb.WriteString(tview.Escape(fmt.Sprintf("Vault of %s as last sealed by the owner\n", vault.Owner)))
+	for _, value := range vault.BankCards {
+		fields := [][2]string{{"Identifier", value.Identifier}, {"Number", value.Number}, {"Brand", string(validation.DetectBrand(value.Number))},
+			{"Holder", value.Holder}, {"CVV", value.Cvv}, {"Expiry", value.Expiry}, {"PIN", value.Pin}, {"Meta", value.Meta}}
+		sb.WriteString("\n" + formatEntry(a.cfg.BankCardDB, fields, value.Labels, value.Fields, true))
+	}
+	for _, value := range vault.LoginsPasswords {
+		fields := [][2]string{{"Identifier", value.Identifier}, {"Login", value.Login}, {"Password", value.Password}, {"Meta", value.Meta}}
+		sb.WriteString("\n" + formatEntry(a.cfg.LoginPasswordDB, fields, value.Labels, value.Fields, true))
+	}
+	for _, value := range vault.TextsBinaries {
+		sb.WriteString("\n" + formatNote(a.cfg.TextBinaryDB, value, true))
+	}
+	if len(vault.BankCards)+len(vault.LoginsPasswords)+len(vault.TextsBinaries) == 0 {
+		sb.WriteString("\nThe vault is empty\n")
+	}
+	return sb.String()
+}
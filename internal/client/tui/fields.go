@@ -0,0 +1,132 @@
+package tui
+
+import (
+	"dk-go-gophkeeper/internal/client/markdown"
+	"dk-go-gophkeeper/internal/client/storage/modelstorage"
+	"dk-go-gophkeeper/internal/client/tui/modeltui"
+	"fmt"
+	"strings"
+
+	"github.com/rivo/tview"
+)
+
+// custom field rendering parameters
+const (
+	customFieldNameLength  = 20
+	customFieldValueLength = 50
+	maskCharacter          = '*'
+	concealedValue         = "********"
+)
+
+// fieldsEditor defines attributes and methods of a fieldsEditor instance, it keeps custom field inputs of a form.
+type fieldsEditor struct {
+	form     *tview.Form
+	fields   []*modeltui.CustomField
+	values   []*tview.InputField
+	revealed bool
+}
+
+// newFieldsEditor adds inputs of existing custom fields to a form.
+func newFieldsEditor(form *tview.Form, fields []modelstorage.CustomField) *fieldsEditor {
+	e := &fieldsEditor{form: form}
+	for _, field := range fields {
+		e.add(modeltui.CustomField{Name: field.Name, Value: field.Value, Concealed: field.Concealed})
+	}
+	return e
+}
+
+// add appends inputs of a custom field to the form, values of concealed fields are masked until revealed.
+func (e *fieldsEditor) add(field modeltui.CustomField) {
+	f := &field
+	e.fields = append(e.fields, f)
+	kind := "Field"
+	if f.Concealed {
+		kind = "Concealed field"
+	}
+	e.form.AddInputField(fmt.Sprintf("%s %d name", kind, len(e.fields)), f.Name, customFieldNameLength, nil, func(name string) {
+		f.Name = name
+	})
+	e.form.AddInputField(fmt.Sprintf("%s %d value", kind, len(e.fields)), f.Value, customFieldValueLength, nil, func(value string) {
+		f.Value = value
+	})
+	value := e.form.GetFormItem(e.form.GetFormItemCount() - 1).(*tview.InputField)
+	if f.Concealed && !e.revealed {
+		value.SetMaskCharacter(maskCharacter)
+	}
+	e.values = append(e.values, value)
+}
+
+// addButtons adds buttons appending plain and concealed fields and revealing values of concealed ones.
+func (e *fieldsEditor) addButtons() {
+	e.form.AddButton("Add field", func() {
+		e.add(modeltui.CustomField{})
+	})
+	e.form.AddButton("Add concealed field", func() {
+		e.add(modeltui.CustomField{Concealed: true})
+	})
+	e.form.AddButton("Reveal", nil)
+	reveal := e.form.GetButton(e.form.GetButtonCount() - 1)
+	reveal.SetSelectedFunc(func() {
+		e.revealed = !e.revealed
+		if e.revealed {
+			reveal.SetLabel("Conceal")
+		} else {
+			reveal.SetLabel("Reveal")
+		}
+		for i, field := range e.fields {
+			if field.Concealed && !e.revealed {
+				e.values[i].SetMaskCharacter(maskCharacter)
+			} else {
+				e.values[i].SetMaskCharacter(0)
+			}
+		}
+	})
+}
+
+// result returns custom fields in the order of their inputs skipping blank ones.
+func (e *fieldsEditor) result() []modelstorage.CustomField {
+	var fields []modelstorage.CustomField
+	for _, field := range e.fields {
+		if strings.TrimSpace(field.Name) == "" && field.Value == "" {
+			continue
+		}
+		fields = append(fields, modelstorage.CustomField{Name: field.Name, Value: field.Value, Concealed: field.Concealed})
+	}
+	return fields
+}
+
+// formatEntry renders an entry as a list of its fields, labels and custom fields, values of concealed custom fields
+// are masked unless revealed. The text is escaped so that values are not taken for style tags.
+func formatEntry(db string, fields [][2]string, labels modelstorage.Labels, custom []modelstorage.CustomField, reveal bool) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Type: %s\n", db))
+	for _, field := range fields {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", field[0], field[1]))
+	}
+	if labels.Folder != "" {
+		sb.WriteString(fmt.Sprintf("Folder: %s\n", labels.Folder))
+	}
+	if len(labels.Tags) > 0 {
+		sb.WriteString(fmt.Sprintf("Tags: %s\n", strings.Join(labels.Tags, ", ")))
+	}
+	if labels.Favorite {
+		sb.WriteString("Favorite: yes\n")
+	}
+	if len(custom) > 0 {
+		sb.WriteString("\nCustom fields:\n")
+	}
+	for _, field := range custom {
+		value := field.Value
+		if field.Concealed && !reveal {
+			value = concealedValue
+		}
+		sb.WriteString(fmt.Sprintf("  %s: %s\n", field.Name, value))
+	}
+	return tview.Escape(sb.String())
+}
+
+// formatNote renders a text/binary entry as a secure note, its fields are followed by the note rendered as Markdown.
+func formatNote(db string, note modelstorage.TextOrBinary, reveal bool) string {
+	fields := [][2]string{{"Identifier", note.Identifier}, {"Meta", note.Meta}}
+	return formatEntry(db, fields, note.Labels, note.Fields, reveal) + "\nNote:\n" + markdown.Render(note.Entry) + "\n"
+}
@@ -1,7 +1,14 @@
 // Package modeltui provides models for TUI.
 package modeltui
 
+import "dk-go-gophkeeper/internal/client/storage/modelstorage"
+
 type (
+	CustomField struct {
+		Name      string
+		Value     string
+		Concealed bool
+	}
 	LoginAndPassword struct {
 		Identifier string
 		Login      string
@@ -18,8 +25,14 @@ type (
 		Number     string
 		Holder     string
 		Cvv        string
+		Expiry     string
+		Pin        string
 		Meta       string
 	}
+	Generate struct {
+		Preset string
+		Size   int
+	}
 	RegisterLogin struct {
 		Login    string
 		Password string
@@ -31,5 +44,35 @@ type (
 	Get struct {
 		Identifier string
 		Db         string
+		Reveal     bool
+	}
+	Import struct {
+		Path   string
+		Format string
+	}
+	Health struct {
+		MinEntropy int
+		MaxAgeDays int
+		Corpus     string
+	}
+	Browse struct {
+		Query         string
+		Order         modelstorage.Order
+		Folder        string
+		Tags          string
+		FavoritesOnly bool
+	}
+	Labels struct {
+		Identifier string
+		Db         string
+		Folder     string
+		Tags       string
+		Favorite   bool
+	}
+	Share struct {
+		Identifier string
+		Db         string
+		Recipient  string
+		Permission string
 	}
 )
@@ -0,0 +1,90 @@
+package tui
+
+import (
+	"dk-go-gophkeeper/internal/client/storage/modelstorage"
+	"fmt"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// addSessionsForm defines form behavior and its contents.
+func (a *App) addSessionsForm() *tview.Form {
+	a.sessionsForm.AddButton("Sessions", func() {
+		a.App.SetFocus(a.sessionsTable)
+	})
+	a.sessionsForm.AddButton("Revoke", func() {
+		row, _ := a.sessionsTable.GetSelection()
+		session, ok := a.sessionsTable.GetCell(row, 0).GetReference().(modelstorage.Session)
+		if !ok {
+			a.operationStatus.SetText("Select a session to revoke first")
+			return
+		}
+		if err := a.storage.RevokeSession(session.ID); err != nil {
+			a.operationStatus.SetText(err.Error())
+			return
+		}
+		if session.Current {
+			a.operationStatus.SetText("Current session was revoked, log in again")
+			pages.SwitchToPage(pageMenu)
+			return
+		}
+		a.refreshSessionsTable()
+		a.operationStatus.SetText(fmt.Sprintf("Session of %s was revoked", sessionDevice(session)))
+	})
+	a.sessionsForm.AddButton("Refresh", func() {
+		a.refreshSessionsTable()
+	})
+	a.sessionsForm.AddButton("Back", func() {
+		pages.SwitchToPage(pageMenu)
+	})
+	a.sessionsForm.SetCancelFunc(func() {
+		pages.SwitchToPage(pageMenu)
+	})
+	return a.sessionsForm
+}
+
+// refreshSessionsTable retrieves open sessions of the user from the server and lists them.
+func (a *App) refreshSessionsTable() {
+	sessions, err := a.storage.Sessions()
+	if err != nil {
+		a.operationStatus.SetText(err.Error())
+		return
+	}
+	a.sessionsTable.Clear()
+	for col, title := range []string{"Device", "Version", "IP", "Last seen", "Created"} {
+		a.sessionsTable.SetCell(0, col, tview.NewTableCell(title).SetTextColor(tcell.ColorGreen).SetSelectable(false))
+	}
+	for i, session := range sessions {
+		device := tview.NewTableCell(sessionDevice(session)).SetReference(session).SetExpansion(1)
+		if session.Current {
+			device.SetText(device.Text + " (this device)").SetTextColor(tcell.ColorYellow)
+		}
+		a.sessionsTable.SetCell(i+1, 0, device)
+		a.sessionsTable.SetCell(i+1, 1, tview.NewTableCell(session.ClientVersion))
+		a.sessionsTable.SetCell(i+1, 2, tview.NewTableCell(session.Peer).SetExpansion(1))
+		a.sessionsTable.SetCell(i+1, 3, tview.NewTableCell(sessionTime(session.LastSeenAt)))
+		a.sessionsTable.SetCell(i+1, 4, tview.NewTableCell(sessionTime(session.CreatedAt)))
+	}
+	if len(sessions) != 0 {
+		a.sessionsTable.Select(1, 0).ScrollToBeginning()
+	}
+	a.operationStatus.SetText(fmt.Sprintf("Sessions: %d open", len(sessions)))
+}
+
+// sessionDevice returns the name of the device a session was opened from.
+func sessionDevice(session modelstorage.Session) string {
+	if session.DeviceName == "" {
+		return "unknown device"
+	}
+	return session.DeviceName
+}
+
+// sessionTime formats a time of a session in local time.
+func sessionTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Local().Format("2006-01-02 15:04:05")
+}
@@ -0,0 +1,242 @@
+package tui
+
+import (
+	"dk-go-gophkeeper/internal/client/storage/modelstorage"
+	"dk-go-gophkeeper/internal/client/tui/modeltui"
+	"dk-go-gophkeeper/internal/validation"
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// addShareForm defines form behavior and its contents, the given entry is shared with or revoked from a user.
+func (a *App) addShareForm(summary modelstorage.Summary) *tview.Form {
+	share := modeltui.Share{Identifier: summary.Identifier, Db: summary.Db, Permission: modelstorage.PermissionRead}
+	a.shareForm.SetTitle(fmt.Sprintf("Sharing of %s", summary.Identifier)).SetBorder(true)
+	a.shareForm.AddInputField("User", "", loginLength, nil, func(recipient string) {
+		share.Recipient = strings.TrimSpace(recipient)
+	})
+	a.shareForm.AddDropDown("Permission", []string{modelstorage.PermissionRead, modelstorage.PermissionReadWrite}, 0, func(permission string, idx int) {
+		share.Permission = permission
+	})
+	a.shareForm.AddButton("Share", func() {
+		if err := a.storage.Share(share.Identifier, share.Db, share.Recipient, share.Permission); err != nil {
+			a.operationStatus.SetText(err.Error())
+			pages.SwitchToPage(pageBrowse)
+			return
+		}
+		a.operationStatus.SetText(fmt.Sprintf("Sharing with %s: OK", share.Recipient))
+		a.refreshShareRecipients(share.Identifier, share.Db)
+	})
+	a.shareForm.AddButton("Revoke", func() {
+		if err := a.storage.Unshare(share.Identifier, share.Db, share.Recipient); err != nil {
+			a.operationStatus.SetText(err.Error())
+			pages.SwitchToPage(pageBrowse)
+			return
+		}
+		a.operationStatus.SetText(fmt.Sprintf("Revoking share of %s: OK", share.Recipient))
+		a.refreshShareRecipients(share.Identifier, share.Db)
+	})
+	a.shareForm.AddButton("Back", func() {
+		pages.SwitchToPage(pageBrowse)
+	})
+	a.refreshShareRecipients(share.Identifier, share.Db)
+	return a.shareForm
+}
+
+// refreshShareRecipients lists users the given entry is shared with.
+func (a *App) refreshShareRecipients(identifier, db string) {
+	shares, err := a.storage.Shares()
+	if err != nil {
+		a.shareRecipients.SetText(err.Error())
+		return
+	}
+	var sb strings.Builder
+	for _, share := range shares {
+		if share.Identifier != identifier || share.Db != db {
+			continue
+		}
+		if share.ReshareRequired {
+			sb.WriteString("Shared before clients kept key pairs, share again to restore access\n")
+		}
+		for _, recipient := range share.Recipients {
+			sb.WriteString(fmt.Sprintf("%s (%s)\n", recipient.Login, recipient.Permission))
+		}
+	}
+	if sb.Len() == 0 {
+		sb.WriteString("Not shared with anyone")
+	}
+	a.shareRecipients.SetText(sb.String())
+}
+
+// addSharedForm defines form behavior and its contents, entries shared with the user are listed below the form.
+func (a *App) addSharedForm() *tview.Form {
+	a.sharedForm.AddButton("Entries", func() {
+		a.App.SetFocus(a.sharedTable)
+	})
+	a.sharedForm.AddButton("Edit", func() {
+		entry, ok := a.selectedSharedEntry()
+		if !ok {
+			a.operationStatus.SetText("No entry selected")
+			return
+		}
+		if entry.Permission != modelstorage.PermissionReadWrite {
+			a.operationStatus.SetText(fmt.Sprintf("Entry %s is shared read-only", entry.Identifier()))
+			return
+		}
+		a.sharedEditForm.Clear(true)
+		a.addSharedEditForm(entry)
+		pages.SwitchToPage(pageSharedEdit)
+	})
+	a.sharedForm.AddButton("Refresh", func() {
+		a.refreshSharedTable()
+	})
+	a.sharedForm.AddButton("Back", func() {
+		pages.SwitchToPage(pageMenu)
+	})
+	a.sharedForm.SetCancelFunc(func() {
+		pages.SwitchToPage(pageMenu)
+	})
+	return a.sharedForm
+}
+
+// refreshSharedTable retrieves entries shared with the user from the server and lists them.
+func (a *App) refreshSharedTable() {
+	a.sharedTable.Clear()
+	for col, title := range []string{"Type", "Identifier", "Owner", "Permission"} {
+		a.sharedTable.SetCell(0, col, tview.NewTableCell(title).SetTextColor(tcell.ColorGreen).SetSelectable(false))
+	}
+	entries, err := a.storage.SharedWithMe()
+	if err != nil {
+		a.operationStatus.SetText(err.Error())
+		a.sharedDetail.SetText("")
+		return
+	}
+	for i, entry := range entries {
+		a.sharedTable.SetCell(i+1, 0, tview.NewTableCell(entry.Db).SetReference(entry))
+		a.sharedTable.SetCell(i+1, 1, tview.NewTableCell(entry.Identifier()).SetExpansion(1))
+		a.sharedTable.SetCell(i+1, 2, tview.NewTableCell(entry.Owner).SetExpansion(1))
+		a.sharedTable.SetCell(i+1, 3, tview.NewTableCell(entry.Permission))
+	}
+	a.operationStatus.SetText(fmt.Sprintf("Shared with me: %d entries", len(entries)))
+	if len(entries) == 0 {
+		a.sharedDetail.SetText("No entries are shared with you")
+		return
+	}
+	a.sharedTable.Select(1, 0).ScrollToBeginning()
+	a.showSharedDetail(1)
+}
+
+// selectedSharedEntry returns the entry selected in the list of entries shared with the user.
+func (a *App) selectedSharedEntry() (modelstorage.SharedEntry, bool) {
+	row, _ := a.sharedTable.GetSelection()
+	entry, ok := a.sharedTable.GetCell(row, 0).GetReference().(modelstorage.SharedEntry)
+	return entry, ok
+}
+
+// showSharedDetail displays the shared entry in the given row of the list, concealed fields stay masked.
+func (a *App) showSharedDetail(row int) {
+	entry, ok := a.sharedTable.GetCell(row, 0).GetReference().(modelstorage.SharedEntry)
+	if !ok {
+		return
+	}
+	var text string
+	switch {
+	case entry.BankCard != nil:
+		value := entry.BankCard
+		pin := value.Pin
+		if pin != "" {
+			pin = concealedValue
+		}
+		fields := [][2]string{{"Identifier", value.Identifier}, {"Number", value.Number}, {"Brand", string(validation.DetectBrand(value.Number))},
+			{"Holder", value.Holder}, {"CVV", value.Cvv}, {"Expiry", value.Expiry}, {"PIN", pin}, {"Meta", value.Meta}}
+		text = formatEntry(entry.Db, fields, value.Labels, value.Fields, false)
+	case entry.LoginPassword != nil:
+		value := entry.LoginPassword
+		fields := [][2]string{{"Identifier", value.Identifier}, {"Login", value.Login}, {"Password", value.Password}, {"Meta", value.Meta}}
+		text = formatEntry(entry.Db, fields, value.Labels, value.Fields, false)
+	case entry.TextBinary != nil:
+		text = formatNote(entry.Db, *entry.TextBinary, false)
+	}
+	text += tview.Escape(fmt.Sprintf("\nShared by %s (%s)\n", entry.Owner, entry.Permission))
+	a.sharedDetail.SetText(text).ScrollToBeginning()
+}
+
+// addSharedEditForm defines form behavior and its contents, the update of the shared entry is sent to its owner on
+// saving, the identifier stays as the owner set it. Notes are edited in the note editor below the form.
+func (a *App) addSharedEditForm(entry modelstorage.SharedEntry) *tview.Form {
+	a.sharedEditForm.SetTitle(fmt.Sprintf("%s shared by %s", entry.Identifier(), entry.Owner)).SetBorder(true)
+	a.sharedEditView.Clear().AddItem(a.sharedEditForm, 0, 1, true)
+	switch {
+	case entry.BankCard != nil:
+		value := *entry.BankCard
+		entry.BankCard = &value
+		a.sharedEditForm.AddInputField("Card number", value.Number, bankCardNumberLength, nil, func(number string) {
+			entry.BankCard.Number = number
+		})
+		a.sharedEditForm.AddInputField("Holder", value.Holder, bankCardHolderLength, nil, func(holder string) {
+			entry.BankCard.Holder = holder
+		})
+		a.sharedEditForm.AddInputField("CVV", value.Cvv, bankCardCVVLength, nil, func(cvv string) {
+			entry.BankCard.Cvv = cvv
+		})
+		a.sharedEditForm.AddInputField("Expiry (MM/YY)", value.Expiry, bankCardExpiryLength, nil, func(expiry string) {
+			entry.BankCard.Expiry = expiry
+		})
+		a.sharedEditForm.AddPasswordField("PIN", value.Pin, bankCardPINLength, maskCharacter, func(pin string) {
+			entry.BankCard.Pin = pin
+		})
+		a.sharedEditForm.AddInputField("Meta", value.Meta, metaLength, nil, func(meta string) {
+			entry.BankCard.Meta = meta
+		})
+	case entry.LoginPassword != nil:
+		value := *entry.LoginPassword
+		entry.LoginPassword = &value
+		a.sharedEditForm.AddInputField("Login", value.Login, loginLength, nil, func(login string) {
+			entry.LoginPassword.Login = login
+		})
+		a.sharedEditForm.AddInputField("Password", value.Password, passwordLength, nil, func(password string) {
+			entry.LoginPassword.Password = password
+		})
+		a.sharedEditForm.AddInputField("Meta", value.Meta, metaLength, nil, func(meta string) {
+			entry.LoginPassword.Meta = meta
+		})
+	case entry.TextBinary != nil:
+		value := *entry.TextBinary
+		entry.TextBinary = &value
+		a.sharedEditForm.AddInputField("Meta", value.Meta, metaLength, nil, func(meta string) {
+			entry.TextBinary.Meta = meta
+		})
+		a.noteEditor.SetText(value.Entry, false)
+		a.noteEditor.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+			if event.Key() == tcell.KeyEscape {
+				a.App.SetFocus(a.sharedEditForm)
+				return nil
+			}
+			return event
+		})
+		a.sharedEditForm.AddButton("Edit note", func() {
+			a.App.SetFocus(a.noteEditor)
+		})
+		a.sharedEditView.AddItem(a.noteEditor, 0, 2, false)
+	}
+	a.sharedEditForm.AddButton("Save", func() {
+		if entry.TextBinary != nil {
+			entry.TextBinary.Entry = a.noteEditor.GetText()
+		}
+		if err := a.storage.UpdateShared(entry); err != nil {
+			a.operationStatus.SetText(errorText(err))
+			pages.SwitchToPage(pageShared)
+			return
+		}
+		a.refreshSharedTable()
+		a.operationStatus.SetText(fmt.Sprintf("Updating shared entry %s: OK", entry.Identifier()))
+		pages.SwitchToPage(pageShared)
+	})
+	a.sharedEditForm.AddButton("Cancel", func() {
+		pages.SwitchToPage(pageShared)
+	})
+	return a.sharedEditForm
+}
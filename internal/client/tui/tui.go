@@ -3,13 +3,26 @@ package tui
 
 import (
 	"context"
+	breachV1 "dk-go-gophkeeper/internal/client/breach/v1"
+	"dk-go-gophkeeper/internal/client/generator"
+	"dk-go-gophkeeper/internal/client/health"
+	"dk-go-gophkeeper/internal/client/importer"
+	"dk-go-gophkeeper/internal/client/importer/modelimport"
+	importerV1 "dk-go-gophkeeper/internal/client/importer/v1"
 	"dk-go-gophkeeper/internal/client/storage"
+	"dk-go-gophkeeper/internal/client/storage/modelstorage"
+	"dk-go-gophkeeper/internal/client/storage/search"
 	"dk-go-gophkeeper/internal/client/tui/modeltui"
 	"dk-go-gophkeeper/internal/config"
+	"dk-go-gophkeeper/internal/validation"
+	"errors"
 	"fmt"
 	"log"
+	"os"
+	"sort"
+	"strconv"
 	"strings"
-	"unicode"
+	"time"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
@@ -31,9 +44,20 @@ const (
 	pageStoreTextBinary    = "store_text_binary"
 	pageStoreBankCard      = "store_bank_card"
 	pageRemove             = "remove"
+	pageImport             = "import"
 	pageRegister           = "register"
 	pageLogin              = "login"
 	pageGetData            = "get_data"
+	pageBrowse             = "browse"
+	pageLabels             = "labels"
+	pageFields             = "fields"
+	pageHealth             = "health"
+	pageShare              = "share"
+	pageShared             = "shared"
+	pageSharedEdit         = "shared_edit"
+	pageActivity           = "activity"
+	pageSessions           = "sessions"
+	pageEmergency          = "emergency"
 	pageResult             = "result"
 	pageMenu               = "menu"
 )
@@ -43,14 +67,32 @@ const (
 const (
 	identifierLength     = 20
 	metaLength           = 50
-	bankCardNumberLength = 16
+	bankCardNumberLength = 23
 	bankCardHolderLength = 20
-	bankCardCVVLength    = 3
+	bankCardCVVLength    = 4
+	bankCardExpiryLength = 7
+	bankCardPINLength    = 12
 	loginLength          = 20
 	passwordLength       = 20
-	textEntryLength      = 50
+	filePathLength       = 50
+	searchLength         = 50
+	generatedSizeLength  = 3
+	folderLength         = 50
+	tagsLength           = 50
+	healthOptionLength   = 4
+	emergencyWaitLength  = 2
 )
 
+// generator presets offered by the login/password form
+const (
+	presetPassword          = "Password"
+	presetPasswordNoSymbols = "Password without symbols and ambiguous characters"
+	presetPassphrase        = "Passphrase"
+)
+
+// rootFolder is the title of the folder tree root node listing entries of all folders
+const rootFolder = "All folders"
+
 // shared static attributes
 
 var flex = tview.NewFlex()
@@ -74,10 +116,17 @@ var menu = tview.NewFlex().
 	AddItem(tview.NewBox(), 0, 1, false).
 	AddItem(buttonRegister, 0, 1, false)
 var buttonStoreLoginPassword = tview.NewButton("Add login/password item")
-var buttonStoreTextBinary = tview.NewButton("Add text/binary item")
+var buttonStoreTextBinary = tview.NewButton("Add secure note")
 var buttonStoreBankCard = tview.NewButton("Add bank card item")
 var buttonGetData = tview.NewButton("Get item")
+var buttonBrowse = tview.NewButton("Browse items")
 var buttonRemove = tview.NewButton("Remove item")
+var buttonImport = tview.NewButton("Import items")
+var buttonHealth = tview.NewButton("Password health")
+var buttonShared = tview.NewButton("Shared with me")
+var buttonActivity = tview.NewButton("Account activity")
+var buttonSessions = tview.NewButton("Sessions and devices")
+var buttonEmergency = tview.NewButton("Emergency access")
 var buttonBackToMainScreen = tview.NewButton("Back to menu")
 var input = tview.NewFlex().SetDirection(tview.FlexRow).
 	AddItem(buttonStoreLoginPassword, 0, 10, false).
@@ -88,13 +137,28 @@ var input = tview.NewFlex().SetDirection(tview.FlexRow).
 	AddItem(tview.NewBox(), 0, 2, false).
 	AddItem(buttonGetData, 0, 10, false).
 	AddItem(tview.NewBox(), 0, 2, false).
-	AddItem(buttonRemove, 0, 10, false)
+	AddItem(buttonBrowse, 0, 10, false).
+	AddItem(tview.NewBox(), 0, 2, false).
+	AddItem(buttonRemove, 0, 10, false).
+	AddItem(tview.NewBox(), 0, 2, false).
+	AddItem(buttonImport, 0, 10, false).
+	AddItem(tview.NewBox(), 0, 2, false).
+	AddItem(buttonHealth, 0, 10, false).
+	AddItem(tview.NewBox(), 0, 2, false).
+	AddItem(buttonShared, 0, 10, false).
+	AddItem(tview.NewBox(), 0, 2, false).
+	AddItem(buttonActivity, 0, 10, false).
+	AddItem(tview.NewBox(), 0, 2, false).
+	AddItem(buttonSessions, 0, 10, false).
+	AddItem(tview.NewBox(), 0, 2, false).
+	AddItem(buttonEmergency, 0, 10, false)
 var body = tview.NewFlex().AddItem(input, 0, 1, false)
 
 // App defines attributes and methods of an App instance.
 type App struct {
 	App                    *tview.Application
 	storage                storage.DataStorage
+	importer               importer.Importer
 	cancel                 context.CancelFunc
 	registerLoginDetails   modeltui.RegisterLogin
 	registerForm           *tview.Form
@@ -103,10 +167,36 @@ type App struct {
 	storeBankCardForm      *tview.Form
 	textsOrBinaries        []modeltui.TextOrBinary
 	storeTextOrBinaryForm  *tview.Form
+	noteEditor             *tview.TextArea
 	loginsAndPasswords     []modeltui.LoginAndPassword
 	storeLoginPasswordForm *tview.Form
 	removeForm             *tview.Form
 	retrieveDataPieceForm  *tview.Form
+	importForm             *tview.Form
+	healthForm             *tview.Form
+	browseForm             *tview.Form
+	browseTable            *tview.Table
+	browseDetail           *tview.TextView
+	browseQuery            modeltui.Browse
+	browseTree             *tview.TreeView
+	labelsForm             *tview.Form
+	fieldsForm             *tview.Form
+	shareForm              *tview.Form
+	shareRecipients        *tview.TextView
+	sharedForm             *tview.Form
+	sharedTable            *tview.Table
+	sharedDetail           *tview.TextView
+	sharedEditForm         *tview.Form
+	sharedEditView         *tview.Flex
+	activityForm           *tview.Form
+	activityTable          *tview.Table
+	activityOldest         int64
+	sessionsForm           *tview.Form
+	sessionsTable          *tview.Table
+	emergencyForm          *tview.Form
+	emergencyTable         *tview.Table
+	revealConcealed        bool
+	generator              *generator.Generator
 	loginStatus            *tview.TextView
 	operationStatus        *tview.TextView
 	result                 *tview.TextView
@@ -128,8 +218,11 @@ func (a *App) addRetrieveDataPieceForm() *tview.Form {
 	a.retrieveDataPieceForm.AddDropDown("DB type", []string{a.cfg.BankCardDB, a.cfg.LoginPasswordDB, a.cfg.TextBinaryDB}, 0, func(db string, idx int) {
 		query.Db = db
 	})
+	a.retrieveDataPieceForm.AddCheckbox("Reveal concealed fields", false, func(checked bool) {
+		query.Reveal = checked
+	})
 	a.retrieveDataPieceForm.AddButton("Get", func() {
-		result, err := a.storage.Get(query.Identifier, query.Db)
+		result, err := a.describe(query.Identifier, query.Db, query.Reveal)
 		if err != nil {
 			a.operationStatus.SetText(err.Error())
 			pages.SwitchToPage("menu")
@@ -145,6 +238,312 @@ func (a *App) addRetrieveDataPieceForm() *tview.Form {
 	return a.retrieveDataPieceForm
 }
 
+// addBrowseForm defines form behavior and its contents, the list of entries is refreshed on every keystroke.
+func (a *App) addBrowseForm() *tview.Form {
+	orders := []modelstorage.Order{modelstorage.OrderRelevance, modelstorage.OrderIdentifier, modelstorage.OrderDb}
+	options := make([]string, len(orders))
+	current := 0
+	for i, order := range orders {
+		options[i] = string(order)
+		if order == a.browseQuery.Order {
+			current = i
+		}
+	}
+	a.browseForm.AddInputField("Search", a.browseQuery.Query, searchLength, nil, func(query string) {
+		a.browseQuery.Query = query
+		a.refreshBrowseTable()
+	})
+	a.browseForm.AddDropDown("Sort by", options, current, func(option string, idx int) {
+		a.browseQuery.Order = modelstorage.Order(option)
+		a.refreshBrowseTable()
+	})
+	a.browseForm.AddInputField("Tags", a.browseQuery.Tags, tagsLength, nil, func(tags string) {
+		a.browseQuery.Tags = tags
+		a.refreshBrowseTable()
+	})
+	a.browseForm.AddCheckbox("Favorites only", a.browseQuery.FavoritesOnly, func(checked bool) {
+		a.browseQuery.FavoritesOnly = checked
+		a.refreshBrowseTable()
+	})
+	a.browseForm.AddButton("Folders", func() {
+		a.App.SetFocus(a.browseTree)
+	})
+	a.browseForm.AddButton("Entries", func() {
+		a.App.SetFocus(a.browseTable)
+	})
+	a.browseForm.AddButton("Labels", func() {
+		summary, ok := a.selectedSummary()
+		if !ok {
+			a.operationStatus.SetText("No entry selected")
+			return
+		}
+		a.labelsForm.Clear(true)
+		a.addLabelsForm(summary)
+		pages.SwitchToPage(pageLabels)
+	})
+	a.browseForm.AddButton("Fields", func() {
+		summary, ok := a.selectedSummary()
+		if !ok {
+			a.operationStatus.SetText("No entry selected")
+			return
+		}
+		a.fieldsForm.Clear(true)
+		if err := a.addFieldsForm(summary); err != nil {
+			a.operationStatus.SetText(err.Error())
+			return
+		}
+		pages.SwitchToPage(pageFields)
+	})
+	a.browseForm.AddButton("Share", func() {
+		summary, ok := a.selectedSummary()
+		if !ok {
+			a.operationStatus.SetText("No entry selected")
+			return
+		}
+		a.shareForm.Clear(true)
+		a.addShareForm(summary)
+		pages.SwitchToPage(pageShare)
+	})
+	a.browseForm.AddButton("Reveal", nil)
+	reveal := a.browseForm.GetButton(a.browseForm.GetButtonCount() - 1)
+	reveal.SetSelectedFunc(func() {
+		a.revealConcealed = !a.revealConcealed
+		if a.revealConcealed {
+			reveal.SetLabel("Conceal")
+		} else {
+			reveal.SetLabel("Reveal")
+		}
+		row, _ := a.browseTable.GetSelection()
+		a.showBrowseDetail(row)
+	})
+	a.browseForm.AddButton("Back", func() {
+		pages.SwitchToPage(pageMenu)
+	})
+	a.browseForm.SetCancelFunc(func() {
+		pages.SwitchToPage(pageMenu)
+	})
+	return a.browseForm
+}
+
+// refreshBrowseTree rebuilds the folder tree from folders of all entries keeping the current folder selected.
+func (a *App) refreshBrowseTree() {
+	root := tview.NewTreeNode(rootFolder).SetReference("").SetColor(tcell.ColorGreen)
+	nodes := map[string]*tview.TreeNode{"": root}
+	var folders []string
+	for _, summary := range a.storage.Search("", modelstorage.OrderIdentifier) {
+		if summary.Folder != "" {
+			folders = append(folders, summary.Folder)
+		}
+	}
+	sort.Strings(folders)
+	current := root
+	for _, folder := range folders {
+		parent := root
+		segments := strings.Split(folder, "/")
+		for i, segment := range segments {
+			path := strings.Join(segments[:i+1], "/")
+			node, ok := nodes[strings.ToLower(path)]
+			if !ok {
+				node = tview.NewTreeNode(segment).SetReference(path)
+				nodes[strings.ToLower(path)] = node
+				parent.AddChild(node)
+			}
+			if strings.EqualFold(path, a.browseQuery.Folder) {
+				current = node
+			}
+			parent = node
+		}
+	}
+	a.browseTree.SetRoot(root).SetCurrentNode(current)
+}
+
+// refreshBrowseTable fills the list of entries with search results within the chosen folder having the chosen tags
+// and selects the best match.
+func (a *App) refreshBrowseTable() {
+	a.browseTable.Clear()
+	for col, title := range []string{"Type", "Identifier", "Folder", "Tags", "Meta"} {
+		a.browseTable.SetCell(0, col, tview.NewTableCell(title).SetTextColor(tcell.ColorGreen).SetSelectable(false))
+	}
+	tags := strings.Split(a.browseQuery.Tags, ",")
+	filtered := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			filtered = append(filtered, tag)
+		}
+	}
+	summaries := make([]modelstorage.Summary, 0)
+	for _, summary := range a.storage.Search(a.browseQuery.Query, a.browseQuery.Order) {
+		if search.MatchLabels(summary.Labels, a.browseQuery.Folder, filtered, a.browseQuery.FavoritesOnly) {
+			summaries = append(summaries, summary)
+		}
+	}
+	for i, summary := range summaries {
+		identifier := summary.Identifier
+		if summary.Favorite {
+			identifier = "★ " + identifier
+		}
+		a.browseTable.SetCell(i+1, 0, tview.NewTableCell(summary.Db).SetReference(summary))
+		a.browseTable.SetCell(i+1, 1, tview.NewTableCell(identifier).SetExpansion(1))
+		a.browseTable.SetCell(i+1, 2, tview.NewTableCell(summary.Folder).SetExpansion(1))
+		a.browseTable.SetCell(i+1, 3, tview.NewTableCell(strings.Join(summary.Tags, ", ")).SetExpansion(1))
+		a.browseTable.SetCell(i+1, 4, tview.NewTableCell(summary.Meta).SetExpansion(2).SetMaxWidth(metaLength))
+	}
+	a.operationStatus.SetText(fmt.Sprintf("Browsing: %d entries found", len(summaries)))
+	if len(summaries) == 0 {
+		a.browseDetail.SetText("No entries found")
+		return
+	}
+	a.browseTable.Select(1, 0).ScrollToBeginning()
+	a.showBrowseDetail(1)
+}
+
+// addLabelsForm defines form behavior and its contents, labels of the given entry are replaced on saving.
+func (a *App) addLabelsForm(summary modelstorage.Summary) *tview.Form {
+	labels := modeltui.Labels{
+		Identifier: summary.Identifier,
+		Db:         summary.Db,
+		Folder:     summary.Folder,
+		Tags:       strings.Join(summary.Tags, ", "),
+		Favorite:   summary.Favorite,
+	}
+	a.labelsForm.SetTitle(fmt.Sprintf("Labels of %s", summary.Identifier)).SetBorder(true)
+	a.labelsForm.AddInputField("Folder", labels.Folder, folderLength, nil, func(folder string) {
+		labels.Folder = folder
+	})
+	a.labelsForm.AddInputField("Tags", labels.Tags, tagsLength, nil, func(tags string) {
+		labels.Tags = tags
+	})
+	a.labelsForm.AddCheckbox("Favorite", labels.Favorite, func(checked bool) {
+		labels.Favorite = checked
+	})
+	a.labelsForm.AddButton("Save", func() {
+		err := a.storage.SetLabels(labels.Identifier, labels.Db, modelstorage.Labels{Folder: labels.Folder, Tags: []string{labels.Tags}, Favorite: labels.Favorite})
+		if err != nil {
+			a.operationStatus.SetText(err.Error())
+			pages.SwitchToPage(pageBrowse)
+			return
+		}
+		a.refreshBrowseTree()
+		a.refreshBrowseTable()
+		a.operationStatus.SetText("Setting labels: OK")
+		pages.SwitchToPage(pageBrowse)
+	})
+	a.labelsForm.AddButton("Cancel", func() {
+		pages.SwitchToPage(pageBrowse)
+	})
+	return a.labelsForm
+}
+
+// selectedSummary returns the summary of the entry selected in the list of entries.
+func (a *App) selectedSummary() (modelstorage.Summary, bool) {
+	row, _ := a.browseTable.GetSelection()
+	summary, ok := a.browseTable.GetCell(row, 0).GetReference().(modelstorage.Summary)
+	return summary, ok
+}
+
+// describe renders an entry with values of concealed custom fields masked unless revealed.
+func (a *App) describe(identifier, db string, reveal bool) (string, error) {
+	batch := a.storage.Export()
+	switch db {
+	case a.cfg.BankCardDB:
+		for _, value := range batch.BankCards {
+			if value.Identifier == identifier {
+				pin := value.Pin
+				if pin != "" && !reveal {
+					pin = concealedValue
+				}
+				fields := [][2]string{{"Identifier", value.Identifier}, {"Number", value.Number}, {"Brand", string(validation.DetectBrand(value.Number))},
+					{"Holder", value.Holder}, {"CVV", value.Cvv}, {"Expiry", value.Expiry}, {"PIN", pin}, {"Meta", value.Meta}}
+				if warning := validation.ExpiryWarning(value.Expiry, time.Now()); warning != "" {
+					fields = append(fields, [2]string{"Warning", warning})
+				}
+				return formatEntry(db, fields, value.Labels, value.Fields, reveal), nil
+			}
+		}
+	case a.cfg.LoginPasswordDB:
+		for _, value := range batch.LoginsPasswords {
+			if value.Identifier == identifier {
+				fields := [][2]string{{"Identifier", value.Identifier}, {"Login", value.Login}, {"Password", value.Password}, {"Meta", value.Meta}}
+				return formatEntry(db, fields, value.Labels, value.Fields, reveal), nil
+			}
+		}
+	case a.cfg.TextBinaryDB:
+		for _, value := range batch.TextsBinaries {
+			if value.Identifier == identifier {
+				return formatNote(db, value, reveal), nil
+			}
+		}
+	default:
+		return "", fmt.Errorf("invalid db %s", db)
+	}
+	return "", fmt.Errorf("entry ID %s in %s storage does not exist", identifier, db)
+}
+
+// customFields returns custom fields of an entry.
+func (a *App) customFields(identifier, db string) ([]modelstorage.CustomField, error) {
+	batch := a.storage.Export()
+	switch db {
+	case a.cfg.BankCardDB:
+		for _, value := range batch.BankCards {
+			if value.Identifier == identifier {
+				return value.Fields, nil
+			}
+		}
+	case a.cfg.LoginPasswordDB:
+		for _, value := range batch.LoginsPasswords {
+			if value.Identifier == identifier {
+				return value.Fields, nil
+			}
+		}
+	case a.cfg.TextBinaryDB:
+		for _, value := range batch.TextsBinaries {
+			if value.Identifier == identifier {
+				return value.Fields, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("entry ID %s in %s storage does not exist", identifier, db)
+}
+
+// addFieldsForm defines form behavior and its contents, custom fields of the given entry are replaced on saving.
+func (a *App) addFieldsForm(summary modelstorage.Summary) error {
+	fields, err := a.customFields(summary.Identifier, summary.Db)
+	if err != nil {
+		return err
+	}
+	a.fieldsForm.SetTitle(fmt.Sprintf("Custom fields of %s", summary.Identifier)).SetBorder(true)
+	editor := newFieldsEditor(a.fieldsForm, fields)
+	editor.addButtons()
+	a.fieldsForm.AddButton("Save", func() {
+		if err := a.storage.SetFields(summary.Identifier, summary.Db, editor.result()); err != nil {
+			a.operationStatus.SetText(err.Error())
+			pages.SwitchToPage(pageBrowse)
+			return
+		}
+		a.refreshBrowseTable()
+		a.operationStatus.SetText("Setting custom fields: OK")
+		pages.SwitchToPage(pageBrowse)
+	})
+	a.fieldsForm.AddButton("Cancel", func() {
+		pages.SwitchToPage(pageBrowse)
+	})
+	return nil
+}
+
+// showBrowseDetail displays the entry in the given row of the list of entries.
+func (a *App) showBrowseDetail(row int) {
+	summary, ok := a.browseTable.GetCell(row, 0).GetReference().(modelstorage.Summary)
+	if !ok {
+		return
+	}
+	result, err := a.describe(summary.Identifier, summary.Db, a.revealConcealed)
+	if err != nil {
+		a.browseDetail.SetText(tview.Escape(err.Error()))
+		return
+	}
+	a.browseDetail.SetText(result).ScrollToBeginning()
+}
+
 // addRemovalForm defines form behavior and its contents.
 func (a *App) addRemovalForm() *tview.Form {
 	removal := modeltui.Removal{}
@@ -174,6 +573,95 @@ func (a *App) addRemovalForm() *tview.Form {
 	return a.removeForm
 }
 
+// addImportForm defines form behavior and its contents.
+func (a *App) addImportForm() *tview.Form {
+	query := modeltui.Import{}
+	a.importForm.AddInputField("File path", "", filePathLength, nil, func(path string) {
+		query.Path = strings.TrimSpace(path)
+	})
+	a.importForm.AddDropDown("Format", importerV1.Formats(), 0, func(format string, idx int) {
+		query.Format = format
+	})
+	run := func(dryRun bool) {
+		file, err := os.Open(query.Path)
+		if err != nil {
+			a.operationStatus.SetText(err.Error())
+			pages.SwitchToPage("menu")
+			return
+		}
+		defer file.Close()
+		var report modelimport.Report
+		if dryRun {
+			report, err = a.importer.Preview(query.Format, file)
+		} else {
+			report, err = a.importer.Import(query.Format, file)
+		}
+		if err != nil {
+			a.operationStatus.SetText(err.Error())
+			pages.SwitchToPage("menu")
+			return
+		}
+		a.operationStatus.SetText(fmt.Sprintf("Importing data: %d new, %d duplicates, %d failed", len(report.New), len(report.Duplicates), len(report.Failed)))
+		a.result.SetText(tview.Escape(formatImportReport(report)))
+		pages.SwitchToPage("result")
+	}
+	a.importForm.AddButton("Preview", func() {
+		run(true)
+	})
+	a.importForm.AddButton("Import", func() {
+		run(false)
+	})
+	a.importForm.AddButton("Cancel", func() {
+		pages.SwitchToPage("menu")
+	})
+	return a.importForm
+}
+
+// addHealthForm defines form behavior and its contents.
+func (a *App) addHealthForm() *tview.Form {
+	query := modeltui.Health{MinEntropy: int(health.DefaultOptions.MinEntropy), MaxAgeDays: health.DefaultOptions.MaxAgeDays, Corpus: a.cfg.BreachCorpus}
+	a.healthForm.AddInputField("Minimum entropy, bits", strconv.Itoa(query.MinEntropy), healthOptionLength, tview.InputFieldInteger, func(bits string) {
+		query.MinEntropy, _ = strconv.Atoi(bits)
+	})
+	a.healthForm.AddInputField("Maximum password age, days", strconv.Itoa(query.MaxAgeDays), healthOptionLength, tview.InputFieldInteger, func(days string) {
+		query.MaxAgeDays, _ = strconv.Atoi(days)
+	})
+	a.healthForm.AddInputField("Breach corpus path", query.Corpus, filePathLength, nil, func(path string) {
+		query.Corpus = strings.TrimSpace(path)
+	})
+	a.healthForm.AddButton("Audit", func() {
+		if query.MaxAgeDays < 0 {
+			a.operationStatus.SetText("Maximum password age cannot be negative")
+			pages.SwitchToPage("menu")
+			return
+		}
+		options := health.Options{MinEntropy: float64(query.MinEntropy), MaxAgeDays: query.MaxAgeDays}
+		if query.Corpus != "" {
+			checker, err := breachV1.InitOfflineChecker(query.Corpus, a.logger)
+			if err != nil {
+				a.operationStatus.SetText(err.Error())
+				pages.SwitchToPage("menu")
+				return
+			}
+			defer checker.Close()
+			options.Checker = checker
+		}
+		report, err := health.Audit(a.storage.Export(), options)
+		if err != nil {
+			a.operationStatus.SetText(err.Error())
+			pages.SwitchToPage("menu")
+			return
+		}
+		a.operationStatus.SetText(fmt.Sprintf("Password health: %d weak, %d reused, %d old, %d breached", report.Weak, report.Reused, report.Old, report.Breached))
+		a.result.SetText(tview.Escape(health.Format(report))).ScrollToBeginning()
+		pages.SwitchToPage("result")
+	})
+	a.healthForm.AddButton("Cancel", func() {
+		pages.SwitchToPage("menu")
+	})
+	return a.healthForm
+}
+
 // addLoginPasswordForm defines form behavior and its contents.
 func (a *App) addLoginPasswordForm() *tview.Form {
 	loginAndPassword := modeltui.LoginAndPassword{}
@@ -204,10 +692,43 @@ func (a *App) addLoginPasswordForm() *tview.Form {
 	a.storeLoginPasswordForm.AddInputField("Meta", "", metaLength, nil, func(meta string) {
 		loginAndPassword.Meta = meta
 	})
+	generate := modeltui.Generate{Preset: presetPassword, Size: generator.DefaultPolicy.Length}
+	var sizeInput *tview.InputField
+	a.storeLoginPasswordForm.AddDropDown("Generator", []string{presetPassword, presetPasswordNoSymbols, presetPassphrase}, 0, func(preset string, idx int) {
+		generate.Preset = preset
+		// the size input is missing while the initial option is being selected
+		if sizeInput == nil {
+			return
+		}
+		if preset == presetPassphrase {
+			sizeInput.SetText(strconv.Itoa(generator.DefaultPassphrasePolicy.Words))
+		} else {
+			sizeInput.SetText(strconv.Itoa(generator.DefaultPolicy.Length))
+		}
+	})
+	a.storeLoginPasswordForm.AddInputField("Length or words", strconv.Itoa(generate.Size), generatedSizeLength, tview.InputFieldInteger, func(size string) {
+		generate.Size, _ = strconv.Atoi(size)
+	})
+	sizeInput = a.storeLoginPasswordForm.GetFormItemByLabel("Length or words").(*tview.InputField)
+	passwordInput := a.storeLoginPasswordForm.GetFormItemByLabel("Password").(*tview.InputField)
+	a.storeLoginPasswordForm.AddButton("Generate", func() {
+		password, entropy, err := a.generate(generate)
+		if err != nil {
+			a.operationStatus.SetText(err.Error())
+			return
+		}
+		passwordInput.SetText(password)
+		a.operationStatus.SetText(fmt.Sprintf("Generated password: estimated entropy %.1f bits", entropy))
+	})
+	fields := newFieldsEditor(a.storeLoginPasswordForm, nil)
+	fields.addButtons()
 	a.storeLoginPasswordForm.AddButton("Submit", func() {
 		err := a.storage.AddLoginPassword(loginAndPassword.Identifier, loginAndPassword.Login, loginAndPassword.Password, loginAndPassword.Meta)
+		if custom := fields.result(); err == nil && len(custom) > 0 {
+			err = a.storage.SetFields(loginAndPassword.Identifier, a.cfg.LoginPasswordDB, custom)
+		}
 		if err != nil {
-			a.operationStatus.SetText(err.Error())
+			a.operationStatus.SetText(errorText(err))
 		} else {
 			a.operationStatus.SetText("Adding login/password: OK")
 		}
@@ -219,7 +740,29 @@ func (a *App) addLoginPasswordForm() *tview.Form {
 	return a.storeLoginPasswordForm
 }
 
-// addTextOrBinaryForm defines form behavior and its contents.
+// generate produces a random password or passphrase according to the chosen preset and returns its entropy estimate.
+func (a *App) generate(generate modeltui.Generate) (string, float64, error) {
+	switch generate.Preset {
+	case presetPassphrase:
+		policy := generator.DefaultPassphrasePolicy
+		policy.Words = generate.Size
+		password, err := a.generator.Passphrase(policy)
+		return password, policy.Entropy(), err
+	case presetPasswordNoSymbols:
+		policy := generator.DefaultPolicy
+		policy.Length, policy.Symbols, policy.ExcludeAmbiguous = generate.Size, false, true
+		password, err := a.generator.Password(policy)
+		return password, policy.Entropy(), err
+	default:
+		policy := generator.DefaultPolicy
+		policy.Length = generate.Size
+		password, err := a.generator.Password(policy)
+		return password, policy.Entropy(), err
+	}
+}
+
+// addTextOrBinaryForm defines form behavior and its contents, the note itself is written in the multi-line editor
+// below the form.
 func (a *App) addTextOrBinaryForm() *tview.Form {
 	textOrBinary := modeltui.TextOrBinary{}
 	a.storeTextOrBinaryForm.AddInputField("Identifier", "", identifierLength, nil, func(id string) {
@@ -230,18 +773,32 @@ func (a *App) addTextOrBinaryForm() *tview.Form {
 			textOrBinary.Identifier = id
 		}
 	})
-	a.storeTextOrBinaryForm.AddInputField("Input", "", textEntryLength, nil, func(entry string) {
-		textOrBinary.Entry = entry
-	})
 	a.storeTextOrBinaryForm.AddInputField("Meta", "", metaLength, nil, func(meta string) {
 		textOrBinary.Meta = meta
 	})
+	a.noteEditor.SetText("", false)
+	a.noteEditor.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEscape {
+			a.App.SetFocus(a.storeTextOrBinaryForm)
+			return nil
+		}
+		return event
+	})
+	a.storeTextOrBinaryForm.AddButton("Edit note", func() {
+		a.App.SetFocus(a.noteEditor)
+	})
+	fields := newFieldsEditor(a.storeTextOrBinaryForm, nil)
+	fields.addButtons()
 	a.storeTextOrBinaryForm.AddButton("Submit", func() {
+		textOrBinary.Entry = a.noteEditor.GetText()
 		err := a.storage.AddTextBinary(textOrBinary.Identifier, textOrBinary.Entry, textOrBinary.Meta)
+		if custom := fields.result(); err == nil && len(custom) > 0 {
+			err = a.storage.SetFields(textOrBinary.Identifier, a.cfg.TextBinaryDB, custom)
+		}
 		if err != nil {
-			a.operationStatus.SetText(err.Error())
+			a.operationStatus.SetText(errorText(err))
 		} else {
-			a.operationStatus.SetText("Adding text/binary: OK")
+			a.operationStatus.SetText("Adding secure note: OK")
 		}
 		pages.SwitchToPage("menu")
 	})
@@ -263,12 +820,7 @@ func (a *App) addBankCardForm() *tview.Form {
 		}
 	})
 	a.storeBankCardForm.AddInputField("Number", "", bankCardNumberLength, nil, func(number string) {
-		if len(number) != 16 && !isInt(number) {
-			a.operationStatus.SetText("Bank card number must be a 16-digit code")
-			pages.SwitchToPage("menu")
-		} else {
-			bankCard.Number = number
-		}
+		bankCard.Number = number
 	})
 	a.storeBankCardForm.AddInputField("Holder", "", bankCardHolderLength, nil, func(holder string) {
 		if strings.ReplaceAll(holder, " ", "") == "" {
@@ -279,22 +831,30 @@ func (a *App) addBankCardForm() *tview.Form {
 		}
 	})
 	a.storeBankCardForm.AddInputField("CVV", "", bankCardCVVLength, nil, func(cvv string) {
-		if len(cvv) != 3 && !isInt(cvv) {
-			a.operationStatus.SetText("Bank card CVV must be a 3-digit code")
-			pages.SwitchToPage("menu")
-		} else {
-			bankCard.Number = cvv
-		}
+		bankCard.Cvv = cvv
+	})
+	a.storeBankCardForm.AddInputField("Expiry (MM/YY)", "", bankCardExpiryLength, nil, func(expiry string) {
+		bankCard.Expiry = expiry
+	})
+	a.storeBankCardForm.AddPasswordField("PIN (optional)", "", bankCardPINLength, '*', func(pin string) {
+		bankCard.Pin = pin
 	})
 	a.storeBankCardForm.AddInputField("Meta", "", metaLength, nil, func(meta string) {
 		bankCard.Meta = meta
 	})
+	fields := newFieldsEditor(a.storeBankCardForm, nil)
+	fields.addButtons()
 	a.storeBankCardForm.AddButton("Submit", func() {
-		err := a.storage.AddBankCard(bankCard.Identifier, bankCard.Number, bankCard.Holder, bankCard.Cvv, bankCard.Meta)
+		err := a.storage.AddBankCard(bankCard.Identifier, bankCard.Number, bankCard.Holder, bankCard.Cvv, bankCard.Expiry, bankCard.Pin, bankCard.Meta)
+		if custom := fields.result(); err == nil && len(custom) > 0 {
+			err = a.storage.SetFields(bankCard.Identifier, a.cfg.BankCardDB, custom)
+		}
 		if err != nil {
-			a.operationStatus.SetText(err.Error())
+			a.operationStatus.SetText(errorText(err))
+		} else if warning := validation.ExpiryWarning(bankCard.Expiry, time.Now()); warning != "" {
+			a.operationStatus.SetText(fmt.Sprintf("Adding %s bank card: OK, %s", validation.DetectBrand(bankCard.Number), warning))
 		} else {
-			a.operationStatus.SetText("Adding bank card: OK")
+			a.operationStatus.SetText(fmt.Sprintf("Adding %s bank card: OK", validation.DetectBrand(bankCard.Number)))
 		}
 		pages.SwitchToPage("menu")
 	})
@@ -373,12 +933,13 @@ func (a *App) addLoginForm() *tview.Form {
 }
 
 // InitTUI initializes a TUI instance and defines non-static attributes.
-func InitTUI(cancel context.CancelFunc, storage storage.DataStorage, logger *zerolog.Logger, cfg *config.Config) App {
+func InitTUI(cancel context.CancelFunc, storage storage.DataStorage, importer importer.Importer, logger *zerolog.Logger, cfg *config.Config) App {
 	logger.Print("Attempting to initialize TUI")
 	var app = tview.NewApplication()
 	application := App{
 		App:                    app,
 		storage:                storage,
+		importer:               importer,
 		cancel:                 cancel,
 		registerLoginDetails:   modeltui.RegisterLogin{},
 		registerForm:           tview.NewForm(),
@@ -387,13 +948,37 @@ func InitTUI(cancel context.CancelFunc, storage storage.DataStorage, logger *zer
 		storeBankCardForm:      tview.NewForm(),
 		textsOrBinaries:        make([]modeltui.TextOrBinary, 0),
 		storeTextOrBinaryForm:  tview.NewForm(),
+		noteEditor:             tview.NewTextArea().SetPlaceholder("Write the note in Markdown, press Esc to return to the form"),
 		loginsAndPasswords:     make([]modeltui.LoginAndPassword, 0),
 		storeLoginPasswordForm: tview.NewForm(),
 		removeForm:             tview.NewForm(),
 		retrieveDataPieceForm:  tview.NewForm(),
+		importForm:             tview.NewForm(),
+		healthForm:             tview.NewForm(),
+		browseForm:             tview.NewForm(),
+		browseTable:            tview.NewTable().SetSelectable(true, false).SetFixed(1, 0),
+		browseDetail:           tview.NewTextView().SetDynamicColors(true).SetScrollable(true).SetWrap(true),
+		browseQuery:            modeltui.Browse{Order: modelstorage.OrderRelevance},
+		browseTree:             tview.NewTreeView(),
+		labelsForm:             tview.NewForm(),
+		fieldsForm:             tview.NewForm(),
+		shareForm:              tview.NewForm(),
+		shareRecipients:        tview.NewTextView().SetScrollable(true).SetWrap(true),
+		sharedForm:             tview.NewForm(),
+		sharedTable:            tview.NewTable().SetSelectable(true, false).SetFixed(1, 0),
+		sharedDetail:           tview.NewTextView().SetDynamicColors(true).SetScrollable(true).SetWrap(true),
+		sharedEditForm:         tview.NewForm(),
+		sharedEditView:         tview.NewFlex().SetDirection(tview.FlexRow),
+		activityForm:           tview.NewForm(),
+		activityTable:          tview.NewTable().SetSelectable(true, false).SetFixed(1, 0),
+		sessionsForm:           tview.NewForm(),
+		sessionsTable:          tview.NewTable().SetSelectable(true, false).SetFixed(1, 0),
+		emergencyForm:          tview.NewForm(),
+		emergencyTable:         tview.NewTable().SetSelectable(true, false).SetFixed(1, 0),
+		generator:              generator.InitGenerator(),
 		loginStatus:            tview.NewTextView().SetText("Logged in as: NA").SetTextAlign(1).SetScrollable(true).SetTextColor(tcell.ColorRed),
 		operationStatus:        tview.NewTextView().SetText("Nothing to report yet").SetTextAlign(1).SetScrollable(true).SetTextColor(tcell.ColorRed),
-		result:                 tview.NewTextView().SetText("Nothing was requested yet").SetTextAlign(1).SetScrollable(true),
+		result:                 tview.NewTextView().SetText("Nothing was requested yet").SetTextAlign(1).SetDynamicColors(true).SetScrollable(true),
 		logger:                 logger,
 		cfg:                    cfg,
 	}
@@ -423,6 +1008,40 @@ func (a *App) Run() {
 		a.addRemovalForm()
 		pages.SwitchToPage(pageRemove)
 	})
+	buttonImport.SetSelectedFunc(func() {
+		a.importForm.Clear(true)
+		a.addImportForm()
+		pages.SwitchToPage(pageImport)
+	})
+	buttonHealth.SetSelectedFunc(func() {
+		a.healthForm.Clear(true)
+		a.addHealthForm()
+		pages.SwitchToPage(pageHealth)
+	})
+	buttonShared.SetSelectedFunc(func() {
+		a.sharedForm.Clear(true)
+		a.addSharedForm()
+		a.refreshSharedTable()
+		pages.SwitchToPage(pageShared)
+	})
+	buttonActivity.SetSelectedFunc(func() {
+		a.activityForm.Clear(true)
+		a.addActivityForm()
+		a.refreshActivityTable(0)
+		pages.SwitchToPage(pageActivity)
+	})
+	buttonSessions.SetSelectedFunc(func() {
+		a.sessionsForm.Clear(true)
+		a.addSessionsForm()
+		a.refreshSessionsTable()
+		pages.SwitchToPage(pageSessions)
+	})
+	buttonEmergency.SetSelectedFunc(func() {
+		a.emergencyForm.Clear(true)
+		a.addEmergencyForm()
+		a.refreshEmergencyTable()
+		pages.SwitchToPage(pageEmergency)
+	})
 	buttonRegister.SetSelectedFunc(func() {
 		a.registerForm.Clear(true)
 		a.addRegisterForm()
@@ -450,10 +1069,97 @@ func (a *App) Run() {
 		a.addRetrieveDataPieceForm()
 		pages.SwitchToPage(pageGetData)
 	})
+	buttonBrowse.SetSelectedFunc(func() {
+		a.browseForm.Clear(true)
+		a.addBrowseForm()
+		a.refreshBrowseTree()
+		a.refreshBrowseTable()
+		pages.SwitchToPage(pageBrowse)
+	})
+	a.browseTree.SetSelectedFunc(func(node *tview.TreeNode) {
+		a.browseQuery.Folder, _ = node.GetReference().(string)
+		node.SetExpanded(!node.IsExpanded())
+		a.refreshBrowseTable()
+	})
+	a.browseTree.SetDoneFunc(func(key tcell.Key) {
+		a.App.SetFocus(a.browseTable)
+	})
+	a.browseTable.SetSelectionChangedFunc(func(row, column int) {
+		a.showBrowseDetail(row)
+	})
+	a.browseTable.SetSelectedFunc(func(row, column int) {
+		a.showBrowseDetail(row)
+		a.App.SetFocus(a.browseDetail)
+	})
+	a.browseTable.SetDoneFunc(func(key tcell.Key) {
+		a.App.SetFocus(a.browseForm)
+	})
+	a.browseDetail.SetDoneFunc(func(key tcell.Key) {
+		a.App.SetFocus(a.browseTable)
+	})
+	a.sharedTable.SetSelectionChangedFunc(func(row, column int) {
+		a.showSharedDetail(row)
+	})
+	a.sharedTable.SetDoneFunc(func(key tcell.Key) {
+		a.App.SetFocus(a.sharedForm)
+	})
+	a.activityTable.SetDoneFunc(func(key tcell.Key) {
+		a.App.SetFocus(a.activityForm)
+	})
+	a.sessionsTable.SetSelectedFunc(func(row, column int) {
+		a.App.SetFocus(a.sessionsForm)
+	})
+	a.sessionsTable.SetDoneFunc(func(key tcell.Key) {
+		a.App.SetFocus(a.sessionsForm)
+	})
+	a.emergencyTable.SetSelectedFunc(func(row, column int) {
+		a.App.SetFocus(a.emergencyForm)
+	})
+	a.emergencyTable.SetDoneFunc(func(key tcell.Key) {
+		a.App.SetFocus(a.emergencyForm)
+	})
 	buttonBackToMainScreen.SetSelectedFunc(func() {
 		pages.SwitchToPage(pageMenu)
 	})
 
+	a.browseTree.SetBorder(true).SetTitle("Folders")
+	a.browseDetail.SetBorder(true).SetTitle("Details")
+	a.shareRecipients.SetBorder(true).SetTitle("Shared with")
+	a.sharedDetail.SetBorder(true).SetTitle("Details")
+	a.noteEditor.SetBorder(true).SetTitle("Note (Markdown)")
+	browseView := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(a.browseForm, 0, 2, true).
+		AddItem(tview.NewFlex().
+			AddItem(a.browseTree, 0, 1, false).
+			AddItem(a.browseTable, 0, 2, false).
+			AddItem(a.browseDetail, 0, 1, false), 0, 8, false)
+
+	shareView := tview.NewFlex().
+		AddItem(a.shareForm, 0, 2, true).
+		AddItem(a.shareRecipients, 0, 1, false)
+
+	sharedView := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(a.sharedForm, 0, 1, true).
+		AddItem(tview.NewFlex().
+			AddItem(a.sharedTable, 0, 2, false).
+			AddItem(a.sharedDetail, 0, 1, false), 0, 8, false)
+
+	activityView := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(a.activityForm, 0, 1, true).
+		AddItem(a.activityTable, 0, 8, false)
+
+	sessionsView := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(a.sessionsForm, 0, 1, true).
+		AddItem(a.sessionsTable, 0, 8, false)
+
+	emergencyView := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(a.emergencyForm, 0, 2, true).
+		AddItem(a.emergencyTable, 0, 7, false)
+
+	noteView := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(a.storeTextOrBinaryForm, 0, 1, true).
+		AddItem(a.noteEditor, 0, 2, false)
+
 	resultView := tview.NewFlex().SetDirection(tview.FlexRow).
 		AddItem(a.result, 0, 9, false).
 		AddItem(buttonBackToMainScreen, 0, 1, false)
@@ -470,12 +1176,23 @@ func (a *App) Run() {
 
 	pages.AddPage(pageMenu, flex, true, true)
 	pages.AddPage(pageStoreLoginPassword, a.storeLoginPasswordForm, true, false)
-	pages.AddPage(pageStoreTextBinary, a.storeTextOrBinaryForm, true, false)
+	pages.AddPage(pageStoreTextBinary, noteView, true, false)
 	pages.AddPage(pageStoreBankCard, a.storeBankCardForm, true, false)
 	pages.AddPage(pageRegister, a.registerForm, true, false)
 	pages.AddPage(pageLogin, a.loginForm, true, false)
 	pages.AddPage(pageRemove, a.removeForm, true, false)
 	pages.AddPage(pageGetData, a.retrieveDataPieceForm, true, false)
+	pages.AddPage(pageImport, a.importForm, true, false)
+	pages.AddPage(pageHealth, a.healthForm, true, false)
+	pages.AddPage(pageBrowse, browseView, true, false)
+	pages.AddPage(pageLabels, a.labelsForm, true, false)
+	pages.AddPage(pageFields, a.fieldsForm, true, false)
+	pages.AddPage(pageShare, shareView, true, false)
+	pages.AddPage(pageShared, sharedView, true, false)
+	pages.AddPage(pageSharedEdit, a.sharedEditView, true, false)
+	pages.AddPage(pageActivity, activityView, true, false)
+	pages.AddPage(pageSessions, sessionsView, true, false)
+	pages.AddPage(pageEmergency, emergencyView, true, false)
 	pages.AddPage(pageResult, resultView, true, false)
 
 	a.logger.Info().Msg("Starting the TUI")
@@ -485,12 +1202,44 @@ func (a *App) Run() {
 	a.logger.Info().Msg("TUI closed, Run() function returned")
 }
 
-// isInt checks that any rune inside a string is a digit
-func isInt(s string) bool {
-	for _, c := range s {
-		if !unicode.IsDigit(c) {
-			return false
+// formatImportReport renders an import report as a human-readable text.
+func formatImportReport(report modelimport.Report) string {
+	var sb strings.Builder
+	mode := "import"
+	if report.DryRun {
+		mode = "dry run"
+	}
+	sb.WriteString(fmt.Sprintf("Format: %s (%s)\n\n", report.Format, mode))
+	sections := []struct {
+		title string
+		items []modelimport.Item
+	}{
+		{"New", report.New},
+		{"Duplicates", report.Duplicates},
+		{"Failed", report.Failed},
+	}
+	for _, section := range sections {
+		sb.WriteString(fmt.Sprintf("%s: %d\n", section.title, len(section.items)))
+		for _, item := range section.items {
+			line := fmt.Sprintf("  [%s] %s", item.Db, item.Identifier)
+			if item.Reason != "" {
+				line += ": " + item.Reason
+			}
+			sb.WriteString(line + "\n")
 		}
 	}
-	return true
+	return sb.String()
+}
+
+// errorText renders an error, field violations are listed field by field.
+func errorText(err error) string {
+	var violations validation.Errors
+	if !errors.As(err, &violations) {
+		return err.Error()
+	}
+	lines := make([]string, 0, len(violations))
+	for _, violation := range violations {
+		lines = append(lines, fmt.Sprintf("%s: %s", violation.Field, violation.Description))
+	}
+	return strings.Join(lines, "\n")
 }
@@ -10,14 +10,28 @@ import (
 
 // Config handles all constants and parameters.
 type Config struct {
-	ServerAddress   string `json:"server_address" env:"SERVER_ADDRESS"`
-	DatabaseDSN     string `json:"database_dsn" env:"DATABASE_DSN"`
-	UserKey         string `env:"USER_KEY" env-default:"jds__63h3_7ds"`
-	AuthBearerName  string `env:"BEARER_KEY" env-default:"token"`
-	BankCardDB      string `env:"BANK_CARD_DB" env-default:"bankCard"`
-	LoginPasswordDB string `env:"LOGIN_PASSWORD_DB" env-default:"loginPassword"`
-	TextBinaryDB    string `env:"TEXT_BINARY_DB" env-default:"textBinary"`
-	HandlersTO      int    `env:"HANDLERS_TO" env-default:"500"`
+	ServerAddress    string `json:"server_address" env:"SERVER_ADDRESS"`
+	DatabaseDSN      string `json:"database_dsn" env:"DATABASE_DSN"`
+	UserKey          string `env:"USER_KEY" env-default:"jds__63h3_7ds"`
+	AuthBearerName   string `env:"BEARER_KEY" env-default:"token"`
+	CollectionHeader string `env:"COLLECTION_KEY" env-default:"collection"`
+	SessionHeader    string `env:"SESSION_KEY" env-default:"session"`
+	DeviceName       string `env:"DEVICE_NAME"`
+	Collection       string `env:"COLLECTION"`
+	BankCardDB       string `env:"BANK_CARD_DB" env-default:"bankCard"`
+	LoginPasswordDB  string `env:"LOGIN_PASSWORD_DB" env-default:"loginPassword"`
+	TextBinaryDB     string `env:"TEXT_BINARY_DB" env-default:"textBinary"`
+	HandlersTO       int    `env:"HANDLERS_TO" env-default:"500"`
+	ImportBatchSize  int    `env:"IMPORT_BATCH_SIZE" env-default:"50"`
+	SessionPath      string `env:"SESSION_PATH"`
+	KeyringPath      string `env:"KEYRING_PATH"`
+	AgentSocket      string `env:"AGENT_SOCKET"`
+	AgentIdleTimeout int    `env:"AGENT_IDLE_TIMEOUT" env-default:"900"`
+	BreachCorpus     string `env:"BREACH_CORPUS"`
+	EmergencyTimer   int    `env:"EMERGENCY_TIMER_INTERVAL" env-default:"60"`
+	MaxEntryLength   int    `env:"MAX_ENTRY_LENGTH" env-default:"3145728"`
+	SessionIdleTTL   int    `env:"SESSION_IDLE_TTL" env-default:"30"`
+	SessionMaxAge    int    `env:"SESSION_MAX_AGE" env-default:"180"`
 }
 
 // NewDefaultConfiguration initializes a configuration struct.
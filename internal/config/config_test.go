@@ -21,6 +21,11 @@ func TestNewDefaultConfiguration(t *testing.T) {
 	_ = os.Setenv("LOGIN_PASSWORD_DB", "someLoginPassword")
 	_ = os.Setenv("TEXT_BINARY_DB", "someTextBinary")
 	_ = os.Setenv("HANDLERS_TO", "1000")
+	_ = os.Setenv("IMPORT_BATCH_SIZE", "10")
+	_ = os.Setenv("AGENT_SOCKET", "some_socket")
+	_ = os.Setenv("AGENT_IDLE_TIMEOUT", "60")
+	_ = os.Setenv("COLLECTION", "some_collection")
+	_ = os.Setenv("DEVICE_NAME", "some_device")
 	cfg := NewDefaultConfiguration()
 	var a = ""
 	var c = ""
@@ -30,14 +35,25 @@ func TestNewDefaultConfiguration(t *testing.T) {
 		log.Fatal(err)
 	}
 	expCfg := Config{
-		ServerAddress:   "some_server_address",
-		DatabaseDSN:     "some_dsn",
-		UserKey:         "some_user_key",
-		AuthBearerName:  "some_key",
-		BankCardDB:      "someBankCard",
-		LoginPasswordDB: "someLoginPassword",
-		TextBinaryDB:    "someTextBinary",
-		HandlersTO:      1000,
+		ServerAddress:    "some_server_address",
+		DatabaseDSN:      "some_dsn",
+		UserKey:          "some_user_key",
+		AuthBearerName:   "some_key",
+		CollectionHeader: "collection",
+		Collection:       "some_collection",
+		SessionHeader:    "session",
+		DeviceName:       "some_device",
+		BankCardDB:       "someBankCard",
+		LoginPasswordDB:  "someLoginPassword",
+		TextBinaryDB:     "someTextBinary",
+		HandlersTO:       1000,
+		ImportBatchSize:  10,
+		AgentSocket:      "some_socket",
+		AgentIdleTimeout: 60,
+		EmergencyTimer:   60,
+		MaxEntryLength:   3145728,
+		SessionIdleTTL:   30,
+		SessionMaxAge:    180,
 	}
 	assert.Equal(t, &expCfg, cfg)
 }
@@ -52,14 +68,22 @@ func TestConfig_ParseFlags(t *testing.T) {
 		log.Fatal(err)
 	}
 	expCfg := Config{
-		ServerAddress:   ":8080",
-		DatabaseDSN:     "json_database_dsn",
-		UserKey:         "some_user_key",
-		AuthBearerName:  "token",
-		BankCardDB:      "bankCard",
-		LoginPasswordDB: "loginPassword",
-		TextBinaryDB:    "textBinary",
-		HandlersTO:      500,
+		ServerAddress:    ":8080",
+		DatabaseDSN:      "json_database_dsn",
+		UserKey:          "some_user_key",
+		AuthBearerName:   "token",
+		CollectionHeader: "collection",
+		SessionHeader:    "session",
+		BankCardDB:       "bankCard",
+		LoginPasswordDB:  "loginPassword",
+		TextBinaryDB:     "textBinary",
+		HandlersTO:       500,
+		ImportBatchSize:  50,
+		AgentIdleTimeout: 900,
+		EmergencyTimer:   60,
+		MaxEntryLength:   3145728,
+		SessionIdleTTL:   30,
+		SessionMaxAge:    180,
 	}
 	assert.Equal(t, &expCfg, cfg)
 }
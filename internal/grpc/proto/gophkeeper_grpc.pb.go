@@ -32,9 +32,44 @@ type GophkeeperClient interface {
 	PostBankCard(ctx context.Context, in *SendBankCardRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
 	PostLoginPassword(ctx context.Context, in *SendLoginPasswordRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
 	PostTextBinary(ctx context.Context, in *SendTextBinaryRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
-	GetTextsBinaries(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*GetTextsBinariesResponse, error)
-	GetLoginsPasswords(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*GetLoginsPasswordsResponse, error)
-	GetBankCards(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*GetBankCardsResponse, error)
+	GetTextsBinaries(ctx context.Context, in *PageRequest, opts ...grpc.CallOption) (*GetTextsBinariesResponse, error)
+	GetLoginsPasswords(ctx context.Context, in *PageRequest, opts ...grpc.CallOption) (*GetLoginsPasswordsResponse, error)
+	GetBankCards(ctx context.Context, in *PageRequest, opts ...grpc.CallOption) (*GetBankCardsResponse, error)
+	StreamTextsBinaries(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (Gophkeeper_StreamTextsBinariesClient, error)
+	StreamLoginsPasswords(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (Gophkeeper_StreamLoginsPasswordsClient, error)
+	StreamBankCards(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (Gophkeeper_StreamBankCardsClient, error)
+	BatchUpsert(ctx context.Context, in *BatchUpsertRequest, opts ...grpc.CallOption) (*BatchUpsertResponse, error)
+	SearchEntries(ctx context.Context, in *SearchEntriesRequest, opts ...grpc.CallOption) (*SearchEntriesResponse, error)
+	SetPublicKey(ctx context.Context, in *PublicKey, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	GetPublicKey(ctx context.Context, in *GetPublicKeyRequest, opts ...grpc.CallOption) (*PublicKey, error)
+	ShareEntry(ctx context.Context, in *ShareEntryRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	RevokeShare(ctx context.Context, in *RevokeShareRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	GetShares(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*GetSharesResponse, error)
+	GetSharedWithMe(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*GetSharedWithMeResponse, error)
+	UpdateSharedEntry(ctx context.Context, in *UpdateSharedEntryRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	SetSharePayload(ctx context.Context, in *SetSharePayloadRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	CreateCollection(ctx context.Context, in *CreateCollectionRequest, opts ...grpc.CallOption) (*Collection, error)
+	GetCollections(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*GetCollectionsResponse, error)
+	DeleteCollection(ctx context.Context, in *CollectionRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	InviteMember(ctx context.Context, in *InviteMemberRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	GetInvitations(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*GetInvitationsResponse, error)
+	RespondInvitation(ctx context.Context, in *RespondInvitationRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	GetMembers(ctx context.Context, in *CollectionRequest, opts ...grpc.CallOption) (*GetMembersResponse, error)
+	SetMemberRole(ctx context.Context, in *SetMemberRoleRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	RemoveMember(ctx context.Context, in *RemoveMemberRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	GetCollectionEvents(ctx context.Context, in *CollectionRequest, opts ...grpc.CallOption) (*GetCollectionEventsResponse, error)
+	ListAuditEvents(ctx context.Context, in *ListAuditEventsRequest, opts ...grpc.CallOption) (*ListAuditEventsResponse, error)
+	ListSessions(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ListSessionsResponse, error)
+	RevokeSession(ctx context.Context, in *RevokeSessionRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	SetTrustedContact(ctx context.Context, in *SetTrustedContactRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	RemoveTrustedContact(ctx context.Context, in *EmergencyAccessRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	ListEmergencyAccess(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ListEmergencyAccessResponse, error)
+	RequestEmergencyAccess(ctx context.Context, in *EmergencyAccessRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	ApproveEmergencyAccess(ctx context.Context, in *EmergencyAccessRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	RejectEmergencyAccess(ctx context.Context, in *EmergencyAccessRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	SetEmergencyEntries(ctx context.Context, in *SetEmergencyEntriesRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	StreamEmergencyEntries(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (Gophkeeper_StreamEmergencyEntriesClient, error)
+	StreamEmergencyVault(ctx context.Context, in *EmergencyAccessRequest, opts ...grpc.CallOption) (Gophkeeper_StreamEmergencyVaultClient, error)
 }
 
 type gophkeeperClient struct {
@@ -117,7 +152,7 @@ func (c *gophkeeperClient) PostTextBinary(ctx context.Context, in *SendTextBinar
 	return out, nil
 }
 
-func (c *gophkeeperClient) GetTextsBinaries(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*GetTextsBinariesResponse, error) {
+func (c *gophkeeperClient) GetTextsBinaries(ctx context.Context, in *PageRequest, opts ...grpc.CallOption) (*GetTextsBinariesResponse, error) {
 	out := new(GetTextsBinariesResponse)
 	err := c.cc.Invoke(ctx, "/proto.Gophkeeper/GetTextsBinaries", in, out, opts...)
 	if err != nil {
@@ -126,7 +161,7 @@ func (c *gophkeeperClient) GetTextsBinaries(ctx context.Context, in *emptypb.Emp
 	return out, nil
 }
 
-func (c *gophkeeperClient) GetLoginsPasswords(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*GetLoginsPasswordsResponse, error) {
+func (c *gophkeeperClient) GetLoginsPasswords(ctx context.Context, in *PageRequest, opts ...grpc.CallOption) (*GetLoginsPasswordsResponse, error) {
 	out := new(GetLoginsPasswordsResponse)
 	err := c.cc.Invoke(ctx, "/proto.Gophkeeper/GetLoginsPasswords", in, out, opts...)
 	if err != nil {
@@ -135,7 +170,7 @@ func (c *gophkeeperClient) GetLoginsPasswords(ctx context.Context, in *emptypb.E
 	return out, nil
 }
 
-func (c *gophkeeperClient) GetBankCards(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*GetBankCardsResponse, error) {
+func (c *gophkeeperClient) GetBankCards(ctx context.Context, in *PageRequest, opts ...grpc.CallOption) (*GetBankCardsResponse, error) {
 	out := new(GetBankCardsResponse)
 	err := c.cc.Invoke(ctx, "/proto.Gophkeeper/GetBankCards", in, out, opts...)
 	if err != nil {
@@ -144,6 +179,436 @@ func (c *gophkeeperClient) GetBankCards(ctx context.Context, in *emptypb.Empty,
 	return out, nil
 }
 
+func (c *gophkeeperClient) StreamTextsBinaries(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (Gophkeeper_StreamTextsBinariesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Gophkeeper_ServiceDesc.Streams[0], "/proto.Gophkeeper/StreamTextsBinaries", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &gophkeeperStreamTextsBinariesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Gophkeeper_StreamTextsBinariesClient interface {
+	Recv() (*ResponsePieceTextBinary, error)
+	grpc.ClientStream
+}
+
+type gophkeeperStreamTextsBinariesClient struct {
+	grpc.ClientStream
+}
+
+func (x *gophkeeperStreamTextsBinariesClient) Recv() (*ResponsePieceTextBinary, error) {
+	m := new(ResponsePieceTextBinary)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *gophkeeperClient) StreamLoginsPasswords(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (Gophkeeper_StreamLoginsPasswordsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Gophkeeper_ServiceDesc.Streams[1], "/proto.Gophkeeper/StreamLoginsPasswords", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &gophkeeperStreamLoginsPasswordsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Gophkeeper_StreamLoginsPasswordsClient interface {
+	Recv() (*ResponsePieceLoginPassword, error)
+	grpc.ClientStream
+}
+
+type gophkeeperStreamLoginsPasswordsClient struct {
+	grpc.ClientStream
+}
+
+func (x *gophkeeperStreamLoginsPasswordsClient) Recv() (*ResponsePieceLoginPassword, error) {
+	m := new(ResponsePieceLoginPassword)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *gophkeeperClient) StreamBankCards(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (Gophkeeper_StreamBankCardsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Gophkeeper_ServiceDesc.Streams[2], "/proto.Gophkeeper/StreamBankCards", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &gophkeeperStreamBankCardsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Gophkeeper_StreamBankCardsClient interface {
+	Recv() (*ResponsePieceBankCard, error)
+	grpc.ClientStream
+}
+
+type gophkeeperStreamBankCardsClient struct {
+	grpc.ClientStream
+}
+
+func (x *gophkeeperStreamBankCardsClient) Recv() (*ResponsePieceBankCard, error) {
+	m := new(ResponsePieceBankCard)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *gophkeeperClient) BatchUpsert(ctx context.Context, in *BatchUpsertRequest, opts ...grpc.CallOption) (*BatchUpsertResponse, error) {
+	out := new(BatchUpsertResponse)
+	err := c.cc.Invoke(ctx, "/proto.Gophkeeper/BatchUpsert", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophkeeperClient) SearchEntries(ctx context.Context, in *SearchEntriesRequest, opts ...grpc.CallOption) (*SearchEntriesResponse, error) {
+	out := new(SearchEntriesResponse)
+	err := c.cc.Invoke(ctx, "/proto.Gophkeeper/SearchEntries", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophkeeperClient) SetPublicKey(ctx context.Context, in *PublicKey, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/proto.Gophkeeper/SetPublicKey", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophkeeperClient) GetPublicKey(ctx context.Context, in *GetPublicKeyRequest, opts ...grpc.CallOption) (*PublicKey, error) {
+	out := new(PublicKey)
+	err := c.cc.Invoke(ctx, "/proto.Gophkeeper/GetPublicKey", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophkeeperClient) ShareEntry(ctx context.Context, in *ShareEntryRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/proto.Gophkeeper/ShareEntry", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophkeeperClient) RevokeShare(ctx context.Context, in *RevokeShareRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/proto.Gophkeeper/RevokeShare", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophkeeperClient) GetShares(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*GetSharesResponse, error) {
+	out := new(GetSharesResponse)
+	err := c.cc.Invoke(ctx, "/proto.Gophkeeper/GetShares", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophkeeperClient) GetSharedWithMe(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*GetSharedWithMeResponse, error) {
+	out := new(GetSharedWithMeResponse)
+	err := c.cc.Invoke(ctx, "/proto.Gophkeeper/GetSharedWithMe", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophkeeperClient) UpdateSharedEntry(ctx context.Context, in *UpdateSharedEntryRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/proto.Gophkeeper/UpdateSharedEntry", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophkeeperClient) SetSharePayload(ctx context.Context, in *SetSharePayloadRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/proto.Gophkeeper/SetSharePayload", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophkeeperClient) CreateCollection(ctx context.Context, in *CreateCollectionRequest, opts ...grpc.CallOption) (*Collection, error) {
+	out := new(Collection)
+	err := c.cc.Invoke(ctx, "/proto.Gophkeeper/CreateCollection", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophkeeperClient) GetCollections(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*GetCollectionsResponse, error) {
+	out := new(GetCollectionsResponse)
+	err := c.cc.Invoke(ctx, "/proto.Gophkeeper/GetCollections", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophkeeperClient) DeleteCollection(ctx context.Context, in *CollectionRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/proto.Gophkeeper/DeleteCollection", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophkeeperClient) InviteMember(ctx context.Context, in *InviteMemberRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/proto.Gophkeeper/InviteMember", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophkeeperClient) GetInvitations(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*GetInvitationsResponse, error) {
+	out := new(GetInvitationsResponse)
+	err := c.cc.Invoke(ctx, "/proto.Gophkeeper/GetInvitations", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophkeeperClient) RespondInvitation(ctx context.Context, in *RespondInvitationRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/proto.Gophkeeper/RespondInvitation", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophkeeperClient) GetMembers(ctx context.Context, in *CollectionRequest, opts ...grpc.CallOption) (*GetMembersResponse, error) {
+	out := new(GetMembersResponse)
+	err := c.cc.Invoke(ctx, "/proto.Gophkeeper/GetMembers", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophkeeperClient) SetMemberRole(ctx context.Context, in *SetMemberRoleRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/proto.Gophkeeper/SetMemberRole", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophkeeperClient) RemoveMember(ctx context.Context, in *RemoveMemberRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/proto.Gophkeeper/RemoveMember", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophkeeperClient) GetCollectionEvents(ctx context.Context, in *CollectionRequest, opts ...grpc.CallOption) (*GetCollectionEventsResponse, error) {
+	out := new(GetCollectionEventsResponse)
+	err := c.cc.Invoke(ctx, "/proto.Gophkeeper/GetCollectionEvents", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophkeeperClient) ListAuditEvents(ctx context.Context, in *ListAuditEventsRequest, opts ...grpc.CallOption) (*ListAuditEventsResponse, error) {
+	out := new(ListAuditEventsResponse)
+	err := c.cc.Invoke(ctx, "/proto.Gophkeeper/ListAuditEvents", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophkeeperClient) ListSessions(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ListSessionsResponse, error) {
+	out := new(ListSessionsResponse)
+	err := c.cc.Invoke(ctx, "/proto.Gophkeeper/ListSessions", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophkeeperClient) RevokeSession(ctx context.Context, in *RevokeSessionRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/proto.Gophkeeper/RevokeSession", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophkeeperClient) SetTrustedContact(ctx context.Context, in *SetTrustedContactRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/proto.Gophkeeper/SetTrustedContact", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophkeeperClient) RemoveTrustedContact(ctx context.Context, in *EmergencyAccessRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/proto.Gophkeeper/RemoveTrustedContact", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophkeeperClient) ListEmergencyAccess(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ListEmergencyAccessResponse, error) {
+	out := new(ListEmergencyAccessResponse)
+	err := c.cc.Invoke(ctx, "/proto.Gophkeeper/ListEmergencyAccess", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophkeeperClient) RequestEmergencyAccess(ctx context.Context, in *EmergencyAccessRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/proto.Gophkeeper/RequestEmergencyAccess", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophkeeperClient) ApproveEmergencyAccess(ctx context.Context, in *EmergencyAccessRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/proto.Gophkeeper/ApproveEmergencyAccess", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophkeeperClient) RejectEmergencyAccess(ctx context.Context, in *EmergencyAccessRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/proto.Gophkeeper/RejectEmergencyAccess", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophkeeperClient) SetEmergencyEntries(ctx context.Context, in *SetEmergencyEntriesRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/proto.Gophkeeper/SetEmergencyEntries", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophkeeperClient) StreamEmergencyEntries(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (Gophkeeper_StreamEmergencyEntriesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Gophkeeper_ServiceDesc.Streams[3], "/proto.Gophkeeper/StreamEmergencyEntries", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &gophkeeperStreamEmergencyEntriesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Gophkeeper_StreamEmergencyEntriesClient interface {
+	Recv() (*EmergencyEntry, error)
+	grpc.ClientStream
+}
+
+type gophkeeperStreamEmergencyEntriesClient struct {
+	grpc.ClientStream
+}
+
+func (x *gophkeeperStreamEmergencyEntriesClient) Recv() (*EmergencyEntry, error) {
+	m := new(EmergencyEntry)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *gophkeeperClient) StreamEmergencyVault(ctx context.Context, in *EmergencyAccessRequest, opts ...grpc.CallOption) (Gophkeeper_StreamEmergencyVaultClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Gophkeeper_ServiceDesc.Streams[4], "/proto.Gophkeeper/StreamEmergencyVault", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &gophkeeperStreamEmergencyVaultClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Gophkeeper_StreamEmergencyVaultClient interface {
+	Recv() (*EmergencyEntry, error)
+	grpc.ClientStream
+}
+
+type gophkeeperStreamEmergencyVaultClient struct {
+	grpc.ClientStream
+}
+
+func (x *gophkeeperStreamEmergencyVaultClient) Recv() (*EmergencyEntry, error) {
+	m := new(EmergencyEntry)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // GophkeeperServer is the server API for Gophkeeper service.
 // All implementations must embed UnimplementedGophkeeperServer
 // for forward compatibility
@@ -156,260 +621,1045 @@ type GophkeeperServer interface {
 	PostBankCard(context.Context, *SendBankCardRequest) (*emptypb.Empty, error)
 	PostLoginPassword(context.Context, *SendLoginPasswordRequest) (*emptypb.Empty, error)
 	PostTextBinary(context.Context, *SendTextBinaryRequest) (*emptypb.Empty, error)
-	GetTextsBinaries(context.Context, *emptypb.Empty) (*GetTextsBinariesResponse, error)
-	GetLoginsPasswords(context.Context, *emptypb.Empty) (*GetLoginsPasswordsResponse, error)
-	GetBankCards(context.Context, *emptypb.Empty) (*GetBankCardsResponse, error)
+	GetTextsBinaries(context.Context, *PageRequest) (*GetTextsBinariesResponse, error)
+	GetLoginsPasswords(context.Context, *PageRequest) (*GetLoginsPasswordsResponse, error)
+	GetBankCards(context.Context, *PageRequest) (*GetBankCardsResponse, error)
+	StreamTextsBinaries(*emptypb.Empty, Gophkeeper_StreamTextsBinariesServer) error
+	StreamLoginsPasswords(*emptypb.Empty, Gophkeeper_StreamLoginsPasswordsServer) error
+	StreamBankCards(*emptypb.Empty, Gophkeeper_StreamBankCardsServer) error
+	BatchUpsert(context.Context, *BatchUpsertRequest) (*BatchUpsertResponse, error)
+	SearchEntries(context.Context, *SearchEntriesRequest) (*SearchEntriesResponse, error)
+	SetPublicKey(context.Context, *PublicKey) (*emptypb.Empty, error)
+	GetPublicKey(context.Context, *GetPublicKeyRequest) (*PublicKey, error)
+	ShareEntry(context.Context, *ShareEntryRequest) (*emptypb.Empty, error)
+	RevokeShare(context.Context, *RevokeShareRequest) (*emptypb.Empty, error)
+	GetShares(context.Context, *emptypb.Empty) (*GetSharesResponse, error)
+	GetSharedWithMe(context.Context, *emptypb.Empty) (*GetSharedWithMeResponse, error)
+	UpdateSharedEntry(context.Context, *UpdateSharedEntryRequest) (*emptypb.Empty, error)
+	SetSharePayload(context.Context, *SetSharePayloadRequest) (*emptypb.Empty, error)
+	CreateCollection(context.Context, *CreateCollectionRequest) (*Collection, error)
+	GetCollections(context.Context, *emptypb.Empty) (*GetCollectionsResponse, error)
+	DeleteCollection(context.Context, *CollectionRequest) (*emptypb.Empty, error)
+	InviteMember(context.Context, *InviteMemberRequest) (*emptypb.Empty, error)
+	GetInvitations(context.Context, *emptypb.Empty) (*GetInvitationsResponse, error)
+	RespondInvitation(context.Context, *RespondInvitationRequest) (*emptypb.Empty, error)
+	GetMembers(context.Context, *CollectionRequest) (*GetMembersResponse, error)
+	SetMemberRole(context.Context, *SetMemberRoleRequest) (*emptypb.Empty, error)
+	RemoveMember(context.Context, *RemoveMemberRequest) (*emptypb.Empty, error)
+	GetCollectionEvents(context.Context, *CollectionRequest) (*GetCollectionEventsResponse, error)
+	ListAuditEvents(context.Context, *ListAuditEventsRequest) (*ListAuditEventsResponse, error)
+	ListSessions(context.Context, *emptypb.Empty) (*ListSessionsResponse, error)
+	RevokeSession(context.Context, *RevokeSessionRequest) (*emptypb.Empty, error)
+	SetTrustedContact(context.Context, *SetTrustedContactRequest) (*emptypb.Empty, error)
+	RemoveTrustedContact(context.Context, *EmergencyAccessRequest) (*emptypb.Empty, error)
+	ListEmergencyAccess(context.Context, *emptypb.Empty) (*ListEmergencyAccessResponse, error)
+	RequestEmergencyAccess(context.Context, *EmergencyAccessRequest) (*emptypb.Empty, error)
+	ApproveEmergencyAccess(context.Context, *EmergencyAccessRequest) (*emptypb.Empty, error)
+	RejectEmergencyAccess(context.Context, *EmergencyAccessRequest) (*emptypb.Empty, error)
+	SetEmergencyEntries(context.Context, *SetEmergencyEntriesRequest) (*emptypb.Empty, error)
+	StreamEmergencyEntries(*emptypb.Empty, Gophkeeper_StreamEmergencyEntriesServer) error
+	StreamEmergencyVault(*EmergencyAccessRequest, Gophkeeper_StreamEmergencyVaultServer) error
+	mustEmbedUnimplementedGophkeeperServer()
+}
+
+// UnimplementedGophkeeperServer must be embedded to have forward compatible implementations.
+type UnimplementedGophkeeperServer struct {
+}
+
+func (UnimplementedGophkeeperServer) Login(context.Context, *LoginRegisterRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Login not implemented")
+}
+func (UnimplementedGophkeeperServer) Register(context.Context, *LoginRegisterRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Register not implemented")
+}
+func (UnimplementedGophkeeperServer) DeleteBankCard(context.Context, *DeleteBankCardRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteBankCard not implemented")
+}
+func (UnimplementedGophkeeperServer) DeleteLoginPassword(context.Context, *DeleteLoginPasswordRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteLoginPassword not implemented")
+}
+func (UnimplementedGophkeeperServer) DeleteTextBinary(context.Context, *DeleteTextBinaryRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteTextBinary not implemented")
+}
+func (UnimplementedGophkeeperServer) PostBankCard(context.Context, *SendBankCardRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PostBankCard not implemented")
+}
+func (UnimplementedGophkeeperServer) PostLoginPassword(context.Context, *SendLoginPasswordRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PostLoginPassword not implemented")
+}
+func (UnimplementedGophkeeperServer) PostTextBinary(context.Context, *SendTextBinaryRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PostTextBinary not implemented")
+}
+func (UnimplementedGophkeeperServer) GetTextsBinaries(context.Context, *PageRequest) (*GetTextsBinariesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTextsBinaries not implemented")
+}
+func (UnimplementedGophkeeperServer) GetLoginsPasswords(context.Context, *PageRequest) (*GetLoginsPasswordsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLoginsPasswords not implemented")
+}
+func (UnimplementedGophkeeperServer) GetBankCards(context.Context, *PageRequest) (*GetBankCardsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBankCards not implemented")
+}
+func (UnimplementedGophkeeperServer) StreamTextsBinaries(*emptypb.Empty, Gophkeeper_StreamTextsBinariesServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamTextsBinaries not implemented")
+}
+func (UnimplementedGophkeeperServer) StreamLoginsPasswords(*emptypb.Empty, Gophkeeper_StreamLoginsPasswordsServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamLoginsPasswords not implemented")
+}
+func (UnimplementedGophkeeperServer) StreamBankCards(*emptypb.Empty, Gophkeeper_StreamBankCardsServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamBankCards not implemented")
+}
+func (UnimplementedGophkeeperServer) BatchUpsert(context.Context, *BatchUpsertRequest) (*BatchUpsertResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchUpsert not implemented")
+}
+func (UnimplementedGophkeeperServer) SearchEntries(context.Context, *SearchEntriesRequest) (*SearchEntriesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchEntries not implemented")
+}
+func (UnimplementedGophkeeperServer) SetPublicKey(context.Context, *PublicKey) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetPublicKey not implemented")
+}
+func (UnimplementedGophkeeperServer) GetPublicKey(context.Context, *GetPublicKeyRequest) (*PublicKey, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPublicKey not implemented")
+}
+func (UnimplementedGophkeeperServer) ShareEntry(context.Context, *ShareEntryRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ShareEntry not implemented")
+}
+func (UnimplementedGophkeeperServer) RevokeShare(context.Context, *RevokeShareRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevokeShare not implemented")
+}
+func (UnimplementedGophkeeperServer) GetShares(context.Context, *emptypb.Empty) (*GetSharesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetShares not implemented")
+}
+func (UnimplementedGophkeeperServer) GetSharedWithMe(context.Context, *emptypb.Empty) (*GetSharedWithMeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSharedWithMe not implemented")
+}
+func (UnimplementedGophkeeperServer) UpdateSharedEntry(context.Context, *UpdateSharedEntryRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateSharedEntry not implemented")
+}
+func (UnimplementedGophkeeperServer) SetSharePayload(context.Context, *SetSharePayloadRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetSharePayload not implemented")
+}
+func (UnimplementedGophkeeperServer) CreateCollection(context.Context, *CreateCollectionRequest) (*Collection, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateCollection not implemented")
+}
+func (UnimplementedGophkeeperServer) GetCollections(context.Context, *emptypb.Empty) (*GetCollectionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCollections not implemented")
+}
+func (UnimplementedGophkeeperServer) DeleteCollection(context.Context, *CollectionRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteCollection not implemented")
+}
+func (UnimplementedGophkeeperServer) InviteMember(context.Context, *InviteMemberRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method InviteMember not implemented")
+}
+func (UnimplementedGophkeeperServer) GetInvitations(context.Context, *emptypb.Empty) (*GetInvitationsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetInvitations not implemented")
+}
+func (UnimplementedGophkeeperServer) RespondInvitation(context.Context, *RespondInvitationRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RespondInvitation not implemented")
+}
+func (UnimplementedGophkeeperServer) GetMembers(context.Context, *CollectionRequest) (*GetMembersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMembers not implemented")
+}
+func (UnimplementedGophkeeperServer) SetMemberRole(context.Context, *SetMemberRoleRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetMemberRole not implemented")
+}
+func (UnimplementedGophkeeperServer) RemoveMember(context.Context, *RemoveMemberRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveMember not implemented")
+}
+func (UnimplementedGophkeeperServer) GetCollectionEvents(context.Context, *CollectionRequest) (*GetCollectionEventsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCollectionEvents not implemented")
+}
+func (UnimplementedGophkeeperServer) ListAuditEvents(context.Context, *ListAuditEventsRequest) (*ListAuditEventsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListAuditEvents not implemented")
+}
+func (UnimplementedGophkeeperServer) ListSessions(context.Context, *emptypb.Empty) (*ListSessionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSessions not implemented")
+}
+func (UnimplementedGophkeeperServer) RevokeSession(context.Context, *RevokeSessionRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevokeSession not implemented")
+}
+func (UnimplementedGophkeeperServer) SetTrustedContact(context.Context, *SetTrustedContactRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetTrustedContact not implemented")
+}
+func (UnimplementedGophkeeperServer) RemoveTrustedContact(context.Context, *EmergencyAccessRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveTrustedContact not implemented")
+}
+func (UnimplementedGophkeeperServer) ListEmergencyAccess(context.Context, *emptypb.Empty) (*ListEmergencyAccessResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListEmergencyAccess not implemented")
+}
+func (UnimplementedGophkeeperServer) RequestEmergencyAccess(context.Context, *EmergencyAccessRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RequestEmergencyAccess not implemented")
+}
+func (UnimplementedGophkeeperServer) ApproveEmergencyAccess(context.Context, *EmergencyAccessRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ApproveEmergencyAccess not implemented")
+}
+func (UnimplementedGophkeeperServer) RejectEmergencyAccess(context.Context, *EmergencyAccessRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RejectEmergencyAccess not implemented")
+}
+func (UnimplementedGophkeeperServer) SetEmergencyEntries(context.Context, *SetEmergencyEntriesRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetEmergencyEntries not implemented")
+}
+func (UnimplementedGophkeeperServer) StreamEmergencyEntries(*emptypb.Empty, Gophkeeper_StreamEmergencyEntriesServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamEmergencyEntries not implemented")
+}
+func (UnimplementedGophkeeperServer) StreamEmergencyVault(*EmergencyAccessRequest, Gophkeeper_StreamEmergencyVaultServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamEmergencyVault not implemented")
+}
+func (UnimplementedGophkeeperServer) mustEmbedUnimplementedGophkeeperServer() {}
+
+// UnsafeGophkeeperServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to GophkeeperServer will
+// result in compilation errors.
+type UnsafeGophkeeperServer interface {
 	mustEmbedUnimplementedGophkeeperServer()
 }
 
-// UnimplementedGophkeeperServer must be embedded to have forward compatible implementations.
-type UnimplementedGophkeeperServer struct {
+func RegisterGophkeeperServer(s grpc.ServiceRegistrar, srv GophkeeperServer) {
+	s.RegisterService(&Gophkeeper_ServiceDesc, srv)
+}
+
+func _Gophkeeper_Login_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LoginRegisterRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophkeeperServer).Login(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Gophkeeper/Login",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophkeeperServer).Login(ctx, req.(*LoginRegisterRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Gophkeeper_Register_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LoginRegisterRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophkeeperServer).Register(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Gophkeeper/Register",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophkeeperServer).Register(ctx, req.(*LoginRegisterRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Gophkeeper_DeleteBankCard_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteBankCardRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophkeeperServer).DeleteBankCard(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Gophkeeper/DeleteBankCard",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophkeeperServer).DeleteBankCard(ctx, req.(*DeleteBankCardRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Gophkeeper_DeleteLoginPassword_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteLoginPasswordRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophkeeperServer).DeleteLoginPassword(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Gophkeeper/DeleteLoginPassword",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophkeeperServer).DeleteLoginPassword(ctx, req.(*DeleteLoginPasswordRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Gophkeeper_DeleteTextBinary_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteTextBinaryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophkeeperServer).DeleteTextBinary(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Gophkeeper/DeleteTextBinary",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophkeeperServer).DeleteTextBinary(ctx, req.(*DeleteTextBinaryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Gophkeeper_PostBankCard_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SendBankCardRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophkeeperServer).PostBankCard(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Gophkeeper/PostBankCard",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophkeeperServer).PostBankCard(ctx, req.(*SendBankCardRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Gophkeeper_PostLoginPassword_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SendLoginPasswordRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophkeeperServer).PostLoginPassword(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Gophkeeper/PostLoginPassword",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophkeeperServer).PostLoginPassword(ctx, req.(*SendLoginPasswordRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Gophkeeper_PostTextBinary_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SendTextBinaryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophkeeperServer).PostTextBinary(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Gophkeeper/PostTextBinary",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophkeeperServer).PostTextBinary(ctx, req.(*SendTextBinaryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func (UnimplementedGophkeeperServer) Login(context.Context, *LoginRegisterRequest) (*emptypb.Empty, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method Login not implemented")
+func _Gophkeeper_GetTextsBinaries_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophkeeperServer).GetTextsBinaries(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Gophkeeper/GetTextsBinaries",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophkeeperServer).GetTextsBinaries(ctx, req.(*PageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
-func (UnimplementedGophkeeperServer) Register(context.Context, *LoginRegisterRequest) (*emptypb.Empty, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method Register not implemented")
+
+func _Gophkeeper_GetLoginsPasswords_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophkeeperServer).GetLoginsPasswords(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Gophkeeper/GetLoginsPasswords",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophkeeperServer).GetLoginsPasswords(ctx, req.(*PageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
-func (UnimplementedGophkeeperServer) DeleteBankCard(context.Context, *DeleteBankCardRequest) (*emptypb.Empty, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method DeleteBankCard not implemented")
+
+func _Gophkeeper_GetBankCards_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophkeeperServer).GetBankCards(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Gophkeeper/GetBankCards",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophkeeperServer).GetBankCards(ctx, req.(*PageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
-func (UnimplementedGophkeeperServer) DeleteLoginPassword(context.Context, *DeleteLoginPasswordRequest) (*emptypb.Empty, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method DeleteLoginPassword not implemented")
+
+func _Gophkeeper_StreamTextsBinaries_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(emptypb.Empty)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(GophkeeperServer).StreamTextsBinaries(m, &gophkeeperStreamTextsBinariesServer{stream})
 }
-func (UnimplementedGophkeeperServer) DeleteTextBinary(context.Context, *DeleteTextBinaryRequest) (*emptypb.Empty, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method DeleteTextBinary not implemented")
+
+type Gophkeeper_StreamTextsBinariesServer interface {
+	Send(*ResponsePieceTextBinary) error
+	grpc.ServerStream
+}
+
+type gophkeeperStreamTextsBinariesServer struct {
+	grpc.ServerStream
+}
+
+func (x *gophkeeperStreamTextsBinariesServer) Send(m *ResponsePieceTextBinary) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Gophkeeper_StreamLoginsPasswords_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(emptypb.Empty)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(GophkeeperServer).StreamLoginsPasswords(m, &gophkeeperStreamLoginsPasswordsServer{stream})
+}
+
+type Gophkeeper_StreamLoginsPasswordsServer interface {
+	Send(*ResponsePieceLoginPassword) error
+	grpc.ServerStream
+}
+
+type gophkeeperStreamLoginsPasswordsServer struct {
+	grpc.ServerStream
+}
+
+func (x *gophkeeperStreamLoginsPasswordsServer) Send(m *ResponsePieceLoginPassword) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Gophkeeper_StreamBankCards_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(emptypb.Empty)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(GophkeeperServer).StreamBankCards(m, &gophkeeperStreamBankCardsServer{stream})
+}
+
+type Gophkeeper_StreamBankCardsServer interface {
+	Send(*ResponsePieceBankCard) error
+	grpc.ServerStream
+}
+
+type gophkeeperStreamBankCardsServer struct {
+	grpc.ServerStream
+}
+
+func (x *gophkeeperStreamBankCardsServer) Send(m *ResponsePieceBankCard) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Gophkeeper_BatchUpsert_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchUpsertRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophkeeperServer).BatchUpsert(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Gophkeeper/BatchUpsert",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophkeeperServer).BatchUpsert(ctx, req.(*BatchUpsertRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Gophkeeper_SearchEntries_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchEntriesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophkeeperServer).SearchEntries(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Gophkeeper/SearchEntries",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophkeeperServer).SearchEntries(ctx, req.(*SearchEntriesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Gophkeeper_SetPublicKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PublicKey)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophkeeperServer).SetPublicKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Gophkeeper/SetPublicKey",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophkeeperServer).SetPublicKey(ctx, req.(*PublicKey))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Gophkeeper_GetPublicKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPublicKeyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophkeeperServer).GetPublicKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Gophkeeper/GetPublicKey",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophkeeperServer).GetPublicKey(ctx, req.(*GetPublicKeyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Gophkeeper_ShareEntry_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ShareEntryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophkeeperServer).ShareEntry(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Gophkeeper/ShareEntry",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophkeeperServer).ShareEntry(ctx, req.(*ShareEntryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Gophkeeper_RevokeShare_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevokeShareRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophkeeperServer).RevokeShare(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Gophkeeper/RevokeShare",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophkeeperServer).RevokeShare(ctx, req.(*RevokeShareRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Gophkeeper_GetShares_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophkeeperServer).GetShares(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Gophkeeper/GetShares",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophkeeperServer).GetShares(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Gophkeeper_GetSharedWithMe_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophkeeperServer).GetSharedWithMe(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Gophkeeper/GetSharedWithMe",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophkeeperServer).GetSharedWithMe(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Gophkeeper_UpdateSharedEntry_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateSharedEntryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophkeeperServer).UpdateSharedEntry(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Gophkeeper/UpdateSharedEntry",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophkeeperServer).UpdateSharedEntry(ctx, req.(*UpdateSharedEntryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Gophkeeper_SetSharePayload_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetSharePayloadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophkeeperServer).SetSharePayload(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Gophkeeper/SetSharePayload",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophkeeperServer).SetSharePayload(ctx, req.(*SetSharePayloadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Gophkeeper_CreateCollection_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateCollectionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophkeeperServer).CreateCollection(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Gophkeeper/CreateCollection",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophkeeperServer).CreateCollection(ctx, req.(*CreateCollectionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
-func (UnimplementedGophkeeperServer) PostBankCard(context.Context, *SendBankCardRequest) (*emptypb.Empty, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method PostBankCard not implemented")
+
+func _Gophkeeper_GetCollections_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophkeeperServer).GetCollections(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Gophkeeper/GetCollections",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophkeeperServer).GetCollections(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
 }
-func (UnimplementedGophkeeperServer) PostLoginPassword(context.Context, *SendLoginPasswordRequest) (*emptypb.Empty, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method PostLoginPassword not implemented")
+
+func _Gophkeeper_DeleteCollection_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CollectionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophkeeperServer).DeleteCollection(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Gophkeeper/DeleteCollection",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophkeeperServer).DeleteCollection(ctx, req.(*CollectionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
-func (UnimplementedGophkeeperServer) PostTextBinary(context.Context, *SendTextBinaryRequest) (*emptypb.Empty, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method PostTextBinary not implemented")
+
+func _Gophkeeper_InviteMember_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InviteMemberRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophkeeperServer).InviteMember(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Gophkeeper/InviteMember",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophkeeperServer).InviteMember(ctx, req.(*InviteMemberRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
-func (UnimplementedGophkeeperServer) GetTextsBinaries(context.Context, *emptypb.Empty) (*GetTextsBinariesResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method GetTextsBinaries not implemented")
+
+func _Gophkeeper_GetInvitations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophkeeperServer).GetInvitations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Gophkeeper/GetInvitations",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophkeeperServer).GetInvitations(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
 }
-func (UnimplementedGophkeeperServer) GetLoginsPasswords(context.Context, *emptypb.Empty) (*GetLoginsPasswordsResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method GetLoginsPasswords not implemented")
+
+func _Gophkeeper_RespondInvitation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RespondInvitationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophkeeperServer).RespondInvitation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Gophkeeper/RespondInvitation",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophkeeperServer).RespondInvitation(ctx, req.(*RespondInvitationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
-func (UnimplementedGophkeeperServer) GetBankCards(context.Context, *emptypb.Empty) (*GetBankCardsResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method GetBankCards not implemented")
+
+func _Gophkeeper_GetMembers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CollectionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophkeeperServer).GetMembers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Gophkeeper/GetMembers",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophkeeperServer).GetMembers(ctx, req.(*CollectionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
-func (UnimplementedGophkeeperServer) mustEmbedUnimplementedGophkeeperServer() {}
 
-// UnsafeGophkeeperServer may be embedded to opt out of forward compatibility for this service.
-// Use of this interface is not recommended, as added methods to GophkeeperServer will
-// result in compilation errors.
-type UnsafeGophkeeperServer interface {
-	mustEmbedUnimplementedGophkeeperServer()
+func _Gophkeeper_SetMemberRole_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetMemberRoleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophkeeperServer).SetMemberRole(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Gophkeeper/SetMemberRole",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophkeeperServer).SetMemberRole(ctx, req.(*SetMemberRoleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func RegisterGophkeeperServer(s grpc.ServiceRegistrar, srv GophkeeperServer) {
-	s.RegisterService(&Gophkeeper_ServiceDesc, srv)
+func _Gophkeeper_RemoveMember_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveMemberRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophkeeperServer).RemoveMember(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Gophkeeper/RemoveMember",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophkeeperServer).RemoveMember(ctx, req.(*RemoveMemberRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func _Gophkeeper_Login_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(LoginRegisterRequest)
+func _Gophkeeper_GetCollectionEvents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CollectionRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(GophkeeperServer).Login(ctx, in)
+		return srv.(GophkeeperServer).GetCollectionEvents(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/proto.Gophkeeper/Login",
+		FullMethod: "/proto.Gophkeeper/GetCollectionEvents",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(GophkeeperServer).Login(ctx, req.(*LoginRegisterRequest))
+		return srv.(GophkeeperServer).GetCollectionEvents(ctx, req.(*CollectionRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Gophkeeper_Register_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(LoginRegisterRequest)
+func _Gophkeeper_ListAuditEvents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAuditEventsRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(GophkeeperServer).Register(ctx, in)
+		return srv.(GophkeeperServer).ListAuditEvents(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/proto.Gophkeeper/Register",
+		FullMethod: "/proto.Gophkeeper/ListAuditEvents",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(GophkeeperServer).Register(ctx, req.(*LoginRegisterRequest))
+		return srv.(GophkeeperServer).ListAuditEvents(ctx, req.(*ListAuditEventsRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Gophkeeper_DeleteBankCard_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(DeleteBankCardRequest)
+func _Gophkeeper_ListSessions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(GophkeeperServer).DeleteBankCard(ctx, in)
+		return srv.(GophkeeperServer).ListSessions(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/proto.Gophkeeper/DeleteBankCard",
+		FullMethod: "/proto.Gophkeeper/ListSessions",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(GophkeeperServer).DeleteBankCard(ctx, req.(*DeleteBankCardRequest))
+		return srv.(GophkeeperServer).ListSessions(ctx, req.(*emptypb.Empty))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Gophkeeper_DeleteLoginPassword_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(DeleteLoginPasswordRequest)
+func _Gophkeeper_RevokeSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevokeSessionRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(GophkeeperServer).DeleteLoginPassword(ctx, in)
+		return srv.(GophkeeperServer).RevokeSession(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/proto.Gophkeeper/DeleteLoginPassword",
+		FullMethod: "/proto.Gophkeeper/RevokeSession",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(GophkeeperServer).DeleteLoginPassword(ctx, req.(*DeleteLoginPasswordRequest))
+		return srv.(GophkeeperServer).RevokeSession(ctx, req.(*RevokeSessionRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Gophkeeper_DeleteTextBinary_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(DeleteTextBinaryRequest)
+func _Gophkeeper_SetTrustedContact_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetTrustedContactRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(GophkeeperServer).DeleteTextBinary(ctx, in)
+		return srv.(GophkeeperServer).SetTrustedContact(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/proto.Gophkeeper/DeleteTextBinary",
+		FullMethod: "/proto.Gophkeeper/SetTrustedContact",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(GophkeeperServer).DeleteTextBinary(ctx, req.(*DeleteTextBinaryRequest))
+		return srv.(GophkeeperServer).SetTrustedContact(ctx, req.(*SetTrustedContactRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Gophkeeper_PostBankCard_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(SendBankCardRequest)
+func _Gophkeeper_RemoveTrustedContact_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EmergencyAccessRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(GophkeeperServer).PostBankCard(ctx, in)
+		return srv.(GophkeeperServer).RemoveTrustedContact(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/proto.Gophkeeper/PostBankCard",
+		FullMethod: "/proto.Gophkeeper/RemoveTrustedContact",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(GophkeeperServer).PostBankCard(ctx, req.(*SendBankCardRequest))
+		return srv.(GophkeeperServer).RemoveTrustedContact(ctx, req.(*EmergencyAccessRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Gophkeeper_PostLoginPassword_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(SendLoginPasswordRequest)
+func _Gophkeeper_ListEmergencyAccess_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(GophkeeperServer).PostLoginPassword(ctx, in)
+		return srv.(GophkeeperServer).ListEmergencyAccess(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/proto.Gophkeeper/PostLoginPassword",
+		FullMethod: "/proto.Gophkeeper/ListEmergencyAccess",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(GophkeeperServer).PostLoginPassword(ctx, req.(*SendLoginPasswordRequest))
+		return srv.(GophkeeperServer).ListEmergencyAccess(ctx, req.(*emptypb.Empty))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Gophkeeper_PostTextBinary_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(SendTextBinaryRequest)
+func _Gophkeeper_RequestEmergencyAccess_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EmergencyAccessRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(GophkeeperServer).PostTextBinary(ctx, in)
+		return srv.(GophkeeperServer).RequestEmergencyAccess(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/proto.Gophkeeper/PostTextBinary",
+		FullMethod: "/proto.Gophkeeper/RequestEmergencyAccess",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(GophkeeperServer).PostTextBinary(ctx, req.(*SendTextBinaryRequest))
+		return srv.(GophkeeperServer).RequestEmergencyAccess(ctx, req.(*EmergencyAccessRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Gophkeeper_GetTextsBinaries_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(emptypb.Empty)
+func _Gophkeeper_ApproveEmergencyAccess_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EmergencyAccessRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(GophkeeperServer).GetTextsBinaries(ctx, in)
+		return srv.(GophkeeperServer).ApproveEmergencyAccess(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/proto.Gophkeeper/GetTextsBinaries",
+		FullMethod: "/proto.Gophkeeper/ApproveEmergencyAccess",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(GophkeeperServer).GetTextsBinaries(ctx, req.(*emptypb.Empty))
+		return srv.(GophkeeperServer).ApproveEmergencyAccess(ctx, req.(*EmergencyAccessRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Gophkeeper_GetLoginsPasswords_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(emptypb.Empty)
+func _Gophkeeper_RejectEmergencyAccess_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EmergencyAccessRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(GophkeeperServer).GetLoginsPasswords(ctx, in)
+		return srv.(GophkeeperServer).RejectEmergencyAccess(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/proto.Gophkeeper/GetLoginsPasswords",
+		FullMethod: "/proto.Gophkeeper/RejectEmergencyAccess",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(GophkeeperServer).GetLoginsPasswords(ctx, req.(*emptypb.Empty))
+		return srv.(GophkeeperServer).RejectEmergencyAccess(ctx, req.(*EmergencyAccessRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Gophkeeper_GetBankCards_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(emptypb.Empty)
+func _Gophkeeper_SetEmergencyEntries_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetEmergencyEntriesRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(GophkeeperServer).GetBankCards(ctx, in)
+		return srv.(GophkeeperServer).SetEmergencyEntries(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/proto.Gophkeeper/GetBankCards",
+		FullMethod: "/proto.Gophkeeper/SetEmergencyEntries",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(GophkeeperServer).GetBankCards(ctx, req.(*emptypb.Empty))
+		return srv.(GophkeeperServer).SetEmergencyEntries(ctx, req.(*SetEmergencyEntriesRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Gophkeeper_StreamEmergencyEntries_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(emptypb.Empty)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(GophkeeperServer).StreamEmergencyEntries(m, &gophkeeperStreamEmergencyEntriesServer{stream})
+}
+
+type Gophkeeper_StreamEmergencyEntriesServer interface {
+	Send(*EmergencyEntry) error
+	grpc.ServerStream
+}
+
+type gophkeeperStreamEmergencyEntriesServer struct {
+	grpc.ServerStream
+}
+
+func (x *gophkeeperStreamEmergencyEntriesServer) Send(m *EmergencyEntry) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Gophkeeper_StreamEmergencyVault_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(EmergencyAccessRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(GophkeeperServer).StreamEmergencyVault(m, &gophkeeperStreamEmergencyVaultServer{stream})
+}
+
+type Gophkeeper_StreamEmergencyVaultServer interface {
+	Send(*EmergencyEntry) error
+	grpc.ServerStream
+}
+
+type gophkeeperStreamEmergencyVaultServer struct {
+	grpc.ServerStream
+}
+
+func (x *gophkeeperStreamEmergencyVaultServer) Send(m *EmergencyEntry) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 // Gophkeeper_ServiceDesc is the grpc.ServiceDesc for Gophkeeper service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -461,7 +1711,153 @@ var Gophkeeper_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetBankCards",
 			Handler:    _Gophkeeper_GetBankCards_Handler,
 		},
+		{
+			MethodName: "BatchUpsert",
+			Handler:    _Gophkeeper_BatchUpsert_Handler,
+		},
+		{
+			MethodName: "SearchEntries",
+			Handler:    _Gophkeeper_SearchEntries_Handler,
+		},
+		{
+			MethodName: "SetPublicKey",
+			Handler:    _Gophkeeper_SetPublicKey_Handler,
+		},
+		{
+			MethodName: "GetPublicKey",
+			Handler:    _Gophkeeper_GetPublicKey_Handler,
+		},
+		{
+			MethodName: "ShareEntry",
+			Handler:    _Gophkeeper_ShareEntry_Handler,
+		},
+		{
+			MethodName: "RevokeShare",
+			Handler:    _Gophkeeper_RevokeShare_Handler,
+		},
+		{
+			MethodName: "GetShares",
+			Handler:    _Gophkeeper_GetShares_Handler,
+		},
+		{
+			MethodName: "GetSharedWithMe",
+			Handler:    _Gophkeeper_GetSharedWithMe_Handler,
+		},
+		{
+			MethodName: "UpdateSharedEntry",
+			Handler:    _Gophkeeper_UpdateSharedEntry_Handler,
+		},
+		{
+			MethodName: "SetSharePayload",
+			Handler:    _Gophkeeper_SetSharePayload_Handler,
+		},
+		{
+			MethodName: "CreateCollection",
+			Handler:    _Gophkeeper_CreateCollection_Handler,
+		},
+		{
+			MethodName: "GetCollections",
+			Handler:    _Gophkeeper_GetCollections_Handler,
+		},
+		{
+			MethodName: "DeleteCollection",
+			Handler:    _Gophkeeper_DeleteCollection_Handler,
+		},
+		{
+			MethodName: "InviteMember",
+			Handler:    _Gophkeeper_InviteMember_Handler,
+		},
+		{
+			MethodName: "GetInvitations",
+			Handler:    _Gophkeeper_GetInvitations_Handler,
+		},
+		{
+			MethodName: "RespondInvitation",
+			Handler:    _Gophkeeper_RespondInvitation_Handler,
+		},
+		{
+			MethodName: "GetMembers",
+			Handler:    _Gophkeeper_GetMembers_Handler,
+		},
+		{
+			MethodName: "SetMemberRole",
+			Handler:    _Gophkeeper_SetMemberRole_Handler,
+		},
+		{
+			MethodName: "RemoveMember",
+			Handler:    _Gophkeeper_RemoveMember_Handler,
+		},
+		{
+			MethodName: "GetCollectionEvents",
+			Handler:    _Gophkeeper_GetCollectionEvents_Handler,
+		},
+		{
+			MethodName: "ListAuditEvents",
+			Handler:    _Gophkeeper_ListAuditEvents_Handler,
+		},
+		{
+			MethodName: "ListSessions",
+			Handler:    _Gophkeeper_ListSessions_Handler,
+		},
+		{
+			MethodName: "RevokeSession",
+			Handler:    _Gophkeeper_RevokeSession_Handler,
+		},
+		{
+			MethodName: "SetTrustedContact",
+			Handler:    _Gophkeeper_SetTrustedContact_Handler,
+		},
+		{
+			MethodName: "RemoveTrustedContact",
+			Handler:    _Gophkeeper_RemoveTrustedContact_Handler,
+		},
+		{
+			MethodName: "ListEmergencyAccess",
+			Handler:    _Gophkeeper_ListEmergencyAccess_Handler,
+		},
+		{
+			MethodName: "RequestEmergencyAccess",
+			Handler:    _Gophkeeper_RequestEmergencyAccess_Handler,
+		},
+		{
+			MethodName: "ApproveEmergencyAccess",
+			Handler:    _Gophkeeper_ApproveEmergencyAccess_Handler,
+		},
+		{
+			MethodName: "RejectEmergencyAccess",
+			Handler:    _Gophkeeper_RejectEmergencyAccess_Handler,
+		},
+		{
+			MethodName: "SetEmergencyEntries",
+			Handler:    _Gophkeeper_SetEmergencyEntries_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamTextsBinaries",
+			Handler:       _Gophkeeper_StreamTextsBinaries_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamLoginsPasswords",
+			Handler:       _Gophkeeper_StreamLoginsPasswords_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamBankCards",
+			Handler:       _Gophkeeper_StreamBankCards_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamEmergencyEntries",
+			Handler:       _Gophkeeper_StreamEmergencyEntries_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamEmergencyVault",
+			Handler:       _Gophkeeper_StreamEmergencyVault_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "gophkeeper.proto",
 }
@@ -0,0 +1,141 @@
+// Package keys provides key pairs of users and record keys of shared entries, which clients seal entries with and wrap
+// for every user the entries are shared with. Private keys never leave clients, the server stores public keys only.
+package keys
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/nacl/box"
+)
+
+// keySize is the size of user keys and record keys in bytes.
+const keySize = 32
+
+// ErrInvalidKey is returned when a key cannot be used to open a record or a wrapped key.
+var ErrInvalidKey = errors.New("invalid key")
+
+// NewKeyPair creates a user key pair used to wrap record keys shared with the user.
+func NewKeyPair() (string, string, error) {
+	publicKey, privateKey, err := box.GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", err
+	}
+	return hex.EncodeToString(publicKey[:]), hex.EncodeToString(privateKey[:]), nil
+}
+
+// ValidatePublicKey checks whether a public key of a key pair is well-formed.
+func ValidatePublicKey(publicKey string) error {
+	_, err := decodeKey(publicKey)
+	return err
+}
+
+// NewRecordKey creates a random key of a shared record.
+func NewRecordKey() (string, error) {
+	key := make([]byte, keySize)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(key), nil
+}
+
+// SealRecord encrypts a shared record with its key, every record gets a random nonce prepended to the ciphertext.
+func SealRecord(recordKey, data string) (string, error) {
+	aead, err := recordAEAD(recordKey)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(aead.Seal(nonce, nonce, []byte(data), nil)), nil
+}
+
+// OpenRecord decrypts a shared record with its key.
+func OpenRecord(recordKey, msg string) (string, error) {
+	aead, err := recordAEAD(recordKey)
+	if err != nil {
+		return "", err
+	}
+	sealed, err := hex.DecodeString(msg)
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return "", ErrInvalidKey
+	}
+	data, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], nil)
+	if err != nil {
+		return "", ErrInvalidKey
+	}
+	return string(data), nil
+}
+
+// WrapKey encrypts a record key for the owner of a public key.
+func WrapKey(recordKey, publicKey string) (string, error) {
+	recipient, err := decodeKey(publicKey)
+	if err != nil {
+		return "", err
+	}
+	wrapped, err := box.SealAnonymous(nil, []byte(recordKey), recipient, rand.Reader)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(wrapped), nil
+}
+
+// UnwrapKey decrypts a record key wrapped for a key pair.
+func UnwrapKey(wrappedKey, publicKey, privateKey string) (string, error) {
+	public, err := decodeKey(publicKey)
+	if err != nil {
+		return "", err
+	}
+	private, err := decodeKey(privateKey)
+	if err != nil {
+		return "", err
+	}
+	wrapped, err := hex.DecodeString(wrappedKey)
+	if err != nil {
+		return "", err
+	}
+	recordKey, ok := box.OpenAnonymous(nil, wrapped, public, private)
+	if !ok {
+		return "", ErrInvalidKey
+	}
+	return string(recordKey), nil
+}
+
+// recordAEAD returns an AES-GCM instance keyed by a record key.
+func recordAEAD(recordKey string) (cipher.AEAD, error) {
+	key, err := hex.DecodeString(recordKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(key) != keySize {
+		return nil, ErrInvalidKey
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// decodeKey decodes a hex-encoded key of a key pair.
+func decodeKey(key string) (*[keySize]byte, error) {
+	decoded, err := hex.DecodeString(key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidKey, err)
+	}
+	if len(decoded) != keySize {
+		return nil, fmt.Errorf("%w: key must be %d bytes long", ErrInvalidKey, keySize)
+	}
+	var array [keySize]byte
+	copy(array[:], decoded)
+	return &array, nil
+}
@@ -0,0 +1,44 @@
+package keys
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrapKey(t *testing.T) {
+	publicKey, privateKey, err := NewKeyPair()
+	assert.Equal(t, nil, err)
+	otherPublicKey, otherPrivateKey, _ := NewKeyPair()
+	recordKey, err := NewRecordKey()
+	assert.Equal(t, nil, err)
+	wrappedKey, err := WrapKey(recordKey, publicKey)
+	assert.Equal(t, nil, err)
+	unwrappedKey, err := UnwrapKey(wrappedKey, publicKey, privateKey)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, recordKey, unwrappedKey)
+	_, err = UnwrapKey(wrappedKey, otherPublicKey, otherPrivateKey)
+	assert.Equal(t, ErrInvalidKey, err)
+}
+
+func TestSealRecord(t *testing.T) {
+	recordKey, _ := NewRecordKey()
+	otherRecordKey, _ := NewRecordKey()
+	sealed, err := SealRecord(recordKey, "generic_data")
+	assert.Equal(t, nil, err)
+	sealedAgain, _ := SealRecord(recordKey, "generic_data")
+	assert.NotEqual(t, sealed, sealedAgain)
+	data, err := OpenRecord(recordKey, sealed)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "generic_data", data)
+	_, err = OpenRecord(otherRecordKey, sealed)
+	assert.Equal(t, ErrInvalidKey, err)
+}
+
+func TestValidatePublicKey(t *testing.T) {
+	publicKey, _, _ := NewKeyPair()
+	assert.Equal(t, nil, ValidatePublicKey(publicKey))
+	assert.Equal(t, true, errors.Is(ValidatePublicKey("abcd"), ErrInvalidKey))
+	assert.Equal(t, true, errors.Is(ValidatePublicKey("not hex"), ErrInvalidKey))
+}
@@ -33,6 +33,20 @@ func (m *MockCipher) EXPECT() *MockCipherMockRecorder {
 	return m.recorder
 }
 
+// BlindIndex mocks base method.
+func (m *MockCipher) BlindIndex(userID, term string) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BlindIndex", userID, term)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// BlindIndex indicates an expected call of BlindIndex.
+func (mr *MockCipherMockRecorder) BlindIndex(userID, term interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlindIndex", reflect.TypeOf((*MockCipher)(nil).BlindIndex), userID, term)
+}
+
 // Decode mocks base method.
 func (m *MockCipher) Decode(msg string) (string, error) {
 	m.ctrl.T.Helper()
@@ -62,6 +76,22 @@ func (mr *MockCipherMockRecorder) Encode(data interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Encode", reflect.TypeOf((*MockCipher)(nil).Encode), data)
 }
 
+// NewSessionKey mocks base method.
+func (m *MockCipher) NewSessionKey() (string, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NewSessionKey")
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// NewSessionKey indicates an expected call of NewSessionKey.
+func (mr *MockCipherMockRecorder) NewSessionKey() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewSessionKey", reflect.TypeOf((*MockCipher)(nil).NewSessionKey))
+}
+
 // NewToken mocks base method.
 func (m *MockCipher) NewToken() (string, string) {
 	m.ctrl.T.Helper()
@@ -77,6 +107,34 @@ func (mr *MockCipherMockRecorder) NewToken() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewToken", reflect.TypeOf((*MockCipher)(nil).NewToken))
 }
 
+// SessionDigest mocks base method.
+func (m *MockCipher) SessionDigest(sessionKey string) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SessionDigest", sessionKey)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// SessionDigest indicates an expected call of SessionDigest.
+func (mr *MockCipherMockRecorder) SessionDigest(sessionKey interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SessionDigest", reflect.TypeOf((*MockCipher)(nil).SessionDigest), sessionKey)
+}
+
+// SignCheckpoint mocks base method.
+func (m *MockCipher) SignCheckpoint(message string) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SignCheckpoint", message)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// SignCheckpoint indicates an expected call of SignCheckpoint.
+func (mr *MockCipherMockRecorder) SignCheckpoint(message interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignCheckpoint", reflect.TypeOf((*MockCipher)(nil).SignCheckpoint), message)
+}
+
 // ValidateToken mocks base method.
 func (m *MockCipher) ValidateToken(token string) (string, error) {
 	m.ctrl.T.Helper()
@@ -91,3 +149,17 @@ func (mr *MockCipherMockRecorder) ValidateToken(token interface{}) *gomock.Call
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidateToken", reflect.TypeOf((*MockCipher)(nil).ValidateToken), token)
 }
+
+// VerifyCheckpoint mocks base method.
+func (m *MockCipher) VerifyCheckpoint(message, signature string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyCheckpoint", message, signature)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// VerifyCheckpoint indicates an expected call of VerifyCheckpoint.
+func (mr *MockCipherMockRecorder) VerifyCheckpoint(message, signature interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyCheckpoint", reflect.TypeOf((*MockCipher)(nil).VerifyCheckpoint), message, signature)
+}
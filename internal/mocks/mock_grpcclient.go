@@ -243,6 +243,45 @@ func (mr *MockTextBinarySenderMockRecorder) SendTextBinary(arg0 interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendTextBinary", reflect.TypeOf((*MockTextBinarySender)(nil).SendTextBinary), arg0)
 }
 
+// MockBatchSender is a mock of BatchSender interface.
+type MockBatchSender struct {
+	ctrl     *gomock.Controller
+	recorder *MockBatchSenderMockRecorder
+}
+
+// MockBatchSenderMockRecorder is the mock recorder for MockBatchSender.
+type MockBatchSenderMockRecorder struct {
+	mock *MockBatchSender
+}
+
+// NewMockBatchSender creates a new mock instance.
+func NewMockBatchSender(ctrl *gomock.Controller) *MockBatchSender {
+	mock := &MockBatchSender{ctrl: ctrl}
+	mock.recorder = &MockBatchSenderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBatchSender) EXPECT() *MockBatchSenderMockRecorder {
+	return m.recorder
+}
+
+// SendBatch mocks base method.
+func (m *MockBatchSender) SendBatch(arg0 modelstorage.Batch, arg1 bool) ([]modelstorage.BatchItemResult, codes.Code, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendBatch", arg0, arg1)
+	ret0, _ := ret[0].([]modelstorage.BatchItemResult)
+	ret1, _ := ret[1].(codes.Code)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// SendBatch indicates an expected call of SendBatch.
+func (mr *MockBatchSenderMockRecorder) SendBatch(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendBatch", reflect.TypeOf((*MockBatchSender)(nil).SendBatch), arg0, arg1)
+}
+
 // MockRemover is a mock of Remover interface.
 type MockRemover struct {
 	ctrl     *gomock.Controller
@@ -311,246 +350,1380 @@ func (mr *MockRemoverMockRecorder) RemoveTextBinary(arg0 interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveTextBinary", reflect.TypeOf((*MockRemover)(nil).RemoveTextBinary), arg0)
 }
 
-// MockClientAuthorizer is a mock of ClientAuthorizer interface.
-type MockClientAuthorizer struct {
+// MockClientSharer is a mock of ClientSharer interface.
+type MockClientSharer struct {
 	ctrl     *gomock.Controller
-	recorder *MockClientAuthorizerMockRecorder
+	recorder *MockClientSharerMockRecorder
 }
 
-// MockClientAuthorizerMockRecorder is the mock recorder for MockClientAuthorizer.
-type MockClientAuthorizerMockRecorder struct {
-	mock *MockClientAuthorizer
+// MockClientSharerMockRecorder is the mock recorder for MockClientSharer.
+type MockClientSharerMockRecorder struct {
+	mock *MockClientSharer
 }
 
-// NewMockClientAuthorizer creates a new mock instance.
-func NewMockClientAuthorizer(ctrl *gomock.Controller) *MockClientAuthorizer {
-	mock := &MockClientAuthorizer{ctrl: ctrl}
-	mock.recorder = &MockClientAuthorizerMockRecorder{mock}
+// NewMockClientSharer creates a new mock instance.
+func NewMockClientSharer(ctrl *gomock.Controller) *MockClientSharer {
+	mock := &MockClientSharer{ctrl: ctrl}
+	mock.recorder = &MockClientSharerMockRecorder{mock}
 	return mock
 }
 
 // EXPECT returns an object that allows the caller to indicate expected use.
-func (m *MockClientAuthorizer) EXPECT() *MockClientAuthorizerMockRecorder {
+func (m *MockClientSharer) EXPECT() *MockClientSharerMockRecorder {
 	return m.recorder
 }
 
-// Login mocks base method.
-func (m *MockClientAuthorizer) Login(arg0 modelstorage.RegisterLogin) (codes.Code, error) {
+// GetPublicKey mocks base method.
+func (m *MockClientSharer) GetPublicKey(login string) (string, codes.Code, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Login", arg0)
+	ret := m.ctrl.Call(m, "GetPublicKey", login)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(codes.Code)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetPublicKey indicates an expected call of GetPublicKey.
+func (mr *MockClientSharerMockRecorder) GetPublicKey(login interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPublicKey", reflect.TypeOf((*MockClientSharer)(nil).GetPublicKey), login)
+}
+
+// GetSharedWithMe mocks base method.
+func (m *MockClientSharer) GetSharedWithMe() ([]modelstorage.SharedEntry, codes.Code, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSharedWithMe")
+	ret0, _ := ret[0].([]modelstorage.SharedEntry)
+	ret1, _ := ret[1].(codes.Code)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetSharedWithMe indicates an expected call of GetSharedWithMe.
+func (mr *MockClientSharerMockRecorder) GetSharedWithMe() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSharedWithMe", reflect.TypeOf((*MockClientSharer)(nil).GetSharedWithMe))
+}
+
+// GetShares mocks base method.
+func (m *MockClientSharer) GetShares() ([]modelstorage.Share, codes.Code, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetShares")
+	ret0, _ := ret[0].([]modelstorage.Share)
+	ret1, _ := ret[1].(codes.Code)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetShares indicates an expected call of GetShares.
+func (mr *MockClientSharerMockRecorder) GetShares() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetShares", reflect.TypeOf((*MockClientSharer)(nil).GetShares))
+}
+
+// RevokeShare mocks base method.
+func (m *MockClientSharer) RevokeShare(identifier, db, recipient string, sealed modelstorage.SealedEntry) (codes.Code, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeShare", identifier, db, recipient, sealed)
 	ret0, _ := ret[0].(codes.Code)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// Login indicates an expected call of Login.
-func (mr *MockClientAuthorizerMockRecorder) Login(arg0 interface{}) *gomock.Call {
+// RevokeShare indicates an expected call of RevokeShare.
+func (mr *MockClientSharerMockRecorder) RevokeShare(identifier, db, recipient, sealed interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockClientAuthorizer)(nil).Login), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeShare", reflect.TypeOf((*MockClientSharer)(nil).RevokeShare), identifier, db, recipient, sealed)
 }
 
-// Register mocks base method.
-func (m *MockClientAuthorizer) Register(arg0 modelstorage.RegisterLogin) (codes.Code, error) {
+// SetPublicKey mocks base method.
+func (m *MockClientSharer) SetPublicKey(publicKey string) (codes.Code, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Register", arg0)
+	ret := m.ctrl.Call(m, "SetPublicKey", publicKey)
 	ret0, _ := ret[0].(codes.Code)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// Register indicates an expected call of Register.
-func (mr *MockClientAuthorizerMockRecorder) Register(arg0 interface{}) *gomock.Call {
+// SetPublicKey indicates an expected call of SetPublicKey.
+func (mr *MockClientSharerMockRecorder) SetPublicKey(publicKey interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Register", reflect.TypeOf((*MockClientAuthorizer)(nil).Register), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPublicKey", reflect.TypeOf((*MockClientSharer)(nil).SetPublicKey), publicKey)
 }
 
-// MockGRPCClient is a mock of GRPCClient interface.
-type MockGRPCClient struct {
+// SetSharePayload mocks base method.
+func (m *MockClientSharer) SetSharePayload(identifier, db, payload string) (codes.Code, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetSharePayload", identifier, db, payload)
+	ret0, _ := ret[0].(codes.Code)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetSharePayload indicates an expected call of SetSharePayload.
+func (mr *MockClientSharerMockRecorder) SetSharePayload(identifier, db, payload interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSharePayload", reflect.TypeOf((*MockClientSharer)(nil).SetSharePayload), identifier, db, payload)
+}
+
+// ShareEntry mocks base method.
+func (m *MockClientSharer) ShareEntry(identifier, db, recipient, permission string, sealed modelstorage.SealedEntry) (codes.Code, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ShareEntry", identifier, db, recipient, permission, sealed)
+	ret0, _ := ret[0].(codes.Code)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ShareEntry indicates an expected call of ShareEntry.
+func (mr *MockClientSharerMockRecorder) ShareEntry(identifier, db, recipient, permission, sealed interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ShareEntry", reflect.TypeOf((*MockClientSharer)(nil).ShareEntry), identifier, db, recipient, permission, sealed)
+}
+
+// UpdateSharedEntry mocks base method.
+func (m *MockClientSharer) UpdateSharedEntry(entry modelstorage.SharedEntry, payload string) (codes.Code, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSharedEntry", entry, payload)
+	ret0, _ := ret[0].(codes.Code)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateSharedEntry indicates an expected call of UpdateSharedEntry.
+func (mr *MockClientSharerMockRecorder) UpdateSharedEntry(entry, payload interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSharedEntry", reflect.TypeOf((*MockClientSharer)(nil).UpdateSharedEntry), entry, payload)
+}
+
+// MockClientCollector is a mock of ClientCollector interface.
+type MockClientCollector struct {
 	ctrl     *gomock.Controller
-	recorder *MockGRPCClientMockRecorder
+	recorder *MockClientCollectorMockRecorder
 }
 
-// MockGRPCClientMockRecorder is the mock recorder for MockGRPCClient.
-type MockGRPCClientMockRecorder struct {
-	mock *MockGRPCClient
+// MockClientCollectorMockRecorder is the mock recorder for MockClientCollector.
+type MockClientCollectorMockRecorder struct {
+	mock *MockClientCollector
 }
 
-// NewMockGRPCClient creates a new mock instance.
-func NewMockGRPCClient(ctrl *gomock.Controller) *MockGRPCClient {
-	mock := &MockGRPCClient{ctrl: ctrl}
-	mock.recorder = &MockGRPCClientMockRecorder{mock}
+// NewMockClientCollector creates a new mock instance.
+func NewMockClientCollector(ctrl *gomock.Controller) *MockClientCollector {
+	mock := &MockClientCollector{ctrl: ctrl}
+	mock.recorder = &MockClientCollectorMockRecorder{mock}
 	return mock
 }
 
 // EXPECT returns an object that allows the caller to indicate expected use.
-func (m *MockGRPCClient) EXPECT() *MockGRPCClientMockRecorder {
+func (m *MockClientCollector) EXPECT() *MockClientCollectorMockRecorder {
 	return m.recorder
 }
 
-// GetBankCards mocks base method.
-func (m *MockGRPCClient) GetBankCards() (map[string]modelstorage.BankCard, codes.Code, error) {
+// CreateCollection mocks base method.
+func (m *MockClientCollector) CreateCollection(name string) (modelstorage.Collection, codes.Code, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetBankCards")
-	ret0, _ := ret[0].(map[string]modelstorage.BankCard)
+	ret := m.ctrl.Call(m, "CreateCollection", name)
+	ret0, _ := ret[0].(modelstorage.Collection)
 	ret1, _ := ret[1].(codes.Code)
 	ret2, _ := ret[2].(error)
 	return ret0, ret1, ret2
 }
 
-// GetBankCards indicates an expected call of GetBankCards.
-func (mr *MockGRPCClientMockRecorder) GetBankCards() *gomock.Call {
+// CreateCollection indicates an expected call of CreateCollection.
+func (mr *MockClientCollectorMockRecorder) CreateCollection(name interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBankCards", reflect.TypeOf((*MockGRPCClient)(nil).GetBankCards))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCollection", reflect.TypeOf((*MockClientCollector)(nil).CreateCollection), name)
 }
 
-// GetLoginsPasswords mocks base method.
-func (m *MockGRPCClient) GetLoginsPasswords() (map[string]modelstorage.LoginAndPassword, codes.Code, error) {
+// DeleteCollection mocks base method.
+func (m *MockClientCollector) DeleteCollection(collectionID string) (codes.Code, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetLoginsPasswords")
-	ret0, _ := ret[0].(map[string]modelstorage.LoginAndPassword)
+	ret := m.ctrl.Call(m, "DeleteCollection", collectionID)
+	ret0, _ := ret[0].(codes.Code)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteCollection indicates an expected call of DeleteCollection.
+func (mr *MockClientCollectorMockRecorder) DeleteCollection(collectionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCollection", reflect.TypeOf((*MockClientCollector)(nil).DeleteCollection), collectionID)
+}
+
+// GetCollectionEvents mocks base method.
+func (m *MockClientCollector) GetCollectionEvents(collectionID string) ([]modelstorage.CollectionEvent, codes.Code, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCollectionEvents", collectionID)
+	ret0, _ := ret[0].([]modelstorage.CollectionEvent)
 	ret1, _ := ret[1].(codes.Code)
 	ret2, _ := ret[2].(error)
 	return ret0, ret1, ret2
 }
 
-// GetLoginsPasswords indicates an expected call of GetLoginsPasswords.
-func (mr *MockGRPCClientMockRecorder) GetLoginsPasswords() *gomock.Call {
+// GetCollectionEvents indicates an expected call of GetCollectionEvents.
+func (mr *MockClientCollectorMockRecorder) GetCollectionEvents(collectionID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoginsPasswords", reflect.TypeOf((*MockGRPCClient)(nil).GetLoginsPasswords))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCollectionEvents", reflect.TypeOf((*MockClientCollector)(nil).GetCollectionEvents), collectionID)
 }
 
-// GetTextsBinaries mocks base method.
-func (m *MockGRPCClient) GetTextsBinaries() (map[string]modelstorage.TextOrBinary, codes.Code, error) {
+// GetCollections mocks base method.
+func (m *MockClientCollector) GetCollections() ([]modelstorage.Collection, codes.Code, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetTextsBinaries")
-	ret0, _ := ret[0].(map[string]modelstorage.TextOrBinary)
+	ret := m.ctrl.Call(m, "GetCollections")
+	ret0, _ := ret[0].([]modelstorage.Collection)
 	ret1, _ := ret[1].(codes.Code)
 	ret2, _ := ret[2].(error)
 	return ret0, ret1, ret2
 }
 
-// GetTextsBinaries indicates an expected call of GetTextsBinaries.
-func (mr *MockGRPCClientMockRecorder) GetTextsBinaries() *gomock.Call {
+// GetCollections indicates an expected call of GetCollections.
+func (mr *MockClientCollectorMockRecorder) GetCollections() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTextsBinaries", reflect.TypeOf((*MockGRPCClient)(nil).GetTextsBinaries))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCollections", reflect.TypeOf((*MockClientCollector)(nil).GetCollections))
 }
 
-// Login mocks base method.
-func (m *MockGRPCClient) Login(arg0 modelstorage.RegisterLogin) (codes.Code, error) {
+// GetInvitations mocks base method.
+func (m *MockClientCollector) GetInvitations() ([]modelstorage.CollectionInvitation, codes.Code, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Login", arg0)
-	ret0, _ := ret[0].(codes.Code)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "GetInvitations")
+	ret0, _ := ret[0].([]modelstorage.CollectionInvitation)
+	ret1, _ := ret[1].(codes.Code)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
 }
 
-// Login indicates an expected call of Login.
-func (mr *MockGRPCClientMockRecorder) Login(arg0 interface{}) *gomock.Call {
+// GetInvitations indicates an expected call of GetInvitations.
+func (mr *MockClientCollectorMockRecorder) GetInvitations() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockGRPCClient)(nil).Login), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInvitations", reflect.TypeOf((*MockClientCollector)(nil).GetInvitations))
 }
 
-// Register mocks base method.
-func (m *MockGRPCClient) Register(arg0 modelstorage.RegisterLogin) (codes.Code, error) {
+// GetMembers mocks base method.
+func (m *MockClientCollector) GetMembers(collectionID string) ([]modelstorage.CollectionMember, codes.Code, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Register", arg0)
-	ret0, _ := ret[0].(codes.Code)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "GetMembers", collectionID)
+	ret0, _ := ret[0].([]modelstorage.CollectionMember)
+	ret1, _ := ret[1].(codes.Code)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
 }
 
-// Register indicates an expected call of Register.
-func (mr *MockGRPCClientMockRecorder) Register(arg0 interface{}) *gomock.Call {
+// GetMembers indicates an expected call of GetMembers.
+func (mr *MockClientCollectorMockRecorder) GetMembers(collectionID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Register", reflect.TypeOf((*MockGRPCClient)(nil).Register), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMembers", reflect.TypeOf((*MockClientCollector)(nil).GetMembers), collectionID)
 }
 
-// RemoveBankCard mocks base method.
-func (m *MockGRPCClient) RemoveBankCard(arg0 string) (codes.Code, error) {
+// InviteMember mocks base method.
+func (m *MockClientCollector) InviteMember(collectionID, login, role string) (codes.Code, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "RemoveBankCard", arg0)
+	ret := m.ctrl.Call(m, "InviteMember", collectionID, login, role)
 	ret0, _ := ret[0].(codes.Code)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// RemoveBankCard indicates an expected call of RemoveBankCard.
-func (mr *MockGRPCClientMockRecorder) RemoveBankCard(arg0 interface{}) *gomock.Call {
+// InviteMember indicates an expected call of InviteMember.
+func (mr *MockClientCollectorMockRecorder) InviteMember(collectionID, login, role interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveBankCard", reflect.TypeOf((*MockGRPCClient)(nil).RemoveBankCard), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InviteMember", reflect.TypeOf((*MockClientCollector)(nil).InviteMember), collectionID, login, role)
 }
 
-// RemoveLoginPassword mocks base method.
-func (m *MockGRPCClient) RemoveLoginPassword(arg0 string) (codes.Code, error) {
+// RemoveMember mocks base method.
+func (m *MockClientCollector) RemoveMember(collectionID, login string) (codes.Code, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "RemoveLoginPassword", arg0)
+	ret := m.ctrl.Call(m, "RemoveMember", collectionID, login)
 	ret0, _ := ret[0].(codes.Code)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// RemoveLoginPassword indicates an expected call of RemoveLoginPassword.
-func (mr *MockGRPCClientMockRecorder) RemoveLoginPassword(arg0 interface{}) *gomock.Call {
+// RemoveMember indicates an expected call of RemoveMember.
+func (mr *MockClientCollectorMockRecorder) RemoveMember(collectionID, login interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveLoginPassword", reflect.TypeOf((*MockGRPCClient)(nil).RemoveLoginPassword), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveMember", reflect.TypeOf((*MockClientCollector)(nil).RemoveMember), collectionID, login)
 }
 
-// RemoveTextBinary mocks base method.
-func (m *MockGRPCClient) RemoveTextBinary(arg0 string) (codes.Code, error) {
+// RespondInvitation mocks base method.
+func (m *MockClientCollector) RespondInvitation(invitationID int64, accept bool) (codes.Code, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "RemoveTextBinary", arg0)
+	ret := m.ctrl.Call(m, "RespondInvitation", invitationID, accept)
 	ret0, _ := ret[0].(codes.Code)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// RemoveTextBinary indicates an expected call of RemoveTextBinary.
-func (mr *MockGRPCClientMockRecorder) RemoveTextBinary(arg0 interface{}) *gomock.Call {
+// RespondInvitation indicates an expected call of RespondInvitation.
+func (mr *MockClientCollectorMockRecorder) RespondInvitation(invitationID, accept interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveTextBinary", reflect.TypeOf((*MockGRPCClient)(nil).RemoveTextBinary), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RespondInvitation", reflect.TypeOf((*MockClientCollector)(nil).RespondInvitation), invitationID, accept)
 }
 
-// SendBankCard mocks base method.
-func (m *MockGRPCClient) SendBankCard(arg0 modelstorage.BankCard) (codes.Code, error) {
+// SetMemberRole mocks base method.
+func (m *MockClientCollector) SetMemberRole(collectionID, login, role string) (codes.Code, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SendBankCard", arg0)
+	ret := m.ctrl.Call(m, "SetMemberRole", collectionID, login, role)
 	ret0, _ := ret[0].(codes.Code)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// SendBankCard indicates an expected call of SendBankCard.
-func (mr *MockGRPCClientMockRecorder) SendBankCard(arg0 interface{}) *gomock.Call {
+// SetMemberRole indicates an expected call of SetMemberRole.
+func (mr *MockClientCollectorMockRecorder) SetMemberRole(collectionID, login, role interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendBankCard", reflect.TypeOf((*MockGRPCClient)(nil).SendBankCard), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetMemberRole", reflect.TypeOf((*MockClientCollector)(nil).SetMemberRole), collectionID, login, role)
 }
 
-// SendLoginPassword mocks base method.
-func (m *MockGRPCClient) SendLoginPassword(arg0 modelstorage.LoginAndPassword) (codes.Code, error) {
+// MockClientAuditor is a mock of ClientAuditor interface.
+type MockClientAuditor struct {
+	ctrl     *gomock.Controller
+	recorder *MockClientAuditorMockRecorder
+}
+
+// MockClientAuditorMockRecorder is the mock recorder for MockClientAuditor.
+type MockClientAuditorMockRecorder struct {
+	mock *MockClientAuditor
+}
+
+// NewMockClientAuditor creates a new mock instance.
+func NewMockClientAuditor(ctrl *gomock.Controller) *MockClientAuditor {
+	mock := &MockClientAuditor{ctrl: ctrl}
+	mock.recorder = &MockClientAuditorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockClientAuditor) EXPECT() *MockClientAuditorMockRecorder {
+	return m.recorder
+}
+
+// ListAuditEvents mocks base method.
+func (m *MockClientAuditor) ListAuditEvents(beforeID int64, limit int) ([]modelstorage.AuditEvent, codes.Code, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SendLoginPassword", arg0)
+	ret := m.ctrl.Call(m, "ListAuditEvents", beforeID, limit)
+	ret0, _ := ret[0].([]modelstorage.AuditEvent)
+	ret1, _ := ret[1].(codes.Code)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListAuditEvents indicates an expected call of ListAuditEvents.
+func (mr *MockClientAuditorMockRecorder) ListAuditEvents(beforeID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAuditEvents", reflect.TypeOf((*MockClientAuditor)(nil).ListAuditEvents), beforeID, limit)
+}
+
+// MockClientAuthorizer is a mock of ClientAuthorizer interface.
+type MockClientAuthorizer struct {
+	ctrl     *gomock.Controller
+	recorder *MockClientAuthorizerMockRecorder
+}
+
+// MockClientAuthorizerMockRecorder is the mock recorder for MockClientAuthorizer.
+type MockClientAuthorizerMockRecorder struct {
+	mock *MockClientAuthorizer
+}
+
+// NewMockClientAuthorizer creates a new mock instance.
+func NewMockClientAuthorizer(ctrl *gomock.Controller) *MockClientAuthorizer {
+	mock := &MockClientAuthorizer{ctrl: ctrl}
+	mock.recorder = &MockClientAuthorizerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockClientAuthorizer) EXPECT() *MockClientAuthorizerMockRecorder {
+	return m.recorder
+}
+
+// Login mocks base method.
+func (m *MockClientAuthorizer) Login(arg0 modelstorage.RegisterLogin) (codes.Code, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Login", arg0)
 	ret0, _ := ret[0].(codes.Code)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// SendLoginPassword indicates an expected call of SendLoginPassword.
-func (mr *MockGRPCClientMockRecorder) SendLoginPassword(arg0 interface{}) *gomock.Call {
+// Login indicates an expected call of Login.
+func (mr *MockClientAuthorizerMockRecorder) Login(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendLoginPassword", reflect.TypeOf((*MockGRPCClient)(nil).SendLoginPassword), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockClientAuthorizer)(nil).Login), arg0)
 }
 
-// SendTextBinary mocks base method.
-func (m *MockGRPCClient) SendTextBinary(arg0 modelstorage.TextOrBinary) (codes.Code, error) {
+// Register mocks base method.
+func (m *MockClientAuthorizer) Register(arg0 modelstorage.RegisterLogin) (codes.Code, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SendTextBinary", arg0)
+	ret := m.ctrl.Call(m, "Register", arg0)
 	ret0, _ := ret[0].(codes.Code)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// SendTextBinary indicates an expected call of SendTextBinary.
-func (mr *MockGRPCClientMockRecorder) SendTextBinary(arg0 interface{}) *gomock.Call {
+// Register indicates an expected call of Register.
+func (mr *MockClientAuthorizerMockRecorder) Register(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendTextBinary", reflect.TypeOf((*MockGRPCClient)(nil).SendTextBinary), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Register", reflect.TypeOf((*MockClientAuthorizer)(nil).Register), arg0)
+}
+
+// MockClientSessionManager is a mock of ClientSessionManager interface.
+type MockClientSessionManager struct {
+	ctrl     *gomock.Controller
+	recorder *MockClientSessionManagerMockRecorder
+}
+
+// MockClientSessionManagerMockRecorder is the mock recorder for MockClientSessionManager.
+type MockClientSessionManagerMockRecorder struct {
+	mock *MockClientSessionManager
+}
+
+// NewMockClientSessionManager creates a new mock instance.
+func NewMockClientSessionManager(ctrl *gomock.Controller) *MockClientSessionManager {
+	mock := &MockClientSessionManager{ctrl: ctrl}
+	mock.recorder = &MockClientSessionManagerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockClientSessionManager) EXPECT() *MockClientSessionManagerMockRecorder {
+	return m.recorder
+}
+
+// ListSessions mocks base method.
+func (m *MockClientSessionManager) ListSessions() ([]modelstorage.Session, codes.Code, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSessions")
+	ret0, _ := ret[0].([]modelstorage.Session)
+	ret1, _ := ret[1].(codes.Code)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListSessions indicates an expected call of ListSessions.
+func (mr *MockClientSessionManagerMockRecorder) ListSessions() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSessions", reflect.TypeOf((*MockClientSessionManager)(nil).ListSessions))
+}
+
+// RevokeSession mocks base method.
+func (m *MockClientSessionManager) RevokeSession(sessionID int64) (codes.Code, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeSession", sessionID)
+	ret0, _ := ret[0].(codes.Code)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeSession indicates an expected call of RevokeSession.
+func (mr *MockClientSessionManagerMockRecorder) RevokeSession(sessionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeSession", reflect.TypeOf((*MockClientSessionManager)(nil).RevokeSession), sessionID)
+}
+
+// MockClientEmergencyAccessor is a mock of ClientEmergencyAccessor interface.
+type MockClientEmergencyAccessor struct {
+	ctrl     *gomock.Controller
+	recorder *MockClientEmergencyAccessorMockRecorder
+}
+
+// MockClientEmergencyAccessorMockRecorder is the mock recorder for MockClientEmergencyAccessor.
+type MockClientEmergencyAccessorMockRecorder struct {
+	mock *MockClientEmergencyAccessor
+}
+
+// NewMockClientEmergencyAccessor creates a new mock instance.
+func NewMockClientEmergencyAccessor(ctrl *gomock.Controller) *MockClientEmergencyAccessor {
+	mock := &MockClientEmergencyAccessor{ctrl: ctrl}
+	mock.recorder = &MockClientEmergencyAccessorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockClientEmergencyAccessor) EXPECT() *MockClientEmergencyAccessorMockRecorder {
+	return m.recorder
+}
+
+// ApproveEmergencyAccess mocks base method.
+func (m *MockClientEmergencyAccessor) ApproveEmergencyAccess(contact string) (codes.Code, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApproveEmergencyAccess", contact)
+	ret0, _ := ret[0].(codes.Code)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ApproveEmergencyAccess indicates an expected call of ApproveEmergencyAccess.
+func (mr *MockClientEmergencyAccessorMockRecorder) ApproveEmergencyAccess(contact interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApproveEmergencyAccess", reflect.TypeOf((*MockClientEmergencyAccessor)(nil).ApproveEmergencyAccess), contact)
+}
+
+// GetEmergencyEntries mocks base method.
+func (m *MockClientEmergencyAccessor) GetEmergencyEntries() ([]modelstorage.EmergencyEntry, codes.Code, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEmergencyEntries")
+	ret0, _ := ret[0].([]modelstorage.EmergencyEntry)
+	ret1, _ := ret[1].(codes.Code)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetEmergencyEntries indicates an expected call of GetEmergencyEntries.
+func (mr *MockClientEmergencyAccessorMockRecorder) GetEmergencyEntries() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEmergencyEntries", reflect.TypeOf((*MockClientEmergencyAccessor)(nil).GetEmergencyEntries))
+}
+
+// GetEmergencyVault mocks base method.
+func (m *MockClientEmergencyAccessor) GetEmergencyVault(owner string) ([]modelstorage.EmergencyEntry, codes.Code, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEmergencyVault", owner)
+	ret0, _ := ret[0].([]modelstorage.EmergencyEntry)
+	ret1, _ := ret[1].(codes.Code)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetEmergencyVault indicates an expected call of GetEmergencyVault.
+func (mr *MockClientEmergencyAccessorMockRecorder) GetEmergencyVault(owner interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEmergencyVault", reflect.TypeOf((*MockClientEmergencyAccessor)(nil).GetEmergencyVault), owner)
+}
+
+// ListEmergencyAccess mocks base method.
+func (m *MockClientEmergencyAccessor) ListEmergencyAccess() (modelstorage.EmergencyAccessList, codes.Code, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListEmergencyAccess")
+	ret0, _ := ret[0].(modelstorage.EmergencyAccessList)
+	ret1, _ := ret[1].(codes.Code)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListEmergencyAccess indicates an expected call of ListEmergencyAccess.
+func (mr *MockClientEmergencyAccessorMockRecorder) ListEmergencyAccess() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEmergencyAccess", reflect.TypeOf((*MockClientEmergencyAccessor)(nil).ListEmergencyAccess))
+}
+
+// RejectEmergencyAccess mocks base method.
+func (m *MockClientEmergencyAccessor) RejectEmergencyAccess(contact string) (codes.Code, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RejectEmergencyAccess", contact)
+	ret0, _ := ret[0].(codes.Code)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RejectEmergencyAccess indicates an expected call of RejectEmergencyAccess.
+func (mr *MockClientEmergencyAccessorMockRecorder) RejectEmergencyAccess(contact interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RejectEmergencyAccess", reflect.TypeOf((*MockClientEmergencyAccessor)(nil).RejectEmergencyAccess), contact)
+}
+
+// RemoveTrustedContact mocks base method.
+func (m *MockClientEmergencyAccessor) RemoveTrustedContact(login string) (codes.Code, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveTrustedContact", login)
+	ret0, _ := ret[0].(codes.Code)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemoveTrustedContact indicates an expected call of RemoveTrustedContact.
+func (mr *MockClientEmergencyAccessorMockRecorder) RemoveTrustedContact(login interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveTrustedContact", reflect.TypeOf((*MockClientEmergencyAccessor)(nil).RemoveTrustedContact), login)
+}
+
+// RequestEmergencyAccess mocks base method.
+func (m *MockClientEmergencyAccessor) RequestEmergencyAccess(owner string) (codes.Code, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestEmergencyAccess", owner)
+	ret0, _ := ret[0].(codes.Code)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RequestEmergencyAccess indicates an expected call of RequestEmergencyAccess.
+func (mr *MockClientEmergencyAccessorMockRecorder) RequestEmergencyAccess(owner interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestEmergencyAccess", reflect.TypeOf((*MockClientEmergencyAccessor)(nil).RequestEmergencyAccess), owner)
+}
+
+// SetEmergencyEntries mocks base method.
+func (m *MockClientEmergencyAccessor) SetEmergencyEntries(entries []modelstorage.EmergencyEntry) (codes.Code, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetEmergencyEntries", entries)
+	ret0, _ := ret[0].(codes.Code)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetEmergencyEntries indicates an expected call of SetEmergencyEntries.
+func (mr *MockClientEmergencyAccessorMockRecorder) SetEmergencyEntries(entries interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetEmergencyEntries", reflect.TypeOf((*MockClientEmergencyAccessor)(nil).SetEmergencyEntries), entries)
+}
+
+// SetTrustedContact mocks base method.
+func (m *MockClientEmergencyAccessor) SetTrustedContact(login string, waitDays int) (codes.Code, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTrustedContact", login, waitDays)
+	ret0, _ := ret[0].(codes.Code)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetTrustedContact indicates an expected call of SetTrustedContact.
+func (mr *MockClientEmergencyAccessorMockRecorder) SetTrustedContact(login, waitDays interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTrustedContact", reflect.TypeOf((*MockClientEmergencyAccessor)(nil).SetTrustedContact), login, waitDays)
+}
+
+// MockSessionKeeper is a mock of SessionKeeper interface.
+type MockSessionKeeper struct {
+	ctrl     *gomock.Controller
+	recorder *MockSessionKeeperMockRecorder
+}
+
+// MockSessionKeeperMockRecorder is the mock recorder for MockSessionKeeper.
+type MockSessionKeeperMockRecorder struct {
+	mock *MockSessionKeeper
+}
+
+// NewMockSessionKeeper creates a new mock instance.
+func NewMockSessionKeeper(ctrl *gomock.Controller) *MockSessionKeeper {
+	mock := &MockSessionKeeper{ctrl: ctrl}
+	mock.recorder = &MockSessionKeeperMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSessionKeeper) EXPECT() *MockSessionKeeperMockRecorder {
+	return m.recorder
+}
+
+// SetToken mocks base method.
+func (m *MockSessionKeeper) SetToken(token, sessionKey string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetToken", token, sessionKey)
+}
+
+// SetToken indicates an expected call of SetToken.
+func (mr *MockSessionKeeperMockRecorder) SetToken(token, sessionKey interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetToken", reflect.TypeOf((*MockSessionKeeper)(nil).SetToken), token, sessionKey)
+}
+
+// Token mocks base method.
+func (m *MockSessionKeeper) Token() (string, string) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Token")
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(string)
+	return ret0, ret1
+}
+
+// Token indicates an expected call of Token.
+func (mr *MockSessionKeeperMockRecorder) Token() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Token", reflect.TypeOf((*MockSessionKeeper)(nil).Token))
+}
+
+// MockGRPCClient is a mock of GRPCClient interface.
+type MockGRPCClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockGRPCClientMockRecorder
+}
+
+// MockGRPCClientMockRecorder is the mock recorder for MockGRPCClient.
+type MockGRPCClientMockRecorder struct {
+	mock *MockGRPCClient
+}
+
+// NewMockGRPCClient creates a new mock instance.
+func NewMockGRPCClient(ctrl *gomock.Controller) *MockGRPCClient {
+	mock := &MockGRPCClient{ctrl: ctrl}
+	mock.recorder = &MockGRPCClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockGRPCClient) EXPECT() *MockGRPCClientMockRecorder {
+	return m.recorder
+}
+
+// ApproveEmergencyAccess mocks base method.
+func (m *MockGRPCClient) ApproveEmergencyAccess(contact string) (codes.Code, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApproveEmergencyAccess", contact)
+	ret0, _ := ret[0].(codes.Code)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ApproveEmergencyAccess indicates an expected call of ApproveEmergencyAccess.
+func (mr *MockGRPCClientMockRecorder) ApproveEmergencyAccess(contact interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApproveEmergencyAccess", reflect.TypeOf((*MockGRPCClient)(nil).ApproveEmergencyAccess), contact)
+}
+
+// CreateCollection mocks base method.
+func (m *MockGRPCClient) CreateCollection(name string) (modelstorage.Collection, codes.Code, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCollection", name)
+	ret0, _ := ret[0].(modelstorage.Collection)
+	ret1, _ := ret[1].(codes.Code)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// CreateCollection indicates an expected call of CreateCollection.
+func (mr *MockGRPCClientMockRecorder) CreateCollection(name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCollection", reflect.TypeOf((*MockGRPCClient)(nil).CreateCollection), name)
+}
+
+// DeleteCollection mocks base method.
+func (m *MockGRPCClient) DeleteCollection(collectionID string) (codes.Code, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCollection", collectionID)
+	ret0, _ := ret[0].(codes.Code)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteCollection indicates an expected call of DeleteCollection.
+func (mr *MockGRPCClientMockRecorder) DeleteCollection(collectionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCollection", reflect.TypeOf((*MockGRPCClient)(nil).DeleteCollection), collectionID)
+}
+
+// GetBankCards mocks base method.
+func (m *MockGRPCClient) GetBankCards() (map[string]modelstorage.BankCard, codes.Code, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBankCards")
+	ret0, _ := ret[0].(map[string]modelstorage.BankCard)
+	ret1, _ := ret[1].(codes.Code)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetBankCards indicates an expected call of GetBankCards.
+func (mr *MockGRPCClientMockRecorder) GetBankCards() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBankCards", reflect.TypeOf((*MockGRPCClient)(nil).GetBankCards))
+}
+
+// GetCollectionEvents mocks base method.
+func (m *MockGRPCClient) GetCollectionEvents(collectionID string) ([]modelstorage.CollectionEvent, codes.Code, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCollectionEvents", collectionID)
+	ret0, _ := ret[0].([]modelstorage.CollectionEvent)
+	ret1, _ := ret[1].(codes.Code)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetCollectionEvents indicates an expected call of GetCollectionEvents.
+func (mr *MockGRPCClientMockRecorder) GetCollectionEvents(collectionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCollectionEvents", reflect.TypeOf((*MockGRPCClient)(nil).GetCollectionEvents), collectionID)
+}
+
+// GetCollections mocks base method.
+func (m *MockGRPCClient) GetCollections() ([]modelstorage.Collection, codes.Code, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCollections")
+	ret0, _ := ret[0].([]modelstorage.Collection)
+	ret1, _ := ret[1].(codes.Code)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetCollections indicates an expected call of GetCollections.
+func (mr *MockGRPCClientMockRecorder) GetCollections() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCollections", reflect.TypeOf((*MockGRPCClient)(nil).GetCollections))
+}
+
+// GetEmergencyEntries mocks base method.
+func (m *MockGRPCClient) GetEmergencyEntries() ([]modelstorage.EmergencyEntry, codes.Code, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEmergencyEntries")
+	ret0, _ := ret[0].([]modelstorage.EmergencyEntry)
+	ret1, _ := ret[1].(codes.Code)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetEmergencyEntries indicates an expected call of GetEmergencyEntries.
+func (mr *MockGRPCClientMockRecorder) GetEmergencyEntries() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEmergencyEntries", reflect.TypeOf((*MockGRPCClient)(nil).GetEmergencyEntries))
+}
+
+// GetEmergencyVault mocks base method.
+func (m *MockGRPCClient) GetEmergencyVault(owner string) ([]modelstorage.EmergencyEntry, codes.Code, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEmergencyVault", owner)
+	ret0, _ := ret[0].([]modelstorage.EmergencyEntry)
+	ret1, _ := ret[1].(codes.Code)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetEmergencyVault indicates an expected call of GetEmergencyVault.
+func (mr *MockGRPCClientMockRecorder) GetEmergencyVault(owner interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEmergencyVault", reflect.TypeOf((*MockGRPCClient)(nil).GetEmergencyVault), owner)
+}
+
+// GetInvitations mocks base method.
+func (m *MockGRPCClient) GetInvitations() ([]modelstorage.CollectionInvitation, codes.Code, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInvitations")
+	ret0, _ := ret[0].([]modelstorage.CollectionInvitation)
+	ret1, _ := ret[1].(codes.Code)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetInvitations indicates an expected call of GetInvitations.
+func (mr *MockGRPCClientMockRecorder) GetInvitations() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInvitations", reflect.TypeOf((*MockGRPCClient)(nil).GetInvitations))
+}
+
+// GetLoginsPasswords mocks base method.
+func (m *MockGRPCClient) GetLoginsPasswords() (map[string]modelstorage.LoginAndPassword, codes.Code, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLoginsPasswords")
+	ret0, _ := ret[0].(map[string]modelstorage.LoginAndPassword)
+	ret1, _ := ret[1].(codes.Code)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetLoginsPasswords indicates an expected call of GetLoginsPasswords.
+func (mr *MockGRPCClientMockRecorder) GetLoginsPasswords() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoginsPasswords", reflect.TypeOf((*MockGRPCClient)(nil).GetLoginsPasswords))
+}
+
+// GetMembers mocks base method.
+func (m *MockGRPCClient) GetMembers(collectionID string) ([]modelstorage.CollectionMember, codes.Code, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMembers", collectionID)
+	ret0, _ := ret[0].([]modelstorage.CollectionMember)
+	ret1, _ := ret[1].(codes.Code)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetMembers indicates an expected call of GetMembers.
+func (mr *MockGRPCClientMockRecorder) GetMembers(collectionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMembers", reflect.TypeOf((*MockGRPCClient)(nil).GetMembers), collectionID)
+}
+
+// GetPublicKey mocks base method.
+func (m *MockGRPCClient) GetPublicKey(login string) (string, codes.Code, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPublicKey", login)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(codes.Code)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetPublicKey indicates an expected call of GetPublicKey.
+func (mr *MockGRPCClientMockRecorder) GetPublicKey(login interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPublicKey", reflect.TypeOf((*MockGRPCClient)(nil).GetPublicKey), login)
+}
+
+// GetSharedWithMe mocks base method.
+func (m *MockGRPCClient) GetSharedWithMe() ([]modelstorage.SharedEntry, codes.Code, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSharedWithMe")
+	ret0, _ := ret[0].([]modelstorage.SharedEntry)
+	ret1, _ := ret[1].(codes.Code)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetSharedWithMe indicates an expected call of GetSharedWithMe.
+func (mr *MockGRPCClientMockRecorder) GetSharedWithMe() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSharedWithMe", reflect.TypeOf((*MockGRPCClient)(nil).GetSharedWithMe))
+}
+
+// GetShares mocks base method.
+func (m *MockGRPCClient) GetShares() ([]modelstorage.Share, codes.Code, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetShares")
+	ret0, _ := ret[0].([]modelstorage.Share)
+	ret1, _ := ret[1].(codes.Code)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetShares indicates an expected call of GetShares.
+func (mr *MockGRPCClientMockRecorder) GetShares() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetShares", reflect.TypeOf((*MockGRPCClient)(nil).GetShares))
+}
+
+// GetTextsBinaries mocks base method.
+func (m *MockGRPCClient) GetTextsBinaries() (map[string]modelstorage.TextOrBinary, codes.Code, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTextsBinaries")
+	ret0, _ := ret[0].(map[string]modelstorage.TextOrBinary)
+	ret1, _ := ret[1].(codes.Code)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetTextsBinaries indicates an expected call of GetTextsBinaries.
+func (mr *MockGRPCClientMockRecorder) GetTextsBinaries() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTextsBinaries", reflect.TypeOf((*MockGRPCClient)(nil).GetTextsBinaries))
+}
+
+// InviteMember mocks base method.
+func (m *MockGRPCClient) InviteMember(collectionID, login, role string) (codes.Code, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InviteMember", collectionID, login, role)
+	ret0, _ := ret[0].(codes.Code)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InviteMember indicates an expected call of InviteMember.
+func (mr *MockGRPCClientMockRecorder) InviteMember(collectionID, login, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InviteMember", reflect.TypeOf((*MockGRPCClient)(nil).InviteMember), collectionID, login, role)
+}
+
+// ListAuditEvents mocks base method.
+func (m *MockGRPCClient) ListAuditEvents(beforeID int64, limit int) ([]modelstorage.AuditEvent, codes.Code, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAuditEvents", beforeID, limit)
+	ret0, _ := ret[0].([]modelstorage.AuditEvent)
+	ret1, _ := ret[1].(codes.Code)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListAuditEvents indicates an expected call of ListAuditEvents.
+func (mr *MockGRPCClientMockRecorder) ListAuditEvents(beforeID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAuditEvents", reflect.TypeOf((*MockGRPCClient)(nil).ListAuditEvents), beforeID, limit)
+}
+
+// ListEmergencyAccess mocks base method.
+func (m *MockGRPCClient) ListEmergencyAccess() (modelstorage.EmergencyAccessList, codes.Code, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListEmergencyAccess")
+	ret0, _ := ret[0].(modelstorage.EmergencyAccessList)
+	ret1, _ := ret[1].(codes.Code)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListEmergencyAccess indicates an expected call of ListEmergencyAccess.
+func (mr *MockGRPCClientMockRecorder) ListEmergencyAccess() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEmergencyAccess", reflect.TypeOf((*MockGRPCClient)(nil).ListEmergencyAccess))
+}
+
+// ListSessions mocks base method.
+func (m *MockGRPCClient) ListSessions() ([]modelstorage.Session, codes.Code, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSessions")
+	ret0, _ := ret[0].([]modelstorage.Session)
+	ret1, _ := ret[1].(codes.Code)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListSessions indicates an expected call of ListSessions.
+func (mr *MockGRPCClientMockRecorder) ListSessions() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSessions", reflect.TypeOf((*MockGRPCClient)(nil).ListSessions))
+}
+
+// Login mocks base method.
+func (m *MockGRPCClient) Login(arg0 modelstorage.RegisterLogin) (codes.Code, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Login", arg0)
+	ret0, _ := ret[0].(codes.Code)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Login indicates an expected call of Login.
+func (mr *MockGRPCClientMockRecorder) Login(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockGRPCClient)(nil).Login), arg0)
+}
+
+// Register mocks base method.
+func (m *MockGRPCClient) Register(arg0 modelstorage.RegisterLogin) (codes.Code, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Register", arg0)
+	ret0, _ := ret[0].(codes.Code)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Register indicates an expected call of Register.
+func (mr *MockGRPCClientMockRecorder) Register(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Register", reflect.TypeOf((*MockGRPCClient)(nil).Register), arg0)
+}
+
+// RejectEmergencyAccess mocks base method.
+func (m *MockGRPCClient) RejectEmergencyAccess(contact string) (codes.Code, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RejectEmergencyAccess", contact)
+	ret0, _ := ret[0].(codes.Code)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RejectEmergencyAccess indicates an expected call of RejectEmergencyAccess.
+func (mr *MockGRPCClientMockRecorder) RejectEmergencyAccess(contact interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RejectEmergencyAccess", reflect.TypeOf((*MockGRPCClient)(nil).RejectEmergencyAccess), contact)
+}
+
+// RemoveBankCard mocks base method.
+func (m *MockGRPCClient) RemoveBankCard(arg0 string) (codes.Code, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveBankCard", arg0)
+	ret0, _ := ret[0].(codes.Code)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemoveBankCard indicates an expected call of RemoveBankCard.
+func (mr *MockGRPCClientMockRecorder) RemoveBankCard(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveBankCard", reflect.TypeOf((*MockGRPCClient)(nil).RemoveBankCard), arg0)
+}
+
+// RemoveLoginPassword mocks base method.
+func (m *MockGRPCClient) RemoveLoginPassword(arg0 string) (codes.Code, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveLoginPassword", arg0)
+	ret0, _ := ret[0].(codes.Code)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemoveLoginPassword indicates an expected call of RemoveLoginPassword.
+func (mr *MockGRPCClientMockRecorder) RemoveLoginPassword(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveLoginPassword", reflect.TypeOf((*MockGRPCClient)(nil).RemoveLoginPassword), arg0)
+}
+
+// RemoveMember mocks base method.
+func (m *MockGRPCClient) RemoveMember(collectionID, login string) (codes.Code, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveMember", collectionID, login)
+	ret0, _ := ret[0].(codes.Code)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemoveMember indicates an expected call of RemoveMember.
+func (mr *MockGRPCClientMockRecorder) RemoveMember(collectionID, login interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveMember", reflect.TypeOf((*MockGRPCClient)(nil).RemoveMember), collectionID, login)
+}
+
+// RemoveTextBinary mocks base method.
+func (m *MockGRPCClient) RemoveTextBinary(arg0 string) (codes.Code, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveTextBinary", arg0)
+	ret0, _ := ret[0].(codes.Code)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemoveTextBinary indicates an expected call of RemoveTextBinary.
+func (mr *MockGRPCClientMockRecorder) RemoveTextBinary(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveTextBinary", reflect.TypeOf((*MockGRPCClient)(nil).RemoveTextBinary), arg0)
+}
+
+// RemoveTrustedContact mocks base method.
+func (m *MockGRPCClient) RemoveTrustedContact(login string) (codes.Code, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveTrustedContact", login)
+	ret0, _ := ret[0].(codes.Code)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemoveTrustedContact indicates an expected call of RemoveTrustedContact.
+func (mr *MockGRPCClientMockRecorder) RemoveTrustedContact(login interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveTrustedContact", reflect.TypeOf((*MockGRPCClient)(nil).RemoveTrustedContact), login)
+}
+
+// RequestEmergencyAccess mocks base method.
+func (m *MockGRPCClient) RequestEmergencyAccess(owner string) (codes.Code, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestEmergencyAccess", owner)
+	ret0, _ := ret[0].(codes.Code)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RequestEmergencyAccess indicates an expected call of RequestEmergencyAccess.
+func (mr *MockGRPCClientMockRecorder) RequestEmergencyAccess(owner interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestEmergencyAccess", reflect.TypeOf((*MockGRPCClient)(nil).RequestEmergencyAccess), owner)
+}
+
+// RespondInvitation mocks base method.
+func (m *MockGRPCClient) RespondInvitation(invitationID int64, accept bool) (codes.Code, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RespondInvitation", invitationID, accept)
+	ret0, _ := ret[0].(codes.Code)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RespondInvitation indicates an expected call of RespondInvitation.
+func (mr *MockGRPCClientMockRecorder) RespondInvitation(invitationID, accept interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RespondInvitation", reflect.TypeOf((*MockGRPCClient)(nil).RespondInvitation), invitationID, accept)
+}
+
+// RevokeSession mocks base method.
+func (m *MockGRPCClient) RevokeSession(sessionID int64) (codes.Code, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeSession", sessionID)
+	ret0, _ := ret[0].(codes.Code)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeSession indicates an expected call of RevokeSession.
+func (mr *MockGRPCClientMockRecorder) RevokeSession(sessionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeSession", reflect.TypeOf((*MockGRPCClient)(nil).RevokeSession), sessionID)
+}
+
+// RevokeShare mocks base method.
+func (m *MockGRPCClient) RevokeShare(identifier, db, recipient string, sealed modelstorage.SealedEntry) (codes.Code, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeShare", identifier, db, recipient, sealed)
+	ret0, _ := ret[0].(codes.Code)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeShare indicates an expected call of RevokeShare.
+func (mr *MockGRPCClientMockRecorder) RevokeShare(identifier, db, recipient, sealed interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeShare", reflect.TypeOf((*MockGRPCClient)(nil).RevokeShare), identifier, db, recipient, sealed)
+}
+
+// SendBankCard mocks base method.
+func (m *MockGRPCClient) SendBankCard(arg0 modelstorage.BankCard) (codes.Code, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendBankCard", arg0)
+	ret0, _ := ret[0].(codes.Code)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendBankCard indicates an expected call of SendBankCard.
+func (mr *MockGRPCClientMockRecorder) SendBankCard(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendBankCard", reflect.TypeOf((*MockGRPCClient)(nil).SendBankCard), arg0)
+}
+
+// SendBatch mocks base method.
+func (m *MockGRPCClient) SendBatch(arg0 modelstorage.Batch, arg1 bool) ([]modelstorage.BatchItemResult, codes.Code, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendBatch", arg0, arg1)
+	ret0, _ := ret[0].([]modelstorage.BatchItemResult)
+	ret1, _ := ret[1].(codes.Code)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// SendBatch indicates an expected call of SendBatch.
+func (mr *MockGRPCClientMockRecorder) SendBatch(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendBatch", reflect.TypeOf((*MockGRPCClient)(nil).SendBatch), arg0, arg1)
+}
+
+// SendLoginPassword mocks base method.
+func (m *MockGRPCClient) SendLoginPassword(arg0 modelstorage.LoginAndPassword) (codes.Code, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendLoginPassword", arg0)
+	ret0, _ := ret[0].(codes.Code)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendLoginPassword indicates an expected call of SendLoginPassword.
+func (mr *MockGRPCClientMockRecorder) SendLoginPassword(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendLoginPassword", reflect.TypeOf((*MockGRPCClient)(nil).SendLoginPassword), arg0)
+}
+
+// SendTextBinary mocks base method.
+func (m *MockGRPCClient) SendTextBinary(arg0 modelstorage.TextOrBinary) (codes.Code, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendTextBinary", arg0)
+	ret0, _ := ret[0].(codes.Code)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendTextBinary indicates an expected call of SendTextBinary.
+func (mr *MockGRPCClientMockRecorder) SendTextBinary(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendTextBinary", reflect.TypeOf((*MockGRPCClient)(nil).SendTextBinary), arg0)
+}
+
+// SetEmergencyEntries mocks base method.
+func (m *MockGRPCClient) SetEmergencyEntries(entries []modelstorage.EmergencyEntry) (codes.Code, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetEmergencyEntries", entries)
+	ret0, _ := ret[0].(codes.Code)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetEmergencyEntries indicates an expected call of SetEmergencyEntries.
+func (mr *MockGRPCClientMockRecorder) SetEmergencyEntries(entries interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetEmergencyEntries", reflect.TypeOf((*MockGRPCClient)(nil).SetEmergencyEntries), entries)
+}
+
+// SetMemberRole mocks base method.
+func (m *MockGRPCClient) SetMemberRole(collectionID, login, role string) (codes.Code, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetMemberRole", collectionID, login, role)
+	ret0, _ := ret[0].(codes.Code)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetMemberRole indicates an expected call of SetMemberRole.
+func (mr *MockGRPCClientMockRecorder) SetMemberRole(collectionID, login, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetMemberRole", reflect.TypeOf((*MockGRPCClient)(nil).SetMemberRole), collectionID, login, role)
+}
+
+// SetPublicKey mocks base method.
+func (m *MockGRPCClient) SetPublicKey(publicKey string) (codes.Code, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPublicKey", publicKey)
+	ret0, _ := ret[0].(codes.Code)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetPublicKey indicates an expected call of SetPublicKey.
+func (mr *MockGRPCClientMockRecorder) SetPublicKey(publicKey interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPublicKey", reflect.TypeOf((*MockGRPCClient)(nil).SetPublicKey), publicKey)
+}
+
+// SetSharePayload mocks base method.
+func (m *MockGRPCClient) SetSharePayload(identifier, db, payload string) (codes.Code, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetSharePayload", identifier, db, payload)
+	ret0, _ := ret[0].(codes.Code)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetSharePayload indicates an expected call of SetSharePayload.
+func (mr *MockGRPCClientMockRecorder) SetSharePayload(identifier, db, payload interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSharePayload", reflect.TypeOf((*MockGRPCClient)(nil).SetSharePayload), identifier, db, payload)
+}
+
+// SetToken mocks base method.
+func (m *MockGRPCClient) SetToken(token, sessionKey string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetToken", token, sessionKey)
+}
+
+// SetToken indicates an expected call of SetToken.
+func (mr *MockGRPCClientMockRecorder) SetToken(token, sessionKey interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetToken", reflect.TypeOf((*MockGRPCClient)(nil).SetToken), token, sessionKey)
+}
+
+// SetTrustedContact mocks base method.
+func (m *MockGRPCClient) SetTrustedContact(login string, waitDays int) (codes.Code, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTrustedContact", login, waitDays)
+	ret0, _ := ret[0].(codes.Code)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetTrustedContact indicates an expected call of SetTrustedContact.
+func (mr *MockGRPCClientMockRecorder) SetTrustedContact(login, waitDays interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTrustedContact", reflect.TypeOf((*MockGRPCClient)(nil).SetTrustedContact), login, waitDays)
+}
+
+// ShareEntry mocks base method.
+func (m *MockGRPCClient) ShareEntry(identifier, db, recipient, permission string, sealed modelstorage.SealedEntry) (codes.Code, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ShareEntry", identifier, db, recipient, permission, sealed)
+	ret0, _ := ret[0].(codes.Code)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ShareEntry indicates an expected call of ShareEntry.
+func (mr *MockGRPCClientMockRecorder) ShareEntry(identifier, db, recipient, permission, sealed interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ShareEntry", reflect.TypeOf((*MockGRPCClient)(nil).ShareEntry), identifier, db, recipient, permission, sealed)
+}
+
+// Token mocks base method.
+func (m *MockGRPCClient) Token() (string, string) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Token")
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(string)
+	return ret0, ret1
+}
+
+// Token indicates an expected call of Token.
+func (mr *MockGRPCClientMockRecorder) Token() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Token", reflect.TypeOf((*MockGRPCClient)(nil).Token))
+}
+
+// UpdateSharedEntry mocks base method.
+func (m *MockGRPCClient) UpdateSharedEntry(entry modelstorage.SharedEntry, payload string) (codes.Code, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSharedEntry", entry, payload)
+	ret0, _ := ret[0].(codes.Code)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateSharedEntry indicates an expected call of UpdateSharedEntry.
+func (mr *MockGRPCClientMockRecorder) UpdateSharedEntry(entry, payload interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSharedEntry", reflect.TypeOf((*MockGRPCClient)(nil).UpdateSharedEntry), entry, payload)
 }
@@ -0,0 +1,140 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./interface.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockPrivateKeyLoader is a mock of PrivateKeyLoader interface.
+type MockPrivateKeyLoader struct {
+	ctrl     *gomock.Controller
+	recorder *MockPrivateKeyLoaderMockRecorder
+}
+
+// MockPrivateKeyLoaderMockRecorder is the mock recorder for MockPrivateKeyLoader.
+type MockPrivateKeyLoaderMockRecorder struct {
+	mock *MockPrivateKeyLoader
+}
+
+// NewMockPrivateKeyLoader creates a new mock instance.
+func NewMockPrivateKeyLoader(ctrl *gomock.Controller) *MockPrivateKeyLoader {
+	mock := &MockPrivateKeyLoader{ctrl: ctrl}
+	mock.recorder = &MockPrivateKeyLoaderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPrivateKeyLoader) EXPECT() *MockPrivateKeyLoaderMockRecorder {
+	return m.recorder
+}
+
+// PrivateKey mocks base method.
+func (m *MockPrivateKeyLoader) PrivateKey(publicKey string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PrivateKey", publicKey)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PrivateKey indicates an expected call of PrivateKey.
+func (mr *MockPrivateKeyLoaderMockRecorder) PrivateKey(publicKey interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PrivateKey", reflect.TypeOf((*MockPrivateKeyLoader)(nil).PrivateKey), publicKey)
+}
+
+// MockKeyPairCreator is a mock of KeyPairCreator interface.
+type MockKeyPairCreator struct {
+	ctrl     *gomock.Controller
+	recorder *MockKeyPairCreatorMockRecorder
+}
+
+// MockKeyPairCreatorMockRecorder is the mock recorder for MockKeyPairCreator.
+type MockKeyPairCreatorMockRecorder struct {
+	mock *MockKeyPairCreator
+}
+
+// NewMockKeyPairCreator creates a new mock instance.
+func NewMockKeyPairCreator(ctrl *gomock.Controller) *MockKeyPairCreator {
+	mock := &MockKeyPairCreator{ctrl: ctrl}
+	mock.recorder = &MockKeyPairCreatorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockKeyPairCreator) EXPECT() *MockKeyPairCreatorMockRecorder {
+	return m.recorder
+}
+
+// NewKeyPair mocks base method.
+func (m *MockKeyPairCreator) NewKeyPair() (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NewKeyPair")
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NewKeyPair indicates an expected call of NewKeyPair.
+func (mr *MockKeyPairCreatorMockRecorder) NewKeyPair() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewKeyPair", reflect.TypeOf((*MockKeyPairCreator)(nil).NewKeyPair))
+}
+
+// MockKeeper is a mock of Keeper interface.
+type MockKeeper struct {
+	ctrl     *gomock.Controller
+	recorder *MockKeeperMockRecorder
+}
+
+// MockKeeperMockRecorder is the mock recorder for MockKeeper.
+type MockKeeperMockRecorder struct {
+	mock *MockKeeper
+}
+
+// NewMockKeeper creates a new mock instance.
+func NewMockKeeper(ctrl *gomock.Controller) *MockKeeper {
+	mock := &MockKeeper{ctrl: ctrl}
+	mock.recorder = &MockKeeperMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockKeeper) EXPECT() *MockKeeperMockRecorder {
+	return m.recorder
+}
+
+// NewKeyPair mocks base method.
+func (m *MockKeeper) NewKeyPair() (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NewKeyPair")
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NewKeyPair indicates an expected call of NewKeyPair.
+func (mr *MockKeeperMockRecorder) NewKeyPair() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewKeyPair", reflect.TypeOf((*MockKeeper)(nil).NewKeyPair))
+}
+
+// PrivateKey mocks base method.
+func (m *MockKeeper) PrivateKey(publicKey string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PrivateKey", publicKey)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PrivateKey indicates an expected call of PrivateKey.
+func (mr *MockKeeperMockRecorder) PrivateKey(publicKey interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PrivateKey", reflect.TypeOf((*MockKeeper)(nil).PrivateKey), publicKey)
+}
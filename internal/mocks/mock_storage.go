@@ -8,6 +8,7 @@ import (
 	context "context"
 	modelstorage "dk-go-gophkeeper/internal/server/storage/modelstorage"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 )
@@ -151,48 +152,63 @@ func (m *MockGetter) EXPECT() *MockGetterMockRecorder {
 }
 
 // GetBankCardData mocks base method.
-func (m *MockGetter) GetBankCardData(ctx context.Context, userID string) ([]modelstorage.BankCardStorageEntry, error) {
+func (m *MockGetter) GetBankCardData(ctx context.Context, userID string, afterID int64, limit int, tokens []string) ([]modelstorage.BankCardStorageEntry, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetBankCardData", ctx, userID)
+	ret := m.ctrl.Call(m, "GetBankCardData", ctx, userID, afterID, limit, tokens)
 	ret0, _ := ret[0].([]modelstorage.BankCardStorageEntry)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetBankCardData indicates an expected call of GetBankCardData.
-func (mr *MockGetterMockRecorder) GetBankCardData(ctx, userID interface{}) *gomock.Call {
+func (mr *MockGetterMockRecorder) GetBankCardData(ctx, userID, afterID, limit, tokens interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBankCardData", reflect.TypeOf((*MockGetter)(nil).GetBankCardData), ctx, userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBankCardData", reflect.TypeOf((*MockGetter)(nil).GetBankCardData), ctx, userID, afterID, limit, tokens)
+}
+
+// GetEntry mocks base method.
+func (m *MockGetter) GetEntry(ctx context.Context, userID, db, identifier string) (modelstorage.BatchItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEntry", ctx, userID, db, identifier)
+	ret0, _ := ret[0].(modelstorage.BatchItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEntry indicates an expected call of GetEntry.
+func (mr *MockGetterMockRecorder) GetEntry(ctx, userID, db, identifier interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEntry", reflect.TypeOf((*MockGetter)(nil).GetEntry), ctx, userID, db, identifier)
 }
 
 // GetLoginPasswordData mocks base method.
-func (m *MockGetter) GetLoginPasswordData(ctx context.Context, userID string) ([]modelstorage.LoginPasswordStorageEntry, error) {
+func (m *MockGetter) GetLoginPasswordData(ctx context.Context, userID string, afterID int64, limit int, tokens []string) ([]modelstorage.LoginPasswordStorageEntry, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetLoginPasswordData", ctx, userID)
+	ret := m.ctrl.Call(m, "GetLoginPasswordData", ctx, userID, afterID, limit, tokens)
 	ret0, _ := ret[0].([]modelstorage.LoginPasswordStorageEntry)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetLoginPasswordData indicates an expected call of GetLoginPasswordData.
-func (mr *MockGetterMockRecorder) GetLoginPasswordData(ctx, userID interface{}) *gomock.Call {
+func (mr *MockGetterMockRecorder) GetLoginPasswordData(ctx, userID, afterID, limit, tokens interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoginPasswordData", reflect.TypeOf((*MockGetter)(nil).GetLoginPasswordData), ctx, userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoginPasswordData", reflect.TypeOf((*MockGetter)(nil).GetLoginPasswordData), ctx, userID, afterID, limit, tokens)
 }
 
 // GetTextBinaryData mocks base method.
-func (m *MockGetter) GetTextBinaryData(ctx context.Context, userID string) ([]modelstorage.TextBinaryStorageEntry, error) {
+func (m *MockGetter) GetTextBinaryData(ctx context.Context, userID string, afterID int64, limit int, tokens []string) ([]modelstorage.TextBinaryStorageEntry, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetTextBinaryData", ctx, userID)
+	ret := m.ctrl.Call(m, "GetTextBinaryData", ctx, userID, afterID, limit, tokens)
 	ret0, _ := ret[0].([]modelstorage.TextBinaryStorageEntry)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetTextBinaryData indicates an expected call of GetTextBinaryData.
-func (mr *MockGetterMockRecorder) GetTextBinaryData(ctx, userID interface{}) *gomock.Call {
+func (mr *MockGetterMockRecorder) GetTextBinaryData(ctx, userID, afterID, limit, tokens interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTextBinaryData", reflect.TypeOf((*MockGetter)(nil).GetTextBinaryData), ctx, userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTextBinaryData", reflect.TypeOf((*MockGetter)(nil).GetTextBinaryData), ctx, userID, afterID, limit, tokens)
 }
 
 // MockSetter is a mock of Setter interface.
@@ -219,222 +235,1710 @@ func (m *MockSetter) EXPECT() *MockSetterMockRecorder {
 }
 
 // SetBankCardData mocks base method.
-func (m *MockSetter) SetBankCardData(ctx context.Context, userID, identifier, number, holder, cvv, meta string) error {
+func (m *MockSetter) SetBankCardData(ctx context.Context, userID, identifier, number, holder, cvv, expiry, pin, meta string, labels modelstorage.Labels, fields string, tokens []string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SetBankCardData", ctx, userID, identifier, number, holder, cvv, meta)
+	ret := m.ctrl.Call(m, "SetBankCardData", ctx, userID, identifier, number, holder, cvv, expiry, pin, meta, labels, fields, tokens)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // SetBankCardData indicates an expected call of SetBankCardData.
-func (mr *MockSetterMockRecorder) SetBankCardData(ctx, userID, identifier, number, holder, cvv, meta interface{}) *gomock.Call {
+func (mr *MockSetterMockRecorder) SetBankCardData(ctx, userID, identifier, number, holder, cvv, expiry, pin, meta, labels, fields, tokens interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetBankCardData", reflect.TypeOf((*MockSetter)(nil).SetBankCardData), ctx, userID, identifier, number, holder, cvv, meta)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetBankCardData", reflect.TypeOf((*MockSetter)(nil).SetBankCardData), ctx, userID, identifier, number, holder, cvv, expiry, pin, meta, labels, fields, tokens)
 }
 
 // SetLoginPasswordData mocks base method.
-func (m *MockSetter) SetLoginPasswordData(ctx context.Context, userID, identifier, login, password, meta string) error {
+func (m *MockSetter) SetLoginPasswordData(ctx context.Context, userID, identifier, login, password, meta string, labels modelstorage.Labels, fields string, tokens []string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SetLoginPasswordData", ctx, userID, identifier, login, password, meta)
+	ret := m.ctrl.Call(m, "SetLoginPasswordData", ctx, userID, identifier, login, password, meta, labels, fields, tokens)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // SetLoginPasswordData indicates an expected call of SetLoginPasswordData.
-func (mr *MockSetterMockRecorder) SetLoginPasswordData(ctx, userID, identifier, login, password, meta interface{}) *gomock.Call {
+func (mr *MockSetterMockRecorder) SetLoginPasswordData(ctx, userID, identifier, login, password, meta, labels, fields, tokens interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLoginPasswordData", reflect.TypeOf((*MockSetter)(nil).SetLoginPasswordData), ctx, userID, identifier, login, password, meta)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLoginPasswordData", reflect.TypeOf((*MockSetter)(nil).SetLoginPasswordData), ctx, userID, identifier, login, password, meta, labels, fields, tokens)
 }
 
 // SetTextBinaryData mocks base method.
-func (m *MockSetter) SetTextBinaryData(ctx context.Context, userID, identifier, entry, meta string) error {
+func (m *MockSetter) SetTextBinaryData(ctx context.Context, userID, identifier, entry, meta string, labels modelstorage.Labels, fields string, tokens []string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SetTextBinaryData", ctx, userID, identifier, entry, meta)
+	ret := m.ctrl.Call(m, "SetTextBinaryData", ctx, userID, identifier, entry, meta, labels, fields, tokens)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // SetTextBinaryData indicates an expected call of SetTextBinaryData.
-func (mr *MockSetterMockRecorder) SetTextBinaryData(ctx, userID, identifier, entry, meta interface{}) *gomock.Call {
+func (mr *MockSetterMockRecorder) SetTextBinaryData(ctx, userID, identifier, entry, meta, labels, fields, tokens interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTextBinaryData", reflect.TypeOf((*MockSetter)(nil).SetTextBinaryData), ctx, userID, identifier, entry, meta)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTextBinaryData", reflect.TypeOf((*MockSetter)(nil).SetTextBinaryData), ctx, userID, identifier, entry, meta, labels, fields, tokens)
 }
 
-// MockDataStorage is a mock of DataStorage interface.
-type MockDataStorage struct {
+// MockBatchSetter is a mock of BatchSetter interface.
+type MockBatchSetter struct {
 	ctrl     *gomock.Controller
-	recorder *MockDataStorageMockRecorder
+	recorder *MockBatchSetterMockRecorder
 }
 
-// MockDataStorageMockRecorder is the mock recorder for MockDataStorage.
-type MockDataStorageMockRecorder struct {
-	mock *MockDataStorage
+// MockBatchSetterMockRecorder is the mock recorder for MockBatchSetter.
+type MockBatchSetterMockRecorder struct {
+	mock *MockBatchSetter
 }
 
-// NewMockDataStorage creates a new mock instance.
-func NewMockDataStorage(ctrl *gomock.Controller) *MockDataStorage {
-	mock := &MockDataStorage{ctrl: ctrl}
-	mock.recorder = &MockDataStorageMockRecorder{mock}
+// NewMockBatchSetter creates a new mock instance.
+func NewMockBatchSetter(ctrl *gomock.Controller) *MockBatchSetter {
+	mock := &MockBatchSetter{ctrl: ctrl}
+	mock.recorder = &MockBatchSetterMockRecorder{mock}
 	return mock
 }
 
 // EXPECT returns an object that allows the caller to indicate expected use.
-func (m *MockDataStorage) EXPECT() *MockDataStorageMockRecorder {
+func (m *MockBatchSetter) EXPECT() *MockBatchSetterMockRecorder {
 	return m.recorder
 }
 
-// AddNewUser mocks base method.
-func (m *MockDataStorage) AddNewUser(ctx context.Context, login, password, userID string) error {
+// SetBatchData mocks base method.
+func (m *MockBatchSetter) SetBatchData(ctx context.Context, userID string, items []modelstorage.BatchItem, overwrite bool) ([]modelstorage.BatchItemResult, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "AddNewUser", ctx, login, password, userID)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "SetBatchData", ctx, userID, items, overwrite)
+	ret0, _ := ret[0].([]modelstorage.BatchItemResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// AddNewUser indicates an expected call of AddNewUser.
-func (mr *MockDataStorageMockRecorder) AddNewUser(ctx, login, password, userID interface{}) *gomock.Call {
+// SetBatchData indicates an expected call of SetBatchData.
+func (mr *MockBatchSetterMockRecorder) SetBatchData(ctx, userID, items, overwrite interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddNewUser", reflect.TypeOf((*MockDataStorage)(nil).AddNewUser), ctx, login, password, userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetBatchData", reflect.TypeOf((*MockBatchSetter)(nil).SetBatchData), ctx, userID, items, overwrite)
 }
 
-// CheckUser mocks base method.
-func (m *MockDataStorage) CheckUser(ctx context.Context, login, password string) (string, error) {
+// MockIndexer is a mock of Indexer interface.
+type MockIndexer struct {
+	ctrl     *gomock.Controller
+	recorder *MockIndexerMockRecorder
+}
+
+// MockIndexerMockRecorder is the mock recorder for MockIndexer.
+type MockIndexerMockRecorder struct {
+	mock *MockIndexer
+}
+
+// NewMockIndexer creates a new mock instance.
+func NewMockIndexer(ctrl *gomock.Controller) *MockIndexer {
+	mock := &MockIndexer{ctrl: ctrl}
+	mock.recorder = &MockIndexerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIndexer) EXPECT() *MockIndexerMockRecorder {
+	return m.recorder
+}
+
+// SearchEntries mocks base method.
+func (m *MockIndexer) SearchEntries(ctx context.Context, userID string, tokens []string, cursor modelstorage.SearchCursor, limit int) (modelstorage.SearchPage, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CheckUser", ctx, login, password)
+	ret := m.ctrl.Call(m, "SearchEntries", ctx, userID, tokens, cursor, limit)
+	ret0, _ := ret[0].(modelstorage.SearchPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchEntries indicates an expected call of SearchEntries.
+func (mr *MockIndexerMockRecorder) SearchEntries(ctx, userID, tokens, cursor, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchEntries", reflect.TypeOf((*MockIndexer)(nil).SearchEntries), ctx, userID, tokens, cursor, limit)
+}
+
+// MockSharer is a mock of Sharer interface.
+type MockSharer struct {
+	ctrl     *gomock.Controller
+	recorder *MockSharerMockRecorder
+}
+
+// MockSharerMockRecorder is the mock recorder for MockSharer.
+type MockSharerMockRecorder struct {
+	mock *MockSharer
+}
+
+// NewMockSharer creates a new mock instance.
+func NewMockSharer(ctrl *gomock.Controller) *MockSharer {
+	mock := &MockSharer{ctrl: ctrl}
+	mock.recorder = &MockSharerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSharer) EXPECT() *MockSharerMockRecorder {
+	return m.recorder
+}
+
+// GetShare mocks base method.
+func (m *MockSharer) GetShare(ctx context.Context, ownerID, db, identifier string) (modelstorage.Share, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetShare", ctx, ownerID, db, identifier)
+	ret0, _ := ret[0].(modelstorage.Share)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetShare indicates an expected call of GetShare.
+func (mr *MockSharerMockRecorder) GetShare(ctx, ownerID, db, identifier interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetShare", reflect.TypeOf((*MockSharer)(nil).GetShare), ctx, ownerID, db, identifier)
+}
+
+// GetShareByID mocks base method.
+func (m *MockSharer) GetShareByID(ctx context.Context, shareID int64) (modelstorage.Share, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetShareByID", ctx, shareID)
+	ret0, _ := ret[0].(modelstorage.Share)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetShareByID indicates an expected call of GetShareByID.
+func (mr *MockSharerMockRecorder) GetShareByID(ctx, shareID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetShareByID", reflect.TypeOf((*MockSharer)(nil).GetShareByID), ctx, shareID)
+}
+
+// GetSharedWithUser mocks base method.
+func (m *MockSharer) GetSharedWithUser(ctx context.Context, recipientID string) ([]modelstorage.Share, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSharedWithUser", ctx, recipientID)
+	ret0, _ := ret[0].([]modelstorage.Share)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSharedWithUser indicates an expected call of GetSharedWithUser.
+func (mr *MockSharerMockRecorder) GetSharedWithUser(ctx, recipientID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSharedWithUser", reflect.TypeOf((*MockSharer)(nil).GetSharedWithUser), ctx, recipientID)
+}
+
+// GetSharesByOwner mocks base method.
+func (m *MockSharer) GetSharesByOwner(ctx context.Context, ownerID string) ([]modelstorage.Share, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSharesByOwner", ctx, ownerID)
+	ret0, _ := ret[0].([]modelstorage.Share)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSharesByOwner indicates an expected call of GetSharesByOwner.
+func (mr *MockSharerMockRecorder) GetSharesByOwner(ctx, ownerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSharesByOwner", reflect.TypeOf((*MockSharer)(nil).GetSharesByOwner), ctx, ownerID)
+}
+
+// GetUserIDByLogin mocks base method.
+func (m *MockSharer) GetUserIDByLogin(ctx context.Context, login string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserIDByLogin", ctx, login)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CheckUser indicates an expected call of CheckUser.
-func (mr *MockDataStorageMockRecorder) CheckUser(ctx, login, password interface{}) *gomock.Call {
+// GetUserIDByLogin indicates an expected call of GetUserIDByLogin.
+func (mr *MockSharerMockRecorder) GetUserIDByLogin(ctx, login interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckUser", reflect.TypeOf((*MockDataStorage)(nil).CheckUser), ctx, login, password)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserIDByLogin", reflect.TypeOf((*MockSharer)(nil).GetUserIDByLogin), ctx, login)
 }
 
-// DeleteBatch mocks base method.
-func (m *MockDataStorage) DeleteBatch(ctx context.Context, identifiers []string, userID, db string) error {
+// GetUserKeys mocks base method.
+func (m *MockSharer) GetUserKeys(ctx context.Context, userID string) (modelstorage.UserKeys, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteBatch", ctx, identifiers, userID, db)
+	ret := m.ctrl.Call(m, "GetUserKeys", ctx, userID)
+	ret0, _ := ret[0].(modelstorage.UserKeys)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserKeys indicates an expected call of GetUserKeys.
+func (mr *MockSharerMockRecorder) GetUserKeys(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserKeys", reflect.TypeOf((*MockSharer)(nil).GetUserKeys), ctx, userID)
+}
+
+// SetShare mocks base method.
+func (m *MockSharer) SetShare(ctx context.Context, share modelstorage.Share) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetShare", ctx, share)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// DeleteBatch indicates an expected call of DeleteBatch.
-func (mr *MockDataStorageMockRecorder) DeleteBatch(ctx, identifiers, userID, db interface{}) *gomock.Call {
+// SetShare indicates an expected call of SetShare.
+func (mr *MockSharerMockRecorder) SetShare(ctx, share interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBatch", reflect.TypeOf((*MockDataStorage)(nil).DeleteBatch), ctx, identifiers, userID, db)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetShare", reflect.TypeOf((*MockSharer)(nil).SetShare), ctx, share)
 }
 
-// Flush mocks base method.
-func (m *MockDataStorage) Flush(ctx context.Context, batch []modelstorage.Removal) error {
+// SetSharePayload mocks base method.
+func (m *MockSharer) SetSharePayload(ctx context.Context, shareID int64, payload string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Flush", ctx, batch)
+	ret := m.ctrl.Call(m, "SetSharePayload", ctx, shareID, payload)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// Flush indicates an expected call of Flush.
-func (mr *MockDataStorageMockRecorder) Flush(ctx, batch interface{}) *gomock.Call {
+// SetSharePayload indicates an expected call of SetSharePayload.
+func (mr *MockSharerMockRecorder) SetSharePayload(ctx, shareID, payload interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Flush", reflect.TypeOf((*MockDataStorage)(nil).Flush), ctx, batch)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSharePayload", reflect.TypeOf((*MockSharer)(nil).SetSharePayload), ctx, shareID, payload)
 }
 
-// GetBankCardData mocks base method.
-func (m *MockDataStorage) GetBankCardData(ctx context.Context, userID string) ([]modelstorage.BankCardStorageEntry, error) {
+// SetUserKeys mocks base method.
+func (m *MockSharer) SetUserKeys(ctx context.Context, keys modelstorage.UserKeys) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetBankCardData", ctx, userID)
-	ret0, _ := ret[0].([]modelstorage.BankCardStorageEntry)
+	ret := m.ctrl.Call(m, "SetUserKeys", ctx, keys)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetUserKeys indicates an expected call of SetUserKeys.
+func (mr *MockSharerMockRecorder) SetUserKeys(ctx, keys interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserKeys", reflect.TypeOf((*MockSharer)(nil).SetUserKeys), ctx, keys)
+}
+
+// UpdateSharedEntry mocks base method.
+func (m *MockSharer) UpdateSharedEntry(ctx context.Context, ownerID string, item modelstorage.BatchItem, shareID int64, payload string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSharedEntry", ctx, ownerID, item, shareID, payload)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSharedEntry indicates an expected call of UpdateSharedEntry.
+func (mr *MockSharerMockRecorder) UpdateSharedEntry(ctx, ownerID, item, shareID, payload interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSharedEntry", reflect.TypeOf((*MockSharer)(nil).UpdateSharedEntry), ctx, ownerID, item, shareID, payload)
+}
+
+// MockCollectionManager is a mock of CollectionManager interface.
+type MockCollectionManager struct {
+	ctrl     *gomock.Controller
+	recorder *MockCollectionManagerMockRecorder
+}
+
+// MockCollectionManagerMockRecorder is the mock recorder for MockCollectionManager.
+type MockCollectionManagerMockRecorder struct {
+	mock *MockCollectionManager
+}
+
+// NewMockCollectionManager creates a new mock instance.
+func NewMockCollectionManager(ctrl *gomock.Controller) *MockCollectionManager {
+	mock := &MockCollectionManager{ctrl: ctrl}
+	mock.recorder = &MockCollectionManagerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCollectionManager) EXPECT() *MockCollectionManagerMockRecorder {
+	return m.recorder
+}
+
+// AddCollection mocks base method.
+func (m *MockCollectionManager) AddCollection(ctx context.Context, collection modelstorage.Collection, owner modelstorage.CollectionMember, event modelstorage.CollectionEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddCollection", ctx, collection, owner, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddCollection indicates an expected call of AddCollection.
+func (mr *MockCollectionManagerMockRecorder) AddCollection(ctx, collection, owner, event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddCollection", reflect.TypeOf((*MockCollectionManager)(nil).AddCollection), ctx, collection, owner, event)
+}
+
+// DeleteCollection mocks base method.
+func (m *MockCollectionManager) DeleteCollection(ctx context.Context, collectionID, vaultID string, event modelstorage.CollectionEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCollection", ctx, collectionID, vaultID, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCollection indicates an expected call of DeleteCollection.
+func (mr *MockCollectionManagerMockRecorder) DeleteCollection(ctx, collectionID, vaultID, event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCollection", reflect.TypeOf((*MockCollectionManager)(nil).DeleteCollection), ctx, collectionID, vaultID, event)
+}
+
+// DeleteMember mocks base method.
+func (m *MockCollectionManager) DeleteMember(ctx context.Context, collectionID, userID string, event modelstorage.CollectionEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteMember", ctx, collectionID, userID, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteMember indicates an expected call of DeleteMember.
+func (mr *MockCollectionManagerMockRecorder) DeleteMember(ctx, collectionID, userID, event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteMember", reflect.TypeOf((*MockCollectionManager)(nil).DeleteMember), ctx, collectionID, userID, event)
+}
+
+// GetCollectionEvents mocks base method.
+func (m *MockCollectionManager) GetCollectionEvents(ctx context.Context, collectionID string) ([]modelstorage.CollectionEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCollectionEvents", ctx, collectionID)
+	ret0, _ := ret[0].([]modelstorage.CollectionEvent)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetBankCardData indicates an expected call of GetBankCardData.
-func (mr *MockDataStorageMockRecorder) GetBankCardData(ctx, userID interface{}) *gomock.Call {
+// GetCollectionEvents indicates an expected call of GetCollectionEvents.
+func (mr *MockCollectionManagerMockRecorder) GetCollectionEvents(ctx, collectionID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBankCardData", reflect.TypeOf((*MockDataStorage)(nil).GetBankCardData), ctx, userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCollectionEvents", reflect.TypeOf((*MockCollectionManager)(nil).GetCollectionEvents), ctx, collectionID)
 }
 
-// GetLoginPasswordData mocks base method.
-func (m *MockDataStorage) GetLoginPasswordData(ctx context.Context, userID string) ([]modelstorage.LoginPasswordStorageEntry, error) {
+// GetCollections mocks base method.
+func (m *MockCollectionManager) GetCollections(ctx context.Context, userID string) ([]modelstorage.Collection, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetLoginPasswordData", ctx, userID)
-	ret0, _ := ret[0].([]modelstorage.LoginPasswordStorageEntry)
+	ret := m.ctrl.Call(m, "GetCollections", ctx, userID)
+	ret0, _ := ret[0].([]modelstorage.Collection)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetLoginPasswordData indicates an expected call of GetLoginPasswordData.
-func (mr *MockDataStorageMockRecorder) GetLoginPasswordData(ctx, userID interface{}) *gomock.Call {
+// GetCollections indicates an expected call of GetCollections.
+func (mr *MockCollectionManagerMockRecorder) GetCollections(ctx, userID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoginPasswordData", reflect.TypeOf((*MockDataStorage)(nil).GetLoginPasswordData), ctx, userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCollections", reflect.TypeOf((*MockCollectionManager)(nil).GetCollections), ctx, userID)
 }
 
-// GetTextBinaryData mocks base method.
-func (m *MockDataStorage) GetTextBinaryData(ctx context.Context, userID string) ([]modelstorage.TextBinaryStorageEntry, error) {
+// GetInvitation mocks base method.
+func (m *MockCollectionManager) GetInvitation(ctx context.Context, invitationID int64) (modelstorage.CollectionInvitation, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetTextBinaryData", ctx, userID)
-	ret0, _ := ret[0].([]modelstorage.TextBinaryStorageEntry)
+	ret := m.ctrl.Call(m, "GetInvitation", ctx, invitationID)
+	ret0, _ := ret[0].(modelstorage.CollectionInvitation)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetTextBinaryData indicates an expected call of GetTextBinaryData.
-func (mr *MockDataStorageMockRecorder) GetTextBinaryData(ctx, userID interface{}) *gomock.Call {
+// GetInvitation indicates an expected call of GetInvitation.
+func (mr *MockCollectionManagerMockRecorder) GetInvitation(ctx, invitationID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTextBinaryData", reflect.TypeOf((*MockDataStorage)(nil).GetTextBinaryData), ctx, userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInvitation", reflect.TypeOf((*MockCollectionManager)(nil).GetInvitation), ctx, invitationID)
 }
 
-// SendToQueue mocks base method.
-func (m *MockDataStorage) SendToQueue(item modelstorage.Removal) {
+// GetInvitations mocks base method.
+func (m *MockCollectionManager) GetInvitations(ctx context.Context, inviteeID string) ([]modelstorage.CollectionInvitation, error) {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "SendToQueue", item)
+	ret := m.ctrl.Call(m, "GetInvitations", ctx, inviteeID)
+	ret0, _ := ret[0].([]modelstorage.CollectionInvitation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// SendToQueue indicates an expected call of SendToQueue.
-func (mr *MockDataStorageMockRecorder) SendToQueue(item interface{}) *gomock.Call {
+// GetInvitations indicates an expected call of GetInvitations.
+func (mr *MockCollectionManagerMockRecorder) GetInvitations(ctx, inviteeID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendToQueue", reflect.TypeOf((*MockDataStorage)(nil).SendToQueue), item)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInvitations", reflect.TypeOf((*MockCollectionManager)(nil).GetInvitations), ctx, inviteeID)
 }
 
-// SetBankCardData mocks base method.
-func (m *MockDataStorage) SetBankCardData(ctx context.Context, userID, identifier, number, holder, cvv, meta string) error {
+// GetMember mocks base method.
+func (m *MockCollectionManager) GetMember(ctx context.Context, collectionID, userID string) (modelstorage.CollectionMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMember", ctx, collectionID, userID)
+	ret0, _ := ret[0].(modelstorage.CollectionMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMember indicates an expected call of GetMember.
+func (mr *MockCollectionManagerMockRecorder) GetMember(ctx, collectionID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMember", reflect.TypeOf((*MockCollectionManager)(nil).GetMember), ctx, collectionID, userID)
+}
+
+// GetMembers mocks base method.
+func (m *MockCollectionManager) GetMembers(ctx context.Context, collectionID string) ([]modelstorage.CollectionMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMembers", ctx, collectionID)
+	ret0, _ := ret[0].([]modelstorage.CollectionMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMembers indicates an expected call of GetMembers.
+func (mr *MockCollectionManagerMockRecorder) GetMembers(ctx, collectionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMembers", reflect.TypeOf((*MockCollectionManager)(nil).GetMembers), ctx, collectionID)
+}
+
+// ResolveInvitation mocks base method.
+func (m *MockCollectionManager) ResolveInvitation(ctx context.Context, invitation modelstorage.CollectionInvitation, accept bool, event modelstorage.CollectionEvent) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SetBankCardData", ctx, userID, identifier, number, holder, cvv, meta)
+	ret := m.ctrl.Call(m, "ResolveInvitation", ctx, invitation, accept, event)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// SetBankCardData indicates an expected call of SetBankCardData.
-func (mr *MockDataStorageMockRecorder) SetBankCardData(ctx, userID, identifier, number, holder, cvv, meta interface{}) *gomock.Call {
+// ResolveInvitation indicates an expected call of ResolveInvitation.
+func (mr *MockCollectionManagerMockRecorder) ResolveInvitation(ctx, invitation, accept, event interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetBankCardData", reflect.TypeOf((*MockDataStorage)(nil).SetBankCardData), ctx, userID, identifier, number, holder, cvv, meta)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolveInvitation", reflect.TypeOf((*MockCollectionManager)(nil).ResolveInvitation), ctx, invitation, accept, event)
 }
 
-// SetLoginPasswordData mocks base method.
-func (m *MockDataStorage) SetLoginPasswordData(ctx context.Context, userID, identifier, login, password, meta string) error {
+// SetInvitation mocks base method.
+func (m *MockCollectionManager) SetInvitation(ctx context.Context, invitation modelstorage.CollectionInvitation, event modelstorage.CollectionEvent) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SetLoginPasswordData", ctx, userID, identifier, login, password, meta)
+	ret := m.ctrl.Call(m, "SetInvitation", ctx, invitation, event)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// SetLoginPasswordData indicates an expected call of SetLoginPasswordData.
-func (mr *MockDataStorageMockRecorder) SetLoginPasswordData(ctx, userID, identifier, login, password, meta interface{}) *gomock.Call {
+// SetInvitation indicates an expected call of SetInvitation.
+func (mr *MockCollectionManagerMockRecorder) SetInvitation(ctx, invitation, event interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLoginPasswordData", reflect.TypeOf((*MockDataStorage)(nil).SetLoginPasswordData), ctx, userID, identifier, login, password, meta)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetInvitation", reflect.TypeOf((*MockCollectionManager)(nil).SetInvitation), ctx, invitation, event)
 }
 
-// SetTextBinaryData mocks base method.
-func (m *MockDataStorage) SetTextBinaryData(ctx context.Context, userID, identifier, entry, meta string) error {
+// SetMemberRole mocks base method.
+func (m *MockCollectionManager) SetMemberRole(ctx context.Context, member modelstorage.CollectionMember, event modelstorage.CollectionEvent) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SetTextBinaryData", ctx, userID, identifier, entry, meta)
+	ret := m.ctrl.Call(m, "SetMemberRole", ctx, member, event)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// SetTextBinaryData indicates an expected call of SetTextBinaryData.
-func (mr *MockDataStorageMockRecorder) SetTextBinaryData(ctx, userID, identifier, entry, meta interface{}) *gomock.Call {
+// SetMemberRole indicates an expected call of SetMemberRole.
+func (mr *MockCollectionManagerMockRecorder) SetMemberRole(ctx, member, event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetMemberRole", reflect.TypeOf((*MockCollectionManager)(nil).SetMemberRole), ctx, member, event)
+}
+
+// MockSessionManager is a mock of SessionManager interface.
+type MockSessionManager struct {
+	ctrl     *gomock.Controller
+	recorder *MockSessionManagerMockRecorder
+}
+
+// MockSessionManagerMockRecorder is the mock recorder for MockSessionManager.
+type MockSessionManagerMockRecorder struct {
+	mock *MockSessionManager
+}
+
+// NewMockSessionManager creates a new mock instance.
+func NewMockSessionManager(ctrl *gomock.Controller) *MockSessionManager {
+	mock := &MockSessionManager{ctrl: ctrl}
+	mock.recorder = &MockSessionManagerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSessionManager) EXPECT() *MockSessionManagerMockRecorder {
+	return m.recorder
+}
+
+// AddSession mocks base method.
+func (m *MockSessionManager) AddSession(ctx context.Context, session modelstorage.Session) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddSession", ctx, session)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddSession indicates an expected call of AddSession.
+func (mr *MockSessionManagerMockRecorder) AddSession(ctx, session interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddSession", reflect.TypeOf((*MockSessionManager)(nil).AddSession), ctx, session)
+}
+
+// DeleteExpiredSessions mocks base method.
+func (m *MockSessionManager) DeleteExpiredSessions(ctx context.Context, idleTTL, maxAge time.Duration) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredSessions", ctx, idleTTL, maxAge)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpiredSessions indicates an expected call of DeleteExpiredSessions.
+func (mr *MockSessionManagerMockRecorder) DeleteExpiredSessions(ctx, idleTTL, maxAge interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredSessions", reflect.TypeOf((*MockSessionManager)(nil).DeleteExpiredSessions), ctx, idleTTL, maxAge)
+}
+
+// DeleteSession mocks base method.
+func (m *MockSessionManager) DeleteSession(ctx context.Context, userID string, sessionID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSession", ctx, userID, sessionID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSession indicates an expected call of DeleteSession.
+func (mr *MockSessionManagerMockRecorder) DeleteSession(ctx, userID, sessionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSession", reflect.TypeOf((*MockSessionManager)(nil).DeleteSession), ctx, userID, sessionID)
+}
+
+// GetSession mocks base method.
+func (m *MockSessionManager) GetSession(ctx context.Context, userID, digest string) (modelstorage.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSession", ctx, userID, digest)
+	ret0, _ := ret[0].(modelstorage.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSession indicates an expected call of GetSession.
+func (mr *MockSessionManagerMockRecorder) GetSession(ctx, userID, digest interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSession", reflect.TypeOf((*MockSessionManager)(nil).GetSession), ctx, userID, digest)
+}
+
+// GetSessions mocks base method.
+func (m *MockSessionManager) GetSessions(ctx context.Context, userID string) ([]modelstorage.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSessions", ctx, userID)
+	ret0, _ := ret[0].([]modelstorage.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSessions indicates an expected call of GetSessions.
+func (mr *MockSessionManagerMockRecorder) GetSessions(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSessions", reflect.TypeOf((*MockSessionManager)(nil).GetSessions), ctx, userID)
+}
+
+// TouchSession mocks base method.
+func (m *MockSessionManager) TouchSession(ctx context.Context, sessionID int64, peer string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TouchSession", ctx, sessionID, peer)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TouchSession indicates an expected call of TouchSession.
+func (mr *MockSessionManagerMockRecorder) TouchSession(ctx, sessionID, peer interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TouchSession", reflect.TypeOf((*MockSessionManager)(nil).TouchSession), ctx, sessionID, peer)
+}
+
+// MockEmergencyAccessKeeper is a mock of EmergencyAccessKeeper interface.
+type MockEmergencyAccessKeeper struct {
+	ctrl     *gomock.Controller
+	recorder *MockEmergencyAccessKeeperMockRecorder
+}
+
+// MockEmergencyAccessKeeperMockRecorder is the mock recorder for MockEmergencyAccessKeeper.
+type MockEmergencyAccessKeeperMockRecorder struct {
+	mock *MockEmergencyAccessKeeper
+}
+
+// NewMockEmergencyAccessKeeper creates a new mock instance.
+func NewMockEmergencyAccessKeeper(ctrl *gomock.Controller) *MockEmergencyAccessKeeper {
+	mock := &MockEmergencyAccessKeeper{ctrl: ctrl}
+	mock.recorder = &MockEmergencyAccessKeeperMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEmergencyAccessKeeper) EXPECT() *MockEmergencyAccessKeeperMockRecorder {
+	return m.recorder
+}
+
+// DeleteTrustedContact mocks base method.
+func (m *MockEmergencyAccessKeeper) DeleteTrustedContact(ctx context.Context, ownerID, contactID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTrustedContact", ctx, ownerID, contactID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTrustedContact indicates an expected call of DeleteTrustedContact.
+func (mr *MockEmergencyAccessKeeperMockRecorder) DeleteTrustedContact(ctx, ownerID, contactID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTrustedContact", reflect.TypeOf((*MockEmergencyAccessKeeper)(nil).DeleteTrustedContact), ctx, ownerID, contactID)
+}
+
+// GetDueEmergencyAccess mocks base method.
+func (m *MockEmergencyAccessKeeper) GetDueEmergencyAccess(ctx context.Context) ([]modelstorage.EmergencyAccess, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDueEmergencyAccess", ctx)
+	ret0, _ := ret[0].([]modelstorage.EmergencyAccess)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDueEmergencyAccess indicates an expected call of GetDueEmergencyAccess.
+func (mr *MockEmergencyAccessKeeperMockRecorder) GetDueEmergencyAccess(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDueEmergencyAccess", reflect.TypeOf((*MockEmergencyAccessKeeper)(nil).GetDueEmergencyAccess), ctx)
+}
+
+// GetEmergencyAccess mocks base method.
+func (m *MockEmergencyAccessKeeper) GetEmergencyAccess(ctx context.Context, ownerID, contactID string) (modelstorage.EmergencyAccess, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEmergencyAccess", ctx, ownerID, contactID)
+	ret0, _ := ret[0].(modelstorage.EmergencyAccess)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEmergencyAccess indicates an expected call of GetEmergencyAccess.
+func (mr *MockEmergencyAccessKeeperMockRecorder) GetEmergencyAccess(ctx, ownerID, contactID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEmergencyAccess", reflect.TypeOf((*MockEmergencyAccessKeeper)(nil).GetEmergencyAccess), ctx, ownerID, contactID)
+}
+
+// GetEmergencyEntries mocks base method.
+func (m *MockEmergencyAccessKeeper) GetEmergencyEntries(ctx context.Context, vaultID string, afterID int64, limit int) ([]modelstorage.EmergencyEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEmergencyEntries", ctx, vaultID, afterID, limit)
+	ret0, _ := ret[0].([]modelstorage.EmergencyEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEmergencyEntries indicates an expected call of GetEmergencyEntries.
+func (mr *MockEmergencyAccessKeeperMockRecorder) GetEmergencyEntries(ctx, vaultID, afterID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEmergencyEntries", reflect.TypeOf((*MockEmergencyAccessKeeper)(nil).GetEmergencyEntries), ctx, vaultID, afterID, limit)
+}
+
+// GetEmergencyGrantors mocks base method.
+func (m *MockEmergencyAccessKeeper) GetEmergencyGrantors(ctx context.Context, contactID string) ([]modelstorage.EmergencyAccess, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEmergencyGrantors", ctx, contactID)
+	ret0, _ := ret[0].([]modelstorage.EmergencyAccess)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEmergencyGrantors indicates an expected call of GetEmergencyGrantors.
+func (mr *MockEmergencyAccessKeeperMockRecorder) GetEmergencyGrantors(ctx, contactID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEmergencyGrantors", reflect.TypeOf((*MockEmergencyAccessKeeper)(nil).GetEmergencyGrantors), ctx, contactID)
+}
+
+// GetEmergencyVault mocks base method.
+func (m *MockEmergencyAccessKeeper) GetEmergencyVault(ctx context.Context, accessID, afterID int64, limit int) ([]modelstorage.EmergencyEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEmergencyVault", ctx, accessID, afterID, limit)
+	ret0, _ := ret[0].([]modelstorage.EmergencyEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEmergencyVault indicates an expected call of GetEmergencyVault.
+func (mr *MockEmergencyAccessKeeperMockRecorder) GetEmergencyVault(ctx, accessID, afterID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEmergencyVault", reflect.TypeOf((*MockEmergencyAccessKeeper)(nil).GetEmergencyVault), ctx, accessID, afterID, limit)
+}
+
+// GetTrustedContacts mocks base method.
+func (m *MockEmergencyAccessKeeper) GetTrustedContacts(ctx context.Context, ownerID string) ([]modelstorage.EmergencyAccess, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTrustedContacts", ctx, ownerID)
+	ret0, _ := ret[0].([]modelstorage.EmergencyAccess)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTrustedContacts indicates an expected call of GetTrustedContacts.
+func (mr *MockEmergencyAccessKeeperMockRecorder) GetTrustedContacts(ctx, ownerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTrustedContacts", reflect.TypeOf((*MockEmergencyAccessKeeper)(nil).GetTrustedContacts), ctx, ownerID)
+}
+
+// GrantEmergencyAccess mocks base method.
+func (m *MockEmergencyAccessKeeper) GrantEmergencyAccess(ctx context.Context, accessID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GrantEmergencyAccess", ctx, accessID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// GrantEmergencyAccess indicates an expected call of GrantEmergencyAccess.
+func (mr *MockEmergencyAccessKeeperMockRecorder) GrantEmergencyAccess(ctx, accessID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GrantEmergencyAccess", reflect.TypeOf((*MockEmergencyAccessKeeper)(nil).GrantEmergencyAccess), ctx, accessID)
+}
+
+// RequestEmergencyAccess mocks base method.
+func (m *MockEmergencyAccessKeeper) RequestEmergencyAccess(ctx context.Context, ownerID, contactID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestEmergencyAccess", ctx, ownerID, contactID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RequestEmergencyAccess indicates an expected call of RequestEmergencyAccess.
+func (mr *MockEmergencyAccessKeeperMockRecorder) RequestEmergencyAccess(ctx, ownerID, contactID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestEmergencyAccess", reflect.TypeOf((*MockEmergencyAccessKeeper)(nil).RequestEmergencyAccess), ctx, ownerID, contactID)
+}
+
+// ResetEmergencyAccess mocks base method.
+func (m *MockEmergencyAccessKeeper) ResetEmergencyAccess(ctx context.Context, ownerID, contactID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetEmergencyAccess", ctx, ownerID, contactID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetEmergencyAccess indicates an expected call of ResetEmergencyAccess.
+func (mr *MockEmergencyAccessKeeperMockRecorder) ResetEmergencyAccess(ctx, ownerID, contactID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetEmergencyAccess", reflect.TypeOf((*MockEmergencyAccessKeeper)(nil).ResetEmergencyAccess), ctx, ownerID, contactID)
+}
+
+// SetEmergencyEntries mocks base method.
+func (m *MockEmergencyAccessKeeper) SetEmergencyEntries(ctx context.Context, vaultID string, entries []modelstorage.EmergencyEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetEmergencyEntries", ctx, vaultID, entries)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetEmergencyEntries indicates an expected call of SetEmergencyEntries.
+func (mr *MockEmergencyAccessKeeperMockRecorder) SetEmergencyEntries(ctx, vaultID, entries interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetEmergencyEntries", reflect.TypeOf((*MockEmergencyAccessKeeper)(nil).SetEmergencyEntries), ctx, vaultID, entries)
+}
+
+// SetTrustedContact mocks base method.
+func (m *MockEmergencyAccessKeeper) SetTrustedContact(ctx context.Context, ownerID, contactID string, waitDays int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTrustedContact", ctx, ownerID, contactID, waitDays)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetTrustedContact indicates an expected call of SetTrustedContact.
+func (mr *MockEmergencyAccessKeeperMockRecorder) SetTrustedContact(ctx, ownerID, contactID, waitDays interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTrustedContact", reflect.TypeOf((*MockEmergencyAccessKeeper)(nil).SetTrustedContact), ctx, ownerID, contactID, waitDays)
+}
+
+// MockAuditKeeper is a mock of AuditKeeper interface.
+type MockAuditKeeper struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditKeeperMockRecorder
+}
+
+// MockAuditKeeperMockRecorder is the mock recorder for MockAuditKeeper.
+type MockAuditKeeperMockRecorder struct {
+	mock *MockAuditKeeper
+}
+
+// NewMockAuditKeeper creates a new mock instance.
+func NewMockAuditKeeper(ctrl *gomock.Controller) *MockAuditKeeper {
+	mock := &MockAuditKeeper{ctrl: ctrl}
+	mock.recorder = &MockAuditKeeperMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuditKeeper) EXPECT() *MockAuditKeeperMockRecorder {
+	return m.recorder
+}
+
+// AddAuditCheckpoint mocks base method.
+func (m *MockAuditKeeper) AddAuditCheckpoint(ctx context.Context, checkpoint modelstorage.AuditCheckpoint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddAuditCheckpoint", ctx, checkpoint)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddAuditCheckpoint indicates an expected call of AddAuditCheckpoint.
+func (mr *MockAuditKeeperMockRecorder) AddAuditCheckpoint(ctx, checkpoint interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAuditCheckpoint", reflect.TypeOf((*MockAuditKeeper)(nil).AddAuditCheckpoint), ctx, checkpoint)
+}
+
+// AddAuditEvent mocks base method.
+func (m *MockAuditKeeper) AddAuditEvent(ctx context.Context, event modelstorage.AuditEvent) (modelstorage.AuditEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddAuditEvent", ctx, event)
+	ret0, _ := ret[0].(modelstorage.AuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddAuditEvent indicates an expected call of AddAuditEvent.
+func (mr *MockAuditKeeperMockRecorder) AddAuditEvent(ctx, event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAuditEvent", reflect.TypeOf((*MockAuditKeeper)(nil).AddAuditEvent), ctx, event)
+}
+
+// GetAuditEvents mocks base method.
+func (m *MockAuditKeeper) GetAuditEvents(ctx context.Context, userID string, beforeID int64, limit int) ([]modelstorage.AuditEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuditEvents", ctx, userID, beforeID, limit)
+	ret0, _ := ret[0].([]modelstorage.AuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuditEvents indicates an expected call of GetAuditEvents.
+func (mr *MockAuditKeeperMockRecorder) GetAuditEvents(ctx, userID, beforeID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuditEvents", reflect.TypeOf((*MockAuditKeeper)(nil).GetAuditEvents), ctx, userID, beforeID, limit)
+}
+
+// MockDataStorage is a mock of DataStorage interface.
+type MockDataStorage struct {
+	ctrl     *gomock.Controller
+	recorder *MockDataStorageMockRecorder
+}
+
+// MockDataStorageMockRecorder is the mock recorder for MockDataStorage.
+type MockDataStorageMockRecorder struct {
+	mock *MockDataStorage
+}
+
+// NewMockDataStorage creates a new mock instance.
+func NewMockDataStorage(ctrl *gomock.Controller) *MockDataStorage {
+	mock := &MockDataStorage{ctrl: ctrl}
+	mock.recorder = &MockDataStorageMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDataStorage) EXPECT() *MockDataStorageMockRecorder {
+	return m.recorder
+}
+
+// AddAuditCheckpoint mocks base method.
+func (m *MockDataStorage) AddAuditCheckpoint(ctx context.Context, checkpoint modelstorage.AuditCheckpoint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddAuditCheckpoint", ctx, checkpoint)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddAuditCheckpoint indicates an expected call of AddAuditCheckpoint.
+func (mr *MockDataStorageMockRecorder) AddAuditCheckpoint(ctx, checkpoint interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAuditCheckpoint", reflect.TypeOf((*MockDataStorage)(nil).AddAuditCheckpoint), ctx, checkpoint)
+}
+
+// AddAuditEvent mocks base method.
+func (m *MockDataStorage) AddAuditEvent(ctx context.Context, event modelstorage.AuditEvent) (modelstorage.AuditEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddAuditEvent", ctx, event)
+	ret0, _ := ret[0].(modelstorage.AuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddAuditEvent indicates an expected call of AddAuditEvent.
+func (mr *MockDataStorageMockRecorder) AddAuditEvent(ctx, event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAuditEvent", reflect.TypeOf((*MockDataStorage)(nil).AddAuditEvent), ctx, event)
+}
+
+// AddCollection mocks base method.
+func (m *MockDataStorage) AddCollection(ctx context.Context, collection modelstorage.Collection, owner modelstorage.CollectionMember, event modelstorage.CollectionEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddCollection", ctx, collection, owner, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddCollection indicates an expected call of AddCollection.
+func (mr *MockDataStorageMockRecorder) AddCollection(ctx, collection, owner, event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddCollection", reflect.TypeOf((*MockDataStorage)(nil).AddCollection), ctx, collection, owner, event)
+}
+
+// AddNewUser mocks base method.
+func (m *MockDataStorage) AddNewUser(ctx context.Context, login, password, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddNewUser", ctx, login, password, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddNewUser indicates an expected call of AddNewUser.
+func (mr *MockDataStorageMockRecorder) AddNewUser(ctx, login, password, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddNewUser", reflect.TypeOf((*MockDataStorage)(nil).AddNewUser), ctx, login, password, userID)
+}
+
+// AddSession mocks base method.
+func (m *MockDataStorage) AddSession(ctx context.Context, session modelstorage.Session) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddSession", ctx, session)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddSession indicates an expected call of AddSession.
+func (mr *MockDataStorageMockRecorder) AddSession(ctx, session interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddSession", reflect.TypeOf((*MockDataStorage)(nil).AddSession), ctx, session)
+}
+
+// CheckUser mocks base method.
+func (m *MockDataStorage) CheckUser(ctx context.Context, login, password string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckUser", ctx, login, password)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CheckUser indicates an expected call of CheckUser.
+func (mr *MockDataStorageMockRecorder) CheckUser(ctx, login, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckUser", reflect.TypeOf((*MockDataStorage)(nil).CheckUser), ctx, login, password)
+}
+
+// DeleteBatch mocks base method.
+func (m *MockDataStorage) DeleteBatch(ctx context.Context, identifiers []string, userID, db string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteBatch", ctx, identifiers, userID, db)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteBatch indicates an expected call of DeleteBatch.
+func (mr *MockDataStorageMockRecorder) DeleteBatch(ctx, identifiers, userID, db interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBatch", reflect.TypeOf((*MockDataStorage)(nil).DeleteBatch), ctx, identifiers, userID, db)
+}
+
+// DeleteCollection mocks base method.
+func (m *MockDataStorage) DeleteCollection(ctx context.Context, collectionID, vaultID string, event modelstorage.CollectionEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCollection", ctx, collectionID, vaultID, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCollection indicates an expected call of DeleteCollection.
+func (mr *MockDataStorageMockRecorder) DeleteCollection(ctx, collectionID, vaultID, event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCollection", reflect.TypeOf((*MockDataStorage)(nil).DeleteCollection), ctx, collectionID, vaultID, event)
+}
+
+// DeleteExpiredSessions mocks base method.
+func (m *MockDataStorage) DeleteExpiredSessions(ctx context.Context, idleTTL, maxAge time.Duration) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredSessions", ctx, idleTTL, maxAge)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpiredSessions indicates an expected call of DeleteExpiredSessions.
+func (mr *MockDataStorageMockRecorder) DeleteExpiredSessions(ctx, idleTTL, maxAge interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredSessions", reflect.TypeOf((*MockDataStorage)(nil).DeleteExpiredSessions), ctx, idleTTL, maxAge)
+}
+
+// DeleteMember mocks base method.
+func (m *MockDataStorage) DeleteMember(ctx context.Context, collectionID, userID string, event modelstorage.CollectionEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteMember", ctx, collectionID, userID, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteMember indicates an expected call of DeleteMember.
+func (mr *MockDataStorageMockRecorder) DeleteMember(ctx, collectionID, userID, event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteMember", reflect.TypeOf((*MockDataStorage)(nil).DeleteMember), ctx, collectionID, userID, event)
+}
+
+// DeleteSession mocks base method.
+func (m *MockDataStorage) DeleteSession(ctx context.Context, userID string, sessionID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSession", ctx, userID, sessionID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSession indicates an expected call of DeleteSession.
+func (mr *MockDataStorageMockRecorder) DeleteSession(ctx, userID, sessionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSession", reflect.TypeOf((*MockDataStorage)(nil).DeleteSession), ctx, userID, sessionID)
+}
+
+// DeleteTrustedContact mocks base method.
+func (m *MockDataStorage) DeleteTrustedContact(ctx context.Context, ownerID, contactID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTrustedContact", ctx, ownerID, contactID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTrustedContact indicates an expected call of DeleteTrustedContact.
+func (mr *MockDataStorageMockRecorder) DeleteTrustedContact(ctx, ownerID, contactID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTrustedContact", reflect.TypeOf((*MockDataStorage)(nil).DeleteTrustedContact), ctx, ownerID, contactID)
+}
+
+// Flush mocks base method.
+func (m *MockDataStorage) Flush(ctx context.Context, batch []modelstorage.Removal) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Flush", ctx, batch)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Flush indicates an expected call of Flush.
+func (mr *MockDataStorageMockRecorder) Flush(ctx, batch interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Flush", reflect.TypeOf((*MockDataStorage)(nil).Flush), ctx, batch)
+}
+
+// GetAuditEvents mocks base method.
+func (m *MockDataStorage) GetAuditEvents(ctx context.Context, userID string, beforeID int64, limit int) ([]modelstorage.AuditEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuditEvents", ctx, userID, beforeID, limit)
+	ret0, _ := ret[0].([]modelstorage.AuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuditEvents indicates an expected call of GetAuditEvents.
+func (mr *MockDataStorageMockRecorder) GetAuditEvents(ctx, userID, beforeID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuditEvents", reflect.TypeOf((*MockDataStorage)(nil).GetAuditEvents), ctx, userID, beforeID, limit)
+}
+
+// GetBankCardData mocks base method.
+func (m *MockDataStorage) GetBankCardData(ctx context.Context, userID string, afterID int64, limit int, tokens []string) ([]modelstorage.BankCardStorageEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBankCardData", ctx, userID, afterID, limit, tokens)
+	ret0, _ := ret[0].([]modelstorage.BankCardStorageEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBankCardData indicates an expected call of GetBankCardData.
+func (mr *MockDataStorageMockRecorder) GetBankCardData(ctx, userID, afterID, limit, tokens interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBankCardData", reflect.TypeOf((*MockDataStorage)(nil).GetBankCardData), ctx, userID, afterID, limit, tokens)
+}
+
+// GetCollectionEvents mocks base method.
+func (m *MockDataStorage) GetCollectionEvents(ctx context.Context, collectionID string) ([]modelstorage.CollectionEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCollectionEvents", ctx, collectionID)
+	ret0, _ := ret[0].([]modelstorage.CollectionEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCollectionEvents indicates an expected call of GetCollectionEvents.
+func (mr *MockDataStorageMockRecorder) GetCollectionEvents(ctx, collectionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCollectionEvents", reflect.TypeOf((*MockDataStorage)(nil).GetCollectionEvents), ctx, collectionID)
+}
+
+// GetCollections mocks base method.
+func (m *MockDataStorage) GetCollections(ctx context.Context, userID string) ([]modelstorage.Collection, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCollections", ctx, userID)
+	ret0, _ := ret[0].([]modelstorage.Collection)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCollections indicates an expected call of GetCollections.
+func (mr *MockDataStorageMockRecorder) GetCollections(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCollections", reflect.TypeOf((*MockDataStorage)(nil).GetCollections), ctx, userID)
+}
+
+// GetDueEmergencyAccess mocks base method.
+func (m *MockDataStorage) GetDueEmergencyAccess(ctx context.Context) ([]modelstorage.EmergencyAccess, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDueEmergencyAccess", ctx)
+	ret0, _ := ret[0].([]modelstorage.EmergencyAccess)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDueEmergencyAccess indicates an expected call of GetDueEmergencyAccess.
+func (mr *MockDataStorageMockRecorder) GetDueEmergencyAccess(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDueEmergencyAccess", reflect.TypeOf((*MockDataStorage)(nil).GetDueEmergencyAccess), ctx)
+}
+
+// GetEmergencyAccess mocks base method.
+func (m *MockDataStorage) GetEmergencyAccess(ctx context.Context, ownerID, contactID string) (modelstorage.EmergencyAccess, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEmergencyAccess", ctx, ownerID, contactID)
+	ret0, _ := ret[0].(modelstorage.EmergencyAccess)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEmergencyAccess indicates an expected call of GetEmergencyAccess.
+func (mr *MockDataStorageMockRecorder) GetEmergencyAccess(ctx, ownerID, contactID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEmergencyAccess", reflect.TypeOf((*MockDataStorage)(nil).GetEmergencyAccess), ctx, ownerID, contactID)
+}
+
+// GetEmergencyEntries mocks base method.
+func (m *MockDataStorage) GetEmergencyEntries(ctx context.Context, vaultID string, afterID int64, limit int) ([]modelstorage.EmergencyEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEmergencyEntries", ctx, vaultID, afterID, limit)
+	ret0, _ := ret[0].([]modelstorage.EmergencyEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEmergencyEntries indicates an expected call of GetEmergencyEntries.
+func (mr *MockDataStorageMockRecorder) GetEmergencyEntries(ctx, vaultID, afterID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEmergencyEntries", reflect.TypeOf((*MockDataStorage)(nil).GetEmergencyEntries), ctx, vaultID, afterID, limit)
+}
+
+// GetEmergencyGrantors mocks base method.
+func (m *MockDataStorage) GetEmergencyGrantors(ctx context.Context, contactID string) ([]modelstorage.EmergencyAccess, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEmergencyGrantors", ctx, contactID)
+	ret0, _ := ret[0].([]modelstorage.EmergencyAccess)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEmergencyGrantors indicates an expected call of GetEmergencyGrantors.
+func (mr *MockDataStorageMockRecorder) GetEmergencyGrantors(ctx, contactID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEmergencyGrantors", reflect.TypeOf((*MockDataStorage)(nil).GetEmergencyGrantors), ctx, contactID)
+}
+
+// GetEmergencyVault mocks base method.
+func (m *MockDataStorage) GetEmergencyVault(ctx context.Context, accessID, afterID int64, limit int) ([]modelstorage.EmergencyEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEmergencyVault", ctx, accessID, afterID, limit)
+	ret0, _ := ret[0].([]modelstorage.EmergencyEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEmergencyVault indicates an expected call of GetEmergencyVault.
+func (mr *MockDataStorageMockRecorder) GetEmergencyVault(ctx, accessID, afterID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEmergencyVault", reflect.TypeOf((*MockDataStorage)(nil).GetEmergencyVault), ctx, accessID, afterID, limit)
+}
+
+// GetEntry mocks base method.
+func (m *MockDataStorage) GetEntry(ctx context.Context, userID, db, identifier string) (modelstorage.BatchItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEntry", ctx, userID, db, identifier)
+	ret0, _ := ret[0].(modelstorage.BatchItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEntry indicates an expected call of GetEntry.
+func (mr *MockDataStorageMockRecorder) GetEntry(ctx, userID, db, identifier interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEntry", reflect.TypeOf((*MockDataStorage)(nil).GetEntry), ctx, userID, db, identifier)
+}
+
+// GetInvitation mocks base method.
+func (m *MockDataStorage) GetInvitation(ctx context.Context, invitationID int64) (modelstorage.CollectionInvitation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInvitation", ctx, invitationID)
+	ret0, _ := ret[0].(modelstorage.CollectionInvitation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInvitation indicates an expected call of GetInvitation.
+func (mr *MockDataStorageMockRecorder) GetInvitation(ctx, invitationID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInvitation", reflect.TypeOf((*MockDataStorage)(nil).GetInvitation), ctx, invitationID)
+}
+
+// GetInvitations mocks base method.
+func (m *MockDataStorage) GetInvitations(ctx context.Context, inviteeID string) ([]modelstorage.CollectionInvitation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInvitations", ctx, inviteeID)
+	ret0, _ := ret[0].([]modelstorage.CollectionInvitation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInvitations indicates an expected call of GetInvitations.
+func (mr *MockDataStorageMockRecorder) GetInvitations(ctx, inviteeID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInvitations", reflect.TypeOf((*MockDataStorage)(nil).GetInvitations), ctx, inviteeID)
+}
+
+// GetLoginPasswordData mocks base method.
+func (m *MockDataStorage) GetLoginPasswordData(ctx context.Context, userID string, afterID int64, limit int, tokens []string) ([]modelstorage.LoginPasswordStorageEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLoginPasswordData", ctx, userID, afterID, limit, tokens)
+	ret0, _ := ret[0].([]modelstorage.LoginPasswordStorageEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLoginPasswordData indicates an expected call of GetLoginPasswordData.
+func (mr *MockDataStorageMockRecorder) GetLoginPasswordData(ctx, userID, afterID, limit, tokens interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoginPasswordData", reflect.TypeOf((*MockDataStorage)(nil).GetLoginPasswordData), ctx, userID, afterID, limit, tokens)
+}
+
+// GetMember mocks base method.
+func (m *MockDataStorage) GetMember(ctx context.Context, collectionID, userID string) (modelstorage.CollectionMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMember", ctx, collectionID, userID)
+	ret0, _ := ret[0].(modelstorage.CollectionMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMember indicates an expected call of GetMember.
+func (mr *MockDataStorageMockRecorder) GetMember(ctx, collectionID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMember", reflect.TypeOf((*MockDataStorage)(nil).GetMember), ctx, collectionID, userID)
+}
+
+// GetMembers mocks base method.
+func (m *MockDataStorage) GetMembers(ctx context.Context, collectionID string) ([]modelstorage.CollectionMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMembers", ctx, collectionID)
+	ret0, _ := ret[0].([]modelstorage.CollectionMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMembers indicates an expected call of GetMembers.
+func (mr *MockDataStorageMockRecorder) GetMembers(ctx, collectionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMembers", reflect.TypeOf((*MockDataStorage)(nil).GetMembers), ctx, collectionID)
+}
+
+// GetSession mocks base method.
+func (m *MockDataStorage) GetSession(ctx context.Context, userID, digest string) (modelstorage.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSession", ctx, userID, digest)
+	ret0, _ := ret[0].(modelstorage.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSession indicates an expected call of GetSession.
+func (mr *MockDataStorageMockRecorder) GetSession(ctx, userID, digest interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSession", reflect.TypeOf((*MockDataStorage)(nil).GetSession), ctx, userID, digest)
+}
+
+// GetSessions mocks base method.
+func (m *MockDataStorage) GetSessions(ctx context.Context, userID string) ([]modelstorage.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSessions", ctx, userID)
+	ret0, _ := ret[0].([]modelstorage.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSessions indicates an expected call of GetSessions.
+func (mr *MockDataStorageMockRecorder) GetSessions(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSessions", reflect.TypeOf((*MockDataStorage)(nil).GetSessions), ctx, userID)
+}
+
+// GetShare mocks base method.
+func (m *MockDataStorage) GetShare(ctx context.Context, ownerID, db, identifier string) (modelstorage.Share, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetShare", ctx, ownerID, db, identifier)
+	ret0, _ := ret[0].(modelstorage.Share)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetShare indicates an expected call of GetShare.
+func (mr *MockDataStorageMockRecorder) GetShare(ctx, ownerID, db, identifier interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetShare", reflect.TypeOf((*MockDataStorage)(nil).GetShare), ctx, ownerID, db, identifier)
+}
+
+// GetShareByID mocks base method.
+func (m *MockDataStorage) GetShareByID(ctx context.Context, shareID int64) (modelstorage.Share, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetShareByID", ctx, shareID)
+	ret0, _ := ret[0].(modelstorage.Share)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetShareByID indicates an expected call of GetShareByID.
+func (mr *MockDataStorageMockRecorder) GetShareByID(ctx, shareID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetShareByID", reflect.TypeOf((*MockDataStorage)(nil).GetShareByID), ctx, shareID)
+}
+
+// GetSharedWithUser mocks base method.
+func (m *MockDataStorage) GetSharedWithUser(ctx context.Context, recipientID string) ([]modelstorage.Share, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSharedWithUser", ctx, recipientID)
+	ret0, _ := ret[0].([]modelstorage.Share)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSharedWithUser indicates an expected call of GetSharedWithUser.
+func (mr *MockDataStorageMockRecorder) GetSharedWithUser(ctx, recipientID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSharedWithUser", reflect.TypeOf((*MockDataStorage)(nil).GetSharedWithUser), ctx, recipientID)
+}
+
+// GetSharesByOwner mocks base method.
+func (m *MockDataStorage) GetSharesByOwner(ctx context.Context, ownerID string) ([]modelstorage.Share, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSharesByOwner", ctx, ownerID)
+	ret0, _ := ret[0].([]modelstorage.Share)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSharesByOwner indicates an expected call of GetSharesByOwner.
+func (mr *MockDataStorageMockRecorder) GetSharesByOwner(ctx, ownerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSharesByOwner", reflect.TypeOf((*MockDataStorage)(nil).GetSharesByOwner), ctx, ownerID)
+}
+
+// GetTextBinaryData mocks base method.
+func (m *MockDataStorage) GetTextBinaryData(ctx context.Context, userID string, afterID int64, limit int, tokens []string) ([]modelstorage.TextBinaryStorageEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTextBinaryData", ctx, userID, afterID, limit, tokens)
+	ret0, _ := ret[0].([]modelstorage.TextBinaryStorageEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTextBinaryData indicates an expected call of GetTextBinaryData.
+func (mr *MockDataStorageMockRecorder) GetTextBinaryData(ctx, userID, afterID, limit, tokens interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTextBinaryData", reflect.TypeOf((*MockDataStorage)(nil).GetTextBinaryData), ctx, userID, afterID, limit, tokens)
+}
+
+// GetTrustedContacts mocks base method.
+func (m *MockDataStorage) GetTrustedContacts(ctx context.Context, ownerID string) ([]modelstorage.EmergencyAccess, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTrustedContacts", ctx, ownerID)
+	ret0, _ := ret[0].([]modelstorage.EmergencyAccess)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTrustedContacts indicates an expected call of GetTrustedContacts.
+func (mr *MockDataStorageMockRecorder) GetTrustedContacts(ctx, ownerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTrustedContacts", reflect.TypeOf((*MockDataStorage)(nil).GetTrustedContacts), ctx, ownerID)
+}
+
+// GetUserIDByLogin mocks base method.
+func (m *MockDataStorage) GetUserIDByLogin(ctx context.Context, login string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserIDByLogin", ctx, login)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserIDByLogin indicates an expected call of GetUserIDByLogin.
+func (mr *MockDataStorageMockRecorder) GetUserIDByLogin(ctx, login interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserIDByLogin", reflect.TypeOf((*MockDataStorage)(nil).GetUserIDByLogin), ctx, login)
+}
+
+// GetUserKeys mocks base method.
+func (m *MockDataStorage) GetUserKeys(ctx context.Context, userID string) (modelstorage.UserKeys, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserKeys", ctx, userID)
+	ret0, _ := ret[0].(modelstorage.UserKeys)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserKeys indicates an expected call of GetUserKeys.
+func (mr *MockDataStorageMockRecorder) GetUserKeys(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserKeys", reflect.TypeOf((*MockDataStorage)(nil).GetUserKeys), ctx, userID)
+}
+
+// GrantEmergencyAccess mocks base method.
+func (m *MockDataStorage) GrantEmergencyAccess(ctx context.Context, accessID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GrantEmergencyAccess", ctx, accessID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// GrantEmergencyAccess indicates an expected call of GrantEmergencyAccess.
+func (mr *MockDataStorageMockRecorder) GrantEmergencyAccess(ctx, accessID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GrantEmergencyAccess", reflect.TypeOf((*MockDataStorage)(nil).GrantEmergencyAccess), ctx, accessID)
+}
+
+// RequestEmergencyAccess mocks base method.
+func (m *MockDataStorage) RequestEmergencyAccess(ctx context.Context, ownerID, contactID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestEmergencyAccess", ctx, ownerID, contactID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RequestEmergencyAccess indicates an expected call of RequestEmergencyAccess.
+func (mr *MockDataStorageMockRecorder) RequestEmergencyAccess(ctx, ownerID, contactID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestEmergencyAccess", reflect.TypeOf((*MockDataStorage)(nil).RequestEmergencyAccess), ctx, ownerID, contactID)
+}
+
+// ResetEmergencyAccess mocks base method.
+func (m *MockDataStorage) ResetEmergencyAccess(ctx context.Context, ownerID, contactID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetEmergencyAccess", ctx, ownerID, contactID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetEmergencyAccess indicates an expected call of ResetEmergencyAccess.
+func (mr *MockDataStorageMockRecorder) ResetEmergencyAccess(ctx, ownerID, contactID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetEmergencyAccess", reflect.TypeOf((*MockDataStorage)(nil).ResetEmergencyAccess), ctx, ownerID, contactID)
+}
+
+// ResolveInvitation mocks base method.
+func (m *MockDataStorage) ResolveInvitation(ctx context.Context, invitation modelstorage.CollectionInvitation, accept bool, event modelstorage.CollectionEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResolveInvitation", ctx, invitation, accept, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResolveInvitation indicates an expected call of ResolveInvitation.
+func (mr *MockDataStorageMockRecorder) ResolveInvitation(ctx, invitation, accept, event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolveInvitation", reflect.TypeOf((*MockDataStorage)(nil).ResolveInvitation), ctx, invitation, accept, event)
+}
+
+// SearchEntries mocks base method.
+func (m *MockDataStorage) SearchEntries(ctx context.Context, userID string, tokens []string, cursor modelstorage.SearchCursor, limit int) (modelstorage.SearchPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchEntries", ctx, userID, tokens, cursor, limit)
+	ret0, _ := ret[0].(modelstorage.SearchPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchEntries indicates an expected call of SearchEntries.
+func (mr *MockDataStorageMockRecorder) SearchEntries(ctx, userID, tokens, cursor, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchEntries", reflect.TypeOf((*MockDataStorage)(nil).SearchEntries), ctx, userID, tokens, cursor, limit)
+}
+
+// SendToQueue mocks base method.
+func (m *MockDataStorage) SendToQueue(item modelstorage.Removal) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SendToQueue", item)
+}
+
+// SendToQueue indicates an expected call of SendToQueue.
+func (mr *MockDataStorageMockRecorder) SendToQueue(item interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendToQueue", reflect.TypeOf((*MockDataStorage)(nil).SendToQueue), item)
+}
+
+// SetBankCardData mocks base method.
+func (m *MockDataStorage) SetBankCardData(ctx context.Context, userID, identifier, number, holder, cvv, expiry, pin, meta string, labels modelstorage.Labels, fields string, tokens []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetBankCardData", ctx, userID, identifier, number, holder, cvv, expiry, pin, meta, labels, fields, tokens)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetBankCardData indicates an expected call of SetBankCardData.
+func (mr *MockDataStorageMockRecorder) SetBankCardData(ctx, userID, identifier, number, holder, cvv, expiry, pin, meta, labels, fields, tokens interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetBankCardData", reflect.TypeOf((*MockDataStorage)(nil).SetBankCardData), ctx, userID, identifier, number, holder, cvv, expiry, pin, meta, labels, fields, tokens)
+}
+
+// SetBatchData mocks base method.
+func (m *MockDataStorage) SetBatchData(ctx context.Context, userID string, items []modelstorage.BatchItem, overwrite bool) ([]modelstorage.BatchItemResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetBatchData", ctx, userID, items, overwrite)
+	ret0, _ := ret[0].([]modelstorage.BatchItemResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetBatchData indicates an expected call of SetBatchData.
+func (mr *MockDataStorageMockRecorder) SetBatchData(ctx, userID, items, overwrite interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetBatchData", reflect.TypeOf((*MockDataStorage)(nil).SetBatchData), ctx, userID, items, overwrite)
+}
+
+// SetEmergencyEntries mocks base method.
+func (m *MockDataStorage) SetEmergencyEntries(ctx context.Context, vaultID string, entries []modelstorage.EmergencyEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetEmergencyEntries", ctx, vaultID, entries)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetEmergencyEntries indicates an expected call of SetEmergencyEntries.
+func (mr *MockDataStorageMockRecorder) SetEmergencyEntries(ctx, vaultID, entries interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetEmergencyEntries", reflect.TypeOf((*MockDataStorage)(nil).SetEmergencyEntries), ctx, vaultID, entries)
+}
+
+// SetInvitation mocks base method.
+func (m *MockDataStorage) SetInvitation(ctx context.Context, invitation modelstorage.CollectionInvitation, event modelstorage.CollectionEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetInvitation", ctx, invitation, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetInvitation indicates an expected call of SetInvitation.
+func (mr *MockDataStorageMockRecorder) SetInvitation(ctx, invitation, event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetInvitation", reflect.TypeOf((*MockDataStorage)(nil).SetInvitation), ctx, invitation, event)
+}
+
+// SetLoginPasswordData mocks base method.
+func (m *MockDataStorage) SetLoginPasswordData(ctx context.Context, userID, identifier, login, password, meta string, labels modelstorage.Labels, fields string, tokens []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetLoginPasswordData", ctx, userID, identifier, login, password, meta, labels, fields, tokens)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetLoginPasswordData indicates an expected call of SetLoginPasswordData.
+func (mr *MockDataStorageMockRecorder) SetLoginPasswordData(ctx, userID, identifier, login, password, meta, labels, fields, tokens interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLoginPasswordData", reflect.TypeOf((*MockDataStorage)(nil).SetLoginPasswordData), ctx, userID, identifier, login, password, meta, labels, fields, tokens)
+}
+
+// SetMemberRole mocks base method.
+func (m *MockDataStorage) SetMemberRole(ctx context.Context, member modelstorage.CollectionMember, event modelstorage.CollectionEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetMemberRole", ctx, member, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetMemberRole indicates an expected call of SetMemberRole.
+func (mr *MockDataStorageMockRecorder) SetMemberRole(ctx, member, event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetMemberRole", reflect.TypeOf((*MockDataStorage)(nil).SetMemberRole), ctx, member, event)
+}
+
+// SetShare mocks base method.
+func (m *MockDataStorage) SetShare(ctx context.Context, share modelstorage.Share) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetShare", ctx, share)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetShare indicates an expected call of SetShare.
+func (mr *MockDataStorageMockRecorder) SetShare(ctx, share interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetShare", reflect.TypeOf((*MockDataStorage)(nil).SetShare), ctx, share)
+}
+
+// SetSharePayload mocks base method.
+func (m *MockDataStorage) SetSharePayload(ctx context.Context, shareID int64, payload string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetSharePayload", ctx, shareID, payload)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetSharePayload indicates an expected call of SetSharePayload.
+func (mr *MockDataStorageMockRecorder) SetSharePayload(ctx, shareID, payload interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSharePayload", reflect.TypeOf((*MockDataStorage)(nil).SetSharePayload), ctx, shareID, payload)
+}
+
+// SetTextBinaryData mocks base method.
+func (m *MockDataStorage) SetTextBinaryData(ctx context.Context, userID, identifier, entry, meta string, labels modelstorage.Labels, fields string, tokens []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTextBinaryData", ctx, userID, identifier, entry, meta, labels, fields, tokens)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetTextBinaryData indicates an expected call of SetTextBinaryData.
+func (mr *MockDataStorageMockRecorder) SetTextBinaryData(ctx, userID, identifier, entry, meta, labels, fields, tokens interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTextBinaryData", reflect.TypeOf((*MockDataStorage)(nil).SetTextBinaryData), ctx, userID, identifier, entry, meta, labels, fields, tokens)
+}
+
+// SetTrustedContact mocks base method.
+func (m *MockDataStorage) SetTrustedContact(ctx context.Context, ownerID, contactID string, waitDays int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTrustedContact", ctx, ownerID, contactID, waitDays)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetTrustedContact indicates an expected call of SetTrustedContact.
+func (mr *MockDataStorageMockRecorder) SetTrustedContact(ctx, ownerID, contactID, waitDays interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTrustedContact", reflect.TypeOf((*MockDataStorage)(nil).SetTrustedContact), ctx, ownerID, contactID, waitDays)
+}
+
+// SetUserKeys mocks base method.
+func (m *MockDataStorage) SetUserKeys(ctx context.Context, keys modelstorage.UserKeys) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetUserKeys", ctx, keys)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetUserKeys indicates an expected call of SetUserKeys.
+func (mr *MockDataStorageMockRecorder) SetUserKeys(ctx, keys interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserKeys", reflect.TypeOf((*MockDataStorage)(nil).SetUserKeys), ctx, keys)
+}
+
+// TouchSession mocks base method.
+func (m *MockDataStorage) TouchSession(ctx context.Context, sessionID int64, peer string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TouchSession", ctx, sessionID, peer)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TouchSession indicates an expected call of TouchSession.
+func (mr *MockDataStorageMockRecorder) TouchSession(ctx, sessionID, peer interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TouchSession", reflect.TypeOf((*MockDataStorage)(nil).TouchSession), ctx, sessionID, peer)
+}
+
+// UpdateSharedEntry mocks base method.
+func (m *MockDataStorage) UpdateSharedEntry(ctx context.Context, ownerID string, item modelstorage.BatchItem, shareID int64, payload string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSharedEntry", ctx, ownerID, item, shareID, payload)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSharedEntry indicates an expected call of UpdateSharedEntry.
+func (mr *MockDataStorageMockRecorder) UpdateSharedEntry(ctx, ownerID, item, shareID, payload interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTextBinaryData", reflect.TypeOf((*MockDataStorage)(nil).SetTextBinaryData), ctx, userID, identifier, entry, meta)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSharedEntry", reflect.TypeOf((*MockDataStorage)(nil).UpdateSharedEntry), ctx, ownerID, item, shareID, payload)
 }
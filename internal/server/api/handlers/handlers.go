@@ -6,6 +6,7 @@ import (
 	"dk-go-gophkeeper/internal/config"
 	pb "dk-go-gophkeeper/internal/grpc/proto"
 	cipher "dk-go-gophkeeper/internal/server/cipher/v1"
+	"dk-go-gophkeeper/internal/server/modeldto"
 	"dk-go-gophkeeper/internal/server/processor"
 	service "dk-go-gophkeeper/internal/server/processor/v1"
 	"dk-go-gophkeeper/internal/server/storage"
@@ -15,8 +16,10 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 // GophkeeperServer defines attributes and methods of a GophkeeperServer instance.
@@ -35,9 +38,17 @@ func InitServer(cfg *config.Config, storage storage.DataStorage, logger *zerolog
 		return nil, err
 	}
 	gophkeeperService := service.InitService(storage, cipherInstance, logger)
+	gophkeeperService.SetMaxEntryLength(cfg.MaxEntryLength)
+	gophkeeperService.SetSessionTTL(time.Duration(cfg.SessionIdleTTL)*24*time.Hour, time.Duration(cfg.SessionMaxAge)*24*time.Hour)
+	gophkeeperService.SetDBNames(cfg.BankCardDB, cfg.LoginPasswordDB, cfg.TextBinaryDB)
 	return &GophkeeperServer{processor: gophkeeperService, cfg: cfg, logger: logger}, nil
 }
 
+// Processor returns the processor requests are served with, interceptors check roles in collections with it.
+func (s *GophkeeperServer) Processor() processor.Processor {
+	return s.processor
+}
+
 // Register implements server-side register functionality.
 func (s *GophkeeperServer) Register(ctx context.Context, request *pb.LoginRegisterRequest) (*emptypb.Empty, error) {
 	s.logger.Info().Msg("New register request received")
@@ -46,9 +57,13 @@ func (s *GophkeeperServer) Register(ctx context.Context, request *pb.LoginRegist
 	accessToken, err := s.processor.AddNewUser(ctx, request.Login, request.Password)
 	if err != nil {
 		s.logger.Error().Err(err).Msg("New register request failed")
-		return nil, status.Error(codes.Unauthenticated, err.Error())
+		return nil, err
+	}
+	md, err := s.openSession(ctx, accessToken, request)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("New register request failed when opening session")
+		return nil, err
 	}
-	md := metadata.New(map[string]string{"token": accessToken})
 	err = grpc.SendHeader(ctx, md)
 	if err != nil {
 		s.logger.Error().Err(err).Msg("New register request failed when sending headers")
@@ -66,9 +81,14 @@ func (s *GophkeeperServer) Login(ctx context.Context, request *pb.LoginRegisterR
 	defer cancel()
 	accessToken, err := s.processor.LoginUser(ctx, request.Login, request.Password)
 	if err != nil {
-		return nil, status.Error(codes.Unauthenticated, err.Error())
+		s.logger.Error().Err(err).Msg("New login request failed")
+		return nil, err
+	}
+	md, err := s.openSession(ctx, accessToken, request)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("New login request failed when opening session")
+		return nil, err
 	}
-	md := metadata.New(map[string]string{"token": accessToken})
 	err = grpc.SendHeader(ctx, md)
 	if err != nil {
 		s.logger.Error().Err(err).Msg("New login request failed when sending headers")
@@ -82,7 +102,10 @@ func (s *GophkeeperServer) Login(ctx context.Context, request *pb.LoginRegisterR
 // DeleteBankCard performs bank card entry removal from server DB.
 func (s *GophkeeperServer) DeleteBankCard(ctx context.Context, request *pb.DeleteBankCardRequest) (*emptypb.Empty, error) {
 	s.logger.Info().Msg("New DELETE bank card request received")
-	userID := s.getUserID(ctx)
+	userID, err := s.getVaultID(ctx)
+	if err != nil {
+		return nil, err
+	}
 	s.processor.Delete(userID, request.Identifier, s.cfg.BankCardDB)
 	var response emptypb.Empty
 	return &response, nil
@@ -91,7 +114,10 @@ func (s *GophkeeperServer) DeleteBankCard(ctx context.Context, request *pb.Delet
 // DeleteLoginPassword performs login/password entry removal from server DB.
 func (s *GophkeeperServer) DeleteLoginPassword(ctx context.Context, request *pb.DeleteLoginPasswordRequest) (*emptypb.Empty, error) {
 	s.logger.Info().Msg("New DELETE login/password request received")
-	userID := s.getUserID(ctx)
+	userID, err := s.getVaultID(ctx)
+	if err != nil {
+		return nil, err
+	}
 	s.processor.Delete(userID, request.Identifier, s.cfg.LoginPasswordDB)
 	var response emptypb.Empty
 	return &response, nil
@@ -100,7 +126,10 @@ func (s *GophkeeperServer) DeleteLoginPassword(ctx context.Context, request *pb.
 // DeleteTextBinary performs text/binary entry removal from server DB.
 func (s *GophkeeperServer) DeleteTextBinary(ctx context.Context, request *pb.DeleteTextBinaryRequest) (*emptypb.Empty, error) {
 	s.logger.Info().Msg("New DELETE text/binary request received")
-	userID := s.getUserID(ctx)
+	userID, err := s.getVaultID(ctx)
+	if err != nil {
+		return nil, err
+	}
 	s.processor.Delete(userID, request.Identifier, s.cfg.TextBinaryDB)
 	var response emptypb.Empty
 	return &response, nil
@@ -111,8 +140,11 @@ func (s *GophkeeperServer) PostBankCard(ctx context.Context, request *pb.SendBan
 	s.logger.Info().Msg("New POST bank card request received")
 	ctx, cancel := context.WithTimeout(ctx, time.Duration(s.cfg.HandlersTO)*time.Millisecond)
 	defer cancel()
-	userID := s.getUserID(ctx)
-	err := s.processor.SetBankCardData(ctx, userID, request.Identifier, request.Number, request.Holder, request.Cvv, request.Meta)
+	userID, err := s.getVaultID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = s.processor.SetBankCardData(ctx, userID, request.Identifier, request.Number, request.Holder, request.Cvv, request.Expiry, request.Pin, request.Meta, labelsFromProto(request.GetLabels()), fieldsFromProto(request.GetFields()))
 	if err != nil {
 		return nil, err
 	}
@@ -125,8 +157,11 @@ func (s *GophkeeperServer) PostLoginPassword(ctx context.Context, request *pb.Se
 	s.logger.Info().Msg("New POST login/password request received")
 	ctx, cancel := context.WithTimeout(ctx, time.Duration(s.cfg.HandlersTO)*time.Millisecond)
 	defer cancel()
-	userID := s.getUserID(ctx)
-	err := s.processor.SetLoginPasswordData(ctx, userID, request.Identifier, request.Login, request.Password, request.Meta)
+	userID, err := s.getVaultID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = s.processor.SetLoginPasswordData(ctx, userID, request.Identifier, request.Login, request.Password, request.Meta, labelsFromProto(request.GetLabels()), fieldsFromProto(request.GetFields()))
 	if err != nil {
 		return nil, err
 	}
@@ -139,8 +174,11 @@ func (s *GophkeeperServer) PostTextBinary(ctx context.Context, request *pb.SendT
 	s.logger.Info().Msg("New POST text/binary request received")
 	ctx, cancel := context.WithTimeout(ctx, time.Duration(s.cfg.HandlersTO)*time.Millisecond)
 	defer cancel()
-	userID := s.getUserID(ctx)
-	err := s.processor.SetTextBinaryData(ctx, userID, request.Identifier, request.Entry, request.Meta)
+	userID, err := s.getVaultID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = s.processor.SetTextBinaryData(ctx, userID, request.Identifier, request.Entry, request.Meta, labelsFromProto(request.GetLabels()), fieldsFromProto(request.GetFields()))
 	if err != nil {
 		return nil, err
 	}
@@ -148,17 +186,20 @@ func (s *GophkeeperServer) PostTextBinary(ctx context.Context, request *pb.SendT
 	return &response, nil
 }
 
-// GetBankCards performs bank card entries retrieval from server DB.
-func (s *GophkeeperServer) GetBankCards(ctx context.Context, _ *emptypb.Empty) (*pb.GetBankCardsResponse, error) {
+// GetBankCards performs retrieval of a page of bank card entries from server DB.
+func (s *GophkeeperServer) GetBankCards(ctx context.Context, request *pb.PageRequest) (*pb.GetBankCardsResponse, error) {
 	s.logger.Info().Msg("New GET bank cards request received")
 	ctx, cancel := context.WithTimeout(ctx, time.Duration(s.cfg.HandlersTO)*time.Millisecond)
 	defer cancel()
-	userID := s.getUserID(ctx)
-	bankCards, err := s.processor.GetBankCardData(ctx, userID)
+	userID, err := s.getVaultID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	bankCards, nextPageToken, err := s.processor.GetBankCardData(ctx, userID, request.GetPageToken(), int(request.GetPageSize()), filterFromProto(request))
 	if err != nil {
 		return nil, err
 	}
-	bankCardsResponse := pb.GetBankCardsResponse{}
+	bankCardsResponse := pb.GetBankCardsResponse{NextPageToken: nextPageToken}
 	for _, piece := range bankCards {
 		bankCardResponse := pb.ResponsePieceBankCard{
 			Identifier: piece.Identifier,
@@ -166,57 +207,819 @@ func (s *GophkeeperServer) GetBankCards(ctx context.Context, _ *emptypb.Empty) (
 			Holder:     piece.Holder,
 			Cvv:        piece.CVV,
 			Meta:       piece.Meta,
+			Labels:     labelsToProto(piece.Labels),
+			Fields:     fieldsToProto(piece.Fields),
+			Revision:   revisionToProto(piece.Revision, time.Time{}),
+			Expiry:     piece.Expiry,
+			Pin:        piece.PIN,
 		}
 		bankCardsResponse.ResponsePiecesBankCards = append(bankCardsResponse.ResponsePiecesBankCards, &bankCardResponse)
 	}
 	return &bankCardsResponse, nil
 }
 
-// GetLoginsPasswords performs login/password entries retrieval from server DB.
-func (s *GophkeeperServer) GetLoginsPasswords(ctx context.Context, _ *emptypb.Empty) (*pb.GetLoginsPasswordsResponse, error) {
+// GetLoginsPasswords performs retrieval of a page of login/password entries from server DB.
+func (s *GophkeeperServer) GetLoginsPasswords(ctx context.Context, request *pb.PageRequest) (*pb.GetLoginsPasswordsResponse, error) {
 	s.logger.Info().Msg("New GET logins/passwords request received")
 	ctx, cancel := context.WithTimeout(ctx, time.Duration(s.cfg.HandlersTO)*time.Millisecond)
 	defer cancel()
-	userID := s.getUserID(ctx)
-	loginsPasswords, err := s.processor.GetLoginPasswordData(ctx, userID)
+	userID, err := s.getVaultID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	loginsPasswords, nextPageToken, err := s.processor.GetLoginPasswordData(ctx, userID, request.GetPageToken(), int(request.GetPageSize()), filterFromProto(request))
 	if err != nil {
 		return nil, err
 	}
-	loginsPasswordsResponse := pb.GetLoginsPasswordsResponse{}
+	loginsPasswordsResponse := pb.GetLoginsPasswordsResponse{NextPageToken: nextPageToken}
 	for _, piece := range loginsPasswords {
 		loginPasswordResponse := pb.ResponsePieceLoginPassword{
 			Identifier: piece.Identifier,
 			Login:      piece.Login,
 			Password:   piece.Password,
 			Meta:       piece.Meta,
+			Labels:     labelsToProto(piece.Labels),
+			Fields:     fieldsToProto(piece.Fields),
+			Revision:   revisionToProto(piece.Revision, piece.PasswordChangedAt),
 		}
 		loginsPasswordsResponse.ResponsePiecesLoginsPasswords = append(loginsPasswordsResponse.ResponsePiecesLoginsPasswords, &loginPasswordResponse)
 	}
 	return &loginsPasswordsResponse, nil
 }
 
-// GetTextsBinaries performs text/binary entries retrieval from server DB.
-func (s *GophkeeperServer) GetTextsBinaries(ctx context.Context, _ *emptypb.Empty) (*pb.GetTextsBinariesResponse, error) {
+// GetTextsBinaries performs retrieval of a page of text/binary entries from server DB.
+func (s *GophkeeperServer) GetTextsBinaries(ctx context.Context, request *pb.PageRequest) (*pb.GetTextsBinariesResponse, error) {
 	s.logger.Info().Msg("New GET texts/binaries request received")
 	ctx, cancel := context.WithTimeout(ctx, time.Duration(s.cfg.HandlersTO)*time.Millisecond)
 	defer cancel()
-	userID := s.getUserID(ctx)
-	textsBinaries, err := s.processor.GetTextBinaryData(ctx, userID)
+	userID, err := s.getVaultID(ctx)
 	if err != nil {
 		return nil, err
 	}
-	textsBinariesResponse := pb.GetTextsBinariesResponse{}
+	textsBinaries, nextPageToken, err := s.processor.GetTextBinaryData(ctx, userID, request.GetPageToken(), int(request.GetPageSize()), filterFromProto(request))
+	if err != nil {
+		return nil, err
+	}
+	textsBinariesResponse := pb.GetTextsBinariesResponse{NextPageToken: nextPageToken}
 	for _, piece := range textsBinaries {
 		textBinaryResponse := pb.ResponsePieceTextBinary{
 			Identifier: piece.Identifier,
 			Entry:      piece.Entry,
 			Meta:       piece.Meta,
+			Labels:     labelsToProto(piece.Labels),
+			Fields:     fieldsToProto(piece.Fields),
+			Revision:   revisionToProto(piece.Revision, time.Time{}),
 		}
 		textsBinariesResponse.ResponsePiecesTextsBinaries = append(textsBinariesResponse.ResponsePiecesTextsBinaries, &textBinaryResponse)
 	}
 	return &textsBinariesResponse, nil
 }
 
+// StreamBankCards streams all bank card entries from server DB page by page.
+func (s *GophkeeperServer) StreamBankCards(_ *emptypb.Empty, stream pb.Gophkeeper_StreamBankCardsServer) error {
+	s.logger.Info().Msg("New STREAM bank cards request received")
+	userID, err := s.getVaultID(stream.Context())
+	if err != nil {
+		return err
+	}
+	var pageToken string
+	for {
+		ctx, cancel := context.WithTimeout(stream.Context(), time.Duration(s.cfg.HandlersTO)*time.Millisecond)
+		bankCards, nextPageToken, err := s.processor.GetBankCardData(ctx, userID, pageToken, 0, modeldto.Filter{})
+		cancel()
+		if err != nil {
+			return err
+		}
+		for _, piece := range bankCards {
+			err = stream.Send(&pb.ResponsePieceBankCard{
+				Identifier: piece.Identifier,
+				Number:     piece.Number,
+				Holder:     piece.Holder,
+				Cvv:        piece.CVV,
+				Meta:       piece.Meta,
+				Labels:     labelsToProto(piece.Labels),
+				Fields:     fieldsToProto(piece.Fields),
+				Revision:   revisionToProto(piece.Revision, time.Time{}),
+				Expiry:     piece.Expiry,
+				Pin:        piece.PIN,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		if nextPageToken == "" {
+			return nil
+		}
+		pageToken = nextPageToken
+	}
+}
+
+// StreamLoginsPasswords streams all login/password entries from server DB page by page.
+func (s *GophkeeperServer) StreamLoginsPasswords(_ *emptypb.Empty, stream pb.Gophkeeper_StreamLoginsPasswordsServer) error {
+	s.logger.Info().Msg("New STREAM logins/passwords request received")
+	userID, err := s.getVaultID(stream.Context())
+	if err != nil {
+		return err
+	}
+	var pageToken string
+	for {
+		ctx, cancel := context.WithTimeout(stream.Context(), time.Duration(s.cfg.HandlersTO)*time.Millisecond)
+		loginsPasswords, nextPageToken, err := s.processor.GetLoginPasswordData(ctx, userID, pageToken, 0, modeldto.Filter{})
+		cancel()
+		if err != nil {
+			return err
+		}
+		for _, piece := range loginsPasswords {
+			err = stream.Send(&pb.ResponsePieceLoginPassword{
+				Identifier: piece.Identifier,
+				Login:      piece.Login,
+				Password:   piece.Password,
+				Meta:       piece.Meta,
+				Labels:     labelsToProto(piece.Labels),
+				Fields:     fieldsToProto(piece.Fields),
+				Revision:   revisionToProto(piece.Revision, piece.PasswordChangedAt),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		if nextPageToken == "" {
+			return nil
+		}
+		pageToken = nextPageToken
+	}
+}
+
+// StreamTextsBinaries streams all text/binary entries from server DB page by page.
+func (s *GophkeeperServer) StreamTextsBinaries(_ *emptypb.Empty, stream pb.Gophkeeper_StreamTextsBinariesServer) error {
+	s.logger.Info().Msg("New STREAM texts/binaries request received")
+	userID, err := s.getVaultID(stream.Context())
+	if err != nil {
+		return err
+	}
+	var pageToken string
+	for {
+		ctx, cancel := context.WithTimeout(stream.Context(), time.Duration(s.cfg.HandlersTO)*time.Millisecond)
+		textsBinaries, nextPageToken, err := s.processor.GetTextBinaryData(ctx, userID, pageToken, 0, modeldto.Filter{})
+		cancel()
+		if err != nil {
+			return err
+		}
+		for _, piece := range textsBinaries {
+			err = stream.Send(&pb.ResponsePieceTextBinary{
+				Identifier: piece.Identifier,
+				Entry:      piece.Entry,
+				Meta:       piece.Meta,
+				Labels:     labelsToProto(piece.Labels),
+				Fields:     fieldsToProto(piece.Fields),
+				Revision:   revisionToProto(piece.Revision, time.Time{}),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		if nextPageToken == "" {
+			return nil
+		}
+		pageToken = nextPageToken
+	}
+}
+
+// BatchUpsert performs addition or update of a batch of entries of mixed types in server DB.
+func (s *GophkeeperServer) BatchUpsert(ctx context.Context, request *pb.BatchUpsertRequest) (*pb.BatchUpsertResponse, error) {
+	s.logger.Info().Msgf("New BATCH UPSERT request received with %d items", len(request.Items))
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(s.cfg.HandlersTO)*time.Millisecond)
+	defer cancel()
+	userID, err := s.getVaultID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]modeldto.BatchItem, 0, len(request.Items))
+	for _, piece := range request.Items {
+		items = append(items, s.batchItemFromProto(piece))
+	}
+	results, err := s.processor.SetBatchData(ctx, userID, items, request.Overwrite)
+	if err != nil {
+		return nil, err
+	}
+	response := pb.BatchUpsertResponse{}
+	for _, result := range results {
+		resultResponse := pb.BatchItemResult{
+			Index:      uint32(result.Index),
+			Identifier: result.Identifier,
+			Created:    result.Created,
+			Code:       uint32(status.Code(result.Err)),
+		}
+		if result.Err != nil {
+			resultResponse.Error = status.Convert(result.Err).Message()
+		}
+		response.Results = append(response.Results, &resultResponse)
+	}
+	return &response, nil
+}
+
+// SearchEntries performs a search of entries by meta keywords and tags without decrypting entries which do not match.
+func (s *GophkeeperServer) SearchEntries(ctx context.Context, request *pb.SearchEntriesRequest) (*pb.SearchEntriesResponse, error) {
+	s.logger.Info().Msgf("New SEARCH request received with %d keywords and %d tags", len(request.Keywords), len(request.Tags))
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(s.cfg.HandlersTO)*time.Millisecond)
+	defer cancel()
+	userID, err := s.getVaultID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	page, err := s.processor.SearchEntries(ctx, userID, request.Keywords, modeldto.Filter{
+		Folder:        request.Folder,
+		Tags:          request.Tags,
+		FavoritesOnly: request.FavoritesOnly,
+	}, int(request.PageSize), request.PageToken)
+	if err != nil {
+		return nil, err
+	}
+	response := pb.SearchEntriesResponse{NextPageToken: page.NextPageToken}
+	for _, item := range page.Items {
+		response.Items = append(response.Items, s.batchItemToProto(item))
+	}
+	return &response, nil
+}
+
+// SetPublicKey publishes a public key of the key pair kept by a client of the user.
+func (s *GophkeeperServer) SetPublicKey(ctx context.Context, request *pb.PublicKey) (*emptypb.Empty, error) {
+	s.logger.Info().Msg("New SET PUBLIC KEY request received")
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(s.cfg.HandlersTO)*time.Millisecond)
+	defer cancel()
+	userID := s.getUserID(ctx)
+	err := s.processor.SetPublicKey(ctx, userID, request.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// GetPublicKey retrieves a public key published by a registered user or, if no login is given, by the user.
+func (s *GophkeeperServer) GetPublicKey(ctx context.Context, request *pb.GetPublicKeyRequest) (*pb.PublicKey, error) {
+	s.logger.Info().Msg("New GET PUBLIC KEY request received")
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(s.cfg.HandlersTO)*time.Millisecond)
+	defer cancel()
+	userID := s.getUserID(ctx)
+	publicKey, err := s.processor.GetPublicKey(ctx, userID, request.Login)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.PublicKey{PublicKey: publicKey}, nil
+}
+
+// ShareEntry shares an entry of the user with another registered user.
+func (s *GophkeeperServer) ShareEntry(ctx context.Context, request *pb.ShareEntryRequest) (*emptypb.Empty, error) {
+	s.logger.Info().Msg("New SHARE request received")
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(s.cfg.HandlersTO)*time.Millisecond)
+	defer cancel()
+	// members of a collection are granted access by their roles instead
+	if s.getCollectionID(ctx) != "" {
+		return nil, status.Error(codes.FailedPrecondition, "entries of collections cannot be shared")
+	}
+	userID := s.getUserID(ctx)
+	sealed := modeldto.SealedEntry{
+		Payload:     request.Payload,
+		OwnerKey:    request.OwnerKey,
+		WrappedKeys: map[string]string{request.Recipient: request.WrappedKey},
+	}
+	err := s.processor.ShareEntry(ctx, userID, request.Db, request.Identifier, request.Recipient, request.Permission, sealed)
+	if err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// RevokeShare revokes access of another user to an entry of the user.
+func (s *GophkeeperServer) RevokeShare(ctx context.Context, request *pb.RevokeShareRequest) (*emptypb.Empty, error) {
+	s.logger.Info().Msg("New REVOKE SHARE request received")
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(s.cfg.HandlersTO)*time.Millisecond)
+	defer cancel()
+	userID := s.getUserID(ctx)
+	sealed := modeldto.SealedEntry{Payload: request.Payload, OwnerKey: request.OwnerKey, WrappedKeys: make(map[string]string, len(request.RecipientKeys))}
+	for _, recipientKey := range request.RecipientKeys {
+		sealed.WrappedKeys[recipientKey.Login] = recipientKey.WrappedKey
+	}
+	err := s.processor.RevokeShare(ctx, userID, request.Db, request.Identifier, request.Recipient, sealed)
+	if err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// SetSharePayload replaces an entry the user shares with its update sealed by the client of the user.
+func (s *GophkeeperServer) SetSharePayload(ctx context.Context, request *pb.SetSharePayloadRequest) (*emptypb.Empty, error) {
+	s.logger.Info().Msg("New SET SHARE PAYLOAD request received")
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(s.cfg.HandlersTO)*time.Millisecond)
+	defer cancel()
+	userID := s.getUserID(ctx)
+	err := s.processor.SetSharePayload(ctx, userID, request.Db, request.Identifier, request.Payload)
+	if err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// GetShares retrieves entries the user shares with others together with their recipients.
+func (s *GophkeeperServer) GetShares(ctx context.Context, _ *emptypb.Empty) (*pb.GetSharesResponse, error) {
+	s.logger.Info().Msg("New GET SHARES request received")
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(s.cfg.HandlersTO)*time.Millisecond)
+	defer cancel()
+	userID := s.getUserID(ctx)
+	shares, err := s.processor.GetShares(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	response := pb.GetSharesResponse{}
+	for _, share := range shares {
+		piece := pb.Share{Identifier: share.Identifier, Db: share.Db, OwnerKey: share.OwnerKey, ReshareRequired: share.ReshareRequired}
+		if !share.UpdatedAt.IsZero() {
+			piece.UpdatedAt = timestamppb.New(share.UpdatedAt)
+		}
+		for _, recipient := range share.Recipients {
+			piece.Recipients = append(piece.Recipients, &pb.ShareRecipient{Login: recipient.Login, Permission: recipient.Permission, PublicKey: recipient.PublicKey})
+		}
+		response.Shares = append(response.Shares, &piece)
+	}
+	return &response, nil
+}
+
+// GetSharedWithMe retrieves entries other users share with the user.
+func (s *GophkeeperServer) GetSharedWithMe(ctx context.Context, _ *emptypb.Empty) (*pb.GetSharedWithMeResponse, error) {
+	s.logger.Info().Msg("New GET SHARED WITH ME request received")
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(s.cfg.HandlersTO)*time.Millisecond)
+	defer cancel()
+	userID := s.getUserID(ctx)
+	entries, err := s.processor.GetSharedWithMe(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	response := pb.GetSharedWithMeResponse{}
+	for _, entry := range entries {
+		piece := pb.SharedEntry{
+			ShareId:    entry.ID,
+			Owner:      entry.Owner,
+			Permission: entry.Permission,
+			Db:         entry.Db,
+			Payload:    entry.Payload,
+			WrappedKey: entry.WrappedKey,
+		}
+		if !entry.UpdatedAt.IsZero() {
+			piece.UpdatedAt = timestamppb.New(entry.UpdatedAt)
+		}
+		response.Entries = append(response.Entries, &piece)
+	}
+	return &response, nil
+}
+
+// UpdateSharedEntry updates an entry shared with the user with read-write permission.
+func (s *GophkeeperServer) UpdateSharedEntry(ctx context.Context, request *pb.UpdateSharedEntryRequest) (*emptypb.Empty, error) {
+	s.logger.Info().Msgf("New UPDATE SHARED ENTRY request received for share %d", request.ShareId)
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(s.cfg.HandlersTO)*time.Millisecond)
+	defer cancel()
+	userID := s.getUserID(ctx)
+	err := s.processor.UpdateSharedEntry(ctx, userID, request.ShareId, s.batchItemFromProto(request.GetItem()), request.Payload)
+	if err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// CreateCollection creates a collection owned by the user.
+func (s *GophkeeperServer) CreateCollection(ctx context.Context, request *pb.CreateCollectionRequest) (*pb.Collection, error) {
+	s.logger.Info().Msg("New CREATE COLLECTION request received")
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(s.cfg.HandlersTO)*time.Millisecond)
+	defer cancel()
+	userID := s.getUserID(ctx)
+	collection, err := s.processor.CreateCollection(ctx, userID, request.Name)
+	if err != nil {
+		return nil, err
+	}
+	return collectionToProto(collection), nil
+}
+
+// GetCollections retrieves collections the user is a member of.
+func (s *GophkeeperServer) GetCollections(ctx context.Context, _ *emptypb.Empty) (*pb.GetCollectionsResponse, error) {
+	s.logger.Info().Msg("New GET COLLECTIONS request received")
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(s.cfg.HandlersTO)*time.Millisecond)
+	defer cancel()
+	userID := s.getUserID(ctx)
+	collections, err := s.processor.GetCollections(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	response := pb.GetCollectionsResponse{}
+	for _, collection := range collections {
+		response.Collections = append(response.Collections, collectionToProto(collection))
+	}
+	return &response, nil
+}
+
+// DeleteCollection deletes a collection owned by the user along with its entries.
+func (s *GophkeeperServer) DeleteCollection(ctx context.Context, request *pb.CollectionRequest) (*emptypb.Empty, error) {
+	s.logger.Info().Msg("New DELETE COLLECTION request received")
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(s.cfg.HandlersTO)*time.Millisecond)
+	defer cancel()
+	userID := s.getUserID(ctx)
+	err := s.processor.DeleteCollection(ctx, userID, request.CollectionId)
+	if err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// InviteMember invites a registered user to a collection with a role.
+func (s *GophkeeperServer) InviteMember(ctx context.Context, request *pb.InviteMemberRequest) (*emptypb.Empty, error) {
+	s.logger.Info().Msg("New INVITE MEMBER request received")
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(s.cfg.HandlersTO)*time.Millisecond)
+	defer cancel()
+	userID := s.getUserID(ctx)
+	err := s.processor.InviteMember(ctx, userID, request.CollectionId, request.Login, request.Role)
+	if err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// GetInvitations retrieves pending invitations of the user to collections.
+func (s *GophkeeperServer) GetInvitations(ctx context.Context, _ *emptypb.Empty) (*pb.GetInvitationsResponse, error) {
+	s.logger.Info().Msg("New GET INVITATIONS request received")
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(s.cfg.HandlersTO)*time.Millisecond)
+	defer cancel()
+	userID := s.getUserID(ctx)
+	invitations, err := s.processor.GetInvitations(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	response := pb.GetInvitationsResponse{}
+	for _, invitation := range invitations {
+		piece := pb.CollectionInvitation{
+			Id:             invitation.ID,
+			CollectionId:   invitation.CollectionID,
+			CollectionName: invitation.CollectionName,
+			Inviter:        invitation.Inviter,
+			Role:           invitation.Role,
+		}
+		if !invitation.CreatedAt.IsZero() {
+			piece.CreatedAt = timestamppb.New(invitation.CreatedAt)
+		}
+		response.Invitations = append(response.Invitations, &piece)
+	}
+	return &response, nil
+}
+
+// RespondInvitation accepts or declines an invitation of the user to a collection.
+func (s *GophkeeperServer) RespondInvitation(ctx context.Context, request *pb.RespondInvitationRequest) (*emptypb.Empty, error) {
+	s.logger.Info().Msgf("New RESPOND INVITATION request received for invitation %d", request.InvitationId)
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(s.cfg.HandlersTO)*time.Millisecond)
+	defer cancel()
+	userID := s.getUserID(ctx)
+	err := s.processor.RespondInvitation(ctx, userID, request.InvitationId, request.Accept)
+	if err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// GetMembers retrieves members of a collection the user is a member of.
+func (s *GophkeeperServer) GetMembers(ctx context.Context, request *pb.CollectionRequest) (*pb.GetMembersResponse, error) {
+	s.logger.Info().Msg("New GET MEMBERS request received")
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(s.cfg.HandlersTO)*time.Millisecond)
+	defer cancel()
+	userID := s.getUserID(ctx)
+	members, err := s.processor.GetMembers(ctx, userID, request.CollectionId)
+	if err != nil {
+		return nil, err
+	}
+	response := pb.GetMembersResponse{}
+	for _, member := range members {
+		piece := pb.CollectionMember{Login: member.Login, Role: member.Role}
+		if !member.JoinedAt.IsZero() {
+			piece.JoinedAt = timestamppb.New(member.JoinedAt)
+		}
+		response.Members = append(response.Members, &piece)
+	}
+	return &response, nil
+}
+
+// SetMemberRole changes the role of a member of a collection.
+func (s *GophkeeperServer) SetMemberRole(ctx context.Context, request *pb.SetMemberRoleRequest) (*emptypb.Empty, error) {
+	s.logger.Info().Msg("New SET MEMBER ROLE request received")
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(s.cfg.HandlersTO)*time.Millisecond)
+	defer cancel()
+	userID := s.getUserID(ctx)
+	err := s.processor.SetMemberRole(ctx, userID, request.CollectionId, request.Login, request.Role)
+	if err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// RemoveMember removes a member from a collection, the user leaves the collection by removing themselves.
+func (s *GophkeeperServer) RemoveMember(ctx context.Context, request *pb.RemoveMemberRequest) (*emptypb.Empty, error) {
+	s.logger.Info().Msg("New REMOVE MEMBER request received")
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(s.cfg.HandlersTO)*time.Millisecond)
+	defer cancel()
+	userID := s.getUserID(ctx)
+	err := s.processor.RemoveMember(ctx, userID, request.CollectionId, request.Login)
+	if err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// GetCollectionEvents retrieves the audit of membership changes of a collection.
+func (s *GophkeeperServer) GetCollectionEvents(ctx context.Context, request *pb.CollectionRequest) (*pb.GetCollectionEventsResponse, error) {
+	s.logger.Info().Msg("New GET COLLECTION EVENTS request received")
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(s.cfg.HandlersTO)*time.Millisecond)
+	defer cancel()
+	userID := s.getUserID(ctx)
+	events, err := s.processor.GetCollectionEvents(ctx, userID, request.CollectionId)
+	if err != nil {
+		return nil, err
+	}
+	response := pb.GetCollectionEventsResponse{}
+	for _, event := range events {
+		piece := pb.CollectionEvent{Actor: event.Actor, Target: event.Target, Action: event.Action, Role: event.Role}
+		if !event.CreatedAt.IsZero() {
+			piece.CreatedAt = timestamppb.New(event.CreatedAt)
+		}
+		response.Events = append(response.Events, &piece)
+	}
+	return &response, nil
+}
+
+// ListAuditEvents retrieves a page of the audit log of a user, the newest events first.
+func (s *GophkeeperServer) ListAuditEvents(ctx context.Context, request *pb.ListAuditEventsRequest) (*pb.ListAuditEventsResponse, error) {
+	s.logger.Info().Msg("New LIST AUDIT EVENTS request received")
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(s.cfg.HandlersTO)*time.Millisecond)
+	defer cancel()
+	userID := s.getUserID(ctx)
+	events, err := s.processor.ListAuditEvents(ctx, userID, request.BeforeId, int(request.Limit))
+	if err != nil {
+		return nil, err
+	}
+	response := pb.ListAuditEventsResponse{}
+	for _, event := range events {
+		piece := pb.AuditEvent{Id: event.ID, Action: event.Action, Method: event.Method, Peer: event.Peer, Code: event.Code}
+		if !event.CreatedAt.IsZero() {
+			piece.CreatedAt = timestamppb.New(event.CreatedAt)
+		}
+		response.Events = append(response.Events, &piece)
+	}
+	return &response, nil
+}
+
+// ListSessions retrieves devices the user is logged in from.
+func (s *GophkeeperServer) ListSessions(ctx context.Context, _ *emptypb.Empty) (*pb.ListSessionsResponse, error) {
+	s.logger.Info().Msg("New LIST SESSIONS request received")
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(s.cfg.HandlersTO)*time.Millisecond)
+	defer cancel()
+	userID := s.getUserID(ctx)
+	sessions, err := s.processor.ListSessions(ctx, userID, s.getSessionKey(ctx))
+	if err != nil {
+		return nil, err
+	}
+	response := pb.ListSessionsResponse{}
+	for _, session := range sessions {
+		piece := pb.Session{
+			Id:            session.ID,
+			DeviceName:    session.DeviceName,
+			ClientVersion: session.ClientVersion,
+			Peer:          session.Peer,
+			CreatedAt:     timestamppb.New(session.CreatedAt),
+			LastSeenAt:    timestamppb.New(session.LastSeenAt),
+			Current:       session.Current,
+		}
+		response.Sessions = append(response.Sessions, &piece)
+	}
+	return &response, nil
+}
+
+// RevokeSession logs the user out of a device.
+func (s *GophkeeperServer) RevokeSession(ctx context.Context, request *pb.RevokeSessionRequest) (*emptypb.Empty, error) {
+	s.logger.Info().Msg("New REVOKE SESSION request received")
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(s.cfg.HandlersTO)*time.Millisecond)
+	defer cancel()
+	userID := s.getUserID(ctx)
+	err := s.processor.RevokeSession(ctx, userID, request.Id)
+	if err != nil {
+		return nil, err
+	}
+	var response emptypb.Empty
+	return &response, nil
+}
+
+// SetTrustedContact designates a user as a trusted contact who may request emergency access to the vault of the user.
+func (s *GophkeeperServer) SetTrustedContact(ctx context.Context, request *pb.SetTrustedContactRequest) (*emptypb.Empty, error) {
+	s.logger.Info().Msg("New SET TRUSTED CONTACT request received")
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(s.cfg.HandlersTO)*time.Millisecond)
+	defer cancel()
+	userID := s.getUserID(ctx)
+	err := s.processor.SetTrustedContact(ctx, userID, request.Login, int(request.WaitDays))
+	if err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// RemoveTrustedContact removes a trusted contact of the user.
+func (s *GophkeeperServer) RemoveTrustedContact(ctx context.Context, request *pb.EmergencyAccessRequest) (*emptypb.Empty, error) {
+	s.logger.Info().Msg("New REMOVE TRUSTED CONTACT request received")
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(s.cfg.HandlersTO)*time.Millisecond)
+	defer cancel()
+	userID := s.getUserID(ctx)
+	err := s.processor.RemoveTrustedContact(ctx, userID, request.Login)
+	if err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// ListEmergencyAccess lists trusted contacts of the user and owners who designated the user as their trusted contact.
+func (s *GophkeeperServer) ListEmergencyAccess(ctx context.Context, _ *emptypb.Empty) (*pb.ListEmergencyAccessResponse, error) {
+	s.logger.Info().Msg("New LIST EMERGENCY ACCESS request received")
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(s.cfg.HandlersTO)*time.Millisecond)
+	defer cancel()
+	userID := s.getUserID(ctx)
+	contacts, grantors, err := s.processor.ListEmergencyAccess(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	response := pb.ListEmergencyAccessResponse{}
+	for _, contact := range contacts {
+		response.TrustedContacts = append(response.TrustedContacts, emergencyAccessToProto(contact))
+	}
+	for _, grantor := range grantors {
+		response.Grantors = append(response.Grantors, emergencyAccessToProto(grantor))
+	}
+	return &response, nil
+}
+
+// RequestEmergencyAccess requests emergency access to the vault of an owner who designated the user as a trusted
+// contact.
+func (s *GophkeeperServer) RequestEmergencyAccess(ctx context.Context, request *pb.EmergencyAccessRequest) (*emptypb.Empty, error) {
+	s.logger.Info().Msg("New REQUEST EMERGENCY ACCESS request received")
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(s.cfg.HandlersTO)*time.Millisecond)
+	defer cancel()
+	userID := s.getUserID(ctx)
+	err := s.processor.RequestEmergencyAccess(ctx, userID, request.Login)
+	if err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// ApproveEmergencyAccess grants a pending request of a trusted contact of the user at once.
+func (s *GophkeeperServer) ApproveEmergencyAccess(ctx context.Context, request *pb.EmergencyAccessRequest) (*emptypb.Empty, error) {
+	s.logger.Info().Msg("New APPROVE EMERGENCY ACCESS request received")
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(s.cfg.HandlersTO)*time.Millisecond)
+	defer cancel()
+	userID := s.getUserID(ctx)
+	err := s.processor.ApproveEmergencyAccess(ctx, userID, request.Login)
+	if err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// RejectEmergencyAccess rejects a pending request of a trusted contact of the user or revokes access granted to them.
+func (s *GophkeeperServer) RejectEmergencyAccess(ctx context.Context, request *pb.EmergencyAccessRequest) (*emptypb.Empty, error) {
+	s.logger.Info().Msg("New REJECT EMERGENCY ACCESS request received")
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(s.cfg.HandlersTO)*time.Millisecond)
+	defer cancel()
+	userID := s.getUserID(ctx)
+	err := s.processor.RejectEmergencyAccess(ctx, userID, request.Login)
+	if err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// SetEmergencyEntries stores entries of the user sealed for trusted contacts of the user.
+func (s *GophkeeperServer) SetEmergencyEntries(ctx context.Context, request *pb.SetEmergencyEntriesRequest) (*emptypb.Empty, error) {
+	s.logger.Info().Msg("New SET EMERGENCY ENTRIES request received")
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(s.cfg.HandlersTO)*time.Millisecond)
+	defer cancel()
+	userID := s.getUserID(ctx)
+	entries := make([]modeldto.EmergencyEntry, 0, len(request.GetEntries()))
+	for _, piece := range request.GetEntries() {
+		entry := modeldto.EmergencyEntry{Identifier: piece.GetIdentifier(), Db: piece.GetDb(), Payload: piece.GetPayload()}
+		entry.WrappedKeys = make(map[string]string, len(piece.GetWrappedKeys()))
+		for _, key := range piece.GetWrappedKeys() {
+			entry.WrappedKeys[key.GetLogin()] = key.GetWrappedKey()
+		}
+		entries = append(entries, entry)
+	}
+	err := s.processor.SetEmergencyEntries(ctx, userID, entries)
+	if err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// StreamEmergencyEntries streams entries of the user sealed for trusted contacts page by page, payloads are left out.
+func (s *GophkeeperServer) StreamEmergencyEntries(_ *emptypb.Empty, stream pb.Gophkeeper_StreamEmergencyEntriesServer) error {
+	s.logger.Info().Msg("New STREAM emergency entries request received")
+	userID := s.getUserID(stream.Context())
+	var pageToken string
+	for {
+		ctx, cancel := context.WithTimeout(stream.Context(), time.Duration(s.cfg.HandlersTO)*time.Millisecond)
+		entries, nextPageToken, err := s.processor.GetEmergencyEntries(ctx, userID, pageToken, 0)
+		cancel()
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if err = stream.Send(emergencyEntryToProto(entry)); err != nil {
+				return err
+			}
+		}
+		if nextPageToken == "" {
+			return nil
+		}
+		pageToken = nextPageToken
+	}
+}
+
+// StreamEmergencyVault streams entries of an owner who granted emergency access to the user page by page.
+func (s *GophkeeperServer) StreamEmergencyVault(request *pb.EmergencyAccessRequest, stream pb.Gophkeeper_StreamEmergencyVaultServer) error {
+	s.logger.Info().Msg("New STREAM emergency vault request received")
+	userID := s.getUserID(stream.Context())
+	var pageToken string
+	for {
+		ctx, cancel := context.WithTimeout(stream.Context(), time.Duration(s.cfg.HandlersTO)*time.Millisecond)
+		entries, nextPageToken, err := s.processor.GetEmergencyVault(ctx, userID, request.Login, pageToken, 0)
+		cancel()
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if err = stream.Send(emergencyEntryToProto(entry)); err != nil {
+				return err
+			}
+		}
+		if nextPageToken == "" {
+			return nil
+		}
+		pageToken = nextPageToken
+	}
+}
+
+// openSession opens a session of a user logging in from a device and returns the header passing it to the client
+// along with the access token.
+func (s *GophkeeperServer) openSession(ctx context.Context, accessToken string, request *pb.LoginRegisterRequest) (metadata.MD, error) {
+	sessionKey, err := s.processor.OpenSession(ctx, accessToken, modeldto.Session{
+		DeviceName:    request.GetDeviceName(),
+		ClientVersion: request.GetClientVersion(),
+		Peer:          peerAddress(ctx),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return metadata.New(map[string]string{s.cfg.AuthBearerName: accessToken, s.cfg.SessionHeader: sessionKey}), nil
+}
+
+// getSessionKey retrieves a key of the session of a request from request context.
+func (s *GophkeeperServer) getSessionKey(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get(s.cfg.SessionHeader)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// peerAddress retrieves an address of the peer a request came from.
+func peerAddress(ctx context.Context) string {
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		return p.Addr.String()
+	}
+	return ""
+}
+
+// getCollectionID retrieves an ID of the collection a request addresses from request context.
+func (s *GophkeeperServer) getCollectionID(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get(s.cfg.CollectionHeader)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// getVaultID retrieves an ID of the vault a request addresses. Requests address vaults of their users unless they
+// address a collection, whose vault is resolved by the auth interceptor once the role of the user is checked.
+func (s *GophkeeperServer) getVaultID(ctx context.Context) (string, error) {
+	if vaultID, ok := modeldto.VaultID(ctx); ok {
+		return vaultID, nil
+	}
+	if s.getCollectionID(ctx) != "" {
+		return "", status.Error(codes.PermissionDenied, "access to the collection was not authorized")
+	}
+	return s.getUserID(ctx), nil
+}
+
 // getUserID retrieves userID from request context.
 func (s *GophkeeperServer) getUserID(ctx context.Context) string {
 	md, _ := metadata.FromIncomingContext(ctx)
@@ -224,3 +1027,147 @@ func (s *GophkeeperServer) getUserID(ctx context.Context) string {
 	userID := values[0]
 	return userID
 }
+
+// batchItemFromProto converts an entry of a request to an item of the matching DB.
+func (s *GophkeeperServer) batchItemFromProto(piece *pb.BatchItem) modeldto.BatchItem {
+	var item modeldto.BatchItem
+	switch {
+	case piece.GetBankCard() != nil:
+		bankCard := piece.GetBankCard()
+		item.Db = s.cfg.BankCardDB
+		item.BankCard = modeldto.BankCard{Identifier: bankCard.Identifier, Number: bankCard.Number, Holder: bankCard.Holder, CVV: bankCard.Cvv, Expiry: bankCard.Expiry, PIN: bankCard.Pin, Meta: bankCard.Meta, Labels: labelsFromProto(bankCard.GetLabels()), Fields: fieldsFromProto(bankCard.GetFields())}
+	case piece.GetLoginPassword() != nil:
+		loginPassword := piece.GetLoginPassword()
+		item.Db = s.cfg.LoginPasswordDB
+		item.LoginPassword = modeldto.LoginPassword{Identifier: loginPassword.Identifier, Login: loginPassword.Login, Password: loginPassword.Password, Meta: loginPassword.Meta, Labels: labelsFromProto(loginPassword.GetLabels()), Fields: fieldsFromProto(loginPassword.GetFields())}
+	case piece.GetTextBinary() != nil:
+		textBinary := piece.GetTextBinary()
+		item.Db = s.cfg.TextBinaryDB
+		item.TextBinary = modeldto.TextBinary{Identifier: textBinary.Identifier, Entry: textBinary.Entry, Meta: textBinary.Meta, Labels: labelsFromProto(textBinary.GetLabels()), Fields: fieldsFromProto(textBinary.GetFields())}
+	}
+	return item
+}
+
+// batchItemToProto converts an item of any DB to an entry of a response.
+func (s *GophkeeperServer) batchItemToProto(item modeldto.BatchItem) *pb.BatchItem {
+	var piece pb.BatchItem
+	switch item.Db {
+	case s.cfg.BankCardDB:
+		piece.Item = &pb.BatchItem_BankCard{BankCard: &pb.SendBankCardRequest{
+			Identifier: item.BankCard.Identifier,
+			Number:     item.BankCard.Number,
+			Holder:     item.BankCard.Holder,
+			Cvv:        item.BankCard.CVV,
+			Meta:       item.BankCard.Meta,
+			Labels:     labelsToProto(item.BankCard.Labels),
+			Fields:     fieldsToProto(item.BankCard.Fields),
+			Expiry:     item.BankCard.Expiry,
+			Pin:        item.BankCard.PIN,
+		}}
+	case s.cfg.LoginPasswordDB:
+		piece.Item = &pb.BatchItem_LoginPassword{LoginPassword: &pb.SendLoginPasswordRequest{
+			Identifier: item.LoginPassword.Identifier,
+			Login:      item.LoginPassword.Login,
+			Password:   item.LoginPassword.Password,
+			Meta:       item.LoginPassword.Meta,
+			Labels:     labelsToProto(item.LoginPassword.Labels),
+			Fields:     fieldsToProto(item.LoginPassword.Fields),
+		}}
+	case s.cfg.TextBinaryDB:
+		piece.Item = &pb.BatchItem_TextBinary{TextBinary: &pb.SendTextBinaryRequest{
+			Identifier: item.TextBinary.Identifier,
+			Entry:      item.TextBinary.Entry,
+			Meta:       item.TextBinary.Meta,
+			Labels:     labelsToProto(item.TextBinary.Labels),
+			Fields:     fieldsToProto(item.TextBinary.Fields),
+		}}
+	}
+	return &piece
+}
+
+// labelsFromProto converts labels of a request, missing labels are empty.
+func labelsFromProto(labels *pb.Labels) modeldto.Labels {
+	return modeldto.Labels{Folder: labels.GetFolder(), Tags: labels.GetTags(), Favorite: labels.GetFavorite()}
+}
+
+// labelsToProto converts labels of an entry to a response.
+func labelsToProto(labels modeldto.Labels) *pb.Labels {
+	return &pb.Labels{Folder: labels.Folder, Tags: labels.Tags, Favorite: labels.Favorite}
+}
+
+// fieldsFromProto converts custom fields of a request.
+func fieldsFromProto(fields []*pb.CustomField) []modeldto.CustomField {
+	var converted []modeldto.CustomField
+	for _, field := range fields {
+		converted = append(converted, modeldto.CustomField{Name: field.GetName(), Value: field.GetValue(), Concealed: field.GetConcealed()})
+	}
+	return converted
+}
+
+// fieldsToProto converts custom fields of an entry to a response.
+func fieldsToProto(fields []modeldto.CustomField) []*pb.CustomField {
+	var converted []*pb.CustomField
+	for _, field := range fields {
+		converted = append(converted, &pb.CustomField{Name: field.Name, Value: field.Value, Concealed: field.Concealed})
+	}
+	return converted
+}
+
+// revisionToProto converts revision timestamps of an entry to a response, unknown timestamps are omitted.
+func revisionToProto(revision modeldto.Revision, passwordChangedAt time.Time) *pb.Revision {
+	if revision.CreatedAt.IsZero() && revision.UpdatedAt.IsZero() && passwordChangedAt.IsZero() {
+		return nil
+	}
+	converted := &pb.Revision{}
+	if !revision.CreatedAt.IsZero() {
+		converted.CreatedAt = timestamppb.New(revision.CreatedAt)
+	}
+	if !revision.UpdatedAt.IsZero() {
+		converted.UpdatedAt = timestamppb.New(revision.UpdatedAt)
+	}
+	if !passwordChangedAt.IsZero() {
+		converted.PasswordChangedAt = timestamppb.New(passwordChangedAt)
+	}
+	return converted
+}
+
+// collectionToProto converts a collection to its response.
+func collectionToProto(collection modeldto.Collection) *pb.Collection {
+	piece := pb.Collection{Id: collection.ID, Name: collection.Name, Role: collection.Role}
+	if !collection.CreatedAt.IsZero() {
+		piece.CreatedAt = timestamppb.New(collection.CreatedAt)
+	}
+	return &piece
+}
+
+// emergencyEntryToProto converts a sealed emergency entry of a response.
+func emergencyEntryToProto(entry modeldto.EmergencyEntry) *pb.EmergencyEntry {
+	return &pb.EmergencyEntry{
+		Identifier: entry.Identifier,
+		Db:         entry.Db,
+		Payload:    entry.Payload,
+		Contacts:   entry.Contacts,
+		WrappedKey: entry.WrappedKey,
+		SealedAt:   timestamppb.New(entry.SealedAt),
+	}
+}
+
+// emergencyAccessToProto converts emergency access of a trusted contact to its response, unknown times are omitted.
+func emergencyAccessToProto(access modeldto.EmergencyAccess) *pb.EmergencyAccess {
+	piece := pb.EmergencyAccess{Owner: access.Owner, Contact: access.Contact, WaitDays: uint32(access.WaitDays), Status: access.Status}
+	if !access.RequestedAt.IsZero() {
+		piece.RequestedAt = timestamppb.New(access.RequestedAt)
+	}
+	if !access.GrantsAt.IsZero() {
+		piece.GrantsAt = timestamppb.New(access.GrantsAt)
+	}
+	if !access.GrantedAt.IsZero() {
+		piece.GrantedAt = timestamppb.New(access.GrantedAt)
+	}
+	return &piece
+}
+
+// filterFromProto converts a filter of a page request.
+func filterFromProto(request *pb.PageRequest) modeldto.Filter {
+	return modeldto.Filter{Folder: request.GetFolder(), Tags: request.GetTags(), FavoritesOnly: request.GetFavoritesOnly()}
+}
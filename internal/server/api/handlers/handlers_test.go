@@ -7,6 +7,8 @@ import (
 	"dk-go-gophkeeper/internal/mocks"
 	"dk-go-gophkeeper/internal/server/api/interceptors"
 	"dk-go-gophkeeper/internal/server/cipher/v1"
+	"dk-go-gophkeeper/internal/server/modeldto"
+	storageErrors "dk-go-gophkeeper/internal/server/storage/errors"
 	serverStorage "dk-go-gophkeeper/internal/server/storage/modelstorage"
 	"errors"
 	"github.com/golang/mock/gomock"
@@ -15,14 +17,18 @@ import (
 	"github.com/stretchr/testify/suite"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"io"
 	"log"
 	"net"
 	"os"
 	"sync"
 	"testing"
+	"time"
 )
 
 type HandlersTestSuite struct {
@@ -44,7 +50,12 @@ func (suite *HandlersTestSuite) SetupTest() {
 	cfg.ServerAddress = ":8080"
 	cfg.UserKey = "jds__63h3_7ds"
 	cfg.AuthBearerName = "token"
+	cfg.CollectionHeader = "collection"
+	cfg.SessionHeader = "session"
 	cfg.HandlersTO = 500
+	cfg.BankCardDB = "bankCard"
+	cfg.LoginPasswordDB = "loginPassword"
+	cfg.TextBinaryDB = "textBinary"
 	suite.cfg = cfg
 	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
 	suite.ctx, suite.cancel = context.WithCancel(context.Background())
@@ -62,8 +73,12 @@ func (suite *HandlersTestSuite) SetupTest() {
 	if err != nil {
 		log.Fatal(err)
 	}
-	interceptorService := interceptors.NewAuthHandler(cipherInstance, cfg)
-	suite.s = grpc.NewServer(grpc.UnaryInterceptor(interceptorService.UnaryServerInterceptor()))
+	interceptorService := interceptors.NewAuthHandler(cipherInstance, cfg, server.Processor(), server.Processor())
+	errorService := interceptors.NewErrorHandler(&logger)
+	suite.s = grpc.NewServer(
+		grpc.ChainUnaryInterceptor(errorService.UnaryServerInterceptor(), interceptorService.UnaryServerInterceptor()),
+		grpc.ChainStreamInterceptor(errorService.StreamServerInterceptor(), interceptorService.StreamServerInterceptor()),
+	)
 	pb.RegisterGophkeeperServer(suite.s, suite.server)
 	listen, err := net.Listen("tcp", ":8080")
 	if err != nil {
@@ -75,7 +90,10 @@ func (suite *HandlersTestSuite) SetupTest() {
 		_ = suite.s.Serve(listen)
 	}()
 	suite.token = "8773a90a68ebd0fd56dffb1441682414fbec5f454eba9be6129bb00744f50d7f19fd870e97eba101a03b857c675e4836de6f5196"
-	suite.md = metadata.New(map[string]string{suite.cfg.AuthBearerName: suite.token})
+	suite.md = metadata.New(map[string]string{suite.cfg.AuthBearerName: suite.token, suite.cfg.SessionHeader: "session_key"})
+	// requests are made from a session seen just now
+	suite.storage.EXPECT().GetSession(gomock.Any(), gomock.Any(), cipherInstance.SessionDigest("session_key")).Return(serverStorage.Session{ID: 1, LastSeenAt: time.Now()}, nil).AnyTimes()
+	suite.storage.EXPECT().TouchSession(gomock.Any(), int64(1), gomock.Any()).Return(nil).AnyTimes()
 }
 
 func TestHandlersTestSuite(t *testing.T) {
@@ -84,9 +102,17 @@ func TestHandlersTestSuite(t *testing.T) {
 
 func (suite *HandlersTestSuite) TestLoginFail1() {
 	suite.storage.EXPECT().CheckUser(gomock.Any(), gomock.Any(), gomock.Any()).Return("some_user_id", nil)
+	suite.storage.EXPECT().AddSession(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, session serverStorage.Session) (int64, error) {
+		assert.Equal(suite.T(), "some_user_id", session.UserID)
+		assert.Equal(suite.T(), "laptop", session.DeviceName)
+		assert.Equal(suite.T(), "v1.2.0", session.ClientVersion)
+		return 1, nil
+	})
 	request := pb.LoginRegisterRequest{
-		Login:    "some_login",
-		Password: "some_password",
+		Login:         "some_login",
+		Password:      "some_password",
+		DeviceName:    "laptop",
+		ClientVersion: "v1.2.0",
 	}
 	newCtx := metadata.NewIncomingContext(context.Background(), suite.md)
 	_, err := suite.server.Login(newCtx, &request)
@@ -106,14 +132,59 @@ func (suite *HandlersTestSuite) TestLoginFail2() {
 	newCtx := metadata.NewIncomingContext(context.Background(), suite.md)
 	_, err := suite.server.Login(newCtx, &request)
 	e, _ := status.FromError(err)
+	assert.Equal(suite.T(), codes.Internal, e.Code())
+	suite.s.GracefulStop()
+	suite.cancel()
+	suite.wg.Wait()
+}
+
+func (suite *HandlersTestSuite) TestLoginInvalidPassword() {
+	suite.storage.EXPECT().CheckUser(gomock.Any(), gomock.Any(), gomock.Any()).Return("", &storageErrors.InvalidCredentialsError{})
+	request := pb.LoginRegisterRequest{
+		Login:    "some_login",
+		Password: "some_password",
+	}
+	newCtx := metadata.NewIncomingContext(context.Background(), suite.md)
+	_, err := suite.server.Login(newCtx, &request)
+	e, _ := status.FromError(err)
 	assert.Equal(suite.T(), codes.Unauthenticated, e.Code())
 	suite.s.GracefulStop()
 	suite.cancel()
 	suite.wg.Wait()
 }
 
+func (suite *HandlersTestSuite) TestLoginNotFound() {
+	suite.storage.EXPECT().CheckUser(gomock.Any(), gomock.Any(), gomock.Any()).Return("", &storageErrors.NotFoundError{})
+	request := pb.LoginRegisterRequest{
+		Login:    "some_login",
+		Password: "some_password",
+	}
+	newCtx := metadata.NewIncomingContext(context.Background(), suite.md)
+	_, err := suite.server.Login(newCtx, &request)
+	e, _ := status.FromError(err)
+	assert.Equal(suite.T(), codes.NotFound, e.Code())
+	suite.s.GracefulStop()
+	suite.cancel()
+	suite.wg.Wait()
+}
+
+func (suite *HandlersTestSuite) TestRegisterAlreadyExists() {
+	suite.storage.EXPECT().AddNewUser(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(&storageErrors.AlreadyExistsError{ID: "encoded_login"})
+	request := pb.LoginRegisterRequest{
+		Login:    "some_login",
+		Password: "some_password",
+	}
+	newCtx := metadata.NewIncomingContext(context.Background(), suite.md)
+	_, err := suite.server.Register(newCtx, &request)
+	assert.Equal(suite.T(), "rpc error: code = AlreadyExists desc = already exists", err.Error())
+	suite.s.GracefulStop()
+	suite.cancel()
+	suite.wg.Wait()
+}
+
 func (suite *HandlersTestSuite) TestRegisterFail1() {
 	suite.storage.EXPECT().AddNewUser(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	suite.storage.EXPECT().AddSession(gomock.Any(), gomock.Any()).Return(int64(1), nil)
 	request := pb.LoginRegisterRequest{
 		Login:    "some_login",
 		Password: "some_password",
@@ -135,7 +206,7 @@ func (suite *HandlersTestSuite) TestRegisterFail2() {
 	newCtx := metadata.NewIncomingContext(context.Background(), suite.md)
 	_, err := suite.server.Register(newCtx, &request)
 	e, _ := status.FromError(err)
-	assert.Equal(suite.T(), codes.Unauthenticated, e.Code())
+	assert.Equal(suite.T(), codes.Internal, e.Code())
 	suite.s.GracefulStop()
 	suite.cancel()
 	suite.wg.Wait()
@@ -181,12 +252,12 @@ func (suite *HandlersTestSuite) TestDeleteTextBinary() {
 }
 
 func (suite *HandlersTestSuite) TestPostBankCardSuccess() {
-	suite.storage.EXPECT().SetBankCardData(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	suite.storage.EXPECT().SetBankCardData(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
 	request := pb.SendBankCardRequest{
 		Identifier: "1",
-		Number:     "2",
+		Number:     "4111111111111111",
 		Holder:     "3",
-		Cvv:        "4",
+		Cvv:        "123",
 		Meta:       "5",
 	}
 	newCtx := metadata.NewIncomingContext(context.Background(), suite.md)
@@ -197,25 +268,42 @@ func (suite *HandlersTestSuite) TestPostBankCardSuccess() {
 	suite.wg.Wait()
 }
 
+func (suite *HandlersTestSuite) TestPostBankCardInvalid() {
+	request := pb.SendBankCardRequest{
+		Identifier: "1",
+		Number:     "4111111111111112",
+		Holder:     "3",
+		Cvv:        "123",
+		Expiry:     "12/30",
+	}
+	newCtx := metadata.NewIncomingContext(context.Background(), suite.md)
+	_, err := suite.server.PostBankCard(newCtx, &request)
+	assert.Equal(suite.T(), codes.InvalidArgument, status.Code(err))
+	assert.Equal(suite.T(), "card number fails the checksum, check it for typos", status.Convert(err).Message())
+	suite.s.GracefulStop()
+	suite.cancel()
+	suite.wg.Wait()
+}
+
 func (suite *HandlersTestSuite) TestPostBankCardFail() {
-	suite.storage.EXPECT().SetBankCardData(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("generic_error"))
+	suite.storage.EXPECT().SetBankCardData(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("generic_error"))
 	request := pb.SendBankCardRequest{
 		Identifier: "1",
-		Number:     "2",
+		Number:     "4111111111111111",
 		Holder:     "3",
-		Cvv:        "4",
+		Cvv:        "123",
 		Meta:       "5",
 	}
 	newCtx := metadata.NewIncomingContext(context.Background(), suite.md)
 	_, err := suite.server.PostBankCard(newCtx, &request)
-	assert.Equal(suite.T(), "generic_error", err.Error())
+	assert.Equal(suite.T(), "rpc error: code = Internal desc = generic_error", err.Error())
 	suite.s.GracefulStop()
 	suite.cancel()
 	suite.wg.Wait()
 }
 
 func (suite *HandlersTestSuite) TestPostLoginPasswordSuccess() {
-	suite.storage.EXPECT().SetLoginPasswordData(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	suite.storage.EXPECT().SetLoginPasswordData(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
 	request := pb.SendLoginPasswordRequest{
 		Identifier: "1",
 		Login:      "2",
@@ -231,7 +319,7 @@ func (suite *HandlersTestSuite) TestPostLoginPasswordSuccess() {
 }
 
 func (suite *HandlersTestSuite) TestPostLoginPasswordFail() {
-	suite.storage.EXPECT().SetLoginPasswordData(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("generic_error"))
+	suite.storage.EXPECT().SetLoginPasswordData(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("generic_error"))
 	request := pb.SendLoginPasswordRequest{
 		Identifier: "1",
 		Login:      "2",
@@ -240,14 +328,14 @@ func (suite *HandlersTestSuite) TestPostLoginPasswordFail() {
 	}
 	newCtx := metadata.NewIncomingContext(context.Background(), suite.md)
 	_, err := suite.server.PostLoginPassword(newCtx, &request)
-	assert.Equal(suite.T(), "generic_error", err.Error())
+	assert.Equal(suite.T(), "rpc error: code = Internal desc = generic_error", err.Error())
 	suite.s.GracefulStop()
 	suite.cancel()
 	suite.wg.Wait()
 }
 
 func (suite *HandlersTestSuite) TestPostTextBinarySuccess() {
-	suite.storage.EXPECT().SetTextBinaryData(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	suite.storage.EXPECT().SetTextBinaryData(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
 	request := pb.SendTextBinaryRequest{
 		Identifier: "1",
 		Entry:      "2",
@@ -262,7 +350,7 @@ func (suite *HandlersTestSuite) TestPostTextBinarySuccess() {
 }
 
 func (suite *HandlersTestSuite) TestPostTextBinaryFail() {
-	suite.storage.EXPECT().SetTextBinaryData(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("generic_error"))
+	suite.storage.EXPECT().SetTextBinaryData(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("generic_error"))
 	request := pb.SendTextBinaryRequest{
 		Identifier: "1",
 		Entry:      "2",
@@ -270,18 +358,18 @@ func (suite *HandlersTestSuite) TestPostTextBinaryFail() {
 	}
 	newCtx := metadata.NewIncomingContext(context.Background(), suite.md)
 	_, err := suite.server.PostTextBinary(newCtx, &request)
-	assert.Equal(suite.T(), "generic_error", err.Error())
+	assert.Equal(suite.T(), "rpc error: code = Internal desc = generic_error", err.Error())
 	suite.s.GracefulStop()
 	suite.cancel()
 	suite.wg.Wait()
 }
 
 func (suite *HandlersTestSuite) TestGetBankCardsFail() {
-	suite.storage.EXPECT().GetBankCardData(gomock.Any(), gomock.Any()).Return(nil, errors.New("generic_error"))
+	suite.storage.EXPECT().GetBankCardData(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("generic_error"))
 	newCtx := metadata.NewIncomingContext(context.Background(), suite.md)
-	var request *emptypb.Empty
+	var request *pb.PageRequest
 	_, err := suite.server.GetBankCards(newCtx, request)
-	assert.Equal(suite.T(), "generic_error", err.Error())
+	assert.Equal(suite.T(), "rpc error: code = Internal desc = generic_error", err.Error())
 	suite.s.GracefulStop()
 	suite.cancel()
 	suite.wg.Wait()
@@ -297,6 +385,8 @@ func (suite *HandlersTestSuite) TestGetBankCardsSuccess() {
 			Holder:     suite.cipher.Encode("4"),
 			CVV:        suite.cipher.Encode("5"),
 			Meta:       suite.cipher.Encode("6"),
+			Labels:     serverStorage.Labels{Folder: suite.cipher.Encode("Work/Cards"), Tags: suite.cipher.Encode("visa,travel"), Favorite: true},
+			Expiry:     suite.cipher.Encode("09/27"),
 		},
 	}
 	expResp := pb.GetBankCardsResponse{}
@@ -306,11 +396,13 @@ func (suite *HandlersTestSuite) TestGetBankCardsSuccess() {
 		Holder:     "4",
 		Cvv:        "5",
 		Meta:       "6",
+		Labels:     &pb.Labels{Folder: "Work/Cards", Tags: []string{"visa", "travel"}, Favorite: true},
+		Expiry:     "09/27",
 	}
 	expResp.ResponsePiecesBankCards = append(expResp.ResponsePiecesBankCards, &expSubresp)
-	suite.storage.EXPECT().GetBankCardData(gomock.Any(), gomock.Any()).Return(storageData, nil)
+	suite.storage.EXPECT().GetBankCardData(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(storageData, nil)
 	newCtx := metadata.NewIncomingContext(context.Background(), suite.md)
-	var request *emptypb.Empty
+	var request *pb.PageRequest
 	resp, err := suite.server.GetBankCards(newCtx, request)
 	assert.Equal(suite.T(), nil, err)
 	assert.Equal(suite.T(), &expResp, resp)
@@ -319,12 +411,61 @@ func (suite *HandlersTestSuite) TestGetBankCardsSuccess() {
 	suite.wg.Wait()
 }
 
+func (suite *HandlersTestSuite) TestStreamBankCardsSuccess() {
+	storageData := []serverStorage.BankCardStorageEntry{
+		{
+			ID:         1,
+			Identifier: suite.cipher.Encode("1"),
+			UserID:     suite.cipher.Encode("2"),
+			Number:     suite.cipher.Encode("3"),
+			Holder:     suite.cipher.Encode("4"),
+			CVV:        suite.cipher.Encode("5"),
+			Meta:       suite.cipher.Encode("6"),
+		},
+	}
+	suite.storage.EXPECT().GetBankCardData(gomock.Any(), suite.token, int64(0), gomock.Any(), gomock.Len(0)).Return(storageData, nil)
+	conn, err := grpc.Dial(":8080", grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		suite.T().Fatal(err)
+	}
+	defer conn.Close()
+	newCtx := metadata.NewOutgoingContext(context.Background(), suite.md)
+	stream, err := pb.NewGophkeeperClient(conn).StreamBankCards(newCtx, &emptypb.Empty{})
+	assert.Equal(suite.T(), nil, err)
+	piece, err := stream.Recv()
+	assert.Equal(suite.T(), nil, err)
+	assert.Equal(suite.T(), "1", piece.Identifier)
+	assert.Equal(suite.T(), "3", piece.Number)
+	_, err = stream.Recv()
+	assert.Equal(suite.T(), io.EOF, err)
+	suite.s.GracefulStop()
+	suite.cancel()
+	suite.wg.Wait()
+}
+
+func (suite *HandlersTestSuite) TestStreamBankCardsFail() {
+	suite.storage.EXPECT().GetBankCardData(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("generic_error"))
+	conn, err := grpc.Dial(":8080", grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		suite.T().Fatal(err)
+	}
+	defer conn.Close()
+	newCtx := metadata.NewOutgoingContext(context.Background(), suite.md)
+	stream, err := pb.NewGophkeeperClient(conn).StreamBankCards(newCtx, &emptypb.Empty{})
+	assert.Equal(suite.T(), nil, err)
+	_, err = stream.Recv()
+	assert.Equal(suite.T(), "rpc error: code = Internal desc = internal server error", err.Error())
+	suite.s.GracefulStop()
+	suite.cancel()
+	suite.wg.Wait()
+}
+
 func (suite *HandlersTestSuite) TestGetLoginsPasswordsFail() {
-	suite.storage.EXPECT().GetLoginPasswordData(gomock.Any(), gomock.Any()).Return(nil, errors.New("generic_error"))
+	suite.storage.EXPECT().GetLoginPasswordData(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("generic_error"))
 	newCtx := metadata.NewIncomingContext(context.Background(), suite.md)
-	var request *emptypb.Empty
+	var request *pb.PageRequest
 	_, err := suite.server.GetLoginsPasswords(newCtx, request)
-	assert.Equal(suite.T(), "generic_error", err.Error())
+	assert.Equal(suite.T(), "rpc error: code = Internal desc = generic_error", err.Error())
 	suite.s.GracefulStop()
 	suite.cancel()
 	suite.wg.Wait()
@@ -347,11 +488,12 @@ func (suite *HandlersTestSuite) TestGetLoginsPasswordsSuccess() {
 		Login:      "3",
 		Password:   "4",
 		Meta:       "5",
+		Labels:     &pb.Labels{},
 	}
 	expResp.ResponsePiecesLoginsPasswords = append(expResp.GetResponsePiecesLoginsPasswords(), &expSubresp)
-	suite.storage.EXPECT().GetLoginPasswordData(gomock.Any(), gomock.Any()).Return(storageData, nil)
+	suite.storage.EXPECT().GetLoginPasswordData(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(storageData, nil)
 	newCtx := metadata.NewIncomingContext(context.Background(), suite.md)
-	var request *emptypb.Empty
+	var request *pb.PageRequest
 	resp, err := suite.server.GetLoginsPasswords(newCtx, request)
 	assert.Equal(suite.T(), nil, err)
 	assert.Equal(suite.T(), &expResp, resp)
@@ -361,11 +503,11 @@ func (suite *HandlersTestSuite) TestGetLoginsPasswordsSuccess() {
 }
 
 func (suite *HandlersTestSuite) TestGetTextsBinariesFail() {
-	suite.storage.EXPECT().GetTextBinaryData(gomock.Any(), gomock.Any()).Return(nil, errors.New("generic_error"))
+	suite.storage.EXPECT().GetTextBinaryData(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("generic_error"))
 	newCtx := metadata.NewIncomingContext(context.Background(), suite.md)
-	var request *emptypb.Empty
+	var request *pb.PageRequest
 	_, err := suite.server.GetTextsBinaries(newCtx, request)
-	assert.Equal(suite.T(), "generic_error", err.Error())
+	assert.Equal(suite.T(), "rpc error: code = Internal desc = generic_error", err.Error())
 	suite.s.GracefulStop()
 	suite.cancel()
 	suite.wg.Wait()
@@ -386,11 +528,12 @@ func (suite *HandlersTestSuite) TestGetTextsBinariesSuccess() {
 		Identifier: "1",
 		Entry:      "3",
 		Meta:       "4",
+		Labels:     &pb.Labels{},
 	}
 	expResp.ResponsePiecesTextsBinaries = append(expResp.ResponsePiecesTextsBinaries, &expSubresp)
-	suite.storage.EXPECT().GetTextBinaryData(gomock.Any(), gomock.Any()).Return(storageData, nil)
+	suite.storage.EXPECT().GetTextBinaryData(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(storageData, nil)
 	newCtx := metadata.NewIncomingContext(context.Background(), suite.md)
-	var request *emptypb.Empty
+	var request *pb.PageRequest
 	resp, err := suite.server.GetTextsBinaries(newCtx, request)
 	assert.Equal(suite.T(), nil, err)
 	assert.Equal(suite.T(), &expResp, resp)
@@ -398,3 +541,281 @@ func (suite *HandlersTestSuite) TestGetTextsBinariesSuccess() {
 	suite.cancel()
 	suite.wg.Wait()
 }
+
+func (suite *HandlersTestSuite) TestBatchUpsert() {
+	storageOutput := []serverStorage.BatchItemResult{
+		{Db: "bankCard", Identifier: suite.cipher.Encode("1"), Created: true},
+		{Db: "textBinary", Identifier: suite.cipher.Encode("3"), Created: false},
+	}
+	suite.storage.EXPECT().SetBatchData(gomock.Any(), gomock.Any(), gomock.Len(2), true).Return(storageOutput, nil)
+	suite.storage.EXPECT().GetSharesByOwner(gomock.Any(), gomock.Any()).Return(nil, nil)
+	request := pb.BatchUpsertRequest{
+		Overwrite: true,
+		Items: []*pb.BatchItem{
+			{Item: &pb.BatchItem_BankCard{BankCard: &pb.SendBankCardRequest{Identifier: "1", Number: "4111111111111111", Cvv: "123"}}},
+			{Item: &pb.BatchItem_LoginPassword{LoginPassword: &pb.SendLoginPasswordRequest{Identifier: ""}}},
+			{Item: &pb.BatchItem_TextBinary{TextBinary: &pb.SendTextBinaryRequest{Identifier: "3"}}},
+		},
+	}
+	newCtx := metadata.NewIncomingContext(context.Background(), suite.md)
+	response, err := suite.server.BatchUpsert(newCtx, &request)
+	assert.Equal(suite.T(), nil, err)
+	assert.Equal(suite.T(), 3, len(response.Results))
+	assert.Equal(suite.T(), true, response.Results[0].Created)
+	assert.Equal(suite.T(), uint32(codes.OK), response.Results[0].Code)
+	assert.Equal(suite.T(), uint32(codes.InvalidArgument), response.Results[1].Code)
+	assert.Equal(suite.T(), "identifier cannot be empty", response.Results[1].Error)
+	assert.Equal(suite.T(), false, response.Results[2].Created)
+	suite.s.GracefulStop()
+	suite.cancel()
+	suite.wg.Wait()
+}
+
+func (suite *HandlersTestSuite) TestBatchUpsertFail() {
+	suite.storage.EXPECT().SetBatchData(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("generic_error"))
+	request := pb.BatchUpsertRequest{
+		Items: []*pb.BatchItem{{Item: &pb.BatchItem_BankCard{BankCard: &pb.SendBankCardRequest{Identifier: "1", Number: "4111111111111111", Cvv: "123"}}}},
+	}
+	newCtx := metadata.NewIncomingContext(context.Background(), suite.md)
+	_, err := suite.server.BatchUpsert(newCtx, &request)
+	assert.Equal(suite.T(), "rpc error: code = Internal desc = generic_error", err.Error())
+	suite.s.GracefulStop()
+	suite.cancel()
+	suite.wg.Wait()
+}
+
+func (suite *HandlersTestSuite) TestSearchEntries() {
+	storageOutput := serverStorage.SearchPage{
+		Items: []serverStorage.BatchItem{
+			{Db: "loginPassword", LoginPassword: serverStorage.LoginPasswordStorageEntry{
+				Identifier: suite.cipher.Encode("github"),
+				Login:      suite.cipher.Encode("user"),
+				Password:   suite.cipher.Encode("pass"),
+				Meta:       suite.cipher.Encode("#work"),
+			}},
+		},
+		NextCursor: serverStorage.SearchCursor{Db: "loginPassword", Identifier: "encoded_github"},
+	}
+	suite.storage.EXPECT().SearchEntries(gomock.Any(), gomock.Any(), gomock.Len(1), serverStorage.SearchCursor{}, 10).Return(storageOutput, nil)
+	request := pb.SearchEntriesRequest{Tags: []string{"work"}, PageSize: 10}
+	newCtx := metadata.NewIncomingContext(context.Background(), suite.md)
+	response, err := suite.server.SearchEntries(newCtx, &request)
+	assert.Equal(suite.T(), nil, err)
+	assert.Equal(suite.T(), "bG9naW5QYXNzd29yZC9lbmNvZGVkX2dpdGh1Yg", response.NextPageToken)
+	assert.Equal(suite.T(), 1, len(response.Items))
+	assert.Equal(suite.T(), "github", response.Items[0].GetLoginPassword().Identifier)
+	assert.Equal(suite.T(), "pass", response.Items[0].GetLoginPassword().Password)
+	suite.s.GracefulStop()
+	suite.cancel()
+	suite.wg.Wait()
+}
+
+func (suite *HandlersTestSuite) TestSearchEntriesFail() {
+	request := pb.SearchEntriesRequest{}
+	newCtx := metadata.NewIncomingContext(context.Background(), suite.md)
+	_, err := suite.server.SearchEntries(newCtx, &request)
+	assert.Equal(suite.T(), codes.InvalidArgument, status.Code(err))
+	suite.s.GracefulStop()
+	suite.cancel()
+	suite.wg.Wait()
+}
+
+func (suite *HandlersTestSuite) TestGetLoginsPasswordsRevision() {
+	created := time.Date(2023, time.January, 2, 3, 4, 5, 0, time.UTC)
+	changed := created.Add(time.Hour)
+	storageData := []serverStorage.LoginPasswordStorageEntry{
+		{
+			Identifier:        suite.cipher.Encode("1"),
+			Login:             suite.cipher.Encode("3"),
+			Password:          suite.cipher.Encode("4"),
+			Meta:              suite.cipher.Encode("5"),
+			Revision:          serverStorage.Revision{CreatedAt: created, UpdatedAt: changed},
+			PasswordChangedAt: changed,
+		},
+	}
+	suite.storage.EXPECT().GetLoginPasswordData(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(storageData, nil)
+	newCtx := metadata.NewIncomingContext(context.Background(), suite.md)
+	resp, err := suite.server.GetLoginsPasswords(newCtx, &pb.PageRequest{})
+	assert.Equal(suite.T(), nil, err)
+	revision := resp.GetResponsePiecesLoginsPasswords()[0].GetRevision()
+	assert.Equal(suite.T(), created, revision.GetCreatedAt().AsTime())
+	assert.Equal(suite.T(), changed, revision.GetUpdatedAt().AsTime())
+	assert.Equal(suite.T(), changed, revision.GetPasswordChangedAt().AsTime())
+	suite.s.GracefulStop()
+	suite.cancel()
+	suite.wg.Wait()
+}
+
+func (suite *HandlersTestSuite) TestShareEntryNotRegistered() {
+	suite.storage.EXPECT().GetUserIDByLogin(gomock.Any(), suite.cipher.Encode("bob")).Return("", &storageErrors.NotFoundError{})
+	request := pb.ShareEntryRequest{Identifier: "github", Db: "loginPassword", Recipient: "bob", Permission: "read"}
+	newCtx := metadata.NewIncomingContext(context.Background(), suite.md)
+	_, err := suite.server.ShareEntry(newCtx, &request)
+	assert.Equal(suite.T(), "rpc error: code = NotFound desc = user bob is not registered", err.Error())
+	suite.s.GracefulStop()
+	suite.cancel()
+	suite.wg.Wait()
+}
+
+func (suite *HandlersTestSuite) TestGetShares() {
+	updated := time.Date(2023, time.January, 2, 3, 4, 5, 0, time.UTC)
+	storageOutput := []serverStorage.Share{
+		{
+			ID:         1,
+			Db:         "loginPassword",
+			Identifier: suite.cipher.Encode("github"),
+			UpdatedAt:  updated,
+			Recipients: []serverStorage.ShareRecipient{{Login: suite.cipher.Encode("bob"), Permission: "read-write"}},
+		},
+	}
+	suite.storage.EXPECT().GetSharesByOwner(gomock.Any(), gomock.Any()).Return(storageOutput, nil)
+	newCtx := metadata.NewIncomingContext(context.Background(), suite.md)
+	response, err := suite.server.GetShares(newCtx, &emptypb.Empty{})
+	assert.Equal(suite.T(), nil, err)
+	assert.Equal(suite.T(), 1, len(response.Shares))
+	assert.Equal(suite.T(), "github", response.Shares[0].Identifier)
+	assert.Equal(suite.T(), "bob", response.Shares[0].Recipients[0].Login)
+	assert.Equal(suite.T(), "read-write", response.Shares[0].Recipients[0].Permission)
+	assert.Equal(suite.T(), updated, response.Shares[0].UpdatedAt.AsTime())
+	suite.s.GracefulStop()
+	suite.cancel()
+	suite.wg.Wait()
+}
+
+func (suite *HandlersTestSuite) TestUpdateSharedEntryReadOnly() {
+	// recipients are referred to by registered IDs, which access tokens are derived from
+	accountID, err := suite.cipher.ValidateToken(suite.token)
+	assert.Equal(suite.T(), nil, err)
+	suite.storage.EXPECT().GetShareByID(gomock.Any(), int64(1)).Return(serverStorage.Share{
+		ID:         1,
+		Db:         "textBinary",
+		Recipients: []serverStorage.ShareRecipient{{RecipientID: accountID, Permission: "read"}},
+	}, nil)
+	request := pb.UpdateSharedEntryRequest{
+		ShareId: 1,
+		Item:    &pb.BatchItem{Item: &pb.BatchItem_TextBinary{TextBinary: &pb.SendTextBinaryRequest{Identifier: "notes", Entry: "changed"}}},
+	}
+	newCtx := metadata.NewIncomingContext(context.Background(), suite.md)
+	_, err = suite.server.UpdateSharedEntry(newCtx, &request)
+	assert.Equal(suite.T(), codes.PermissionDenied, status.Code(err))
+	suite.s.GracefulStop()
+	suite.cancel()
+	suite.wg.Wait()
+}
+
+func (suite *HandlersTestSuite) TestDeleteTextBinaryInCollection() {
+	accountID, err := suite.cipher.ValidateToken(suite.token)
+	assert.Equal(suite.T(), nil, err)
+	suite.storage.EXPECT().GetMember(gomock.Any(), "team", accountID).Return(serverStorage.CollectionMember{Role: "member"}, nil)
+	suite.storage.EXPECT().SendToQueue(gomock.Any()).Return()
+	request := pb.DeleteTextBinaryRequest{
+		Identifier: "some_id",
+	}
+	md := suite.md.Copy()
+	md.Set(suite.cfg.CollectionHeader, "team")
+	newCtx := metadata.NewIncomingContext(context.Background(), md)
+	// the vault of the collection is resolved by the auth interceptor
+	_, err = suite.server.DeleteTextBinary(newCtx, &request)
+	assert.Equal(suite.T(), codes.PermissionDenied, status.Code(err))
+	authHandler := interceptors.NewAuthHandler(suite.cipher, suite.cfg, suite.server.Processor(), suite.server.Processor())
+	newCtx, err = authHandler.CollectionFunc(newCtx, "/proto.Gophkeeper/DeleteTextBinary")
+	assert.Equal(suite.T(), nil, err)
+	vaultID, _ := modeldto.VaultID(newCtx)
+	assert.Equal(suite.T(), "collection/team", vaultID)
+	_, err = suite.server.DeleteTextBinary(newCtx, &request)
+	assert.Equal(suite.T(), nil, err)
+	suite.s.GracefulStop()
+	suite.cancel()
+	suite.wg.Wait()
+}
+
+func (suite *HandlersTestSuite) TestShareEntryInCollection() {
+	request := pb.ShareEntryRequest{Identifier: "github", Db: "loginPassword", Recipient: "bob", Permission: "read"}
+	md := suite.md.Copy()
+	md.Set(suite.cfg.CollectionHeader, "team")
+	newCtx := metadata.NewIncomingContext(context.Background(), md)
+	_, err := suite.server.ShareEntry(newCtx, &request)
+	assert.Equal(suite.T(), "rpc error: code = FailedPrecondition desc = entries of collections cannot be shared", err.Error())
+	suite.s.GracefulStop()
+	suite.cancel()
+	suite.wg.Wait()
+}
+
+func (suite *HandlersTestSuite) TestListSessionsSuccess() {
+	userID, err := suite.cipher.ValidateToken(suite.token)
+	assert.Equal(suite.T(), nil, err)
+	lastSeenAt := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	suite.storage.EXPECT().GetSessions(gomock.Any(), userID).Return([]serverStorage.Session{
+		{ID: 2, UserID: userID, Digest: suite.cipher.SessionDigest("session_key"), DeviceName: "laptop", Peer: "10.0.0.1", LastSeenAt: lastSeenAt},
+		{ID: 1, UserID: userID, Digest: suite.cipher.SessionDigest("another_key"), DeviceName: "phone", Peer: "10.0.0.2", LastSeenAt: lastSeenAt},
+	}, nil)
+	md := suite.md.Copy()
+	md.Set(suite.cfg.SessionHeader, "session_key")
+	newCtx := metadata.NewIncomingContext(context.Background(), md)
+	response, err := suite.server.ListSessions(newCtx, &emptypb.Empty{})
+	assert.Equal(suite.T(), nil, err)
+	assert.Equal(suite.T(), 2, len(response.Sessions))
+	assert.Equal(suite.T(), "laptop", response.Sessions[0].DeviceName)
+	assert.Equal(suite.T(), true, response.Sessions[0].Current)
+	assert.Equal(suite.T(), false, response.Sessions[1].Current)
+	assert.Equal(suite.T(), lastSeenAt, response.Sessions[1].LastSeenAt.AsTime())
+	suite.s.GracefulStop()
+	suite.cancel()
+	suite.wg.Wait()
+}
+
+func (suite *HandlersTestSuite) TestRevokeSessionNotFound() {
+	userID, err := suite.cipher.ValidateToken(suite.token)
+	assert.Equal(suite.T(), nil, err)
+	suite.storage.EXPECT().DeleteSession(gomock.Any(), userID, int64(7)).Return(&storageErrors.NotFoundError{})
+	newCtx := metadata.NewIncomingContext(context.Background(), suite.md)
+	_, err = suite.server.RevokeSession(newCtx, &pb.RevokeSessionRequest{Id: 7})
+	assert.Equal(suite.T(), "rpc error: code = NotFound desc = session 7 not found", err.Error())
+	suite.s.GracefulStop()
+	suite.cancel()
+	suite.wg.Wait()
+}
+
+func (suite *HandlersTestSuite) TestListEmergencyAccessSuccess() {
+	userID, err := suite.cipher.ValidateToken(suite.token)
+	assert.Equal(suite.T(), nil, err)
+	requestedAt := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	suite.storage.EXPECT().GetTrustedContacts(gomock.Any(), userID).Return([]serverStorage.EmergencyAccess{
+		{ID: 4, OwnerID: userID, OwnerLogin: suite.cipher.Encode("alice"), ContactID: "bob_id", ContactLogin: suite.cipher.Encode("bob"),
+			WaitDays: 7, Status: modeldto.EmergencyRequested, RequestedAt: requestedAt},
+	}, nil)
+	suite.storage.EXPECT().GetEmergencyGrantors(gomock.Any(), userID).Return(nil, nil)
+	newCtx := metadata.NewIncomingContext(context.Background(), suite.md)
+	response, err := suite.server.ListEmergencyAccess(newCtx, &emptypb.Empty{})
+	assert.Equal(suite.T(), nil, err)
+	assert.Equal(suite.T(), 1, len(response.TrustedContacts))
+	assert.Equal(suite.T(), 0, len(response.Grantors))
+	assert.Equal(suite.T(), "bob", response.TrustedContacts[0].Contact)
+	assert.Equal(suite.T(), requestedAt.AddDate(0, 0, 7), response.TrustedContacts[0].GrantsAt.AsTime())
+	assert.Equal(suite.T(), (*timestamppb.Timestamp)(nil), response.TrustedContacts[0].GrantedAt)
+	suite.s.GracefulStop()
+	suite.cancel()
+	suite.wg.Wait()
+}
+
+func (suite *HandlersTestSuite) TestStreamEmergencyVaultNotGranted() {
+	userID, err := suite.cipher.ValidateToken(suite.token)
+	assert.Equal(suite.T(), nil, err)
+	suite.storage.EXPECT().GetUserIDByLogin(gomock.Any(), suite.cipher.Encode("alice")).Return("alice_id", nil)
+	suite.storage.EXPECT().GetEmergencyAccess(gomock.Any(), "alice_id", userID).Return(serverStorage.EmergencyAccess{
+		ID: 4, OwnerID: "alice_id", ContactID: userID, WaitDays: 7, Status: modeldto.EmergencyDesignated,
+	}, nil)
+	conn, err := grpc.Dial(":8080", grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		suite.T().Fatal(err)
+	}
+	defer conn.Close()
+	newCtx := metadata.NewOutgoingContext(context.Background(), suite.md)
+	stream, err := pb.NewGophkeeperClient(conn).StreamEmergencyVault(newCtx, &pb.EmergencyAccessRequest{Login: "alice"})
+	assert.Equal(suite.T(), nil, err)
+	_, err = stream.Recv()
+	assert.Equal(suite.T(), "rpc error: code = PermissionDenied desc = emergency access to the vault of alice is not granted", err.Error())
+	suite.s.GracefulStop()
+	suite.cancel()
+	suite.wg.Wait()
+}
@@ -0,0 +1,128 @@
+package interceptors
+
+import (
+	"context"
+	"dk-go-gophkeeper/internal/config"
+	pb "dk-go-gophkeeper/internal/grpc/proto"
+	"dk-go-gophkeeper/internal/server/modeldto"
+	"path"
+	"time"
+
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Auditor defines a set of methods for types implementing Auditor.
+type Auditor interface {
+	RecordAuditEvent(ctx context.Context, userID string, event modeldto.AuditEvent) error
+	RecordLoginEvent(ctx context.Context, login string, event modeldto.AuditEvent) error
+}
+
+// auditActions maps security-relevant methods to actions they are recorded with, other methods are not audited.
+var auditActions = map[string]string{
+	"/proto.Gophkeeper/Login":                  modeldto.AuditLogin,
+	"/proto.Gophkeeper/Register":               modeldto.AuditRegister,
+	"/proto.Gophkeeper/GetBankCards":           modeldto.ActionRead,
+	"/proto.Gophkeeper/GetLoginsPasswords":     modeldto.ActionRead,
+	"/proto.Gophkeeper/GetTextsBinaries":       modeldto.ActionRead,
+	"/proto.Gophkeeper/StreamBankCards":        modeldto.ActionRead,
+	"/proto.Gophkeeper/StreamLoginsPasswords":  modeldto.ActionRead,
+	"/proto.Gophkeeper/StreamTextsBinaries":    modeldto.ActionRead,
+	"/proto.Gophkeeper/SearchEntries":          modeldto.ActionRead,
+	"/proto.Gophkeeper/GetSharedWithMe":        modeldto.ActionRead,
+	"/proto.Gophkeeper/PostBankCard":           modeldto.ActionWrite,
+	"/proto.Gophkeeper/PostLoginPassword":      modeldto.ActionWrite,
+	"/proto.Gophkeeper/PostTextBinary":         modeldto.ActionWrite,
+	"/proto.Gophkeeper/BatchUpsert":            modeldto.ActionWrite,
+	"/proto.Gophkeeper/UpdateSharedEntry":      modeldto.ActionWrite,
+	"/proto.Gophkeeper/DeleteBankCard":         modeldto.ActionDelete,
+	"/proto.Gophkeeper/DeleteLoginPassword":    modeldto.ActionDelete,
+	"/proto.Gophkeeper/DeleteTextBinary":       modeldto.ActionDelete,
+	"/proto.Gophkeeper/ShareEntry":             modeldto.AuditShare,
+	"/proto.Gophkeeper/RevokeShare":            modeldto.AuditShare,
+	"/proto.Gophkeeper/SetSharePayload":        modeldto.AuditShare,
+	"/proto.Gophkeeper/SetPublicKey":           modeldto.AuditShare,
+	"/proto.Gophkeeper/CreateCollection":       modeldto.AuditCollection,
+	"/proto.Gophkeeper/DeleteCollection":       modeldto.AuditCollection,
+	"/proto.Gophkeeper/InviteMember":           modeldto.AuditCollection,
+	"/proto.Gophkeeper/RespondInvitation":      modeldto.AuditCollection,
+	"/proto.Gophkeeper/SetMemberRole":          modeldto.AuditCollection,
+	"/proto.Gophkeeper/RemoveMember":           modeldto.AuditCollection,
+	"/proto.Gophkeeper/RevokeSession":          modeldto.AuditSession,
+	"/proto.Gophkeeper/SetTrustedContact":      modeldto.AuditEmergency,
+	"/proto.Gophkeeper/RemoveTrustedContact":   modeldto.AuditEmergency,
+	"/proto.Gophkeeper/RequestEmergencyAccess": modeldto.AuditEmergency,
+	"/proto.Gophkeeper/ApproveEmergencyAccess": modeldto.AuditEmergency,
+	"/proto.Gophkeeper/RejectEmergencyAccess":  modeldto.AuditEmergency,
+	"/proto.Gophkeeper/SetEmergencyEntries":    modeldto.AuditEmergency,
+	"/proto.Gophkeeper/StreamEmergencyVault":   modeldto.AuditEmergency,
+}
+
+// AuditHandler defines attributes and methods of an AuditHandler instance.
+type AuditHandler struct {
+	auditor Auditor
+	cfg     *config.Config
+	logger  *zerolog.Logger
+}
+
+// NewAuditHandler initializes AuditHandler instance.
+func NewAuditHandler(auditor Auditor, cfg *config.Config, logger *zerolog.Logger) *AuditHandler {
+	return &AuditHandler{
+		auditor: auditor,
+		cfg:     cfg,
+		logger:  logger,
+	}
+}
+
+// Record appends a finished request to the audit log of its user. Login and register attempts are attributed to the
+// user of their login, other requests to the user of their access token. Requests are served even if they could not be
+// recorded, the failure is logged instead.
+func (a *AuditHandler) Record(ctx context.Context, method string, req interface{}, err error) {
+	action, ok := auditActions[method]
+	if !ok {
+		return
+	}
+	event := modeldto.AuditEvent{Action: action, Method: path.Base(method), Code: status.Code(err).String()}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		event.Peer = p.Addr.String()
+	}
+	// the request context might be done already
+	auditCtx, cancel := context.WithTimeout(context.Background(), time.Duration(a.cfg.HandlersTO)*time.Millisecond)
+	defer cancel()
+	var auditErr error
+	if request, ok := req.(*pb.LoginRegisterRequest); ok {
+		auditErr = a.auditor.RecordLoginEvent(auditCtx, request.GetLogin(), event)
+	} else {
+		md, _ := metadata.FromIncomingContext(ctx)
+		values := md.Get(a.cfg.AuthBearerName)
+		if len(values) == 0 {
+			a.logger.Warn().Str("method", method).Str("peer", event.Peer).Msg("Request without authorization data was not audited")
+			return
+		}
+		auditErr = a.auditor.RecordAuditEvent(auditCtx, values[0], event)
+	}
+	if auditErr != nil {
+		a.logger.Error().Err(auditErr).Str("method", method).Str("peer", event.Peer).Msg("Could not record audit event")
+	}
+}
+
+// UnaryServerInterceptor returns a new unary server interceptor that records security-relevant requests.
+func (a *AuditHandler) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		a.Record(ctx, info.FullMethod, req, err)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor returns a new stream server interceptor that records security-relevant streams.
+func (a *AuditHandler) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		err := handler(srv, ss)
+		a.Record(ss.Context(), info.FullMethod, nil, err)
+		return err
+	}
+}
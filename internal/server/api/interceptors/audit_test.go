@@ -0,0 +1,74 @@
+package interceptors
+
+import (
+	"context"
+	"dk-go-gophkeeper/internal/config"
+	pb "dk-go-gophkeeper/internal/grpc/proto"
+	"dk-go-gophkeeper/internal/mocks"
+	"dk-go-gophkeeper/internal/server/api/handlers"
+	cipher "dk-go-gophkeeper/internal/server/cipher/v1"
+	storageErrors "dk-go-gophkeeper/internal/server/storage/errors"
+	serverStorage "dk-go-gophkeeper/internal/server/storage/modelstorage"
+	"errors"
+	"net"
+	"os"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func TestAuditHandler_UnaryServerInterceptor(t *testing.T) {
+	cfg := config.NewDefaultConfiguration()
+	cfg.UserKey = "jds__63h3_7ds"
+	cfg.AuthBearerName = "token"
+	cfg.HandlersTO = 500
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	storage := mocks.NewMockDataStorage(ctrl)
+	server, err := handlers.InitServer(cfg, storage, &logger)
+	assert.Equal(t, nil, err)
+	cipherInstance, err := cipher.NewCipherService(cfg, &logger)
+	assert.Equal(t, nil, err)
+	interceptor := NewAuditHandler(server.Processor(), cfg, &logger).UnaryServerInterceptor()
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 5000}})
+	failed := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, status.Error(codes.Unauthenticated, "invalid login or password")
+	}
+	succeeded := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "generic_response", nil
+	}
+
+	// failed logins are attributed to users of their logins
+	storage.EXPECT().GetUserIDByLogin(gomock.Any(), cipherInstance.Encode("alice")).Return("alice_id", nil)
+	storage.EXPECT().AddAuditEvent(gomock.Any(), serverStorage.AuditEvent{UserID: "alice_id", Action: "login", Method: "Login", Peer: "10.0.0.1:5000", Code: "Unauthenticated"}).Return(serverStorage.AuditEvent{Seq: 1}, nil)
+	_, err = interceptor(ctx, &pb.LoginRegisterRequest{Login: "alice", Password: "wrong"}, &grpc.UnaryServerInfo{FullMethod: "/proto.Gophkeeper/Login"}, failed)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	// attempts for unregistered logins belong to no one
+	storage.EXPECT().GetUserIDByLogin(gomock.Any(), cipherInstance.Encode("mallory")).Return("", &storageErrors.NotFoundError{})
+	_, err = interceptor(ctx, &pb.LoginRegisterRequest{Login: "mallory", Password: "wrong"}, &grpc.UnaryServerInfo{FullMethod: "/proto.Gophkeeper/Login"}, failed)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	// requests are served even if they could not be recorded
+	token := "8773a90a68ebd0fd56dffb1441682414fbec5f454eba9be6129bb00744f50d7f19fd870e97eba101a03b857c675e4836de6f5196"
+	accountID, err := cipherInstance.ValidateToken(token)
+	assert.Equal(t, nil, err)
+	storage.EXPECT().AddAuditEvent(gomock.Any(), serverStorage.AuditEvent{UserID: accountID, Action: "read", Method: "GetBankCards", Peer: "10.0.0.1:5000", Code: "OK"}).Return(serverStorage.AuditEvent{}, &storageErrors.ExecutionPSQLError{Err: errors.New("generic_error")})
+	tokenCtx := metadata.NewIncomingContext(ctx, metadata.New(map[string]string{cfg.AuthBearerName: token}))
+	resp, err := interceptor(tokenCtx, &pb.PageRequest{}, &grpc.UnaryServerInfo{FullMethod: "/proto.Gophkeeper/GetBankCards"}, succeeded)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "generic_response", resp)
+
+	// listings are not audited
+	resp, err = interceptor(tokenCtx, nil, &grpc.UnaryServerInfo{FullMethod: "/proto.Gophkeeper/ListAuditEvents"}, succeeded)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "generic_response", resp)
+}
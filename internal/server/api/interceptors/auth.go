@@ -5,28 +5,63 @@ import (
 	"context"
 	"dk-go-gophkeeper/internal/config"
 	"dk-go-gophkeeper/internal/server/cipher"
+	"dk-go-gophkeeper/internal/server/modeldto"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
 )
 
+// CollectionAuthorizer defines a set of methods for types implementing CollectionAuthorizer.
+type CollectionAuthorizer interface {
+	CollectionVault(ctx context.Context, userID, collectionID, action string) (string, error)
+}
+
+// SessionChecker defines a set of methods for types implementing SessionChecker.
+type SessionChecker interface {
+	CheckSession(ctx context.Context, userID, sessionKey, peer string) error
+}
+
+// collectionActions maps methods operating on entries to actions they take on entries of a collection.
+var collectionActions = map[string]string{
+	"/proto.Gophkeeper/GetBankCards":          modeldto.ActionRead,
+	"/proto.Gophkeeper/GetLoginsPasswords":    modeldto.ActionRead,
+	"/proto.Gophkeeper/GetTextsBinaries":      modeldto.ActionRead,
+	"/proto.Gophkeeper/StreamBankCards":       modeldto.ActionRead,
+	"/proto.Gophkeeper/StreamLoginsPasswords": modeldto.ActionRead,
+	"/proto.Gophkeeper/StreamTextsBinaries":   modeldto.ActionRead,
+	"/proto.Gophkeeper/SearchEntries":         modeldto.ActionRead,
+	"/proto.Gophkeeper/PostBankCard":          modeldto.ActionWrite,
+	"/proto.Gophkeeper/PostLoginPassword":     modeldto.ActionWrite,
+	"/proto.Gophkeeper/PostTextBinary":        modeldto.ActionWrite,
+	"/proto.Gophkeeper/BatchUpsert":           modeldto.ActionWrite,
+	"/proto.Gophkeeper/DeleteBankCard":        modeldto.ActionDelete,
+	"/proto.Gophkeeper/DeleteLoginPassword":   modeldto.ActionDelete,
+	"/proto.Gophkeeper/DeleteTextBinary":      modeldto.ActionDelete,
+}
+
 // AuthHandler defines attributes and methods of an AuthHandler instance.
 type AuthHandler struct {
-	sec cipher.Cipher
-	cfg *config.Config
+	sec         cipher.Cipher
+	cfg         *config.Config
+	collections CollectionAuthorizer
+	sessions    SessionChecker
 }
 
 // NewAuthHandler initializes AuthHandler instance.
-func NewAuthHandler(sec cipher.Cipher, cfg *config.Config) *AuthHandler {
+func NewAuthHandler(sec cipher.Cipher, cfg *config.Config, collections CollectionAuthorizer, sessions SessionChecker) *AuthHandler {
 	return &AuthHandler{
-		sec: sec,
-		cfg: cfg,
+		sec:         sec,
+		cfg:         cfg,
+		collections: collections,
+		sessions:    sessions,
 	}
 }
 
-// AuthFunc checks request context for metadata and validates metadata-derived authorization token.
+// AuthFunc checks request context for metadata, validates metadata-derived authorization token and rejects sessions
+// revoked by their users.
 func (a *AuthHandler) AuthFunc(ctx context.Context) error {
 	md, ok := metadata.FromIncomingContext(ctx)
 	if !ok {
@@ -40,7 +75,45 @@ func (a *AuthHandler) AuthFunc(ctx context.Context) error {
 	if err != nil {
 		return status.Error(codes.PermissionDenied, err.Error())
 	}
-	return nil
+	var sessionKey, peerAddress string
+	if sessions := md.Get(a.cfg.SessionHeader); len(sessions) != 0 {
+		sessionKey = sessions[0]
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		peerAddress = p.Addr.String()
+	}
+	return a.sessions.CheckSession(ctx, values[0], sessionKey, peerAddress)
+}
+
+// CollectionFunc checks the role of an authenticated user in the collection a request addresses by metadata and
+// returns a context carrying the vault of the collection for handlers. Requests addressing no collection and methods
+// not operating on entries pass as they are.
+func (a *AuthHandler) CollectionFunc(ctx context.Context, method string) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	collections := md.Get(a.cfg.CollectionHeader)
+	if len(collections) == 0 || collections[0] == "" {
+		return ctx, nil
+	}
+	action, ok := collectionActions[method]
+	if !ok {
+		return ctx, nil
+	}
+	vaultID, err := a.collections.CollectionVault(ctx, md.Get(a.cfg.AuthBearerName)[0], collections[0], action)
+	if err != nil {
+		return nil, err
+	}
+	return modeldto.WithVaultID(ctx, vaultID), nil
+}
+
+// vaultStream overrides the context of a server stream with the one carrying the vault the stream addresses.
+type vaultStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context returns the context of the stream.
+func (s *vaultStream) Context() context.Context {
+	return s.ctx
 }
 
 // UnaryServerInterceptor returns a new unary server interceptors that performs per-request authentication.
@@ -56,7 +129,26 @@ func (a *AuthHandler) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
 			if err != nil {
 				return nil, err
 			}
+			ctx, err = a.CollectionFunc(ctx, info.FullMethod)
+			if err != nil {
+				return nil, err
+			}
 			return handler(ctx, req)
 		}
 	}
 }
+
+// StreamServerInterceptor returns a new stream server interceptor that performs per-stream authentication.
+func (a *AuthHandler) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		err := a.AuthFunc(ss.Context())
+		if err != nil {
+			return err
+		}
+		ctx, err := a.CollectionFunc(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &vaultStream{ServerStream: ss, ctx: ctx})
+	}
+}
@@ -7,10 +7,13 @@ import (
 	"dk-go-gophkeeper/internal/mocks"
 	"dk-go-gophkeeper/internal/server/api/handlers"
 	cipher "dk-go-gophkeeper/internal/server/cipher/v1"
+	storageErrors "dk-go-gophkeeper/internal/server/storage/errors"
+	serverStorage "dk-go-gophkeeper/internal/server/storage/modelstorage"
 	"github.com/rs/zerolog"
 	"net"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
@@ -18,6 +21,7 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
 )
@@ -27,16 +31,17 @@ func TestNewAuthHandler(t *testing.T) {
 	cfg.UserKey = "jds__63h3_7ds"
 	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
 	cipherInstance, _ := cipher.NewCipherService(cfg, &logger)
-	_ = NewAuthHandler(cipherInstance, cfg)
+	_ = NewAuthHandler(cipherInstance, cfg, nil, nil)
 }
 
 func TestAuthHandler_AuthFunc_NoMD(t *testing.T) {
 	cfg := config.NewDefaultConfiguration()
 	cfg.UserKey = "jds__63h3_7ds"
 	cfg.AuthBearerName = "token"
+	cfg.SessionHeader = "session"
 	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
 	cipherInstance, _ := cipher.NewCipherService(cfg, &logger)
-	authHandler := NewAuthHandler(cipherInstance, cfg)
+	authHandler := NewAuthHandler(cipherInstance, cfg, nil, nil)
 	ctx := context.Background()
 	err := authHandler.AuthFunc(ctx)
 	if e, ok := status.FromError(err); ok {
@@ -50,23 +55,61 @@ func TestAuthHandler_AuthFunc_CorrectMD(t *testing.T) {
 	cfg := config.NewDefaultConfiguration()
 	cfg.UserKey = "jds__63h3_7ds"
 	cfg.AuthBearerName = "token"
+	cfg.SessionHeader = "session"
 	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
 	cipherInstance, _ := cipher.NewCipherService(cfg, &logger)
-	authHandler := NewAuthHandler(cipherInstance, cfg)
 	token := "8773a90a68ebd0fd56dffb1441682414fbec5f454eba9be6129bb00744f50d7f19fd870e97eba101a03b857c675e4836de6f5196"
-	md := metadata.New(map[string]string{cfg.AuthBearerName: token})
-	ctx := metadata.NewIncomingContext(context.Background(), md)
-	err := authHandler.AuthFunc(ctx)
+	userID, _ := cipherInstance.ValidateToken(token)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	storageInit := mocks.NewMockDataStorage(ctrl)
+	storageInit.EXPECT().GetSession(gomock.Any(), userID, cipherInstance.SessionDigest("session_key")).Return(serverStorage.Session{ID: 1, Peer: "10.0.0.1", LastSeenAt: time.Now()}, nil)
+	server, err := handlers.InitServer(cfg, storageInit, &logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	authHandler := NewAuthHandler(cipherInstance, cfg, server.Processor(), server.Processor())
+	md := metadata.New(map[string]string{cfg.AuthBearerName: token, cfg.SessionHeader: "session_key"})
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 5000}})
+	ctx = metadata.NewIncomingContext(ctx, md)
+	err = authHandler.AuthFunc(ctx)
 	assert.Equal(t, nil, err)
 }
 
+func TestAuthHandler_AuthFunc_RevokedSession(t *testing.T) {
+	cfg := config.NewDefaultConfiguration()
+	cfg.UserKey = "jds__63h3_7ds"
+	cfg.AuthBearerName = "token"
+	cfg.SessionHeader = "session"
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	cipherInstance, _ := cipher.NewCipherService(cfg, &logger)
+	token := "8773a90a68ebd0fd56dffb1441682414fbec5f454eba9be6129bb00744f50d7f19fd870e97eba101a03b857c675e4836de6f5196"
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	storageInit := mocks.NewMockDataStorage(ctrl)
+	storageInit.EXPECT().GetSession(gomock.Any(), gomock.Any(), cipherInstance.SessionDigest("revoked_key")).Return(serverStorage.Session{}, &storageErrors.NotFoundError{})
+	server, err := handlers.InitServer(cfg, storageInit, &logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	authHandler := NewAuthHandler(cipherInstance, cfg, server.Processor(), server.Processor())
+	md := metadata.New(map[string]string{cfg.AuthBearerName: token, cfg.SessionHeader: "revoked_key"})
+	err = authHandler.AuthFunc(metadata.NewIncomingContext(context.Background(), md))
+	assert.Equal(t, "rpc error: code = Unauthenticated desc = session was revoked", err.Error())
+	// the access token alone is not enough
+	md = metadata.New(map[string]string{cfg.AuthBearerName: token})
+	err = authHandler.AuthFunc(metadata.NewIncomingContext(context.Background(), md))
+	assert.Equal(t, "rpc error: code = Unauthenticated desc = session is required, log in again", err.Error())
+}
+
 func TestAuthHandler_AuthFunc_IncorrectMD(t *testing.T) {
 	cfg := config.NewDefaultConfiguration()
 	cfg.UserKey = "jds__63h3_7ds"
 	cfg.AuthBearerName = "token"
+	cfg.SessionHeader = "session"
 	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
 	cipherInstance, _ := cipher.NewCipherService(cfg, &logger)
-	authHandler := NewAuthHandler(cipherInstance, cfg)
+	authHandler := NewAuthHandler(cipherInstance, cfg, nil, nil)
 	token := "some_token"
 	md := metadata.New(map[string]string{cfg.AuthBearerName: token})
 	ctx := metadata.NewIncomingContext(context.Background(), md)
@@ -82,9 +125,10 @@ func TestAuthHandler_AuthFunc_EmptyMD(t *testing.T) {
 	cfg := config.NewDefaultConfiguration()
 	cfg.UserKey = "jds__63h3_7ds"
 	cfg.AuthBearerName = "token"
+	cfg.SessionHeader = "session"
 	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
 	cipherInstance, _ := cipher.NewCipherService(cfg, &logger)
-	authHandler := NewAuthHandler(cipherInstance, cfg)
+	authHandler := NewAuthHandler(cipherInstance, cfg, nil, nil)
 	md := metadata.New(map[string]string{"some_key": "some_token"})
 	ctx := metadata.NewIncomingContext(context.Background(), md)
 	err := authHandler.AuthFunc(ctx)
@@ -99,9 +143,10 @@ func TestAuthHandler_UnaryServerInterceptor_FailDataAccess(t *testing.T) {
 	cfg := config.NewDefaultConfiguration()
 	cfg.UserKey = "jds__63h3_7ds"
 	cfg.AuthBearerName = "token"
+	cfg.SessionHeader = "session"
 	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
 	cipherInstance, _ := cipher.NewCipherService(cfg, &logger)
-	authHandler := NewAuthHandler(cipherInstance, cfg)
+	authHandler := NewAuthHandler(cipherInstance, cfg, nil, nil)
 
 	listen, err := net.Listen("tcp", ":8080")
 	if err != nil {
@@ -133,7 +178,7 @@ func TestAuthHandler_UnaryServerInterceptor_FailDataAccess(t *testing.T) {
 	ctx := context.Background()
 	var header, trailer metadata.MD
 	c := pb.NewGophkeeperClient(conn)
-	var request emptypb.Empty
+	var request pb.PageRequest
 	_, err = c.GetBankCards(ctx, &request, grpc.Header(&header), grpc.Trailer(&trailer))
 	assert.Equal(t, "rpc error: code = Unauthenticated desc = Empty authorization data was found", err.Error())
 	s.GracefulStop()
@@ -143,9 +188,10 @@ func TestAuthHandler_UnaryServerInterceptor_Login(t *testing.T) {
 	cfg := config.NewDefaultConfiguration()
 	cfg.UserKey = "jds__63h3_7ds"
 	cfg.AuthBearerName = "token"
+	cfg.SessionHeader = "session"
 	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
 	cipherInstance, _ := cipher.NewCipherService(cfg, &logger)
-	authHandler := NewAuthHandler(cipherInstance, cfg)
+	authHandler := NewAuthHandler(cipherInstance, cfg, nil, nil)
 
 	listen, err := net.Listen("tcp", ":8080")
 	if err != nil {
@@ -156,6 +202,7 @@ func TestAuthHandler_UnaryServerInterceptor_Login(t *testing.T) {
 	defer ctrl.Finish()
 	storageInit := mocks.NewMockDataStorage(ctrl)
 	storageInit.EXPECT().CheckUser(gomock.Any(), gomock.Any(), gomock.Any()).Return("generic_token", nil)
+	storageInit.EXPECT().AddSession(gomock.Any(), gomock.Any()).Return(int64(1), nil)
 	server, err := handlers.InitServer(cfg, storageInit, &logger)
 	if err != nil {
 		t.Fatal(err)
@@ -181,6 +228,7 @@ func TestAuthHandler_UnaryServerInterceptor_Login(t *testing.T) {
 	var request pb.LoginRegisterRequest
 	_, err = c.Login(ctx, &request, grpc.Header(&header), grpc.Trailer(&trailer))
 	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, len(header.Get(cfg.SessionHeader)))
 	s.GracefulStop()
 }
 
@@ -188,9 +236,10 @@ func TestAuthHandler_UnaryServerInterceptor_Register(t *testing.T) {
 	cfg := config.NewDefaultConfiguration()
 	cfg.UserKey = "jds__63h3_7ds"
 	cfg.AuthBearerName = "token"
+	cfg.SessionHeader = "session"
 	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
 	cipherInstance, _ := cipher.NewCipherService(cfg, &logger)
-	authHandler := NewAuthHandler(cipherInstance, cfg)
+	authHandler := NewAuthHandler(cipherInstance, cfg, nil, nil)
 
 	listen, err := net.Listen("tcp", ":8080")
 	if err != nil {
@@ -201,6 +250,7 @@ func TestAuthHandler_UnaryServerInterceptor_Register(t *testing.T) {
 	defer ctrl.Finish()
 	storageInit := mocks.NewMockDataStorage(ctrl)
 	storageInit.EXPECT().AddNewUser(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	storageInit.EXPECT().AddSession(gomock.Any(), gomock.Any()).Return(int64(1), nil)
 	server, err := handlers.InitServer(cfg, storageInit, &logger)
 	if err != nil {
 		t.Fatal(err)
@@ -228,3 +278,105 @@ func TestAuthHandler_UnaryServerInterceptor_Register(t *testing.T) {
 	assert.Equal(t, nil, err)
 	s.GracefulStop()
 }
+
+func TestAuthHandler_StreamServerInterceptor_FailDataAccess(t *testing.T) {
+	cfg := config.NewDefaultConfiguration()
+	cfg.UserKey = "jds__63h3_7ds"
+	cfg.AuthBearerName = "token"
+	cfg.SessionHeader = "session"
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	cipherInstance, _ := cipher.NewCipherService(cfg, &logger)
+	authHandler := NewAuthHandler(cipherInstance, cfg, nil, nil)
+
+	listen, err := net.Listen("tcp", ":8080")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := grpc.NewServer(grpc.StreamInterceptor(authHandler.StreamServerInterceptor()))
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	storageInit := mocks.NewMockDataStorage(ctrl)
+	server, err := handlers.InitServer(cfg, storageInit, &logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pb.RegisterGophkeeperServer(s, server)
+	go func(t *testing.T) {
+		err1 := s.Serve(listen)
+		if err1 != nil {
+			t.Error(err1)
+		}
+	}(t)
+
+	conn, err := grpc.Dial(":8080", grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	// send a request
+	ctx := context.Background()
+	var header, trailer metadata.MD
+	c := pb.NewGophkeeperClient(conn)
+	var request emptypb.Empty
+	stream, err := c.StreamBankCards(ctx, &request, grpc.Header(&header), grpc.Trailer(&trailer))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = stream.Recv()
+	assert.Equal(t, "rpc error: code = Unauthenticated desc = Empty authorization data was found", err.Error())
+	s.GracefulStop()
+}
+
+func TestAuthHandler_UnaryServerInterceptor_CollectionRole(t *testing.T) {
+	cfg := config.NewDefaultConfiguration()
+	cfg.UserKey = "jds__63h3_7ds"
+	cfg.AuthBearerName = "token"
+	cfg.SessionHeader = "session"
+	cfg.CollectionHeader = "collection"
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	cipherInstance, _ := cipher.NewCipherService(cfg, &logger)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	storageInit := mocks.NewMockDataStorage(ctrl)
+	// read-only members may read entries of the collection but not change them, roles are checked once per request
+	storageInit.EXPECT().GetMember(gomock.Any(), "some_collection", gomock.Any()).Return(serverStorage.CollectionMember{Role: "read-only"}, nil).Times(2)
+	storageInit.EXPECT().GetBankCardData(gomock.Any(), "collection/some_collection", gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil)
+	storageInit.EXPECT().GetSession(gomock.Any(), gomock.Any(), cipherInstance.SessionDigest("session_key")).Return(serverStorage.Session{ID: 1}, nil).Times(2)
+	storageInit.EXPECT().TouchSession(gomock.Any(), int64(1), gomock.Any()).Return(nil).Times(2)
+	server, err := handlers.InitServer(cfg, storageInit, &logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	authHandler := NewAuthHandler(cipherInstance, cfg, server.Processor(), server.Processor())
+
+	listen, err := net.Listen("tcp", ":8080")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := grpc.NewServer(grpc.UnaryInterceptor(authHandler.UnaryServerInterceptor()))
+	pb.RegisterGophkeeperServer(s, server)
+	go func(t *testing.T) {
+		err1 := s.Serve(listen)
+		if err1 != nil {
+			t.Error(err1)
+		}
+	}(t)
+
+	conn, err := grpc.Dial(":8080", grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	// send requests addressing the collection
+	token := "8773a90a68ebd0fd56dffb1441682414fbec5f454eba9be6129bb00744f50d7f19fd870e97eba101a03b857c675e4836de6f5196"
+	md := metadata.New(map[string]string{cfg.AuthBearerName: token, cfg.SessionHeader: "session_key", cfg.CollectionHeader: "some_collection"})
+	ctx := metadata.NewOutgoingContext(context.Background(), md)
+	c := pb.NewGophkeeperClient(conn)
+	_, err = c.PostBankCard(ctx, &pb.SendBankCardRequest{Identifier: "card"})
+	assert.Equal(t, "rpc error: code = PermissionDenied desc = role member is required in collection some_collection", err.Error())
+	_, err = c.GetBankCards(ctx, &pb.PageRequest{})
+	assert.Equal(t, nil, err)
+	s.GracefulStop()
+}
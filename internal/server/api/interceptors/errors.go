@@ -0,0 +1,59 @@
+package interceptors
+
+import (
+	"context"
+	storageErrors "dk-go-gophkeeper/internal/server/storage/errors"
+
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// InternalErrorMessage is the only message clients get about internal errors, their details are logged instead.
+const InternalErrorMessage = "internal server error"
+
+// ErrorHandler defines attributes and methods of an ErrorHandler instance.
+type ErrorHandler struct {
+	logger *zerolog.Logger
+}
+
+// NewErrorHandler initializes ErrorHandler instance.
+func NewErrorHandler(logger *zerolog.Logger) *ErrorHandler {
+	return &ErrorHandler{
+		logger: logger,
+	}
+}
+
+// Translate converts an error returned by a handler into a status: storage errors get the matching codes, internal
+// errors are logged and their details are hidden from clients.
+func (e *ErrorHandler) Translate(method string, err error) error {
+	if err == nil {
+		return nil
+	}
+	st := status.Convert(storageErrors.ToStatus(err))
+	switch st.Code() {
+	case codes.Internal, codes.Unknown, codes.DataLoss:
+		e.logger.Error().Err(err).Str("method", method).Msg("Request failed with an internal error")
+		return status.Error(codes.Internal, InternalErrorMessage)
+	}
+	return st.Err()
+}
+
+// UnaryServerInterceptor returns a new unary server interceptor that translates errors of requests.
+func (e *ErrorHandler) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return nil, e.Translate(info.FullMethod, err)
+		}
+		return resp, nil
+	}
+}
+
+// StreamServerInterceptor returns a new stream server interceptor that translates errors of streams.
+func (e *ErrorHandler) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return e.Translate(info.FullMethod, handler(srv, ss))
+	}
+}
@@ -0,0 +1,56 @@
+package interceptors
+
+import (
+	"context"
+	storageErrors "dk-go-gophkeeper/internal/server/storage/errors"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestErrorHandler_Translate(t *testing.T) {
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	errorHandler := NewErrorHandler(&logger)
+	tests := []struct {
+		err     error
+		code    codes.Code
+		message string
+	}{
+		{err: &storageErrors.AlreadyExistsError{ID: "encoded_login"}, code: codes.AlreadyExists, message: "already exists"},
+		{err: &storageErrors.NotFoundError{}, code: codes.NotFound, message: "not found"},
+		{err: &storageErrors.InvalidCredentialsError{}, code: codes.Unauthenticated, message: "invalid login or password"},
+		{err: &storageErrors.ContextTimeoutExceededError{Err: context.DeadlineExceeded}, code: codes.DeadlineExceeded, message: "request timed out"},
+		{err: &storageErrors.ExecutionPSQLError{Err: errors.New("generic_error")}, code: codes.Internal, message: InternalErrorMessage},
+		{err: status.Error(codes.Internal, "generic_error"), code: codes.Internal, message: InternalErrorMessage},
+		{err: errors.New("generic_error"), code: codes.Internal, message: InternalErrorMessage},
+		{err: status.Error(codes.InvalidArgument, "generic_error"), code: codes.InvalidArgument, message: "generic_error"},
+	}
+	for _, tt := range tests {
+		st := status.Convert(errorHandler.Translate("/proto.Gophkeeper/Register", tt.err))
+		assert.Equal(t, tt.code, st.Code())
+		assert.Equal(t, tt.message, st.Message())
+	}
+	assert.Equal(t, nil, errorHandler.Translate("/proto.Gophkeeper/Register", nil))
+}
+
+func TestErrorHandler_UnaryServerInterceptor(t *testing.T) {
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	interceptor := NewErrorHandler(&logger).UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/proto.Gophkeeper/Login"}
+	resp, err := interceptor(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "generic_response", nil
+	})
+	assert.Equal(t, "generic_response", resp)
+	assert.Equal(t, nil, err)
+	resp, err = interceptor(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, &storageErrors.InvalidCredentialsError{}
+	})
+	assert.Equal(t, nil, resp)
+	assert.Equal(t, "rpc error: code = Unauthenticated desc = invalid login or password", err.Error())
+}
@@ -0,0 +1,158 @@
+// Package auditchain provides tamper evidence of audit logs.
+//
+// Events of every user form a chain: each event is numbered from one and carries the hash of the previous event of
+// the user, so that editing, removing or reordering events breaks the chain from that event on. Signed checkpoints
+// periodically pin the hash of an event, so that the chain cannot be rebuilt without the signing key and removing its
+// newest events is noticed as well.
+package auditchain
+
+import (
+	"crypto/sha256"
+	"dk-go-gophkeeper/internal/server/storage/modelstorage"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Hash returns the hash of an event chained to the hash of the previous event of its user, the previous hash of the
+// first event is empty. Times are hashed with the microsecond precision they are stored with.
+func Hash(prevHash string, event modelstorage.AuditEvent) string {
+	record := fmt.Sprintf("%s\n%q\n%d\n%q\n%q\n%q\n%q\n%s", prevHash, event.UserID, event.Seq, event.Action, event.Method,
+		event.Peer, event.Code, event.CreatedAt.UTC().Truncate(time.Microsecond).Format(time.RFC3339Nano))
+	sum := sha256.Sum256([]byte(record))
+	return hex.EncodeToString(sum[:])
+}
+
+// CheckpointMessage returns the message a checkpoint is signed over.
+func CheckpointMessage(checkpoint modelstorage.AuditCheckpoint) string {
+	return fmt.Sprintf("%q\n%d\n%s", checkpoint.UserID, checkpoint.Seq, checkpoint.Hash)
+}
+
+// BrokenLinkError reports an event of a user the audit chain breaks at.
+type BrokenLinkError struct {
+	UserID string
+	Seq    int64
+	ID     int64
+	Reason string
+}
+
+// Error converts BrokenLinkError to a string.
+func (e *BrokenLinkError) Error() string {
+	return fmt.Sprintf("audit chain of user %s breaks at event %d (id %d): %s", e.UserID, e.Seq, e.ID, e.Reason)
+}
+
+// Verifier defines attributes and methods of a Verifier instance.
+type Verifier struct {
+	checkpoints map[string]map[int64]modelstorage.AuditCheckpoint
+	verify      func(message, signature string) bool
+	last        *modelstorage.AuditEvent
+	Events      int
+	Users       int
+}
+
+// NewVerifier initializes a Verifier instance checking chains against checkpoints, verify checks a signature of a
+// checkpoint message.
+func NewVerifier(checkpoints []modelstorage.AuditCheckpoint, verify func(message, signature string) bool) *Verifier {
+	byUser := make(map[string]map[int64]modelstorage.AuditCheckpoint)
+	for _, checkpoint := range checkpoints {
+		if byUser[checkpoint.UserID] == nil {
+			byUser[checkpoint.UserID] = make(map[int64]modelstorage.AuditCheckpoint)
+		}
+		byUser[checkpoint.UserID][checkpoint.Seq] = checkpoint
+	}
+	return &Verifier{checkpoints: byUser, verify: verify}
+}
+
+// Next verifies the next event, events must be ordered by user and then by their numbers. A *BrokenLinkError is
+// returned for the first event the chain of its user breaks at.
+func (v *Verifier) Next(event modelstorage.AuditEvent) error {
+	var prevHash string
+	expectedSeq := int64(1)
+	if v.last != nil && v.last.UserID == event.UserID {
+		prevHash = v.last.Hash
+		expectedSeq = v.last.Seq + 1
+	} else {
+		if err := v.finishUser(); err != nil {
+			return err
+		}
+		v.Users++
+	}
+	switch {
+	case event.Seq != expectedSeq:
+		return &BrokenLinkError{UserID: event.UserID, Seq: event.Seq, ID: event.ID, Reason: fmt.Sprintf("expected event %d", expectedSeq)}
+	case event.PrevHash != prevHash:
+		return &BrokenLinkError{UserID: event.UserID, Seq: event.Seq, ID: event.ID, Reason: "previous hash does not match"}
+	case Hash(prevHash, event) != event.Hash:
+		return &BrokenLinkError{UserID: event.UserID, Seq: event.Seq, ID: event.ID, Reason: "event was modified"}
+	}
+	if checkpoint, ok := v.checkpoints[event.UserID][event.Seq]; ok {
+		if err := v.checkCheckpoint(checkpoint); err != nil {
+			return err
+		}
+		if checkpoint.Hash != event.Hash {
+			return &BrokenLinkError{UserID: event.UserID, Seq: event.Seq, ID: event.ID, Reason: "hash does not match the checkpoint"}
+		}
+		delete(v.checkpoints[event.UserID], event.Seq)
+	}
+	v.last = &event
+	v.Events++
+	return nil
+}
+
+// Finish verifies that no checkpointed events are missing once all events were passed to Next.
+func (v *Verifier) Finish() error {
+	if err := v.finishUser(); err != nil {
+		return err
+	}
+	// users whose events are gone altogether
+	userIDs := make([]string, 0, len(v.checkpoints))
+	for userID := range v.checkpoints {
+		userIDs = append(userIDs, userID)
+	}
+	sort.Strings(userIDs)
+	for _, userID := range userIDs {
+		if checkpoint, ok := v.firstCheckpoint(userID); ok {
+			if err := v.checkCheckpoint(checkpoint); err != nil {
+				return err
+			}
+			return &BrokenLinkError{UserID: userID, Seq: 1, Reason: fmt.Sprintf("events up to checkpointed event %d are missing", checkpoint.Seq)}
+		}
+	}
+	return nil
+}
+
+// finishUser verifies that no checkpointed events of the last user are missing.
+func (v *Verifier) finishUser() error {
+	if v.last == nil {
+		return nil
+	}
+	if checkpoint, ok := v.firstCheckpoint(v.last.UserID); ok {
+		if err := v.checkCheckpoint(checkpoint); err != nil {
+			return err
+		}
+		return &BrokenLinkError{UserID: v.last.UserID, Seq: v.last.Seq + 1, Reason: fmt.Sprintf("events up to checkpointed event %d are missing", checkpoint.Seq)}
+	}
+	delete(v.checkpoints, v.last.UserID)
+	return nil
+}
+
+// firstCheckpoint returns the earliest checkpoint of a user not matched with an event yet.
+func (v *Verifier) firstCheckpoint(userID string) (modelstorage.AuditCheckpoint, bool) {
+	var first modelstorage.AuditCheckpoint
+	found := false
+	for seq, checkpoint := range v.checkpoints[userID] {
+		if !found || seq < first.Seq {
+			first, found = checkpoint, true
+		}
+	}
+	return first, found
+}
+
+// checkCheckpoint verifies the signature of a checkpoint.
+func (v *Verifier) checkCheckpoint(checkpoint modelstorage.AuditCheckpoint) error {
+	if !v.verify(CheckpointMessage(checkpoint), checkpoint.Signature) {
+		return &BrokenLinkError{UserID: checkpoint.UserID, Seq: checkpoint.Seq, Reason: fmt.Sprintf("checkpoint %d has an invalid signature", checkpoint.ID)}
+	}
+	return nil
+}
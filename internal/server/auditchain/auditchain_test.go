@@ -0,0 +1,160 @@
+package auditchain
+
+import (
+	"dk-go-gophkeeper/internal/server/storage/modelstorage"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// sign is a stand-in for a keyed signature of checkpoint messages.
+func sign(message string) string {
+	return "signed:" + message
+}
+
+func verify(message, signature string) bool {
+	return signature == sign(message)
+}
+
+// newChain builds a valid chain of events of a user.
+func newChain(userID string, length int) []modelstorage.AuditEvent {
+	var events []modelstorage.AuditEvent
+	var prevHash string
+	createdAt := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	for i := 1; i <= length; i++ {
+		event := modelstorage.AuditEvent{
+			ID:        int64(i),
+			UserID:    userID,
+			Seq:       int64(i),
+			Action:    "read",
+			Method:    "GetBankCards",
+			Peer:      "10.0.0.1:5000",
+			Code:      "OK",
+			CreatedAt: createdAt.Add(time.Duration(i) * time.Second),
+			PrevHash:  prevHash,
+		}
+		event.Hash = Hash(prevHash, event)
+		prevHash = event.Hash
+		events = append(events, event)
+	}
+	return events
+}
+
+func newCheckpoint(event modelstorage.AuditEvent) modelstorage.AuditCheckpoint {
+	checkpoint := modelstorage.AuditCheckpoint{UserID: event.UserID, Seq: event.Seq, Hash: event.Hash}
+	checkpoint.Signature = sign(CheckpointMessage(checkpoint))
+	return checkpoint
+}
+
+// walk passes events to a verifier and returns the first broken link.
+func walk(events []modelstorage.AuditEvent, checkpoints []modelstorage.AuditCheckpoint) *BrokenLinkError {
+	verifier := NewVerifier(checkpoints, verify)
+	var err error
+	for _, event := range events {
+		if err = verifier.Next(event); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = verifier.Finish()
+	}
+	var brokenLink *BrokenLinkError
+	if errors.As(err, &brokenLink) {
+		return brokenLink
+	}
+	return nil
+}
+
+func TestHash(t *testing.T) {
+	event := newChain("alice_id", 1)[0]
+	assert.Equal(t, 64, len(event.Hash))
+	// times are hashed in UTC with the stored precision
+	event.CreatedAt = event.CreatedAt.In(time.FixedZone("UTC+3", 3*60*60)).Add(time.Nanosecond)
+	assert.Equal(t, event.Hash, Hash("", event))
+	event.Peer = "10.0.0.2:5000"
+	assert.NotEqual(t, event.Hash, Hash("", event))
+	assert.NotEqual(t, Hash("", event), Hash("previous", event))
+}
+
+func TestVerifier(t *testing.T) {
+	alice := newChain("alice_id", 5)
+	bob := newChain("bob_id", 3)
+	checkpoints := []modelstorage.AuditCheckpoint{newCheckpoint(alice[1]), newCheckpoint(alice[3]), newCheckpoint(bob[2])}
+
+	tt := []struct {
+		name        string
+		events      func() []modelstorage.AuditEvent
+		checkpoints func() []modelstorage.AuditCheckpoint
+		brokenLink  *BrokenLinkError
+	}{
+		{
+			name:   "Intact chains",
+			events: func() []modelstorage.AuditEvent { return append(append([]modelstorage.AuditEvent{}, alice...), bob...) },
+		},
+		{
+			name: "Modified event",
+			events: func() []modelstorage.AuditEvent {
+				events := append(append([]modelstorage.AuditEvent{}, alice...), bob...)
+				events[2].Code = "Unauthenticated"
+				return events
+			},
+			brokenLink: &BrokenLinkError{UserID: "alice_id", Seq: 3, ID: 3, Reason: "event was modified"},
+		},
+		{
+			name: "Removed event",
+			events: func() []modelstorage.AuditEvent {
+				return append(append(append([]modelstorage.AuditEvent{}, alice[:2]...), alice[3:]...), bob...)
+			},
+			brokenLink: &BrokenLinkError{UserID: "alice_id", Seq: 4, ID: 4, Reason: "expected event 3"},
+		},
+		{
+			name: "Rehashed chain",
+			events: func() []modelstorage.AuditEvent {
+				events := append(append([]modelstorage.AuditEvent{}, alice...), bob...)
+				events[0].Peer = "10.0.0.2:5000"
+				prevHash := ""
+				for i := range alice {
+					events[i].PrevHash = prevHash
+					events[i].Hash = Hash(prevHash, events[i])
+					prevHash = events[i].Hash
+				}
+				return events
+			},
+			brokenLink: &BrokenLinkError{UserID: "alice_id", Seq: 2, ID: 2, Reason: "hash does not match the checkpoint"},
+		},
+		{
+			name: "Removed newest events",
+			events: func() []modelstorage.AuditEvent {
+				return append(append([]modelstorage.AuditEvent{}, alice[:3]...), bob...)
+			},
+			brokenLink: &BrokenLinkError{UserID: "alice_id", Seq: 4, Reason: "events up to checkpointed event 4 are missing"},
+		},
+		{
+			name:       "Removed chain",
+			events:     func() []modelstorage.AuditEvent { return alice },
+			brokenLink: &BrokenLinkError{UserID: "bob_id", Seq: 1, Reason: "events up to checkpointed event 3 are missing"},
+		},
+		{
+			name:   "Forged checkpoint",
+			events: func() []modelstorage.AuditEvent { return append(append([]modelstorage.AuditEvent{}, alice...), bob...) },
+			checkpoints: func() []modelstorage.AuditCheckpoint {
+				forged := append([]modelstorage.AuditCheckpoint{}, checkpoints...)
+				forged[2].Hash = bob[1].Hash
+				forged[2].ID = 3
+				return forged
+			},
+			brokenLink: &BrokenLinkError{UserID: "bob_id", Seq: 3, Reason: "checkpoint 3 has an invalid signature"},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			used := checkpoints
+			if tc.checkpoints != nil {
+				used = tc.checkpoints()
+			}
+			assert.Equal(t, tc.brokenLink, walk(tc.events(), used))
+		})
+	}
+}
@@ -7,4 +7,9 @@ type Cipher interface {
 	Decode(msg string) (string, error)
 	NewToken() (string, string)
 	ValidateToken(token string) (string, error)
+	BlindIndex(userID, term string) string
+	SignCheckpoint(message string) string
+	VerifyCheckpoint(message, signature string) bool
+	NewSessionKey() (string, string, error)
+	SessionDigest(sessionKey string) string
 }
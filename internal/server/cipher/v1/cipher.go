@@ -4,6 +4,8 @@ package cipher
 import (
 	"crypto/aes"
 	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
 	"crypto/sha256"
 	"dk-go-gophkeeper/internal/config"
 	procCipher "dk-go-gophkeeper/internal/server/cipher"
@@ -18,12 +20,23 @@ var (
 	_ procCipher.Cipher = (*Cipher)(nil)
 )
 
+// blindIndexContext separates the blind index key from the encryption key derived from the same secret.
+const blindIndexContext = "gophkeeper blind index"
+
+// sessionKeySize is the size of session keys in bytes.
+const sessionKeySize = 32
+
+// checkpointContext separates the audit checkpoint key from other keys derived from the same secret.
+const checkpointContext = "gophkeeper audit checkpoint"
+
 // Cipher defines attributes and methods of a Cipher instance.
 type Cipher struct {
-	aesgcm cipher.AEAD
-	nonce  []byte
-	key    []byte
-	logger *zerolog.Logger
+	aesgcm   cipher.AEAD
+	nonce    []byte
+	key      []byte
+	indexKey []byte
+	auditKey []byte
+	logger   *zerolog.Logger
 }
 
 // NewCipherService initializes a Cipher instance.
@@ -39,11 +52,17 @@ func NewCipherService(cfg *config.Config, logger *zerolog.Logger) (*Cipher, erro
 		return nil, err
 	}
 	nonce := key[len(key)-aesgcm.NonceSize():]
+	mac := hmac.New(sha256.New, []byte(cfg.UserKey))
+	mac.Write([]byte(blindIndexContext))
+	auditMac := hmac.New(sha256.New, []byte(cfg.UserKey))
+	auditMac.Write([]byte(checkpointContext))
 	return &Cipher{
-		aesgcm: aesgcm,
-		nonce:  nonce,
-		key:    []byte(cfg.UserKey),
-		logger: logger,
+		aesgcm:   aesgcm,
+		nonce:    nonce,
+		key:      []byte(cfg.UserKey),
+		indexKey: mac.Sum(nil),
+		auditKey: auditMac.Sum(nil),
+		logger:   logger,
 	}, nil
 }
 
@@ -81,3 +100,47 @@ func (s *Cipher) ValidateToken(token string) (string, error) {
 	}
 	return userID, nil
 }
+
+// BlindIndex returns a keyed HMAC token of a search term, tokens of different users never coincide.
+func (s *Cipher) BlindIndex(userID, term string) string {
+	mac := hmac.New(sha256.New, s.indexKey)
+	mac.Write([]byte(userID))
+	mac.Write([]byte{0})
+	mac.Write([]byte(term))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignCheckpoint returns a keyed HMAC signature of an audit checkpoint message.
+func (s *Cipher) SignCheckpoint(message string) string {
+	mac := hmac.New(sha256.New, s.auditKey)
+	mac.Write([]byte(message))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyCheckpoint checks a signature of an audit checkpoint message in constant time.
+func (s *Cipher) VerifyCheckpoint(message, signature string) bool {
+	decoded, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, s.auditKey)
+	mac.Write([]byte(message))
+	return hmac.Equal(decoded, mac.Sum(nil))
+}
+
+// NewSessionKey creates a random key of a session along with the digest it is stored by, the key itself is never
+// stored.
+func (s *Cipher) NewSessionKey() (string, string, error) {
+	key := make([]byte, sessionKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return "", "", err
+	}
+	sessionKey := hex.EncodeToString(key)
+	return sessionKey, s.SessionDigest(sessionKey), nil
+}
+
+// SessionDigest returns the digest a session is stored by.
+func (s *Cipher) SessionDigest(sessionKey string) string {
+	sum := sha256.Sum256([]byte(sessionKey))
+	return hex.EncodeToString(sum[:])
+}
@@ -39,6 +39,52 @@ func TestCipher_ValidateTokenFail(t *testing.T) {
 	assert.Equal(t, err.Error(), "encoding/hex: invalid byte: U+006E 'n'")
 }
 
+func TestCipher_BlindIndex(t *testing.T) {
+	cfg := config.NewDefaultConfiguration()
+	cfg.UserKey = "jds__63h3_7ds"
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	cipher, _ := NewCipherService(cfg, &logger)
+	token := cipher.BlindIndex("user1", "word:github")
+	assert.Equal(t, 64, len(token))
+	assert.Equal(t, token, cipher.BlindIndex("user1", "word:github"))
+	assert.NotEqual(t, token, cipher.BlindIndex("user2", "word:github"))
+	assert.NotEqual(t, token, cipher.BlindIndex("user1", "tag:github"))
+
+	cfg.UserKey = "another_key"
+	anotherCipher, _ := NewCipherService(cfg, &logger)
+	assert.NotEqual(t, token, anotherCipher.BlindIndex("user1", "word:github"))
+}
+
+func TestCipher_SignCheckpoint(t *testing.T) {
+	cfg := config.NewDefaultConfiguration()
+	cfg.UserKey = "jds__63h3_7ds"
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	cipher, _ := NewCipherService(cfg, &logger)
+	signature := cipher.SignCheckpoint("checkpoint")
+	assert.Equal(t, true, cipher.VerifyCheckpoint("checkpoint", signature))
+	assert.Equal(t, false, cipher.VerifyCheckpoint("another checkpoint", signature))
+	assert.Equal(t, false, cipher.VerifyCheckpoint("checkpoint", "not_hex"))
+	assert.NotEqual(t, cipher.BlindIndex("", "checkpoint"), signature)
+
+	cfg.UserKey = "another_key"
+	anotherCipher, _ := NewCipherService(cfg, &logger)
+	assert.Equal(t, false, anotherCipher.VerifyCheckpoint("checkpoint", signature))
+}
+
+func TestCipher_NewSessionKey(t *testing.T) {
+	cfg := config.NewDefaultConfiguration()
+	cfg.UserKey = "jds__63h3_7ds"
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	cipher, _ := NewCipherService(cfg, &logger)
+	sessionKey, digest, err := cipher.NewSessionKey()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 64, len(sessionKey))
+	assert.Equal(t, digest, cipher.SessionDigest(sessionKey))
+	assert.NotEqual(t, sessionKey, digest)
+	anotherKey, _, _ := cipher.NewSessionKey()
+	assert.NotEqual(t, sessionKey, anotherKey)
+}
+
 func TestDecode_Fail(t *testing.T) {
 	cfg := config.NewDefaultConfiguration()
 	cfg.UserKey = "jds__63h3_7ds"
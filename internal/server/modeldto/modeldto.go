@@ -1,11 +1,107 @@
 // Package modeldto provides models for data transferring between the handlers and the storage.
 package modeldto
 
+import (
+	"context"
+	"time"
+)
+
+// permissions of users an entry is shared with
+const (
+	PermissionRead      = "read"
+	PermissionReadWrite = "read-write"
+)
+
+// roles of collection members, from the most to the least privileged
+const (
+	RoleOwner    = "owner"
+	RoleAdmin    = "admin"
+	RoleMember   = "member"
+	RoleReadOnly = "read-only"
+)
+
+// actions on entries of a collection the roles of its members are checked for
+const (
+	ActionRead   = "read"
+	ActionWrite  = "write"
+	ActionDelete = "delete"
+)
+
+// vaultKey is a context key of the vault a request addresses.
+type vaultKey struct{}
+
+// WithVaultID returns a copy of the context carrying the ID of the vault a request addresses.
+func WithVaultID(ctx context.Context, vaultID string) context.Context {
+	return context.WithValue(ctx, vaultKey{}, vaultID)
+}
+
+// VaultID returns the ID of the vault a request addresses if it was resolved.
+func VaultID(ctx context.Context) (string, bool) {
+	vaultID, ok := ctx.Value(vaultKey{}).(string)
+	return vaultID, ok
+}
+
+// membership changes recorded in the audit of a collection
+const (
+	EventCreated     = "created"
+	EventDeleted     = "deleted"
+	EventInvited     = "invited"
+	EventJoined      = "joined"
+	EventDeclined    = "declined"
+	EventRoleChanged = "role-changed"
+	EventRemoved     = "removed"
+	EventLeft        = "left"
+)
+
+// security-relevant actions recorded in the audit log of a user besides actions on entries
+const (
+	AuditLogin      = "login"
+	AuditRegister   = "register"
+	AuditShare      = "share"
+	AuditCollection = "collection"
+	AuditSession    = "session"
+	AuditEmergency  = "emergency"
+)
+
+// states of emergency access of a trusted contact to a vault
+const (
+	EmergencyDesignated = "designated"
+	EmergencyRequested  = "requested"
+	EmergencyGranted    = "granted"
+)
+
+type Labels struct {
+	Folder   string
+	Tags     []string
+	Favorite bool
+}
+
+type CustomField struct {
+	Name      string
+	Value     string
+	Concealed bool
+}
+
+type Filter struct {
+	Folder        string
+	Tags          []string
+	FavoritesOnly bool
+}
+
+type Revision struct {
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
 type LoginPassword struct {
 	Identifier string
 	Login      string
 	Password   string
 	Meta       string
+	Labels
+	Fields []CustomField
+	Revision
+	PasswordChangedAt time.Time
 }
 
 type BankCard struct {
@@ -14,10 +110,146 @@ type BankCard struct {
 	Holder     string
 	CVV        string
 	Meta       string
+	Labels
+	Fields []CustomField
+	Revision
+	Expiry string
+	PIN    string
 }
 
 type TextBinary struct {
 	Identifier string
 	Entry      string
 	Meta       string
+	Labels
+	Fields []CustomField
+	Revision
+}
+
+type BatchItem struct {
+	Db            string
+	BankCard      BankCard
+	LoginPassword LoginPassword
+	TextBinary    TextBinary
+}
+
+type BatchItemResult struct {
+	Index      int
+	Identifier string
+	Created    bool
+	Err        error
+}
+
+type SearchPage struct {
+	Items         []BatchItem
+	NextPageToken string
+}
+
+type ShareRecipient struct {
+	Login      string
+	Permission string
+	PublicKey  string
+}
+
+type Share struct {
+	Db              string
+	Identifier      string
+	OwnerKey        string
+	Recipients      []ShareRecipient
+	UpdatedAt       time.Time
+	ReshareRequired bool
+}
+
+type SharedEntry struct {
+	ID         int64
+	Owner      string
+	Permission string
+	Db         string
+	Payload    string
+	WrappedKey string
+	UpdatedAt  time.Time
+}
+
+// SealedEntry holds an entry sealed by a client with its record key along with the record key wrapped for the owner
+// and, by their logins, for users the entry is shared with.
+type SealedEntry struct {
+	Payload     string
+	OwnerKey    string
+	WrappedKeys map[string]string
+}
+
+type Collection struct {
+	ID        string
+	Name      string
+	Role      string
+	CreatedAt time.Time
+}
+
+type CollectionMember struct {
+	Login    string
+	Role     string
+	JoinedAt time.Time
+}
+
+type CollectionInvitation struct {
+	ID             int64
+	CollectionID   string
+	CollectionName string
+	Inviter        string
+	Role           string
+	CreatedAt      time.Time
+}
+
+type CollectionEvent struct {
+	Actor     string
+	Target    string
+	Action    string
+	Role      string
+	CreatedAt time.Time
+}
+
+// AuditEvent holds a request of a user recorded in the audit log, Code is the name of the status code it ended with.
+type AuditEvent struct {
+	ID        int64
+	Action    string
+	Method    string
+	Peer      string
+	Code      string
+	CreatedAt time.Time
+}
+
+// Session holds a device a user is logged in from, Current marks the session of the request listing sessions.
+type Session struct {
+	ID            int64
+	DeviceName    string
+	ClientVersion string
+	Peer          string
+	CreatedAt     time.Time
+	LastSeenAt    time.Time
+	Current       bool
+}
+
+// EmergencyAccess holds a trusted contact of a vault owner. GrantsAt is the time a pending request is granted at unless
+// the owner rejects it.
+type EmergencyAccess struct {
+	Owner       string
+	Contact     string
+	WaitDays    int
+	Status      string
+	RequestedAt time.Time
+	GrantsAt    time.Time
+	GrantedAt   time.Time
+}
+
+// EmergencyEntry holds an entry sealed by a client of its owner for trusted contacts. WrappedKeys holds its record key
+// wrapped for the contacts by their logins, entries listed for the owner name the contacts in Contacts instead and
+// entries retrieved by a contact hold the record key wrapped for the contact in WrappedKey.
+type EmergencyEntry struct {
+	Identifier  string
+	Db          string
+	Payload     string
+	WrappedKeys map[string]string
+	Contacts    []string
+	WrappedKey  string
+	SealedAt    time.Time
 }
@@ -15,16 +15,83 @@ type Authorizer interface {
 
 // Getter defines a set of methods for types implementing Getter.
 type Getter interface {
-	GetBankCardData(ctx context.Context, userID string) ([]modeldto.BankCard, error)
-	GetLoginPasswordData(ctx context.Context, userID string) ([]modeldto.LoginPassword, error)
-	GetTextBinaryData(ctx context.Context, userID string) ([]modeldto.TextBinary, error)
+	GetBankCardData(ctx context.Context, userID, pageToken string, pageSize int, filter modeldto.Filter) ([]modeldto.BankCard, string, error)
+	GetLoginPasswordData(ctx context.Context, userID, pageToken string, pageSize int, filter modeldto.Filter) ([]modeldto.LoginPassword, string, error)
+	GetTextBinaryData(ctx context.Context, userID, pageToken string, pageSize int, filter modeldto.Filter) ([]modeldto.TextBinary, string, error)
 }
 
 // Setter defines a set of methods for types implementing Setter.
 type Setter interface {
-	SetBankCardData(ctx context.Context, userID, identifier, number, holder, cvv, meta string) error
-	SetLoginPasswordData(ctx context.Context, userID, identifier, login, password, meta string) error
-	SetTextBinaryData(ctx context.Context, userID, identifier, entry, meta string) error
+	SetBankCardData(ctx context.Context, userID, identifier, number, holder, cvv, expiry, pin, meta string, labels modeldto.Labels, fields []modeldto.CustomField) error
+	SetLoginPasswordData(ctx context.Context, userID, identifier, login, password, meta string, labels modeldto.Labels, fields []modeldto.CustomField) error
+	SetTextBinaryData(ctx context.Context, userID, identifier, entry, meta string, labels modeldto.Labels, fields []modeldto.CustomField) error
+}
+
+// BatchSetter defines a set of methods for types implementing BatchSetter.
+type BatchSetter interface {
+	SetBatchData(ctx context.Context, userID string, items []modeldto.BatchItem, overwrite bool) ([]modeldto.BatchItemResult, error)
+}
+
+// Searcher defines a set of methods for types implementing Searcher.
+type Searcher interface {
+	SearchEntries(ctx context.Context, userID string, keywords []string, filter modeldto.Filter, pageSize int, pageToken string) (modeldto.SearchPage, error)
+}
+
+// Sharer defines a set of methods for types implementing Sharer.
+type Sharer interface {
+	SetPublicKey(ctx context.Context, userID, publicKey string) error
+	GetPublicKey(ctx context.Context, userID, login string) (string, error)
+	ShareEntry(ctx context.Context, userID, db, identifier, recipient, permission string, sealed modeldto.SealedEntry) error
+	RevokeShare(ctx context.Context, userID, db, identifier, recipient string, sealed modeldto.SealedEntry) error
+	SetSharePayload(ctx context.Context, userID, db, identifier, payload string) error
+	GetShares(ctx context.Context, userID string) ([]modeldto.Share, error)
+	GetSharedWithMe(ctx context.Context, userID string) ([]modeldto.SharedEntry, error)
+	UpdateSharedEntry(ctx context.Context, userID string, shareID int64, item modeldto.BatchItem, payload string) error
+}
+
+// CollectionManager defines a set of methods for types implementing CollectionManager.
+type CollectionManager interface {
+	CreateCollection(ctx context.Context, userID, name string) (modeldto.Collection, error)
+	GetCollections(ctx context.Context, userID string) ([]modeldto.Collection, error)
+	DeleteCollection(ctx context.Context, userID, collectionID string) error
+	InviteMember(ctx context.Context, userID, collectionID, invitee, role string) error
+	GetInvitations(ctx context.Context, userID string) ([]modeldto.CollectionInvitation, error)
+	RespondInvitation(ctx context.Context, userID string, invitationID int64, accept bool) error
+	GetMembers(ctx context.Context, userID, collectionID string) ([]modeldto.CollectionMember, error)
+	SetMemberRole(ctx context.Context, userID, collectionID, member, role string) error
+	RemoveMember(ctx context.Context, userID, collectionID, member string) error
+	GetCollectionEvents(ctx context.Context, userID, collectionID string) ([]modeldto.CollectionEvent, error)
+	CollectionVault(ctx context.Context, userID, collectionID, action string) (string, error)
+}
+
+// Auditor defines a set of methods for types implementing Auditor.
+type Auditor interface {
+	RecordAuditEvent(ctx context.Context, userID string, event modeldto.AuditEvent) error
+	RecordLoginEvent(ctx context.Context, login string, event modeldto.AuditEvent) error
+	ListAuditEvents(ctx context.Context, userID string, beforeID int64, limit int) ([]modeldto.AuditEvent, error)
+}
+
+// SessionManager defines a set of methods for types implementing SessionManager.
+type SessionManager interface {
+	OpenSession(ctx context.Context, userID string, session modeldto.Session) (string, error)
+	CheckSession(ctx context.Context, userID, sessionKey, peer string) error
+	DeleteExpiredSessions(ctx context.Context) (int64, error)
+	ListSessions(ctx context.Context, userID, sessionKey string) ([]modeldto.Session, error)
+	RevokeSession(ctx context.Context, userID string, sessionID int64) error
+}
+
+// EmergencyAccessManager defines a set of methods for types implementing EmergencyAccessManager.
+type EmergencyAccessManager interface {
+	SetTrustedContact(ctx context.Context, userID, contact string, waitDays int) error
+	RemoveTrustedContact(ctx context.Context, userID, contact string) error
+	ListEmergencyAccess(ctx context.Context, userID string) ([]modeldto.EmergencyAccess, []modeldto.EmergencyAccess, error)
+	RequestEmergencyAccess(ctx context.Context, userID, owner string) error
+	ApproveEmergencyAccess(ctx context.Context, userID, contact string) error
+	RejectEmergencyAccess(ctx context.Context, userID, contact string) error
+	SetEmergencyEntries(ctx context.Context, userID string, entries []modeldto.EmergencyEntry) error
+	GetEmergencyEntries(ctx context.Context, userID, pageToken string, pageSize int) ([]modeldto.EmergencyEntry, string, error)
+	GetEmergencyVault(ctx context.Context, userID, owner, pageToken string, pageSize int) ([]modeldto.EmergencyEntry, string, error)
+	GrantDueEmergencyAccess(ctx context.Context) (int, error)
 }
 
 // Deleter defines a set of methods for types implementing Deleter.
@@ -37,5 +104,12 @@ type Processor interface {
 	Authorizer
 	Getter
 	Setter
+	BatchSetter
+	Searcher
+	Sharer
+	CollectionManager
+	Auditor
+	SessionManager
+	EmergencyAccessManager
 	Deleter
 }
@@ -0,0 +1,96 @@
+package processor
+
+import (
+	"context"
+	"dk-go-gophkeeper/internal/server/auditchain"
+	"dk-go-gophkeeper/internal/server/modeldto"
+	storageErrors "dk-go-gophkeeper/internal/server/storage/errors"
+	"dk-go-gophkeeper/internal/server/storage/modelstorage"
+	"errors"
+)
+
+// audit log page sizes
+const (
+	defaultAuditPageSize = 50
+	maxAuditPageSize     = 500
+)
+
+// auditCheckpointInterval is the amount of events of a user between signed checkpoints of their audit chain.
+const auditCheckpointInterval = 100
+
+// addAuditEvent appends an event to the audit log of a registered user and logs it along with the user. Every
+// auditCheckpointInterval-th event of the user is checkpointed with a signature of its hash.
+func (proc *Processor) addAuditEvent(ctx context.Context, accountID string, event modeldto.AuditEvent) error {
+	proc.logger.Info().Str("user", accountID).Str("action", event.Action).Str("method", event.Method).
+		Str("peer", event.Peer).Str("code", event.Code).Msg("Audit event")
+	added, err := proc.storage.AddAuditEvent(ctx, modelstorage.AuditEvent{
+		UserID: accountID,
+		Action: event.Action,
+		Method: event.Method,
+		Peer:   event.Peer,
+		Code:   event.Code,
+	})
+	if err != nil {
+		return storageErrors.ToStatus(err)
+	}
+	if added.Seq%auditCheckpointInterval != 0 {
+		return nil
+	}
+	checkpoint := modelstorage.AuditCheckpoint{UserID: accountID, Seq: added.Seq, Hash: added.Hash}
+	checkpoint.Signature = proc.cipher.SignCheckpoint(auditchain.CheckpointMessage(checkpoint))
+	return storageErrors.ToStatus(proc.storage.AddAuditCheckpoint(ctx, checkpoint))
+}
+
+// RecordAuditEvent appends an event to the audit log of the user a request is authorized for.
+func (proc *Processor) RecordAuditEvent(ctx context.Context, userID string, event modeldto.AuditEvent) error {
+	accountID, err := proc.accountID(userID)
+	if err != nil {
+		return err
+	}
+	return proc.addAuditEvent(ctx, accountID, event)
+}
+
+// RecordLoginEvent appends a login or register attempt to the audit log of the user registered with the login.
+// Attempts for unregistered logins belong to no one and are not recorded.
+func (proc *Processor) RecordLoginEvent(ctx context.Context, login string, event modeldto.AuditEvent) error {
+	accountID, err := proc.storage.GetUserIDByLogin(ctx, proc.cipher.Encode(login))
+	var notFound *storageErrors.NotFoundError
+	if errors.As(err, &notFound) {
+		return nil
+	}
+	if err != nil {
+		return storageErrors.ToStatus(err)
+	}
+	return proc.addAuditEvent(ctx, accountID, event)
+}
+
+// ListAuditEvents retrieves a page of the audit log of a user, the newest events first. Events older than beforeID are
+// retrieved unless it is zero.
+func (proc *Processor) ListAuditEvents(ctx context.Context, userID string, beforeID int64, limit int) ([]modeldto.AuditEvent, error) {
+	accountID, err := proc.accountID(userID)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case limit <= 0:
+		limit = defaultAuditPageSize
+	case limit > maxAuditPageSize:
+		limit = maxAuditPageSize
+	}
+	storageEvents, err := proc.storage.GetAuditEvents(ctx, accountID, beforeID, limit)
+	if err != nil {
+		return nil, storageErrors.ToStatus(err)
+	}
+	var events []modeldto.AuditEvent
+	for _, event := range storageEvents {
+		events = append(events, modeldto.AuditEvent{
+			ID:        event.ID,
+			Action:    event.Action,
+			Method:    event.Method,
+			Peer:      event.Peer,
+			Code:      event.Code,
+			CreatedAt: event.CreatedAt,
+		})
+	}
+	return events, nil
+}
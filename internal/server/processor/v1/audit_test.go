@@ -0,0 +1,54 @@
+package processor
+
+import (
+	"context"
+	"dk-go-gophkeeper/internal/server/auditchain"
+	"dk-go-gophkeeper/internal/server/modeldto"
+	"dk-go-gophkeeper/internal/server/storage/modelstorage"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProcessor_RecordAuditEvent(t *testing.T) {
+	ctrl, cipher, storage := newCollectionMocks(t)
+	defer ctrl.Finish()
+	event := modeldto.AuditEvent{Action: modeldto.ActionRead, Method: "GetBankCards", Peer: "10.0.0.1:5000", Code: "OK"}
+	stored := modelstorage.AuditEvent{UserID: "alice_id", Action: modeldto.ActionRead, Method: "GetBankCards", Peer: "10.0.0.1:5000", Code: "OK"}
+	storage.EXPECT().AddAuditEvent(gomock.Any(), stored).Return(modelstorage.AuditEvent{Seq: 99, Hash: "hash_99"}, nil)
+	storage.EXPECT().AddAuditEvent(gomock.Any(), stored).Return(modelstorage.AuditEvent{Seq: auditCheckpointInterval, Hash: "hash_100"}, nil)
+	// every auditCheckpointInterval-th event is checkpointed
+	checkpoint := modelstorage.AuditCheckpoint{UserID: "alice_id", Seq: auditCheckpointInterval, Hash: "hash_100"}
+	cipher.EXPECT().SignCheckpoint(auditchain.CheckpointMessage(checkpoint)).Return("signature")
+	checkpoint.Signature = "signature"
+	storage.EXPECT().AddAuditCheckpoint(gomock.Any(), checkpoint).Return(nil)
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	processor := InitService(storage, cipher, &logger)
+	err := processor.RecordAuditEvent(context.Background(), "alice_token", event)
+	assert.Equal(t, nil, err)
+	err = processor.RecordAuditEvent(context.Background(), "alice_token", event)
+	assert.Equal(t, nil, err)
+}
+
+func TestProcessor_ListAuditEvents(t *testing.T) {
+	ctrl, cipher, storage := newCollectionMocks(t)
+	defer ctrl.Finish()
+	createdAt := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	storage.EXPECT().GetAuditEvents(gomock.Any(), "alice_id", int64(0), defaultAuditPageSize).Return([]modelstorage.AuditEvent{
+		{ID: 7, UserID: "alice_id", Action: modeldto.AuditLogin, Method: "Login", Peer: "10.0.0.1:5000", Code: "OK", CreatedAt: createdAt},
+	}, nil)
+	storage.EXPECT().GetAuditEvents(gomock.Any(), "alice_id", int64(7), maxAuditPageSize).Return(nil, nil)
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	processor := InitService(storage, cipher, &logger)
+	events, err := processor.ListAuditEvents(context.Background(), "alice_token", 0, 0)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []modeldto.AuditEvent{{ID: 7, Action: modeldto.AuditLogin, Method: "Login", Peer: "10.0.0.1:5000", Code: "OK", CreatedAt: createdAt}}, events)
+	// pages are limited
+	events, err = processor.ListAuditEvents(context.Background(), "alice_token", 7, 10000)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, len(events))
+}
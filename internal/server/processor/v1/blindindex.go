@@ -0,0 +1,225 @@
+package processor
+
+import (
+	"context"
+	"dk-go-gophkeeper/internal/server/modeldto"
+	storageErrors "dk-go-gophkeeper/internal/server/storage/errors"
+	"dk-go-gophkeeper/internal/server/storage/modelstorage"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// blind index parameters
+const (
+	minKeywordLength = 2
+	maxIndexTerms    = 100
+)
+
+// term prefixes distinguishing kinds of indexed terms
+const (
+	termKeyword  = "word:"
+	termTag      = "tag:"
+	termFolder   = "folder:"
+	termFavorite = "is:favorite"
+)
+
+// splitTerms splits a text into lowercase words, a leading # of a word is kept to tell tags from keywords.
+func splitTerms(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '#'
+	})
+}
+
+// indexTerms returns unique search terms of an entry: every word of meta is a keyword, words starting with # are tags
+// too; labels add their tags, the folder along with all its parents and whether the entry is a favorite.
+func indexTerms(meta string, labels modeldto.Labels) []string {
+	seen := make(map[string]bool)
+	var terms []string
+	add := func(term string) {
+		if !seen[term] && len(terms) < maxIndexTerms {
+			seen[term] = true
+			terms = append(terms, term)
+		}
+	}
+	for _, term := range filterTerms(modeldto.Filter{Tags: labels.Tags, FavoritesOnly: labels.Favorite}) {
+		add(term)
+	}
+	folder := strings.ToLower(labels.Folder)
+	for i := range folder {
+		if strings.HasPrefix(folder[i:], folderSeparator) {
+			add(termFolder + folder[:i])
+		}
+	}
+	if folder != "" {
+		add(termFolder + folder)
+	}
+	for _, word := range splitTerms(meta) {
+		keyword := strings.Trim(word, "#")
+		if strings.HasPrefix(word, "#") && keyword != "" {
+			add(termTag + keyword)
+		}
+		if utf8.RuneCountInString(keyword) >= minKeywordLength {
+			add(termKeyword + keyword)
+		}
+	}
+	return terms
+}
+
+// filterTerms returns unique search terms of a filter: entries must have all the tags, be stored in the folder
+// or any of its subfolders and be a favorite if requested.
+func filterTerms(filter modeldto.Filter) []string {
+	var terms []string
+	for _, tag := range cleanTags(filter.Tags) {
+		terms = append(terms, termTag+tag)
+	}
+	if folder := cleanFolder(filter.Folder); folder != "" {
+		terms = append(terms, termFolder+strings.ToLower(folder))
+	}
+	if filter.FavoritesOnly {
+		terms = append(terms, termFavorite)
+	}
+	return terms
+}
+
+// queryTerms returns unique search terms all of which must be present in matching entries.
+func queryTerms(keywords []string, filter modeldto.Filter) []string {
+	seen := make(map[string]bool)
+	var terms []string
+	add := func(term string) {
+		if !seen[term] {
+			seen[term] = true
+			terms = append(terms, term)
+		}
+	}
+	for _, keyword := range keywords {
+		for _, word := range splitTerms(keyword) {
+			if word = strings.Trim(word, "#"); utf8.RuneCountInString(word) >= minKeywordLength {
+				add(termKeyword + word)
+			}
+		}
+	}
+	for _, term := range filterTerms(filter) {
+		add(term)
+	}
+	return terms
+}
+
+// tokens converts search terms into blind index tokens of a user.
+func (proc *Processor) tokens(userID string, terms []string) []string {
+	tokens := make([]string, 0, len(terms))
+	for _, term := range terms {
+		tokens = append(tokens, proc.cipher.BlindIndex(userID, term))
+	}
+	return tokens
+}
+
+// entryTokens returns blind index tokens of an entry stored along with it.
+func (proc *Processor) entryTokens(userID, meta string, labels modeldto.Labels) []string {
+	return proc.tokens(userID, indexTerms(meta, labels))
+}
+
+// SearchEntries performs a search of entries by meta keywords and labels using blind indexes and decodes a page of them.
+func (proc *Processor) SearchEntries(ctx context.Context, userID string, keywords []string, filter modeldto.Filter, pageSize int, token string) (modeldto.SearchPage, error) {
+	terms := queryTerms(keywords, filter)
+	if len(terms) == 0 {
+		return modeldto.SearchPage{}, status.Errorf(codes.InvalidArgument, "at least one label or keyword of %d or more characters is required", minKeywordLength)
+	}
+	cursor, limit, err := parseSearchPage(token, pageSize)
+	if err != nil {
+		return modeldto.SearchPage{}, err
+	}
+	storagePage, err := proc.storage.SearchEntries(ctx, userID, proc.tokens(userID, terms), cursor, limit)
+	if err != nil {
+		return modeldto.SearchPage{}, storageErrors.ToStatus(err)
+	}
+	page := modeldto.SearchPage{Items: make([]modeldto.BatchItem, 0, len(storagePage.Items))}
+	if storagePage.NextCursor != (modelstorage.SearchCursor{}) {
+		page.NextPageToken = searchPageToken(storagePage.NextCursor)
+	}
+	for _, storageItem := range storagePage.Items {
+		item, err := proc.decodeItem(storageItem)
+		if err != nil {
+			return modeldto.SearchPage{}, err
+		}
+		page.Items = append(page.Items, item)
+	}
+	return page, nil
+}
+
+// decodeItem performs decoding of a stored entry of any type including its custom fields.
+func (proc *Processor) decodeItem(storageItem modelstorage.BatchItem) (modeldto.BatchItem, error) {
+	var err error
+	decode := func(msg string) string {
+		if err != nil {
+			return ""
+		}
+		var decoded string
+		decoded, err = proc.cipher.Decode(msg)
+		return decoded
+	}
+	decodeLabels := func(labels modelstorage.Labels) modeldto.Labels {
+		if err != nil {
+			return modeldto.Labels{}
+		}
+		var decoded modeldto.Labels
+		decoded, err = proc.decodeLabels(labels)
+		return decoded
+	}
+	decodeOptional := func(msg string) string {
+		if err != nil {
+			return ""
+		}
+		var decoded string
+		decoded, err = proc.decodeOptional(msg)
+		return decoded
+	}
+	decodeFields := func(fields string) []modeldto.CustomField {
+		if err != nil {
+			return nil
+		}
+		var decoded []modeldto.CustomField
+		decoded, err = proc.decodeFields(fields)
+		return decoded
+	}
+	item := modeldto.BatchItem{Db: proc.requestDB(storageItem.Db)}
+	switch storageItem.Db {
+	case batchBankCardDB:
+		item.BankCard = modeldto.BankCard{
+			Identifier: decode(storageItem.BankCard.Identifier),
+			Number:     decode(storageItem.BankCard.Number),
+			Holder:     decode(storageItem.BankCard.Holder),
+			CVV:        decode(storageItem.BankCard.CVV),
+			Meta:       decode(storageItem.BankCard.Meta),
+			Labels:     decodeLabels(storageItem.BankCard.Labels),
+			Revision:   modeldto.Revision{CreatedAt: storageItem.BankCard.CreatedAt, UpdatedAt: storageItem.BankCard.UpdatedAt},
+			Expiry:     decodeOptional(storageItem.BankCard.Expiry),
+			PIN:        decodeOptional(storageItem.BankCard.PIN),
+			Fields:     decodeFields(storageItem.BankCard.Fields),
+		}
+	case batchLoginPasswordDB:
+		item.LoginPassword = modeldto.LoginPassword{
+			Identifier:        decode(storageItem.LoginPassword.Identifier),
+			Login:             decode(storageItem.LoginPassword.Login),
+			Password:          decode(storageItem.LoginPassword.Password),
+			Meta:              decode(storageItem.LoginPassword.Meta),
+			Labels:            decodeLabels(storageItem.LoginPassword.Labels),
+			Revision:          modeldto.Revision{CreatedAt: storageItem.LoginPassword.CreatedAt, UpdatedAt: storageItem.LoginPassword.UpdatedAt},
+			PasswordChangedAt: storageItem.LoginPassword.PasswordChangedAt,
+			Fields:            decodeFields(storageItem.LoginPassword.Fields),
+		}
+	case batchTextBinaryDB:
+		item.TextBinary = modeldto.TextBinary{
+			Identifier: decode(storageItem.TextBinary.Identifier),
+			Entry:      decode(storageItem.TextBinary.Entry),
+			Meta:       decode(storageItem.TextBinary.Meta),
+			Labels:     decodeLabels(storageItem.TextBinary.Labels),
+			Revision:   modeldto.Revision{CreatedAt: storageItem.TextBinary.CreatedAt, UpdatedAt: storageItem.TextBinary.UpdatedAt},
+			Fields:     decodeFields(storageItem.TextBinary.Fields),
+		}
+	}
+	return item, err
+}
@@ -0,0 +1,29 @@
+package processor
+
+import (
+	"dk-go-gophkeeper/internal/validation"
+)
+
+// prepareBankCard validates a bank card entry and normalizes its number and expiry date to be stored.
+func prepareBankCard(identifier, number, holder, cvv, expiry, pin, meta string) (string, string, error) {
+	if err := validation.ValidateBankCardEntry(identifier, number, holder, cvv, expiry, pin, meta); err != nil {
+		return "", "", invalidArgument(err)
+	}
+	return validation.NormalizeCardNumber(number), validation.NormalizeExpiry(expiry), nil
+}
+
+// encodeOptional performs an encoding of an optional value, a missing value is stored as an empty string.
+func (proc *Processor) encodeOptional(value string) string {
+	if value == "" {
+		return ""
+	}
+	return proc.cipher.Encode(value)
+}
+
+// decodeOptional performs a decoding of an optional value stored as an empty string when missing.
+func (proc *Processor) decodeOptional(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	return proc.cipher.Decode(value)
+}
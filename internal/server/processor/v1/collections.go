@@ -0,0 +1,430 @@
+package processor
+
+import (
+	"context"
+	"dk-go-gophkeeper/internal/server/modeldto"
+	storageErrors "dk-go-gophkeeper/internal/server/storage/errors"
+	"dk-go-gophkeeper/internal/server/storage/modelstorage"
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// collectionVaultPrefix prefixes IDs of vaults of collections, which key entries of collections the way access tokens
+// key entries of users. Access tokens are hexadecimal, so the two never coincide.
+const collectionVaultPrefix = "collection/"
+
+// roleRanks orders roles of collection members by their privileges.
+var roleRanks = map[string]int{
+	modeldto.RoleReadOnly: 1,
+	modeldto.RoleMember:   2,
+	modeldto.RoleAdmin:    3,
+	modeldto.RoleOwner:    4,
+}
+
+// actionRoles lists the least privileged roles allowed to take actions on entries of a collection.
+var actionRoles = map[string]string{
+	modeldto.ActionRead:   modeldto.RoleReadOnly,
+	modeldto.ActionWrite:  modeldto.RoleMember,
+	modeldto.ActionDelete: modeldto.RoleMember,
+}
+
+// collectionVault returns an ID of the vault keeping entries of a collection.
+func collectionVault(collectionID string) string {
+	return collectionVaultPrefix + collectionID
+}
+
+// isCollectionVault checks whether a vault keeps entries of a collection rather than of a user.
+func isCollectionVault(vaultID string) bool {
+	return strings.HasPrefix(vaultID, collectionVaultPrefix)
+}
+
+// checkRole checks a role offered to a collection member.
+func checkRole(role string) error {
+	if _, ok := roleRanks[role]; !ok {
+		return status.Errorf(codes.InvalidArgument, "invalid role %s", role)
+	}
+	return nil
+}
+
+// hasRole checks whether a role grants at least the privileges of another one.
+func hasRole(role, least string) bool {
+	return roleRanks[role] >= roleRanks[least]
+}
+
+// userIDByLogin returns an ID of a registered user.
+func (proc *Processor) userIDByLogin(ctx context.Context, login string) (string, error) {
+	if strings.TrimSpace(login) == "" {
+		return "", status.Error(codes.InvalidArgument, "login cannot be empty")
+	}
+	userID, err := proc.storage.GetUserIDByLogin(ctx, proc.cipher.Encode(login))
+	var notFound *storageErrors.NotFoundError
+	if errors.As(err, &notFound) {
+		return "", status.Errorf(codes.NotFound, "user %s is not registered", login)
+	}
+	return userID, storageErrors.ToStatus(err)
+}
+
+// decodeLogin decodes a login joined to a record, records might refer to no user at all.
+func (proc *Processor) decodeLogin(login string) (string, error) {
+	if login == "" {
+		return "", nil
+	}
+	return proc.cipher.Decode(login)
+}
+
+// member returns the membership of a user in a collection, the collection is not found for users outside of it.
+func (proc *Processor) member(ctx context.Context, accountID, collectionID string) (modelstorage.CollectionMember, error) {
+	if collectionID == "" {
+		return modelstorage.CollectionMember{}, status.Error(codes.InvalidArgument, "collection cannot be empty")
+	}
+	member, err := proc.storage.GetMember(ctx, collectionID, accountID)
+	var notFound *storageErrors.NotFoundError
+	if errors.As(err, &notFound) {
+		return member, status.Errorf(codes.NotFound, "collection %s not found", collectionID)
+	}
+	return member, storageErrors.ToStatus(err)
+}
+
+// authorize returns the membership of a user in a collection, the user must have at least the given role in it.
+func (proc *Processor) authorize(ctx context.Context, accountID, collectionID, least string) (modelstorage.CollectionMember, error) {
+	member, err := proc.member(ctx, accountID, collectionID)
+	if err != nil {
+		return member, err
+	}
+	if !hasRole(member.Role, least) {
+		return member, status.Errorf(codes.PermissionDenied, "role %s is required in collection %s", least, collectionID)
+	}
+	return member, nil
+}
+
+// keepOwner makes sure a collection keeps an owner once one of its owners leaves or steps down.
+func (proc *Processor) keepOwner(ctx context.Context, collectionID string) error {
+	members, err := proc.storage.GetMembers(ctx, collectionID)
+	if err != nil {
+		return storageErrors.ToStatus(err)
+	}
+	owners := 0
+	for _, member := range members {
+		if member.Role == modeldto.RoleOwner {
+			owners++
+		}
+	}
+	if owners < 2 {
+		return status.Error(codes.FailedPrecondition, "the last owner cannot leave a collection, delete it instead")
+	}
+	return nil
+}
+
+// CollectionVault checks whether a user may take an action on entries of a collection and returns an ID of the vault
+// keeping them.
+func (proc *Processor) CollectionVault(ctx context.Context, userID, collectionID, action string) (string, error) {
+	least, ok := actionRoles[action]
+	if !ok {
+		return "", status.Errorf(codes.InvalidArgument, "invalid action %s", action)
+	}
+	accountID, err := proc.accountID(userID)
+	if err != nil {
+		return "", err
+	}
+	if _, err = proc.authorize(ctx, accountID, collectionID, least); err != nil {
+		return "", err
+	}
+	return collectionVault(collectionID), nil
+}
+
+// CreateCollection creates a collection owned by a user, its name is encrypted.
+func (proc *Processor) CreateCollection(ctx context.Context, userID, name string) (modeldto.Collection, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return modeldto.Collection{}, status.Error(codes.InvalidArgument, "collection name cannot be empty")
+	}
+	accountID, err := proc.accountID(userID)
+	if err != nil {
+		return modeldto.Collection{}, err
+	}
+	collection := modelstorage.Collection{ID: uuid.New().String(), Name: proc.cipher.Encode(name)}
+	owner := modelstorage.CollectionMember{CollectionID: collection.ID, UserID: accountID, Role: modeldto.RoleOwner}
+	event := modelstorage.CollectionEvent{CollectionID: collection.ID, ActorID: accountID, TargetID: accountID, Action: modeldto.EventCreated, Role: modeldto.RoleOwner}
+	err = proc.storage.AddCollection(ctx, collection, owner, event)
+	if err != nil {
+		return modeldto.Collection{}, storageErrors.ToStatus(err)
+	}
+	return modeldto.Collection{ID: collection.ID, Name: name, Role: modeldto.RoleOwner}, nil
+}
+
+// GetCollections retrieves collections a user is a member of along with the role of the user.
+func (proc *Processor) GetCollections(ctx context.Context, userID string) ([]modeldto.Collection, error) {
+	accountID, err := proc.accountID(userID)
+	if err != nil {
+		return nil, err
+	}
+	storageCollections, err := proc.storage.GetCollections(ctx, accountID)
+	if err != nil {
+		return nil, storageErrors.ToStatus(err)
+	}
+	collections := make([]modeldto.Collection, 0, len(storageCollections))
+	for _, storageCollection := range storageCollections {
+		name, err := proc.cipher.Decode(storageCollection.Name)
+		if err != nil {
+			return nil, err
+		}
+		collections = append(collections, modeldto.Collection{
+			ID:        storageCollection.ID,
+			Name:      name,
+			Role:      storageCollection.Role,
+			CreatedAt: storageCollection.CreatedAt,
+		})
+	}
+	return collections, nil
+}
+
+// DeleteCollection deletes a collection along with its entries, only owners may delete collections.
+func (proc *Processor) DeleteCollection(ctx context.Context, userID, collectionID string) error {
+	accountID, err := proc.accountID(userID)
+	if err != nil {
+		return err
+	}
+	if _, err = proc.authorize(ctx, accountID, collectionID, modeldto.RoleOwner); err != nil {
+		return err
+	}
+	event := modelstorage.CollectionEvent{CollectionID: collectionID, ActorID: accountID, Action: modeldto.EventDeleted}
+	return storageErrors.ToStatus(proc.storage.DeleteCollection(ctx, collectionID, collectionVault(collectionID), event))
+}
+
+// InviteMember invites a registered user to a collection with a role. Owners and admins may invite users, only owners
+// may invite other owners.
+func (proc *Processor) InviteMember(ctx context.Context, userID, collectionID, invitee, role string) error {
+	if err := checkRole(role); err != nil {
+		return err
+	}
+	accountID, err := proc.accountID(userID)
+	if err != nil {
+		return err
+	}
+	inviter, err := proc.authorize(ctx, accountID, collectionID, modeldto.RoleAdmin)
+	if err != nil {
+		return err
+	}
+	if role == modeldto.RoleOwner && inviter.Role != modeldto.RoleOwner {
+		return status.Error(codes.PermissionDenied, "only owners can invite owners")
+	}
+	inviteeID, err := proc.userIDByLogin(ctx, invitee)
+	if err != nil {
+		return err
+	}
+	_, err = proc.storage.GetMember(ctx, collectionID, inviteeID)
+	var notFound *storageErrors.NotFoundError
+	switch {
+	case err == nil:
+		return status.Errorf(codes.AlreadyExists, "user %s is already a member", invitee)
+	case !errors.As(err, &notFound):
+		return storageErrors.ToStatus(err)
+	}
+	invitation := modelstorage.CollectionInvitation{CollectionID: collectionID, InviteeID: inviteeID, InviterID: accountID, Role: role}
+	event := modelstorage.CollectionEvent{CollectionID: collectionID, ActorID: accountID, TargetID: inviteeID, Action: modeldto.EventInvited, Role: role}
+	return storageErrors.ToStatus(proc.storage.SetInvitation(ctx, invitation, event))
+}
+
+// GetInvitations retrieves pending invitations of a user to collections.
+func (proc *Processor) GetInvitations(ctx context.Context, userID string) ([]modeldto.CollectionInvitation, error) {
+	accountID, err := proc.accountID(userID)
+	if err != nil {
+		return nil, err
+	}
+	storageInvitations, err := proc.storage.GetInvitations(ctx, accountID)
+	if err != nil {
+		return nil, storageErrors.ToStatus(err)
+	}
+	invitations := make([]modeldto.CollectionInvitation, 0, len(storageInvitations))
+	for _, storageInvitation := range storageInvitations {
+		name, err := proc.cipher.Decode(storageInvitation.CollectionName)
+		if err != nil {
+			return nil, err
+		}
+		inviter, err := proc.decodeLogin(storageInvitation.InviterLogin)
+		if err != nil {
+			return nil, err
+		}
+		invitations = append(invitations, modeldto.CollectionInvitation{
+			ID:             storageInvitation.ID,
+			CollectionID:   storageInvitation.CollectionID,
+			CollectionName: name,
+			Inviter:        inviter,
+			Role:           storageInvitation.Role,
+			CreatedAt:      storageInvitation.CreatedAt,
+		})
+	}
+	return invitations, nil
+}
+
+// RespondInvitation accepts or declines an invitation of a user to a collection.
+func (proc *Processor) RespondInvitation(ctx context.Context, userID string, invitationID int64, accept bool) error {
+	accountID, err := proc.accountID(userID)
+	if err != nil {
+		return err
+	}
+	invitation, err := proc.storage.GetInvitation(ctx, invitationID)
+	var notFound *storageErrors.NotFoundError
+	if errors.As(err, &notFound) || (err == nil && invitation.InviteeID != accountID) {
+		return status.Error(codes.NotFound, "invitation not found")
+	}
+	if err != nil {
+		return storageErrors.ToStatus(err)
+	}
+	action := modeldto.EventDeclined
+	if accept {
+		action = modeldto.EventJoined
+	}
+	event := modelstorage.CollectionEvent{CollectionID: invitation.CollectionID, ActorID: accountID, TargetID: accountID, Action: action, Role: invitation.Role}
+	err = proc.storage.ResolveInvitation(ctx, invitation, accept, event)
+	if errors.As(err, &notFound) {
+		return status.Error(codes.NotFound, "invitation not found")
+	}
+	return storageErrors.ToStatus(err)
+}
+
+// GetMembers retrieves members of a collection, every member may see the others.
+func (proc *Processor) GetMembers(ctx context.Context, userID, collectionID string) ([]modeldto.CollectionMember, error) {
+	accountID, err := proc.accountID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = proc.authorize(ctx, accountID, collectionID, modeldto.RoleReadOnly); err != nil {
+		return nil, err
+	}
+	storageMembers, err := proc.storage.GetMembers(ctx, collectionID)
+	if err != nil {
+		return nil, storageErrors.ToStatus(err)
+	}
+	members := make([]modeldto.CollectionMember, 0, len(storageMembers))
+	for _, storageMember := range storageMembers {
+		login, err := proc.cipher.Decode(storageMember.Login)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, modeldto.CollectionMember{Login: login, Role: storageMember.Role, JoinedAt: storageMember.JoinedAt})
+	}
+	return members, nil
+}
+
+// SetMemberRole changes the role of a member of a collection. Owners and admins may change roles, only owners may
+// change roles of owners or make other members owners.
+func (proc *Processor) SetMemberRole(ctx context.Context, userID, collectionID, member, role string) error {
+	if err := checkRole(role); err != nil {
+		return err
+	}
+	accountID, err := proc.accountID(userID)
+	if err != nil {
+		return err
+	}
+	actor, err := proc.authorize(ctx, accountID, collectionID, modeldto.RoleAdmin)
+	if err != nil {
+		return err
+	}
+	targetID, err := proc.userIDByLogin(ctx, member)
+	if err != nil {
+		return err
+	}
+	target, err := proc.storage.GetMember(ctx, collectionID, targetID)
+	var notFound *storageErrors.NotFoundError
+	if errors.As(err, &notFound) {
+		return status.Errorf(codes.NotFound, "user %s is not a member", member)
+	}
+	if err != nil {
+		return storageErrors.ToStatus(err)
+	}
+	if actor.Role != modeldto.RoleOwner && (target.Role == modeldto.RoleOwner || role == modeldto.RoleOwner) {
+		return status.Error(codes.PermissionDenied, "only owners can manage owners")
+	}
+	if target.Role == role {
+		return nil
+	}
+	if target.Role == modeldto.RoleOwner {
+		if err = proc.keepOwner(ctx, collectionID); err != nil {
+			return err
+		}
+	}
+	target.Role = role
+	event := modelstorage.CollectionEvent{CollectionID: collectionID, ActorID: accountID, TargetID: targetID, Action: modeldto.EventRoleChanged, Role: role}
+	return storageErrors.ToStatus(proc.storage.SetMemberRole(ctx, target, event))
+}
+
+// RemoveMember removes a member from a collection. Every member may leave a collection, owners and admins may remove
+// others, only owners may remove other owners.
+func (proc *Processor) RemoveMember(ctx context.Context, userID, collectionID, member string) error {
+	accountID, err := proc.accountID(userID)
+	if err != nil {
+		return err
+	}
+	actor, err := proc.member(ctx, accountID, collectionID)
+	if err != nil {
+		return err
+	}
+	targetID, err := proc.userIDByLogin(ctx, member)
+	if err != nil {
+		return err
+	}
+	if targetID == accountID {
+		if actor.Role == modeldto.RoleOwner {
+			if err = proc.keepOwner(ctx, collectionID); err != nil {
+				return err
+			}
+		}
+		event := modelstorage.CollectionEvent{CollectionID: collectionID, ActorID: accountID, TargetID: accountID, Action: modeldto.EventLeft, Role: actor.Role}
+		return storageErrors.ToStatus(proc.storage.DeleteMember(ctx, collectionID, accountID, event))
+	}
+	if !hasRole(actor.Role, modeldto.RoleAdmin) {
+		return status.Errorf(codes.PermissionDenied, "role %s is required in collection %s", modeldto.RoleAdmin, collectionID)
+	}
+	target, err := proc.storage.GetMember(ctx, collectionID, targetID)
+	var notFound *storageErrors.NotFoundError
+	if errors.As(err, &notFound) {
+		return status.Errorf(codes.NotFound, "user %s is not a member", member)
+	}
+	if err != nil {
+		return storageErrors.ToStatus(err)
+	}
+	if target.Role == modeldto.RoleOwner && actor.Role != modeldto.RoleOwner {
+		return status.Error(codes.PermissionDenied, "only owners can manage owners")
+	}
+	event := modelstorage.CollectionEvent{CollectionID: collectionID, ActorID: accountID, TargetID: targetID, Action: modeldto.EventRemoved, Role: target.Role}
+	return storageErrors.ToStatus(proc.storage.DeleteMember(ctx, collectionID, targetID, event))
+}
+
+// GetCollectionEvents retrieves the audit of membership changes of a collection, owners and admins may review it.
+func (proc *Processor) GetCollectionEvents(ctx context.Context, userID, collectionID string) ([]modeldto.CollectionEvent, error) {
+	accountID, err := proc.accountID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = proc.authorize(ctx, accountID, collectionID, modeldto.RoleAdmin); err != nil {
+		return nil, err
+	}
+	storageEvents, err := proc.storage.GetCollectionEvents(ctx, collectionID)
+	if err != nil {
+		return nil, storageErrors.ToStatus(err)
+	}
+	events := make([]modeldto.CollectionEvent, 0, len(storageEvents))
+	for _, storageEvent := range storageEvents {
+		actor, err := proc.decodeLogin(storageEvent.ActorLogin)
+		if err != nil {
+			return nil, err
+		}
+		target, err := proc.decodeLogin(storageEvent.TargetLogin)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, modeldto.CollectionEvent{
+			Actor:     actor,
+			Target:    target,
+			Action:    storageEvent.Action,
+			Role:      storageEvent.Role,
+			CreatedAt: storageEvent.CreatedAt,
+		})
+	}
+	return events, nil
+}
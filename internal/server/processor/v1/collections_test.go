@@ -0,0 +1,196 @@
+package processor
+
+import (
+	"context"
+	"dk-go-gophkeeper/internal/mocks"
+	"dk-go-gophkeeper/internal/server/modeldto"
+	storageErrors "dk-go-gophkeeper/internal/server/storage/errors"
+	"dk-go-gophkeeper/internal/server/storage/modelstorage"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// newCollectionMocks returns mocks whose tokens are IDs of users with the "_token" suffix instead of "_id".
+func newCollectionMocks(t *testing.T) (*gomock.Controller, *mocks.MockCipher, *mocks.MockDataStorage) {
+	ctrl := gomock.NewController(t)
+	cipher := mocks.NewMockCipher(ctrl)
+	storage := mocks.NewMockDataStorage(ctrl)
+	cipher.EXPECT().ValidateToken(gomock.Any()).DoAndReturn(func(token string) (string, error) {
+		return strings.TrimSuffix(token, "_token") + "_id", nil
+	}).AnyTimes()
+	cipher.EXPECT().Encode(gomock.Any()).DoAndReturn(func(data string) string { return "encoded_" + data }).AnyTimes()
+	cipher.EXPECT().Decode(gomock.Any()).DoAndReturn(func(msg string) (string, error) { return strings.TrimPrefix(msg, "encoded_"), nil }).AnyTimes()
+	return ctrl, cipher, storage
+}
+
+func TestProcessor_CollectionVault(t *testing.T) {
+	ctrl, cipher, storage := newCollectionMocks(t)
+	defer ctrl.Finish()
+	storage.EXPECT().GetMember(gomock.Any(), "team", "alice_id").Return(modelstorage.CollectionMember{Role: modeldto.RoleReadOnly}, nil).Times(3)
+	storage.EXPECT().GetMember(gomock.Any(), "team", "bob_id").Return(modelstorage.CollectionMember{Role: modeldto.RoleMember}, nil)
+	storage.EXPECT().GetMember(gomock.Any(), "team", "carol_id").Return(modelstorage.CollectionMember{}, &storageErrors.NotFoundError{})
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	processor := InitService(storage, cipher, &logger)
+	vaultID, err := processor.CollectionVault(context.Background(), "alice_token", "team", modeldto.ActionRead)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "collection/team", vaultID)
+	_, err = processor.CollectionVault(context.Background(), "alice_token", "team", modeldto.ActionWrite)
+	assert.Equal(t, "rpc error: code = PermissionDenied desc = role member is required in collection team", err.Error())
+	_, err = processor.CollectionVault(context.Background(), "alice_token", "team", modeldto.ActionDelete)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	vaultID, err = processor.CollectionVault(context.Background(), "bob_token", "team", modeldto.ActionDelete)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "collection/team", vaultID)
+	// collections of other users are not revealed
+	_, err = processor.CollectionVault(context.Background(), "carol_token", "team", modeldto.ActionRead)
+	assert.Equal(t, "rpc error: code = NotFound desc = collection team not found", err.Error())
+	_, err = processor.CollectionVault(context.Background(), "carol_token", "team", "share")
+	assert.Equal(t, "rpc error: code = InvalidArgument desc = invalid action share", err.Error())
+}
+
+func TestProcessor_CreateCollection(t *testing.T) {
+	ctrl, cipher, storage := newCollectionMocks(t)
+	defer ctrl.Finish()
+	var collectionID string
+	storage.EXPECT().AddCollection(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, collection modelstorage.Collection, owner modelstorage.CollectionMember, event modelstorage.CollectionEvent) error {
+			collectionID = collection.ID
+			assert.Equal(t, "encoded_Ops", collection.Name)
+			assert.Equal(t, modelstorage.CollectionMember{CollectionID: collection.ID, UserID: "alice_id", Role: modeldto.RoleOwner}, owner)
+			assert.Equal(t, modelstorage.CollectionEvent{CollectionID: collection.ID, ActorID: "alice_id", TargetID: "alice_id", Action: modeldto.EventCreated, Role: modeldto.RoleOwner}, event)
+			return nil
+		})
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	processor := InitService(storage, cipher, &logger)
+	collection, err := processor.CreateCollection(context.Background(), "alice_token", " Ops ")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, modeldto.Collection{ID: collectionID, Name: "Ops", Role: modeldto.RoleOwner}, collection)
+	_, err = processor.CreateCollection(context.Background(), "alice_token", " ")
+	assert.Equal(t, "rpc error: code = InvalidArgument desc = collection name cannot be empty", err.Error())
+}
+
+func TestProcessor_InviteMember(t *testing.T) {
+	ctrl, cipher, storage := newCollectionMocks(t)
+	defer ctrl.Finish()
+	storage.EXPECT().GetMember(gomock.Any(), "team", "alice_id").Return(modelstorage.CollectionMember{Role: modeldto.RoleAdmin}, nil).Times(4)
+	storage.EXPECT().GetMember(gomock.Any(), "team", "bob_id").Return(modelstorage.CollectionMember{}, &storageErrors.NotFoundError{})
+	storage.EXPECT().GetMember(gomock.Any(), "team", "carol_id").Return(modelstorage.CollectionMember{Role: modeldto.RoleMember}, nil)
+	storage.EXPECT().GetUserIDByLogin(gomock.Any(), "encoded_bob").Return("bob_id", nil)
+	storage.EXPECT().GetUserIDByLogin(gomock.Any(), "encoded_carol").Return("carol_id", nil)
+	storage.EXPECT().GetUserIDByLogin(gomock.Any(), "encoded_dave").Return("", &storageErrors.NotFoundError{})
+	storage.EXPECT().SetInvitation(gomock.Any(),
+		modelstorage.CollectionInvitation{CollectionID: "team", InviteeID: "bob_id", InviterID: "alice_id", Role: modeldto.RoleMember},
+		modelstorage.CollectionEvent{CollectionID: "team", ActorID: "alice_id", TargetID: "bob_id", Action: modeldto.EventInvited, Role: modeldto.RoleMember},
+	).Return(nil)
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	processor := InitService(storage, cipher, &logger)
+	err := processor.InviteMember(context.Background(), "alice_token", "team", "bob", modeldto.RoleMember)
+	assert.Equal(t, nil, err)
+	err = processor.InviteMember(context.Background(), "alice_token", "team", "bob", "superuser")
+	assert.Equal(t, "rpc error: code = InvalidArgument desc = invalid role superuser", err.Error())
+	// admins cannot grant more than they have
+	err = processor.InviteMember(context.Background(), "alice_token", "team", "bob", modeldto.RoleOwner)
+	assert.Equal(t, "rpc error: code = PermissionDenied desc = only owners can invite owners", err.Error())
+	err = processor.InviteMember(context.Background(), "alice_token", "team", "carol", modeldto.RoleReadOnly)
+	assert.Equal(t, "rpc error: code = AlreadyExists desc = user carol is already a member", err.Error())
+	err = processor.InviteMember(context.Background(), "alice_token", "team", "dave", modeldto.RoleReadOnly)
+	assert.Equal(t, "rpc error: code = NotFound desc = user dave is not registered", err.Error())
+}
+
+func TestProcessor_RespondInvitation(t *testing.T) {
+	ctrl, cipher, storage := newCollectionMocks(t)
+	defer ctrl.Finish()
+	invitation := modelstorage.CollectionInvitation{ID: 4, CollectionID: "team", InviteeID: "bob_id", InviterID: "alice_id", Role: modeldto.RoleReadOnly}
+	storage.EXPECT().GetInvitation(gomock.Any(), int64(4)).Return(invitation, nil).Times(2)
+	storage.EXPECT().ResolveInvitation(gomock.Any(), invitation, true,
+		modelstorage.CollectionEvent{CollectionID: "team", ActorID: "bob_id", TargetID: "bob_id", Action: modeldto.EventJoined, Role: modeldto.RoleReadOnly},
+	).Return(nil)
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	processor := InitService(storage, cipher, &logger)
+	err := processor.RespondInvitation(context.Background(), "bob_token", 4, true)
+	assert.Equal(t, nil, err)
+	// invitations of other users are not revealed
+	err = processor.RespondInvitation(context.Background(), "carol_token", 4, true)
+	assert.Equal(t, "rpc error: code = NotFound desc = invitation not found", err.Error())
+}
+
+func TestProcessor_SetMemberRole(t *testing.T) {
+	ctrl, cipher, storage := newCollectionMocks(t)
+	defer ctrl.Finish()
+	storage.EXPECT().GetMember(gomock.Any(), "team", "alice_id").Return(modelstorage.CollectionMember{CollectionID: "team", UserID: "alice_id", Role: modeldto.RoleOwner}, nil).Times(4)
+	storage.EXPECT().GetMember(gomock.Any(), "team", "bob_id").Return(modelstorage.CollectionMember{CollectionID: "team", UserID: "bob_id", Role: modeldto.RoleAdmin}, nil).Times(2)
+	storage.EXPECT().GetUserIDByLogin(gomock.Any(), "encoded_alice").Return("alice_id", nil).Times(2)
+	storage.EXPECT().GetUserIDByLogin(gomock.Any(), "encoded_bob").Return("bob_id", nil)
+	storage.EXPECT().GetMembers(gomock.Any(), "team").Return([]modelstorage.CollectionMember{
+		{UserID: "alice_id", Role: modeldto.RoleOwner},
+		{UserID: "bob_id", Role: modeldto.RoleAdmin},
+	}, nil)
+	storage.EXPECT().SetMemberRole(gomock.Any(),
+		modelstorage.CollectionMember{CollectionID: "team", UserID: "bob_id", Role: modeldto.RoleReadOnly},
+		modelstorage.CollectionEvent{CollectionID: "team", ActorID: "alice_id", TargetID: "bob_id", Action: modeldto.EventRoleChanged, Role: modeldto.RoleReadOnly},
+	).Return(nil)
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	processor := InitService(storage, cipher, &logger)
+	err := processor.SetMemberRole(context.Background(), "alice_token", "team", "bob", modeldto.RoleReadOnly)
+	assert.Equal(t, nil, err)
+	// the only owner cannot step down
+	err = processor.SetMemberRole(context.Background(), "alice_token", "team", "alice", modeldto.RoleAdmin)
+	assert.Equal(t, "rpc error: code = FailedPrecondition desc = the last owner cannot leave a collection, delete it instead", err.Error())
+	// admins cannot manage owners
+	err = processor.SetMemberRole(context.Background(), "bob_token", "team", "alice", modeldto.RoleMember)
+	assert.Equal(t, "rpc error: code = PermissionDenied desc = only owners can manage owners", err.Error())
+}
+
+func TestProcessor_RemoveMember(t *testing.T) {
+	ctrl, cipher, storage := newCollectionMocks(t)
+	defer ctrl.Finish()
+	storage.EXPECT().GetMember(gomock.Any(), "team", "alice_id").Return(modelstorage.CollectionMember{Role: modeldto.RoleOwner}, nil)
+	storage.EXPECT().GetMember(gomock.Any(), "team", "bob_id").Return(modelstorage.CollectionMember{Role: modeldto.RoleMember}, nil).Times(2)
+	storage.EXPECT().GetUserIDByLogin(gomock.Any(), "encoded_alice").Return("alice_id", nil).Times(2)
+	storage.EXPECT().GetUserIDByLogin(gomock.Any(), "encoded_bob").Return("bob_id", nil)
+	storage.EXPECT().GetMembers(gomock.Any(), "team").Return([]modelstorage.CollectionMember{
+		{UserID: "alice_id", Role: modeldto.RoleOwner},
+		{UserID: "bob_id", Role: modeldto.RoleMember},
+	}, nil)
+	storage.EXPECT().DeleteMember(gomock.Any(), "team", "bob_id",
+		modelstorage.CollectionEvent{CollectionID: "team", ActorID: "bob_id", TargetID: "bob_id", Action: modeldto.EventLeft, Role: modeldto.RoleMember},
+	).Return(nil)
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	processor := InitService(storage, cipher, &logger)
+	// members may leave on their own
+	err := processor.RemoveMember(context.Background(), "bob_token", "team", "bob")
+	assert.Equal(t, nil, err)
+	// but may not remove others
+	err = processor.RemoveMember(context.Background(), "bob_token", "team", "alice")
+	assert.Equal(t, "rpc error: code = PermissionDenied desc = role admin is required in collection team", err.Error())
+	err = processor.RemoveMember(context.Background(), "alice_token", "team", "alice")
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
+
+func TestProcessor_GetCollectionEvents(t *testing.T) {
+	ctrl, cipher, storage := newCollectionMocks(t)
+	defer ctrl.Finish()
+	storage.EXPECT().GetMember(gomock.Any(), "team", "alice_id").Return(modelstorage.CollectionMember{Role: modeldto.RoleAdmin}, nil)
+	storage.EXPECT().GetMember(gomock.Any(), "team", "bob_id").Return(modelstorage.CollectionMember{Role: modeldto.RoleMember}, nil)
+	storage.EXPECT().GetCollectionEvents(gomock.Any(), "team").Return([]modelstorage.CollectionEvent{
+		{ActorLogin: "encoded_alice", TargetLogin: "encoded_alice", Action: modeldto.EventCreated, Role: modeldto.RoleOwner},
+		{ActorLogin: "encoded_alice", Action: modeldto.EventDeleted},
+	}, nil)
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	processor := InitService(storage, cipher, &logger)
+	events, err := processor.GetCollectionEvents(context.Background(), "alice_token", "team")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []modeldto.CollectionEvent{
+		{Actor: "alice", Target: "alice", Action: modeldto.EventCreated, Role: modeldto.RoleOwner},
+		{Actor: "alice", Action: modeldto.EventDeleted},
+	}, events)
+	_, err = processor.GetCollectionEvents(context.Background(), "bob_token", "team")
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
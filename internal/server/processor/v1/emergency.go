@@ -0,0 +1,373 @@
+package processor
+
+import (
+	"context"
+	"dk-go-gophkeeper/internal/server/modeldto"
+	storageErrors "dk-go-gophkeeper/internal/server/storage/errors"
+	"dk-go-gophkeeper/internal/server/storage/modelstorage"
+	"errors"
+	"sort"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// waiting periods of emergency access in days
+const (
+	defaultEmergencyWaitDays = 7
+	maxEmergencyWaitDays     = 90
+)
+
+// emergencyTimerMethod is the method emergency access granted by the timer is recorded with in audit logs of owners.
+const emergencyTimerMethod = "EmergencyAccessTimer"
+
+// trustedContactID returns an ID of a registered user designated as a trusted contact.
+func (proc *Processor) trustedContactID(ctx context.Context, ownerID, contact string) (string, error) {
+	contactID, err := proc.userIDByLogin(ctx, contact)
+	if err != nil {
+		return "", err
+	}
+	if contactID == ownerID {
+		return "", status.Error(codes.InvalidArgument, "owners cannot be their own trusted contacts")
+	}
+	return contactID, nil
+}
+
+// emergencyAccessToDTO converts emergency access of a trusted contact, logins of the owner and the contact are decoded.
+func (proc *Processor) emergencyAccessToDTO(access modelstorage.EmergencyAccess) (modeldto.EmergencyAccess, error) {
+	owner, err := proc.decodeLogin(access.OwnerLogin)
+	if err != nil {
+		return modeldto.EmergencyAccess{}, err
+	}
+	contact, err := proc.decodeLogin(access.ContactLogin)
+	if err != nil {
+		return modeldto.EmergencyAccess{}, err
+	}
+	converted := modeldto.EmergencyAccess{
+		Owner:       owner,
+		Contact:     contact,
+		WaitDays:    access.WaitDays,
+		Status:      access.Status,
+		RequestedAt: access.RequestedAt,
+		GrantedAt:   access.GrantedAt,
+	}
+	if access.Status == modeldto.EmergencyRequested {
+		converted.GrantsAt = access.RequestedAt.AddDate(0, 0, access.WaitDays)
+	}
+	return converted, nil
+}
+
+// recordOwnerEvent appends a change of emergency access to the audit log of the vault owner, so that the owner learns
+// about requests and grants. The change is kept even if it could not be recorded.
+func (proc *Processor) recordOwnerEvent(ctx context.Context, ownerID, method string) {
+	err := proc.addAuditEvent(ctx, ownerID, modeldto.AuditEvent{Action: modeldto.AuditEmergency, Method: method, Code: codes.OK.String()})
+	if err != nil {
+		proc.logger.Error().Err(err).Msgf("Could not record %s in the audit log of the owner", method)
+	}
+}
+
+// SetTrustedContact designates a user as a trusted contact who may request emergency access to the vault of the
+// owner, or changes the waiting period of an existing one. The default waiting period is used if it is zero.
+func (proc *Processor) SetTrustedContact(ctx context.Context, userID, contact string, waitDays int) error {
+	if waitDays == 0 {
+		waitDays = defaultEmergencyWaitDays
+	}
+	if waitDays < 0 || waitDays > maxEmergencyWaitDays {
+		return status.Errorf(codes.InvalidArgument, "waiting period must be from 1 to %d days", maxEmergencyWaitDays)
+	}
+	ownerID, err := proc.accountID(userID)
+	if err != nil {
+		return err
+	}
+	contactID, err := proc.trustedContactID(ctx, ownerID, contact)
+	if err != nil {
+		return err
+	}
+	return storageErrors.ToStatus(proc.storage.SetTrustedContact(ctx, ownerID, contactID, waitDays))
+}
+
+// RemoveTrustedContact removes a trusted contact of the owner, access granted to the contact is revoked.
+func (proc *Processor) RemoveTrustedContact(ctx context.Context, userID, contact string) error {
+	ownerID, err := proc.accountID(userID)
+	if err != nil {
+		return err
+	}
+	contactID, err := proc.trustedContactID(ctx, ownerID, contact)
+	if err != nil {
+		return err
+	}
+	err = proc.storage.DeleteTrustedContact(ctx, ownerID, contactID)
+	var notFound *storageErrors.NotFoundError
+	if errors.As(err, &notFound) {
+		return status.Errorf(codes.NotFound, "user %s is not a trusted contact", contact)
+	}
+	return storageErrors.ToStatus(err)
+}
+
+// ListEmergencyAccess retrieves trusted contacts of a user along with owners who designated the user as their trusted
+// contact.
+func (proc *Processor) ListEmergencyAccess(ctx context.Context, userID string) ([]modeldto.EmergencyAccess, []modeldto.EmergencyAccess, error) {
+	accountID, err := proc.accountID(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	storageContacts, err := proc.storage.GetTrustedContacts(ctx, accountID)
+	if err != nil {
+		return nil, nil, storageErrors.ToStatus(err)
+	}
+	storageGrantors, err := proc.storage.GetEmergencyGrantors(ctx, accountID)
+	if err != nil {
+		return nil, nil, storageErrors.ToStatus(err)
+	}
+	contacts := make([]modeldto.EmergencyAccess, 0, len(storageContacts))
+	for _, storageContact := range storageContacts {
+		contact, err := proc.emergencyAccessToDTO(storageContact)
+		if err != nil {
+			return nil, nil, err
+		}
+		contacts = append(contacts, contact)
+	}
+	grantors := make([]modeldto.EmergencyAccess, 0, len(storageGrantors))
+	for _, storageGrantor := range storageGrantors {
+		grantor, err := proc.emergencyAccessToDTO(storageGrantor)
+		if err != nil {
+			return nil, nil, err
+		}
+		grantors = append(grantors, grantor)
+	}
+	return contacts, grantors, nil
+}
+
+// emergencyAccess retrieves emergency access of a trusted contact to the vault of an owner.
+func (proc *Processor) emergencyAccess(ctx context.Context, ownerID, contactID, notFoundMessage string) (modelstorage.EmergencyAccess, error) {
+	access, err := proc.storage.GetEmergencyAccess(ctx, ownerID, contactID)
+	var notFound *storageErrors.NotFoundError
+	if errors.As(err, &notFound) {
+		return access, status.Error(codes.NotFound, notFoundMessage)
+	}
+	return access, storageErrors.ToStatus(err)
+}
+
+// RequestEmergencyAccess starts the waiting period of a trusted contact, access to the vault is granted once it is over
+// unless the owner rejects the request.
+func (proc *Processor) RequestEmergencyAccess(ctx context.Context, userID, owner string) error {
+	contactID, err := proc.accountID(userID)
+	if err != nil {
+		return err
+	}
+	ownerID, err := proc.userIDByLogin(ctx, owner)
+	if err != nil {
+		return err
+	}
+	access, err := proc.emergencyAccess(ctx, ownerID, contactID, "user "+owner+" has not designated you as a trusted contact")
+	if err != nil {
+		return err
+	}
+	if access.Status != modeldto.EmergencyDesignated {
+		return status.Errorf(codes.AlreadyExists, "emergency access to the vault of %s is already %s", owner, access.Status)
+	}
+	err = proc.storage.RequestEmergencyAccess(ctx, ownerID, contactID)
+	if err != nil {
+		return storageErrors.ToStatus(err)
+	}
+	proc.recordOwnerEvent(ctx, ownerID, "RequestEmergencyAccess")
+	return nil
+}
+
+// ApproveEmergencyAccess grants a pending request of a trusted contact without waiting for its waiting period to end.
+func (proc *Processor) ApproveEmergencyAccess(ctx context.Context, userID, contact string) error {
+	ownerID, err := proc.accountID(userID)
+	if err != nil {
+		return err
+	}
+	contactID, err := proc.trustedContactID(ctx, ownerID, contact)
+	if err != nil {
+		return err
+	}
+	access, err := proc.emergencyAccess(ctx, ownerID, contactID, "user "+contact+" is not a trusted contact")
+	if err != nil {
+		return err
+	}
+	if access.Status != modeldto.EmergencyRequested {
+		return status.Errorf(codes.FailedPrecondition, "user %s has no pending request of emergency access", contact)
+	}
+	return storageErrors.ToStatus(proc.storage.GrantEmergencyAccess(ctx, access.ID))
+}
+
+// RejectEmergencyAccess rejects a pending request of a trusted contact or revokes access granted to the contact, who
+// stays a trusted contact and may request access again.
+func (proc *Processor) RejectEmergencyAccess(ctx context.Context, userID, contact string) error {
+	ownerID, err := proc.accountID(userID)
+	if err != nil {
+		return err
+	}
+	contactID, err := proc.trustedContactID(ctx, ownerID, contact)
+	if err != nil {
+		return err
+	}
+	err = proc.storage.ResetEmergencyAccess(ctx, ownerID, contactID)
+	var notFound *storageErrors.NotFoundError
+	if errors.As(err, &notFound) {
+		return status.Errorf(codes.NotFound, "user %s has neither requested nor been granted emergency access", contact)
+	}
+	return storageErrors.ToStatus(err)
+}
+
+// SetEmergencyEntries stores entries of the owner sealed by the client of the owner with their record keys, which are
+// wrapped for trusted contacts of the owner only, so that the server never opens entries granted to contacts.
+func (proc *Processor) SetEmergencyEntries(ctx context.Context, userID string, entries []modeldto.EmergencyEntry) error {
+	if len(entries) > maxPageSize {
+		return status.Errorf(codes.InvalidArgument, "at most %d entries can be sealed at once", maxPageSize)
+	}
+	ownerID, err := proc.accountID(userID)
+	if err != nil {
+		return err
+	}
+	contacts, err := proc.storage.GetTrustedContacts(ctx, ownerID)
+	if err != nil {
+		return storageErrors.ToStatus(err)
+	}
+	accessIDs := make(map[string]int64, len(contacts))
+	for _, contact := range contacts {
+		login, err := proc.decodeLogin(contact.ContactLogin)
+		if err != nil {
+			return err
+		}
+		accessIDs[login] = contact.ID
+	}
+	storageEntries := make([]modelstorage.EmergencyEntry, 0, len(entries))
+	for _, entry := range entries {
+		db, err := proc.shareDB(entry.Db)
+		if err != nil {
+			return err
+		}
+		if entry.Identifier == "" || entry.Payload == "" {
+			return status.Error(codes.InvalidArgument, "entries must be sealed with their identifiers")
+		}
+		storageEntry := modelstorage.EmergencyEntry{Db: db, Identifier: proc.cipher.Encode(entry.Identifier), Payload: entry.Payload}
+		for login, wrappedKey := range entry.WrappedKeys {
+			accessID, ok := accessIDs[login]
+			if !ok {
+				return status.Errorf(codes.FailedPrecondition, "user %s is not a trusted contact", login)
+			}
+			if wrappedKey == "" {
+				return status.Errorf(codes.InvalidArgument, "record key of entry %s must be wrapped for %s", entry.Identifier, login)
+			}
+			storageEntry.Keys = append(storageEntry.Keys, modelstorage.EmergencyKey{AccessID: accessID, WrappedKey: wrappedKey})
+		}
+		sort.Slice(storageEntry.Keys, func(i, j int) bool { return storageEntry.Keys[i].AccessID < storageEntry.Keys[j].AccessID })
+		storageEntries = append(storageEntries, storageEntry)
+	}
+	// entries of the owner are keyed by the access token of the owner
+	return storageErrors.ToStatus(proc.storage.SetEmergencyEntries(ctx, userID, storageEntries))
+}
+
+// GetEmergencyEntries retrieves a page of entries of the owner sealed for trusted contacts along with logins of the
+// contacts they are sealed for, so that the client of the owner reseals entries changed or added since.
+func (proc *Processor) GetEmergencyEntries(ctx context.Context, userID, token string, pageSize int) ([]modeldto.EmergencyEntry, string, error) {
+	cursor, limit, err := parsePage(token, pageSize)
+	if err != nil {
+		return nil, "", err
+	}
+	// one extra entry tells whether there is a next page
+	storageEntries, err := proc.storage.GetEmergencyEntries(ctx, userID, cursor, limit+1)
+	if err != nil {
+		return nil, "", storageErrors.ToStatus(err)
+	}
+	var nextPageToken string
+	if len(storageEntries) > limit {
+		storageEntries = storageEntries[:limit]
+		nextPageToken = pageToken(storageEntries[limit-1].ID)
+	}
+	entries := make([]modeldto.EmergencyEntry, 0, len(storageEntries))
+	for _, storageEntry := range storageEntries {
+		identifier, err := proc.cipher.Decode(storageEntry.Identifier)
+		if err != nil {
+			return nil, "", err
+		}
+		entry := modeldto.EmergencyEntry{Identifier: identifier, Db: proc.requestDB(storageEntry.Db), SealedAt: storageEntry.SealedAt}
+		for _, key := range storageEntry.Keys {
+			contact, err := proc.decodeLogin(key.ContactLogin)
+			if err != nil {
+				return nil, "", err
+			}
+			entry.Contacts = append(entry.Contacts, contact)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nextPageToken, nil
+}
+
+// GetEmergencyVault retrieves a page of entries of an owner who granted emergency access to a trusted contact. Entries
+// are sealed by the client of the owner along with their record keys wrapped for the contact, the client of the
+// contact opens them.
+func (proc *Processor) GetEmergencyVault(ctx context.Context, userID, owner, token string, pageSize int) ([]modeldto.EmergencyEntry, string, error) {
+	cursor, limit, err := parsePage(token, pageSize)
+	if err != nil {
+		return nil, "", err
+	}
+	contactID, err := proc.accountID(userID)
+	if err != nil {
+		return nil, "", err
+	}
+	ownerID, err := proc.userIDByLogin(ctx, owner)
+	if err != nil {
+		return nil, "", err
+	}
+	access, err := proc.emergencyAccess(ctx, ownerID, contactID, "user "+owner+" has not designated you as a trusted contact")
+	if err != nil {
+		return nil, "", err
+	}
+	if access.Status != modeldto.EmergencyGranted {
+		return nil, "", status.Errorf(codes.PermissionDenied, "emergency access to the vault of %s is not granted", owner)
+	}
+	storageEntries, err := proc.storage.GetEmergencyVault(ctx, access.ID, cursor, limit+1)
+	if err != nil {
+		return nil, "", storageErrors.ToStatus(err)
+	}
+	var nextPageToken string
+	if len(storageEntries) > limit {
+		storageEntries = storageEntries[:limit]
+		nextPageToken = pageToken(storageEntries[limit-1].ID)
+	}
+	entries := make([]modeldto.EmergencyEntry, 0, len(storageEntries))
+	for _, storageEntry := range storageEntries {
+		identifier, err := proc.cipher.Decode(storageEntry.Identifier)
+		if err != nil {
+			return nil, "", err
+		}
+		entry := modeldto.EmergencyEntry{Identifier: identifier, Db: proc.requestDB(storageEntry.Db), Payload: storageEntry.Payload, SealedAt: storageEntry.SealedAt}
+		for _, key := range storageEntry.Keys {
+			entry.WrappedKey = key.WrappedKey
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nextPageToken, nil
+}
+
+// GrantDueEmergencyAccess grants requests of emergency access whose waiting periods are over and returns the amount of
+// granted requests. Requests which could not be granted are retried on the next call, the last failure is returned.
+func (proc *Processor) GrantDueEmergencyAccess(ctx context.Context) (int, error) {
+	due, err := proc.storage.GetDueEmergencyAccess(ctx)
+	if err != nil {
+		return 0, storageErrors.ToStatus(err)
+	}
+	var granted int
+	var lastErr error
+	for _, access := range due {
+		err = proc.storage.GrantEmergencyAccess(ctx, access.ID)
+		// the owner might have rejected the request meanwhile
+		var notFound *storageErrors.NotFoundError
+		if errors.As(err, &notFound) {
+			continue
+		}
+		if err != nil {
+			proc.logger.Error().Err(err).Msgf("Could not grant emergency access %d", access.ID)
+			lastErr = err
+			continue
+		}
+		proc.recordOwnerEvent(ctx, access.OwnerID, emergencyTimerMethod)
+		granted++
+	}
+	return granted, lastErr
+}
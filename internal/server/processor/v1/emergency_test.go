@@ -0,0 +1,158 @@
+package processor
+
+import (
+	"context"
+	"dk-go-gophkeeper/internal/mocks"
+	"dk-go-gophkeeper/internal/server/modeldto"
+	storageErrors "dk-go-gophkeeper/internal/server/storage/errors"
+	"dk-go-gophkeeper/internal/server/storage/modelstorage"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProcessor_SetTrustedContact(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cipher := mocks.NewMockCipher(ctrl)
+	storage := mocks.NewMockDataStorage(ctrl)
+	cipher.EXPECT().Encode(gomock.Any()).DoAndReturn(func(data string) string { return "encoded_" + data }).AnyTimes()
+	cipher.EXPECT().ValidateToken("alice_token").Return("alice_id", nil).Times(3)
+	storage.EXPECT().GetUserIDByLogin(gomock.Any(), "encoded_bob").Return("bob_id", nil)
+	storage.EXPECT().GetUserIDByLogin(gomock.Any(), "encoded_alice").Return("alice_id", nil)
+	storage.EXPECT().GetUserIDByLogin(gomock.Any(), "encoded_carol").Return("", &storageErrors.NotFoundError{})
+	// the default waiting period is used
+	storage.EXPECT().SetTrustedContact(gomock.Any(), "alice_id", "bob_id", defaultEmergencyWaitDays).Return(nil)
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	processor := InitService(storage, cipher, &logger)
+	err := processor.SetTrustedContact(context.Background(), "alice_token", "bob", 0)
+	assert.Equal(t, nil, err)
+	err = processor.SetTrustedContact(context.Background(), "alice_token", "alice", 3)
+	assert.Equal(t, "rpc error: code = InvalidArgument desc = owners cannot be their own trusted contacts", err.Error())
+	err = processor.SetTrustedContact(context.Background(), "alice_token", "carol", 3)
+	assert.Equal(t, "rpc error: code = NotFound desc = user carol is not registered", err.Error())
+	err = processor.SetTrustedContact(context.Background(), "alice_token", "bob", 91)
+	assert.Equal(t, "rpc error: code = InvalidArgument desc = waiting period must be from 1 to 90 days", err.Error())
+}
+
+func TestProcessor_RequestEmergencyAccess(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cipher := mocks.NewMockCipher(ctrl)
+	storage := mocks.NewMockDataStorage(ctrl)
+	cipher.EXPECT().Encode(gomock.Any()).DoAndReturn(func(data string) string { return "encoded_" + data }).AnyTimes()
+	cipher.EXPECT().ValidateToken("bob_token").Return("bob_id", nil).Times(3)
+	storage.EXPECT().GetUserIDByLogin(gomock.Any(), "encoded_alice").Return("alice_id", nil).Times(2)
+	storage.EXPECT().GetUserIDByLogin(gomock.Any(), "encoded_carol").Return("carol_id", nil)
+	storage.EXPECT().GetEmergencyAccess(gomock.Any(), "alice_id", "bob_id").Return(modelstorage.EmergencyAccess{
+		ID: 4, OwnerID: "alice_id", ContactID: "bob_id", WaitDays: 7, Status: modeldto.EmergencyDesignated,
+	}, nil)
+	storage.EXPECT().RequestEmergencyAccess(gomock.Any(), "alice_id", "bob_id").Return(nil)
+	// the owner learns about the request from the audit log
+	storage.EXPECT().AddAuditEvent(gomock.Any(), modelstorage.AuditEvent{
+		UserID: "alice_id", Action: modeldto.AuditEmergency, Method: "RequestEmergencyAccess", Code: "OK",
+	}).Return(modelstorage.AuditEvent{Seq: 1}, nil)
+	storage.EXPECT().GetEmergencyAccess(gomock.Any(), "alice_id", "bob_id").Return(modelstorage.EmergencyAccess{
+		ID: 4, OwnerID: "alice_id", ContactID: "bob_id", WaitDays: 7, Status: modeldto.EmergencyRequested,
+	}, nil)
+	storage.EXPECT().GetEmergencyAccess(gomock.Any(), "carol_id", "bob_id").Return(modelstorage.EmergencyAccess{}, &storageErrors.NotFoundError{})
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	processor := InitService(storage, cipher, &logger)
+	err := processor.RequestEmergencyAccess(context.Background(), "bob_token", "alice")
+	assert.Equal(t, nil, err)
+	err = processor.RequestEmergencyAccess(context.Background(), "bob_token", "alice")
+	assert.Equal(t, "rpc error: code = AlreadyExists desc = emergency access to the vault of alice is already requested", err.Error())
+	err = processor.RequestEmergencyAccess(context.Background(), "bob_token", "carol")
+	assert.Equal(t, "rpc error: code = NotFound desc = user carol has not designated you as a trusted contact", err.Error())
+}
+
+func TestProcessor_GrantDueEmergencyAccess(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cipher := mocks.NewMockCipher(ctrl)
+	storage := mocks.NewMockDataStorage(ctrl)
+	cipher.EXPECT().Encode(gomock.Any()).DoAndReturn(func(data string) string { return "encoded_" + data }).AnyTimes()
+	storage.EXPECT().GetDueEmergencyAccess(gomock.Any()).Return([]modelstorage.EmergencyAccess{
+		{ID: 4, OwnerID: "alice_id", ContactID: "bob_id", WaitDays: 7, Status: modeldto.EmergencyRequested},
+		{ID: 5, OwnerID: "carol_id", ContactID: "bob_id", WaitDays: 1, Status: modeldto.EmergencyRequested},
+	}, nil)
+	// granting only changes the state of access, entries stay as the client of the owner sealed them
+	storage.EXPECT().GrantEmergencyAccess(gomock.Any(), int64(4)).Return(nil)
+	// the second request was rejected meanwhile
+	storage.EXPECT().GrantEmergencyAccess(gomock.Any(), int64(5)).Return(&storageErrors.NotFoundError{})
+	storage.EXPECT().AddAuditEvent(gomock.Any(), modelstorage.AuditEvent{
+		UserID: "alice_id", Action: modeldto.AuditEmergency, Method: emergencyTimerMethod, Code: "OK",
+	}).Return(modelstorage.AuditEvent{Seq: 1}, nil)
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	processor := InitService(storage, cipher, &logger)
+	granted, err := processor.GrantDueEmergencyAccess(context.Background())
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, granted)
+}
+
+func TestProcessor_SetEmergencyEntries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cipher := mocks.NewMockCipher(ctrl)
+	storage := mocks.NewMockDataStorage(ctrl)
+	cipher.EXPECT().Encode(gomock.Any()).DoAndReturn(func(data string) string { return "encoded_" + data }).AnyTimes()
+	cipher.EXPECT().Decode(gomock.Any()).DoAndReturn(func(data string) (string, error) { return strings.TrimPrefix(data, "encoded_"), nil }).AnyTimes()
+	cipher.EXPECT().ValidateToken("alice_token").Return("alice_id", nil).Times(2)
+	storage.EXPECT().GetTrustedContacts(gomock.Any(), "alice_id").Return([]modelstorage.EmergencyAccess{
+		{ID: 4, OwnerID: "alice_id", ContactID: "bob_id", ContactLogin: "encoded_bob", Status: modeldto.EmergencyDesignated},
+		{ID: 5, OwnerID: "alice_id", ContactID: "carol_id", ContactLogin: "encoded_carol", Status: modeldto.EmergencyGranted},
+	}, nil).Times(2)
+	// the server stores ciphertexts and wrapped keys only, entries are keyed by the access token of the owner
+	storage.EXPECT().SetEmergencyEntries(gomock.Any(), "alice_token", []modelstorage.EmergencyEntry{{
+		Db: "loginPassword", Identifier: "encoded_db", Payload: "sealed_db",
+		Keys: []modelstorage.EmergencyKey{{AccessID: 4, WrappedKey: "bob_wrapped"}, {AccessID: 5, WrappedKey: "carol_wrapped"}},
+	}}).Return(nil)
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	processor := InitService(storage, cipher, &logger)
+	err := processor.SetEmergencyEntries(context.Background(), "alice_token", []modeldto.EmergencyEntry{{
+		Identifier: "db", Db: "loginPassword", Payload: "sealed_db",
+		WrappedKeys: map[string]string{"carol": "carol_wrapped", "bob": "bob_wrapped"},
+	}})
+	assert.Equal(t, nil, err)
+	err = processor.SetEmergencyEntries(context.Background(), "alice_token", []modeldto.EmergencyEntry{{
+		Identifier: "db", Db: "loginPassword", Payload: "sealed_db", WrappedKeys: map[string]string{"dave": "dave_wrapped"},
+	}})
+	assert.Equal(t, "rpc error: code = FailedPrecondition desc = user dave is not a trusted contact", err.Error())
+}
+
+func TestProcessor_GetEmergencyVault(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cipher := mocks.NewMockCipher(ctrl)
+	storage := mocks.NewMockDataStorage(ctrl)
+	sealedAt := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	cipher.EXPECT().Encode(gomock.Any()).DoAndReturn(func(data string) string { return "encoded_" + data }).AnyTimes()
+	cipher.EXPECT().Decode(gomock.Any()).DoAndReturn(func(data string) (string, error) { return strings.TrimPrefix(data, "encoded_"), nil }).AnyTimes()
+	cipher.EXPECT().ValidateToken("bob_token").Return("bob_id", nil).Times(2)
+	storage.EXPECT().GetUserIDByLogin(gomock.Any(), "encoded_alice").Return("alice_id", nil).Times(2)
+	storage.EXPECT().GetEmergencyAccess(gomock.Any(), "alice_id", "bob_id").Return(modelstorage.EmergencyAccess{
+		ID: 4, OwnerID: "alice_id", ContactID: "bob_id", Status: modeldto.EmergencyGranted, GrantedAt: sealedAt,
+	}, nil)
+	// one extra entry tells there is a next page
+	storage.EXPECT().GetEmergencyVault(gomock.Any(), int64(4), int64(0), 2).Return([]modelstorage.EmergencyEntry{
+		{ID: 7, Db: "loginPassword", Identifier: "encoded_db", Payload: "sealed_db", SealedAt: sealedAt, Keys: []modelstorage.EmergencyKey{{AccessID: 4, WrappedKey: "bob_wrapped"}}},
+		{ID: 9, Db: "textBinary", Identifier: "encoded_note", Payload: "sealed_note", SealedAt: sealedAt, Keys: []modelstorage.EmergencyKey{{AccessID: 4, WrappedKey: "bob_wrapped"}}},
+	}, nil)
+	storage.EXPECT().GetEmergencyAccess(gomock.Any(), "alice_id", "bob_id").Return(modelstorage.EmergencyAccess{
+		ID: 4, OwnerID: "alice_id", ContactID: "bob_id", Status: modeldto.EmergencyRequested,
+	}, nil)
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	processor := InitService(storage, cipher, &logger)
+	// entries are opened by the trusted contact only
+	entries, next, err := processor.GetEmergencyVault(context.Background(), "bob_token", "alice", "", 1)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "7", next)
+	assert.Equal(t, []modeldto.EmergencyEntry{{Identifier: "db", Db: "loginPassword", Payload: "sealed_db", WrappedKey: "bob_wrapped", SealedAt: sealedAt}}, entries)
+	_, _, err = processor.GetEmergencyVault(context.Background(), "bob_token", "alice", "", 1)
+	assert.Equal(t, "rpc error: code = PermissionDenied desc = emergency access to the vault of alice is not granted", err.Error())
+}
@@ -0,0 +1,31 @@
+package processor
+
+import (
+	"dk-go-gophkeeper/internal/validation"
+	"errors"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// invalidArgument converts a validation error into an InvalidArgument status, field violations are attached as
+// BadRequest details so that clients can show them field by field.
+func invalidArgument(err error) error {
+	var violations validation.Errors
+	if !errors.As(err, &violations) {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	badRequest := &errdetails.BadRequest{}
+	for _, violation := range violations {
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       violation.Field,
+			Description: violation.Description,
+		})
+	}
+	st, detailsErr := status.New(codes.InvalidArgument, violations.Error()).WithDetails(badRequest)
+	if detailsErr != nil {
+		return status.Error(codes.InvalidArgument, violations.Error())
+	}
+	return st.Err()
+}
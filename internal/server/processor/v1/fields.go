@@ -0,0 +1,97 @@
+package processor
+
+import (
+	"dk-go-gophkeeper/internal/server/modeldto"
+	"dk-go-gophkeeper/internal/server/storage/modelstorage"
+	"dk-go-gophkeeper/internal/validation"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// maxCustomFields limits the amount of custom fields of a single entry.
+const maxCustomFields = 100
+
+// cleanFields validates custom fields of an entry: names are trimmed, must not be empty and must be unique within
+// the entry, values are limited in size, the order of fields is kept.
+func cleanFields(fields []modeldto.CustomField) ([]modeldto.CustomField, error) {
+	if len(fields) > maxCustomFields {
+		return nil, fieldViolation(validation.FieldFields, "at most %d custom fields are allowed", maxCustomFields)
+	}
+	seen := make(map[string]bool, len(fields))
+	var cleaned []modeldto.CustomField
+	for idx, field := range fields {
+		field.Name = strings.TrimSpace(field.Name)
+		if field.Name == "" {
+			return nil, fieldViolation(validation.FieldFields, "name of custom field %d cannot be empty", idx+1)
+		}
+		if seen[field.Name] {
+			return nil, fieldViolation(validation.FieldFields, "duplicate custom field %s", field.Name)
+		}
+		if len(field.Value) > validation.MaxFieldValueLength {
+			return nil, fieldViolation(validation.FieldFields, "value of custom field %s must not exceed %d bytes", field.Name, validation.MaxFieldValueLength)
+		}
+		seen[field.Name] = true
+		cleaned = append(cleaned, field)
+	}
+	return cleaned, nil
+}
+
+// fieldViolation returns an InvalidArgument status of a single field violation.
+func fieldViolation(field, format string, args ...interface{}) error {
+	return invalidArgument(validation.Errors{{Field: field, Description: fmt.Sprintf(format, args...)}})
+}
+
+// encodeFields performs an encoding of every name and value of custom fields and serializes them, an entry without
+// custom fields is stored as an empty string.
+func (proc *Processor) encodeFields(fields []modeldto.CustomField) (string, error) {
+	if len(fields) == 0 {
+		return "", nil
+	}
+	encoded := make([]modelstorage.CustomField, 0, len(fields))
+	for _, field := range fields {
+		encoded = append(encoded, modelstorage.CustomField{
+			Name:      proc.cipher.Encode(field.Name),
+			Value:     proc.cipher.Encode(field.Value),
+			Concealed: field.Concealed,
+		})
+	}
+	data, err := json.Marshal(encoded)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// prepareFields validates and encodes custom fields of an entry to be stored.
+func (proc *Processor) prepareFields(fields []modeldto.CustomField) (string, error) {
+	fields, err := cleanFields(fields)
+	if err != nil {
+		return "", err
+	}
+	return proc.encodeFields(fields)
+}
+
+// decodeFields performs a decoding of custom fields of a stored entry.
+func (proc *Processor) decodeFields(fields string) ([]modeldto.CustomField, error) {
+	if fields == "" {
+		return nil, nil
+	}
+	var encoded []modelstorage.CustomField
+	if err := json.Unmarshal([]byte(fields), &encoded); err != nil {
+		return nil, err
+	}
+	decoded := make([]modeldto.CustomField, 0, len(encoded))
+	for _, field := range encoded {
+		name, err := proc.cipher.Decode(field.Name)
+		if err != nil {
+			return nil, err
+		}
+		value, err := proc.cipher.Decode(field.Value)
+		if err != nil {
+			return nil, err
+		}
+		decoded = append(decoded, modeldto.CustomField{Name: name, Value: value, Concealed: field.Concealed})
+	}
+	return decoded, nil
+}
@@ -0,0 +1,77 @@
+package processor
+
+import (
+	"dk-go-gophkeeper/internal/server/modeldto"
+	"dk-go-gophkeeper/internal/server/storage/modelstorage"
+	"strings"
+)
+
+// separators of labels
+const (
+	folderSeparator = "/"
+	tagSeparator    = ","
+)
+
+// cleanFolder normalizes a folder path: its segments are trimmed and empty segments are dropped.
+func cleanFolder(folder string) string {
+	var segments []string
+	for _, segment := range strings.Split(folder, folderSeparator) {
+		if segment = strings.TrimSpace(segment); segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	return strings.Join(segments, folderSeparator)
+}
+
+// cleanTags normalizes tags: they are trimmed, lowercased and deduplicated, a leading # is dropped.
+func cleanTags(tags []string) []string {
+	seen := make(map[string]bool)
+	var cleaned []string
+	for _, tag := range tags {
+		for _, part := range strings.Split(tag, tagSeparator) {
+			part = strings.TrimLeft(strings.ToLower(strings.TrimSpace(part)), "#")
+			if part != "" && !seen[part] {
+				seen[part] = true
+				cleaned = append(cleaned, part)
+			}
+		}
+	}
+	return cleaned
+}
+
+// cleanLabels normalizes labels of an entry.
+func cleanLabels(labels modeldto.Labels) modeldto.Labels {
+	return modeldto.Labels{Folder: cleanFolder(labels.Folder), Tags: cleanTags(labels.Tags), Favorite: labels.Favorite}
+}
+
+// encodeLabels performs an encoding of labels of an entry, empty labels are stored as empty strings.
+func (proc *Processor) encodeLabels(labels modeldto.Labels) modelstorage.Labels {
+	encoded := modelstorage.Labels{Favorite: labels.Favorite}
+	if labels.Folder != "" {
+		encoded.Folder = proc.cipher.Encode(labels.Folder)
+	}
+	if len(labels.Tags) > 0 {
+		encoded.Tags = proc.cipher.Encode(strings.Join(labels.Tags, tagSeparator))
+	}
+	return encoded
+}
+
+// decodeLabels performs a decoding of labels of a stored entry.
+func (proc *Processor) decodeLabels(labels modelstorage.Labels) (modeldto.Labels, error) {
+	decoded := modeldto.Labels{Favorite: labels.Favorite}
+	if labels.Folder != "" {
+		folder, err := proc.cipher.Decode(labels.Folder)
+		if err != nil {
+			return modeldto.Labels{}, err
+		}
+		decoded.Folder = folder
+	}
+	if labels.Tags != "" {
+		tags, err := proc.cipher.Decode(labels.Tags)
+		if err != nil {
+			return modeldto.Labels{}, err
+		}
+		decoded.Tags = strings.Split(tags, tagSeparator)
+	}
+	return decoded, nil
+}
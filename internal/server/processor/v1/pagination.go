@@ -0,0 +1,65 @@
+package processor
+
+import (
+	"dk-go-gophkeeper/internal/server/storage/modelstorage"
+	"encoding/base64"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// page size limits of paginated requests
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+)
+
+// parsePage returns the position encoded in a page token and the page size clamped to the allowed range.
+func parsePage(pageToken string, pageSize int) (int64, int, error) {
+	var cursor int64
+	if pageToken != "" {
+		var err error
+		cursor, err = strconv.ParseInt(pageToken, 10, 64)
+		if err != nil || cursor <= 0 {
+			return 0, 0, status.Error(codes.InvalidArgument, "invalid page token")
+		}
+	}
+	return cursor, pageLimit(pageSize), nil
+}
+
+// pageLimit returns the page size clamped to the allowed range.
+func pageLimit(pageSize int) int {
+	switch {
+	case pageSize <= 0:
+		return defaultPageSize
+	case pageSize > maxPageSize:
+		return maxPageSize
+	}
+	return pageSize
+}
+
+// parseSearchPage returns the search position encoded in a page token and the page size clamped to the allowed range.
+func parseSearchPage(pageToken string, pageSize int) (modelstorage.SearchCursor, int, error) {
+	var cursor modelstorage.SearchCursor
+	if pageToken != "" {
+		decoded, err := base64.RawURLEncoding.DecodeString(pageToken)
+		db, identifier, found := strings.Cut(string(decoded), "/")
+		if err != nil || !found || db == "" || identifier == "" {
+			return cursor, 0, status.Error(codes.InvalidArgument, "invalid page token")
+		}
+		cursor = modelstorage.SearchCursor{Db: db, Identifier: identifier}
+	}
+	return cursor, pageLimit(pageSize), nil
+}
+
+// pageToken returns a token of the page following the given position.
+func pageToken(position int64) string {
+	return strconv.FormatInt(position, 10)
+}
+
+// searchPageToken returns a token of the search page following the given position.
+func searchPageToken(cursor modelstorage.SearchCursor) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(cursor.Db + "/" + cursor.Identifier))
+}
@@ -7,9 +7,22 @@ import (
 	"dk-go-gophkeeper/internal/server/modeldto"
 	"dk-go-gophkeeper/internal/server/processor"
 	"dk-go-gophkeeper/internal/server/storage"
+	storageErrors "dk-go-gophkeeper/internal/server/storage/errors"
 	"dk-go-gophkeeper/internal/server/storage/modelstorage"
+	"dk-go-gophkeeper/internal/validation"
+	"time"
 
 	"github.com/rs/zerolog"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DB identifiers entries are stored under, requests refer to them by the configured DB names
+
+const (
+	batchBankCardDB      = "bankCard"
+	batchLoginPasswordDB = "loginPassword"
+	batchTextBinaryDB    = "textBinary"
 )
 
 // check for interface compliance
@@ -19,72 +32,157 @@ var (
 
 // Processor defines methods and attributes of a Processor instance.
 type Processor struct {
-	storage storage.DataStorage
-	cipher  cipher.Cipher
-	logger  *zerolog.Logger
+	storage        storage.DataStorage
+	cipher         cipher.Cipher
+	logger         *zerolog.Logger
+	maxEntryLength int
+	// dbNames maps configured DB names to DB identifiers entries are stored under
+	dbNames map[string]string
+	// sessions expire once they are idle or old for longer than these limits, zero limits are not applied
+	sessionIdleTTL time.Duration
+	sessionMaxAge  time.Duration
 }
 
 // InitService initializes a Processor instance.
 func InitService(st storage.DataStorage, cp cipher.Cipher, logger *zerolog.Logger) *Processor {
 	logger.Info().Msg("Attempting to initialize processor")
 	serviceProcessor := &Processor{
-		storage: st,
-		cipher:  cp,
-		logger:  logger,
+		storage:        st,
+		cipher:         cp,
+		logger:         logger,
+		maxEntryLength: validation.MaxEntryLength,
 	}
+	serviceProcessor.SetDBNames(batchBankCardDB, batchLoginPasswordDB, batchTextBinaryDB)
 	return serviceProcessor
 }
 
+// SetDBNames sets DB names requests refer to entries of each type by, entries are stored under fixed DB identifiers
+// regardless of them.
+func (proc *Processor) SetDBNames(bankCard, loginPassword, textBinary string) {
+	proc.dbNames = map[string]string{
+		bankCard:      batchBankCardDB,
+		loginPassword: batchLoginPasswordDB,
+		textBinary:    batchTextBinaryDB,
+	}
+}
+
+// storageDB returns a DB identifier entries of the named DB are stored under, it is empty for unknown DBs.
+func (proc *Processor) storageDB(db string) string {
+	return proc.dbNames[db]
+}
+
+// requestDB returns a DB name requests refer to entries stored under the given DB identifier by.
+func (proc *Processor) requestDB(db string) string {
+	for name, storageDB := range proc.dbNames {
+		if storageDB == db {
+			return name
+		}
+	}
+	return db
+}
+
+// SetMaxEntryLength limits the size of text/binary entries in bytes, a limit which is not positive keeps the default.
+// Limits exceeding validation.MaxEntryLength are ignored, since larger entries would not fit into a gRPC message anyway.
+func (proc *Processor) SetMaxEntryLength(limit int) {
+	if limit <= 0 {
+		return
+	}
+	if limit > validation.MaxEntryLength {
+		proc.logger.Warn().Msgf("Ignoring text/binary entry limit of %d bytes, %d bytes are allowed at most", limit, validation.MaxEntryLength)
+		return
+	}
+	proc.maxEntryLength = limit
+}
+
 // GetUserID validates authorization token in a login request.
 func (proc *Processor) GetUserID(accessToken string) (string, error) {
 	userID, err := proc.cipher.ValidateToken(accessToken)
 	return userID, err
 }
 
+// accountID returns an ID a user is registered with. Handlers identify users by their access tokens, which key their
+// entries, while shares and key pairs refer to registered IDs, so that logins of users can be joined to them.
+func (proc *Processor) accountID(userID string) (string, error) {
+	accountID, err := proc.cipher.ValidateToken(userID)
+	if err != nil {
+		return "", status.Error(codes.Unauthenticated, "invalid access token")
+	}
+	return accountID, nil
+}
+
 // AddNewUser performs a registering procedure of a new user.
 func (proc *Processor) AddNewUser(ctx context.Context, login, password string) (string, error) {
 	accessToken, userID := proc.cipher.NewToken()
 	err := proc.storage.AddNewUser(ctx, proc.cipher.Encode(login), proc.cipher.Encode(password), userID)
-	return accessToken, err
+	if err != nil {
+		return "", storageErrors.ToStatus(err)
+	}
+	return accessToken, nil
 }
 
 // LoginUser performs a login procedure of an existing user.
 func (proc *Processor) LoginUser(ctx context.Context, login, password string) (string, error) {
 	userID, err := proc.storage.CheckUser(ctx, proc.cipher.Encode(login), proc.cipher.Encode(password))
 	if err != nil {
-		return "", err
+		return "", storageErrors.ToStatus(err)
 	}
 	accessToken := proc.cipher.Encode(userID)
 	return accessToken, nil
 }
 
-// GetBankCardData performs a retrieval of all bank card entries and their decoding.
-func (proc *Processor) GetBankCardData(ctx context.Context, userID string) ([]modeldto.BankCard, error) {
-	bankCards, err := proc.storage.GetBankCardData(ctx, userID)
+// GetBankCardData performs a retrieval of a page of bank card entries matching the filter and their decoding.
+func (proc *Processor) GetBankCardData(ctx context.Context, userID, token string, pageSize int, filter modeldto.Filter) ([]modeldto.BankCard, string, error) {
+	cursor, limit, err := parsePage(token, pageSize)
 	if err != nil {
-		return nil, err
+		return nil, "", err
+	}
+	// one extra entry tells whether there is a next page
+	bankCards, err := proc.storage.GetBankCardData(ctx, userID, cursor, limit+1, proc.tokens(userID, filterTerms(filter)))
+	if err != nil {
+		return nil, "", storageErrors.ToStatus(err)
+	}
+	var nextPageToken string
+	if len(bankCards) > limit {
+		bankCards = bankCards[:limit]
+		nextPageToken = pageToken(int64(bankCards[limit-1].ID))
 	}
 	var responseBankCards []modeldto.BankCard
 	for _, bankCard := range bankCards {
 		decodedIdentifier, err := proc.cipher.Decode(bankCard.Identifier)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
 		decodedNumber, err := proc.cipher.Decode(bankCard.Number)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
 		decodedHolder, err := proc.cipher.Decode(bankCard.Holder)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
 		decodedCVV, err := proc.cipher.Decode(bankCard.CVV)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
 		decodedMeta, err := proc.cipher.Decode(bankCard.Meta)
 		if err != nil {
-			return nil, err
+			return nil, "", err
+		}
+		decodedLabels, err := proc.decodeLabels(bankCard.Labels)
+		if err != nil {
+			return nil, "", err
+		}
+		decodedFields, err := proc.decodeFields(bankCard.Fields)
+		if err != nil {
+			return nil, "", err
+		}
+		decodedExpiry, err := proc.decodeOptional(bankCard.Expiry)
+		if err != nil {
+			return nil, "", err
+		}
+		decodedPIN, err := proc.decodeOptional(bankCard.PIN)
+		if err != nil {
+			return nil, "", err
 		}
 		responseBankCard := modeldto.BankCard{
 			Identifier: decodedIdentifier,
@@ -92,105 +190,297 @@ func (proc *Processor) GetBankCardData(ctx context.Context, userID string) ([]mo
 			Holder:     decodedHolder,
 			CVV:        decodedCVV,
 			Meta:       decodedMeta,
+			Labels:     decodedLabels,
+			Fields:     decodedFields,
+			Revision:   modeldto.Revision{CreatedAt: bankCard.CreatedAt, UpdatedAt: bankCard.UpdatedAt},
+			Expiry:     decodedExpiry,
+			PIN:        decodedPIN,
 		}
 		responseBankCards = append(responseBankCards, responseBankCard)
 	}
-	return responseBankCards, nil
+	return responseBankCards, nextPageToken, nil
 }
 
-// GetLoginPasswordData performs a retrieval of all login/password entries and their decoding.
-func (proc *Processor) GetLoginPasswordData(ctx context.Context, userID string) ([]modeldto.LoginPassword, error) {
-	loginsPasswords, err := proc.storage.GetLoginPasswordData(ctx, userID)
+// GetLoginPasswordData performs a retrieval of a page of login/password entries matching the filter and their decoding.
+func (proc *Processor) GetLoginPasswordData(ctx context.Context, userID, token string, pageSize int, filter modeldto.Filter) ([]modeldto.LoginPassword, string, error) {
+	cursor, limit, err := parsePage(token, pageSize)
+	if err != nil {
+		return nil, "", err
+	}
+	// one extra entry tells whether there is a next page
+	loginsPasswords, err := proc.storage.GetLoginPasswordData(ctx, userID, cursor, limit+1, proc.tokens(userID, filterTerms(filter)))
 	if err != nil {
-		return nil, err
+		return nil, "", storageErrors.ToStatus(err)
+	}
+	var nextPageToken string
+	if len(loginsPasswords) > limit {
+		loginsPasswords = loginsPasswords[:limit]
+		nextPageToken = pageToken(int64(loginsPasswords[limit-1].ID))
 	}
 	var responseLoginsPasswords []modeldto.LoginPassword
 	for _, loginPassword := range loginsPasswords {
 		decodedIdentifier, err := proc.cipher.Decode(loginPassword.Identifier)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
 		decodedLogin, err := proc.cipher.Decode(loginPassword.Login)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
 		decodedPassword, err := proc.cipher.Decode(loginPassword.Password)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
 		decodedMeta, err := proc.cipher.Decode(loginPassword.Meta)
 		if err != nil {
-			return nil, err
+			return nil, "", err
+		}
+		decodedLabels, err := proc.decodeLabels(loginPassword.Labels)
+		if err != nil {
+			return nil, "", err
+		}
+		decodedFields, err := proc.decodeFields(loginPassword.Fields)
+		if err != nil {
+			return nil, "", err
 		}
 		responseLoginPassword := modeldto.LoginPassword{
-			Identifier: decodedIdentifier,
-			Login:      decodedLogin,
-			Password:   decodedPassword,
-			Meta:       decodedMeta,
+			Identifier:        decodedIdentifier,
+			Login:             decodedLogin,
+			Password:          decodedPassword,
+			Meta:              decodedMeta,
+			Labels:            decodedLabels,
+			Fields:            decodedFields,
+			Revision:          modeldto.Revision{CreatedAt: loginPassword.CreatedAt, UpdatedAt: loginPassword.UpdatedAt},
+			PasswordChangedAt: loginPassword.PasswordChangedAt,
 		}
 		responseLoginsPasswords = append(responseLoginsPasswords, responseLoginPassword)
 	}
-	return responseLoginsPasswords, nil
+	return responseLoginsPasswords, nextPageToken, nil
 }
 
-// GetTextBinaryData performs a retrieval of all text/binary entries and their decoding.
-func (proc *Processor) GetTextBinaryData(ctx context.Context, userID string) ([]modeldto.TextBinary, error) {
-	textsBinaries, err := proc.storage.GetTextBinaryData(ctx, userID)
+// GetTextBinaryData performs a retrieval of a page of text/binary entries matching the filter and their decoding.
+func (proc *Processor) GetTextBinaryData(ctx context.Context, userID, token string, pageSize int, filter modeldto.Filter) ([]modeldto.TextBinary, string, error) {
+	cursor, limit, err := parsePage(token, pageSize)
+	if err != nil {
+		return nil, "", err
+	}
+	// one extra entry tells whether there is a next page
+	textsBinaries, err := proc.storage.GetTextBinaryData(ctx, userID, cursor, limit+1, proc.tokens(userID, filterTerms(filter)))
 	if err != nil {
-		return nil, err
+		return nil, "", storageErrors.ToStatus(err)
+	}
+	var nextPageToken string
+	if len(textsBinaries) > limit {
+		textsBinaries = textsBinaries[:limit]
+		nextPageToken = pageToken(int64(textsBinaries[limit-1].ID))
 	}
 	var responseTextsBinaries []modeldto.TextBinary
 	for _, textBinary := range textsBinaries {
 		decodedIdentifier, err := proc.cipher.Decode(textBinary.Identifier)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
 		decodedEntry, err := proc.cipher.Decode(textBinary.Entry)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
 		decodedMeta, err := proc.cipher.Decode(textBinary.Meta)
 		if err != nil {
-			return nil, err
+			return nil, "", err
+		}
+		decodedLabels, err := proc.decodeLabels(textBinary.Labels)
+		if err != nil {
+			return nil, "", err
+		}
+		decodedFields, err := proc.decodeFields(textBinary.Fields)
+		if err != nil {
+			return nil, "", err
 		}
 		responsetextBinary := modeldto.TextBinary{
 			Identifier: decodedIdentifier,
 			Entry:      decodedEntry,
 			Meta:       decodedMeta,
+			Labels:     decodedLabels,
+			Fields:     decodedFields,
+			Revision:   modeldto.Revision{CreatedAt: textBinary.CreatedAt, UpdatedAt: textBinary.UpdatedAt},
 		}
 		responseTextsBinaries = append(responseTextsBinaries, responsetextBinary)
 	}
-	return responseTextsBinaries, nil
+	return responseTextsBinaries, nextPageToken, nil
 }
 
-// SetBankCardData performs an encoding of a bank card entry and sends it to storage.
-func (proc *Processor) SetBankCardData(ctx context.Context, userID, identifier, number, holder, cvv, meta string) error {
+// SetBankCardData performs an encoding of a bank card entry and sends it to storage along with its search tokens.
+func (proc *Processor) SetBankCardData(ctx context.Context, userID, identifier, number, holder, cvv, expiry, pin, meta string, labels modeldto.Labels, fields []modeldto.CustomField) error {
+	number, expiry, err := prepareBankCard(identifier, number, holder, cvv, expiry, pin, meta)
+	if err != nil {
+		return err
+	}
+	labels = cleanLabels(labels)
+	encodedFields, err := proc.prepareFields(fields)
+	if err != nil {
+		return err
+	}
 	encodedIndentifier := proc.cipher.Encode(identifier)
 	encodedNumber := proc.cipher.Encode(number)
 	encodedHolder := proc.cipher.Encode(holder)
 	encodedCvv := proc.cipher.Encode(cvv)
 	encodedMeta := proc.cipher.Encode(meta)
-	err := proc.storage.SetBankCardData(ctx, userID, encodedIndentifier, encodedNumber, encodedHolder, encodedCvv, encodedMeta)
-	return err
+	err = proc.storage.SetBankCardData(ctx, userID, encodedIndentifier, encodedNumber, encodedHolder, encodedCvv, proc.encodeOptional(expiry), proc.encodeOptional(pin), encodedMeta, proc.encodeLabels(labels), encodedFields, proc.entryTokens(userID, meta, labels))
+	return storageErrors.ToStatus(err)
 }
 
-// SetLoginPasswordData performs an encoding of a login/password entry and sends it to storage.
-func (proc *Processor) SetLoginPasswordData(ctx context.Context, userID, identifier, login, password, meta string) error {
+// SetLoginPasswordData performs an encoding of a login/password entry and sends it to storage along with its search tokens.
+func (proc *Processor) SetLoginPasswordData(ctx context.Context, userID, identifier, login, password, meta string, labels modeldto.Labels, fields []modeldto.CustomField) error {
+	if err := validation.ValidateLoginPassword(identifier, login, password, meta); err != nil {
+		return invalidArgument(err)
+	}
+	labels = cleanLabels(labels)
+	encodedFields, err := proc.prepareFields(fields)
+	if err != nil {
+		return err
+	}
 	encodedIndentifier := proc.cipher.Encode(identifier)
 	encodedLogin := proc.cipher.Encode(login)
 	encodedPassword := proc.cipher.Encode(password)
 	encodedMeta := proc.cipher.Encode(meta)
-	err := proc.storage.SetLoginPasswordData(ctx, userID, encodedIndentifier, encodedLogin, encodedPassword, encodedMeta)
-	return err
+	err = proc.storage.SetLoginPasswordData(ctx, userID, encodedIndentifier, encodedLogin, encodedPassword, encodedMeta, proc.encodeLabels(labels), encodedFields, proc.entryTokens(userID, meta, labels))
+	return storageErrors.ToStatus(err)
 }
 
-// SetTextBinaryData performs an encoding of a text/binary entry and sends it to storage.
-func (proc *Processor) SetTextBinaryData(ctx context.Context, userID, identifier, entry, meta string) error {
+// SetTextBinaryData performs an encoding of a text/binary entry and sends it to storage along with its search tokens.
+func (proc *Processor) SetTextBinaryData(ctx context.Context, userID, identifier, entry, meta string, labels modeldto.Labels, fields []modeldto.CustomField) error {
+	if err := validation.ValidateTextBinary(identifier, entry, meta, proc.maxEntryLength); err != nil {
+		return invalidArgument(err)
+	}
+	labels = cleanLabels(labels)
+	encodedFields, err := proc.prepareFields(fields)
+	if err != nil {
+		return err
+	}
 	encodedIndentifier := proc.cipher.Encode(identifier)
 	encodedEntry := proc.cipher.Encode(entry)
 	encodedMeta := proc.cipher.Encode(meta)
-	err := proc.storage.SetTextBinaryData(ctx, userID, encodedIndentifier, encodedEntry, encodedMeta)
-	return err
+	err = proc.storage.SetTextBinaryData(ctx, userID, encodedIndentifier, encodedEntry, encodedMeta, proc.encodeLabels(labels), encodedFields, proc.entryTokens(userID, meta, labels))
+	return storageErrors.ToStatus(err)
+}
+
+// SetBatchData performs an encoding of a batch of entries of mixed types and stores them along with their search tokens.
+// Existing entries are replaced only if overwrite is set, otherwise they are reported as already existing.
+func (proc *Processor) SetBatchData(ctx context.Context, userID string, items []modeldto.BatchItem, overwrite bool) ([]modeldto.BatchItemResult, error) {
+	results, storageItems, keys := proc.prepareBatch(userID, items)
+	if len(storageItems) == 0 {
+		return results, nil
+	}
+	storageResults, err := proc.storage.SetBatchData(ctx, userID, storageItems, overwrite)
+	if err != nil {
+		return nil, storageErrors.ToStatus(err)
+	}
+	created := make(map[string]bool, len(storageResults))
+	for _, storageResult := range storageResults {
+		created[storageResult.Db+"/"+storageResult.Identifier] = storageResult.Created
+	}
+	for idx, key := range keys {
+		if key == "" {
+			continue
+		}
+		isCreated, ok := created[key]
+		switch {
+		case !ok && overwrite:
+			results[idx].Err = status.Error(codes.Internal, "entry was not stored")
+			continue
+		case !ok:
+			results[idx].Err = status.Errorf(codes.AlreadyExists, "entry ID %s already exists", results[idx].Identifier)
+			continue
+		}
+		results[idx].Created = isCreated
+	}
+	return results, nil
+}
+
+// prepareBatch validates and encodes batch items of mixed types. Results of invalid items carry their errors, valid
+// items are returned for storage along with their storage keys, which are empty for invalid items.
+func (proc *Processor) prepareBatch(userID string, items []modeldto.BatchItem) ([]modeldto.BatchItemResult, []modelstorage.BatchItem, []string) {
+	results := make([]modeldto.BatchItemResult, len(items))
+	storageItems := make([]modelstorage.BatchItem, 0, len(items))
+	keys := make([]string, len(items))
+	for idx, item := range items {
+		results[idx].Index = idx
+		db := proc.storageDB(item.Db)
+		storageItem := modelstorage.BatchItem{Db: db}
+		var identifier string
+		var itemErr error
+		switch db {
+		case batchBankCardDB:
+			identifier = item.BankCard.Identifier
+			labels := cleanLabels(item.BankCard.Labels)
+			var fields string
+			fields, itemErr = proc.prepareFields(item.BankCard.Fields)
+			number, expiry, cardErr := prepareBankCard(item.BankCard.Identifier, item.BankCard.Number, item.BankCard.Holder, item.BankCard.CVV,
+				item.BankCard.Expiry, item.BankCard.PIN, item.BankCard.Meta)
+			if cardErr != nil {
+				itemErr = cardErr
+			}
+			storageItem.BankCard = modelstorage.BankCardStorageEntry{
+				Identifier: proc.cipher.Encode(item.BankCard.Identifier),
+				Number:     proc.cipher.Encode(number),
+				Holder:     proc.cipher.Encode(item.BankCard.Holder),
+				CVV:        proc.cipher.Encode(item.BankCard.CVV),
+				Meta:       proc.cipher.Encode(item.BankCard.Meta),
+				Labels:     proc.encodeLabels(labels),
+				Fields:     fields,
+				Expiry:     proc.encodeOptional(expiry),
+				PIN:        proc.encodeOptional(item.BankCard.PIN),
+			}
+			storageItem.Tokens = proc.entryTokens(userID, item.BankCard.Meta, labels)
+			keys[idx] = db + "/" + storageItem.BankCard.Identifier
+		case batchLoginPasswordDB:
+			identifier = item.LoginPassword.Identifier
+			labels := cleanLabels(item.LoginPassword.Labels)
+			var fields string
+			fields, itemErr = proc.prepareFields(item.LoginPassword.Fields)
+			if err := validation.ValidateLoginPassword(item.LoginPassword.Identifier, item.LoginPassword.Login, item.LoginPassword.Password, item.LoginPassword.Meta); err != nil {
+				itemErr = invalidArgument(err)
+			}
+			storageItem.LoginPassword = modelstorage.LoginPasswordStorageEntry{
+				Identifier: proc.cipher.Encode(item.LoginPassword.Identifier),
+				Login:      proc.cipher.Encode(item.LoginPassword.Login),
+				Password:   proc.cipher.Encode(item.LoginPassword.Password),
+				Meta:       proc.cipher.Encode(item.LoginPassword.Meta),
+				Labels:     proc.encodeLabels(labels),
+				Fields:     fields,
+			}
+			storageItem.Tokens = proc.entryTokens(userID, item.LoginPassword.Meta, labels)
+			keys[idx] = db + "/" + storageItem.LoginPassword.Identifier
+		case batchTextBinaryDB:
+			identifier = item.TextBinary.Identifier
+			labels := cleanLabels(item.TextBinary.Labels)
+			var fields string
+			fields, itemErr = proc.prepareFields(item.TextBinary.Fields)
+			if err := validation.ValidateTextBinary(item.TextBinary.Identifier, item.TextBinary.Entry, item.TextBinary.Meta, proc.maxEntryLength); err != nil {
+				itemErr = invalidArgument(err)
+			}
+			storageItem.TextBinary = modelstorage.TextBinaryStorageEntry{
+				Identifier: proc.cipher.Encode(item.TextBinary.Identifier),
+				Entry:      proc.cipher.Encode(item.TextBinary.Entry),
+				Meta:       proc.cipher.Encode(item.TextBinary.Meta),
+				Labels:     proc.encodeLabels(labels),
+				Fields:     fields,
+			}
+			storageItem.Tokens = proc.entryTokens(userID, item.TextBinary.Meta, labels)
+			keys[idx] = db + "/" + storageItem.TextBinary.Identifier
+		default:
+			results[idx].Err = status.Errorf(codes.InvalidArgument, "invalid db %s", item.Db)
+			continue
+		}
+		results[idx].Identifier = identifier
+		if itemErr != nil {
+			results[idx].Err = itemErr
+			keys[idx] = ""
+			continue
+		}
+		storageItems = append(storageItems, storageItem)
+	}
+	return results, storageItems, keys
 }
 
 // Delete performs a removal procedure of a data piece.
@@ -199,7 +489,7 @@ func (proc *Processor) Delete(userID, identifier, db string) {
 	item := modelstorage.Removal{
 		UserID:     userID,
 		Identifier: encodedIndentifier,
-		Db:         db,
+		Db:         proc.storageDB(db),
 	}
 	proc.storage.SendToQueue(item)
 }
@@ -5,14 +5,20 @@ import (
 	"dk-go-gophkeeper/internal/config"
 	"dk-go-gophkeeper/internal/mocks"
 	"dk-go-gophkeeper/internal/server/modeldto"
+	storageErrors "dk-go-gophkeeper/internal/server/storage/errors"
 	"dk-go-gophkeeper/internal/server/storage/modelstorage"
+	"dk-go-gophkeeper/internal/validation"
 	"errors"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/golang/mock/gomock"
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 func TestInitService(t *testing.T) {
@@ -58,6 +64,21 @@ func TestProcessor_AddNewUser(t *testing.T) {
 	assert.Equal(t, nil, err)
 }
 
+func TestProcessor_AddNewUserAlreadyExists(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cipher := mocks.NewMockCipher(ctrl)
+	storage := mocks.NewMockDataStorage(ctrl)
+	cipher.EXPECT().NewToken().Return("generic_access_token", "generic_user_id")
+	cipher.EXPECT().Encode(gomock.Any()).Return("generic_encoded_data").AnyTimes()
+	storage.EXPECT().AddNewUser(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(&storageErrors.AlreadyExistsError{ID: "generic_encoded_data"})
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	processor := InitService(storage, cipher, &logger)
+	accessToken, err := processor.AddNewUser(context.Background(), "generic_login", "generic_password")
+	assert.Equal(t, "", accessToken)
+	assert.Equal(t, "rpc error: code = AlreadyExists desc = already exists", err.Error())
+}
+
 func TestProcessor_LoginUser(t *testing.T) {
 	cfg := config.NewDefaultConfiguration()
 	cfg.UserKey = "jds__63h3_7ds"
@@ -86,7 +107,20 @@ func TestProcessor_LoginUserFail(t *testing.T) {
 	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
 	processor := InitService(storage, cipher, &logger)
 	_, err := processor.LoginUser(context.Background(), "generic_login", "generic_password")
-	assert.Equal(t, "generic_error", err.Error())
+	assert.Equal(t, "rpc error: code = Internal desc = generic_error", err.Error())
+}
+
+func TestProcessor_LoginUserInvalidPassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cipher := mocks.NewMockCipher(ctrl)
+	storage := mocks.NewMockDataStorage(ctrl)
+	storage.EXPECT().CheckUser(gomock.Any(), gomock.Any(), gomock.Any()).Return("", &storageErrors.InvalidCredentialsError{})
+	cipher.EXPECT().Encode(gomock.Any()).Return("generic_encoded_data").AnyTimes()
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	processor := InitService(storage, cipher, &logger)
+	_, err := processor.LoginUser(context.Background(), "generic_login", "generic_password")
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
 }
 
 func TestProcessor_GetBankCardData(t *testing.T) {
@@ -109,10 +143,10 @@ func TestProcessor_GetBankCardData(t *testing.T) {
 			Meta:       cipher.Encode("some_data"),
 		},
 	}
-	storage.EXPECT().GetBankCardData(gomock.Any(), gomock.Any()).Return(storageOutput, nil)
+	storage.EXPECT().GetBankCardData(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(storageOutput, nil)
 	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
 	processor := InitService(storage, cipher, &logger)
-	bankCards, err := processor.GetBankCardData(context.Background(), "some_user_id")
+	bankCards, _, err := processor.GetBankCardData(context.Background(), "some_user_id", "", 0, modeldto.Filter{})
 	assert.Equal(t, nil, err)
 	expectedBankCards := []modeldto.BankCard{{Identifier: "generic_decoded_data", Number: "generic_decoded_data", Holder: "generic_decoded_data", CVV: "generic_decoded_data", Meta: "generic_decoded_data"}}
 	assert.Equal(t, expectedBankCards, bankCards)
@@ -125,11 +159,11 @@ func TestProcessor_GetBankCardDataFail1(t *testing.T) {
 	defer ctrl.Finish()
 	cipher := mocks.NewMockCipher(ctrl)
 	storage := mocks.NewMockDataStorage(ctrl)
-	storage.EXPECT().GetBankCardData(gomock.Any(), gomock.Any()).Return(nil, errors.New("generic_error"))
+	storage.EXPECT().GetBankCardData(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("generic_error"))
 	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
 	processor := InitService(storage, cipher, &logger)
-	_, err := processor.GetBankCardData(context.Background(), "some_user_id")
-	assert.Equal(t, "generic_error", err.Error())
+	_, _, err := processor.GetBankCardData(context.Background(), "some_user_id", "", 0, modeldto.Filter{})
+	assert.Equal(t, "rpc error: code = Internal desc = generic_error", err.Error())
 }
 
 func TestProcessor_GetBankCardDataFail2(t *testing.T) {
@@ -152,13 +186,88 @@ func TestProcessor_GetBankCardDataFail2(t *testing.T) {
 			Meta:       cipher.Encode("some_data"),
 		},
 	}
-	storage.EXPECT().GetBankCardData(gomock.Any(), gomock.Any()).Return(storageOutput, nil)
+	storage.EXPECT().GetBankCardData(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(storageOutput, nil)
 	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
 	processor := InitService(storage, cipher, &logger)
-	_, err := processor.GetBankCardData(context.Background(), "some_user_id")
+	_, _, err := processor.GetBankCardData(context.Background(), "some_user_id", "", 0, modeldto.Filter{})
 	assert.Equal(t, "generic_error", err.Error())
 }
 
+func TestProcessor_GetBankCardDataPages(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cipher := mocks.NewMockCipher(ctrl)
+	cipher.EXPECT().Decode(gomock.Any()).Return("generic_decoded_data", nil).AnyTimes()
+	storage := mocks.NewMockDataStorage(ctrl)
+	storageOutput := []modelstorage.BankCardStorageEntry{{ID: 3}, {ID: 7}, {ID: 9}}
+	gomock.InOrder(
+		storage.EXPECT().GetBankCardData(gomock.Any(), "some_user_id", int64(0), 3, gomock.Len(0)).Return(storageOutput, nil),
+		storage.EXPECT().GetBankCardData(gomock.Any(), "some_user_id", int64(7), 3, gomock.Len(0)).Return(storageOutput[2:], nil),
+	)
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	processor := InitService(storage, cipher, &logger)
+	bankCards, nextPageToken, err := processor.GetBankCardData(context.Background(), "some_user_id", "", 2, modeldto.Filter{})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, len(bankCards))
+	assert.Equal(t, "7", nextPageToken)
+	bankCards, nextPageToken, err = processor.GetBankCardData(context.Background(), "some_user_id", nextPageToken, 2, modeldto.Filter{})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, len(bankCards))
+	assert.Equal(t, "", nextPageToken)
+}
+
+func TestProcessor_GetBankCardDataInvalidPageToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cipher := mocks.NewMockCipher(ctrl)
+	storage := mocks.NewMockDataStorage(ctrl)
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	processor := InitService(storage, cipher, &logger)
+	_, _, err := processor.GetBankCardData(context.Background(), "some_user_id", "abc", 0, modeldto.Filter{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestParsePage(t *testing.T) {
+	tests := []struct {
+		name       string
+		pageToken  string
+		pageSize   int
+		wantCursor int64
+		wantLimit  int
+		wantErr    bool
+	}{
+		{name: "defaults", wantLimit: defaultPageSize},
+		{name: "clamped", pageToken: "42", pageSize: maxPageSize + 1, wantCursor: 42, wantLimit: maxPageSize},
+		{name: "negative cursor", pageToken: "-1", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cursor, limit, err := parsePage(tt.pageToken, tt.pageSize)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.wantCursor, cursor)
+			assert.Equal(t, tt.wantLimit, limit)
+		})
+	}
+}
+
+func TestParseSearchPage(t *testing.T) {
+	cursor := modelstorage.SearchCursor{Db: "textBinary", Identifier: "id1"}
+	parsed, limit, err := parseSearchPage(searchPageToken(cursor), 0)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, cursor, parsed)
+	assert.Equal(t, defaultPageSize, limit)
+
+	parsed, limit, err = parseSearchPage("", maxPageSize+1)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, modelstorage.SearchCursor{}, parsed)
+	assert.Equal(t, maxPageSize, limit)
+
+	for _, token := range []string{"42", "dGV4dEJpbmFyeQ", "!"} {
+		_, _, err = parseSearchPage(token, 0)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	}
+}
+
 func TestProcessor_GetLoginPasswordData(t *testing.T) {
 	cfg := config.NewDefaultConfiguration()
 	cfg.UserKey = "jds__63h3_7ds"
@@ -178,10 +287,10 @@ func TestProcessor_GetLoginPasswordData(t *testing.T) {
 			Meta:       cipher.Encode("some_data"),
 		},
 	}
-	storage.EXPECT().GetLoginPasswordData(gomock.Any(), gomock.Any()).Return(storageOutput, nil)
+	storage.EXPECT().GetLoginPasswordData(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(storageOutput, nil)
 	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
 	processor := InitService(storage, cipher, &logger)
-	loginsPasswords, err := processor.GetLoginPasswordData(context.Background(), "some_user_id")
+	loginsPasswords, _, err := processor.GetLoginPasswordData(context.Background(), "some_user_id", "", 0, modeldto.Filter{})
 	assert.Equal(t, nil, err)
 	expectedLoginsPasswords := []modeldto.LoginPassword{{Identifier: "generic_decoded_data", Login: "generic_decoded_data", Password: "generic_decoded_data", Meta: "generic_decoded_data"}}
 	assert.Equal(t, expectedLoginsPasswords, loginsPasswords)
@@ -194,11 +303,11 @@ func TestProcessor_GetLoginPasswordDataFail1(t *testing.T) {
 	defer ctrl.Finish()
 	cipher := mocks.NewMockCipher(ctrl)
 	storage := mocks.NewMockDataStorage(ctrl)
-	storage.EXPECT().GetLoginPasswordData(gomock.Any(), gomock.Any()).Return(nil, errors.New("generic_error"))
+	storage.EXPECT().GetLoginPasswordData(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("generic_error"))
 	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
 	processor := InitService(storage, cipher, &logger)
-	_, err := processor.GetLoginPasswordData(context.Background(), "some_user_id")
-	assert.Equal(t, "generic_error", err.Error())
+	_, _, err := processor.GetLoginPasswordData(context.Background(), "some_user_id", "", 0, modeldto.Filter{})
+	assert.Equal(t, "rpc error: code = Internal desc = generic_error", err.Error())
 }
 
 func TestProcessor_GetLoginPasswordDataFail2(t *testing.T) {
@@ -220,10 +329,10 @@ func TestProcessor_GetLoginPasswordDataFail2(t *testing.T) {
 			Meta:       cipher.Encode("some_data"),
 		},
 	}
-	storage.EXPECT().GetLoginPasswordData(gomock.Any(), gomock.Any()).Return(storageOutput, nil)
+	storage.EXPECT().GetLoginPasswordData(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(storageOutput, nil)
 	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
 	processor := InitService(storage, cipher, &logger)
-	_, err := processor.GetLoginPasswordData(context.Background(), "some_user_id")
+	_, _, err := processor.GetLoginPasswordData(context.Background(), "some_user_id", "", 0, modeldto.Filter{})
 	assert.Equal(t, "generic_error", err.Error())
 }
 
@@ -245,10 +354,10 @@ func TestProcessor_GetTextBinaryData(t *testing.T) {
 			Meta:       cipher.Encode("some_data"),
 		},
 	}
-	storage.EXPECT().GetTextBinaryData(gomock.Any(), gomock.Any()).Return(storageOutput, nil)
+	storage.EXPECT().GetTextBinaryData(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(storageOutput, nil)
 	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
 	processor := InitService(storage, cipher, &logger)
-	textsBinaries, err := processor.GetTextBinaryData(context.Background(), "some_user_id")
+	textsBinaries, _, err := processor.GetTextBinaryData(context.Background(), "some_user_id", "", 0, modeldto.Filter{})
 	assert.Equal(t, nil, err)
 	expectedTextsBinaries := []modeldto.TextBinary{{Identifier: "generic_decoded_data", Entry: "generic_decoded_data", Meta: "generic_decoded_data"}}
 	assert.Equal(t, expectedTextsBinaries, textsBinaries)
@@ -261,11 +370,11 @@ func TestProcessor_GetTextBinaryDataFail1(t *testing.T) {
 	defer ctrl.Finish()
 	cipher := mocks.NewMockCipher(ctrl)
 	storage := mocks.NewMockDataStorage(ctrl)
-	storage.EXPECT().GetTextBinaryData(gomock.Any(), gomock.Any()).Return(nil, errors.New("generic_error"))
+	storage.EXPECT().GetTextBinaryData(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("generic_error"))
 	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
 	processor := InitService(storage, cipher, &logger)
-	_, err := processor.GetTextBinaryData(context.Background(), "some_user_id")
-	assert.Equal(t, "generic_error", err.Error())
+	_, _, err := processor.GetTextBinaryData(context.Background(), "some_user_id", "", 0, modeldto.Filter{})
+	assert.Equal(t, "rpc error: code = Internal desc = generic_error", err.Error())
 }
 
 func TestProcessor_GetTextBinaryDataFail2(t *testing.T) {
@@ -286,10 +395,10 @@ func TestProcessor_GetTextBinaryDataFail2(t *testing.T) {
 			Meta:       cipher.Encode("some_data"),
 		},
 	}
-	storage.EXPECT().GetTextBinaryData(gomock.Any(), gomock.Any()).Return(storageOutput, nil)
+	storage.EXPECT().GetTextBinaryData(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(storageOutput, nil)
 	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
 	processor := InitService(storage, cipher, &logger)
-	_, err := processor.GetTextBinaryData(context.Background(), "some_user_id")
+	_, _, err := processor.GetTextBinaryData(context.Background(), "some_user_id", "", 0, modeldto.Filter{})
 	assert.Equal(t, "generic_error", err.Error())
 }
 
@@ -301,13 +410,40 @@ func TestProcessor_SetBankCardData(t *testing.T) {
 	cipher := mocks.NewMockCipher(ctrl)
 	cipher.EXPECT().Encode(gomock.Any()).Return("generic_encoded_data").AnyTimes()
 	storage := mocks.NewMockDataStorage(ctrl)
-	storage.EXPECT().SetBankCardData(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	storage.EXPECT().SetBankCardData(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
 	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
 	processor := InitService(storage, cipher, &logger)
-	err := processor.SetBankCardData(context.Background(), "", "", "", "", "", "")
+	err := processor.SetBankCardData(context.Background(), "", "card", "4111111111111111", "", "123", "", "", "", modeldto.Labels{}, nil)
 	assert.Equal(t, nil, err)
 }
 
+func TestProcessor_SetBankCardDataNormalized(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cipher := mocks.NewMockCipher(ctrl)
+	cipher.EXPECT().Encode(gomock.Any()).DoAndReturn(func(msg string) string { return "encoded_" + msg }).AnyTimes()
+	cipher.EXPECT().BlindIndex(gomock.Any(), gomock.Any()).Return("token").AnyTimes()
+	storage := mocks.NewMockDataStorage(ctrl)
+	storage.EXPECT().SetBankCardData(gomock.Any(), "some_user_id", "encoded_card", "encoded_378282246310005", "encoded_holder", "encoded_1234", "encoded_09/31", "encoded_0000", gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	processor := InitService(storage, cipher, &logger)
+	err := processor.SetBankCardData(context.Background(), "some_user_id", "card", "3782 822463 10005", "holder", "1234", "09/2031", "0000", "", modeldto.Labels{}, nil)
+	assert.Equal(t, nil, err)
+}
+
+func TestProcessor_SetBankCardDataInvalid(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cipher := mocks.NewMockCipher(ctrl)
+	storage := mocks.NewMockDataStorage(ctrl)
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	processor := InitService(storage, cipher, &logger)
+	err := processor.SetBankCardData(context.Background(), "some_user_id", "card", "4111111111111112", "holder", "12", "13/30", "", "", modeldto.Labels{}, nil)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Equal(t, "card number fails the checksum, check it for typos; card security code must consist of 3 digits; "+
+		"expiry date must be of the MM/YY form", status.Convert(err).Message())
+}
+
 func TestProcessor_SetLoginPasswordData(t *testing.T) {
 	cfg := config.NewDefaultConfiguration()
 	cfg.UserKey = "jds__63h3_7ds"
@@ -316,13 +452,33 @@ func TestProcessor_SetLoginPasswordData(t *testing.T) {
 	cipher := mocks.NewMockCipher(ctrl)
 	cipher.EXPECT().Encode(gomock.Any()).Return("generic_encoded_data").AnyTimes()
 	storage := mocks.NewMockDataStorage(ctrl)
-	storage.EXPECT().SetLoginPasswordData(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	storage.EXPECT().SetLoginPasswordData(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
 	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
 	processor := InitService(storage, cipher, &logger)
-	err := processor.SetLoginPasswordData(context.Background(), "", "", "", "", "")
+	err := processor.SetLoginPasswordData(context.Background(), "", "login", "", "", "", modeldto.Labels{}, nil)
 	assert.Equal(t, nil, err)
 }
 
+func TestProcessor_SetLoginPasswordDataInvalid(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cipher := mocks.NewMockCipher(ctrl)
+	storage := mocks.NewMockDataStorage(ctrl)
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	processor := InitService(storage, cipher, &logger)
+	err := processor.SetLoginPasswordData(context.Background(), "some_user_id", " ", "", "", strings.Repeat("a", 4097), modeldto.Labels{}, nil)
+	st := status.Convert(err)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+	assert.Equal(t, "identifier cannot be empty; meta must not exceed 4096 bytes", st.Message())
+	assert.Equal(t, 1, len(st.Details()))
+	badRequest, ok := st.Details()[0].(*errdetails.BadRequest)
+	assert.Equal(t, true, ok)
+	assert.Equal(t, 2, len(badRequest.GetFieldViolations()))
+	assert.Equal(t, "identifier", badRequest.GetFieldViolations()[0].GetField())
+	assert.Equal(t, "meta", badRequest.GetFieldViolations()[1].GetField())
+	assert.Equal(t, "meta must not exceed 4096 bytes", badRequest.GetFieldViolations()[1].GetDescription())
+}
+
 func TestProcessor_SetTextBinaryData(t *testing.T) {
 	cfg := config.NewDefaultConfiguration()
 	cfg.UserKey = "jds__63h3_7ds"
@@ -331,13 +487,27 @@ func TestProcessor_SetTextBinaryData(t *testing.T) {
 	cipher := mocks.NewMockCipher(ctrl)
 	cipher.EXPECT().Encode(gomock.Any()).Return("generic_encoded_data").AnyTimes()
 	storage := mocks.NewMockDataStorage(ctrl)
-	storage.EXPECT().SetTextBinaryData(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	storage.EXPECT().SetTextBinaryData(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
 	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
 	processor := InitService(storage, cipher, &logger)
-	err := processor.SetTextBinaryData(context.Background(), "", "", "", "")
+	err := processor.SetTextBinaryData(context.Background(), "", "text", "", "", modeldto.Labels{}, nil)
 	assert.Equal(t, nil, err)
 }
 
+func TestProcessor_SetTextBinaryDataTooLong(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cipher := mocks.NewMockCipher(ctrl)
+	storage := mocks.NewMockDataStorage(ctrl)
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	processor := InitService(storage, cipher, &logger)
+	// limits above the gRPC message limit are ignored
+	processor.SetMaxEntryLength(validation.MaxEntryLength + 1)
+	processor.SetMaxEntryLength(16)
+	err := processor.SetTextBinaryData(context.Background(), "", "note", strings.Repeat("a", 17), "", modeldto.Labels{}, nil)
+	assert.Equal(t, "rpc error: code = InvalidArgument desc = entry must not exceed 16 bytes", err.Error())
+}
+
 func TestProcessor_Delete(t *testing.T) {
 	cfg := config.NewDefaultConfiguration()
 	cfg.UserKey = "jds__63h3_7ds"
@@ -351,3 +521,252 @@ func TestProcessor_Delete(t *testing.T) {
 	processor := InitService(storage, cipher, &logger)
 	processor.Delete("", "", "")
 }
+
+func TestProcessor_SetBatchData(t *testing.T) {
+	cfg := config.NewDefaultConfiguration()
+	cfg.UserKey = "jds__63h3_7ds"
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cipher := mocks.NewMockCipher(ctrl)
+	cipher.EXPECT().Encode(gomock.Any()).DoAndReturn(func(data string) string { return "encoded_" + data }).AnyTimes()
+	storage := mocks.NewMockDataStorage(ctrl)
+	storageOutput := []modelstorage.BatchItemResult{
+		{Db: "bankCard", Identifier: "encoded_id1", Created: true},
+		{Db: "loginPassword", Identifier: "encoded_id2", Created: false},
+	}
+	storage.EXPECT().SetBatchData(gomock.Any(), gomock.Any(), gomock.Len(2), true).DoAndReturn(func(_ context.Context, _ string, items []modelstorage.BatchItem, _ bool) ([]modelstorage.BatchItemResult, error) {
+		assert.Equal(t, []string{"token_tag:visa", "token_word:visa"}, items[0].Tokens)
+		assert.Equal(t, []string{}, items[1].Tokens)
+		return storageOutput, nil
+	})
+	cipher.EXPECT().BlindIndex("some_user_id", gomock.Any()).DoAndReturn(func(userID, term string) string { return "token_" + term }).AnyTimes()
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	processor := InitService(storage, cipher, &logger)
+	items := []modeldto.BatchItem{
+		{Db: "bankCard", BankCard: modeldto.BankCard{Identifier: "id1", Number: "4111111111111111", CVV: "123", Meta: "#Visa"}},
+		{Db: "loginPassword", LoginPassword: modeldto.LoginPassword{Identifier: "id2"}},
+		{Db: "textBinary", TextBinary: modeldto.TextBinary{Identifier: ""}},
+		{Db: "generic_db"},
+		{Db: "bankCard", BankCard: modeldto.BankCard{Identifier: "id3", Number: "4111111111111112", CVV: "123"}},
+	}
+	results, err := processor.SetBatchData(context.Background(), "some_user_id", items, true)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, modeldto.BatchItemResult{Index: 0, Identifier: "id1", Created: true}, results[0])
+	assert.Equal(t, modeldto.BatchItemResult{Index: 1, Identifier: "id2", Created: false}, results[1])
+	assert.Equal(t, codes.InvalidArgument, status.Code(results[2].Err))
+	assert.Equal(t, codes.InvalidArgument, status.Code(results[3].Err))
+	assert.Equal(t, "card number fails the checksum, check it for typos", status.Convert(results[4].Err).Message())
+
+	// existing entries are left intact unless overwriting is requested
+	storage.EXPECT().SetBatchData(gomock.Any(), gomock.Any(), gomock.Len(2), false).Return(storageOutput[:1], nil)
+	results, err = processor.SetBatchData(context.Background(), "some_user_id", items[:2], false)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, modeldto.BatchItemResult{Index: 0, Identifier: "id1", Created: true}, results[0])
+	assert.Equal(t, codes.AlreadyExists, status.Code(results[1].Err))
+	assert.Equal(t, "entry ID id2 already exists", status.Convert(results[1].Err).Message())
+}
+
+func TestProcessor_SetDBNames(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cipher := mocks.NewMockCipher(ctrl)
+	cipher.EXPECT().Encode(gomock.Any()).DoAndReturn(func(data string) string { return "encoded_" + data }).AnyTimes()
+	cipher.EXPECT().Decode(gomock.Any()).DoAndReturn(func(msg string) (string, error) { return "decoded_" + msg, nil }).AnyTimes()
+	cipher.EXPECT().BlindIndex("some_user_id", gomock.Any()).DoAndReturn(func(userID, term string) string { return "token_" + term }).AnyTimes()
+	storage := mocks.NewMockDataStorage(ctrl)
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	processor := InitService(storage, cipher, &logger)
+	processor.SetDBNames("cards", "logins", "notes")
+
+	// entries are stored and indexed under their DB identifiers
+	storage.EXPECT().SetBatchData(gomock.Any(), "some_user_id", []modelstorage.BatchItem{
+		{Db: "textBinary", TextBinary: modelstorage.TextBinaryStorageEntry{Identifier: "encoded_id1", Entry: "encoded_", Meta: "encoded_"}, Tokens: []string{}},
+	}, false).Return([]modelstorage.BatchItemResult{{Db: "textBinary", Identifier: "encoded_id1", Created: true}}, nil)
+	results, err := processor.SetBatchData(context.Background(), "some_user_id", []modeldto.BatchItem{
+		{Db: "notes", TextBinary: modeldto.TextBinary{Identifier: "id1"}},
+		{Db: "textBinary", TextBinary: modeldto.TextBinary{Identifier: "id2"}},
+	}, false)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, modeldto.BatchItemResult{Index: 0, Identifier: "id1", Created: true}, results[0])
+	assert.Equal(t, codes.InvalidArgument, status.Code(results[1].Err))
+
+	// search results refer to the configured DB names
+	storage.EXPECT().SearchEntries(gomock.Any(), "some_user_id", gomock.Any(), modelstorage.SearchCursor{}, defaultPageSize).Return(modelstorage.SearchPage{
+		Items: []modelstorage.BatchItem{{Db: "textBinary", TextBinary: modelstorage.TextBinaryStorageEntry{Identifier: "id1"}}},
+	}, nil)
+	page, err := processor.SearchEntries(context.Background(), "some_user_id", []string{"github"}, modeldto.Filter{}, 0, "")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "notes", page.Items[0].Db)
+
+	storage.EXPECT().SendToQueue(modelstorage.Removal{UserID: "some_user_id", Identifier: "encoded_id1", Db: "textBinary"})
+	processor.Delete("some_user_id", "id1", "notes")
+}
+
+func TestProcessor_SetBatchDataFail(t *testing.T) {
+	cfg := config.NewDefaultConfiguration()
+	cfg.UserKey = "jds__63h3_7ds"
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cipher := mocks.NewMockCipher(ctrl)
+	cipher.EXPECT().Encode(gomock.Any()).Return("generic_encoded_data").AnyTimes()
+	storage := mocks.NewMockDataStorage(ctrl)
+	storage.EXPECT().SetBatchData(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("generic_error"))
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	processor := InitService(storage, cipher, &logger)
+	items := []modeldto.BatchItem{{Db: "textBinary", TextBinary: modeldto.TextBinary{Identifier: "id1"}}}
+	_, err := processor.SetBatchData(context.Background(), "some_user_id", items, false)
+	assert.Equal(t, "rpc error: code = Internal desc = generic_error", err.Error())
+}
+
+func TestIndexTerms(t *testing.T) {
+	assert.Equal(t, []string{"word:github", "tag:work", "word:work", "word:2fa"}, indexTerms("GitHub, #work; 2FA a #", modeldto.Labels{}))
+	assert.Equal(t, 0, len(indexTerms("", modeldto.Labels{})))
+	assert.Equal(t, []string{"word:prod", "word:db", "tag:ops"}, queryTerms([]string{"Prod-DB", "db", "x"}, modeldto.Filter{Tags: []string{"#Ops", " "}}))
+	labels := modeldto.Labels{Folder: "Work/Cloud/AWS", Tags: []string{"ops"}, Favorite: true}
+	assert.Equal(t, []string{"tag:ops", "is:favorite", "folder:work", "folder:work/cloud", "folder:work/cloud/aws", "word:root", "word:ops"}, indexTerms("root #ops", labels))
+	assert.Equal(t, []string{"tag:prod", "folder:work/cloud", "is:favorite"}, filterTerms(modeldto.Filter{Folder: " /Work// Cloud/", Tags: []string{"#Prod"}, FavoritesOnly: true}))
+}
+
+func TestCleanLabels(t *testing.T) {
+	labels := cleanLabels(modeldto.Labels{Folder: "/ Personal //Bank/ ", Tags: []string{" #Finance", "finance,cards", ""}, Favorite: true})
+	assert.Equal(t, modeldto.Labels{Folder: "Personal/Bank", Tags: []string{"finance", "cards"}, Favorite: true}, labels)
+	assert.Equal(t, modeldto.Labels{}, cleanLabels(modeldto.Labels{Folder: " / "}))
+}
+
+func TestProcessor_SetBankCardDataLabels(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cipher := mocks.NewMockCipher(ctrl)
+	cipher.EXPECT().Encode(gomock.Any()).DoAndReturn(func(msg string) string { return "encoded_" + msg }).AnyTimes()
+	cipher.EXPECT().BlindIndex("some_user_id", gomock.Any()).DoAndReturn(func(_, term string) string { return "token_" + term }).AnyTimes()
+	storage := mocks.NewMockDataStorage(ctrl)
+	storedLabels := modelstorage.Labels{Folder: "encoded_Personal/Bank", Tags: "encoded_visa,travel", Favorite: true}
+	storage.EXPECT().SetBankCardData(gomock.Any(), "some_user_id", "encoded_card", gomock.Any(), gomock.Any(), gomock.Any(), "", "", gomock.Any(), storedLabels, gomock.Any(),
+		[]string{"token_tag:visa", "token_tag:travel", "token_is:favorite", "token_folder:personal", "token_folder:personal/bank"}).Return(nil)
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	processor := InitService(storage, cipher, &logger)
+	labels := modeldto.Labels{Folder: "Personal/Bank/", Tags: []string{"Visa", "travel"}, Favorite: true}
+	err := processor.SetBankCardData(context.Background(), "some_user_id", "card", "4111111111111111", "", "123", "", "", "", labels, nil)
+	assert.Equal(t, nil, err)
+}
+
+func TestProcessor_GetBankCardDataFilter(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cipher := mocks.NewMockCipher(ctrl)
+	cipher.EXPECT().Decode(gomock.Any()).DoAndReturn(func(msg string) (string, error) { return "decoded_" + msg, nil }).AnyTimes()
+	cipher.EXPECT().BlindIndex("some_user_id", gomock.Any()).DoAndReturn(func(_, term string) string { return "token_" + term }).AnyTimes()
+	storage := mocks.NewMockDataStorage(ctrl)
+	storageOutput := []modelstorage.BankCardStorageEntry{{ID: 1, Identifier: "card", Labels: modelstorage.Labels{Tags: "visa", Favorite: true}}}
+	storage.EXPECT().GetBankCardData(gomock.Any(), "some_user_id", int64(0), defaultPageSize+1, []string{"token_folder:personal", "token_is:favorite"}).Return(storageOutput, nil)
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	processor := InitService(storage, cipher, &logger)
+	bankCards, _, err := processor.GetBankCardData(context.Background(), "some_user_id", "", 0, modeldto.Filter{Folder: "Personal", FavoritesOnly: true})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, modeldto.Labels{Tags: []string{"decoded_visa"}, Favorite: true}, bankCards[0].Labels)
+}
+
+func TestCleanFields(t *testing.T) {
+	fields, err := cleanFields([]modeldto.CustomField{{Name: " PIN ", Value: "1234", Concealed: true}, {Name: "Recovery email", Value: "a@b.c"}})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []modeldto.CustomField{{Name: "PIN", Value: "1234", Concealed: true}, {Name: "Recovery email", Value: "a@b.c"}}, fields)
+	_, err = cleanFields([]modeldto.CustomField{{Name: "PIN"}, {Name: " "}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Equal(t, "name of custom field 2 cannot be empty", status.Convert(err).Message())
+	_, err = cleanFields([]modeldto.CustomField{{Name: "PIN"}, {Name: "PIN "}})
+	assert.Equal(t, "duplicate custom field PIN", status.Convert(err).Message())
+	_, err = cleanFields(make([]modeldto.CustomField, maxCustomFields+1))
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestProcessor_SetLoginPasswordDataFields(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cipher := mocks.NewMockCipher(ctrl)
+	cipher.EXPECT().Encode(gomock.Any()).DoAndReturn(func(msg string) string { return "encoded_" + msg }).AnyTimes()
+	cipher.EXPECT().BlindIndex(gomock.Any(), gomock.Any()).Return("token").AnyTimes()
+	storage := mocks.NewMockDataStorage(ctrl)
+	storedFields := `[{"name":"encoded_PIN","value":"encoded_1234","concealed":true},{"name":"encoded_Question","value":"encoded_Pet"}]`
+	storage.EXPECT().SetLoginPasswordData(gomock.Any(), "some_user_id", "encoded_bank", gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), storedFields, gomock.Any()).Return(nil)
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	processor := InitService(storage, cipher, &logger)
+	fields := []modeldto.CustomField{{Name: "PIN", Value: "1234", Concealed: true}, {Name: "Question", Value: "Pet"}}
+	err := processor.SetLoginPasswordData(context.Background(), "some_user_id", "bank", "", "", "", modeldto.Labels{}, fields)
+	assert.Equal(t, nil, err)
+
+	err = processor.SetLoginPasswordData(context.Background(), "some_user_id", "bank", "", "", "", modeldto.Labels{}, []modeldto.CustomField{{Value: "orphan"}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestProcessor_GetLoginPasswordDataFields(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cipher := mocks.NewMockCipher(ctrl)
+	cipher.EXPECT().Decode(gomock.Any()).DoAndReturn(func(msg string) (string, error) { return strings.TrimPrefix(msg, "encoded_"), nil }).AnyTimes()
+	storage := mocks.NewMockDataStorage(ctrl)
+	storageOutput := []modelstorage.LoginPasswordStorageEntry{
+		{ID: 1, Identifier: "encoded_bank", Fields: `[{"name":"encoded_PIN","value":"encoded_1234","concealed":true}]`},
+		{ID: 2, Identifier: "encoded_legacy"},
+	}
+	storage.EXPECT().GetLoginPasswordData(gomock.Any(), "some_user_id", int64(0), defaultPageSize+1, gomock.Any()).Return(storageOutput, nil)
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	processor := InitService(storage, cipher, &logger)
+	loginsPasswords, _, err := processor.GetLoginPasswordData(context.Background(), "some_user_id", "", 0, modeldto.Filter{})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []modeldto.CustomField{{Name: "PIN", Value: "1234", Concealed: true}}, loginsPasswords[0].Fields)
+	assert.Equal(t, []modeldto.CustomField(nil), loginsPasswords[1].Fields)
+}
+
+func TestProcessor_SearchEntries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cipher := mocks.NewMockCipher(ctrl)
+	cipher.EXPECT().BlindIndex("some_user_id", gomock.Any()).DoAndReturn(func(userID, term string) string { return "token_" + term }).AnyTimes()
+	cipher.EXPECT().Decode(gomock.Any()).DoAndReturn(func(msg string) (string, error) { return "decoded_" + msg, nil }).AnyTimes()
+	storage := mocks.NewMockDataStorage(ctrl)
+	storageOutput := modelstorage.SearchPage{
+		Items: []modelstorage.BatchItem{
+			{Db: "textBinary", TextBinary: modelstorage.TextBinaryStorageEntry{Identifier: "id1", Entry: "entry", Meta: "meta",
+				Fields: `[{"name":"name","value":"value","concealed":true}]`}},
+		},
+		NextCursor: modelstorage.SearchCursor{Db: "textBinary", Identifier: "id1"},
+	}
+	storage.EXPECT().SearchEntries(gomock.Any(), "some_user_id", []string{"token_word:github", "token_tag:work"}, modelstorage.SearchCursor{}, defaultPageSize).Return(storageOutput, nil)
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	processor := InitService(storage, cipher, &logger)
+	page, err := processor.SearchEntries(context.Background(), "some_user_id", []string{"github"}, modeldto.Filter{Tags: []string{"work"}}, 0, "")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, modeldto.SearchPage{
+		Items: []modeldto.BatchItem{
+			{Db: "textBinary", TextBinary: modeldto.TextBinary{Identifier: "decoded_id1", Entry: "decoded_entry", Meta: "decoded_meta",
+				Fields: []modeldto.CustomField{{Name: "decoded_name", Value: "decoded_value", Concealed: true}}}},
+		},
+		NextPageToken: "dGV4dEJpbmFyeS9pZDE",
+	}, page)
+
+	storage.EXPECT().SearchEntries(gomock.Any(), "some_user_id", gomock.Any(), modelstorage.SearchCursor{Db: "textBinary", Identifier: "id1"}, maxPageSize).Return(modelstorage.SearchPage{}, nil)
+	page, err = processor.SearchEntries(context.Background(), "some_user_id", []string{"github"}, modeldto.Filter{}, 100000, page.NextPageToken)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "", page.NextPageToken)
+}
+
+func TestProcessor_SearchEntriesFail(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cipher := mocks.NewMockCipher(ctrl)
+	cipher.EXPECT().BlindIndex(gomock.Any(), gomock.Any()).Return("generic_token").AnyTimes()
+	cipher.EXPECT().Decode(gomock.Any()).Return("", errors.New("generic_error")).AnyTimes()
+	storage := mocks.NewMockDataStorage(ctrl)
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	processor := InitService(storage, cipher, &logger)
+
+	_, err := processor.SearchEntries(context.Background(), "some_user_id", []string{"a"}, modeldto.Filter{}, 0, "")
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = processor.SearchEntries(context.Background(), "some_user_id", []string{"github"}, modeldto.Filter{}, 0, "generic_token")
+	assert.Equal(t, "invalid page token", status.Convert(err).Message())
+
+	storageOutput := modelstorage.SearchPage{Items: []modelstorage.BatchItem{{Db: "bankCard"}}}
+	storage.EXPECT().SearchEntries(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(storageOutput, nil)
+	_, err = processor.SearchEntries(context.Background(), "some_user_id", []string{"github"}, modeldto.Filter{}, 0, "")
+	assert.Equal(t, "generic_error", err.Error())
+}
@@ -0,0 +1,177 @@
+package processor
+
+import (
+	"context"
+	"dk-go-gophkeeper/internal/server/modeldto"
+	storageErrors "dk-go-gophkeeper/internal/server/storage/errors"
+	"dk-go-gophkeeper/internal/server/storage/modelstorage"
+	"errors"
+	"net"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// sessionTouchInterval limits how often the last-seen time of a session is updated.
+const sessionTouchInterval = time.Minute
+
+// session field lengths
+const (
+	deviceNameLength    = 100
+	clientVersionLength = 50
+)
+
+// truncate cuts a client-provided value to a length limit.
+func truncate(value string, length int) string {
+	runes := []rune(value)
+	if len(runes) > length {
+		return string(runes[:length])
+	}
+	return value
+}
+
+// peerIP returns the IP address of a peer address, sessions are seen from IP addresses regardless of ports.
+func peerIP(peer string) string {
+	host, _, err := net.SplitHostPort(peer)
+	if err != nil {
+		return peer
+	}
+	return host
+}
+
+// OpenSession creates a session of a user logged in from a device and returns its key.
+func (proc *Processor) OpenSession(ctx context.Context, userID string, session modeldto.Session) (string, error) {
+	accountID, err := proc.accountID(userID)
+	if err != nil {
+		return "", err
+	}
+	sessionKey, digest, err := proc.cipher.NewSessionKey()
+	if err != nil {
+		return "", status.Error(codes.Internal, err.Error())
+	}
+	_, err = proc.storage.AddSession(ctx, modelstorage.Session{
+		UserID:        accountID,
+		Digest:        digest,
+		DeviceName:    truncate(session.DeviceName, deviceNameLength),
+		ClientVersion: truncate(session.ClientVersion, clientVersionLength),
+		Peer:          peerIP(session.Peer),
+	})
+	if err != nil {
+		return "", storageErrors.ToStatus(err)
+	}
+	return sessionKey, nil
+}
+
+// SetSessionTTL sets limits sessions expire after once they are not seen or exist for longer, a limit which is not
+// positive is not applied.
+func (proc *Processor) SetSessionTTL(idleTTL, maxAge time.Duration) {
+	proc.sessionIdleTTL = idleTTL
+	proc.sessionMaxAge = maxAge
+}
+
+// expired checks whether a session is idle or old for longer than the limits.
+func (proc *Processor) expired(session modelstorage.Session) bool {
+	if proc.sessionIdleTTL > 0 && time.Since(session.LastSeenAt) > proc.sessionIdleTTL {
+		return true
+	}
+	return proc.sessionMaxAge > 0 && time.Since(session.CreatedAt) > proc.sessionMaxAge
+}
+
+// session retrieves a session of a user by its key, revoked sessions are reported as unauthenticated.
+func (proc *Processor) session(ctx context.Context, accountID, sessionKey string) (modelstorage.Session, error) {
+	if sessionKey == "" {
+		return modelstorage.Session{}, status.Error(codes.Unauthenticated, "session is required, log in again")
+	}
+	session, err := proc.storage.GetSession(ctx, accountID, proc.cipher.SessionDigest(sessionKey))
+	var notFound *storageErrors.NotFoundError
+	if errors.As(err, &notFound) {
+		return modelstorage.Session{}, status.Error(codes.Unauthenticated, "session was revoked")
+	}
+	if err != nil {
+		return modelstorage.Session{}, storageErrors.ToStatus(err)
+	}
+	return session, nil
+}
+
+// CheckSession checks that a session of a user was neither revoked nor expired and records that it was seen from the
+// IP address of a peer.
+func (proc *Processor) CheckSession(ctx context.Context, userID, sessionKey, peer string) error {
+	accountID, err := proc.accountID(userID)
+	if err != nil {
+		return err
+	}
+	session, err := proc.session(ctx, accountID, sessionKey)
+	if err != nil {
+		return err
+	}
+	if proc.expired(session) {
+		// expired sessions are removed by the cleanup anyway
+		if err := proc.storage.DeleteSession(ctx, accountID, session.ID); err != nil {
+			proc.logger.Error().Err(err).Int64("session", session.ID).Msg("Could not delete expired session")
+		}
+		return status.Error(codes.Unauthenticated, "session expired, log in again")
+	}
+	ip := peerIP(peer)
+	if time.Since(session.LastSeenAt) < sessionTouchInterval && session.Peer == ip {
+		return nil
+	}
+	// the request is served even if the last-seen time could not be updated
+	if err := proc.storage.TouchSession(ctx, session.ID, ip); err != nil {
+		proc.logger.Error().Err(err).Int64("session", session.ID).Msg("Could not touch session")
+	}
+	return nil
+}
+
+// DeleteExpiredSessions removes sessions of all users which are idle or old for longer than the limits and returns their
+// amount.
+func (proc *Processor) DeleteExpiredSessions(ctx context.Context) (int64, error) {
+	if proc.sessionIdleTTL <= 0 && proc.sessionMaxAge <= 0 {
+		return 0, nil
+	}
+	deleted, err := proc.storage.DeleteExpiredSessions(ctx, proc.sessionIdleTTL, proc.sessionMaxAge)
+	if err != nil {
+		return 0, storageErrors.ToStatus(err)
+	}
+	return deleted, nil
+}
+
+// ListSessions retrieves sessions of a user, the session of the request is marked as current.
+func (proc *Processor) ListSessions(ctx context.Context, userID, sessionKey string) ([]modeldto.Session, error) {
+	accountID, err := proc.accountID(userID)
+	if err != nil {
+		return nil, err
+	}
+	storageSessions, err := proc.storage.GetSessions(ctx, accountID)
+	if err != nil {
+		return nil, storageErrors.ToStatus(err)
+	}
+	digest := proc.cipher.SessionDigest(sessionKey)
+	var sessions []modeldto.Session
+	for _, session := range storageSessions {
+		sessions = append(sessions, modeldto.Session{
+			ID:            session.ID,
+			DeviceName:    session.DeviceName,
+			ClientVersion: session.ClientVersion,
+			Peer:          session.Peer,
+			CreatedAt:     session.CreatedAt,
+			LastSeenAt:    session.LastSeenAt,
+			Current:       session.Digest == digest,
+		})
+	}
+	return sessions, nil
+}
+
+// RevokeSession revokes a session of a user, requests of the device it belongs to are rejected from then on.
+func (proc *Processor) RevokeSession(ctx context.Context, userID string, sessionID int64) error {
+	accountID, err := proc.accountID(userID)
+	if err != nil {
+		return err
+	}
+	err = proc.storage.DeleteSession(ctx, accountID, sessionID)
+	var notFound *storageErrors.NotFoundError
+	if errors.As(err, &notFound) {
+		return status.Errorf(codes.NotFound, "session %d not found", sessionID)
+	}
+	return storageErrors.ToStatus(err)
+}
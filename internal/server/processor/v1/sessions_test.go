@@ -0,0 +1,51 @@
+package processor
+
+import (
+	"context"
+	"dk-go-gophkeeper/internal/mocks"
+	"dk-go-gophkeeper/internal/server/storage/modelstorage"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProcessor_CheckSession(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cipher := mocks.NewMockCipher(ctrl)
+	storage := mocks.NewMockDataStorage(ctrl)
+	cipher.EXPECT().ValidateToken("alice_token").Return("alice_id", nil).AnyTimes()
+	cipher.EXPECT().SessionDigest(gomock.Any()).DoAndReturn(func(key string) string { return key + "_digest" }).AnyTimes()
+	now := time.Now()
+	storage.EXPECT().GetSession(gomock.Any(), "alice_id", "recent_digest").Return(modelstorage.Session{
+		ID: 1, Peer: "10.0.0.1", CreatedAt: now.Add(-time.Hour), LastSeenAt: now,
+	}, nil)
+	storage.EXPECT().GetSession(gomock.Any(), "alice_id", "idle_digest").Return(modelstorage.Session{
+		ID: 2, Peer: "10.0.0.1", CreatedAt: now.Add(-48 * time.Hour), LastSeenAt: now.Add(-25 * time.Hour),
+	}, nil)
+	storage.EXPECT().GetSession(gomock.Any(), "alice_id", "old_digest").Return(modelstorage.Session{
+		ID: 3, Peer: "10.0.0.1", CreatedAt: now.Add(-8 * 24 * time.Hour), LastSeenAt: now,
+	}, nil)
+	// expired sessions are removed right away
+	storage.EXPECT().DeleteSession(gomock.Any(), "alice_id", int64(2)).Return(nil)
+	storage.EXPECT().DeleteSession(gomock.Any(), "alice_id", int64(3)).Return(nil)
+	storage.EXPECT().DeleteExpiredSessions(gomock.Any(), 24*time.Hour, 7*24*time.Hour).Return(int64(4), nil)
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	processor := InitService(storage, cipher, &logger)
+	processor.SetSessionTTL(24*time.Hour, 7*24*time.Hour)
+
+	// a session seen recently from the same IP address is not touched
+	err := processor.CheckSession(context.Background(), "alice_token", "recent", "10.0.0.1:5000")
+	assert.Equal(t, nil, err)
+	err = processor.CheckSession(context.Background(), "alice_token", "idle", "10.0.0.1:5000")
+	assert.Equal(t, "rpc error: code = Unauthenticated desc = session expired, log in again", err.Error())
+	err = processor.CheckSession(context.Background(), "alice_token", "old", "10.0.0.1:5000")
+	assert.Equal(t, "rpc error: code = Unauthenticated desc = session expired, log in again", err.Error())
+	deleted, err := processor.DeleteExpiredSessions(context.Background())
+	assert.Equal(t, nil, err)
+	assert.Equal(t, int64(4), deleted)
+}
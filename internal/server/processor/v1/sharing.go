@@ -0,0 +1,383 @@
+package processor
+
+import (
+	"context"
+	"dk-go-gophkeeper/internal/keys"
+	"dk-go-gophkeeper/internal/server/modeldto"
+	storageErrors "dk-go-gophkeeper/internal/server/storage/errors"
+	"dk-go-gophkeeper/internal/server/storage/modelstorage"
+	"errors"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// recipientID returns an ID of a registered user an entry is shared with.
+func (proc *Processor) recipientID(ctx context.Context, accountID, recipient string) (string, error) {
+	if strings.TrimSpace(recipient) == "" {
+		return "", status.Error(codes.InvalidArgument, "recipient cannot be empty")
+	}
+	recipientID, err := proc.storage.GetUserIDByLogin(ctx, proc.cipher.Encode(recipient))
+	var notFound *storageErrors.NotFoundError
+	if errors.As(err, &notFound) {
+		return "", status.Errorf(codes.NotFound, "user %s is not registered", recipient)
+	}
+	if err != nil {
+		return "", storageErrors.ToStatus(err)
+	}
+	if recipientID == accountID {
+		return "", status.Error(codes.InvalidArgument, "entries cannot be shared with their owner")
+	}
+	return recipientID, nil
+}
+
+// shareDB returns a DB identifier a shared entry of the named DB is stored under.
+func (proc *Processor) shareDB(db string) (string, error) {
+	storageDB := proc.storageDB(db)
+	if storageDB == "" {
+		return "", status.Errorf(codes.InvalidArgument, "invalid db %s", db)
+	}
+	return storageDB, nil
+}
+
+// checkSealed validates an entry sealed by a client, its record key must be wrapped for its owner and every recipient.
+func checkSealed(sealed modeldto.SealedEntry, identifier string, recipients []string) error {
+	if sealed.Payload == "" || sealed.OwnerKey == "" {
+		return status.Errorf(codes.InvalidArgument, "entry %s must be sealed with a record key wrapped for its owner", identifier)
+	}
+	for _, recipient := range recipients {
+		if sealed.WrappedKeys[recipient] == "" {
+			return status.Errorf(codes.FailedPrecondition, "record key of entry %s must be wrapped for %s", identifier, recipient)
+		}
+	}
+	return nil
+}
+
+// SetPublicKey publishes a public key of the key pair kept by a client of a user, record keys of entries shared with
+// the user are wrapped with it. A published key is never replaced, since record keys might be wrapped with it already.
+func (proc *Processor) SetPublicKey(ctx context.Context, userID, publicKey string) error {
+	if err := keys.ValidatePublicKey(publicKey); err != nil {
+		return status.Error(codes.InvalidArgument, "invalid public key")
+	}
+	accountID, err := proc.accountID(userID)
+	if err != nil {
+		return err
+	}
+	err = proc.storage.SetUserKeys(ctx, modelstorage.UserKeys{UserID: accountID, PublicKey: publicKey})
+	if err != nil {
+		return storageErrors.ToStatus(err)
+	}
+	// another client of the user might have published its key first
+	userKeys, err := proc.storage.GetUserKeys(ctx, accountID)
+	if err != nil {
+		return storageErrors.ToStatus(err)
+	}
+	if userKeys.PublicKey != publicKey {
+		return status.Error(codes.AlreadyExists, "another public key is already published")
+	}
+	return nil
+}
+
+// GetPublicKey retrieves a public key published by a registered user, the key of the user itself is retrieved for an
+// empty login.
+func (proc *Processor) GetPublicKey(ctx context.Context, userID, login string) (string, error) {
+	keyOwnerID, err := proc.accountID(userID)
+	if err != nil {
+		return "", err
+	}
+	if login != "" {
+		keyOwnerID, err = proc.userIDByLogin(ctx, login)
+		if err != nil {
+			return "", err
+		}
+	}
+	userKeys, err := proc.storage.GetUserKeys(ctx, keyOwnerID)
+	var notFound *storageErrors.NotFoundError
+	switch {
+	case errors.As(err, &notFound) && login == "":
+		return "", status.Error(codes.NotFound, "no public key is published")
+	case errors.As(err, &notFound):
+		return "", status.Errorf(codes.NotFound, "user %s has not published a public key yet", login)
+	case err != nil:
+		return "", storageErrors.ToStatus(err)
+	}
+	return userKeys.PublicKey, nil
+}
+
+// ShareEntry shares an entry with a user or changes the permission of a user the entry is already shared with. The
+// entry comes sealed by the client of the owner with a record key of its own, which is wrapped for the owner and the
+// recipient, so the server never sees the record key.
+func (proc *Processor) ShareEntry(ctx context.Context, userID, db, identifier, recipient, permission string, sealed modeldto.SealedEntry) error {
+	db, err := proc.shareDB(db)
+	if err != nil {
+		return err
+	}
+	if permission != modeldto.PermissionRead && permission != modeldto.PermissionReadWrite {
+		return status.Errorf(codes.InvalidArgument, "invalid permission %s", permission)
+	}
+	ownerID, err := proc.accountID(userID)
+	if err != nil {
+		return err
+	}
+	recipientID, err := proc.recipientID(ctx, ownerID, recipient)
+	if err != nil {
+		return err
+	}
+	if err = checkSealed(sealed, identifier, []string{recipient}); err != nil {
+		return err
+	}
+	encodedIdentifier := proc.cipher.Encode(identifier)
+	// only existing entries of the owner can be shared
+	if _, err = proc.storage.GetEntry(ctx, userID, db, encodedIdentifier); err != nil {
+		return storageErrors.ToStatus(err)
+	}
+	share, err := proc.storage.GetShare(ctx, ownerID, db, encodedIdentifier)
+	var notFound *storageErrors.NotFoundError
+	switch {
+	case errors.As(err, &notFound):
+		share = modelstorage.Share{OwnerID: ownerID, Db: db, Identifier: encodedIdentifier}
+	case err != nil:
+		return storageErrors.ToStatus(err)
+	}
+	share.Payload, share.OwnerKey = sealed.Payload, sealed.OwnerKey
+	newRecipient := modelstorage.ShareRecipient{RecipientID: recipientID, WrappedKey: sealed.WrappedKeys[recipient], Permission: permission}
+	recipients := make([]modelstorage.ShareRecipient, 0, len(share.Recipients)+1)
+	for _, shareRecipient := range share.Recipients {
+		if shareRecipient.RecipientID == recipientID {
+			continue
+		}
+		// an entry shared again gets a new record key, which is wrapped for recipients having published public keys
+		// of their clients, others are dropped
+		if share.ReshareRequired {
+			login, err := proc.cipher.Decode(shareRecipient.Login)
+			if err != nil {
+				return err
+			}
+			if sealed.WrappedKeys[login] == "" {
+				continue
+			}
+			shareRecipient.WrappedKey = sealed.WrappedKeys[login]
+		}
+		recipients = append(recipients, shareRecipient)
+	}
+	share.Recipients = append(recipients, newRecipient)
+	share.ReshareRequired = false
+	return storageErrors.ToStatus(proc.storage.SetShare(ctx, share))
+}
+
+// RevokeShare stops sharing an entry with a user. The client of the owner rotates the record key of the entry, so the
+// key the user might have kept opens no later revision of the entry, and wraps the new key for remaining recipients.
+func (proc *Processor) RevokeShare(ctx context.Context, userID, db, identifier, recipient string, sealed modeldto.SealedEntry) error {
+	db, err := proc.shareDB(db)
+	if err != nil {
+		return err
+	}
+	ownerID, err := proc.accountID(userID)
+	if err != nil {
+		return err
+	}
+	recipientID, err := proc.recipientID(ctx, ownerID, recipient)
+	if err != nil {
+		return err
+	}
+	share, err := proc.storage.GetShare(ctx, ownerID, db, proc.cipher.Encode(identifier))
+	var notFound *storageErrors.NotFoundError
+	if errors.As(err, &notFound) {
+		return status.Errorf(codes.NotFound, "entry %s is not shared", identifier)
+	}
+	if err != nil {
+		return storageErrors.ToStatus(err)
+	}
+	remaining := make([]modelstorage.ShareRecipient, 0, len(share.Recipients))
+	logins := make([]string, 0, len(share.Recipients))
+	for _, shareRecipient := range share.Recipients {
+		if shareRecipient.RecipientID == recipientID {
+			continue
+		}
+		login, err := proc.cipher.Decode(shareRecipient.Login)
+		if err != nil {
+			return err
+		}
+		remaining = append(remaining, shareRecipient)
+		logins = append(logins, login)
+	}
+	if len(remaining) == len(share.Recipients) {
+		return status.Errorf(codes.NotFound, "entry %s is not shared with %s", identifier, recipient)
+	}
+	share.Recipients = remaining
+	// nobody can open an entry to be shared again, so it is left sealed as it is
+	if len(remaining) == 0 || share.ReshareRequired {
+		return storageErrors.ToStatus(proc.storage.SetShare(ctx, share))
+	}
+	if err = checkSealed(sealed, identifier, logins); err != nil {
+		return err
+	}
+	share.Payload, share.OwnerKey = sealed.Payload, sealed.OwnerKey
+	for idx, login := range logins {
+		share.Recipients[idx].WrappedKey = sealed.WrappedKeys[login]
+	}
+	return storageErrors.ToStatus(proc.storage.SetShare(ctx, share))
+}
+
+// SetSharePayload replaces an entry shared by a user with its update sealed by the client of the user with the same
+// record key, so that everyone the entry is shared with gets the update.
+func (proc *Processor) SetSharePayload(ctx context.Context, userID, db, identifier, payload string) error {
+	db, err := proc.shareDB(db)
+	if err != nil {
+		return err
+	}
+	if payload == "" {
+		return status.Errorf(codes.InvalidArgument, "entry %s must be sealed with its record key", identifier)
+	}
+	ownerID, err := proc.accountID(userID)
+	if err != nil {
+		return err
+	}
+	share, err := proc.storage.GetShare(ctx, ownerID, db, proc.cipher.Encode(identifier))
+	var notFound *storageErrors.NotFoundError
+	if errors.As(err, &notFound) {
+		return status.Errorf(codes.NotFound, "entry %s is not shared", identifier)
+	}
+	if err != nil {
+		return storageErrors.ToStatus(err)
+	}
+	if share.ReshareRequired {
+		return status.Errorf(codes.FailedPrecondition, "entry %s must be shared again", identifier)
+	}
+	return storageErrors.ToStatus(proc.storage.SetSharePayload(ctx, share.ID, payload))
+}
+
+// GetShares retrieves entries a user shares along with the users they are shared with. Record keys wrapped for the
+// user and public keys of recipients are included, so that the client of the user can reseal the entries.
+func (proc *Processor) GetShares(ctx context.Context, userID string) ([]modeldto.Share, error) {
+	ownerID, err := proc.accountID(userID)
+	if err != nil {
+		return nil, err
+	}
+	storageShares, err := proc.storage.GetSharesByOwner(ctx, ownerID)
+	if err != nil {
+		return nil, storageErrors.ToStatus(err)
+	}
+	shares := make([]modeldto.Share, 0, len(storageShares))
+	for _, storageShare := range storageShares {
+		identifier, err := proc.cipher.Decode(storageShare.Identifier)
+		if err != nil {
+			return nil, err
+		}
+		share := modeldto.Share{
+			Db:              proc.requestDB(storageShare.Db),
+			Identifier:      identifier,
+			OwnerKey:        storageShare.OwnerKey,
+			UpdatedAt:       storageShare.UpdatedAt,
+			ReshareRequired: storageShare.ReshareRequired,
+		}
+		for _, storageRecipient := range storageShare.Recipients {
+			login, err := proc.cipher.Decode(storageRecipient.Login)
+			if err != nil {
+				return nil, err
+			}
+			share.Recipients = append(share.Recipients, modeldto.ShareRecipient{
+				Login:      login,
+				Permission: storageRecipient.Permission,
+				PublicKey:  storageRecipient.PublicKey,
+			})
+		}
+		shares = append(shares, share)
+	}
+	return shares, nil
+}
+
+// GetSharedWithMe retrieves entries shared with a user sealed along with their record keys wrapped for the user, the
+// client of the user opens them.
+func (proc *Processor) GetSharedWithMe(ctx context.Context, userID string) ([]modeldto.SharedEntry, error) {
+	recipientID, err := proc.accountID(userID)
+	if err != nil {
+		return nil, err
+	}
+	storageShares, err := proc.storage.GetSharedWithUser(ctx, recipientID)
+	if err != nil {
+		return nil, storageErrors.ToStatus(err)
+	}
+	entries := make([]modeldto.SharedEntry, 0, len(storageShares))
+	for _, storageShare := range storageShares {
+		recipient := storageShare.Recipients[0]
+		owner, err := proc.cipher.Decode(storageShare.OwnerLogin)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, modeldto.SharedEntry{
+			ID:         storageShare.ID,
+			Owner:      owner,
+			Permission: recipient.Permission,
+			Db:         proc.requestDB(storageShare.Db),
+			Payload:    storageShare.Payload,
+			WrappedKey: recipient.WrappedKey,
+			UpdatedAt:  storageShare.UpdatedAt,
+		})
+	}
+	return entries, nil
+}
+
+// UpdateSharedEntry performs an update of an entry shared with a user by a read-write permission. The update is stored
+// as the entry of its owner along with its copy the client of the user seals with the record key of the entry for
+// everyone the entry is shared with, both are stored at once.
+func (proc *Processor) UpdateSharedEntry(ctx context.Context, userID string, shareID int64, item modeldto.BatchItem, payload string) error {
+	recipientID, err := proc.accountID(userID)
+	if err != nil {
+		return err
+	}
+	share, err := proc.storage.GetShareByID(ctx, shareID)
+	var notFound *storageErrors.NotFoundError
+	if errors.As(err, &notFound) {
+		return status.Error(codes.NotFound, "shared entry not found")
+	}
+	if err != nil {
+		return storageErrors.ToStatus(err)
+	}
+	permission := ""
+	for _, recipient := range share.Recipients {
+		if recipient.RecipientID == recipientID {
+			permission = recipient.Permission
+		}
+	}
+	switch permission {
+	case "":
+		return status.Error(codes.NotFound, "shared entry not found")
+	case modeldto.PermissionRead:
+		return status.Error(codes.PermissionDenied, "entry is shared read-only")
+	}
+	if share.ReshareRequired {
+		return status.Error(codes.FailedPrecondition, "shared entry must be shared again by its owner")
+	}
+	if proc.storageDB(item.Db) != share.Db {
+		return status.Errorf(codes.InvalidArgument, "shared entry is not of db %s", item.Db)
+	}
+	if payload == "" {
+		return status.Error(codes.InvalidArgument, "shared entry must be sealed with its record key")
+	}
+	// the identifier of a shared entry cannot be changed by its recipients
+	identifier, err := proc.cipher.Decode(share.Identifier)
+	if err != nil {
+		return err
+	}
+	switch share.Db {
+	case batchBankCardDB:
+		item.BankCard.Identifier = identifier
+	case batchLoginPasswordDB:
+		item.LoginPassword.Identifier = identifier
+	case batchTextBinaryDB:
+		item.TextBinary.Identifier = identifier
+	}
+	// entries of the owner are keyed by the access token of the owner
+	ownerToken := proc.cipher.Encode(share.OwnerID)
+	results, storageItems, _ := proc.prepareBatch(ownerToken, []modeldto.BatchItem{item})
+	if results[0].Err != nil {
+		return results[0].Err
+	}
+	err = proc.storage.UpdateSharedEntry(ctx, ownerToken, storageItems[0], share.ID, payload)
+	if errors.As(err, &notFound) {
+		return status.Error(codes.NotFound, "shared entry not found")
+	}
+	return storageErrors.ToStatus(err)
+}
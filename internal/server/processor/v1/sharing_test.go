@@ -0,0 +1,267 @@
+package processor
+
+import (
+	"context"
+	"dk-go-gophkeeper/internal/keys"
+	"dk-go-gophkeeper/internal/mocks"
+	"dk-go-gophkeeper/internal/server/modeldto"
+	storageErrors "dk-go-gophkeeper/internal/server/storage/errors"
+	"dk-go-gophkeeper/internal/server/storage/modelstorage"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestProcessor_ShareEntry(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cipher := mocks.NewMockCipher(ctrl)
+	storage := mocks.NewMockDataStorage(ctrl)
+	cipher.EXPECT().Encode(gomock.Any()).DoAndReturn(func(data string) string { return "encoded_" + data }).AnyTimes()
+	cipher.EXPECT().Decode(gomock.Any()).DoAndReturn(func(msg string) (string, error) { return "decoded_" + msg, nil }).AnyTimes()
+	cipher.EXPECT().ValidateToken("alice_token").Return("alice_id", nil)
+	storage.EXPECT().GetUserIDByLogin(gomock.Any(), "encoded_bob").Return("bob_id", nil)
+	// entries are keyed by access tokens, shares by registered IDs
+	storage.EXPECT().GetEntry(gomock.Any(), "alice_token", "loginPassword", "encoded_staging").Return(modelstorage.BatchItem{
+		Db:            "loginPassword",
+		LoginPassword: modelstorage.LoginPasswordStorageEntry{Identifier: "encoded_staging", Login: "encoded_db_user"},
+	}, nil)
+	storage.EXPECT().GetShare(gomock.Any(), "alice_id", "loginPassword", "encoded_staging").Return(modelstorage.Share{}, &storageErrors.NotFoundError{})
+	// the entry comes sealed by the client of the owner
+	storage.EXPECT().SetShare(gomock.Any(), modelstorage.Share{
+		OwnerID:    "alice_id",
+		Db:         "loginPassword",
+		Identifier: "encoded_staging",
+		Payload:    "sealed_payload",
+		OwnerKey:   "alice_wrapped",
+		Recipients: []modelstorage.ShareRecipient{{RecipientID: "bob_id", WrappedKey: "bob_wrapped", Permission: modeldto.PermissionReadWrite}},
+	}).Return(nil)
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	processor := InitService(storage, cipher, &logger)
+	sealed := modeldto.SealedEntry{Payload: "sealed_payload", OwnerKey: "alice_wrapped", WrappedKeys: map[string]string{"bob": "bob_wrapped"}}
+	err := processor.ShareEntry(context.Background(), "alice_token", "loginPassword", "staging", "bob", modeldto.PermissionReadWrite, sealed)
+	assert.Equal(t, nil, err)
+}
+
+func TestProcessor_ShareEntryAgain(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cipher := mocks.NewMockCipher(ctrl)
+	storage := mocks.NewMockDataStorage(ctrl)
+	cipher.EXPECT().Encode(gomock.Any()).DoAndReturn(func(data string) string { return "encoded_" + data }).AnyTimes()
+	cipher.EXPECT().Decode(gomock.Any()).DoAndReturn(func(msg string) (string, error) { return strings.TrimPrefix(msg, "encoded_"), nil }).AnyTimes()
+	cipher.EXPECT().ValidateToken("alice_token").Return("alice_id", nil).Times(2)
+	storage.EXPECT().GetUserIDByLogin(gomock.Any(), "encoded_bob").Return("bob_id", nil)
+	storage.EXPECT().GetEntry(gomock.Any(), "alice_token", "textBinary", "encoded_notes").Return(modelstorage.BatchItem{Db: "textBinary"}, nil)
+	legacy := modelstorage.Share{
+		ID:              3,
+		OwnerID:         "alice_id",
+		Db:              "textBinary",
+		Identifier:      "encoded_notes",
+		Payload:         "legacy_payload",
+		OwnerKey:        "alice_legacy_wrapped",
+		ReshareRequired: true,
+		Recipients: []modelstorage.ShareRecipient{
+			{RecipientID: "bob_id", Login: "encoded_bob", WrappedKey: "bob_legacy_wrapped", Permission: modeldto.PermissionRead},
+			{RecipientID: "carol_id", Login: "encoded_carol", WrappedKey: "carol_legacy_wrapped", Permission: modeldto.PermissionRead},
+			{RecipientID: "dave_id", Login: "encoded_dave", WrappedKey: "dave_legacy_wrapped", Permission: modeldto.PermissionRead},
+		},
+	}
+	storage.EXPECT().GetShare(gomock.Any(), "alice_id", "textBinary", "encoded_notes").Return(legacy, nil).Times(2)
+	// recipients without public keys of their clients are dropped
+	storage.EXPECT().SetShare(gomock.Any(), modelstorage.Share{
+		ID:         3,
+		OwnerID:    "alice_id",
+		Db:         "textBinary",
+		Identifier: "encoded_notes",
+		Payload:    "new_payload",
+		OwnerKey:   "alice_new_wrapped",
+		Recipients: []modelstorage.ShareRecipient{
+			{RecipientID: "carol_id", Login: "encoded_carol", WrappedKey: "carol_new_wrapped", Permission: modeldto.PermissionRead},
+			{RecipientID: "bob_id", WrappedKey: "bob_new_wrapped", Permission: modeldto.PermissionReadWrite},
+		},
+	}).Return(nil)
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	processor := InitService(storage, cipher, &logger)
+	sealed := modeldto.SealedEntry{Payload: "new_payload", OwnerKey: "alice_new_wrapped", WrappedKeys: map[string]string{"bob": "bob_new_wrapped", "carol": "carol_new_wrapped"}}
+	err := processor.ShareEntry(context.Background(), "alice_token", "textBinary", "notes", "bob", modeldto.PermissionReadWrite, sealed)
+	assert.Equal(t, nil, err)
+	// nobody has the record key of the entry to seal its updates with
+	err = processor.SetSharePayload(context.Background(), "alice_token", "textBinary", "notes", "new_payload")
+	assert.Equal(t, "rpc error: code = FailedPrecondition desc = entry notes must be shared again", err.Error())
+}
+
+func TestProcessor_ShareEntryInvalid(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cipher := mocks.NewMockCipher(ctrl)
+	storage := mocks.NewMockDataStorage(ctrl)
+	cipher.EXPECT().Encode(gomock.Any()).DoAndReturn(func(data string) string { return "encoded_" + data }).AnyTimes()
+	cipher.EXPECT().ValidateToken("alice_token").Return("alice_id", nil).Times(3)
+	cipher.EXPECT().ValidateToken("forged_token").Return("", errors.New("message authentication failed"))
+	storage.EXPECT().GetUserIDByLogin(gomock.Any(), "encoded_alice").Return("alice_id", nil)
+	storage.EXPECT().GetUserIDByLogin(gomock.Any(), "encoded_carol").Return("", &storageErrors.NotFoundError{})
+	storage.EXPECT().GetUserIDByLogin(gomock.Any(), "encoded_bob").Return("bob_id", nil)
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	processor := InitService(storage, cipher, &logger)
+	var sealed modeldto.SealedEntry
+	err := processor.ShareEntry(context.Background(), "alice_token", "generic_db", "staging", "bob", modeldto.PermissionRead, sealed)
+	assert.Equal(t, "rpc error: code = InvalidArgument desc = invalid db generic_db", err.Error())
+	err = processor.ShareEntry(context.Background(), "alice_token", "loginPassword", "staging", "bob", "owner", sealed)
+	assert.Equal(t, "rpc error: code = InvalidArgument desc = invalid permission owner", err.Error())
+	err = processor.ShareEntry(context.Background(), "forged_token", "loginPassword", "staging", "bob", modeldto.PermissionRead, sealed)
+	assert.Equal(t, "rpc error: code = Unauthenticated desc = invalid access token", err.Error())
+	err = processor.ShareEntry(context.Background(), "alice_token", "loginPassword", "staging", "alice", modeldto.PermissionRead, sealed)
+	assert.Equal(t, "rpc error: code = InvalidArgument desc = entries cannot be shared with their owner", err.Error())
+	err = processor.ShareEntry(context.Background(), "alice_token", "loginPassword", "staging", "carol", modeldto.PermissionRead, sealed)
+	assert.Equal(t, "rpc error: code = NotFound desc = user carol is not registered", err.Error())
+	// record keys are never wrapped by the server
+	sealed = modeldto.SealedEntry{Payload: "sealed_payload", OwnerKey: "alice_wrapped"}
+	err = processor.ShareEntry(context.Background(), "alice_token", "loginPassword", "staging", "bob", modeldto.PermissionRead, sealed)
+	assert.Equal(t, "rpc error: code = FailedPrecondition desc = record key of entry staging must be wrapped for bob", err.Error())
+}
+
+func TestProcessor_RevokeShare(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cipher := mocks.NewMockCipher(ctrl)
+	storage := mocks.NewMockDataStorage(ctrl)
+	cipher.EXPECT().Encode(gomock.Any()).DoAndReturn(func(data string) string { return "encoded_" + data }).AnyTimes()
+	cipher.EXPECT().ValidateToken("alice_token").Return("alice_id", nil).Times(2)
+	storage.EXPECT().GetUserIDByLogin(gomock.Any(), "encoded_bob").Return("bob_id", nil).Times(2)
+	storage.EXPECT().GetShare(gomock.Any(), "alice_id", "textBinary", "encoded_notes").Return(modelstorage.Share{
+		ID:         3,
+		OwnerID:    "alice_id",
+		Db:         "textBinary",
+		Identifier: "encoded_notes",
+		Payload:    "old_payload",
+		OwnerKey:   "alice_old_wrapped",
+		Recipients: []modelstorage.ShareRecipient{
+			{RecipientID: "bob_id", Login: "encoded_bob", WrappedKey: "bob_old_wrapped", Permission: modeldto.PermissionRead},
+			{RecipientID: "carol_id", Login: "encoded_carol", WrappedKey: "carol_old_wrapped", Permission: modeldto.PermissionReadWrite},
+		},
+	}, nil).Times(2)
+	cipher.EXPECT().Decode(gomock.Any()).DoAndReturn(func(msg string) (string, error) { return strings.TrimPrefix(msg, "encoded_"), nil }).AnyTimes()
+	// the record key is rotated by the client of the owner and wrapped for the remaining users only
+	storage.EXPECT().SetShare(gomock.Any(), modelstorage.Share{
+		ID:         3,
+		OwnerID:    "alice_id",
+		Db:         "textBinary",
+		Identifier: "encoded_notes",
+		Payload:    "new_payload",
+		OwnerKey:   "alice_new_wrapped",
+		Recipients: []modelstorage.ShareRecipient{{RecipientID: "carol_id", Login: "encoded_carol", WrappedKey: "carol_new_wrapped", Permission: modeldto.PermissionReadWrite}},
+	}).Return(nil)
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	processor := InitService(storage, cipher, &logger)
+	err := processor.RevokeShare(context.Background(), "alice_token", "textBinary", "notes", "bob", modeldto.SealedEntry{Payload: "new_payload", OwnerKey: "alice_new_wrapped"})
+	assert.Equal(t, "rpc error: code = FailedPrecondition desc = record key of entry notes must be wrapped for carol", err.Error())
+	sealed := modeldto.SealedEntry{Payload: "new_payload", OwnerKey: "alice_new_wrapped", WrappedKeys: map[string]string{"carol": "carol_new_wrapped"}}
+	err = processor.RevokeShare(context.Background(), "alice_token", "textBinary", "notes", "bob", sealed)
+	assert.Equal(t, nil, err)
+}
+
+func TestProcessor_GetSharedWithMe(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cipher := mocks.NewMockCipher(ctrl)
+	storage := mocks.NewMockDataStorage(ctrl)
+	cipher.EXPECT().ValidateToken("bob_token").Return("bob_id", nil)
+	storage.EXPECT().GetSharedWithUser(gomock.Any(), "bob_id").Return([]modelstorage.Share{
+		{
+			ID:         3,
+			OwnerLogin: "encoded_alice",
+			Db:         "textBinary",
+			Payload:    "payload",
+			Recipients: []modelstorage.ShareRecipient{{RecipientID: "bob_id", WrappedKey: "bob_wrapped", Permission: modeldto.PermissionRead}},
+		},
+	}, nil)
+	cipher.EXPECT().Decode("encoded_alice").Return("alice", nil)
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	processor := InitService(storage, cipher, &logger)
+	entries, err := processor.GetSharedWithMe(context.Background(), "bob_token")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []modeldto.SharedEntry{{
+		ID:         3,
+		Owner:      "alice",
+		Permission: modeldto.PermissionRead,
+		Db:         "textBinary",
+		Payload:    "payload",
+		WrappedKey: "bob_wrapped",
+	}}, entries)
+}
+
+func TestProcessor_UpdateSharedEntry(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cipher := mocks.NewMockCipher(ctrl)
+	storage := mocks.NewMockDataStorage(ctrl)
+	cipher.EXPECT().ValidateToken(gomock.Any()).DoAndReturn(func(token string) (string, error) {
+		return strings.TrimSuffix(token, "_token") + "_id", nil
+	}).AnyTimes()
+	storage.EXPECT().GetShareByID(gomock.Any(), int64(3)).Return(modelstorage.Share{
+		ID:         3,
+		OwnerID:    "alice_id",
+		Db:         "textBinary",
+		Identifier: "encoded_notes",
+		Recipients: []modelstorage.ShareRecipient{
+			{RecipientID: "bob_id", Permission: modeldto.PermissionRead},
+			{RecipientID: "dave_id", Permission: modeldto.PermissionReadWrite},
+		},
+	}, nil).Times(3)
+	cipher.EXPECT().Encode(gomock.Any()).DoAndReturn(func(data string) string { return "encoded_" + data }).AnyTimes()
+	cipher.EXPECT().Decode(gomock.Any()).DoAndReturn(func(msg string) (string, error) { return strings.TrimPrefix(msg, "encoded_"), nil }).AnyTimes()
+	// the entry of the owner and its sealed copy are stored at once, recipients cannot rename the entry
+	storage.EXPECT().UpdateSharedEntry(gomock.Any(), "encoded_alice_id", gomock.Any(), int64(3), "sealed_payload").DoAndReturn(
+		func(ctx context.Context, ownerID string, item modelstorage.BatchItem, shareID int64, payload string) error {
+			assert.Equal(t, "encoded_notes", item.TextBinary.Identifier)
+			assert.Equal(t, "encoded_changed", item.TextBinary.Entry)
+			return nil
+		})
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	processor := InitService(storage, cipher, &logger)
+	item := modeldto.BatchItem{Db: "textBinary", TextBinary: modeldto.TextBinary{Identifier: "notes", Entry: "changed"}}
+	err := processor.UpdateSharedEntry(context.Background(), "bob_token", 3, item, "sealed_payload")
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	err = processor.UpdateSharedEntry(context.Background(), "carol_token", 3, item, "sealed_payload")
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	item.TextBinary.Identifier = "renamed"
+	err = processor.UpdateSharedEntry(context.Background(), "dave_token", 3, item, "sealed_payload")
+	assert.Equal(t, nil, err)
+}
+
+func TestProcessor_SetPublicKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cipher := mocks.NewMockCipher(ctrl)
+	storage := mocks.NewMockDataStorage(ctrl)
+	publicKey, _, _ := keys.NewKeyPair()
+	otherPublicKey, _, _ := keys.NewKeyPair()
+	cipher.EXPECT().Encode(gomock.Any()).DoAndReturn(func(data string) string { return "encoded_" + data }).AnyTimes()
+	cipher.EXPECT().ValidateToken("alice_token").Return("alice_id", nil).Times(4)
+	storage.EXPECT().SetUserKeys(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+	storage.EXPECT().GetUserKeys(gomock.Any(), "alice_id").Return(modelstorage.UserKeys{UserID: "alice_id", PublicKey: publicKey}, nil).Times(3)
+	storage.EXPECT().GetUserIDByLogin(gomock.Any(), "encoded_bob").Return("bob_id", nil)
+	storage.EXPECT().GetUserKeys(gomock.Any(), "bob_id").Return(modelstorage.UserKeys{}, &storageErrors.NotFoundError{})
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	processor := InitService(storage, cipher, &logger)
+	err := processor.SetPublicKey(context.Background(), "alice_token", "private_key")
+	assert.Equal(t, "rpc error: code = InvalidArgument desc = invalid public key", err.Error())
+	err = processor.SetPublicKey(context.Background(), "alice_token", publicKey)
+	assert.Equal(t, nil, err)
+	// a published key is kept, record keys might be wrapped with it already
+	err = processor.SetPublicKey(context.Background(), "alice_token", otherPublicKey)
+	assert.Equal(t, "rpc error: code = AlreadyExists desc = another public key is already published", err.Error())
+	key, err := processor.GetPublicKey(context.Background(), "alice_token", "")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, publicKey, key)
+	_, err = processor.GetPublicKey(context.Background(), "alice_token", "bob")
+	assert.Equal(t, "rpc error: code = NotFound desc = user bob has not published a public key yet", err.Error())
+}
@@ -29,7 +29,7 @@ type (
 	ScanningPSQLError struct {
 		Err error
 	}
-	InvalidPasswordError struct {
+	InvalidCredentialsError struct {
 		Err error
 	}
 )
@@ -62,6 +62,6 @@ func (e *ScanningPSQLError) Error() string {
 	return fmt.Sprintf("%s: could not scan rows", e.Err.Error())
 }
 
-func (e *InvalidPasswordError) Error() string {
-	return "password is invalid"
+func (e *InvalidCredentialsError) Error() string {
+	return "login or password is invalid"
 }
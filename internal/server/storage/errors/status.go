@@ -0,0 +1,53 @@
+package errors
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// messages of statuses storage errors are translated into, they leave out details such as encoded logins
+const (
+	MessageWrongDB            = "invalid DB identifier"
+	MessageAlreadyExists      = "already exists"
+	MessageNotFound           = "not found"
+	MessageInvalidCredentials = "invalid login or password"
+	MessageTimeout            = "request timed out"
+	MessageCanceled           = "request canceled"
+)
+
+// ToStatus translates a storage error into a status of the matching code, statuses are kept as they are. Statement,
+// execution and scanning errors as well as unknown ones become Internal and keep their messages, which must not reach
+// clients.
+func ToStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	var (
+		wrongDB            *WrongDBError
+		alreadyExists      *AlreadyExistsError
+		notFound           *NotFoundError
+		invalidCredentials *InvalidCredentialsError
+		timeoutExceeded    *ContextTimeoutExceededError
+	)
+	switch {
+	case errors.As(err, &wrongDB):
+		return status.Error(codes.InvalidArgument, MessageWrongDB)
+	case errors.As(err, &alreadyExists):
+		return status.Error(codes.AlreadyExists, MessageAlreadyExists)
+	case errors.As(err, &notFound):
+		return status.Error(codes.NotFound, MessageNotFound)
+	case errors.As(err, &invalidCredentials):
+		return status.Error(codes.Unauthenticated, MessageInvalidCredentials)
+	case errors.As(err, &timeoutExceeded), errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, MessageTimeout)
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, MessageCanceled)
+	}
+	return status.Error(codes.Internal, err.Error())
+}
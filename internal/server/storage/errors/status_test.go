@@ -0,0 +1,36 @@
+package errors
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestToStatus(t *testing.T) {
+	tests := []struct {
+		err     error
+		code    codes.Code
+		message string
+	}{
+		{err: &WrongDBError{ID: "generic_db"}, code: codes.InvalidArgument, message: MessageWrongDB},
+		{err: &AlreadyExistsError{ID: "generic_id"}, code: codes.AlreadyExists, message: MessageAlreadyExists},
+		{err: &NotFoundError{}, code: codes.NotFound, message: MessageNotFound},
+		{err: &InvalidCredentialsError{}, code: codes.Unauthenticated, message: MessageInvalidCredentials},
+		{err: &ContextTimeoutExceededError{Err: context.DeadlineExceeded}, code: codes.DeadlineExceeded, message: MessageTimeout},
+		{err: &StatementPSQLError{Err: errors.New("generic_error")}, code: codes.Internal, message: "generic_error: could not compile"},
+		{err: &ExecutionPSQLError{Err: errors.New("generic_error")}, code: codes.Internal, message: "generic_error: could not execute"},
+		{err: &ScanningPSQLError{Err: errors.New("generic_error")}, code: codes.Internal, message: "generic_error: could not scan rows"},
+		{err: context.Canceled, code: codes.Canceled, message: MessageCanceled},
+		{err: status.Error(codes.NotFound, "generic_error"), code: codes.NotFound, message: "generic_error"},
+		{err: nil, code: codes.OK, message: ""},
+	}
+	for _, tt := range tests {
+		st := status.Convert(ToStatus(tt.err))
+		assert.Equal(t, tt.code, st.Code())
+		assert.Equal(t, tt.message, st.Message())
+	}
+}
@@ -4,6 +4,7 @@ package storage
 import (
 	"context"
 	"dk-go-gophkeeper/internal/server/storage/modelstorage"
+	"time"
 )
 
 // BatchDeleter defines a set of methods for types implementing BatchDeleter.
@@ -21,16 +22,90 @@ type StorageAuthorizer interface {
 
 // Getter defines a set of methods for types implementing Getter.
 type Getter interface {
-	GetBankCardData(ctx context.Context, userID string) ([]modelstorage.BankCardStorageEntry, error)
-	GetLoginPasswordData(ctx context.Context, userID string) ([]modelstorage.LoginPasswordStorageEntry, error)
-	GetTextBinaryData(ctx context.Context, userID string) ([]modelstorage.TextBinaryStorageEntry, error)
+	GetBankCardData(ctx context.Context, userID string, afterID int64, limit int, tokens []string) ([]modelstorage.BankCardStorageEntry, error)
+	GetLoginPasswordData(ctx context.Context, userID string, afterID int64, limit int, tokens []string) ([]modelstorage.LoginPasswordStorageEntry, error)
+	GetTextBinaryData(ctx context.Context, userID string, afterID int64, limit int, tokens []string) ([]modelstorage.TextBinaryStorageEntry, error)
+	GetEntry(ctx context.Context, userID, db, identifier string) (modelstorage.BatchItem, error)
 }
 
 // Setter defines a set of methods for types implementing Setter.
 type Setter interface {
-	SetBankCardData(ctx context.Context, userID, identifier, number, holder, cvv, meta string) error
-	SetLoginPasswordData(ctx context.Context, userID, identifier, login, password, meta string) error
-	SetTextBinaryData(ctx context.Context, userID, identifier, entry, meta string) error
+	SetBankCardData(ctx context.Context, userID, identifier, number, holder, cvv, expiry, pin, meta string, labels modelstorage.Labels, fields string, tokens []string) error
+	SetLoginPasswordData(ctx context.Context, userID, identifier, login, password, meta string, labels modelstorage.Labels, fields string, tokens []string) error
+	SetTextBinaryData(ctx context.Context, userID, identifier, entry, meta string, labels modelstorage.Labels, fields string, tokens []string) error
+}
+
+// BatchSetter defines a set of methods for types implementing BatchSetter.
+type BatchSetter interface {
+	SetBatchData(ctx context.Context, userID string, items []modelstorage.BatchItem, overwrite bool) ([]modelstorage.BatchItemResult, error)
+}
+
+// Indexer defines a set of methods for types implementing Indexer.
+type Indexer interface {
+	SearchEntries(ctx context.Context, userID string, tokens []string, cursor modelstorage.SearchCursor, limit int) (modelstorage.SearchPage, error)
+}
+
+// Sharer defines a set of methods for types implementing Sharer.
+type Sharer interface {
+	GetUserIDByLogin(ctx context.Context, login string) (string, error)
+	GetUserKeys(ctx context.Context, userID string) (modelstorage.UserKeys, error)
+	SetUserKeys(ctx context.Context, keys modelstorage.UserKeys) error
+	GetShare(ctx context.Context, ownerID, db, identifier string) (modelstorage.Share, error)
+	GetShareByID(ctx context.Context, shareID int64) (modelstorage.Share, error)
+	GetSharesByOwner(ctx context.Context, ownerID string) ([]modelstorage.Share, error)
+	GetSharedWithUser(ctx context.Context, recipientID string) ([]modelstorage.Share, error)
+	SetShare(ctx context.Context, share modelstorage.Share) error
+	SetSharePayload(ctx context.Context, shareID int64, payload string) error
+	UpdateSharedEntry(ctx context.Context, ownerID string, item modelstorage.BatchItem, shareID int64, payload string) error
+}
+
+// CollectionManager defines a set of methods for types implementing CollectionManager.
+type CollectionManager interface {
+	AddCollection(ctx context.Context, collection modelstorage.Collection, owner modelstorage.CollectionMember, event modelstorage.CollectionEvent) error
+	GetCollections(ctx context.Context, userID string) ([]modelstorage.Collection, error)
+	DeleteCollection(ctx context.Context, collectionID, vaultID string, event modelstorage.CollectionEvent) error
+	GetMember(ctx context.Context, collectionID, userID string) (modelstorage.CollectionMember, error)
+	GetMembers(ctx context.Context, collectionID string) ([]modelstorage.CollectionMember, error)
+	SetMemberRole(ctx context.Context, member modelstorage.CollectionMember, event modelstorage.CollectionEvent) error
+	DeleteMember(ctx context.Context, collectionID, userID string, event modelstorage.CollectionEvent) error
+	SetInvitation(ctx context.Context, invitation modelstorage.CollectionInvitation, event modelstorage.CollectionEvent) error
+	GetInvitation(ctx context.Context, invitationID int64) (modelstorage.CollectionInvitation, error)
+	GetInvitations(ctx context.Context, inviteeID string) ([]modelstorage.CollectionInvitation, error)
+	ResolveInvitation(ctx context.Context, invitation modelstorage.CollectionInvitation, accept bool, event modelstorage.CollectionEvent) error
+	GetCollectionEvents(ctx context.Context, collectionID string) ([]modelstorage.CollectionEvent, error)
+}
+
+// SessionManager defines a set of methods for types implementing SessionManager.
+type SessionManager interface {
+	AddSession(ctx context.Context, session modelstorage.Session) (int64, error)
+	GetSession(ctx context.Context, userID, digest string) (modelstorage.Session, error)
+	GetSessions(ctx context.Context, userID string) ([]modelstorage.Session, error)
+	TouchSession(ctx context.Context, sessionID int64, peer string) error
+	DeleteExpiredSessions(ctx context.Context, idleTTL, maxAge time.Duration) (int64, error)
+	DeleteSession(ctx context.Context, userID string, sessionID int64) error
+}
+
+// EmergencyAccessKeeper defines a set of methods for types implementing EmergencyAccessKeeper.
+type EmergencyAccessKeeper interface {
+	SetTrustedContact(ctx context.Context, ownerID, contactID string, waitDays int) error
+	DeleteTrustedContact(ctx context.Context, ownerID, contactID string) error
+	GetEmergencyAccess(ctx context.Context, ownerID, contactID string) (modelstorage.EmergencyAccess, error)
+	GetTrustedContacts(ctx context.Context, ownerID string) ([]modelstorage.EmergencyAccess, error)
+	GetEmergencyGrantors(ctx context.Context, contactID string) ([]modelstorage.EmergencyAccess, error)
+	GetDueEmergencyAccess(ctx context.Context) ([]modelstorage.EmergencyAccess, error)
+	RequestEmergencyAccess(ctx context.Context, ownerID, contactID string) error
+	GrantEmergencyAccess(ctx context.Context, accessID int64) error
+	ResetEmergencyAccess(ctx context.Context, ownerID, contactID string) error
+	SetEmergencyEntries(ctx context.Context, vaultID string, entries []modelstorage.EmergencyEntry) error
+	GetEmergencyEntries(ctx context.Context, vaultID string, afterID int64, limit int) ([]modelstorage.EmergencyEntry, error)
+	GetEmergencyVault(ctx context.Context, accessID, afterID int64, limit int) ([]modelstorage.EmergencyEntry, error)
+}
+
+// AuditKeeper defines a set of methods for types implementing AuditKeeper.
+type AuditKeeper interface {
+	AddAuditEvent(ctx context.Context, event modelstorage.AuditEvent) (modelstorage.AuditEvent, error)
+	AddAuditCheckpoint(ctx context.Context, checkpoint modelstorage.AuditCheckpoint) error
+	GetAuditEvents(ctx context.Context, userID string, beforeID int64, limit int) ([]modelstorage.AuditEvent, error)
 }
 
 // DataStorage defines a set of methods for types implementing DataStorage.
@@ -39,4 +114,11 @@ type DataStorage interface {
 	BatchDeleter
 	Getter
 	Setter
+	BatchSetter
+	Indexer
+	Sharer
+	CollectionManager
+	AuditKeeper
+	SessionManager
+	EmergencyAccessKeeper
 }
@@ -1,5 +1,7 @@
 package modelstorage
 
+import "time"
+
 type Removal struct {
 	UserID     string
 	Identifier string
@@ -14,6 +16,23 @@ type UserStorageEntry struct {
 	RegisteredAt string `db:"registered_at"`
 }
 
+type Labels struct {
+	Folder   string `db:"folder"`
+	Tags     string `db:"tags"`
+	Favorite bool   `db:"favorite"`
+}
+
+type CustomField struct {
+	Name      string `json:"name"`
+	Value     string `json:"value"`
+	Concealed bool   `json:"concealed,omitempty"`
+}
+
+type Revision struct {
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
 type LoginPasswordStorageEntry struct {
 	ID         uint   `db:"id"`
 	UserID     string `db:"user_id"`
@@ -21,6 +40,10 @@ type LoginPasswordStorageEntry struct {
 	Login      string `db:"login"`
 	Password   string `db:"password"`
 	Meta       string `db:"cred_meta"`
+	Labels
+	Fields string `db:"custom_fields"`
+	Revision
+	PasswordChangedAt time.Time `db:"password_changed_at"`
 }
 
 type BankCardStorageEntry struct {
@@ -31,6 +54,11 @@ type BankCardStorageEntry struct {
 	Holder     string `db:"card_holder"`
 	CVV        string `db:"card_cvv"`
 	Meta       string `db:"card_meta"`
+	Labels
+	Fields string `db:"custom_fields"`
+	Revision
+	Expiry string `db:"card_expiry"`
+	PIN    string `db:"card_pin"`
 }
 
 type TextBinaryStorageEntry struct {
@@ -39,4 +67,163 @@ type TextBinaryStorageEntry struct {
 	Identifier string `db:"identifier"`
 	Entry      string `db:"text_entry"`
 	Meta       string `db:"text_meta"`
+	Labels
+	Fields string `db:"custom_fields"`
+	Revision
+}
+
+// BatchItem holds an entry of any type, Tokens replace search tokens of the entry once it is stored.
+type BatchItem struct {
+	Db            string
+	BankCard      BankCardStorageEntry
+	LoginPassword LoginPasswordStorageEntry
+	TextBinary    TextBinaryStorageEntry
+	Tokens        []string
+}
+
+type BatchItemResult struct {
+	Db         string
+	Identifier string
+	Created    bool
+}
+
+type BlindIndexEntry struct {
+	Db         string
+	Identifier string
+	Tokens     []string
+}
+
+type SearchCursor struct {
+	Db         string
+	Identifier string
+}
+
+type SearchPage struct {
+	Items      []BatchItem
+	NextCursor SearchCursor
+}
+
+type UserKeys struct {
+	UserID    string `db:"user_id"`
+	PublicKey string `db:"public_key"`
+}
+
+type ShareRecipient struct {
+	RecipientID string `db:"recipient_id"`
+	Login       string `db:"login"`
+	WrappedKey  string `db:"wrapped_key"`
+	Permission  string `db:"permission"`
+	PublicKey   string `db:"public_key"`
+}
+
+type Share struct {
+	ID              int64     `db:"id"`
+	OwnerID         string    `db:"owner_id"`
+	OwnerLogin      string    `db:"login"`
+	Db              string    `db:"db"`
+	Identifier      string    `db:"identifier"`
+	Payload         string    `db:"payload"`
+	OwnerKey        string    `db:"owner_key"`
+	UpdatedAt       time.Time `db:"updated_at"`
+	ReshareRequired bool      `db:"reshare_required"`
+	Recipients      []ShareRecipient
+}
+
+type Collection struct {
+	ID        string    `db:"collection_id"`
+	Name      string    `db:"name"`
+	Role      string    `db:"role"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+type CollectionMember struct {
+	CollectionID string    `db:"collection_id"`
+	UserID       string    `db:"user_id"`
+	Login        string    `db:"login"`
+	Role         string    `db:"role"`
+	JoinedAt     time.Time `db:"joined_at"`
+}
+
+type CollectionInvitation struct {
+	ID             int64     `db:"id"`
+	CollectionID   string    `db:"collection_id"`
+	CollectionName string    `db:"name"`
+	InviteeID      string    `db:"invitee_id"`
+	InviterID      string    `db:"inviter_id"`
+	InviterLogin   string    `db:"login"`
+	Role           string    `db:"role"`
+	CreatedAt      time.Time `db:"created_at"`
+}
+
+type CollectionEvent struct {
+	ID           int64     `db:"id"`
+	CollectionID string    `db:"collection_id"`
+	ActorID      string    `db:"actor_id"`
+	ActorLogin   string    `db:"actor_login"`
+	TargetID     string    `db:"target_id"`
+	TargetLogin  string    `db:"target_login"`
+	Action       string    `db:"action"`
+	Role         string    `db:"role"`
+	CreatedAt    time.Time `db:"created_at"`
+}
+
+type AuditEvent struct {
+	ID        int64     `db:"id"`
+	UserID    string    `db:"user_id"`
+	Seq       int64     `db:"seq"`
+	Action    string    `db:"action"`
+	Method    string    `db:"method"`
+	Peer      string    `db:"peer"`
+	Code      string    `db:"code"`
+	CreatedAt time.Time `db:"created_at"`
+	PrevHash  string    `db:"prev_hash"`
+	Hash      string    `db:"hash"`
+}
+
+type AuditCheckpoint struct {
+	ID        int64     `db:"id"`
+	UserID    string    `db:"user_id"`
+	Seq       int64     `db:"seq"`
+	Hash      string    `db:"hash"`
+	Signature string    `db:"signature"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+type Session struct {
+	ID            int64     `db:"id"`
+	UserID        string    `db:"user_id"`
+	Digest        string    `db:"digest"`
+	DeviceName    string    `db:"device_name"`
+	ClientVersion string    `db:"client_version"`
+	Peer          string    `db:"peer"`
+	CreatedAt     time.Time `db:"created_at"`
+	LastSeenAt    time.Time `db:"last_seen_at"`
+}
+
+type EmergencyAccess struct {
+	ID           int64     `db:"id"`
+	OwnerID      string    `db:"owner_id"`
+	OwnerLogin   string    `db:"owner_login"`
+	ContactID    string    `db:"contact_id"`
+	ContactLogin string    `db:"contact_login"`
+	WaitDays     int       `db:"wait_days"`
+	Status       string    `db:"status"`
+	RequestedAt  time.Time `db:"requested_at"`
+	GrantedAt    time.Time `db:"granted_at"`
+}
+
+type EmergencyEntry struct {
+	ID         int64     `db:"id"`
+	VaultID    string    `db:"vault_id"`
+	Db         string    `db:"db"`
+	Identifier string    `db:"identifier"`
+	Payload    string    `db:"payload"`
+	SealedAt   time.Time `db:"sealed_at"`
+	Keys       []EmergencyKey
+}
+
+type EmergencyKey struct {
+	AccessID     int64  `db:"access_id"`
+	ContactLogin string `db:"contact_login"`
+	WrappedKey   string `db:"wrapped_key"`
 }
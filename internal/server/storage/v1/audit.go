@@ -0,0 +1,145 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"dk-go-gophkeeper/internal/server/auditchain"
+	storageErrors "dk-go-gophkeeper/internal/server/storage/errors"
+	"dk-go-gophkeeper/internal/server/storage/modelstorage"
+	"errors"
+	"time"
+)
+
+// audit log queries, events are retrieved from the newest one
+const (
+	lockAuditChainQuery      = "SELECT pg_advisory_xact_lock(hashtext($1))"
+	selectAuditChainEndQuery = "SELECT seq, hash FROM audit_events WHERE user_id = $1 AND seq > 0 ORDER BY seq DESC LIMIT 1"
+	insertAuditEventQuery    = "INSERT INTO audit_events (user_id, seq, action, method, peer, code, created_at, prev_hash, hash) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id"
+	selectAuditEventsQuery   = "SELECT id, user_id, action, method, peer, code, created_at FROM audit_events WHERE user_id = $1 AND ($2 = 0 OR id < $2) ORDER BY id DESC LIMIT $3"
+	selectAuditChainsQuery   = "SELECT id, user_id, seq, action, method, peer, code, created_at, prev_hash, hash FROM audit_events WHERE seq > 0 ORDER BY user_id, seq"
+	insertCheckpointQuery    = "INSERT INTO audit_checkpoints (user_id, seq, hash, signature) VALUES ($1, $2, $3, $4)"
+	selectCheckpointsQuery   = "SELECT id, user_id, seq, hash, signature, created_at FROM audit_checkpoints ORDER BY user_id, seq"
+)
+
+// AddAuditEvent appends an event to the audit log of its user and chains it to the previous event of the user, the
+// appended event is returned along with its number and hash.
+func (s *Storage) AddAuditEvent(ctx context.Context, event modelstorage.AuditEvent) (modelstorage.AuditEvent, error) {
+	err := s.inTx(ctx, "adding audit event", false, func(tx *sql.Tx) error {
+		// concurrent appends would fork the chain of the user
+		_, err := tx.ExecContext(ctx, lockAuditChainQuery, event.UserID)
+		if err != nil {
+			return &storageErrors.ExecutionPSQLError{Err: err}
+		}
+		var seq int64
+		var prevHash string
+		err = tx.QueryRowContext(ctx, selectAuditChainEndQuery, event.UserID).Scan(&seq, &prevHash)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return &storageErrors.ExecutionPSQLError{Err: err}
+		}
+		event.Seq = seq + 1
+		event.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+		event.PrevHash = prevHash
+		event.Hash = auditchain.Hash(prevHash, event)
+		err = tx.QueryRowContext(ctx, insertAuditEventQuery, event.UserID, event.Seq, event.Action, event.Method, event.Peer,
+			event.Code, event.CreatedAt, event.PrevHash, event.Hash).Scan(&event.ID)
+		if err != nil {
+			return &storageErrors.ExecutionPSQLError{Err: err}
+		}
+		return nil
+	})
+	return event, err
+}
+
+// AddAuditCheckpoint stores a signed hash of an event of a user.
+func (s *Storage) AddAuditCheckpoint(ctx context.Context, checkpoint modelstorage.AuditCheckpoint) error {
+	return s.inTx(ctx, "adding audit checkpoint", false, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, insertCheckpointQuery, checkpoint.UserID, checkpoint.Seq, checkpoint.Hash, checkpoint.Signature)
+		if err != nil {
+			return &storageErrors.ExecutionPSQLError{Err: err}
+		}
+		return nil
+	})
+}
+
+// GetAuditEvents retrieves a page of the audit log of a user, the newest events first. Events older than beforeID are
+// retrieved unless it is zero.
+func (s *Storage) GetAuditEvents(ctx context.Context, userID string, beforeID int64, limit int) ([]modelstorage.AuditEvent, error) {
+	var events []modelstorage.AuditEvent
+	err := s.inTx(ctx, "getting audit events", true, func(tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, selectAuditEventsQuery, userID, beforeID, limit)
+		if err != nil {
+			return &storageErrors.ExecutionPSQLError{Err: err}
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var event modelstorage.AuditEvent
+			err = rows.Scan(&event.ID, &event.UserID, &event.Action, &event.Method, &event.Peer, &event.Code, &event.CreatedAt)
+			if err != nil {
+				return &storageErrors.ScanningPSQLError{Err: err}
+			}
+			events = append(events, event)
+		}
+		if err = rows.Err(); err != nil {
+			return &storageErrors.ScanningPSQLError{Err: err}
+		}
+		return nil
+	})
+	return events, err
+}
+
+// WalkAuditChains passes chained events of all users to fn ordered by user and then by their numbers, walking stops at
+// the first error of fn which is returned as is.
+func (s *Storage) WalkAuditChains(ctx context.Context, fn func(event modelstorage.AuditEvent) error) error {
+	var fnErr error
+	err := s.inTx(ctx, "walking audit chains", true, func(tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, selectAuditChainsQuery)
+		if err != nil {
+			return &storageErrors.ExecutionPSQLError{Err: err}
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var event modelstorage.AuditEvent
+			err = rows.Scan(&event.ID, &event.UserID, &event.Seq, &event.Action, &event.Method, &event.Peer, &event.Code,
+				&event.CreatedAt, &event.PrevHash, &event.Hash)
+			if err != nil {
+				return &storageErrors.ScanningPSQLError{Err: err}
+			}
+			if fnErr = fn(event); fnErr != nil {
+				return nil
+			}
+		}
+		if err = rows.Err(); err != nil {
+			return &storageErrors.ScanningPSQLError{Err: err}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return fnErr
+}
+
+// GetAuditCheckpoints retrieves checkpoints of all users.
+func (s *Storage) GetAuditCheckpoints(ctx context.Context) ([]modelstorage.AuditCheckpoint, error) {
+	var checkpoints []modelstorage.AuditCheckpoint
+	err := s.inTx(ctx, "getting audit checkpoints", true, func(tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, selectCheckpointsQuery)
+		if err != nil {
+			return &storageErrors.ExecutionPSQLError{Err: err}
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var checkpoint modelstorage.AuditCheckpoint
+			err = rows.Scan(&checkpoint.ID, &checkpoint.UserID, &checkpoint.Seq, &checkpoint.Hash, &checkpoint.Signature, &checkpoint.CreatedAt)
+			if err != nil {
+				return &storageErrors.ScanningPSQLError{Err: err}
+			}
+			checkpoints = append(checkpoints, checkpoint)
+		}
+		if err = rows.Err(); err != nil {
+			return &storageErrors.ScanningPSQLError{Err: err}
+		}
+		return nil
+	})
+	return checkpoints, err
+}
@@ -0,0 +1,144 @@
+package storage
+
+import (
+	storageErrors "dk-go-gophkeeper/internal/server/storage/errors"
+	"dk-go-gophkeeper/internal/server/storage/modelstorage"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// batchInsertSize limits the amount of rows inserted by a single multi-row statement.
+const batchInsertSize = 500
+
+// batchTable describes how entries of a single DB type are upserted.
+type batchTable struct {
+	table   string
+	columns []string
+	values  func(item modelstorage.BatchItem) []interface{}
+	// revisions are extra assignments refreshing revision timestamps of updated rows
+	revisions []string
+}
+
+// updatedAt refreshes the last modification timestamp of an updated row.
+const updatedAt = "updated_at = now()"
+
+// batchTables maps DB identifiers to their upsert descriptions, user_id and identifier always go first.
+var batchTables = map[string]batchTable{
+	"bankCard": {
+		table:   "bank_cards",
+		columns: []string{"user_id", "identifier", "card_number", "card_holder", "card_cvv", "card_meta", "folder", "tags", "favorite", "custom_fields", "card_expiry", "card_pin"},
+		values: func(item modelstorage.BatchItem) []interface{} {
+			return []interface{}{item.BankCard.Identifier, item.BankCard.Number, item.BankCard.Holder, item.BankCard.CVV, item.BankCard.Meta, item.BankCard.Folder, item.BankCard.Tags, item.BankCard.Favorite, item.BankCard.Fields, item.BankCard.Expiry, item.BankCard.PIN}
+		},
+		revisions: []string{updatedAt},
+	},
+	"loginPassword": {
+		table:   "logins_passwords",
+		columns: []string{"user_id", "identifier", "login", "password", "cred_meta", "folder", "tags", "favorite", "custom_fields"},
+		values: func(item modelstorage.BatchItem) []interface{} {
+			return []interface{}{item.LoginPassword.Identifier, item.LoginPassword.Login, item.LoginPassword.Password, item.LoginPassword.Meta, item.LoginPassword.Folder, item.LoginPassword.Tags, item.LoginPassword.Favorite, item.LoginPassword.Fields}
+		},
+		// encryption is deterministic, so equal ciphertexts mean the password was left intact
+		revisions: []string{updatedAt, "password_changed_at = CASE WHEN logins_passwords.password IS DISTINCT FROM EXCLUDED.password THEN now() ELSE logins_passwords.password_changed_at END"},
+	},
+	"textBinary": {
+		table:   "texts_binaries",
+		columns: []string{"user_id", "identifier", "text_entry", "text_meta", "folder", "tags", "favorite", "custom_fields"},
+		values: func(item modelstorage.BatchItem) []interface{} {
+			return []interface{}{item.TextBinary.Identifier, item.TextBinary.Entry, item.TextBinary.Meta, item.TextBinary.Folder, item.TextBinary.Tags, item.TextBinary.Favorite, item.TextBinary.Fields}
+		},
+		revisions: []string{updatedAt},
+	},
+}
+
+// batchTokens returns search tokens of batch items keyed by DB identifier and identifier, the last item wins as it does
+// in upserts.
+func batchTokens(items []modelstorage.BatchItem) map[string][]string {
+	tokens := make(map[string][]string, len(items))
+	for _, item := range items {
+		if table, ok := batchTables[item.Db]; ok {
+			tokens[fmt.Sprintf("%s/%v", item.Db, table.values(item)[0])] = item.Tokens
+		}
+	}
+	return tokens
+}
+
+// batchQuery defines a single multi-row upsert statement and its arguments.
+type batchQuery struct {
+	db   string
+	stmt string
+	args []interface{}
+}
+
+// buildBatchQueries groups batch items by DB type, deduplicates them by identifier (the last one wins)
+// and builds multi-row insert statements reporting whether each row was created or updated, existing rows are
+// updated only if overwrite is set.
+func buildBatchQueries(userID string, items []modelstorage.BatchItem, overwrite bool) ([]batchQuery, error) {
+	grouped := make(map[string][][]interface{})
+	positions := make(map[string]int)
+	var order []string
+	for _, item := range items {
+		table, ok := batchTables[item.Db]
+		if !ok {
+			return nil, &storageErrors.WrongDBError{
+				Err: errors.New("wrong DB identifier"),
+				ID:  item.Db,
+			}
+		}
+		if _, ok := grouped[item.Db]; !ok {
+			order = append(order, item.Db)
+		}
+		values := append([]interface{}{userID}, table.values(item)...)
+		key := fmt.Sprintf("%s/%v", item.Db, values[1])
+		if position, ok := positions[key]; ok {
+			grouped[item.Db][position] = values
+			continue
+		}
+		positions[key] = len(grouped[item.Db])
+		grouped[item.Db] = append(grouped[item.Db], values)
+	}
+	var queries []batchQuery
+	for _, db := range order {
+		table := batchTables[db]
+		rows := grouped[db]
+		for start := 0; start < len(rows); start += batchInsertSize {
+			end := start + batchInsertSize
+			if end > len(rows) {
+				end = len(rows)
+			}
+			queries = append(queries, buildUpsert(db, table, rows[start:end], overwrite))
+		}
+	}
+	return queries, nil
+}
+
+// buildUpsert builds a single multi-row insert statement for the given rows, conflicting rows are either updated
+// or skipped and then missing in the returned rows.
+func buildUpsert(db string, table batchTable, rows [][]interface{}, overwrite bool) batchQuery {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("INSERT INTO %s (%s) VALUES ", table.table, strings.Join(table.columns, ", ")))
+	args := make([]interface{}, 0, len(rows)*len(table.columns))
+	for i, row := range rows {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		placeholders := make([]string, len(row))
+		for j := range row {
+			placeholders[j] = fmt.Sprintf("$%d", len(args)+j+1)
+		}
+		sb.WriteString("(" + strings.Join(placeholders, ", ") + ")")
+		args = append(args, row...)
+	}
+	if !overwrite {
+		sb.WriteString(" ON CONFLICT (user_id, identifier) DO NOTHING RETURNING identifier, (xmax = 0) AS created")
+		return batchQuery{db: db, stmt: sb.String(), args: args}
+	}
+	updates := make([]string, 0, len(table.columns)-2)
+	for _, column := range table.columns[2:] {
+		updates = append(updates, fmt.Sprintf("%s = EXCLUDED.%s", column, column))
+	}
+	updates = append(updates, table.revisions...)
+	sb.WriteString(fmt.Sprintf(" ON CONFLICT (user_id, identifier) DO UPDATE SET %s RETURNING identifier, (xmax = 0) AS created", strings.Join(updates, ", ")))
+	return batchQuery{db: db, stmt: sb.String(), args: args}
+}
@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"dk-go-gophkeeper/internal/server/storage/modelstorage"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildBatchQueries(t *testing.T) {
+	items := []modelstorage.BatchItem{
+		{Db: "textBinary", TextBinary: modelstorage.TextBinaryStorageEntry{Identifier: "id1", Entry: "e1", Meta: "m1"}},
+		{Db: "bankCard", BankCard: modelstorage.BankCardStorageEntry{Identifier: "id2", Number: "n", Holder: "h", CVV: "c", Meta: "m"}},
+		{Db: "textBinary", TextBinary: modelstorage.TextBinaryStorageEntry{Identifier: "id1", Entry: "e2", Meta: "m2"}},
+		{Db: "textBinary", TextBinary: modelstorage.TextBinaryStorageEntry{Identifier: "id3", Entry: "e3", Meta: "m3", Labels: modelstorage.Labels{Folder: "f3", Tags: "t3", Favorite: true}, Fields: "cf3"}},
+	}
+	queries, err := buildBatchQueries("some_user_id", items, true)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, len(queries))
+	assert.Equal(t, "textBinary", queries[0].db)
+	expectedStmt := "INSERT INTO texts_binaries (user_id, identifier, text_entry, text_meta, folder, tags, favorite, custom_fields) VALUES ($1, $2, $3, $4, $5, $6, $7, $8), ($9, $10, $11, $12, $13, $14, $15, $16) " +
+		"ON CONFLICT (user_id, identifier) DO UPDATE SET text_entry = EXCLUDED.text_entry, text_meta = EXCLUDED.text_meta, " +
+		"folder = EXCLUDED.folder, tags = EXCLUDED.tags, favorite = EXCLUDED.favorite, custom_fields = EXCLUDED.custom_fields, updated_at = now() " +
+		"RETURNING identifier, (xmax = 0) AS created"
+	assert.Equal(t, expectedStmt, queries[0].stmt)
+	assert.Equal(t, []interface{}{"some_user_id", "id1", "e2", "m2", "", "", false, "", "some_user_id", "id3", "e3", "m3", "f3", "t3", true, "cf3"}, queries[0].args)
+	assert.Equal(t, "bankCard", queries[1].db)
+	assert.Equal(t, 12, len(queries[1].args))
+
+	queries, err = buildBatchQueries("some_user_id", items[:1], false)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "INSERT INTO texts_binaries (user_id, identifier, text_entry, text_meta, folder, tags, favorite, custom_fields) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) "+
+		"ON CONFLICT (user_id, identifier) DO NOTHING RETURNING identifier, (xmax = 0) AS created", queries[0].stmt)
+
+	_, err = buildBatchQueries("some_user_id", []modelstorage.BatchItem{{Db: "generic_db"}}, false)
+	assert.Equal(t, "generic_db: invalid DB indetifier", err.Error())
+}
+
+func TestBuildBatchQueriesChunks(t *testing.T) {
+	items := make([]modelstorage.BatchItem, 0, batchInsertSize+1)
+	for i := 0; i <= batchInsertSize; i++ {
+		identifier := string(rune('a'+i%26)) + string(rune('a'+i/26))
+		items = append(items, modelstorage.BatchItem{Db: "loginPassword", LoginPassword: modelstorage.LoginPasswordStorageEntry{Identifier: identifier}})
+	}
+	queries, err := buildBatchQueries("some_user_id", items, true)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, len(queries))
+	assert.Equal(t, batchInsertSize*9, len(queries[0].args))
+	assert.Equal(t, 9, len(queries[1].args))
+	assert.Equal(t, true, strings.HasSuffix(queries[1].stmt, "custom_fields = EXCLUDED.custom_fields, updated_at = now(), "+
+		"password_changed_at = CASE WHEN logins_passwords.password IS DISTINCT FROM EXCLUDED.password THEN now() ELSE logins_passwords.password_changed_at END "+
+		"RETURNING identifier, (xmax = 0) AS created"))
+}
+
+func TestBatchTokens(t *testing.T) {
+	items := []modelstorage.BatchItem{
+		{Db: "textBinary", TextBinary: modelstorage.TextBinaryStorageEntry{Identifier: "id1"}, Tokens: []string{"t1"}},
+		{Db: "bankCard", BankCard: modelstorage.BankCardStorageEntry{Identifier: "id1"}, Tokens: []string{"t2", "t3"}},
+		{Db: "textBinary", TextBinary: modelstorage.TextBinaryStorageEntry{Identifier: "id1"}, Tokens: []string{"t4"}},
+		{Db: "generic_db", Tokens: []string{"t5"}},
+	}
+	assert.Equal(t, map[string][]string{"textBinary/id1": {"t4"}, "bankCard/id1": {"t2", "t3"}}, batchTokens(items))
+}
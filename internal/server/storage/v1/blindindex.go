@@ -0,0 +1,213 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	storageErrors "dk-go-gophkeeper/internal/server/storage/errors"
+	"dk-go-gophkeeper/internal/server/storage/modelstorage"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// searchQuery selects entries having all the given tokens ordered by DB identifier and identifier, which stay the same
+// while tokens of an entry are replaced.
+const searchQuery = `SELECT db, identifier FROM blind_indexes
+WHERE user_id = $1 AND token = ANY($2) AND (db, identifier) > ($4, $5)
+GROUP BY db, identifier
+HAVING COUNT(DISTINCT token) = $3
+ORDER BY db, identifier
+LIMIT $6`
+
+// deleteBlindIndexesQuery removes all tokens of the given entries of a single DB type.
+const deleteBlindIndexesQuery = "DELETE FROM blind_indexes WHERE user_id = $1 AND db = $2 AND identifier = ANY($3)"
+
+// blindIndexQuery defines a single statement and its arguments.
+type blindIndexQuery struct {
+	stmt string
+	args []interface{}
+}
+
+// buildBlindIndexQueries builds statements replacing tokens of the given entries: tokens of each entry are removed
+// first, then new tokens are inserted by multi-row statements.
+func buildBlindIndexQueries(userID string, entries []modelstorage.BlindIndexEntry) []blindIndexQuery {
+	identifiers := make(map[string][]string)
+	var order []string
+	var rows [][]interface{}
+	for _, entry := range entries {
+		if _, ok := identifiers[entry.Db]; !ok {
+			order = append(order, entry.Db)
+		}
+		identifiers[entry.Db] = append(identifiers[entry.Db], entry.Identifier)
+		for _, token := range entry.Tokens {
+			rows = append(rows, []interface{}{userID, entry.Db, entry.Identifier, token})
+		}
+	}
+	queries := make([]blindIndexQuery, 0, len(order)+len(rows)/batchInsertSize+1)
+	for _, db := range order {
+		queries = append(queries, blindIndexQuery{stmt: deleteBlindIndexesQuery, args: []interface{}{userID, db, pq.Array(identifiers[db])}})
+	}
+	for start := 0; start < len(rows); start += batchInsertSize {
+		end := start + batchInsertSize
+		if end > len(rows) {
+			end = len(rows)
+		}
+		var sb strings.Builder
+		sb.WriteString("INSERT INTO blind_indexes (user_id, db, identifier, token) VALUES ")
+		args := make([]interface{}, 0, (end-start)*4)
+		for i, row := range rows[start:end] {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			n := len(args)
+			sb.WriteString(fmt.Sprintf("($%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4))
+			args = append(args, row...)
+		}
+		sb.WriteString(" ON CONFLICT DO NOTHING")
+		queries = append(queries, blindIndexQuery{stmt: sb.String(), args: args})
+	}
+	return queries
+}
+
+// replaceBlindIndexes replaces search tokens of the given entries within a transaction.
+func replaceBlindIndexes(ctx context.Context, tx *sql.Tx, userID string, entries []modelstorage.BlindIndexEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	for _, query := range buildBlindIndexQueries(userID, entries) {
+		if _, err := tx.ExecContext(ctx, query.stmt, query.args...); err != nil {
+			return &storageErrors.ExecutionPSQLError{Err: err}
+		}
+	}
+	return nil
+}
+
+// searchMatch defines an entry found by its tokens.
+type searchMatch struct {
+	db         string
+	identifier string
+}
+
+// SearchEntries retrieves up to limit entries having all the given tokens which are positioned after the cursor.
+// NextCursor of the returned page is empty if there are no more entries.
+func (s *Storage) SearchEntries(ctx context.Context, userID string, tokens []string, cursor modelstorage.SearchCursor, limit int) (modelstorage.SearchPage, error) {
+	chanOk := make(chan modelstorage.SearchPage, 1)
+	chanEr := make(chan error, 1)
+	go func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		tx, err := s.DB.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+		if err != nil {
+			chanEr <- &storageErrors.ExecutionPSQLError{Err: err}
+			return
+		}
+		defer func(tx *sql.Tx) {
+			err1 := tx.Rollback()
+			if err1 != nil {
+				return
+			}
+		}(tx)
+		// one extra row tells whether there is a next page
+		rows, err := tx.QueryContext(ctx, searchQuery, userID, pq.Array(tokens), uniqueCount(tokens), cursor.Db, cursor.Identifier, limit+1)
+		if err != nil {
+			chanEr <- &storageErrors.ExecutionPSQLError{Err: err}
+			return
+		}
+		var matches []searchMatch
+		for rows.Next() {
+			var match searchMatch
+			err = rows.Scan(&match.db, &match.identifier)
+			if err != nil {
+				rows.Close()
+				chanEr <- &storageErrors.ScanningPSQLError{Err: err}
+				return
+			}
+			matches = append(matches, match)
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			chanEr <- &storageErrors.ScanningPSQLError{Err: err}
+			return
+		}
+		var page modelstorage.SearchPage
+		if len(matches) > limit {
+			matches = matches[:limit]
+			page.NextCursor = modelstorage.SearchCursor{Db: matches[limit-1].db, Identifier: matches[limit-1].identifier}
+		}
+		identifiers := make(map[string][]string)
+		for _, match := range matches {
+			identifiers[match.db] = append(identifiers[match.db], match.identifier)
+		}
+		items := make(map[string]modelstorage.BatchItem, len(matches))
+		for db, dbIdentifiers := range identifiers {
+			err = s.fetchEntries(ctx, tx, userID, db, dbIdentifiers, items)
+			if err != nil {
+				chanEr <- err
+				return
+			}
+		}
+		for _, match := range matches {
+			// entries removed after being indexed are skipped
+			if item, ok := items[match.db+"/"+match.identifier]; ok {
+				page.Items = append(page.Items, item)
+			}
+		}
+		chanOk <- page
+	}()
+	select {
+	case <-ctx.Done():
+		s.logger.Error().Msg("searching entries failed due to context timeout")
+		return modelstorage.SearchPage{}, &storageErrors.ContextTimeoutExceededError{Err: ctx.Err()}
+	case methodErr := <-chanEr:
+		s.logger.Error().Err(methodErr).Msg("searching entries failed due to storage error")
+		return modelstorage.SearchPage{}, methodErr
+	case page := <-chanOk:
+		s.logger.Info().Msgf("searching entries done, %d found", len(page.Items))
+		return page, nil
+	}
+}
+
+// fetchEntries retrieves entries of a single DB type by their identifiers into items keyed by db and identifier.
+func (s *Storage) fetchEntries(ctx context.Context, tx *sql.Tx, userID, db string, identifiers []string, items map[string]modelstorage.BatchItem) error {
+	table, ok := batchTables[db]
+	if !ok {
+		return &storageErrors.WrongDBError{
+			Err: errors.New("wrong DB identifier"),
+			ID:  db,
+		}
+	}
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf("SELECT %s FROM %s WHERE user_id = $1 AND identifier = ANY($2)", entryColumns[db], table.table), userID, pq.Array(identifiers))
+	if err != nil {
+		return &storageErrors.ExecutionPSQLError{Err: err}
+	}
+	defer rows.Close()
+	for rows.Next() {
+		item := modelstorage.BatchItem{Db: db}
+		var identifier string
+		switch db {
+		case "bankCard":
+			entry := &item.BankCard
+			err = rows.Scan(&entry.ID, &entry.UserID, &entry.Identifier, &entry.Number, &entry.Holder, &entry.CVV, &entry.Meta, &entry.Folder, &entry.Tags, &entry.Favorite, &entry.Fields, &entry.CreatedAt, &entry.UpdatedAt, &entry.Expiry, &entry.PIN)
+			identifier = entry.Identifier
+		case "loginPassword":
+			entry := &item.LoginPassword
+			err = rows.Scan(&entry.ID, &entry.UserID, &entry.Identifier, &entry.Login, &entry.Password, &entry.Meta, &entry.Folder, &entry.Tags, &entry.Favorite, &entry.Fields, &entry.CreatedAt, &entry.UpdatedAt, &entry.PasswordChangedAt)
+			identifier = entry.Identifier
+		case "textBinary":
+			entry := &item.TextBinary
+			err = rows.Scan(&entry.ID, &entry.UserID, &entry.Identifier, &entry.Entry, &entry.Meta, &entry.Folder, &entry.Tags, &entry.Favorite, &entry.Fields, &entry.CreatedAt, &entry.UpdatedAt)
+			identifier = entry.Identifier
+		}
+		if err != nil {
+			return &storageErrors.ScanningPSQLError{Err: err}
+		}
+		items[db+"/"+identifier] = item
+	}
+	if err = rows.Err(); err != nil {
+		return &storageErrors.ScanningPSQLError{Err: err}
+	}
+	return nil
+}
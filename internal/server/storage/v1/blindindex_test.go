@@ -0,0 +1,35 @@
+package storage
+
+import (
+	"dk-go-gophkeeper/internal/server/storage/modelstorage"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildBlindIndexQueries(t *testing.T) {
+	entries := []modelstorage.BlindIndexEntry{
+		{Db: "loginPassword", Identifier: "id1", Tokens: []string{"t1", "t2"}},
+		{Db: "textBinary", Identifier: "id2", Tokens: []string{"t3"}},
+		{Db: "loginPassword", Identifier: "id3"},
+	}
+	queries := buildBlindIndexQueries("some_user_id", entries)
+	assert.Equal(t, 3, len(queries))
+	assert.Equal(t, deleteBlindIndexesQuery, queries[0].stmt)
+	assert.Equal(t, []interface{}{"some_user_id", "loginPassword", pq.Array([]string{"id1", "id3"})}, queries[0].args)
+	assert.Equal(t, []interface{}{"some_user_id", "textBinary", pq.Array([]string{"id2"})}, queries[1].args)
+	expectedStmt := "INSERT INTO blind_indexes (user_id, db, identifier, token) VALUES " +
+		"($1, $2, $3, $4), ($5, $6, $7, $8), ($9, $10, $11, $12) ON CONFLICT DO NOTHING"
+	assert.Equal(t, expectedStmt, queries[2].stmt)
+	assert.Equal(t, []interface{}{
+		"some_user_id", "loginPassword", "id1", "t1",
+		"some_user_id", "loginPassword", "id1", "t2",
+		"some_user_id", "textBinary", "id2", "t3",
+	}, queries[2].args)
+
+	tokens := make([]string, batchInsertSize+1)
+	queries = buildBlindIndexQueries("some_user_id", []modelstorage.BlindIndexEntry{{Db: "textBinary", Identifier: "id1", Tokens: tokens}})
+	assert.Equal(t, 3, len(queries))
+	assert.Equal(t, 4, len(queries[2].args))
+}
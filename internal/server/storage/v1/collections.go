@@ -0,0 +1,310 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	storageErrors "dk-go-gophkeeper/internal/server/storage/errors"
+	"dk-go-gophkeeper/internal/server/storage/modelstorage"
+	"errors"
+	"fmt"
+)
+
+// memberColumns lists columns of a collection member in the order they are scanned in.
+const memberColumns = "m.collection_id, m.user_id, u.login, m.role, m.joined_at"
+
+// invitationColumns lists columns of a collection invitation in the order they are scanned in.
+const invitationColumns = "i.id, i.collection_id, c.name, i.invitee_id, i.inviter_id, u.login, i.role, i.created_at"
+
+// collection queries, logins of members and inviters are joined from the users table
+const (
+	insertCollectionQuery       = "INSERT INTO collections (collection_id, name) VALUES ($1, $2)"
+	selectCollectionsQuery      = "SELECT c.collection_id, c.name, m.role, c.created_at FROM collection_members m JOIN collections c ON c.collection_id = m.collection_id WHERE m.user_id = $1 ORDER BY c.created_at, c.collection_id"
+	deleteCollectionQuery       = "DELETE FROM collections WHERE collection_id = $1"
+	insertMemberQuery           = "INSERT INTO collection_members (collection_id, user_id, role) VALUES ($1, $2, $3) ON CONFLICT (collection_id, user_id) DO NOTHING"
+	selectMemberQuery           = "SELECT " + memberColumns + " FROM collection_members m JOIN users u ON u.user_id = m.user_id WHERE m.collection_id = $1 AND m.user_id = $2"
+	selectMembersQuery          = "SELECT " + memberColumns + " FROM collection_members m JOIN users u ON u.user_id = m.user_id WHERE m.collection_id = $1 ORDER BY m.joined_at, u.login"
+	updateMemberRoleQuery       = "UPDATE collection_members SET role = $3 WHERE collection_id = $1 AND user_id = $2"
+	deleteMemberQuery           = "DELETE FROM collection_members WHERE collection_id = $1 AND user_id = $2"
+	upsertInvitationQuery       = "INSERT INTO collection_invitations (collection_id, invitee_id, inviter_id, role) VALUES ($1, $2, $3, $4) ON CONFLICT (collection_id, invitee_id) DO UPDATE SET inviter_id = EXCLUDED.inviter_id, role = EXCLUDED.role, created_at = now()"
+	selectInvitationQuery       = "SELECT " + invitationColumns + " FROM collection_invitations i JOIN collections c ON c.collection_id = i.collection_id JOIN users u ON u.user_id = i.inviter_id WHERE i.id = $1"
+	selectInvitationsQuery      = "SELECT " + invitationColumns + " FROM collection_invitations i JOIN collections c ON c.collection_id = i.collection_id JOIN users u ON u.user_id = i.inviter_id WHERE i.invitee_id = $1 ORDER BY i.id"
+	deleteInvitationQuery       = "DELETE FROM collection_invitations WHERE id = $1"
+	insertCollectionEventQuery  = "INSERT INTO collection_events (collection_id, actor_id, target_id, action, role) VALUES ($1, $2, $3, $4, $5)"
+	selectCollectionEventsQuery = "SELECT e.id, e.collection_id, e.actor_id, COALESCE(a.login, ''), e.target_id, COALESCE(t.login, ''), e.action, e.role, e.created_at FROM collection_events e LEFT JOIN users a ON a.user_id = e.actor_id LEFT JOIN users t ON t.user_id = e.target_id WHERE e.collection_id = $1 ORDER BY e.id"
+)
+
+// vaultTables lists tables keeping entries of a vault and their blind indexes.
+var vaultTables = []string{"logins_passwords", "texts_binaries", "bank_cards", "blind_indexes"}
+
+// buildVaultRemovalQueries builds statements removing every entry of a vault along with its blind indexes.
+func buildVaultRemovalQueries(vaultID string) []batchQuery {
+	queries := make([]batchQuery, 0, len(vaultTables))
+	for _, table := range vaultTables {
+		queries = append(queries, batchQuery{
+			stmt: fmt.Sprintf("DELETE FROM %s WHERE user_id = $1", table),
+			args: []interface{}{vaultID},
+		})
+	}
+	return queries
+}
+
+// addCollectionEvent appends a record of a membership change to the audit of a collection within a transaction.
+func addCollectionEvent(ctx context.Context, tx *sql.Tx, event modelstorage.CollectionEvent) error {
+	_, err := tx.ExecContext(ctx, insertCollectionEventQuery, event.CollectionID, event.ActorID, event.TargetID, event.Action, event.Role)
+	if err != nil {
+		return &storageErrors.ExecutionPSQLError{Err: err}
+	}
+	return nil
+}
+
+// execer is implemented by a DB and by its transactions.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// execAffecting executes a statement which must affect at least one row, the absence of rows is reported as not found.
+func execAffecting(ctx context.Context, tx execer, query string, args ...interface{}) error {
+	result, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return &storageErrors.ExecutionPSQLError{Err: err}
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return &storageErrors.ExecutionPSQLError{Err: err}
+	}
+	if affected == 0 {
+		return &storageErrors.NotFoundError{Err: sql.ErrNoRows}
+	}
+	return nil
+}
+
+// AddCollection stores a new collection along with its owner.
+func (s *Storage) AddCollection(ctx context.Context, collection modelstorage.Collection, owner modelstorage.CollectionMember, event modelstorage.CollectionEvent) error {
+	return s.inTx(ctx, "adding collection", false, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, insertCollectionQuery, collection.ID, collection.Name)
+		if err != nil {
+			return &storageErrors.ExecutionPSQLError{Err: err}
+		}
+		_, err = tx.ExecContext(ctx, insertMemberQuery, collection.ID, owner.UserID, owner.Role)
+		if err != nil {
+			return &storageErrors.ExecutionPSQLError{Err: err}
+		}
+		return addCollectionEvent(ctx, tx, event)
+	})
+}
+
+// GetCollections retrieves collections a user is a member of, each of them carries the role of the user.
+func (s *Storage) GetCollections(ctx context.Context, userID string) ([]modelstorage.Collection, error) {
+	var collections []modelstorage.Collection
+	err := s.inTx(ctx, "getting collections", true, func(tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, selectCollectionsQuery, userID)
+		if err != nil {
+			return &storageErrors.ExecutionPSQLError{Err: err}
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var collection modelstorage.Collection
+			err = rows.Scan(&collection.ID, &collection.Name, &collection.Role, &collection.CreatedAt)
+			if err != nil {
+				return &storageErrors.ScanningPSQLError{Err: err}
+			}
+			collections = append(collections, collection)
+		}
+		if err = rows.Err(); err != nil {
+			return &storageErrors.ScanningPSQLError{Err: err}
+		}
+		return nil
+	})
+	return collections, err
+}
+
+// DeleteCollection removes a collection along with its members, invitations and every entry of its vault. The audit
+// of the collection is kept.
+func (s *Storage) DeleteCollection(ctx context.Context, collectionID, vaultID string, event modelstorage.CollectionEvent) error {
+	return s.inTx(ctx, "deleting collection", false, func(tx *sql.Tx) error {
+		err := execAffecting(ctx, tx, deleteCollectionQuery, collectionID)
+		if err != nil {
+			return err
+		}
+		for _, query := range buildVaultRemovalQueries(vaultID) {
+			_, err = tx.ExecContext(ctx, query.stmt, query.args...)
+			if err != nil {
+				return &storageErrors.ExecutionPSQLError{Err: err}
+			}
+		}
+		return addCollectionEvent(ctx, tx, event)
+	})
+}
+
+// GetMember retrieves a member of a collection.
+func (s *Storage) GetMember(ctx context.Context, collectionID, userID string) (modelstorage.CollectionMember, error) {
+	var member modelstorage.CollectionMember
+	err := s.inTx(ctx, "getting collection member", true, func(tx *sql.Tx) error {
+		err := tx.QueryRowContext(ctx, selectMemberQuery, collectionID, userID).Scan(&member.CollectionID, &member.UserID, &member.Login, &member.Role, &member.JoinedAt)
+		if errors.Is(err, sql.ErrNoRows) {
+			return &storageErrors.NotFoundError{Err: err}
+		}
+		if err != nil {
+			return &storageErrors.ScanningPSQLError{Err: err}
+		}
+		return nil
+	})
+	return member, err
+}
+
+// GetMembers retrieves all members of a collection.
+func (s *Storage) GetMembers(ctx context.Context, collectionID string) ([]modelstorage.CollectionMember, error) {
+	var members []modelstorage.CollectionMember
+	err := s.inTx(ctx, "getting collection members", true, func(tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, selectMembersQuery, collectionID)
+		if err != nil {
+			return &storageErrors.ExecutionPSQLError{Err: err}
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var member modelstorage.CollectionMember
+			err = rows.Scan(&member.CollectionID, &member.UserID, &member.Login, &member.Role, &member.JoinedAt)
+			if err != nil {
+				return &storageErrors.ScanningPSQLError{Err: err}
+			}
+			members = append(members, member)
+		}
+		if err = rows.Err(); err != nil {
+			return &storageErrors.ScanningPSQLError{Err: err}
+		}
+		return nil
+	})
+	return members, err
+}
+
+// SetMemberRole changes the role of a member of a collection.
+func (s *Storage) SetMemberRole(ctx context.Context, member modelstorage.CollectionMember, event modelstorage.CollectionEvent) error {
+	return s.inTx(ctx, "setting collection member role", false, func(tx *sql.Tx) error {
+		err := execAffecting(ctx, tx, updateMemberRoleQuery, member.CollectionID, member.UserID, member.Role)
+		if err != nil {
+			return err
+		}
+		return addCollectionEvent(ctx, tx, event)
+	})
+}
+
+// DeleteMember removes a member from a collection.
+func (s *Storage) DeleteMember(ctx context.Context, collectionID, userID string, event modelstorage.CollectionEvent) error {
+	return s.inTx(ctx, "deleting collection member", false, func(tx *sql.Tx) error {
+		err := execAffecting(ctx, tx, deleteMemberQuery, collectionID, userID)
+		if err != nil {
+			return err
+		}
+		return addCollectionEvent(ctx, tx, event)
+	})
+}
+
+// SetInvitation stores an invitation to a collection, an earlier invitation of the same user is replaced.
+func (s *Storage) SetInvitation(ctx context.Context, invitation modelstorage.CollectionInvitation, event modelstorage.CollectionEvent) error {
+	return s.inTx(ctx, "setting collection invitation", false, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, upsertInvitationQuery, invitation.CollectionID, invitation.InviteeID, invitation.InviterID, invitation.Role)
+		if err != nil {
+			return &storageErrors.ExecutionPSQLError{Err: err}
+		}
+		return addCollectionEvent(ctx, tx, event)
+	})
+}
+
+// GetInvitation retrieves an invitation to a collection by its ID.
+func (s *Storage) GetInvitation(ctx context.Context, invitationID int64) (modelstorage.CollectionInvitation, error) {
+	var invitations []modelstorage.CollectionInvitation
+	err := s.inTx(ctx, "getting collection invitation", true, func(tx *sql.Tx) error {
+		var err error
+		invitations, err = queryInvitations(ctx, tx, selectInvitationQuery, invitationID)
+		if err != nil {
+			return err
+		}
+		if len(invitations) == 0 {
+			return &storageErrors.NotFoundError{Err: sql.ErrNoRows}
+		}
+		return nil
+	})
+	if err != nil {
+		return modelstorage.CollectionInvitation{}, err
+	}
+	return invitations[0], nil
+}
+
+// GetInvitations retrieves pending invitations of a user.
+func (s *Storage) GetInvitations(ctx context.Context, inviteeID string) ([]modelstorage.CollectionInvitation, error) {
+	var invitations []modelstorage.CollectionInvitation
+	err := s.inTx(ctx, "getting collection invitations", true, func(tx *sql.Tx) error {
+		var err error
+		invitations, err = queryInvitations(ctx, tx, selectInvitationsQuery, inviteeID)
+		return err
+	})
+	return invitations, err
+}
+
+// ResolveInvitation removes an invitation to a collection, an accepted invitation makes the invitee a member with the
+// offered role.
+func (s *Storage) ResolveInvitation(ctx context.Context, invitation modelstorage.CollectionInvitation, accept bool, event modelstorage.CollectionEvent) error {
+	return s.inTx(ctx, "resolving collection invitation", false, func(tx *sql.Tx) error {
+		// a concurrent response might have resolved the invitation first
+		err := execAffecting(ctx, tx, deleteInvitationQuery, invitation.ID)
+		if err != nil {
+			return err
+		}
+		if accept {
+			_, err = tx.ExecContext(ctx, insertMemberQuery, invitation.CollectionID, invitation.InviteeID, invitation.Role)
+			if err != nil {
+				return &storageErrors.ExecutionPSQLError{Err: err}
+			}
+		}
+		return addCollectionEvent(ctx, tx, event)
+	})
+}
+
+// GetCollectionEvents retrieves the audit of membership changes of a collection in the order they happened.
+func (s *Storage) GetCollectionEvents(ctx context.Context, collectionID string) ([]modelstorage.CollectionEvent, error) {
+	var events []modelstorage.CollectionEvent
+	err := s.inTx(ctx, "getting collection events", true, func(tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, selectCollectionEventsQuery, collectionID)
+		if err != nil {
+			return &storageErrors.ExecutionPSQLError{Err: err}
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var event modelstorage.CollectionEvent
+			err = rows.Scan(&event.ID, &event.CollectionID, &event.ActorID, &event.ActorLogin, &event.TargetID, &event.TargetLogin,
+				&event.Action, &event.Role, &event.CreatedAt)
+			if err != nil {
+				return &storageErrors.ScanningPSQLError{Err: err}
+			}
+			events = append(events, event)
+		}
+		if err = rows.Err(); err != nil {
+			return &storageErrors.ScanningPSQLError{Err: err}
+		}
+		return nil
+	})
+	return events, err
+}
+
+// queryInvitations retrieves invitations to collections selected by a query.
+func queryInvitations(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) ([]modelstorage.CollectionInvitation, error) {
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, &storageErrors.ExecutionPSQLError{Err: err}
+	}
+	defer rows.Close()
+	var invitations []modelstorage.CollectionInvitation
+	for rows.Next() {
+		var invitation modelstorage.CollectionInvitation
+		err = rows.Scan(&invitation.ID, &invitation.CollectionID, &invitation.CollectionName, &invitation.InviteeID, &invitation.InviterID,
+			&invitation.InviterLogin, &invitation.Role, &invitation.CreatedAt)
+		if err != nil {
+			return nil, &storageErrors.ScanningPSQLError{Err: err}
+		}
+		invitations = append(invitations, invitation)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, &storageErrors.ScanningPSQLError{Err: err}
+	}
+	return invitations, nil
+}
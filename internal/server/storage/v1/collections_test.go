@@ -0,0 +1,22 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildVaultRemovalQueries(t *testing.T) {
+	queries := buildVaultRemovalQueries("collection/1")
+	stmts := make([]string, 0, len(queries))
+	for _, query := range queries {
+		stmts = append(stmts, query.stmt)
+		assert.Equal(t, []interface{}{"collection/1"}, query.args)
+	}
+	assert.Equal(t, []string{
+		"DELETE FROM logins_passwords WHERE user_id = $1",
+		"DELETE FROM texts_binaries WHERE user_id = $1",
+		"DELETE FROM bank_cards WHERE user_id = $1",
+		"DELETE FROM blind_indexes WHERE user_id = $1",
+	}, stmts)
+}
@@ -0,0 +1,279 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	storageErrors "dk-go-gophkeeper/internal/server/storage/errors"
+	"dk-go-gophkeeper/internal/server/storage/modelstorage"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// emergencyColumns lists columns of emergency access in the order they are scanned in, logins of owners and contacts
+// are joined from the users table.
+const emergencyColumns = "e.id, e.owner_id, o.login, e.contact_id, c.login, e.wait_days, e.status, e.requested_at, e.granted_at"
+
+// emergencyJoin joins logins of owners and contacts to emergency access.
+const emergencyJoin = " FROM emergency_access e JOIN users o ON o.user_id = e.owner_id JOIN users c ON c.user_id = e.contact_id"
+
+// emergency access queries, state changes are conditional on the current state so that concurrent changes of the
+// owner, the contact and the timer job do not overwrite each other
+const (
+	upsertTrustedContactQuery   = "INSERT INTO emergency_access (owner_id, contact_id, wait_days) VALUES ($1, $2, $3) ON CONFLICT (owner_id, contact_id) DO UPDATE SET wait_days = EXCLUDED.wait_days"
+	deleteTrustedContactQuery   = "DELETE FROM emergency_access WHERE owner_id = $1 AND contact_id = $2"
+	selectEmergencyAccessQuery  = "SELECT " + emergencyColumns + emergencyJoin + " WHERE e.owner_id = $1 AND e.contact_id = $2"
+	selectTrustedContactsQuery  = "SELECT " + emergencyColumns + emergencyJoin + " WHERE e.owner_id = $1 ORDER BY e.id"
+	selectGrantorsQuery         = "SELECT " + emergencyColumns + emergencyJoin + " WHERE e.contact_id = $1 ORDER BY e.id"
+	selectDueEmergencyQuery     = "SELECT " + emergencyColumns + emergencyJoin + " WHERE e.status = 'requested' AND e.requested_at + make_interval(days => e.wait_days) <= now() ORDER BY e.requested_at"
+	requestEmergencyAccessQuery = "UPDATE emergency_access SET status = 'requested', requested_at = now() WHERE owner_id = $1 AND contact_id = $2 AND status = 'designated'"
+	grantEmergencyAccessQuery   = "UPDATE emergency_access SET status = 'granted', granted_at = now() WHERE id = $1 AND status = 'requested'"
+	resetEmergencyAccessQuery   = "UPDATE emergency_access SET status = 'designated', requested_at = NULL, granted_at = NULL WHERE owner_id = $1 AND contact_id = $2 AND status <> 'designated'"
+)
+
+// emergency entry queries, entries are sealed only if they exist in the vault of the owner and logins of contacts are
+// joined from the users table
+const (
+	upsertEmergencyEntryQuery   = "INSERT INTO emergency_entries (vault_id, db, identifier, payload, sealed_at) SELECT $1, $2, $3, $4, now() WHERE EXISTS (SELECT 1 FROM %s WHERE user_id = $1 AND identifier = $3) ON CONFLICT (vault_id, db, identifier) DO UPDATE SET payload = EXCLUDED.payload, sealed_at = now() RETURNING id"
+	deleteEmergencyKeysQuery    = "DELETE FROM emergency_keys WHERE entry_id = $1"
+	selectEmergencyEntriesQuery = "SELECT e.id, e.db, e.identifier, e.sealed_at, COALESCE(u.login, '') FROM emergency_entries e LEFT JOIN (emergency_keys k JOIN emergency_access a ON a.id = k.access_id JOIN users u ON u.user_id = a.contact_id) ON k.entry_id = e.id WHERE e.id IN (SELECT id FROM emergency_entries WHERE vault_id = $1 AND id > $2 ORDER BY id LIMIT $3) ORDER BY e.id, u.login"
+	selectEmergencyVaultQuery   = "SELECT e.id, e.db, e.identifier, e.payload, e.sealed_at, k.wrapped_key FROM emergency_keys k JOIN emergency_entries e ON e.id = k.entry_id WHERE k.access_id = $1 AND e.id > $2 ORDER BY e.id LIMIT $3"
+	deleteEmergencyEntriesQuery = "DELETE FROM emergency_entries WHERE vault_id = $1 AND db = $2 AND identifier = ANY($3)"
+)
+
+// buildEmergencyKeysQuery builds a multi-row statement adding record keys of an emergency entry wrapped for trusted
+// contacts.
+func buildEmergencyKeysQuery(entryID int64, keys []modelstorage.EmergencyKey) (string, []interface{}) {
+	var sb strings.Builder
+	sb.WriteString("INSERT INTO emergency_keys (entry_id, access_id, wrapped_key) VALUES ")
+	args := make([]interface{}, 0, len(keys)*3)
+	for i, key := range keys {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		n := len(args)
+		sb.WriteString(fmt.Sprintf("($%d, $%d, $%d)", n+1, n+2, n+3))
+		args = append(args, entryID, key.AccessID, key.WrappedKey)
+	}
+	return sb.String(), args
+}
+
+// scanEmergencyAccess scans a row of emergency access, times of requests and grants are zero unless set.
+func scanEmergencyAccess(row rowScanner) (modelstorage.EmergencyAccess, error) {
+	var access modelstorage.EmergencyAccess
+	var requestedAt, grantedAt sql.NullTime
+	err := row.Scan(&access.ID, &access.OwnerID, &access.OwnerLogin, &access.ContactID, &access.ContactLogin, &access.WaitDays,
+		&access.Status, &requestedAt, &grantedAt)
+	access.RequestedAt = requestedAt.Time
+	access.GrantedAt = grantedAt.Time
+	return access, err
+}
+
+// queryEmergencyAccess retrieves a list of emergency access within a transaction.
+func queryEmergencyAccess(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) ([]modelstorage.EmergencyAccess, error) {
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, &storageErrors.ExecutionPSQLError{Err: err}
+	}
+	defer rows.Close()
+	var list []modelstorage.EmergencyAccess
+	for rows.Next() {
+		access, err := scanEmergencyAccess(rows)
+		if err != nil {
+			return nil, &storageErrors.ScanningPSQLError{Err: err}
+		}
+		list = append(list, access)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, &storageErrors.ScanningPSQLError{Err: err}
+	}
+	return list, nil
+}
+
+// SetTrustedContact designates a trusted contact of a vault owner or changes the waiting period of an existing one.
+func (s *Storage) SetTrustedContact(ctx context.Context, ownerID, contactID string, waitDays int) error {
+	return s.inTx(ctx, "setting trusted contact", false, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, upsertTrustedContactQuery, ownerID, contactID, waitDays)
+		if err != nil {
+			return &storageErrors.ExecutionPSQLError{Err: err}
+		}
+		return nil
+	})
+}
+
+// DeleteTrustedContact removes a trusted contact of a vault owner along with any access granted to the contact.
+func (s *Storage) DeleteTrustedContact(ctx context.Context, ownerID, contactID string) error {
+	return s.inTx(ctx, "deleting trusted contact", false, func(tx *sql.Tx) error {
+		return execAffecting(ctx, tx, deleteTrustedContactQuery, ownerID, contactID)
+	})
+}
+
+// GetEmergencyAccess retrieves emergency access of a trusted contact to the vault of an owner.
+func (s *Storage) GetEmergencyAccess(ctx context.Context, ownerID, contactID string) (modelstorage.EmergencyAccess, error) {
+	var access modelstorage.EmergencyAccess
+	err := s.inTx(ctx, "getting emergency access", true, func(tx *sql.Tx) error {
+		var err error
+		access, err = scanEmergencyAccess(tx.QueryRowContext(ctx, selectEmergencyAccessQuery, ownerID, contactID))
+		if errors.Is(err, sql.ErrNoRows) {
+			return &storageErrors.NotFoundError{Err: err}
+		}
+		if err != nil {
+			return &storageErrors.ScanningPSQLError{Err: err}
+		}
+		return nil
+	})
+	return access, err
+}
+
+// GetTrustedContacts retrieves trusted contacts of a vault owner.
+func (s *Storage) GetTrustedContacts(ctx context.Context, ownerID string) ([]modelstorage.EmergencyAccess, error) {
+	var contacts []modelstorage.EmergencyAccess
+	err := s.inTx(ctx, "getting trusted contacts", true, func(tx *sql.Tx) error {
+		var err error
+		contacts, err = queryEmergencyAccess(ctx, tx, selectTrustedContactsQuery, ownerID)
+		return err
+	})
+	return contacts, err
+}
+
+// GetEmergencyGrantors retrieves emergency access of a user to vaults of owners who designated the user as their
+// trusted contact.
+func (s *Storage) GetEmergencyGrantors(ctx context.Context, contactID string) ([]modelstorage.EmergencyAccess, error) {
+	var grantors []modelstorage.EmergencyAccess
+	err := s.inTx(ctx, "getting emergency grantors", true, func(tx *sql.Tx) error {
+		var err error
+		grantors, err = queryEmergencyAccess(ctx, tx, selectGrantorsQuery, contactID)
+		return err
+	})
+	return grantors, err
+}
+
+// GetDueEmergencyAccess retrieves requests of emergency access whose waiting periods are over.
+func (s *Storage) GetDueEmergencyAccess(ctx context.Context) ([]modelstorage.EmergencyAccess, error) {
+	var due []modelstorage.EmergencyAccess
+	err := s.inTx(ctx, "getting due emergency access", true, func(tx *sql.Tx) error {
+		var err error
+		due, err = queryEmergencyAccess(ctx, tx, selectDueEmergencyQuery)
+		return err
+	})
+	return due, err
+}
+
+// RequestEmergencyAccess starts the waiting period of a trusted contact, access which is already requested or
+// granted is not found.
+func (s *Storage) RequestEmergencyAccess(ctx context.Context, ownerID, contactID string) error {
+	return s.inTx(ctx, "requesting emergency access", false, func(tx *sql.Tx) error {
+		return execAffecting(ctx, tx, requestEmergencyAccessQuery, ownerID, contactID)
+	})
+}
+
+// GrantEmergencyAccess grants a pending request of a trusted contact, a request which was rejected meanwhile is not
+// found.
+func (s *Storage) GrantEmergencyAccess(ctx context.Context, accessID int64) error {
+	return s.inTx(ctx, "granting emergency access", false, func(tx *sql.Tx) error {
+		return execAffecting(ctx, tx, grantEmergencyAccessQuery, accessID)
+	})
+}
+
+// ResetEmergencyAccess rejects a request of a trusted contact or revokes access granted to the contact, entries stay
+// sealed for the contact.
+func (s *Storage) ResetEmergencyAccess(ctx context.Context, ownerID, contactID string) error {
+	return s.inTx(ctx, "resetting emergency access", false, func(tx *sql.Tx) error {
+		return execAffecting(ctx, tx, resetEmergencyAccessQuery, ownerID, contactID)
+	})
+}
+
+// SetEmergencyEntries stores entries of a vault sealed for trusted contacts of its owner replacing their payloads and
+// wrapped keys. Entries which are not in the vault anymore are skipped.
+func (s *Storage) SetEmergencyEntries(ctx context.Context, vaultID string, entries []modelstorage.EmergencyEntry) error {
+	return s.inTx(ctx, "setting emergency entries", false, func(tx *sql.Tx) error {
+		for _, entry := range entries {
+			table, ok := batchTables[entry.Db]
+			if !ok {
+				return &storageErrors.WrongDBError{Err: errors.New("wrong DB identifier"), ID: entry.Db}
+			}
+			var entryID int64
+			err := tx.QueryRowContext(ctx, fmt.Sprintf(upsertEmergencyEntryQuery, table.table), vaultID, entry.Db, entry.Identifier, entry.Payload).Scan(&entryID)
+			if errors.Is(err, sql.ErrNoRows) {
+				continue
+			}
+			if err != nil {
+				return &storageErrors.ExecutionPSQLError{Err: err}
+			}
+			_, err = tx.ExecContext(ctx, deleteEmergencyKeysQuery, entryID)
+			if err != nil {
+				return &storageErrors.ExecutionPSQLError{Err: err}
+			}
+			if len(entry.Keys) == 0 {
+				continue
+			}
+			stmt, args := buildEmergencyKeysQuery(entryID, entry.Keys)
+			_, err = tx.ExecContext(ctx, stmt, args...)
+			if err != nil {
+				return &storageErrors.ExecutionPSQLError{Err: err}
+			}
+		}
+		return nil
+	})
+}
+
+// GetEmergencyEntries retrieves a page of entries of a vault sealed for trusted contacts along with logins of the
+// contacts they are sealed for, payloads and wrapped keys are left out.
+func (s *Storage) GetEmergencyEntries(ctx context.Context, vaultID string, afterID int64, limit int) ([]modelstorage.EmergencyEntry, error) {
+	var entries []modelstorage.EmergencyEntry
+	err := s.inTx(ctx, "getting emergency entries", true, func(tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, selectEmergencyEntriesQuery, vaultID, afterID, limit)
+		if err != nil {
+			return &storageErrors.ExecutionPSQLError{Err: err}
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var entry modelstorage.EmergencyEntry
+			var contactLogin string
+			if err = rows.Scan(&entry.ID, &entry.Db, &entry.Identifier, &entry.SealedAt, &contactLogin); err != nil {
+				return &storageErrors.ScanningPSQLError{Err: err}
+			}
+			// rows of an entry go one after another, one per contact
+			if len(entries) == 0 || entries[len(entries)-1].ID != entry.ID {
+				entry.VaultID = vaultID
+				entries = append(entries, entry)
+			}
+			if contactLogin != "" {
+				last := &entries[len(entries)-1]
+				last.Keys = append(last.Keys, modelstorage.EmergencyKey{ContactLogin: contactLogin})
+			}
+		}
+		if err = rows.Err(); err != nil {
+			return &storageErrors.ScanningPSQLError{Err: err}
+		}
+		return nil
+	})
+	return entries, err
+}
+
+// GetEmergencyVault retrieves a page of entries sealed for a trusted contact along with their record keys wrapped for
+// the contact.
+func (s *Storage) GetEmergencyVault(ctx context.Context, accessID, afterID int64, limit int) ([]modelstorage.EmergencyEntry, error) {
+	var entries []modelstorage.EmergencyEntry
+	err := s.inTx(ctx, "getting emergency vault", true, func(tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, selectEmergencyVaultQuery, accessID, afterID, limit)
+		if err != nil {
+			return &storageErrors.ExecutionPSQLError{Err: err}
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var entry modelstorage.EmergencyEntry
+			key := modelstorage.EmergencyKey{AccessID: accessID}
+			if err = rows.Scan(&entry.ID, &entry.Db, &entry.Identifier, &entry.Payload, &entry.SealedAt, &key.WrappedKey); err != nil {
+				return &storageErrors.ScanningPSQLError{Err: err}
+			}
+			entry.Keys = []modelstorage.EmergencyKey{key}
+			entries = append(entries, entry)
+		}
+		if err = rows.Err(); err != nil {
+			return &storageErrors.ScanningPSQLError{Err: err}
+		}
+		return nil
+	})
+	return entries, err
+}
@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"database/sql"
+	"dk-go-gophkeeper/internal/server/storage/modelstorage"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeRow scans fixed values into destinations of a row.
+type fakeRow []interface{}
+
+func (r fakeRow) Scan(dest ...interface{}) error {
+	for i, value := range r {
+		switch d := dest[i].(type) {
+		case *int64:
+			*d = value.(int64)
+		case *int:
+			*d = value.(int)
+		case *string:
+			*d = value.(string)
+		case *sql.NullTime:
+			if value != nil {
+				*d = sql.NullTime{Time: value.(time.Time), Valid: true}
+			}
+		}
+	}
+	return nil
+}
+
+func TestScanEmergencyAccess(t *testing.T) {
+	requestedAt := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	access, err := scanEmergencyAccess(fakeRow{int64(3), "owner_id", "owner_login", "contact_id", "contact_login", 7, "requested", requestedAt, nil})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, modelstorage.EmergencyAccess{
+		ID:           3,
+		OwnerID:      "owner_id",
+		OwnerLogin:   "owner_login",
+		ContactID:    "contact_id",
+		ContactLogin: "contact_login",
+		WaitDays:     7,
+		Status:       "requested",
+		RequestedAt:  requestedAt,
+	}, access)
+}
+
+func TestBuildEmergencyKeysQuery(t *testing.T) {
+	keys := []modelstorage.EmergencyKey{
+		{AccessID: 3, WrappedKey: "key1"},
+		{AccessID: 5, WrappedKey: "key2"},
+	}
+	stmt, args := buildEmergencyKeysQuery(7, keys)
+	assert.Equal(t, "INSERT INTO emergency_keys (entry_id, access_id, wrapped_key) VALUES ($1, $2, $3), ($4, $5, $6)", stmt)
+	assert.Equal(t, []interface{}{int64(7), int64(3), "key1", int64(7), int64(5), "key2"}, args)
+}
@@ -0,0 +1,94 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	storageErrors "dk-go-gophkeeper/internal/server/storage/errors"
+
+	"github.com/rs/zerolog"
+)
+
+// migration changes data of an existing DB once, applied migrations are recorded in schema_migrations by their
+// versions, so they are never repeated.
+type migration struct {
+	version int
+	name    string
+	apply   func(ctx context.Context, tx *sql.Tx, logger *zerolog.Logger) error
+}
+
+// migrations lists data migrations in the order they are applied in, versions are never reused.
+var migrations = []migration{
+	{version: 1, name: "client key pairs", apply: migrateClientKeyPairs},
+}
+
+// insertMigrationQuery records a migration, nothing is inserted if the migration is applied already.
+const insertMigrationQuery = "INSERT INTO schema_migrations (version, name) VALUES ($1, $2) ON CONFLICT (version) DO NOTHING"
+
+// migrate applies migrations not applied to the DB yet, each of them within a single transaction along with its record.
+func (s *Storage) migrate(ctx context.Context) error {
+	for _, m := range migrations {
+		tx, err := s.DB.BeginTx(ctx, nil)
+		if err != nil {
+			return &storageErrors.ExecutionPSQLError{Err: err}
+		}
+		// a concurrently starting server waits for the record and skips the migration
+		result, err := tx.ExecContext(ctx, insertMigrationQuery, m.version, m.name)
+		if err != nil {
+			_ = tx.Rollback()
+			return &storageErrors.ExecutionPSQLError{Err: err}
+		}
+		affected, err := result.RowsAffected()
+		if err != nil || affected == 0 {
+			_ = tx.Rollback()
+			if err != nil {
+				return &storageErrors.ExecutionPSQLError{Err: err}
+			}
+			continue
+		}
+		s.logger.Info().Msgf("Applying migration %d (%s)", m.version, m.name)
+		if err = m.apply(ctx, tx, s.logger); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+		if err = tx.Commit(); err != nil {
+			return &storageErrors.ExecutionPSQLError{Err: err}
+		}
+	}
+	return nil
+}
+
+// migrateClientKeyPairs drops key pairs the server used to generate and keep for users, clients publish public keys of
+// their own key pairs instead. Record keys of shared entries were wrapped with the dropped pairs and cannot be
+// unwrapped by clients, so the entries are kept for their owners to share them again.
+func migrateClientKeyPairs(ctx context.Context, tx *sql.Tx, logger *zerolog.Logger) error {
+	var legacy bool
+	err := tx.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'user_keys' AND column_name = 'private_key')").Scan(&legacy)
+	if err != nil {
+		return &storageErrors.ScanningPSQLError{Err: err}
+	}
+	if !legacy {
+		return nil
+	}
+	result, err := tx.ExecContext(ctx, "UPDATE shared_entries SET reshare_required = TRUE")
+	if err != nil {
+		return &storageErrors.ExecutionPSQLError{Err: err}
+	}
+	shares, err := result.RowsAffected()
+	if err != nil {
+		return &storageErrors.ExecutionPSQLError{Err: err}
+	}
+	result, err = tx.ExecContext(ctx, "DELETE FROM user_keys")
+	if err != nil {
+		return &storageErrors.ExecutionPSQLError{Err: err}
+	}
+	pairs, err := result.RowsAffected()
+	if err != nil {
+		return &storageErrors.ExecutionPSQLError{Err: err}
+	}
+	_, err = tx.ExecContext(ctx, "ALTER TABLE user_keys DROP COLUMN private_key")
+	if err != nil {
+		return &storageErrors.ExecutionPSQLError{Err: err}
+	}
+	logger.Warn().Msgf("%d key pairs kept by the server were dropped, %d shared entries sealed with them have to be shared again by their owners", pairs, shares)
+	return nil
+}
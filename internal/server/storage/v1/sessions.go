@@ -0,0 +1,123 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	storageErrors "dk-go-gophkeeper/internal/server/storage/errors"
+	"dk-go-gophkeeper/internal/server/storage/modelstorage"
+	"errors"
+	"time"
+)
+
+// sessionColumns lists columns of a session in the order they are scanned in.
+const sessionColumns = "id, user_id, digest, device_name, client_version, peer, created_at, last_seen_at"
+
+// session queries, sessions are listed from the most recently seen one
+const (
+	insertSessionQuery  = "INSERT INTO sessions (user_id, digest, device_name, client_version, peer) VALUES ($1, $2, $3, $4, $5) RETURNING id"
+	selectSessionQuery  = "SELECT " + sessionColumns + " FROM sessions WHERE user_id = $1 AND digest = $2"
+	selectSessionsQuery = "SELECT " + sessionColumns + " FROM sessions WHERE user_id = $1 ORDER BY last_seen_at DESC, id DESC"
+	touchSessionQuery   = "UPDATE sessions SET last_seen_at = now(), peer = $2 WHERE id = $1"
+	deleteSessionQuery  = "DELETE FROM sessions WHERE user_id = $1 AND id = $2"
+	// limits are given in seconds, a limit which is not positive is not applied
+	deleteExpiredSessionsQuery = "DELETE FROM sessions WHERE ($1::bigint > 0 AND last_seen_at < now() - make_interval(secs => $1::bigint)) OR ($2::bigint > 0 AND created_at < now() - make_interval(secs => $2::bigint))"
+)
+
+// rowScanner is implemented by a single row and by rows of a query.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanSession scans a row of the sessions table.
+func scanSession(row rowScanner) (modelstorage.Session, error) {
+	var session modelstorage.Session
+	err := row.Scan(&session.ID, &session.UserID, &session.Digest, &session.DeviceName, &session.ClientVersion, &session.Peer,
+		&session.CreatedAt, &session.LastSeenAt)
+	return session, err
+}
+
+// AddSession stores a new session of a user and returns its ID.
+func (s *Storage) AddSession(ctx context.Context, session modelstorage.Session) (int64, error) {
+	var sessionID int64
+	err := s.inTx(ctx, "adding session", false, func(tx *sql.Tx) error {
+		err := tx.QueryRowContext(ctx, insertSessionQuery, session.UserID, session.Digest, session.DeviceName,
+			session.ClientVersion, session.Peer).Scan(&sessionID)
+		if err != nil {
+			return &storageErrors.ExecutionPSQLError{Err: err}
+		}
+		return nil
+	})
+	return sessionID, err
+}
+
+// GetSession retrieves a session of a user by the digest of its key. Sessions are checked on every authenticated
+// request, so the single-row lookup runs outside of the storage lock.
+func (s *Storage) GetSession(ctx context.Context, userID, digest string) (modelstorage.Session, error) {
+	session, err := scanSession(s.DB.QueryRowContext(ctx, selectSessionQuery, userID, digest))
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return session, &storageErrors.NotFoundError{Err: err}
+	case ctx.Err() != nil:
+		s.logger.Error().Msg("getting session failed due to context timeout")
+		return session, &storageErrors.ContextTimeoutExceededError{Err: ctx.Err()}
+	case err != nil:
+		s.logger.Error().Err(err).Msg("getting session failed due to storage error")
+		return session, &storageErrors.ScanningPSQLError{Err: err}
+	}
+	return session, nil
+}
+
+// GetSessions retrieves all sessions of a user.
+func (s *Storage) GetSessions(ctx context.Context, userID string) ([]modelstorage.Session, error) {
+	var sessions []modelstorage.Session
+	err := s.inTx(ctx, "getting sessions", true, func(tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, selectSessionsQuery, userID)
+		if err != nil {
+			return &storageErrors.ExecutionPSQLError{Err: err}
+		}
+		defer rows.Close()
+		for rows.Next() {
+			session, err := scanSession(rows)
+			if err != nil {
+				return &storageErrors.ScanningPSQLError{Err: err}
+			}
+			sessions = append(sessions, session)
+		}
+		if err = rows.Err(); err != nil {
+			return &storageErrors.ScanningPSQLError{Err: err}
+		}
+		return nil
+	})
+	return sessions, err
+}
+
+// TouchSession records the time a session was last seen at along with the peer address it was seen from, the
+// single-row update runs outside of the storage lock as lookups of sessions do.
+func (s *Storage) TouchSession(ctx context.Context, sessionID int64, peer string) error {
+	return execAffecting(ctx, s.DB, touchSessionQuery, sessionID, peer)
+}
+
+// DeleteExpiredSessions removes sessions not seen for the idle time or older than the maximum age and returns their
+// amount, a limit which is not positive is not applied.
+func (s *Storage) DeleteExpiredSessions(ctx context.Context, idleTTL, maxAge time.Duration) (int64, error) {
+	var deleted int64
+	err := s.inTx(ctx, "deleting expired sessions", false, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, deleteExpiredSessionsQuery, int64(idleTTL.Seconds()), int64(maxAge.Seconds()))
+		if err != nil {
+			return &storageErrors.ExecutionPSQLError{Err: err}
+		}
+		deleted, err = result.RowsAffected()
+		if err != nil {
+			return &storageErrors.ExecutionPSQLError{Err: err}
+		}
+		return nil
+	})
+	return deleted, err
+}
+
+// DeleteSession removes a session of a user, its key is not accepted anymore.
+func (s *Storage) DeleteSession(ctx context.Context, userID string, sessionID int64) error {
+	return s.inTx(ctx, "deleting session", false, func(tx *sql.Tx) error {
+		return execAffecting(ctx, tx, deleteSessionQuery, userID, sessionID)
+	})
+}
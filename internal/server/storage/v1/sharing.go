@@ -0,0 +1,312 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	storageErrors "dk-go-gophkeeper/internal/server/storage/errors"
+	"dk-go-gophkeeper/internal/server/storage/modelstorage"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// shareColumns lists columns of a shared entry in the order they are scanned in.
+const shareColumns = "s.id, s.owner_id, u.login, s.db, s.identifier, s.payload, s.owner_key, s.updated_at, s.reshare_required"
+
+// sharing queries, owner logins are joined from the users table
+const (
+	selectUserIDQuery          = "SELECT user_id FROM users WHERE login = $1"
+	selectUserKeysQuery        = "SELECT user_id, public_key FROM user_keys WHERE user_id = $1"
+	insertUserKeysQuery        = "INSERT INTO user_keys (user_id, public_key) VALUES ($1, $2) ON CONFLICT (user_id) DO NOTHING"
+	selectShareQuery           = "SELECT " + shareColumns + " FROM shared_entries s JOIN users u ON u.user_id = s.owner_id WHERE s.owner_id = $1 AND s.db = $2 AND s.identifier = $3"
+	selectShareByIDQuery       = "SELECT " + shareColumns + " FROM shared_entries s JOIN users u ON u.user_id = s.owner_id WHERE s.id = $1"
+	selectSharesByOwnerQuery   = "SELECT " + shareColumns + " FROM shared_entries s JOIN users u ON u.user_id = s.owner_id WHERE s.owner_id = $1 ORDER BY s.id"
+	selectSharedWithQuery      = "SELECT " + shareColumns + ", r.recipient_id, r.wrapped_key, r.permission FROM share_recipients r JOIN shared_entries s ON s.id = r.share_id JOIN users u ON u.user_id = s.owner_id WHERE r.recipient_id = $1 AND NOT s.reshare_required ORDER BY s.id"
+	selectRecipientsQuery      = "SELECT r.share_id, r.recipient_id, u.login, r.wrapped_key, r.permission, COALESCE(k.public_key, '') FROM share_recipients r JOIN users u ON u.user_id = r.recipient_id LEFT JOIN user_keys k ON k.user_id = r.recipient_id WHERE r.share_id = ANY($1) ORDER BY r.share_id, u.login"
+	upsertShareQuery           = "INSERT INTO shared_entries (owner_id, db, identifier, payload, owner_key, reshare_required, updated_at) VALUES ($1, $2, $3, $4, $5, $6, now()) ON CONFLICT (owner_id, db, identifier) DO UPDATE SET payload = EXCLUDED.payload, owner_key = EXCLUDED.owner_key, reshare_required = EXCLUDED.reshare_required, updated_at = now() RETURNING id"
+	deleteShareQuery           = "DELETE FROM shared_entries WHERE owner_id = $1 AND db = $2 AND identifier = $3"
+	deleteOtherRecipientsQuery = "DELETE FROM share_recipients WHERE share_id = $1 AND NOT (recipient_id = ANY($2))"
+	updateSharePayloadQuery    = "UPDATE shared_entries SET payload = $2, updated_at = now() WHERE id = $1"
+	deleteSharesQuery          = "DELETE FROM shared_entries WHERE owner_id = $1 AND db = $2 AND identifier = ANY($3)"
+)
+
+// buildShareRecipientsQuery builds a multi-row statement adding recipients of a shared entry or updating their keys
+// and permissions.
+func buildShareRecipientsQuery(shareID int64, recipients []modelstorage.ShareRecipient) (string, []interface{}) {
+	var sb strings.Builder
+	sb.WriteString("INSERT INTO share_recipients (share_id, recipient_id, wrapped_key, permission) VALUES ")
+	args := make([]interface{}, 0, len(recipients)*4)
+	for i, recipient := range recipients {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		n := len(args)
+		sb.WriteString(fmt.Sprintf("($%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4))
+		args = append(args, shareID, recipient.RecipientID, recipient.WrappedKey, recipient.Permission)
+	}
+	sb.WriteString(" ON CONFLICT (share_id, recipient_id) DO UPDATE SET wrapped_key = EXCLUDED.wrapped_key, permission = EXCLUDED.permission")
+	return sb.String(), args
+}
+
+// GetEntry retrieves a single entry of any type by its encoded identifier.
+func (s *Storage) GetEntry(ctx context.Context, userID, db, identifier string) (modelstorage.BatchItem, error) {
+	items := make(map[string]modelstorage.BatchItem, 1)
+	err := s.inTx(ctx, "getting entry", true, func(tx *sql.Tx) error {
+		return s.fetchEntries(ctx, tx, userID, db, []string{identifier}, items)
+	})
+	if err != nil {
+		return modelstorage.BatchItem{}, err
+	}
+	item, ok := items[db+"/"+identifier]
+	if !ok {
+		return modelstorage.BatchItem{}, &storageErrors.NotFoundError{Err: sql.ErrNoRows}
+	}
+	return item, nil
+}
+
+// GetUserIDByLogin retrieves an ID of a user by the encoded login.
+func (s *Storage) GetUserIDByLogin(ctx context.Context, login string) (string, error) {
+	var userID string
+	err := s.inTx(ctx, "getting user ID", true, func(tx *sql.Tx) error {
+		err := tx.QueryRowContext(ctx, selectUserIDQuery, login).Scan(&userID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return &storageErrors.NotFoundError{Err: err}
+		}
+		if err != nil {
+			return &storageErrors.ScanningPSQLError{Err: err}
+		}
+		return nil
+	})
+	return userID, err
+}
+
+// GetUserKeys retrieves a public key of a user.
+func (s *Storage) GetUserKeys(ctx context.Context, userID string) (modelstorage.UserKeys, error) {
+	var keys modelstorage.UserKeys
+	err := s.inTx(ctx, "getting user keys", true, func(tx *sql.Tx) error {
+		err := tx.QueryRowContext(ctx, selectUserKeysQuery, userID).Scan(&keys.UserID, &keys.PublicKey)
+		if errors.Is(err, sql.ErrNoRows) {
+			return &storageErrors.NotFoundError{Err: err}
+		}
+		if err != nil {
+			return &storageErrors.ScanningPSQLError{Err: err}
+		}
+		return nil
+	})
+	return keys, err
+}
+
+// SetUserKeys stores a public key of a user unless the user already has one.
+func (s *Storage) SetUserKeys(ctx context.Context, keys modelstorage.UserKeys) error {
+	return s.inTx(ctx, "setting user keys", false, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, insertUserKeysQuery, keys.UserID, keys.PublicKey)
+		if err != nil {
+			return &storageErrors.ExecutionPSQLError{Err: err}
+		}
+		return nil
+	})
+}
+
+// GetShare retrieves a shared entry of an owner along with its recipients.
+func (s *Storage) GetShare(ctx context.Context, ownerID, db, identifier string) (modelstorage.Share, error) {
+	var share modelstorage.Share
+	err := s.inTx(ctx, "getting shared entry", true, func(tx *sql.Tx) error {
+		shares, err := queryShares(ctx, tx, selectShareQuery, ownerID, db, identifier)
+		if err != nil {
+			return err
+		}
+		if len(shares) == 0 {
+			return &storageErrors.NotFoundError{Err: sql.ErrNoRows}
+		}
+		share = shares[0]
+		return nil
+	})
+	return share, err
+}
+
+// GetShareByID retrieves a shared entry by its ID along with its recipients.
+func (s *Storage) GetShareByID(ctx context.Context, shareID int64) (modelstorage.Share, error) {
+	var share modelstorage.Share
+	err := s.inTx(ctx, "getting shared entry", true, func(tx *sql.Tx) error {
+		shares, err := queryShares(ctx, tx, selectShareByIDQuery, shareID)
+		if err != nil {
+			return err
+		}
+		if len(shares) == 0 {
+			return &storageErrors.NotFoundError{Err: sql.ErrNoRows}
+		}
+		share = shares[0]
+		return nil
+	})
+	return share, err
+}
+
+// GetSharesByOwner retrieves all entries an owner shares along with their recipients.
+func (s *Storage) GetSharesByOwner(ctx context.Context, ownerID string) ([]modelstorage.Share, error) {
+	var shares []modelstorage.Share
+	err := s.inTx(ctx, "getting shared entries of owner", true, func(tx *sql.Tx) error {
+		var err error
+		shares, err = queryShares(ctx, tx, selectSharesByOwnerQuery, ownerID)
+		return err
+	})
+	return shares, err
+}
+
+// GetSharedWithUser retrieves all entries shared with a user, each of them carries the user as its only recipient.
+// Entries to be shared again by their owners cannot be opened and are left out.
+func (s *Storage) GetSharedWithUser(ctx context.Context, recipientID string) ([]modelstorage.Share, error) {
+	var shares []modelstorage.Share
+	err := s.inTx(ctx, "getting entries shared with user", true, func(tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, selectSharedWithQuery, recipientID)
+		if err != nil {
+			return &storageErrors.ExecutionPSQLError{Err: err}
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var share modelstorage.Share
+			var recipient modelstorage.ShareRecipient
+			err = rows.Scan(&share.ID, &share.OwnerID, &share.OwnerLogin, &share.Db, &share.Identifier, &share.Payload, &share.OwnerKey, &share.UpdatedAt, &share.ReshareRequired,
+				&recipient.RecipientID, &recipient.WrappedKey, &recipient.Permission)
+			if err != nil {
+				return &storageErrors.ScanningPSQLError{Err: err}
+			}
+			share.Recipients = []modelstorage.ShareRecipient{recipient}
+			shares = append(shares, share)
+		}
+		if err = rows.Err(); err != nil {
+			return &storageErrors.ScanningPSQLError{Err: err}
+		}
+		return nil
+	})
+	return shares, err
+}
+
+// SetShare stores a shared entry replacing its payload, owner key and recipients, recipients absent from the entry are
+// revoked. An entry without recipients is not shared anymore and is removed.
+func (s *Storage) SetShare(ctx context.Context, share modelstorage.Share) error {
+	return s.inTx(ctx, "setting shared entry", false, func(tx *sql.Tx) error {
+		if len(share.Recipients) == 0 {
+			_, err := tx.ExecContext(ctx, deleteShareQuery, share.OwnerID, share.Db, share.Identifier)
+			if err != nil {
+				return &storageErrors.ExecutionPSQLError{Err: err}
+			}
+			return nil
+		}
+		var shareID int64
+		err := tx.QueryRowContext(ctx, upsertShareQuery, share.OwnerID, share.Db, share.Identifier, share.Payload, share.OwnerKey, share.ReshareRequired).Scan(&shareID)
+		if err != nil {
+			return &storageErrors.ExecutionPSQLError{Err: err}
+		}
+		recipientIDs := make([]string, 0, len(share.Recipients))
+		for _, recipient := range share.Recipients {
+			recipientIDs = append(recipientIDs, recipient.RecipientID)
+		}
+		_, err = tx.ExecContext(ctx, deleteOtherRecipientsQuery, shareID, pq.Array(recipientIDs))
+		if err != nil {
+			return &storageErrors.ExecutionPSQLError{Err: err}
+		}
+		stmt, args := buildShareRecipientsQuery(shareID, share.Recipients)
+		_, err = tx.ExecContext(ctx, stmt, args...)
+		if err != nil {
+			return &storageErrors.ExecutionPSQLError{Err: err}
+		}
+		return nil
+	})
+}
+
+// SetSharePayload replaces the payload of a shared entry, the record key stays the same.
+func (s *Storage) SetSharePayload(ctx context.Context, shareID int64, payload string) error {
+	return s.inTx(ctx, "setting shared entry payload", false, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, updateSharePayloadQuery, shareID, payload)
+		if err != nil {
+			return &storageErrors.ExecutionPSQLError{Err: err}
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return &storageErrors.ExecutionPSQLError{Err: err}
+		}
+		if affected == 0 {
+			return &storageErrors.NotFoundError{Err: sql.ErrNoRows}
+		}
+		return nil
+	})
+}
+
+// UpdateSharedEntry stores an update of a shared entry as the entry of its owner along with its copy sealed with the
+// record key of the entry within a single transaction, so that the owner and recipients never see different revisions.
+func (s *Storage) UpdateSharedEntry(ctx context.Context, ownerID string, item modelstorage.BatchItem, shareID int64, payload string) error {
+	queries, err := buildBatchQueries(ownerID, []modelstorage.BatchItem{item}, true)
+	if err != nil {
+		return err
+	}
+	return s.inTx(ctx, "updating shared entry", false, func(tx *sql.Tx) error {
+		if _, err := upsertBatch(ctx, tx, ownerID, queries, batchTokens([]modelstorage.BatchItem{item})); err != nil {
+			return err
+		}
+		result, err := tx.ExecContext(ctx, updateSharePayloadQuery, shareID, payload)
+		if err != nil {
+			return &storageErrors.ExecutionPSQLError{Err: err}
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return &storageErrors.ExecutionPSQLError{Err: err}
+		}
+		if affected == 0 {
+			return &storageErrors.NotFoundError{Err: sql.ErrNoRows}
+		}
+		return nil
+	})
+}
+
+// queryShares retrieves shared entries selected by a query along with their recipients.
+func queryShares(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) ([]modelstorage.Share, error) {
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, &storageErrors.ExecutionPSQLError{Err: err}
+	}
+	var shares []modelstorage.Share
+	for rows.Next() {
+		var share modelstorage.Share
+		err = rows.Scan(&share.ID, &share.OwnerID, &share.OwnerLogin, &share.Db, &share.Identifier, &share.Payload, &share.OwnerKey, &share.UpdatedAt, &share.ReshareRequired)
+		if err != nil {
+			rows.Close()
+			return nil, &storageErrors.ScanningPSQLError{Err: err}
+		}
+		shares = append(shares, share)
+	}
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		return nil, &storageErrors.ScanningPSQLError{Err: err}
+	}
+	if len(shares) == 0 {
+		return nil, nil
+	}
+	positions := make(map[int64]int, len(shares))
+	shareIDs := make([]int64, 0, len(shares))
+	for i, share := range shares {
+		positions[share.ID] = i
+		shareIDs = append(shareIDs, share.ID)
+	}
+	rows, err = tx.QueryContext(ctx, selectRecipientsQuery, pq.Array(shareIDs))
+	if err != nil {
+		return nil, &storageErrors.ExecutionPSQLError{Err: err}
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var shareID int64
+		var recipient modelstorage.ShareRecipient
+		err = rows.Scan(&shareID, &recipient.RecipientID, &recipient.Login, &recipient.WrappedKey, &recipient.Permission, &recipient.PublicKey)
+		if err != nil {
+			return nil, &storageErrors.ScanningPSQLError{Err: err}
+		}
+		position := positions[shareID]
+		shares[position].Recipients = append(shares[position].Recipients, recipient)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, &storageErrors.ScanningPSQLError{Err: err}
+	}
+	return shares, nil
+}
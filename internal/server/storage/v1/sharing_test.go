@@ -0,0 +1,20 @@
+package storage
+
+import (
+	"dk-go-gophkeeper/internal/server/storage/modelstorage"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildShareRecipientsQuery(t *testing.T) {
+	recipients := []modelstorage.ShareRecipient{
+		{RecipientID: "user1", WrappedKey: "key1", Permission: "read"},
+		{RecipientID: "user2", WrappedKey: "key2", Permission: "read-write"},
+	}
+	stmt, args := buildShareRecipientsQuery(7, recipients)
+	expectedStmt := "INSERT INTO share_recipients (share_id, recipient_id, wrapped_key, permission) VALUES ($1, $2, $3, $4), ($5, $6, $7, $8) " +
+		"ON CONFLICT (share_id, recipient_id) DO UPDATE SET wrapped_key = EXCLUDED.wrapped_key, permission = EXCLUDED.permission"
+	assert.Equal(t, expectedStmt, stmt)
+	assert.Equal(t, []interface{}{int64(7), "user1", "key1", "read", int64(7), "user2", "key2", "read-write"}, args)
+}
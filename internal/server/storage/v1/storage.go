@@ -11,6 +11,7 @@ import (
 	storageErrors "dk-go-gophkeeper/internal/server/storage/errors"
 	"dk-go-gophkeeper/internal/server/storage/modelstorage"
 	"errors"
+	"fmt"
 	"sync"
 	"time"
 
@@ -53,6 +54,15 @@ func InitStorage(ctx context.Context, logger *zerolog.Logger, cfg *config.Config
 	if err != nil {
 		logger.Fatal().Err(err).Msg("Could not create tables")
 	}
+	err = st.migrate(ctx)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Could not migrate data")
+	}
+	var duplicates int
+	err = db.QueryRowContext(ctx, "SELECT COUNT(*) FROM entry_duplicates").Scan(&duplicates)
+	if err == nil && duplicates > 0 {
+		logger.Warn().Msgf("%d entries with duplicate identifiers are kept in the entry_duplicates table, restore them under new identifiers or delete them", duplicates)
+	}
 	logger.Info().Msg("PSQL DB connection was established")
 
 	const flushPartsAmount = 10
@@ -108,9 +118,96 @@ func InitStorage(ctx context.Context, logger *zerolog.Logger, cfg *config.Config
 	return &st
 }
 
-// GetBankCardData retrieves all bank card entries from storage.
-func (s *Storage) GetBankCardData(ctx context.Context, userID string) ([]modelstorage.BankCardStorageEntry, error) {
-	selectStmt, err := s.DB.PrepareContext(ctx, "SELECT * FROM bank_cards WHERE user_id = $1")
+// OpenStorage initializes a Storage instance for tools inspecting an existing DB, tables are neither created nor
+// changed and no data removal is scheduled.
+func OpenStorage(logger *zerolog.Logger, cfg *config.Config) (*Storage, error) {
+	db, err := sql.Open("pgx", cfg.DatabaseDSN)
+	if err != nil {
+		return nil, err
+	}
+	return &Storage{
+		cfg:    cfg,
+		logger: logger,
+		DB:     db,
+	}, nil
+}
+
+// inTx runs an action within a single transaction, which is committed unless the action fails.
+func (s *Storage) inTx(ctx context.Context, action string, readOnly bool, fn func(tx *sql.Tx) error) error {
+	chanOk := make(chan bool, 1)
+	chanEr := make(chan error, 1)
+	go func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		tx, err := s.DB.BeginTx(ctx, &sql.TxOptions{ReadOnly: readOnly})
+		if err != nil {
+			chanEr <- &storageErrors.ExecutionPSQLError{Err: err}
+			return
+		}
+		defer func(tx *sql.Tx) {
+			err1 := tx.Rollback()
+			if err1 != nil {
+				return
+			}
+		}(tx)
+		if err = fn(tx); err != nil {
+			chanEr <- err
+			return
+		}
+		if err = tx.Commit(); err != nil {
+			chanEr <- &storageErrors.ExecutionPSQLError{Err: err}
+			return
+		}
+		chanOk <- true
+	}()
+	select {
+	case <-ctx.Done():
+		s.logger.Error().Msgf("%s failed due to context timeout", action)
+		return &storageErrors.ContextTimeoutExceededError{Err: ctx.Err()}
+	case methodErr := <-chanEr:
+		s.logger.Error().Err(methodErr).Msgf("%s failed due to storage error", action)
+		return methodErr
+	case <-chanOk:
+		s.logger.Info().Msgf("%s done", action)
+		return nil
+	}
+}
+
+// columns of users and entries in the order they are scanned in, columns added by later migrations go last
+const (
+	userColumns          = "id, user_id, login, password, registered_at"
+	bankCardColumns      = "id, user_id, identifier, card_number, card_holder, card_cvv, card_meta, folder, tags, favorite, custom_fields, created_at, updated_at, card_expiry, card_pin"
+	loginPasswordColumns = "id, user_id, identifier, login, password, cred_meta, folder, tags, favorite, custom_fields, created_at, updated_at, password_changed_at"
+	textBinaryColumns    = "id, user_id, identifier, text_entry, text_meta, folder, tags, favorite, custom_fields, created_at, updated_at"
+)
+
+// entryColumns maps DB identifiers to columns of their entries.
+var entryColumns = map[string]string{
+	"bankCard":      bankCardColumns,
+	"loginPassword": loginPasswordColumns,
+	"textBinary":    textBinaryColumns,
+}
+
+// filteredSelectQuery selects a page of entries of a table, if the token count is not zero only entries having
+// all the given tokens are selected.
+const filteredSelectQuery = `SELECT %s FROM %s WHERE user_id = $1 AND id > $2
+AND ($4 = 0 OR identifier IN (
+	SELECT identifier FROM blind_indexes WHERE user_id = $1 AND db = $5 AND token = ANY($6)
+	GROUP BY identifier HAVING COUNT(DISTINCT token) = $4))
+ORDER BY id LIMIT $3`
+
+// uniqueCount returns the amount of distinct tokens.
+func uniqueCount(tokens []string) int {
+	unique := make(map[string]bool, len(tokens))
+	for _, token := range tokens {
+		unique[token] = true
+	}
+	return len(unique)
+}
+
+// GetBankCardData retrieves up to limit bank card entries following the given row ID having all the given tokens from storage.
+func (s *Storage) GetBankCardData(ctx context.Context, userID string, afterID int64, limit int, tokens []string) ([]modelstorage.BankCardStorageEntry, error) {
+	selectStmt, err := s.DB.PrepareContext(ctx, fmt.Sprintf(filteredSelectQuery, bankCardColumns, "bank_cards"))
 	defer func(selectStmt *sql.Stmt) {
 		err_ := selectStmt.Close()
 		if err_ != nil {
@@ -125,7 +222,7 @@ func (s *Storage) GetBankCardData(ctx context.Context, userID string) ([]modelst
 	go func() {
 		s.mu.Lock()
 		defer s.mu.Unlock()
-		rows, err := selectStmt.QueryContext(ctx, userID)
+		rows, err := selectStmt.QueryContext(ctx, userID, afterID, limit, uniqueCount(tokens), "bankCard", pq.Array(tokens))
 		if err != nil {
 			chanEr <- &storageErrors.ExecutionPSQLError{Err: err}
 			return
@@ -134,7 +231,7 @@ func (s *Storage) GetBankCardData(ctx context.Context, userID string) ([]modelst
 		var queryOutput []modelstorage.BankCardStorageEntry
 		for rows.Next() {
 			var queryOutputRow modelstorage.BankCardStorageEntry
-			err = rows.Scan(&queryOutputRow.ID, &queryOutputRow.UserID, &queryOutputRow.Identifier, &queryOutputRow.Number, &queryOutputRow.Holder, &queryOutputRow.CVV, &queryOutputRow.Meta)
+			err = rows.Scan(&queryOutputRow.ID, &queryOutputRow.UserID, &queryOutputRow.Identifier, &queryOutputRow.Number, &queryOutputRow.Holder, &queryOutputRow.CVV, &queryOutputRow.Meta, &queryOutputRow.Folder, &queryOutputRow.Tags, &queryOutputRow.Favorite, &queryOutputRow.Fields, &queryOutputRow.CreatedAt, &queryOutputRow.UpdatedAt, &queryOutputRow.Expiry, &queryOutputRow.PIN)
 			if err != nil {
 				chanEr <- &storageErrors.ScanningPSQLError{Err: err}
 				return
@@ -160,9 +257,9 @@ func (s *Storage) GetBankCardData(ctx context.Context, userID string) ([]modelst
 	}
 }
 
-// GetLoginPasswordData retrieves all login/password entries from storage.
-func (s *Storage) GetLoginPasswordData(ctx context.Context, userID string) ([]modelstorage.LoginPasswordStorageEntry, error) {
-	selectStmt, err := s.DB.PrepareContext(ctx, "SELECT * FROM logins_passwords WHERE user_id = $1")
+// GetLoginPasswordData retrieves up to limit login/password entries following the given row ID having all the given tokens from storage.
+func (s *Storage) GetLoginPasswordData(ctx context.Context, userID string, afterID int64, limit int, tokens []string) ([]modelstorage.LoginPasswordStorageEntry, error) {
+	selectStmt, err := s.DB.PrepareContext(ctx, fmt.Sprintf(filteredSelectQuery, loginPasswordColumns, "logins_passwords"))
 	defer func(selectStmt *sql.Stmt) {
 		err_ := selectStmt.Close()
 		if err_ != nil {
@@ -177,7 +274,7 @@ func (s *Storage) GetLoginPasswordData(ctx context.Context, userID string) ([]mo
 	go func() {
 		s.mu.Lock()
 		defer s.mu.Unlock()
-		rows, err := selectStmt.QueryContext(ctx, userID)
+		rows, err := selectStmt.QueryContext(ctx, userID, afterID, limit, uniqueCount(tokens), "loginPassword", pq.Array(tokens))
 		if err != nil {
 			chanEr <- &storageErrors.ExecutionPSQLError{Err: err}
 			return
@@ -186,7 +283,7 @@ func (s *Storage) GetLoginPasswordData(ctx context.Context, userID string) ([]mo
 		var queryOutput []modelstorage.LoginPasswordStorageEntry
 		for rows.Next() {
 			var queryOutputRow modelstorage.LoginPasswordStorageEntry
-			err = rows.Scan(&queryOutputRow.ID, &queryOutputRow.UserID, &queryOutputRow.Identifier, &queryOutputRow.Login, &queryOutputRow.Password, &queryOutputRow.Meta)
+			err = rows.Scan(&queryOutputRow.ID, &queryOutputRow.UserID, &queryOutputRow.Identifier, &queryOutputRow.Login, &queryOutputRow.Password, &queryOutputRow.Meta, &queryOutputRow.Folder, &queryOutputRow.Tags, &queryOutputRow.Favorite, &queryOutputRow.Fields, &queryOutputRow.CreatedAt, &queryOutputRow.UpdatedAt, &queryOutputRow.PasswordChangedAt)
 			if err != nil {
 				chanEr <- &storageErrors.ScanningPSQLError{Err: err}
 				return
@@ -212,9 +309,9 @@ func (s *Storage) GetLoginPasswordData(ctx context.Context, userID string) ([]mo
 	}
 }
 
-// GetTextBinaryData retrieves all text/binary entries from storage.
-func (s *Storage) GetTextBinaryData(ctx context.Context, userID string) ([]modelstorage.TextBinaryStorageEntry, error) {
-	selectStmt, err := s.DB.PrepareContext(ctx, "SELECT * FROM texts_binaries WHERE user_id = $1")
+// GetTextBinaryData retrieves up to limit text/binary entries following the given row ID having all the given tokens from storage.
+func (s *Storage) GetTextBinaryData(ctx context.Context, userID string, afterID int64, limit int, tokens []string) ([]modelstorage.TextBinaryStorageEntry, error) {
+	selectStmt, err := s.DB.PrepareContext(ctx, fmt.Sprintf(filteredSelectQuery, textBinaryColumns, "texts_binaries"))
 	defer func(selectStmt *sql.Stmt) {
 		err_ := selectStmt.Close()
 		if err_ != nil {
@@ -229,7 +326,7 @@ func (s *Storage) GetTextBinaryData(ctx context.Context, userID string) ([]model
 	go func() {
 		s.mu.Lock()
 		defer s.mu.Unlock()
-		rows, err := selectStmt.QueryContext(ctx, userID)
+		rows, err := selectStmt.QueryContext(ctx, userID, afterID, limit, uniqueCount(tokens), "textBinary", pq.Array(tokens))
 		if err != nil {
 			chanEr <- &storageErrors.ExecutionPSQLError{Err: err}
 			return
@@ -238,7 +335,7 @@ func (s *Storage) GetTextBinaryData(ctx context.Context, userID string) ([]model
 		var queryOutput []modelstorage.TextBinaryStorageEntry
 		for rows.Next() {
 			var queryOutputRow modelstorage.TextBinaryStorageEntry
-			err = rows.Scan(&queryOutputRow.ID, &queryOutputRow.UserID, &queryOutputRow.Identifier, &queryOutputRow.Entry, &queryOutputRow.Meta)
+			err = rows.Scan(&queryOutputRow.ID, &queryOutputRow.UserID, &queryOutputRow.Identifier, &queryOutputRow.Entry, &queryOutputRow.Meta, &queryOutputRow.Folder, &queryOutputRow.Tags, &queryOutputRow.Favorite, &queryOutputRow.Fields, &queryOutputRow.CreatedAt, &queryOutputRow.UpdatedAt)
 			if err != nil {
 				chanEr <- &storageErrors.ScanningPSQLError{Err: err}
 				return
@@ -264,142 +361,100 @@ func (s *Storage) GetTextBinaryData(ctx context.Context, userID string) ([]model
 	}
 }
 
-// SetBankCardData adds a new bank card entry to storage.
-func (s *Storage) SetBankCardData(ctx context.Context, userID, identifier, number, holder, cvv, meta string) error {
-	selectStmt, err := s.DB.PrepareContext(ctx, "SELECT * FROM bank_cards WHERE user_id = $1 AND identifier = $2")
+// insert statements of single entries, existing entries are never overwritten by them
+const (
+	insertBankCardQuery      = "INSERT INTO bank_cards (user_id, identifier, card_number, card_holder, card_cvv, card_meta, folder, tags, favorite, custom_fields, card_expiry, card_pin) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) ON CONFLICT (user_id, identifier) DO NOTHING"
+	insertLoginPasswordQuery = "INSERT INTO logins_passwords (user_id, identifier, login, password, cred_meta, folder, tags, favorite, custom_fields) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) ON CONFLICT (user_id, identifier) DO NOTHING"
+	insertTextBinaryQuery    = "INSERT INTO texts_binaries (user_id, identifier, text_entry, text_meta, folder, tags, favorite, custom_fields) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) ON CONFLICT (user_id, identifier) DO NOTHING"
+)
+
+// addEntry inserts a new entry and its search tokens within a transaction, an entry with the same identifier must not
+// exist yet.
+func addEntry(ctx context.Context, tx *sql.Tx, userID string, index modelstorage.BlindIndexEntry, query string, args ...interface{}) error {
+	result, err := tx.ExecContext(ctx, query, args...)
 	if err != nil {
-		return &storageErrors.StatementPSQLError{Err: err}
+		return &storageErrors.ExecutionPSQLError{Err: err}
 	}
-	newDataStmt, err := s.DB.PrepareContext(ctx, "INSERT INTO bank_cards (user_id, identifier, card_number, card_holder, card_cvv, card_meta) VALUES ($1, $2, $3, $4, $5, $6)")
+	inserted, err := result.RowsAffected()
 	if err != nil {
-		return &storageErrors.StatementPSQLError{Err: err}
+		return &storageErrors.ExecutionPSQLError{Err: err}
 	}
-	defer selectStmt.Close()
-	defer newDataStmt.Close()
-	chanOk := make(chan bool)
-	chanEr := make(chan error)
-	go func() {
-		s.mu.Lock()
-		defer s.mu.Unlock()
-		var queryOutput modelstorage.BankCardStorageEntry
-		err := selectStmt.QueryRowContext(ctx, userID, identifier).Scan(&queryOutput.ID, &queryOutput.UserID, &queryOutput.Identifier, &queryOutput.Number, &queryOutput.Holder, &queryOutput.CVV, &queryOutput.Meta)
-		switch {
-		case errors.Is(err, sql.ErrNoRows):
-			_, err = newDataStmt.ExecContext(ctx, userID, identifier, number, holder, cvv, meta)
-			if err != nil {
-				chanEr <- &storageErrors.ExecutionPSQLError{Err: err}
-				return
-			}
-			chanOk <- true
-		case err != nil:
-			chanEr <- &storageErrors.ExecutionPSQLError{Err: err}
-		default:
-			chanEr <- &storageErrors.AlreadyExistsError{Err: err}
-		}
-	}()
-	select {
-	case <-ctx.Done():
-		s.logger.Error().Msgf("adding new bank card failed for ID %s due to context timeout", identifier)
-		return &storageErrors.ContextTimeoutExceededError{Err: ctx.Err()}
-	case methodErr := <-chanEr:
-		s.logger.Error().Err(methodErr).Msgf("adding new bank card failed for ID %s due to storage error", identifier)
-		return methodErr
-	case <-chanOk:
-		s.logger.Info().Msgf("adding new bank card done for ID %s", identifier)
-		return nil
+	if inserted == 0 {
+		return &storageErrors.AlreadyExistsError{Err: errors.New("entry already exists"), ID: index.Identifier}
 	}
+	return replaceBlindIndexes(ctx, tx, userID, []modelstorage.BlindIndexEntry{index})
 }
 
-// SetLoginPasswordData adds a new login/password entry to storage.
-func (s *Storage) SetLoginPasswordData(ctx context.Context, userID, identifier, login, password, meta string) error {
-	selectStmt, err := s.DB.PrepareContext(ctx, "SELECT * FROM logins_passwords WHERE user_id = $1 AND identifier = $2")
-	if err != nil {
-		return &storageErrors.StatementPSQLError{Err: err}
-	}
-	newDataStmt, err := s.DB.PrepareContext(ctx, "INSERT INTO logins_passwords (user_id, identifier, login, password, cred_meta) VALUES ($1, $2, $3, $4, $5)")
-	if err != nil {
-		return &storageErrors.StatementPSQLError{Err: err}
-	}
-	defer selectStmt.Close()
-	defer newDataStmt.Close()
-	chanOk := make(chan bool)
-	chanEr := make(chan error)
-	go func() {
-		s.mu.Lock()
-		defer s.mu.Unlock()
-		var queryOutput modelstorage.LoginPasswordStorageEntry
-		err := selectStmt.QueryRowContext(ctx, userID, identifier).Scan(&queryOutput.ID, &queryOutput.UserID, &queryOutput.Identifier, &queryOutput.Login, &queryOutput.Password, &queryOutput.Meta)
-		switch {
-		case errors.Is(err, sql.ErrNoRows):
-			_, err = newDataStmt.ExecContext(ctx, userID, identifier, login, password, meta)
-			if err != nil {
-				chanEr <- &storageErrors.ExecutionPSQLError{Err: err}
-				return
-			}
-			chanOk <- true
-		case err != nil:
-			chanEr <- &storageErrors.ExecutionPSQLError{Err: err}
-		default:
-			chanEr <- &storageErrors.AlreadyExistsError{Err: err}
-		}
-	}()
-	select {
-	case <-ctx.Done():
-		s.logger.Error().Msgf("adding new login/password failed for ID %s due to context timeout", identifier)
-		return &storageErrors.ContextTimeoutExceededError{Err: ctx.Err()}
-	case methodErr := <-chanEr:
-		s.logger.Error().Err(methodErr).Msgf("adding new login/password failed for ID %s due to storage error", identifier)
-		return methodErr
-	case <-chanOk:
-		s.logger.Info().Msgf("adding new login/password done for ID %s", identifier)
-		return nil
-	}
+// SetBankCardData adds a new bank card entry to storage along with its search tokens.
+func (s *Storage) SetBankCardData(ctx context.Context, userID, identifier, number, holder, cvv, expiry, pin, meta string, labels modelstorage.Labels, fields string, tokens []string) error {
+	return s.inTx(ctx, fmt.Sprintf("adding new bank card for ID %s", identifier), false, func(tx *sql.Tx) error {
+		return addEntry(ctx, tx, userID, modelstorage.BlindIndexEntry{Db: "bankCard", Identifier: identifier, Tokens: tokens}, insertBankCardQuery, userID, identifier, number, holder, cvv, meta, labels.Folder, labels.Tags, labels.Favorite, fields, expiry, pin)
+	})
+}
+
+// SetLoginPasswordData adds a new login/password entry to storage along with its search tokens.
+func (s *Storage) SetLoginPasswordData(ctx context.Context, userID, identifier, login, password, meta string, labels modelstorage.Labels, fields string, tokens []string) error {
+	return s.inTx(ctx, fmt.Sprintf("adding new login/password for ID %s", identifier), false, func(tx *sql.Tx) error {
+		return addEntry(ctx, tx, userID, modelstorage.BlindIndexEntry{Db: "loginPassword", Identifier: identifier, Tokens: tokens}, insertLoginPasswordQuery, userID, identifier, login, password, meta, labels.Folder, labels.Tags, labels.Favorite, fields)
+	})
 }
 
-// SetTextBinaryData adds a new text/binary entry to storage.
-func (s *Storage) SetTextBinaryData(ctx context.Context, userID, identifier, entry, meta string) error {
-	selectStmt, err := s.DB.PrepareContext(ctx, "SELECT * FROM texts_binaries WHERE user_id = $1 AND identifier = $2")
+// SetTextBinaryData adds a new text/binary entry to storage along with its search tokens.
+func (s *Storage) SetTextBinaryData(ctx context.Context, userID, identifier, entry, meta string, labels modelstorage.Labels, fields string, tokens []string) error {
+	return s.inTx(ctx, fmt.Sprintf("adding new text/binary for ID %s", identifier), false, func(tx *sql.Tx) error {
+		return addEntry(ctx, tx, userID, modelstorage.BlindIndexEntry{Db: "textBinary", Identifier: identifier, Tokens: tokens}, insertTextBinaryQuery, userID, identifier, entry, meta, labels.Folder, labels.Tags, labels.Favorite, fields)
+	})
+}
+
+// SetBatchData inserts a batch of entries of mixed types within a single transaction, existing entries are updated
+// only if overwrite is set and are left out of the results otherwise.
+func (s *Storage) SetBatchData(ctx context.Context, userID string, items []modelstorage.BatchItem, overwrite bool) ([]modelstorage.BatchItemResult, error) {
+	queries, err := buildBatchQueries(userID, items, overwrite)
 	if err != nil {
-		return &storageErrors.StatementPSQLError{Err: err}
+		return nil, err
 	}
-	newDataStmt, err := s.DB.PrepareContext(ctx, "INSERT INTO texts_binaries (user_id, identifier, text_entry, text_meta) VALUES ($1, $2, $3, $4)")
+	var results []modelstorage.BatchItemResult
+	err = s.inTx(ctx, fmt.Sprintf("upserting batch of %d entries", len(items)), false, func(tx *sql.Tx) error {
+		var err error
+		results, err = upsertBatch(ctx, tx, userID, queries, batchTokens(items))
+		return err
+	})
 	if err != nil {
-		return &storageErrors.StatementPSQLError{Err: err}
+		return nil, err
 	}
-	defer selectStmt.Close()
-	defer newDataStmt.Close()
-	chanOk := make(chan bool)
-	chanEr := make(chan error)
-	go func() {
-		s.mu.Lock()
-		defer s.mu.Unlock()
-		var queryOutput modelstorage.TextBinaryStorageEntry
-		err := selectStmt.QueryRowContext(ctx, userID, identifier).Scan(&queryOutput.ID, &queryOutput.UserID, &queryOutput.Identifier, &queryOutput.Entry, &queryOutput.Meta)
-		switch {
-		case errors.Is(err, sql.ErrNoRows):
-			_, err = newDataStmt.ExecContext(ctx, userID, identifier, entry, meta)
+	return results, nil
+}
+
+// upsertBatch runs batch upsert statements within a transaction and replaces search tokens of stored entries.
+func upsertBatch(ctx context.Context, tx *sql.Tx, userID string, queries []batchQuery, tokens map[string][]string) ([]modelstorage.BatchItemResult, error) {
+	var results []modelstorage.BatchItemResult
+	var indexes []modelstorage.BlindIndexEntry
+	for _, query := range queries {
+		rows, err := tx.QueryContext(ctx, query.stmt, query.args...)
+		if err != nil {
+			return nil, &storageErrors.ExecutionPSQLError{Err: err}
+		}
+		for rows.Next() {
+			result := modelstorage.BatchItemResult{Db: query.db}
+			err = rows.Scan(&result.Identifier, &result.Created)
 			if err != nil {
-				chanEr <- &storageErrors.ExecutionPSQLError{Err: err}
-				return
+				rows.Close()
+				return nil, &storageErrors.ScanningPSQLError{Err: err}
 			}
-			chanOk <- true
-		case err != nil:
-			chanEr <- &storageErrors.ExecutionPSQLError{Err: err}
-		default:
-			chanEr <- &storageErrors.AlreadyExistsError{Err: err}
+			results = append(results, result)
+			indexes = append(indexes, modelstorage.BlindIndexEntry{Db: query.db, Identifier: result.Identifier, Tokens: tokens[query.db+"/"+result.Identifier]})
 		}
-	}()
-	select {
-	case <-ctx.Done():
-		s.logger.Error().Msgf("adding new text/binary failed for ID %s due to context timeout", identifier)
-		return &storageErrors.ContextTimeoutExceededError{Err: ctx.Err()}
-	case methodErr := <-chanEr:
-		s.logger.Error().Err(methodErr).Msgf("adding new text/binary failed for ID %s due to storage error", identifier)
-		return methodErr
-	case <-chanOk:
-		s.logger.Info().Msgf("adding new text/binary done for ID %s", identifier)
-		return nil
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, &storageErrors.ScanningPSQLError{Err: err}
+		}
+	}
+	// search tokens are replaced only for stored entries
+	if err := replaceBlindIndexes(ctx, tx, userID, indexes); err != nil {
+		return nil, err
 	}
+	return results, nil
 }
 
 // AddNewUser performs a registering procedure of a new user.
@@ -441,7 +496,7 @@ func (s *Storage) AddNewUser(ctx context.Context, login, password, userID string
 
 // CheckUser performs a login procedure of an existing user.
 func (s *Storage) CheckUser(ctx context.Context, login, password string) (string, error) {
-	selectStmt, err := s.DB.PrepareContext(ctx, "SELECT * FROM users WHERE login = $1")
+	selectStmt, err := s.DB.PrepareContext(ctx, "SELECT "+userColumns+" FROM users WHERE login = $1")
 	defer func(selectStmt *sql.Stmt) {
 		err_ := selectStmt.Close()
 		if err_ != nil {
@@ -459,9 +514,10 @@ func (s *Storage) CheckUser(ctx context.Context, login, password string) (string
 		var queryOutput modelstorage.UserStorageEntry
 		err := selectStmt.QueryRowContext(ctx, login).Scan(&queryOutput.ID, &queryOutput.UserID, &queryOutput.Login, &queryOutput.Password, &queryOutput.RegisteredAt)
 		switch {
+		// absent logins are reported as wrong passwords are, so that nobody can tell which logins are registered
 		case errors.Is(err, sql.ErrNoRows):
 			s.logger.Warn().Msg("Absent login detected")
-			chanEr <- &storageErrors.NotFoundError{Err: err}
+			chanEr <- &storageErrors.InvalidCredentialsError{Err: err}
 		case err != nil:
 			chanEr <- err
 		default:
@@ -470,7 +526,7 @@ func (s *Storage) CheckUser(ctx context.Context, login, password string) (string
 			passwordMatch := subtle.ConstantTimeCompare(passwordHash[:], expectedPasswordHash[:]) == 1
 			if !passwordMatch {
 				s.logger.Warn().Msg("Unsuccessful authentication detected")
-				chanEr <- &storageErrors.InvalidPasswordError{Err: nil}
+				chanEr <- &storageErrors.InvalidCredentialsError{Err: nil}
 				return
 			}
 			chanOk <- queryOutput.UserID
@@ -537,6 +593,25 @@ func (s *Storage) DeleteBatch(ctx context.Context, identifiers []string, userID,
 		)
 		if err != nil {
 			chanEr <- &storageErrors.ExecutionPSQLError{Err: err}
+			return
+		}
+		// search tokens of removed entries are removed along with them
+		_, err = tx.ExecContext(ctx, deleteBlindIndexesQuery, userID, db, pq.Array(identifiers))
+		if err != nil {
+			chanEr <- &storageErrors.ExecutionPSQLError{Err: err}
+			return
+		}
+		// removed entries are not shared anymore
+		_, err = tx.ExecContext(ctx, deleteSharesQuery, userID, db, pq.Array(identifiers))
+		if err != nil {
+			chanEr <- &storageErrors.ExecutionPSQLError{Err: err}
+			return
+		}
+		// nor sealed for trusted contacts
+		_, err = tx.ExecContext(ctx, deleteEmergencyEntriesQuery, userID, db, pq.Array(identifiers))
+		if err != nil {
+			chanEr <- &storageErrors.ExecutionPSQLError{Err: err}
+			return
 		}
 		chanOk <- true
 	}()
@@ -601,10 +676,34 @@ func (s *Storage) Flush(ctx context.Context, batch []modelstorage.Removal) error
 	return nil
 }
 
+// moveDuplicatesQuery builds a statement moving entries of a table sharing an identifier with an earlier entry of
+// the same user to entry_duplicates, it runs only until the unique index of the table exists.
+func moveDuplicatesQuery(table string) string {
+	return fmt.Sprintf(`DO $$
+	BEGIN
+		IF to_regclass('%[1]s_user_identifier') IS NULL THEN
+			WITH moved AS (
+				DELETE FROM %[1]s duplicate USING %[1]s original
+				WHERE original.user_id = duplicate.user_id AND original.identifier = duplicate.identifier AND original.id < duplicate.id
+				RETURNING duplicate.*
+			)
+			INSERT INTO entry_duplicates (table_name, entry) SELECT '%[1]s', to_jsonb(moved) FROM moved;
+		END IF;
+	END
+	$$;`, table)
+}
+
 // createTables created necessary tables if the PSQL DB if they do not exist.
 func (s *Storage) createTables(ctx context.Context) error {
 	var queries []string
-	query := `CREATE TABLE IF NOT EXISTS users (
+	// data migrations applied to the DB
+	query := `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version       	INTEGER        	NOT NULL UNIQUE,
+		name            TEXT           	NOT NULL,
+		applied_at 		TIMESTAMPTZ 	NOT NULL DEFAULT now()
+	);`
+	queries = append(queries, query)
+	query = `CREATE TABLE IF NOT EXISTS users (
 		id				BIGSERIAL   	NOT NULL UNIQUE,
 		user_id       	TEXT        	NOT NULL UNIQUE,
 		login         	TEXT        	NOT NULL UNIQUE,
@@ -639,6 +738,237 @@ func (s *Storage) createTables(ctx context.Context) error {
 		card_meta		TEXT
 	);`
 	queries = append(queries, query)
+	// entries sharing an identifier with an earlier entry of the same user are moved aside before unique indexes are
+	// created, they are kept as JSON rows of their tables to be recovered manually
+	query = `CREATE TABLE IF NOT EXISTS entry_duplicates (
+		id           	BIGSERIAL      	NOT NULL UNIQUE,
+		table_name      TEXT           	NOT NULL,
+		entry           JSONB          	NOT NULL,
+		moved_at 		TIMESTAMPTZ 	NOT NULL DEFAULT now()
+	);`
+	queries = append(queries, query)
+	// unique indexes are required for batch upserts
+	for _, table := range []string{"logins_passwords", "texts_binaries", "bank_cards"} {
+		queries = append(queries, moveDuplicatesQuery(table))
+		query = fmt.Sprintf(`CREATE UNIQUE INDEX IF NOT EXISTS %s_user_identifier ON %s (user_id, identifier);`, table, table)
+		queries = append(queries, query)
+	}
+	// keyset pagination indexes
+	query = `CREATE INDEX IF NOT EXISTS logins_passwords_user_id ON logins_passwords (user_id, id);`
+	queries = append(queries, query)
+	query = `CREATE INDEX IF NOT EXISTS texts_binaries_user_id ON texts_binaries (user_id, id);`
+	queries = append(queries, query)
+	query = `CREATE INDEX IF NOT EXISTS bank_cards_user_id ON bank_cards (user_id, id);`
+	queries = append(queries, query)
+	// organizing labels were added after the initial schema, folder and tags are encrypted
+	for _, table := range []string{"logins_passwords", "texts_binaries", "bank_cards"} {
+		query = fmt.Sprintf(`ALTER TABLE %s
+		ADD COLUMN IF NOT EXISTS folder 	TEXT 	NOT NULL DEFAULT '',
+		ADD COLUMN IF NOT EXISTS tags 		TEXT 	NOT NULL DEFAULT '',
+		ADD COLUMN IF NOT EXISTS favorite 	BOOLEAN NOT NULL DEFAULT FALSE;`, table)
+		queries = append(queries, query)
+		// custom fields are stored as a JSON array of fields whose names and values are encrypted one by one
+		query = fmt.Sprintf(`ALTER TABLE %s ADD COLUMN IF NOT EXISTS custom_fields TEXT NOT NULL DEFAULT '';`, table)
+		queries = append(queries, query)
+		// revision timestamps, entries stored before they were introduced get the migration time
+		query = fmt.Sprintf(`ALTER TABLE %s
+		ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT now();`, table)
+		queries = append(queries, query)
+	}
+	query = `ALTER TABLE logins_passwords ADD COLUMN IF NOT EXISTS password_changed_at TIMESTAMPTZ NOT NULL DEFAULT now();`
+	queries = append(queries, query)
+	// expiry dates and PINs of bank cards are optional and encrypted
+	query = `ALTER TABLE bank_cards
+		ADD COLUMN IF NOT EXISTS card_expiry 	TEXT 	NOT NULL DEFAULT '',
+		ADD COLUMN IF NOT EXISTS card_pin 		TEXT 	NOT NULL DEFAULT '';`
+	queries = append(queries, query)
+	// blind indexes map keyed HMAC tokens of search terms to encrypted entry identifiers
+	query = `CREATE TABLE IF NOT EXISTS blind_indexes (
+		id           	BIGSERIAL      	NOT NULL UNIQUE,
+		user_id      	TEXT           	NOT NULL,
+		db           	TEXT           	NOT NULL,
+		identifier      TEXT           	NOT NULL,
+		token        	TEXT           	NOT NULL
+	);`
+	queries = append(queries, query)
+	query = `CREATE UNIQUE INDEX IF NOT EXISTS blind_indexes_entry_token ON blind_indexes (user_id, db, identifier, token);`
+	queries = append(queries, query)
+	query = `CREATE INDEX IF NOT EXISTS blind_indexes_user_token ON blind_indexes (user_id, token);`
+	queries = append(queries, query)
+	// public keys of users wrap record keys of entries shared with them, private keys are kept by clients
+	query = `CREATE TABLE IF NOT EXISTS user_keys (
+		user_id      	TEXT           	NOT NULL UNIQUE,
+		public_key      TEXT           	NOT NULL
+	);`
+	queries = append(queries, query)
+	// a shared entry is encrypted with its own record key, which is wrapped for the owner and each recipient
+	query = `CREATE TABLE IF NOT EXISTS shared_entries (
+		id           	BIGSERIAL      	NOT NULL UNIQUE,
+		owner_id      	TEXT           	NOT NULL,
+		db           	TEXT           	NOT NULL,
+		identifier      TEXT           	NOT NULL,
+		payload        	TEXT           	NOT NULL,
+		owner_key       TEXT           	NOT NULL,
+		updated_at 		TIMESTAMPTZ 	NOT NULL DEFAULT now(),
+		UNIQUE (owner_id, db, identifier)
+	);`
+	queries = append(queries, query)
+	query = `CREATE TABLE IF NOT EXISTS share_recipients (
+		share_id      	BIGINT         	NOT NULL REFERENCES shared_entries (id) ON DELETE CASCADE,
+		recipient_id    TEXT           	NOT NULL,
+		wrapped_key     TEXT           	NOT NULL,
+		permission      TEXT           	NOT NULL,
+		UNIQUE (share_id, recipient_id)
+	);`
+	queries = append(queries, query)
+	query = `CREATE INDEX IF NOT EXISTS share_recipients_recipient ON share_recipients (recipient_id);`
+	queries = append(queries, query)
+	// entries shared with key pairs the server used to keep have to be shared again by their owners
+	query = `ALTER TABLE shared_entries ADD COLUMN IF NOT EXISTS reshare_required BOOLEAN NOT NULL DEFAULT FALSE;`
+	queries = append(queries, query)
+	// entries of a collection are kept in the vault of the collection, its members have roles on it
+	query = `CREATE TABLE IF NOT EXISTS collections (
+		collection_id   TEXT           	NOT NULL UNIQUE,
+		name            TEXT           	NOT NULL,
+		created_at 		TIMESTAMPTZ 	NOT NULL DEFAULT now()
+	);`
+	queries = append(queries, query)
+	query = `CREATE TABLE IF NOT EXISTS collection_members (
+		collection_id   TEXT           	NOT NULL REFERENCES collections (collection_id) ON DELETE CASCADE,
+		user_id         TEXT           	NOT NULL,
+		role            TEXT           	NOT NULL,
+		joined_at 		TIMESTAMPTZ 	NOT NULL DEFAULT now(),
+		UNIQUE (collection_id, user_id)
+	);`
+	queries = append(queries, query)
+	query = `CREATE INDEX IF NOT EXISTS collection_members_user ON collection_members (user_id);`
+	queries = append(queries, query)
+	query = `CREATE TABLE IF NOT EXISTS collection_invitations (
+		id           	BIGSERIAL      	NOT NULL UNIQUE,
+		collection_id   TEXT           	NOT NULL REFERENCES collections (collection_id) ON DELETE CASCADE,
+		invitee_id      TEXT           	NOT NULL,
+		inviter_id      TEXT           	NOT NULL,
+		role            TEXT           	NOT NULL,
+		created_at 		TIMESTAMPTZ 	NOT NULL DEFAULT now(),
+		UNIQUE (collection_id, invitee_id)
+	);`
+	queries = append(queries, query)
+	// membership changes are audited, records are never updated and outlive their collections
+	query = `CREATE TABLE IF NOT EXISTS collection_events (
+		id           	BIGSERIAL      	NOT NULL UNIQUE,
+		collection_id   TEXT           	NOT NULL,
+		actor_id        TEXT           	NOT NULL,
+		target_id       TEXT           	NOT NULL DEFAULT '',
+		action          TEXT           	NOT NULL,
+		role            TEXT           	NOT NULL DEFAULT '',
+		created_at 		TIMESTAMPTZ 	NOT NULL DEFAULT now()
+	);`
+	queries = append(queries, query)
+	query = `CREATE INDEX IF NOT EXISTS collection_events_collection ON collection_events (collection_id, id);`
+	queries = append(queries, query)
+	// security-relevant requests of users, the log is append-only
+	query = `CREATE TABLE IF NOT EXISTS audit_events (
+		id           	BIGSERIAL      	NOT NULL UNIQUE,
+		user_id         TEXT           	NOT NULL,
+		action          TEXT           	NOT NULL,
+		method          TEXT           	NOT NULL,
+		peer            TEXT           	NOT NULL DEFAULT '',
+		code            TEXT           	NOT NULL,
+		created_at 		TIMESTAMPTZ 	NOT NULL DEFAULT now()
+	);`
+	queries = append(queries, query)
+	query = `CREATE INDEX IF NOT EXISTS audit_events_user ON audit_events (user_id, id);`
+	queries = append(queries, query)
+	query = `CREATE OR REPLACE FUNCTION reject_audit_changes() RETURNS trigger AS $$
+	BEGIN
+		RAISE EXCEPTION 'audit log is append-only';
+	END;
+	$$ LANGUAGE plpgsql;`
+	queries = append(queries, query)
+	query = `DROP TRIGGER IF EXISTS audit_events_append_only ON audit_events;`
+	queries = append(queries, query)
+	query = `CREATE TRIGGER audit_events_append_only BEFORE UPDATE OR DELETE OR TRUNCATE ON audit_events
+		FOR EACH STATEMENT EXECUTE PROCEDURE reject_audit_changes();`
+	queries = append(queries, query)
+	// events of a user are chained by hashes, events recorded before chaining have no number and are left out
+	query = `ALTER TABLE audit_events
+		ADD COLUMN IF NOT EXISTS seq BIGINT NOT NULL DEFAULT 0,
+		ADD COLUMN IF NOT EXISTS prev_hash TEXT NOT NULL DEFAULT '',
+		ADD COLUMN IF NOT EXISTS hash TEXT NOT NULL DEFAULT '';`
+	queries = append(queries, query)
+	query = `CREATE UNIQUE INDEX IF NOT EXISTS audit_events_chain ON audit_events (user_id, seq) WHERE seq > 0;`
+	queries = append(queries, query)
+	// signed hashes of chained events, append-only as well
+	query = `CREATE TABLE IF NOT EXISTS audit_checkpoints (
+		id           	BIGSERIAL      	NOT NULL UNIQUE,
+		user_id         TEXT           	NOT NULL,
+		seq             BIGINT         	NOT NULL,
+		hash            TEXT           	NOT NULL,
+		signature       TEXT           	NOT NULL,
+		created_at 		TIMESTAMPTZ 	NOT NULL DEFAULT now()
+	);`
+	queries = append(queries, query)
+	query = `CREATE UNIQUE INDEX IF NOT EXISTS audit_checkpoints_chain ON audit_checkpoints (user_id, seq);`
+	queries = append(queries, query)
+	query = `DROP TRIGGER IF EXISTS audit_checkpoints_append_only ON audit_checkpoints;`
+	queries = append(queries, query)
+	query = `CREATE TRIGGER audit_checkpoints_append_only BEFORE UPDATE OR DELETE OR TRUNCATE ON audit_checkpoints
+		FOR EACH STATEMENT EXECUTE PROCEDURE reject_audit_changes();`
+	queries = append(queries, query)
+	// devices users are logged in from, sessions are identified by digests of their keys and removed once revoked
+	query = `CREATE TABLE IF NOT EXISTS sessions (
+		id           	BIGSERIAL      	NOT NULL UNIQUE,
+		user_id         TEXT           	NOT NULL,
+		digest          TEXT           	NOT NULL UNIQUE,
+		device_name     TEXT           	NOT NULL DEFAULT '',
+		client_version  TEXT           	NOT NULL DEFAULT '',
+		peer            TEXT           	NOT NULL DEFAULT '',
+		created_at 		TIMESTAMPTZ 	NOT NULL DEFAULT now(),
+		last_seen_at 	TIMESTAMPTZ 	NOT NULL DEFAULT now()
+	);`
+	queries = append(queries, query)
+	query = `CREATE INDEX IF NOT EXISTS sessions_user ON sessions (user_id, id);`
+	queries = append(queries, query)
+	// trusted contacts of vault owners
+	query = `CREATE TABLE IF NOT EXISTS emergency_access (
+		id           	BIGSERIAL      	NOT NULL UNIQUE,
+		owner_id        TEXT           	NOT NULL,
+		contact_id      TEXT           	NOT NULL,
+		wait_days       INTEGER        	NOT NULL,
+		status          TEXT           	NOT NULL DEFAULT 'designated',
+		requested_at 	TIMESTAMPTZ,
+		granted_at 		TIMESTAMPTZ,
+		UNIQUE (owner_id, contact_id)
+	);`
+	queries = append(queries, query)
+	// granted vaults used to be sealed by the server as a whole, entries are sealed by clients of owners instead
+	query = `ALTER TABLE emergency_access DROP COLUMN IF EXISTS payload, DROP COLUMN IF EXISTS wrapped_key;`
+	queries = append(queries, query)
+	// entries sealed by clients of owners with their record keys, which are wrapped for each trusted contact
+	query = `CREATE TABLE IF NOT EXISTS emergency_entries (
+		id           	BIGSERIAL      	NOT NULL UNIQUE,
+		vault_id      	TEXT           	NOT NULL,
+		db           	TEXT           	NOT NULL,
+		identifier      TEXT           	NOT NULL,
+		payload        	TEXT           	NOT NULL,
+		sealed_at 		TIMESTAMPTZ 	NOT NULL DEFAULT now(),
+		UNIQUE (vault_id, db, identifier)
+	);`
+	queries = append(queries, query)
+	query = `CREATE TABLE IF NOT EXISTS emergency_keys (
+		entry_id      	BIGINT         	NOT NULL REFERENCES emergency_entries (id) ON DELETE CASCADE,
+		access_id      	BIGINT         	NOT NULL REFERENCES emergency_access (id) ON DELETE CASCADE,
+		wrapped_key     TEXT           	NOT NULL,
+		UNIQUE (entry_id, access_id)
+	);`
+	queries = append(queries, query)
+	query = `CREATE INDEX IF NOT EXISTS emergency_keys_access ON emergency_keys (access_id, entry_id);`
+	queries = append(queries, query)
+	query = `CREATE INDEX IF NOT EXISTS emergency_access_contact ON emergency_access (contact_id);`
+	queries = append(queries, query)
+	query = `CREATE INDEX IF NOT EXISTS emergency_access_requested ON emergency_access (requested_at) WHERE status = 'requested';`
+	queries = append(queries, query)
 	for _, subquery := range queries {
 		_, err := s.DB.ExecContext(ctx, subquery)
 		if err != nil {
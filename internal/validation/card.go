@@ -0,0 +1,294 @@
+// Package validation provides validation of entry data shared by the server and the client.
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Brand defines a payment card brand.
+type Brand string
+
+// supported payment card brands
+const (
+	BrandVisa       Brand = "Visa"
+	BrandMastercard Brand = "Mastercard"
+	BrandAmex       Brand = "American Express"
+	BrandDiscover   Brand = "Discover"
+	BrandDiners     Brand = "Diners Club"
+	BrandJCB        Brand = "JCB"
+	BrandUnionPay   Brand = "UnionPay"
+	BrandMaestro    Brand = "Maestro"
+	BrandMir        Brand = "Mir"
+	BrandUnknown    Brand = "Unknown"
+)
+
+// names of validated bank card fields, they match request field names
+const (
+	FieldNumber = "number"
+	FieldCVV    = "cvv"
+	FieldExpiry = "expiry"
+	FieldPIN    = "pin"
+)
+
+// card number, security code and PIN size limits
+const (
+	minCardNumberLength = 12
+	maxCardNumberLength = 19
+	cvvLength           = 3
+	cidLength           = 4
+	minPINLength        = 4
+	maxPINLength        = 12
+)
+
+// ExpiringSoon is the period before the end of its expiry month within which a card is reported as expiring soon.
+const ExpiringSoon = 60 * 24 * time.Hour
+
+// iinRange maps a range of issuer identification number prefixes of the same length to a brand.
+type iinRange struct {
+	low, high int
+	brand     Brand
+}
+
+// iinRanges lists IIN prefixes of brands, more specific ranges go first.
+var iinRanges = []iinRange{
+	{2200, 2204, BrandMir},
+	{2221, 2720, BrandMastercard},
+	{34, 34, BrandAmex},
+	{37, 37, BrandAmex},
+	{300, 305, BrandDiners},
+	{36, 36, BrandDiners},
+	{38, 39, BrandDiners},
+	{3528, 3589, BrandJCB},
+	{4, 4, BrandVisa},
+	{51, 55, BrandMastercard},
+	{6011, 6011, BrandDiscover},
+	{644, 649, BrandDiscover},
+	{65, 65, BrandDiscover},
+	{62, 62, BrandUnionPay},
+	{50, 50, BrandMaestro},
+	{56, 58, BrandMaestro},
+	{6, 6, BrandMaestro},
+}
+
+// brandLengths lists valid card number lengths of brands.
+var brandLengths = map[Brand][]int{
+	BrandVisa:       {13, 16, 19},
+	BrandMastercard: {16},
+	BrandAmex:       {15},
+	BrandDiscover:   {16, 17, 18, 19},
+	BrandDiners:     {14, 15, 16, 17, 18, 19},
+	BrandJCB:        {16, 17, 18, 19},
+	BrandUnionPay:   {16, 17, 18, 19},
+	BrandMaestro:    {12, 13, 14, 15, 16, 17, 18, 19},
+	BrandMir:        {16, 17, 18, 19},
+	BrandUnknown:    {12, 13, 14, 15, 16, 17, 18, 19},
+}
+
+// FieldError defines a violation of a single field.
+type FieldError struct {
+	Field       string
+	Description string
+}
+
+// Error implements the error interface.
+func (e *FieldError) Error() string {
+	return e.Description
+}
+
+// Errors defines a set of field violations.
+type Errors []*FieldError
+
+// Error implements the error interface.
+func (e Errors) Error() string {
+	descriptions := make([]string, 0, len(e))
+	for _, violation := range e {
+		descriptions = append(descriptions, violation.Description)
+	}
+	return strings.Join(descriptions, "; ")
+}
+
+// add appends a violation of a field if there is any.
+func (e Errors) add(field string, err error) Errors {
+	if err == nil {
+		return e
+	}
+	return append(e, &FieldError{Field: field, Description: err.Error()})
+}
+
+// err returns the violations as an error, nil if there are none.
+func (e Errors) err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+// NormalizeCardNumber drops spaces and dashes card numbers are usually grouped with.
+func NormalizeCardNumber(number string) string {
+	return strings.NewReplacer(" ", "", "-", "").Replace(strings.TrimSpace(number))
+}
+
+// DetectBrand detects a brand of a card number by its issuer identification number.
+func DetectBrand(number string) Brand {
+	number = NormalizeCardNumber(number)
+	for _, r := range iinRanges {
+		length := len(strconv.Itoa(r.low))
+		if len(number) < length {
+			continue
+		}
+		prefix, err := strconv.Atoi(number[:length])
+		if err == nil && prefix >= r.low && prefix <= r.high {
+			return r.brand
+		}
+	}
+	return BrandUnknown
+}
+
+// Luhn reports whether a number of digits passes the Luhn checksum.
+func Luhn(number string) bool {
+	if number == "" || !isDigits(number) {
+		return false
+	}
+	var sum int
+	double := false
+	for i := len(number) - 1; i >= 0; i-- {
+		digit := int(number[i] - '0')
+		if double {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// ValidateCardNumber checks a card number for digits, its length according to the brand and the Luhn checksum, which
+// UnionPay cards do not always have.
+func ValidateCardNumber(number string) (Brand, error) {
+	number = NormalizeCardNumber(number)
+	if number == "" {
+		return BrandUnknown, errors.New("card number cannot be empty")
+	}
+	if !isDigits(number) {
+		return BrandUnknown, errors.New("card number must consist of digits only")
+	}
+	if len(number) < minCardNumberLength || len(number) > maxCardNumberLength {
+		return BrandUnknown, fmt.Errorf("card number must consist of %d to %d digits", minCardNumberLength, maxCardNumberLength)
+	}
+	brand := DetectBrand(number)
+	if !containsLength(brandLengths[brand], len(number)) {
+		return brand, fmt.Errorf("%s card number cannot consist of %d digits", brand, len(number))
+	}
+	if brand != BrandUnionPay && !Luhn(number) {
+		return brand, errors.New("card number fails the checksum, check it for typos")
+	}
+	return brand, nil
+}
+
+// ValidateCVV checks a card security code: American Express cards have a 4-digit CID, others have a 3-digit CVV.
+func ValidateCVV(cvv string, brand Brand) error {
+	length := cvvLength
+	if brand == BrandAmex {
+		length = cidLength
+	}
+	if len(cvv) != length || !isDigits(cvv) {
+		return fmt.Errorf("card security code must consist of %d digits", length)
+	}
+	return nil
+}
+
+// ParseExpiry parses an expiry date of the MM/YY or MM/YYYY form.
+func ParseExpiry(expiry string) (time.Month, int, error) {
+	monthText, yearText, ok := strings.Cut(strings.TrimSpace(expiry), "/")
+	month, err := strconv.Atoi(monthText)
+	if !ok || err != nil || len(monthText) != 2 || !isDigits(monthText) || month < 1 || month > 12 {
+		return 0, 0, errors.New("expiry date must be of the MM/YY form")
+	}
+	year, err := strconv.Atoi(yearText)
+	if err != nil || !isDigits(yearText) || (len(yearText) != 2 && len(yearText) != 4) {
+		return 0, 0, errors.New("expiry date must be of the MM/YY form")
+	}
+	if len(yearText) == 2 {
+		year += 2000
+	}
+	return time.Month(month), year, nil
+}
+
+// NormalizeExpiry converts a valid expiry date to the MM/YY form, an empty date stays empty.
+func NormalizeExpiry(expiry string) string {
+	month, year, err := ParseExpiry(expiry)
+	if err != nil {
+		return strings.TrimSpace(expiry)
+	}
+	return fmt.Sprintf("%02d/%02d", month, year%100)
+}
+
+// ValidatePIN checks an optional card PIN.
+func ValidatePIN(pin string) error {
+	if pin == "" {
+		return nil
+	}
+	if len(pin) < minPINLength || len(pin) > maxPINLength || !isDigits(pin) {
+		return fmt.Errorf("PIN must consist of %d to %d digits", minPINLength, maxPINLength)
+	}
+	return nil
+}
+
+// ValidateBankCard checks all bank card fields, the expiry date and the PIN are optional. Violations of all fields are
+// reported at once as Errors.
+func ValidateBankCard(number, cvv, expiry, pin string) error {
+	var violations Errors
+	brand, err := ValidateCardNumber(number)
+	violations = violations.add(FieldNumber, err)
+	violations = violations.add(FieldCVV, ValidateCVV(cvv, brand))
+	if strings.TrimSpace(expiry) != "" {
+		_, _, err = ParseExpiry(expiry)
+		violations = violations.add(FieldExpiry, err)
+	}
+	violations = violations.add(FieldPIN, ValidatePIN(pin))
+	return violations.err()
+}
+
+// ExpiryWarning returns a warning about an expired card or a card expiring soon, an empty string otherwise.
+func ExpiryWarning(expiry string, now time.Time) string {
+	month, year, err := ParseExpiry(expiry)
+	if err != nil {
+		return ""
+	}
+	// a card is valid through the last day of its expiry month
+	end := time.Date(year, month+1, 1, 0, 0, 0, 0, now.Location())
+	switch {
+	case !now.Before(end):
+		return fmt.Sprintf("card expired at the end of %02d/%d", month, year)
+	case end.Sub(now) <= ExpiringSoon:
+		return fmt.Sprintf("card expires soon, at the end of %02d/%d", month, year)
+	}
+	return ""
+}
+
+// containsLength reports whether a length is one of the lengths.
+func containsLength(lengths []int, length int) bool {
+	for _, l := range lengths {
+		if l == length {
+			return true
+		}
+	}
+	return false
+}
+
+// isDigits reports whether a string consists of ASCII digits only.
+func isDigits(value string) bool {
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
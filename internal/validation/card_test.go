@@ -0,0 +1,87 @@
+package validation
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateCardNumber(t *testing.T) {
+	tests := []struct {
+		number string
+		brand  Brand
+		err    string
+	}{
+		{number: "4111 1111 1111 1111", brand: BrandVisa},
+		{number: "5555-5555-5555-4444", brand: BrandMastercard},
+		{number: "2223003122003222", brand: BrandMastercard},
+		{number: "378282246310005", brand: BrandAmex},
+		{number: "6011111111111117", brand: BrandDiscover},
+		{number: "30569309025904", brand: BrandDiners},
+		{number: "3530111333300000", brand: BrandJCB},
+		{number: "6200000000000005", brand: BrandUnionPay},
+		{number: "6759649826438453", brand: BrandMaestro},
+		{number: "501800000009", brand: BrandMaestro},
+		{number: "2200000000000004", brand: BrandMir},
+		{number: "", brand: BrandUnknown, err: "card number cannot be empty"},
+		{number: "4111a11111111111", brand: BrandUnknown, err: "card number must consist of digits only"},
+		{number: "41111", brand: BrandUnknown, err: "card number must consist of 12 to 19 digits"},
+		{number: "4111111111111112", brand: BrandVisa, err: "card number fails the checksum, check it for typos"},
+		{number: "37828224631000", brand: BrandAmex, err: "American Express card number cannot consist of 14 digits"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.number, func(t *testing.T) {
+			brand, err := ValidateCardNumber(tt.number)
+			assert.Equal(t, tt.brand, brand)
+			if tt.err == "" {
+				assert.Equal(t, nil, err)
+			} else {
+				assert.Equal(t, tt.err, err.Error())
+			}
+		})
+	}
+}
+
+func TestValidateBankCard(t *testing.T) {
+	assert.Equal(t, nil, ValidateBankCard("378282246310005", "1234", "12/30", ""))
+	assert.Equal(t, nil, ValidateBankCard("4111111111111111", "123", "", "0000"))
+
+	err := ValidateBankCard("4111111111111112", "1234", "13/30", "12")
+	var violations Errors
+	assert.Equal(t, true, errors.As(err, &violations))
+	assert.Equal(t, Errors{
+		{Field: FieldNumber, Description: "card number fails the checksum, check it for typos"},
+		{Field: FieldCVV, Description: "card security code must consist of 3 digits"},
+		{Field: FieldExpiry, Description: "expiry date must be of the MM/YY form"},
+		{Field: FieldPIN, Description: "PIN must consist of 4 to 12 digits"},
+	}, violations)
+	assert.Equal(t, "card number fails the checksum, check it for typos; card security code must consist of 3 digits; "+
+		"expiry date must be of the MM/YY form; PIN must consist of 4 to 12 digits", err.Error())
+}
+
+func TestParseExpiry(t *testing.T) {
+	month, year, err := ParseExpiry("09/27")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, time.September, month)
+	assert.Equal(t, 2027, year)
+	_, year, err = ParseExpiry("09/2031")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2031, year)
+	for _, expiry := range []string{"9/27", "00/27", "09-27", "09/027", "ab/cd"} {
+		_, _, err = ParseExpiry(expiry)
+		assert.Equal(t, "expiry date must be of the MM/YY form", err.Error())
+	}
+	assert.Equal(t, "09/31", NormalizeExpiry(" 09/2031 "))
+	assert.Equal(t, "", NormalizeExpiry(""))
+}
+
+func TestExpiryWarning(t *testing.T) {
+	now := time.Date(2024, time.March, 15, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, "card expired at the end of 02/2024", ExpiryWarning("02/24", now))
+	assert.Equal(t, "card expires soon, at the end of 03/2024", ExpiryWarning("03/24", now))
+	assert.Equal(t, "card expires soon, at the end of 04/2024", ExpiryWarning("04/24", now))
+	assert.Equal(t, "", ExpiryWarning("12/24", now))
+	assert.Equal(t, "", ExpiryWarning("", now))
+}
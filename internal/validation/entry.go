@@ -0,0 +1,80 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// names of validated entry fields, they match request field names
+const (
+	FieldIdentifier = "identifier"
+	FieldHolder     = "holder"
+	FieldLogin      = "login"
+	FieldPassword   = "password"
+	FieldEntry      = "entry"
+	FieldMeta       = "meta"
+	FieldFields     = "fields"
+)
+
+// entry field size limits in bytes
+const (
+	MaxIdentifierLength = 256
+	MaxHolderLength     = 256
+	MaxLoginLength      = 256
+	MaxPasswordLength   = 1024
+	MaxMetaLength       = 4096
+	MaxFieldValueLength = 4096
+	// MaxEntryLength keeps a text/binary entry within the default 4 MiB limit of a gRPC message, servers may configure a
+	// lower limit.
+	MaxEntryLength = 3 << 20
+)
+
+// ValidateIdentifier checks an entry identifier, which is required.
+func ValidateIdentifier(identifier string) error {
+	if strings.TrimSpace(identifier) == "" {
+		return errors.New("identifier cannot be empty")
+	}
+	return ValidateLength(FieldIdentifier, identifier, MaxIdentifierLength)
+}
+
+// ValidateLength checks that a field value does not exceed a size limit.
+func ValidateLength(field, value string, limit int) error {
+	if len(value) > limit {
+		return fmt.Errorf("%s must not exceed %d bytes", field, limit)
+	}
+	return nil
+}
+
+// ValidateBankCardEntry checks all fields of a bank card entry, violations of all fields are reported at once as Errors.
+func ValidateBankCardEntry(identifier, number, holder, cvv, expiry, pin, meta string) error {
+	var violations Errors
+	violations = violations.add(FieldIdentifier, ValidateIdentifier(identifier))
+	var card Errors
+	errors.As(ValidateBankCard(number, cvv, expiry, pin), &card)
+	violations = append(violations, card...)
+	violations = violations.add(FieldHolder, ValidateLength(FieldHolder, holder, MaxHolderLength))
+	violations = violations.add(FieldMeta, ValidateLength(FieldMeta, meta, MaxMetaLength))
+	return violations.err()
+}
+
+// ValidateLoginPassword checks all fields of a login/password entry, violations of all fields are reported at once as
+// Errors.
+func ValidateLoginPassword(identifier, login, password, meta string) error {
+	var violations Errors
+	violations = violations.add(FieldIdentifier, ValidateIdentifier(identifier))
+	violations = violations.add(FieldLogin, ValidateLength(FieldLogin, login, MaxLoginLength))
+	violations = violations.add(FieldPassword, ValidateLength(FieldPassword, password, MaxPasswordLength))
+	violations = violations.add(FieldMeta, ValidateLength(FieldMeta, meta, MaxMetaLength))
+	return violations.err()
+}
+
+// ValidateTextBinary checks all fields of a text/binary entry, the entry must not exceed maxEntryLength bytes. Violations
+// of all fields are reported at once as Errors.
+func ValidateTextBinary(identifier, entry, meta string, maxEntryLength int) error {
+	var violations Errors
+	violations = violations.add(FieldIdentifier, ValidateIdentifier(identifier))
+	violations = violations.add(FieldEntry, ValidateLength(FieldEntry, entry, maxEntryLength))
+	violations = violations.add(FieldMeta, ValidateLength(FieldMeta, meta, MaxMetaLength))
+	return violations.err()
+}
@@ -0,0 +1,31 @@
+package validation
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateEntries(t *testing.T) {
+	assert.Equal(t, nil, ValidateLoginPassword("github", "user", "pass", ""))
+	assert.Equal(t, nil, ValidateTextBinary("note", strings.Repeat("a", MaxEntryLength), "", MaxEntryLength))
+	assert.Equal(t, nil, ValidateBankCardEntry("visa", "4111111111111111", "JOHN DOE", "123", "", "", ""))
+
+	err := ValidateLoginPassword("", strings.Repeat("a", MaxLoginLength+1), "pass", "")
+	var violations Errors
+	assert.Equal(t, true, errors.As(err, &violations))
+	assert.Equal(t, Errors{
+		{Field: FieldIdentifier, Description: "identifier cannot be empty"},
+		{Field: FieldLogin, Description: "login must not exceed 256 bytes"},
+	}, violations)
+
+	err = ValidateBankCardEntry(strings.Repeat("a", MaxIdentifierLength+1), "4111111111111111", "", "12", "", "", "")
+	assert.Equal(t, "identifier must not exceed 256 bytes; card security code must consist of 3 digits", err.Error())
+
+	err = ValidateTextBinary("note", strings.Repeat("a", MaxEntryLength+1), "", MaxEntryLength)
+	assert.Equal(t, "entry must not exceed 3145728 bytes", err.Error())
+	err = ValidateTextBinary("note", strings.Repeat("a", 1025), "", 1024)
+	assert.Equal(t, "entry must not exceed 1024 bytes", err.Error())
+}